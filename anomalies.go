@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/misty-step/costctl/report"
+	"github.com/misty-step/costctl/reporter"
+	"github.com/misty-step/costctl/store"
+	"github.com/spf13/cobra"
+)
+
+// anomalies command flags
+var (
+	anomaliesPeriod   string
+	anomaliesAgent    string
+	anomaliesNewSince string
+	anomaliesAll      bool
+	anomaliesFormat   string
+	anomaliesStore    string
+)
+
+var anomaliesCmd = &cobra.Command{
+	Use:   "anomalies",
+	Short: "Track anomalies across runs and list those still open",
+	Long: `Detect anomalies over a period, record them in the local store and list
+the ones still open. Each anomaly has a stable ID derived from its rule and
+scope (agent, cron and session), so runs recognise anomalies they found
+before. An anomaly opens the first time it is found and resolves once a run
+no longer finds it; found again, it opens anew. Runs limited to one agent
+resolve nothing.
+
+report --notify tracks anomalies in the same store, and only posts those not
+yet sent since they opened.
+
+--new-since accepts today, yesterday, week, month, a date (YYYY-MM-DD, local
+time) or an RFC 3339 time.
+
+Examples:
+  costctl anomalies
+  costctl anomalies --new-since yesterday
+  costctl anomalies --period week --all --format json`,
+	RunE: runAnomalies,
+}
+
+func init() {
+	anomaliesCmd.Flags().StringVar(&anomaliesPeriod, "period", "today", "Period to detect anomalies over: today|yesterday|week|month|ytd|all|YYYY-MM")
+	anomaliesCmd.Flags().StringVar(&anomaliesAgent, "agent", "", "Filter by agent")
+	anomaliesCmd.Flags().StringVar(&anomaliesNewSince, "new-since", "", "Only list anomalies that opened since this time")
+	anomaliesCmd.Flags().BoolVar(&anomaliesAll, "all", false, "Include resolved anomalies")
+	anomaliesCmd.Flags().StringVar(&anomaliesFormat, "format", "text", "Output format: json|text")
+	anomaliesCmd.Flags().StringVar(&anomaliesStore, "store", defaultStoreDSN, "Store anomalies are tracked in")
+
+	anomaliesCmd.RegisterFlagCompletionFunc("agent", completeAgents)
+}
+
+func runAnomalies(cmd *cobra.Command, args []string) error {
+	if anomaliesFormat != "json" && anomaliesFormat != "text" {
+		return fmt.Errorf("invalid format: %s (valid: json, text)", anomaliesFormat)
+	}
+	if err := validatePeriod(anomaliesPeriod); err != nil {
+		return err
+	}
+	if anomaliesStore == "" {
+		return fmt.Errorf("--store is required to track anomalies")
+	}
+	now := time.Now()
+	var since time.Time
+	if anomaliesNewSince != "" {
+		var err error
+		if since, err = parseNewSince(anomaliesNewSince, now); err != nil {
+			return err
+		}
+	}
+
+	settings, err := loadSettings()
+	if err != nil {
+		return err
+	}
+	roots, err := resolveAgentsRoots()
+	if err != nil {
+		return err
+	}
+	progress, err := newProgress()
+	if err != nil {
+		return err
+	}
+	cache := openCache()
+	defer saveCache(cache)
+	ctx := context.Background()
+	result, err := report.Generate(ctx, report.Options{
+		Roots:    roots,
+		Period:   anomaliesPeriod,
+		Agent:    anomaliesAgent,
+		Sections: []string{reporter.SectionAnomalies},
+		Settings: settings,
+		Progress: parserProgress(progress),
+		Cache:    cache,
+		Version:  rootCmd.Version,
+	})
+	if err != nil {
+		return err
+	}
+	if progress != nil {
+		progress.Done()
+	}
+
+	st, tracker, err := openAnomalyStore(anomaliesStore)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	records, err := trackAnomalies(ctx, tracker, result.Report.Anomalies, now, anomaliesAgent != "")
+	if err != nil {
+		return err
+	}
+
+	listed := []store.AnomalyRecord{}
+	for _, r := range records {
+		if (anomaliesAll || r.Open()) && !r.FirstSeen.Before(since) {
+			listed = append(listed, r)
+		}
+	}
+
+	if anomaliesFormat == "json" {
+		data, err := json.MarshalIndent(listed, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format anomalies: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(listed) == 0 {
+		fmt.Println("No anomalies")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tOPENED\tTYPE\tAGENT\tDESCRIPTION")
+	for _, r := range listed {
+		status := "open"
+		if !r.Open() {
+			status = "resolved"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", r.ID, status, r.FirstSeen.Local().Format("2006-01-02 15:04"), r.Type, r.Agent, r.Description)
+	}
+	return tw.Flush()
+}
+
+// parseNewSince parses a --new-since value: a period name, which stands
+// for its start, a local date or an RFC 3339 time.
+func parseNewSince(value string, now time.Time) (time.Time, error) {
+	switch value {
+	case "today", "yesterday", "week", "month":
+		from, _, err := reporter.PeriodWindow(value, now)
+		return from, err
+	}
+	if day, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return day, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --new-since: %s (use today, yesterday, week, month, YYYY-MM-DD or an RFC 3339 time)", value)
+}
+
+// openAnomalyStore opens the store at dsn for tracking anomalies.
+func openAnomalyStore(dsn string) (store.Store, store.AnomalyStore, error) {
+	st, err := store.Open(dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	tracker, ok := st.(store.AnomalyStore)
+	if !ok {
+		st.Close()
+		return nil, nil, fmt.Errorf("store %s cannot track anomalies", dsn)
+	}
+	return st, tracker, nil
+}
+
+// trackAnomalies records the anomalies a run found at now and returns
+// every tracked anomaly. Partial runs resolve nothing.
+func trackAnomalies(ctx context.Context, tracker store.AnomalyStore, found []reporter.Anomaly, now time.Time, partial bool) ([]store.AnomalyRecord, error) {
+	tracked, err := tracker.Anomalies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tracked anomalies: %w", err)
+	}
+	changed := store.TrackAnomalies(tracked, found, now, partial)
+	if err := tracker.PutAnomalies(ctx, changed); err != nil {
+		return nil, fmt.Errorf("failed to track anomalies: %w", err)
+	}
+	return tracker.Anomalies(ctx)
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/misty-step/costctl/config"
+	"github.com/misty-step/costctl/formats"
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/report"
+	"github.com/misty-step/costctl/reporter"
+	"github.com/spf13/cobra"
+)
+
+// budget allocate command flags
+var (
+	budgetDays       int
+	budgetPercentile float64
+	budgetHeadroom   float64
+	budgetMinDays    int
+	budgetAgent      string
+	budgetYes        bool
+)
+
+// budget status command flags
+var (
+	budgetStatusAgent  string
+	budgetStatusFormat string
+	budgetFailOver     bool
+)
+
+var budgetCmd = &cobra.Command{
+	Use:   "budget",
+	Short: "Manage spending budgets",
+}
+
+var budgetStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show how much of each budget is spent",
+	Long: `Show each budget configured in the config file with its spend so far in
+the current calendar day, week (from Monday) or month, and the percentage
+of the limit used. Budgets are set under budgets: agents, crons and total
+apply per day, and the same keys under weekly and monthly per week and
+month.
+
+With --fail-over-budget, the command exits with status 4 when a budget is
+exceeded, for CI jobs and cron alerting.
+
+Examples:
+  costctl budget status
+  costctl budget status --agent urza --format json
+  costctl budget status --fail-over-budget || page-oncall "over budget"`,
+	RunE: runBudgetStatus,
+}
+
+var budgetAllocateCmd = &cobra.Command{
+	Use:   "allocate",
+	Short: "Propose per-agent and per-cron daily budgets from recent spend",
+	Long: `Analyze recent daily spend and propose a daily budget for each agent and
+cron: the given percentile of its daily spend plus headroom. Days without
+spend count as $0. Each proposal can be accepted, changed or skipped; the
+accepted budgets are written to budgets in the config file after
+confirmation.
+
+Examples:
+  costctl budget allocate
+  costctl budget allocate --days 14 --percentile 95 --headroom 50
+  costctl budget allocate --yes`,
+	RunE: runBudgetAllocate,
+}
+
+func init() {
+	budgetAllocateCmd.Flags().IntVar(&budgetDays, "days", 30, "Days of history to analyze")
+	budgetAllocateCmd.Flags().Float64Var(&budgetPercentile, "percentile", 90, "Daily spend percentile to base budgets on")
+	budgetAllocateCmd.Flags().Float64Var(&budgetHeadroom, "headroom", 20, "Percent added to the percentile")
+	budgetAllocateCmd.Flags().IntVar(&budgetMinDays, "min-days", 3, "Minimum days with spend before an agent or cron gets a budget")
+	budgetAllocateCmd.Flags().StringVar(&budgetAgent, "agent", "", "Only budget this agent and its crons")
+	budgetAllocateCmd.Flags().BoolVar(&budgetYes, "yes", false, "Accept every proposal and write them without prompting")
+
+	budgetAllocateCmd.RegisterFlagCompletionFunc("agent", completeAgents)
+
+	budgetStatusCmd.Flags().StringVar(&budgetStatusAgent, "agent", "", "Only show this agent's budgets")
+	budgetStatusCmd.Flags().StringVar(&budgetStatusFormat, "format", "text", "Output format: json|text")
+	budgetStatusCmd.Flags().BoolVar(&budgetFailOver, "fail-over-budget", false, "Exit with status 4 when a budget is exceeded")
+
+	budgetStatusCmd.RegisterFlagCompletionFunc("agent", completeAgents)
+
+	budgetCmd.AddCommand(budgetAllocateCmd)
+	budgetCmd.AddCommand(budgetStatusCmd)
+}
+
+func runBudgetStatus(cmd *cobra.Command, args []string) error {
+	if budgetStatusFormat != "json" && budgetStatusFormat != "text" {
+		return fmt.Errorf("invalid format: %s (valid: json, text)", budgetStatusFormat)
+	}
+	settings, err := loadSettings()
+	if err != nil {
+		return err
+	}
+	roots, err := resolveAgentsRoots()
+	if err != nil {
+		return err
+	}
+	progress, err := newProgress()
+	if err != nil {
+		return err
+	}
+	cache := openCache()
+	defer saveCache(cache)
+	// Budgets reach back to the start of their own day, week or month,
+	// whatever the period.
+	result, err := report.Generate(context.Background(), report.Options{
+		Roots:    roots,
+		Period:   "today",
+		Agent:    budgetStatusAgent,
+		Sections: []string{reporter.SectionBudgets},
+		Settings: settings,
+		Progress: parserProgress(progress),
+		Cache:    cache,
+		Version:  rootCmd.Version,
+	})
+	if err != nil {
+		return err
+	}
+	if progress != nil {
+		progress.Done()
+	}
+	budgets := result.Report.Budgets
+	if len(budgets) == 0 {
+		return fmt.Errorf("no budgets configured: set budgets in the config file, or propose them with costctl budget allocate")
+	}
+
+	if budgetStatusFormat == "json" {
+		data, err := json.MarshalIndent(budgets, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format budgets: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "PERIOD\tSCOPE\tNAME\tSINCE\tSPENT\tLIMIT\tUSED\t")
+		for _, b := range budgets {
+			name := b.Name
+			if b.Scope == "total" {
+				name = "all agents"
+			}
+			used := fmt.Sprintf("%.1f%%", b.Percent)
+			if b.Exceeded {
+				used += " over"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", b.Period, b.Scope, name, b.From.Format("2006-01-02"),
+				parser.FormatCost(b.Spent), parser.FormatCost(b.Limit), used)
+		}
+		tw.Flush()
+	}
+
+	if budgetFailOver && result.Report.OverBudget() {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &exitCodeError{code: exitBudgetExceeded}
+	}
+	return nil
+}
+
+func runBudgetAllocate(cmd *cobra.Command, args []string) error {
+	if budgetDays <= 0 {
+		return fmt.Errorf("invalid days: %d (must be positive)", budgetDays)
+	}
+	if budgetPercentile <= 0 || budgetPercentile > 100 {
+		return fmt.Errorf("invalid percentile: %v (must be in (0, 100])", budgetPercentile)
+	}
+	if budgetHeadroom < 0 {
+		return fmt.Errorf("invalid headroom: %v (must not be negative)", budgetHeadroom)
+	}
+
+	now := time.Now()
+	sessions, _, err := parseSessions(budgetAgent, now.AddDate(0, 0, -budgetDays), time.Time{})
+	if err != nil {
+		return err
+	}
+	suggestions := reporter.SuggestBudgets(sessions, reporter.BudgetOptions{
+		Days:          budgetDays,
+		Until:         now,
+		Percentile:    budgetPercentile,
+		Headroom:      budgetHeadroom / 100,
+		MinActiveDays: budgetMinDays,
+	})
+
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	if len(suggestions) == 0 {
+		fmt.Printf("No agents or crons with spend on at least %d of the last %d days\n", budgetMinDays, budgetDays)
+		return nil
+	}
+
+	names := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		names[i] = s.Name
+	}
+	width := formats.ColumnWidth("NAME", names, 25, false)
+	fmt.Printf("Daily budgets from the last %d days (P%g + %g%%):\n\n", budgetDays, budgetPercentile, budgetHeadroom)
+	fmt.Printf("  %-6s %-*s %5s %10s %10s %10s\n", "SCOPE", width, "NAME", "DAYS", fmt.Sprintf("P%g", budgetPercentile), "PROPOSED", "CURRENT")
+	for _, s := range suggestions {
+		current := "-"
+		if b, ok := currentBudget(cfg, s); ok {
+			current = parser.FormatCost(b)
+		}
+		fmt.Printf("  %-6s %-*s %5d %10s %10s %10s\n",
+			s.Scope, width, formats.Truncate(s.Name, width), s.ActiveDays, parser.FormatCost(s.Percentile), parser.FormatCost(s.Suggested), current)
+	}
+
+	accepted := suggestions
+	if !budgetYes {
+		in := bufio.NewReader(cmd.InOrStdin())
+		fmt.Println("\nPress Enter to accept a budget, type an amount to change it, or s to skip it.")
+		if accepted, err = reviewBudgets(in, suggestions); err != nil {
+			return err
+		}
+		if len(accepted) == 0 {
+			fmt.Println("No budgets accepted")
+			return nil
+		}
+		ok, err := confirm(in, fmt.Sprintf("Write %d budgets to %s?", len(accepted), path))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Nothing written")
+			return nil
+		}
+	}
+
+	for _, s := range accepted {
+		switch s.Scope {
+		case "agent":
+			if cfg.Budgets.Agents == nil {
+				cfg.Budgets.Agents = make(map[string]float64)
+			}
+			cfg.Budgets.Agents[s.Name] = s.Suggested
+		case "cron":
+			if cfg.Budgets.Crons == nil {
+				cfg.Budgets.Crons = make(map[string]float64)
+			}
+			cfg.Budgets.Crons[s.Name] = s.Suggested
+		}
+	}
+	if err := cfg.Save(path); err != nil {
+		return err
+	}
+	fmt.Printf("\nSaved %d budgets to %s\n", len(accepted), path)
+	return nil
+}
+
+// currentBudget returns the budget already configured for a suggestion's
+// agent or cron.
+func currentBudget(cfg *config.Config, s reporter.BudgetSuggestion) (float64, bool) {
+	budgets := cfg.Budgets.Agents
+	if s.Scope == "cron" {
+		budgets = cfg.Budgets.Crons
+	}
+	b, ok := budgets[s.Name]
+	return b, ok
+}
+
+// reviewBudgets asks about each suggestion in turn and returns the
+// accepted ones, with any amounts typed in place of the proposal.
+func reviewBudgets(in *bufio.Reader, suggestions []reporter.BudgetSuggestion) ([]reporter.BudgetSuggestion, error) {
+	var accepted []reporter.BudgetSuggestion
+	for _, s := range suggestions {
+		for {
+			answer, err := prompt(in, fmt.Sprintf("  %s %s [%s/day]: ", s.Scope, s.Name, parser.FormatCost(s.Suggested)))
+			if err != nil {
+				return nil, err
+			}
+			if answer == "s" || answer == "skip" {
+				break
+			}
+			if answer != "" {
+				amount, err := strconv.ParseFloat(strings.TrimPrefix(answer, "$"), 64)
+				if err != nil || amount <= 0 {
+					fmt.Printf("  Invalid amount: %s\n", answer)
+					continue
+				}
+				s.Suggested = amount
+			}
+			accepted = append(accepted, s)
+			break
+		}
+	}
+	return accepted, nil
+}
+
+// confirm asks a yes/no question; anything but y or yes is no.
+func confirm(in *bufio.Reader, question string) (bool, error) {
+	answer, err := prompt(in, question+" [y/N]: ")
+	if err != nil {
+		return false, err
+	}
+	return answer == "y" || answer == "yes", nil
+}
+
+// prompt prints a question and reads the trimmed, lower-cased answer. Input
+// ending before an answer aborts, so nothing is written by accident.
+func prompt(in *bufio.Reader, question string) (string, error) {
+	fmt.Print(question)
+	line, err := in.ReadString('\n')
+	if err == io.EOF && line == "" {
+		fmt.Println()
+		return "", fmt.Errorf("aborted: no answer given (use --yes to accept every proposal)")
+	}
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.ToLower(strings.TrimSpace(line)), nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// defaultCachePath is where parsed sessions are kept between runs.
+const defaultCachePath = "~/.costctl/sessions.cache"
+
+// noCache is the --no-cache flag.
+var noCache bool
+
+// openCache loads the session cache, or returns nil with --no-cache. A
+// cache that cannot be read is reported and rebuilt rather than failing
+// the command, since it only saves time.
+func openCache() *parser.Cache {
+	if noCache {
+		return nil
+	}
+	path, err := parser.ExpandPath(defaultCachePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: session cache disabled: %v\n", err)
+		return nil
+	}
+	cache, err := parser.LoadCache(path)
+	if err != nil {
+		if !errors.Is(err, parser.ErrCacheCorrupt) {
+			fmt.Fprintf(os.Stderr, "Warning: session cache disabled: %v\n", err)
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "Warning: rebuilding session cache: %v\n", err)
+		cache = parser.NewCache(path)
+	}
+	return cache
+}
+
+// saveCache drops the sessions of transcripts that are gone and writes the
+// cache back. Failures are only reported.
+func saveCache(cache *parser.Cache) {
+	if cache == nil {
+		return
+	}
+	cache.Prune()
+	if err := cache.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
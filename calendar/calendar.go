@@ -0,0 +1,172 @@
+// Package calendar lays cron runs out as calendar events, an iCalendar or
+// CSV overlay that operators subscribe to or import next to their job
+// schedules, so cost spikes can be read against what was scheduled when.
+package calendar
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// minDuration is the shortest event written: runs recorded as instants
+// would otherwise vanish from most calendar views.
+const minDuration = time.Minute
+
+// prodID identifies costctl as the calendar's producer.
+const prodID = "-//misty-step//costctl//EN"
+
+// Columns are the CSV header.
+var Columns = []string{"start", "end", "cron", "agent", "tenant", "cost", "tokens", "model", "session_id", "uid"}
+
+// Event is one cron run.
+type Event struct {
+	UID       string
+	Start     time.Time
+	End       time.Time
+	Cron      string
+	Agent     string
+	Tenant    string
+	Cost      float64
+	Tokens    int
+	Model     string
+	SessionID string
+}
+
+// Events returns an event per cron run among sessions, oldest first. UIDs
+// are stable across exports from host, so re-imported runs update rather
+// than duplicate. Other sessions, and runs without a start time, are
+// skipped.
+func Events(sessions []parser.Session, host string) []Event {
+	var events []Event
+	for _, s := range sessions {
+		if s.Type != parser.SessionTypeCron || s.StartedAt.IsZero() {
+			continue
+		}
+		end := s.EndedAt
+		if end.Sub(s.StartedAt) < minDuration {
+			end = s.StartedAt.Add(minDuration)
+		}
+		agent := s.Agent
+		if s.Tenant != "" {
+			agent = s.Tenant + "/" + s.Agent
+		}
+		events = append(events, Event{
+			UID:       fmt.Sprintf("%s/%s@%s", agent, s.ID, host),
+			Start:     s.StartedAt.UTC(),
+			End:       end.UTC(),
+			Cron:      s.CronName,
+			Agent:     s.Agent,
+			Tenant:    s.Tenant,
+			Cost:      s.Usage.CostTotal,
+			Tokens:    s.Usage.Total,
+			Model:     s.Usage.Model,
+			SessionID: s.ID,
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+	return events
+}
+
+// Summary titles an event with its cron and cost, e.g. "backup $0.42".
+func (e Event) Summary() string {
+	return fmt.Sprintf("%s $%.2f", e.Cron, e.Cost)
+}
+
+// WriteICS writes events as an iCalendar (RFC 5545) calendar, stamped at
+// stamp.
+func WriteICS(w io.Writer, events []Event, stamp time.Time) error {
+	var b strings.Builder
+	line := func(name, value string) {
+		fold(&b, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", prodID)
+	line("CALSCALE", "GREGORIAN")
+	line("X-WR-CALNAME", "OpenClaw cron costs")
+	for _, e := range events {
+		description := fmt.Sprintf("Agent: %s\nCost: $%.4f\nTokens: %d\nModel: %s\nSession: %s", e.Agent, e.Cost, e.Tokens, e.Model, e.SessionID)
+		if e.Tenant != "" {
+			description = "Tenant: " + e.Tenant + "\n" + description
+		}
+		line("BEGIN", "VEVENT")
+		line("UID", escape(e.UID))
+		line("DTSTAMP", icsTime(stamp))
+		line("DTSTART", icsTime(e.Start))
+		line("DTEND", icsTime(e.End))
+		line("SUMMARY", escape(e.Summary()))
+		line("DESCRIPTION", escape(description))
+		line("CATEGORIES", escape(e.Cron))
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteCSV writes events as CSV with a Columns header, for calendar and
+// spreadsheet tools that import tables.
+func WriteCSV(w io.Writer, events []Event) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(Columns); err != nil {
+		return err
+	}
+	for _, e := range events {
+		if err := cw.Write([]string{
+			e.Start.Format(time.RFC3339),
+			e.End.Format(time.RFC3339),
+			e.Cron,
+			e.Agent,
+			e.Tenant,
+			strconv.FormatFloat(e.Cost, 'f', -1, 64),
+			strconv.Itoa(e.Tokens),
+			e.Model,
+			e.SessionID,
+			e.UID,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escape escapes an iCalendar TEXT value.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// maxLineOctets is the longest content line RFC 5545 allows before
+// folding, excluding the CRLF.
+const maxLineOctets = 75
+
+// fold writes a content line, folded into CRLF-terminated lines of at
+// most maxLineOctets octets without splitting a UTF-8 sequence.
+func fold(b *strings.Builder, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = maxLineOctets - 1 // continuation lines start with a space
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,91 @@
+package calendar
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestEvents(t *testing.T) {
+	start := time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC)
+	sessions := []parser.Session{
+		{ID: "r2", Agent: "urza", Tenant: "acme", Type: parser.SessionTypeCron, CronName: "backup",
+			StartedAt: start.Add(24 * time.Hour), EndedAt: start.Add(24*time.Hour + 10*time.Minute),
+			Usage: parser.Usage{Total: 4000, CostTotal: 0.42, Model: "claude-sonnet-4-5"}},
+		// An instant run is stretched to a minute.
+		{ID: "r1", Agent: "urza", Type: parser.SessionTypeCron, CronName: "backup", StartedAt: start, EndedAt: start},
+		{ID: "chat", Agent: "urza", Type: parser.SessionTypeInteractive, StartedAt: start},
+		{ID: "undated", Agent: "urza", Type: parser.SessionTypeCron, CronName: "backup"},
+	}
+
+	events := Events(sessions, "host-1")
+	if len(events) != 2 {
+		t.Fatalf("expected the 2 dated cron runs, got %+v", events)
+	}
+	if e := events[0]; e.SessionID != "r1" || !e.End.Equal(start.Add(time.Minute)) || e.UID != "urza/r1@host-1" {
+		t.Errorf("unexpected first event %+v", e)
+	}
+	if e := events[1]; e.UID != "acme/urza/r2@host-1" || e.Cost != 0.42 || e.Summary() != "backup $0.42" {
+		t.Errorf("unexpected second event %+v", e)
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	start := time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC)
+	events := []Event{{
+		UID:   "urza/r1@host-1",
+		Start: start, End: start.Add(5 * time.Minute),
+		Cron: "backup, nightly", Agent: "urza", Cost: 1.5, Tokens: 1200,
+		Model: "anthropic/claude-opus-4-6", SessionID: "agent:urza:cron:backup-nightly-0123456789abcdef0123456789abcdef:run:r1",
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, events, start.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"DTSTAMP:20261015T030000Z\r\n",
+		"DTSTART:20261015T020000Z\r\n",
+		"DTEND:20261015T020500Z\r\n",
+		`SUMMARY:backup\, nightly $1.50` + "\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line longer than %d octets: %q", maxLineOctets, line)
+		}
+	}
+	// Unfolding restores the long description.
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	if !strings.Contains(unfolded, `\nSession: agent:urza:cron:backup-nightly-0123456789abcdef0123456789abcdef:run:r1`) {
+		t.Errorf("expected the session in the unfolded description:\n%s", unfolded)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	start := time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []Event{{UID: "urza/r1@host-1", Start: start, End: start.Add(time.Minute), Cron: "backup", Agent: "urza", Cost: 0.25, Tokens: 10, SessionID: "r1"}}); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || len(rows[1]) != len(Columns) {
+		t.Fatalf("expected a header and one row, got %v", rows)
+	}
+	if rows[1][0] != "2026-10-15T02:00:00Z" || rows[1][2] != "backup" || rows[1][5] != "0.25" {
+		t.Errorf("unexpected row %v", rows[1])
+	}
+}
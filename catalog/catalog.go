@@ -0,0 +1,196 @@
+// Package catalog holds pricing and lifecycle metadata for known models.
+package catalog
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Model describes a model's list pricing and deprecation status.
+// Prices are in dollars per million tokens.
+type Model struct {
+	ID              string
+	Provider        string
+	InputPrice      float64
+	OutputPrice     float64
+	CacheReadPrice  float64
+	CacheWritePrice float64
+	Deprecated      bool
+	RetiresAt       time.Time // zero if no retirement date has been announced
+	Replacement     string    // suggested migration target
+	// PriceHistory lists superseded rates, so historical sessions are
+	// priced at the rates in effect when they ran. The flat price fields
+	// apply outside every range.
+	PriceHistory []Price
+}
+
+// Price is a set of rates effective over [EffectiveFrom, EffectiveUntil).
+// A zero bound is open-ended.
+type Price struct {
+	Input          float64
+	Output         float64
+	CacheRead      float64
+	CacheWrite     float64
+	EffectiveFrom  time.Time
+	EffectiveUntil time.Time
+}
+
+// In reports whether the price was in effect at t.
+func (p Price) In(t time.Time) bool {
+	if !p.EffectiveFrom.IsZero() && t.Before(p.EffectiveFrom) {
+		return false
+	}
+	if !p.EffectiveUntil.IsZero() && !t.Before(p.EffectiveUntil) {
+		return false
+	}
+	return true
+}
+
+// Lifecycle statuses returned by Model.Status.
+const (
+	StatusActive     = "active"
+	StatusDeprecated = "deprecated"
+	StatusRetiring   = "retiring"
+	StatusRetired    = "retired"
+)
+
+// RetiringWindow is how far ahead of a retirement date a model is reported
+// as retiring rather than merely deprecated.
+const RetiringWindow = 90 * 24 * time.Hour
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// models is the built-in catalog. IDs are matched as prefixes, so dated
+// snapshots (claude-3-opus-20240229) resolve to their family entry.
+var models = []Model{
+	// Anthropic
+	{ID: "claude-opus-4-6", Provider: "anthropic", InputPrice: 5, OutputPrice: 25, CacheReadPrice: 0.5, CacheWritePrice: 6.25},
+	{ID: "claude-opus-4-5", Provider: "anthropic", InputPrice: 5, OutputPrice: 25, CacheReadPrice: 0.5, CacheWritePrice: 6.25},
+	{ID: "claude-opus-4-1", Provider: "anthropic", InputPrice: 15, OutputPrice: 75, CacheReadPrice: 1.5, CacheWritePrice: 18.75},
+	{ID: "claude-opus-4", Provider: "anthropic", InputPrice: 15, OutputPrice: 75, CacheReadPrice: 1.5, CacheWritePrice: 18.75},
+	{ID: "claude-sonnet-4-5", Provider: "anthropic", InputPrice: 3, OutputPrice: 15, CacheReadPrice: 0.3, CacheWritePrice: 3.75},
+	{ID: "claude-sonnet-4", Provider: "anthropic", InputPrice: 3, OutputPrice: 15, CacheReadPrice: 0.3, CacheWritePrice: 3.75},
+	{ID: "claude-haiku-4-5", Provider: "anthropic", InputPrice: 1, OutputPrice: 5, CacheReadPrice: 0.1, CacheWritePrice: 1.25},
+	{ID: "claude-3-7-sonnet", Provider: "anthropic", InputPrice: 3, OutputPrice: 15, CacheReadPrice: 0.3, CacheWritePrice: 3.75,
+		Deprecated: true, RetiresAt: date(2026, time.February, 19), Replacement: "claude-sonnet-4-5"},
+	{ID: "claude-3-5-haiku", Provider: "anthropic", InputPrice: 0.8, OutputPrice: 4, CacheReadPrice: 0.08, CacheWritePrice: 1,
+		Deprecated: true, RetiresAt: date(2026, time.February, 19), Replacement: "claude-haiku-4-5"},
+	{ID: "claude-3-5-sonnet", Provider: "anthropic", InputPrice: 3, OutputPrice: 15, CacheReadPrice: 0.3, CacheWritePrice: 3.75,
+		Deprecated: true, RetiresAt: date(2025, time.October, 22), Replacement: "claude-sonnet-4-5"},
+	{ID: "claude-3-opus", Provider: "anthropic", InputPrice: 15, OutputPrice: 75, CacheReadPrice: 1.5, CacheWritePrice: 18.75,
+		Deprecated: true, RetiresAt: date(2026, time.January, 5), Replacement: "claude-opus-4-5"},
+	{ID: "claude-3-haiku", Provider: "anthropic", InputPrice: 0.25, OutputPrice: 1.25, CacheReadPrice: 0.03, CacheWritePrice: 0.3},
+
+	// Moonshot
+	{ID: "kimi-k2.5", Provider: "moonshotai", InputPrice: 0.6, OutputPrice: 3, CacheReadPrice: 0.1},
+	{ID: "kimi-k2", Provider: "moonshotai", InputPrice: 0.6, OutputPrice: 2.5, CacheReadPrice: 0.15,
+		Deprecated: true, Replacement: "kimi-k2.5"},
+
+	// OpenAI
+	{ID: "gpt-4o-mini", Provider: "openai", InputPrice: 0.15, OutputPrice: 0.6, CacheReadPrice: 0.075},
+	{ID: "gpt-4o", Provider: "openai", InputPrice: 2.5, OutputPrice: 10, CacheReadPrice: 1.25,
+		PriceHistory: []Price{
+			// The gpt-4o alias pointed at gpt-4o-2024-05-13 until 2024-10-02.
+			{Input: 5, Output: 15, EffectiveUntil: date(2024, time.October, 2)},
+		}},
+}
+
+// byPrefixLength holds catalog entries ordered longest ID first so that
+// Lookup picks the most specific match.
+var byPrefixLength = func() []Model {
+	sorted := append([]Model(nil), models...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].ID) > len(sorted[j].ID)
+	})
+	return sorted
+}()
+
+// Lookup resolves a model identifier as it appears in transcripts
+// (optionally provider-prefixed, e.g. "moonshotai/kimi-k2.5") to its
+// catalog entry.
+func Lookup(model string) (Model, bool) {
+	id := normalize(model)
+	if id == "" {
+		return Model{}, false
+	}
+	for _, m := range byPrefixLength {
+		if id == m.ID || strings.HasPrefix(id, m.ID+"-") || strings.HasPrefix(id, m.ID+"@") {
+			return m, true
+		}
+	}
+	return Model{}, false
+}
+
+// All returns a copy of the catalog.
+func All() []Model {
+	return append([]Model(nil), models...)
+}
+
+func normalize(model string) string {
+	id := strings.ToLower(strings.TrimSpace(model))
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		id = id[i+1:]
+	}
+	return id
+}
+
+// Status reports the lifecycle status of the model at the given instant.
+func (m Model) Status(now time.Time) string {
+	if !m.RetiresAt.IsZero() {
+		if !now.Before(m.RetiresAt) {
+			return StatusRetired
+		}
+		if m.RetiresAt.Sub(now) <= RetiringWindow {
+			return StatusRetiring
+		}
+	}
+	if m.Deprecated {
+		return StatusDeprecated
+	}
+	return StatusActive
+}
+
+// CurrentPrice returns the model's current list rates.
+func (m Model) CurrentPrice() Price {
+	return Price{
+		Input:      m.InputPrice,
+		Output:     m.OutputPrice,
+		CacheRead:  m.CacheReadPrice,
+		CacheWrite: m.CacheWritePrice,
+	}
+}
+
+// PriceAt returns the rates in effect at t. A zero t (unknown session
+// time) yields the current rates.
+func (m Model) PriceAt(t time.Time) Price {
+	if !t.IsZero() {
+		for _, p := range m.PriceHistory {
+			if p.In(t) {
+				return p
+			}
+		}
+	}
+	return m.CurrentPrice()
+}
+
+// EstimateCost prices the given token counts at the model's current list
+// rates.
+func (m Model) EstimateCost(input, output, cacheRead, cacheWrite int) float64 {
+	return m.CurrentPrice().Cost(input, output, cacheRead, cacheWrite)
+}
+
+// EstimateCostAt prices the given token counts at the rates in effect at t.
+func (m Model) EstimateCostAt(t time.Time, input, output, cacheRead, cacheWrite int) float64 {
+	return m.PriceAt(t).Cost(input, output, cacheRead, cacheWrite)
+}
+
+// Cost prices the given token counts at these rates.
+func (p Price) Cost(input, output, cacheRead, cacheWrite int) float64 {
+	return (float64(input)*p.Input +
+		float64(output)*p.Output +
+		float64(cacheRead)*p.CacheRead +
+		float64(cacheWrite)*p.CacheWrite) / 1_000_000
+}
@@ -0,0 +1,125 @@
+package catalog
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		model  string
+		wantID string
+		found  bool
+	}{
+		{"claude-opus-4-6", "claude-opus-4-6", true},
+		{"claude-opus-4-20250514", "claude-opus-4", true},
+		{"claude-3-opus-20240229", "claude-3-opus", true},
+		{"moonshotai/kimi-k2.5", "kimi-k2.5", true},
+		{"moonshotai/kimi-k2-0905", "kimi-k2", true},
+		{"Anthropic/Claude-Sonnet-4-5", "claude-sonnet-4-5", true},
+		{"gpt-4o-mini-2024-07-18", "gpt-4o-mini", true},
+		{"some-local-model", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			m, ok := Lookup(tt.model)
+			if ok != tt.found {
+				t.Fatalf("Lookup(%q) found = %v, want %v", tt.model, ok, tt.found)
+			}
+			if m.ID != tt.wantID {
+				t.Errorf("Lookup(%q) = %q, want %q", tt.model, m.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	retires := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		model    Model
+		now      time.Time
+		expected string
+	}{
+		{"active", Model{}, retires, StatusActive},
+		{"deprecated without date", Model{Deprecated: true}, retires, StatusDeprecated},
+		{"deprecated far from retirement", Model{Deprecated: true, RetiresAt: retires}, retires.AddDate(0, -6, 0), StatusDeprecated},
+		{"retiring soon", Model{Deprecated: true, RetiresAt: retires}, retires.AddDate(0, 0, -30), StatusRetiring},
+		{"retired", Model{Deprecated: true, RetiresAt: retires}, retires, StatusRetired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.model.Status(tt.now); got != tt.expected {
+				t.Errorf("Status() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	m := Model{InputPrice: 3, OutputPrice: 15, CacheReadPrice: 0.3, CacheWritePrice: 3.75}
+	got := m.EstimateCost(1_000_000, 100_000, 2_000_000, 0)
+	expected := 3 + 1.5 + 0.6
+	if math.Abs(got-expected) > 1e-9 {
+		t.Errorf("EstimateCost() = %f, want %f", got, expected)
+	}
+}
+
+func TestReplacementsResolve(t *testing.T) {
+	for _, m := range All() {
+		if m.Replacement == "" {
+			continue
+		}
+		if _, ok := Lookup(m.Replacement); !ok {
+			t.Errorf("%s: replacement %q is not in the catalog", m.ID, m.Replacement)
+		}
+	}
+}
+
+func TestPriceAt(t *testing.T) {
+	change := date(2025, time.March, 1)
+	m := Model{InputPrice: 2, OutputPrice: 8, PriceHistory: []Price{
+		{Input: 4, Output: 16, EffectiveUntil: change},
+	}}
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		expected float64
+	}{
+		{"before change", change.Add(-time.Second), 4},
+		{"at change", change, 2},
+		{"after change", change.AddDate(1, 0, 0), 2},
+		{"unknown time", time.Time{}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.PriceAt(tt.at).Input; got != tt.expected {
+				t.Errorf("PriceAt().Input = %f, want %f", got, tt.expected)
+			}
+		})
+	}
+
+	got := m.EstimateCostAt(change.AddDate(0, 0, -1), 1_000_000, 1_000_000, 0, 0)
+	if math.Abs(got-20) > 1e-9 {
+		t.Errorf("EstimateCostAt() = %f, want 20", got)
+	}
+}
+
+func TestPriceHistoryRanges(t *testing.T) {
+	for _, m := range All() {
+		for _, p := range m.PriceHistory {
+			if p.EffectiveUntil.IsZero() {
+				t.Errorf("%s: superseded price has no end date", m.ID)
+			}
+			if !p.EffectiveFrom.IsZero() && !p.EffectiveFrom.Before(p.EffectiveUntil) {
+				t.Errorf("%s: price range %s..%s is empty", m.ID, p.EffectiveFrom, p.EffectiveUntil)
+			}
+		}
+	}
+}
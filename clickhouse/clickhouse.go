@@ -0,0 +1,436 @@
+// Package clickhouse exports cost data to ClickHouse over its native TCP
+// protocol. It implements just enough of the protocol to run statements and
+// insert uncompressed blocks of String, UInt64, Float64, Date and DateTime
+// columns, which keeps the binary free of a driver dependency.
+package clickhouse
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Default native protocol ports.
+const (
+	defaultPort       = "9000"
+	defaultSecurePort = "9440"
+)
+
+// dialTimeout bounds connecting when the context has no deadline.
+const dialTimeout = 10 * time.Second
+
+// Options are the connection settings parsed from a DSN.
+type Options struct {
+	Addr     string // host:port
+	Database string
+	User     string
+	Password string
+	Secure   bool // TLS
+}
+
+// ParseDSN parses clickhouse://[user[:password]@]host[:port][/database],
+// with optional ?secure=true for TLS. The user and database default to
+// "default"; the port to 9000, or 9440 with TLS.
+func ParseDSN(dsn string) (Options, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return Options{}, fmt.Errorf("invalid clickhouse DSN: %w", err)
+	}
+	if u.Scheme != "clickhouse" || u.Host == "" {
+		return Options{}, fmt.Errorf("invalid clickhouse DSN %q: expected clickhouse://host[:port][/database]", dsn)
+	}
+
+	opts := Options{
+		Database: strings.Trim(u.Path, "/"),
+		User:     u.User.Username(),
+		Secure:   u.Query().Get("secure") == "true",
+	}
+	opts.Password, _ = u.User.Password()
+	if opts.Database == "" {
+		opts.Database = "default"
+	}
+	if opts.User == "" {
+		opts.User = "default"
+	}
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+		if opts.Secure {
+			port = defaultSecurePort
+		}
+	}
+	opts.Addr = net.JoinHostPort(u.Hostname(), port)
+	return opts, nil
+}
+
+// Column is a column of an inserted block.
+type Column struct {
+	Name string
+	Type string // String, UInt64, Float64, Date or DateTime
+}
+
+// Conn is a connection to a ClickHouse server. It is not safe for
+// concurrent use, and should be closed after any error.
+type Conn struct {
+	conn     net.Conn
+	enc      encoder
+	dec      decoder
+	revision uint64
+	// Server is the server's name and version, e.g. "ClickHouse 24.8".
+	Server string
+}
+
+// Open connects to the server at dsn (see ParseDSN) and authenticates.
+func Open(ctx context.Context, dsn string) (*Conn, error) {
+	opts, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var conn net.Conn
+	if opts.Secure {
+		host, _, _ := net.SplitHostPort(opts.Addr)
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", opts.Addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", opts.Addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to clickhouse: %w", err)
+	}
+
+	c := newConn(conn)
+	if err := c.hello(ctx, opts); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func newConn(conn net.Conn) *Conn {
+	return &Conn{
+		conn: conn,
+		enc:  encoder{w: bufio.NewWriter(conn)},
+		dec:  decoder{r: bufio.NewReader(conn)},
+	}
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// watch applies ctx's deadline and cancellation to the connection until
+// the returned function is called.
+func (c *Conn) watch(ctx context.Context) (stop func()) {
+	deadline, _ := ctx.Deadline()
+	c.conn.SetDeadline(deadline)
+	cancel := context.AfterFunc(ctx, func() {
+		c.conn.SetDeadline(time.Unix(1, 0))
+	})
+	return func() { cancel() }
+}
+
+// wrap prefers the context's error to the I/O error it caused.
+func wrap(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	var exception *Exception
+	if errors.As(err, &exception) {
+		return err
+	}
+	return fmt.Errorf("clickhouse: %w", err)
+}
+
+// hello performs the handshake and authenticates.
+func (c *Conn) hello(ctx context.Context, opts Options) error {
+	defer c.watch(ctx)()
+
+	c.enc.uvarint(clientHello)
+	c.enc.string("costctl")
+	c.enc.uvarint(1) // client version major
+	c.enc.uvarint(0) // client version minor
+	c.enc.uvarint(revision)
+	c.enc.string(opts.Database)
+	c.enc.string(opts.User)
+	c.enc.string(opts.Password)
+	if err := c.enc.flush(); err != nil {
+		return wrap(ctx, err)
+	}
+
+	packet, err := c.dec.uvarint()
+	if err != nil {
+		return wrap(ctx, err)
+	}
+	switch packet {
+	case serverHello:
+	case serverException:
+		exception, err := c.dec.exception()
+		if err != nil {
+			return wrap(ctx, err)
+		}
+		return exception
+	default:
+		return fmt.Errorf("clickhouse: unexpected packet %d in handshake", packet)
+	}
+
+	name, err := c.dec.string()
+	if err != nil {
+		return wrap(ctx, err)
+	}
+	var version [3]uint64 // major, minor, revision
+	for i := range version {
+		if version[i], err = c.dec.uvarint(); err != nil {
+			return wrap(ctx, err)
+		}
+	}
+	c.revision = min(version[2], revision)
+	if c.revision >= revisionServerTimezone {
+		if _, err := c.dec.string(); err != nil {
+			return wrap(ctx, err)
+		}
+	}
+	c.Server = fmt.Sprintf("%s %d.%d", name, version[0], version[1])
+	return nil
+}
+
+// Exec runs a statement that returns no rows, such as CREATE TABLE.
+func (c *Conn) Exec(ctx context.Context, query string) error {
+	defer c.watch(ctx)()
+	if err := c.sendQuery(query); err != nil {
+		return wrap(ctx, err)
+	}
+	_, err := c.receive(serverEndOfStream)
+	return wrap(ctx, err)
+}
+
+// Insert inserts rows into table in blocks of at most batchSize rows.
+// Each row holds one value per column: a string, an int, a float64 or a
+// time.Time, matching the column's type. The columns must match the
+// table's, in order.
+func (c *Conn) Insert(ctx context.Context, table string, columns []Column, rows [][]any, batchSize int) error {
+	if batchSize < 1 {
+		return fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+	defer c.watch(ctx)()
+
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.Name
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES", table, strings.Join(names, ", "))
+	if err := c.sendQuery(query); err != nil {
+		return wrap(ctx, err)
+	}
+
+	// The server replies with the structure of the block it expects.
+	header, err := c.receive(serverData)
+	if err != nil {
+		return wrap(ctx, err)
+	}
+	if len(header) != len(columns) {
+		return fmt.Errorf("clickhouse: table %s expects %d columns, got %d", table, len(header), len(columns))
+	}
+	for i, col := range header {
+		if col.Type != columns[i].Type {
+			return fmt.Errorf("clickhouse: column %s of table %s is %s, not %s", col.Name, table, col.Type, columns[i].Type)
+		}
+	}
+
+	for start := 0; start < len(rows); start += batchSize {
+		end := min(start+batchSize, len(rows))
+		if err := c.writeBlock(columns, rows[start:end]); err != nil {
+			return wrap(ctx, err)
+		}
+		if err := c.enc.flush(); err != nil {
+			return wrap(ctx, err)
+		}
+	}
+	// An empty block ends the insert.
+	if err := c.writeBlock(nil, nil); err != nil {
+		return wrap(ctx, err)
+	}
+	if err := c.enc.flush(); err != nil {
+		return wrap(ctx, err)
+	}
+	_, err = c.receive(serverEndOfStream)
+	return wrap(ctx, err)
+}
+
+// sendQuery sends a query followed by the empty block that ends its
+// (absent) external tables.
+func (c *Conn) sendQuery(query string) error {
+	hostname, _ := os.Hostname()
+
+	c.enc.uvarint(clientQuery)
+	c.enc.string("") // query ID, assigned by the server
+	// Client info.
+	c.enc.uint8(1)   // initial query
+	c.enc.string("") // initial user
+	c.enc.string("") // initial query ID
+	c.enc.string(c.conn.LocalAddr().String())
+	c.enc.uint8(1) // TCP interface
+	c.enc.string(os.Getenv("USER"))
+	c.enc.string(hostname)
+	c.enc.string("costctl")
+	c.enc.uvarint(1) // client version major
+	c.enc.uvarint(0) // client version minor
+	c.enc.uvarint(revision)
+	if c.revision >= revisionQuotaKey {
+		c.enc.string("") // quota key
+	}
+	c.enc.string("") // end of settings
+	c.enc.uvarint(stageComplete)
+	c.enc.uvarint(compressionDisable)
+	c.enc.string(query)
+
+	if err := c.writeBlock(nil, nil); err != nil {
+		return err
+	}
+	return c.enc.flush()
+}
+
+// writeBlock writes a data packet holding rows, column by column.
+func (c *Conn) writeBlock(columns []Column, rows [][]any) error {
+	c.enc.uvarint(clientData)
+	c.enc.string("") // temporary table name
+	// Block info: not an overflow block, no bucket.
+	c.enc.uvarint(1)
+	c.enc.uint8(0)
+	c.enc.uvarint(2)
+	c.enc.uint32(0xFFFFFFFF) // bucket -1
+	c.enc.uvarint(0)
+
+	c.enc.uvarint(uint64(len(columns)))
+	c.enc.uvarint(uint64(len(rows)))
+	for i, col := range columns {
+		c.enc.string(col.Name)
+		c.enc.string(col.Type)
+		for _, row := range rows {
+			if len(row) != len(columns) {
+				return fmt.Errorf("row has %d values for %d columns", len(row), len(columns))
+			}
+			if err := c.enc.value(col.Type, row[i]); err != nil {
+				return fmt.Errorf("column %s: %w", col.Name, err)
+			}
+		}
+	}
+	return c.enc.err
+}
+
+// receive reads server packets until one of type want arrives, returning
+// the columns of the block when want is serverData.
+func (c *Conn) receive(want uint64) ([]Column, error) {
+	for {
+		packet, err := c.dec.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		switch packet {
+		case serverData, serverTotals, serverExtremes:
+			columns, err := c.readBlock()
+			if err != nil {
+				return nil, err
+			}
+			if packet == want {
+				return columns, nil
+			}
+		case serverException:
+			exception, err := c.dec.exception()
+			if err != nil {
+				return nil, err
+			}
+			return nil, exception
+		case serverProgress:
+			// Rows, bytes and total rows.
+			for i := 0; i < 3; i++ {
+				if _, err := c.dec.uvarint(); err != nil {
+					return nil, err
+				}
+			}
+		case serverProfileInfo:
+			// Rows, blocks, bytes, applied limit, rows before limit, and
+			// whether rows before limit were calculated.
+			for _, varint := range []bool{true, true, true, false, true, false} {
+				if varint {
+					_, err = c.dec.uvarint()
+				} else {
+					_, err = c.dec.bool()
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+		case serverPong:
+		case serverEndOfStream:
+			if want == serverEndOfStream {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unexpected end of stream")
+		default:
+			return nil, fmt.Errorf("unexpected packet %d", packet)
+		}
+	}
+}
+
+// readBlock reads a data block, discarding its values.
+func (c *Conn) readBlock() ([]Column, error) {
+	if _, err := c.dec.string(); err != nil { // temporary table name
+		return nil, err
+	}
+	// Block info fields, terminated by field 0.
+	for {
+		field, err := c.dec.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		switch field {
+		case 0:
+		case 1:
+			_, err = c.dec.bool()
+		case 2:
+			_, err = c.dec.int32()
+		default:
+			return nil, fmt.Errorf("unknown block info field %d", field)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if field == 0 {
+			break
+		}
+	}
+
+	numColumns, err := c.dec.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	numRows, err := c.dec.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]Column, numColumns)
+	for i := range columns {
+		if columns[i].Name, err = c.dec.string(); err != nil {
+			return nil, err
+		}
+		if columns[i].Type, err = c.dec.string(); err != nil {
+			return nil, err
+		}
+		if err := c.dec.skip(columns[i].Type, numRows); err != nil {
+			return nil, err
+		}
+	}
+	return columns, nil
+}
@@ -0,0 +1,426 @@
+package clickhouse
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// fakeServer speaks the server side of the native protocol, recording the
+// statements it runs and the blocks inserted into each table.
+type fakeServer struct {
+	t       *testing.T
+	enc     encoder
+	dec     decoder
+	tables  map[string][]Column
+	queries []string
+	blocks  map[string][][][]any
+	user    string
+}
+
+func startFake(t *testing.T, tables map[string][]Column) (string, chan *fakeServer) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	done := make(chan *fakeServer, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		s := &fakeServer{
+			t:      t,
+			enc:    encoder{w: bufio.NewWriter(conn)},
+			dec:    decoder{r: bufio.NewReader(conn)},
+			tables: tables,
+			blocks: make(map[string][][][]any),
+		}
+		if err := s.serve(); err != nil && !errors.Is(err, io.EOF) {
+			t.Errorf("fake server: %v", err)
+		}
+		done <- s
+	}()
+	return ln.Addr().String(), done
+}
+
+func (s *fakeServer) strings(n int) ([]string, error) {
+	values := make([]string, n)
+	for i := range values {
+		var err error
+		if values[i], err = s.dec.string(); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func (s *fakeServer) serve() error {
+	if packet, err := s.dec.uvarint(); err != nil || packet != clientHello {
+		return errors.Join(err, errors.New("expected hello"))
+	}
+	hello, err := s.strings(1)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < 3; i++ {
+		s.dec.uvarint()
+	}
+	auth, err := s.strings(3) // database, user, password
+	if err != nil {
+		return err
+	}
+	s.user = auth[1]
+	if hello[0] != "costctl" || auth[2] != "secret" {
+		s.enc.uvarint(serverException)
+		s.writeException("Authentication failed")
+		return s.enc.flush()
+	}
+	s.enc.uvarint(serverHello)
+	s.enc.string("ClickHouse")
+	s.enc.uvarint(24)
+	s.enc.uvarint(8)
+	s.enc.uvarint(54470)
+	s.enc.string("UTC")
+	if err := s.enc.flush(); err != nil {
+		return err
+	}
+
+	for {
+		query, err := s.readQuery()
+		if err != nil {
+			return err
+		}
+		s.queries = append(s.queries, query)
+		if _, err := s.readBlock(); err != nil {
+			return err
+		}
+
+		switch {
+		case strings.Contains(query, "fail"):
+			s.enc.uvarint(serverException)
+			s.writeException("Syntax error")
+		case strings.HasPrefix(query, "INSERT INTO "):
+			table, _, _ := strings.Cut(strings.TrimPrefix(query, "INSERT INTO "), " ")
+			s.enc.uvarint(serverData)
+			s.enc.string("")
+			s.enc.uvarint(0)
+			s.enc.uvarint(uint64(len(s.tables[table])))
+			s.enc.uvarint(0)
+			for _, col := range s.tables[table] {
+				s.enc.string(col.Name)
+				s.enc.string(col.Type)
+			}
+			if err := s.enc.flush(); err != nil {
+				return err
+			}
+			for {
+				rows, err := s.readBlock()
+				if err != nil {
+					return err
+				}
+				if rows == nil {
+					break
+				}
+				s.blocks[table] = append(s.blocks[table], rows)
+			}
+			s.enc.uvarint(serverProgress)
+			s.enc.uvarint(1)
+			s.enc.uvarint(1)
+			s.enc.uvarint(0)
+			s.enc.uvarint(serverEndOfStream)
+		default:
+			s.enc.uvarint(serverEndOfStream)
+		}
+		if err := s.enc.flush(); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *fakeServer) writeException(message string) {
+	s.enc.uint32(516)
+	s.enc.string("DB::Exception")
+	s.enc.string(message)
+	s.enc.string("")
+	s.enc.uint8(0)
+}
+
+func (s *fakeServer) readQuery() (string, error) {
+	packet, err := s.dec.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if packet != clientQuery {
+		return "", errors.New("expected query")
+	}
+	if _, err := s.strings(1); err != nil { // query ID
+		return "", err
+	}
+	s.dec.r.ReadByte() // query kind
+	if _, err := s.strings(3); err != nil {
+		return "", err
+	}
+	s.dec.r.ReadByte() // interface
+	if _, err := s.strings(3); err != nil {
+		return "", err
+	}
+	for i := 0; i < 3; i++ {
+		s.dec.uvarint()
+	}
+	if _, err := s.strings(2); err != nil { // quota key, end of settings
+		return "", err
+	}
+	s.dec.uvarint() // stage
+	s.dec.uvarint() // compression
+	query, err := s.dec.string()
+	return query, err
+}
+
+// readBlock reads a client data block, returning nil for an empty one.
+func (s *fakeServer) readBlock() ([][]any, error) {
+	if packet, err := s.dec.uvarint(); err != nil || packet != clientData {
+		return nil, errors.Join(err, errors.New("expected data"))
+	}
+	s.dec.string()
+	s.dec.uvarint()
+	s.dec.bool()
+	s.dec.uvarint()
+	s.dec.int32()
+	s.dec.uvarint()
+	numColumns, _ := s.dec.uvarint()
+	numRows, err := s.dec.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if numRows == 0 {
+		return nil, nil
+	}
+	rows := make([][]any, numRows)
+	for i := range rows {
+		rows[i] = make([]any, numColumns)
+	}
+	var buf [8]byte
+	for c := 0; c < int(numColumns); c++ {
+		if _, err := s.strings(1); err != nil {
+			return nil, err
+		}
+		typ, err := s.dec.string()
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			switch typ {
+			case "String":
+				row[c], err = s.dec.string()
+			case "Date":
+				_, err = io.ReadFull(s.dec.r, buf[:2])
+				row[c] = epoch.AddDate(0, 0, int(binary.LittleEndian.Uint16(buf[:2])))
+			case "DateTime":
+				_, err = io.ReadFull(s.dec.r, buf[:4])
+				row[c] = time.Unix(int64(binary.LittleEndian.Uint32(buf[:4])), 0).UTC()
+			case "UInt64":
+				_, err = io.ReadFull(s.dec.r, buf[:8])
+				row[c] = int(binary.LittleEndian.Uint64(buf[:8]))
+			case "Float64":
+				_, err = io.ReadFull(s.dec.r, buf[:8])
+				row[c] = math.Float64frombits(binary.LittleEndian.Uint64(buf[:8]))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return rows, nil
+}
+
+func TestParseDSN(t *testing.T) {
+	tests := []struct {
+		dsn  string
+		want Options
+	}{
+		{"clickhouse://localhost", Options{Addr: "localhost:9000", Database: "default", User: "default"}},
+		{"clickhouse://bob:pw@ch:9001/costs", Options{Addr: "ch:9001", Database: "costs", User: "bob", Password: "pw"}},
+		{"clickhouse://ch/costs?secure=true", Options{Addr: "ch:9440", Database: "costs", User: "default", Secure: true}},
+	}
+	for _, tt := range tests {
+		got, err := ParseDSN(tt.dsn)
+		if err != nil {
+			t.Errorf("ParseDSN(%q) failed: %v", tt.dsn, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDSN(%q) = %+v, want %+v", tt.dsn, got, tt.want)
+		}
+	}
+
+	for _, dsn := range []string{"postgres://localhost/costs", "clickhouse:///costs", "localhost:9000"} {
+		if _, err := ParseDSN(dsn); err == nil {
+			t.Errorf("ParseDSN(%q): expected an error", dsn)
+		}
+	}
+}
+
+func TestOpenAuthenticationFailure(t *testing.T) {
+	addr, _ := startFake(t, nil)
+	_, err := Open(context.Background(), "clickhouse://bob:wrong@"+addr)
+	var exception *Exception
+	if !errors.As(err, &exception) || exception.Message != "Authentication failed" {
+		t.Errorf("expected an authentication exception, got %v", err)
+	}
+}
+
+func TestExport(t *testing.T) {
+	addr, done := startFake(t, map[string][]Column{SessionsTable: sessionColumns, DailyTable: dailyColumns})
+	conn, err := Open(context.Background(), "clickhouse://bob:secret@"+addr+"/costs")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if conn.Server != "ClickHouse 24.8" {
+		t.Errorf("Server = %q", conn.Server)
+	}
+
+	day := time.Date(2026, 6, 10, 9, 0, 0, 0, time.UTC)
+	sessions := []parser.Session{
+		{ID: "a", Agent: "ops", Type: parser.SessionTypeCron, CronName: "sync", StartedAt: day, Usage: parser.Usage{CostTotal: 0.5, Total: 100, Model: "m1"}},
+		{ID: "b", Agent: "ops", Type: parser.SessionTypeCron, CronName: "sync", StartedAt: day.Add(time.Hour), Usage: parser.Usage{CostTotal: 0.25, Total: 50, Model: "m1"}},
+		{ID: "c", Agent: "ops", Type: parser.SessionTypeInteractive, StartedAt: day, Usage: parser.Usage{CostTotal: 1}},
+		{ID: "undated", Agent: "ops", Usage: parser.Usage{CostTotal: 2}},
+	}
+	result, err := Export(context.Background(), conn, sessions, "host1", 2)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if result.Sessions != 3 || result.Daily != 2 {
+		t.Errorf("expected 3 session rows and 2 daily rows, got %+v", result)
+	}
+
+	if err := conn.Exec(context.Background(), "please fail"); err == nil || !strings.Contains(err.Error(), "Syntax error") {
+		t.Errorf("expected a server exception, got %v", err)
+	}
+	conn.Close()
+
+	s := <-done
+	if s.user != "bob" || len(s.queries) != 5 || !strings.HasPrefix(s.queries[0], "CREATE TABLE IF NOT EXISTS "+SessionsTable) {
+		t.Errorf("unexpected queries by %s: %q", s.user, s.queries)
+	}
+	// Three session rows in blocks of two.
+	if blocks := s.blocks[SessionsTable]; len(blocks) != 2 || len(blocks[0]) != 2 || len(blocks[1]) != 1 {
+		t.Fatalf("unexpected session blocks: %v", blocks)
+	}
+	first := s.blocks[SessionsTable][0][0]
+	if first[0] != "a" || first[1] != "host1" || first[7] != day || first[10] != 0.5 || first[13] != 100 {
+		t.Errorf("unexpected session row: %v", first)
+	}
+	daily := s.blocks[DailyTable]
+	if len(daily) != 1 || len(daily[0]) != 2 {
+		t.Fatalf("unexpected daily blocks: %v", daily)
+	}
+	for _, row := range daily[0] {
+		if row[0] != time.Date(2026, 6, 10, 0, 0, 0, 0, time.UTC) {
+			t.Errorf("unexpected date in daily row: %v", row)
+		}
+		if row[5] == "sync" && (row[7] != 2 || row[8] != 0.75) {
+			t.Errorf("unexpected cron aggregate: %v", row)
+		}
+	}
+}
+
+// TestExportServer exports to the real server at $COSTCTL_CLICKHOUSE_DSN,
+// which the fake cannot stand in for: it checks the handshake, block
+// layout and value encodings against what ClickHouse actually accepts.
+// Rows are written under a unique host and deleted afterwards.
+func TestExportServer(t *testing.T) {
+	dsn := os.Getenv("COSTCTL_CLICKHOUSE_DSN")
+	if dsn == "" {
+		t.Skip("COSTCTL_CLICKHOUSE_DSN not set")
+	}
+	ctx := context.Background()
+	conn, err := Open(ctx, dsn)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer conn.Close()
+
+	host := fmt.Sprintf("costctl-test-%d", time.Now().UnixNano())
+	defer func() {
+		for _, table := range []string{SessionsTable, DailyTable} {
+			if err := conn.Exec(ctx, fmt.Sprintf("ALTER TABLE %s DELETE WHERE host = '%s'", table, host)); err != nil {
+				t.Errorf("failed to clean up %s: %v", table, err)
+			}
+		}
+	}()
+
+	day := time.Date(2026, 6, 10, 9, 0, 0, 0, time.UTC)
+	sessions := []parser.Session{
+		{ID: "a", Agent: "ops", Type: parser.SessionTypeCron, CronName: "sync", StartedAt: day, Duration: 90 * time.Second, Usage: parser.Usage{CostTotal: 0.5, Input: 60, Output: 40, Total: 100, Model: "m1"}},
+		{ID: "b", Agent: "ops", Type: parser.SessionTypeCron, CronName: "sync", StartedAt: day.Add(time.Hour), Usage: parser.Usage{CostTotal: 0.25, Total: 50, CacheRead: 7, Model: "m1"}},
+		{ID: "c", Tenant: "acme", Agent: "ops", Type: parser.SessionTypeInteractive, StartedAt: day, Usage: parser.Usage{CostTotal: 1}},
+	}
+	// Exporting twice replaces rather than duplicates, once read with FINAL.
+	for i := 0; i < 2; i++ {
+		if _, err := Export(ctx, conn, sessions, host, 2); err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+	}
+
+	// The client cannot read result values, so each check makes the server
+	// throw when it does not hold.
+	checks := []string{
+		`count() = 3`,
+		`sum(total_cost) = 1.75`,
+		`countIf(started_at = toDateTime('2026-06-10 09:00:00', 'UTC')) = 2`,
+		`sumIf(duration_ms, session_id = 'a') = 90000`,
+		`sumIf(input_tokens + output_tokens, session_id = 'a') = 100`,
+		`sumIf(cache_read, session_id = 'b') = 7`,
+		`countIf(tenant = 'acme' AND session_type = 'interactive' AND model = 'unknown') = 1`,
+	}
+	for _, check := range checks {
+		query := fmt.Sprintf("SELECT throwIf(NOT (%s), 'failed: %s') FROM %s FINAL WHERE host = '%s'", check, strings.ReplaceAll(check, "'", ""), SessionsTable, host)
+		if err := conn.Exec(ctx, query); err != nil {
+			t.Errorf("sessions: %v", err)
+		}
+	}
+	checks = []string{
+		`count() = 2`,
+		`countIf(date = toDate('2026-06-10')) = 2`,
+		`sumIf(sessions, cron_name = 'sync') = 2`,
+		`sumIf(total_cost, cron_name = 'sync') = 0.75`,
+		`sumIf(total_tokens, cron_name = 'sync') = 150`,
+	}
+	for _, check := range checks {
+		query := fmt.Sprintf("SELECT throwIf(NOT (%s), 'failed: %s') FROM %s FINAL WHERE host = '%s'", check, strings.ReplaceAll(check, "'", ""), DailyTable, host)
+		if err := conn.Exec(ctx, query); err != nil {
+			t.Errorf("daily: %v", err)
+		}
+	}
+}
+
+func TestInsertColumnMismatch(t *testing.T) {
+	addr, _ := startFake(t, map[string][]Column{"t": {{"x", "Int32"}}})
+	conn, err := Open(context.Background(), "clickhouse://bob:secret@"+addr)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer conn.Close()
+
+	err = conn.Insert(context.Background(), "t", []Column{{"x", "UInt64"}}, [][]any{{1}}, 10)
+	if err == nil || !strings.Contains(err.Error(), "is Int32, not UInt64") {
+		t.Errorf("expected a column type error, got %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/store"
+)
+
+// Exported tables. Both use ReplacingMergeTree keyed on identity, so
+// re-exporting a period replaces its rows once parts merge; query with
+// FINAL for exact figures before then.
+const (
+	SessionsTable = "costctl_sessions"
+	DailyTable    = "costctl_daily"
+)
+
+const sessionsSchema = `CREATE TABLE IF NOT EXISTS ` + SessionsTable + ` (
+	session_id    String,
+	host          String,
+	tenant        String,
+	agent         String,
+	session_type  String,
+	cron_name     String,
+	model         String,
+	started_at    DateTime,
+	duration_ms   UInt64,
+	messages      UInt64,
+	total_cost    Float64,
+	input_tokens  UInt64,
+	output_tokens UInt64,
+	total_tokens  UInt64,
+	cache_read    UInt64,
+	cache_write   UInt64
+) ENGINE = ReplacingMergeTree
+PARTITION BY toYYYYMM(started_at)
+ORDER BY (host, tenant, agent, session_id)`
+
+const dailySchema = `CREATE TABLE IF NOT EXISTS ` + DailyTable + ` (
+	date          Date,
+	host          String,
+	tenant        String,
+	agent         String,
+	session_type  String,
+	cron_name     String,
+	model         String,
+	sessions      UInt64,
+	total_cost    Float64,
+	input_tokens  UInt64,
+	output_tokens UInt64,
+	total_tokens  UInt64,
+	cache_read    UInt64,
+	cache_write   UInt64
+) ENGINE = ReplacingMergeTree
+PARTITION BY toYYYYMM(date)
+ORDER BY (date, host, tenant, agent, session_type, cron_name, model)`
+
+var sessionColumns = []Column{
+	{"session_id", "String"},
+	{"host", "String"},
+	{"tenant", "String"},
+	{"agent", "String"},
+	{"session_type", "String"},
+	{"cron_name", "String"},
+	{"model", "String"},
+	{"started_at", "DateTime"},
+	{"duration_ms", "UInt64"},
+	{"messages", "UInt64"},
+	{"total_cost", "Float64"},
+	{"input_tokens", "UInt64"},
+	{"output_tokens", "UInt64"},
+	{"total_tokens", "UInt64"},
+	{"cache_read", "UInt64"},
+	{"cache_write", "UInt64"},
+}
+
+var dailyColumns = []Column{
+	{"date", "Date"},
+	{"host", "String"},
+	{"tenant", "String"},
+	{"agent", "String"},
+	{"session_type", "String"},
+	{"cron_name", "String"},
+	{"model", "String"},
+	{"sessions", "UInt64"},
+	{"total_cost", "Float64"},
+	{"input_tokens", "UInt64"},
+	{"output_tokens", "UInt64"},
+	{"total_tokens", "UInt64"},
+	{"cache_read", "UInt64"},
+	{"cache_write", "UInt64"},
+}
+
+// Result counts the rows an export inserted.
+type Result struct {
+	Sessions int
+	Daily    int
+}
+
+// Export creates the export tables if needed and inserts one row per
+// session and one per daily aggregate, attributed to host, in blocks of
+// batchSize rows. Sessions without a start time, and those rebuilt from
+// stored aggregates, have no session row.
+func Export(ctx context.Context, conn *Conn, sessions []parser.Session, host string, batchSize int) (Result, error) {
+	for _, schema := range []string{sessionsSchema, dailySchema} {
+		if err := conn.Exec(ctx, schema); err != nil {
+			return Result{}, fmt.Errorf("failed to create table: %w", err)
+		}
+	}
+
+	sessionRows := SessionRows(sessions, host)
+	if err := conn.Insert(ctx, SessionsTable, sessionColumns, sessionRows, batchSize); err != nil {
+		return Result{}, fmt.Errorf("failed to insert sessions: %w", err)
+	}
+	dailyRows, err := DailyRows(store.Aggregate(sessions, host))
+	if err != nil {
+		return Result{}, err
+	}
+	if err := conn.Insert(ctx, DailyTable, dailyColumns, dailyRows, batchSize); err != nil {
+		return Result{}, fmt.Errorf("failed to insert daily aggregates: %w", err)
+	}
+	return Result{Sessions: len(sessionRows), Daily: len(dailyRows)}, nil
+}
+
+// SessionRows converts sessions to rows of the sessions table.
+func SessionRows(sessions []parser.Session, host string) [][]any {
+	rows := make([][]any, 0, len(sessions))
+	for _, s := range sessions {
+		if s.StartedAt.IsZero() || s.Count > 0 {
+			continue
+		}
+		model := s.Usage.Model
+		if model == "" {
+			model = "unknown"
+		}
+		rows = append(rows, []any{
+			s.ID, host, s.Tenant, s.Agent, string(s.Type), s.CronName, model,
+			s.StartedAt, int(s.Duration.Milliseconds()), len(s.Messages),
+			s.Usage.CostTotal, s.Usage.Input, s.Usage.Output, s.Usage.Total,
+			s.Usage.CacheRead, s.Usage.CacheWrite,
+		})
+	}
+	return rows
+}
+
+// DailyRows converts daily aggregates to rows of the daily table.
+func DailyRows(aggregates []store.DailyAggregate) ([][]any, error) {
+	rows := make([][]any, 0, len(aggregates))
+	for _, a := range aggregates {
+		date, err := time.Parse("2006-01-02", a.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid aggregate date %q: %w", a.Date, err)
+		}
+		rows = append(rows, []any{
+			date, a.Host, a.Tenant, a.Agent, string(a.SessionType), a.CronName, a.Model,
+			a.Sessions, a.TotalCost, a.InputTokens, a.OutputTokens, a.TotalTokens,
+			a.CacheRead, a.CacheWrite,
+		})
+	}
+	return rows, nil
+}
@@ -0,0 +1,239 @@
+package clickhouse
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// revision is the native protocol revision spoken by the client. Servers
+// negotiate down to the lower of theirs and ours, so pinning an old
+// revision keeps the handshake and packet layouts small.
+const revision = 54213
+
+// Protocol revisions that add fields to packets.
+const (
+	revisionServerTimezone = 54058
+	revisionQuotaKey       = 54060
+)
+
+// Client packet types.
+const (
+	clientHello = 0
+	clientQuery = 1
+	clientData  = 2
+)
+
+// Server packet types.
+const (
+	serverHello       = 0
+	serverData        = 1
+	serverException   = 2
+	serverProgress    = 3
+	serverPong        = 4
+	serverEndOfStream = 5
+	serverProfileInfo = 6
+	serverTotals      = 7
+	serverExtremes    = 8
+)
+
+// Query processing stage and compression method sent with queries.
+const (
+	stageComplete      = 2
+	compressionDisable = 0
+)
+
+// epoch is day zero of the Date type.
+var epoch = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// encoder writes native protocol values.
+type encoder struct {
+	w   *bufio.Writer
+	buf [8]byte
+	err error
+}
+
+func (e *encoder) write(p []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(p)
+	}
+}
+
+func (e *encoder) uvarint(v uint64) {
+	n := binary.PutUvarint(e.buf[:], v)
+	e.write(e.buf[:n])
+}
+
+func (e *encoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.write([]byte(s))
+}
+
+func (e *encoder) uint8(v uint8) {
+	e.write([]byte{v})
+}
+
+func (e *encoder) uint16(v uint16) {
+	binary.LittleEndian.PutUint16(e.buf[:2], v)
+	e.write(e.buf[:2])
+}
+
+func (e *encoder) uint32(v uint32) {
+	binary.LittleEndian.PutUint32(e.buf[:4], v)
+	e.write(e.buf[:4])
+}
+
+func (e *encoder) uint64(v uint64) {
+	binary.LittleEndian.PutUint64(e.buf[:8], v)
+	e.write(e.buf[:8])
+}
+
+func (e *encoder) flush() error {
+	if e.err == nil {
+		e.err = e.w.Flush()
+	}
+	return e.err
+}
+
+// value writes one value of a column of type typ.
+func (e *encoder) value(typ string, v any) error {
+	switch typ {
+	case "String":
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%T is not a String", v)
+		}
+		e.string(s)
+	case "UInt64":
+		n, ok := v.(int)
+		if !ok || n < 0 {
+			return fmt.Errorf("%v is not a UInt64", v)
+		}
+		e.uint64(uint64(n))
+	case "Float64":
+		f, ok := v.(float64)
+		if !ok {
+			return fmt.Errorf("%T is not a Float64", v)
+		}
+		e.uint64(math.Float64bits(f))
+	case "Date":
+		t, ok := v.(time.Time)
+		if !ok {
+			return fmt.Errorf("%T is not a Date", v)
+		}
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		e.uint16(uint16(day.Sub(epoch) / (24 * time.Hour)))
+	case "DateTime":
+		t, ok := v.(time.Time)
+		if !ok {
+			return fmt.Errorf("%T is not a DateTime", v)
+		}
+		e.uint32(uint32(t.Unix()))
+	default:
+		return fmt.Errorf("unsupported column type %s", typ)
+	}
+	return nil
+}
+
+// decoder reads native protocol values.
+type decoder struct {
+	r   *bufio.Reader
+	buf [8]byte
+}
+
+func (d *decoder) uvarint() (uint64, error) {
+	return binary.ReadUvarint(d.r)
+}
+
+func (d *decoder) string() (string, error) {
+	n, err := d.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if n > 1<<30 {
+		return "", fmt.Errorf("string of %d bytes is too long", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (d *decoder) bool() (bool, error) {
+	b, err := d.r.ReadByte()
+	return b != 0, err
+}
+
+func (d *decoder) int32() (int32, error) {
+	if _, err := io.ReadFull(d.r, d.buf[:4]); err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(d.buf[:4])), nil
+}
+
+// skip discards n values of a column of type typ.
+func (d *decoder) skip(typ string, n uint64) error {
+	size := map[string]int64{
+		"UInt8": 1, "Int8": 1,
+		"UInt16": 2, "Int16": 2, "Date": 2,
+		"UInt32": 4, "Int32": 4, "Float32": 4, "DateTime": 4,
+		"UInt64": 8, "Int64": 8, "Float64": 8,
+	}
+	if typ == "String" {
+		for i := uint64(0); i < n; i++ {
+			if _, err := d.string(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	width, ok := size[typ]
+	if !ok {
+		return fmt.Errorf("unsupported column type %s in result", typ)
+	}
+	_, err := io.CopyN(io.Discard, d.r, width*int64(n))
+	return err
+}
+
+// Exception is an error returned by the server.
+type Exception struct {
+	Code    int32
+	Name    string
+	Message string
+	Nested  *Exception
+}
+
+func (e *Exception) Error() string {
+	return fmt.Sprintf("clickhouse: %s (code %d)", e.Message, e.Code)
+}
+
+func (d *decoder) exception() (*Exception, error) {
+	var e Exception
+	var err error
+	if e.Code, err = d.int32(); err != nil {
+		return nil, err
+	}
+	if e.Name, err = d.string(); err != nil {
+		return nil, err
+	}
+	if e.Message, err = d.string(); err != nil {
+		return nil, err
+	}
+	if _, err = d.string(); err != nil { // stack trace
+		return nil, err
+	}
+	nested, err := d.bool()
+	if err != nil {
+		return nil, err
+	}
+	if nested {
+		if e.Nested, err = d.exception(); err != nil {
+			return nil, err
+		}
+	}
+	return &e, nil
+}
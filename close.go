@@ -0,0 +1,500 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/misty-step/costctl/config"
+	"github.com/misty-step/costctl/formats"
+	"github.com/misty-step/costctl/notify"
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/reconcile"
+	"github.com/misty-step/costctl/report"
+	"github.com/misty-step/costctl/reporter"
+	"github.com/misty-step/costctl/store"
+	"github.com/spf13/cobra"
+)
+
+// close command flags
+var (
+	closeMonth         string
+	closeArchive       string
+	closeStore         string
+	closeProviderUsage []string
+	closeForce         bool
+	closeVerify        bool
+)
+
+var closeCmd = &cobra.Command{
+	Use:   "close",
+	Short: "Freeze a month: archive its final reports, snapshot and reconciliation",
+	Long: `Close a finished month in one step. costctl close:
+
+  1. generates the month's full report as of its last instant, in every
+     format (text, JSON, HTML, Grafana and porcelain),
+  2. exports its session records (see 'costctl report --input'),
+  3. snapshots its daily aggregates, into the archive and the history store,
+  4. reconciles recorded spend by model with provider-billed usage, when
+     close.provider_usage in the config file or --provider-usage lists CSV
+     exports with date, model and cost columns,
+  5. writes manifest.json with every file's SHA-256, signed with HMAC-SHA256
+     into manifest.json.sig when close.signing_key is set,
+
+and stores it all under <archive>/<month>. A closed month is not replaced
+unless --force is given.
+
+--verify checks a closed month instead: every file against its size and
+SHA-256 in manifest.json, and manifest.json.sig against close.signing_key.
+It exits 1 if anything does not match.
+
+Examples:
+  costctl close --month 2026-06
+  costctl close --month 2026-06 --provider-usage ~/billing/anthropic-2026-06.csv
+  costctl close --month 2026-06 --archive /srv/costctl/closed --force
+  costctl close --month 2026-06 --verify`,
+	RunE: runClose,
+}
+
+func init() {
+	closeCmd.Flags().StringVar(&closeMonth, "month", "", "Month to close (YYYY-MM); it must have ended")
+	closeCmd.Flags().StringVar(&closeArchive, "archive", "", "Directory closed months are stored under (default: close.archive in the config file, or "+config.DefaultArchiveDir+")")
+	closeCmd.Flags().StringVar(&closeStore, "store", defaultStoreDSN, "History store the month's daily aggregates are snapshotted to (\"\" to skip)")
+	closeCmd.Flags().StringSliceVar(&closeProviderUsage, "provider-usage", nil, "Provider usage CSV to reconcile against, replacing close.provider_usage (repeatable)")
+	closeCmd.Flags().BoolVar(&closeForce, "force", false, "Replace the month's archive if it was already closed")
+	closeCmd.Flags().BoolVar(&closeVerify, "verify", false, "Check a closed month's files and signature against its manifest instead of closing it")
+	closeCmd.MarkFlagRequired("month")
+}
+
+// closeManifest describes a closed month's archive.
+type closeManifest struct {
+	CostctlVersion string         `json:"costctl_version"`
+	Month          string         `json:"month"`
+	ClosedAt       time.Time      `json:"closed_at"`
+	AsOf           time.Time      `json:"as_of"`
+	Host           string         `json:"host"`
+	Sessions       int            `json:"sessions"`
+	TotalCost      float64        `json:"total_cost"`
+	Reconciled     bool           `json:"reconciled"`
+	Files          []manifestFile `json:"files"`
+}
+
+// manifestFile is a file of the archive, by its path relative to it.
+type manifestFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+func runClose(cmd *cobra.Command, args []string) error {
+	from, err := time.ParseInLocation("2006-01", closeMonth, time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid --month: %s (use YYYY-MM)", closeMonth)
+	}
+	if closeVerify && (closeForce || cmd.Flags().Changed("provider-usage") || cmd.Flags().Changed("store")) {
+		return fmt.Errorf("--verify cannot be combined with --force, --provider-usage or --store")
+	}
+
+	settings, err := loadSettings()
+	if err != nil {
+		return err
+	}
+	archive := closeArchive
+	if archive == "" {
+		archive = settings.Close.Archive
+	}
+	if archive == "" {
+		archive = config.DefaultArchiveDir
+	}
+	if archive, err = parser.ExpandPath(archive); err != nil {
+		return err
+	}
+	dest := filepath.Join(archive, closeMonth)
+	if closeVerify {
+		ok, err := verifyClose(os.Stdout, dest, settings.Close.SigningKey)
+		if err == nil && !ok {
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+			return &exitCodeError{code: exitFailure}
+		}
+		return err
+	}
+
+	until := from.AddDate(0, 1, 0)
+	if time.Now().Before(until) {
+		return fmt.Errorf("%s has not ended yet", closeMonth)
+	}
+	asOf := until.Add(-time.Nanosecond)
+	if _, err := os.Stat(dest); err == nil && !closeForce {
+		return fmt.Errorf("%s is already closed in %s (use --force to close it again)", closeMonth, dest)
+	}
+
+	usagePaths := settings.Close.ProviderUsage
+	if cmd.Flags().Changed("provider-usage") {
+		usagePaths = closeProviderUsage
+	}
+	var usage []reconcile.Usage
+	for _, path := range usagePaths {
+		rows, err := readProviderUsage(strings.ReplaceAll(path, "{month}", closeMonth))
+		if err != nil {
+			return err
+		}
+		usage = append(usage, reconcile.InMonth(rows, closeMonth)...)
+	}
+
+	// Load the month once; the report and the archived records and
+	// snapshot are built from the same sessions.
+	roots, err := resolveAgentsRoots()
+	if err != nil {
+		return err
+	}
+	progress, err := newProgress()
+	if err != nil {
+		return err
+	}
+	opts := report.Options{
+		Roots:     roots,
+		Period:    closeMonth,
+		AsOf:      asOf,
+		Full:      true,
+		AgentDays: true,
+		Settings:  settings,
+		Progress:  parserProgress(progress),
+		Version:   rootCmd.Version,
+	}
+	if storeExists(closeStore) {
+		opts.History = closeStore
+		opts.Tenant = tenantName
+	}
+	ctx := context.Background()
+	loaded, _, err := report.Load(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if progress != nil {
+		progress.Done()
+	}
+	opts.Sessions = reporter.New(loaded, reporter.Config{Period: closeMonth, AsOf: asOf}).Filtered()
+	if opts.Sessions == nil {
+		opts.Sessions = []parser.Session{}
+	}
+	result, err := report.Generate(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("failed to get hostname: %w", err)
+	}
+	rows := store.Aggregate(opts.Sessions, host)
+
+	// Build the archive next to its destination and move it into place
+	// once complete, so a failed close leaves no partial archive.
+	if err := os.MkdirAll(archive, 0755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	tmp, err := os.MkdirTemp(archive, "."+closeMonth+"-")
+	if err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := writeCloseReports(tmp, result.Report, settings); err != nil {
+		return err
+	}
+	if err := writeFile(filepath.Join(tmp, "sessions.ndjson"), func(w io.Writer) error {
+		return parser.WriteRecords(w, opts.Sessions)
+	}); err != nil {
+		return err
+	}
+	snapshot, err := store.NewFileStore(filepath.Join(tmp, "snapshot"))
+	if err != nil {
+		return err
+	}
+	if err := snapshot.Put(ctx, rows); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	var reconciled *reconcile.Result
+	if len(usagePaths) > 0 {
+		tolerance := settings.Close.Tolerance
+		if tolerance == 0 {
+			tolerance = config.DefaultReconcileTolerance
+		}
+		r := reconcile.Compare(recordedByModel(opts.Sessions), usage, tolerance)
+		reconciled = &r
+		if err := writeJSONFile(filepath.Join(tmp, "reconciliation.json"), r); err != nil {
+			return err
+		}
+	}
+
+	manifest := closeManifest{
+		CostctlVersion: version,
+		Month:          closeMonth,
+		ClosedAt:       time.Now().UTC(),
+		AsOf:           asOf,
+		Host:           host,
+		Sessions:       result.TotalSessions,
+		TotalCost:      result.TotalCost,
+		Reconciled:     reconciled != nil,
+	}
+	if manifest.Files, err = checksumFiles(tmp); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if err := os.WriteFile(filepath.Join(tmp, "manifest.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if key := settings.Close.SigningKey; key != "" {
+		if err := os.WriteFile(filepath.Join(tmp, "manifest.json.sig"), []byte(notify.Sign(key, data)+"\n"), 0644); err != nil {
+			return fmt.Errorf("failed to write manifest signature: %w", err)
+		}
+	}
+
+	if closeStore != "" {
+		s, err := store.Open(closeStore)
+		if err != nil {
+			return err
+		}
+		err = s.Put(ctx, rows)
+		s.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+	}
+
+	if closeForce {
+		if err := os.RemoveAll(dest); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", dest, err)
+		}
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		return fmt.Errorf("failed to store archive: %w", err)
+	}
+
+	fmt.Printf("Closed %s: $%.2f across %d sessions\n", closeMonth, result.TotalCost, result.TotalSessions)
+	if reconciled != nil {
+		printReconciliation(os.Stdout, *reconciled)
+	}
+	signed := "unsigned"
+	if settings.Close.SigningKey != "" {
+		signed = "signed"
+	}
+	fmt.Printf("Archived %d files to %s (%s)\n", len(manifest.Files)+1, dest, signed)
+	return nil
+}
+
+// verifyClose checks the archive of a closed month in dir: that its files
+// are those manifest.json lists, with their sizes and checksums, and, with
+// a signing key, that manifest.json.sig signs manifest.json. It writes the
+// outcome, with each mismatch found, to w and reports whether all matched.
+func verifyClose(w io.Writer, dir, key string) (bool, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return false, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var manifest closeManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return false, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	var problems []string
+	signature := "unsigned"
+	sig, err := os.ReadFile(filepath.Join(dir, "manifest.json.sig"))
+	switch {
+	case err != nil && !os.IsNotExist(err):
+		return false, fmt.Errorf("failed to read manifest signature: %w", err)
+	case key == "" && err == nil:
+		signature = "signature not checked: no close.signing_key"
+	case key == "":
+		// Closed without a key, and none to check with.
+	case err != nil:
+		problems = append(problems, "manifest.json.sig is missing")
+	case !hmac.Equal([]byte(strings.TrimSpace(string(sig))), []byte(notify.Sign(key, data))):
+		problems = append(problems, "manifest.json.sig does not sign manifest.json with close.signing_key")
+	default:
+		signature = "signature valid"
+	}
+
+	files, err := checksumFiles(dir)
+	if err != nil {
+		return false, err
+	}
+	found := make(map[string]manifestFile, len(files))
+	for _, f := range files {
+		found[f.Path] = f
+	}
+	listed := map[string]bool{"manifest.json": true, "manifest.json.sig": true}
+	for _, want := range manifest.Files {
+		listed[want.Path] = true
+		got, ok := found[want.Path]
+		switch {
+		case !ok:
+			problems = append(problems, want.Path+" is missing")
+		case got != want:
+			problems = append(problems, want.Path+" does not match manifest.json")
+		}
+	}
+	for _, f := range files {
+		if !listed[f.Path] {
+			problems = append(problems, f.Path+" is not in manifest.json")
+		}
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(w, "%s does not match its manifest:\n", manifest.Month)
+		for _, p := range problems {
+			fmt.Fprintf(w, "  %s\n", p)
+		}
+		return false, nil
+	}
+	fmt.Fprintf(w, "Verified %s: %d files match manifest.json (%s)\n", manifest.Month, len(manifest.Files), signature)
+	return true, nil
+}
+
+// writeCloseReports writes the report in every output format.
+func writeCloseReports(dir string, r reporter.Report, settings *config.Config) error {
+	dates, err := formats.NewDateStyle(settings.Display.DateFormat, settings.Display.ISOWeeks)
+	if err != nil {
+		return err
+	}
+	costs, err := costStyle(settings.Display.Costs)
+	if err != nil {
+		return err
+	}
+	for _, out := range []struct {
+		name      string
+		formatter formats.Formatter
+	}{
+		{"report.txt", &formats.TextFormatter{Dates: dates, Wide: true, Costs: costs}},
+		{"report.json", formats.NewJSONFormatter()},
+		{"report.html", &formats.HTMLFormatter{Dates: dates, Costs: costs}},
+		{"report.grafana.json", formats.NewGrafanaFormatter()},
+		{"report.tsv", formats.NewPorcelainFormatter()},
+	} {
+		output, err := out.formatter.Format(r)
+		if err != nil {
+			return fmt.Errorf("failed to format %s: %w", out.name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, out.name), []byte(output), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", out.name, err)
+		}
+	}
+	return nil
+}
+
+// readProviderUsage reads a provider usage CSV.
+func readProviderUsage(path string) ([]reconcile.Usage, error) {
+	path, err := parser.ExpandPath(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open provider usage: %w", err)
+	}
+	defer f.Close()
+	usage, err := reconcile.ReadCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return usage, nil
+}
+
+// recordedByModel sums the spend providers bill for by model, leaving out
+// self-hosted estimates.
+func recordedByModel(sessions []parser.Session) map[string]float64 {
+	recorded := make(map[string]float64)
+	for _, s := range sessions {
+		if s.Estimated {
+			continue
+		}
+		model := s.Usage.Model
+		if model == "" {
+			model = "unknown"
+		}
+		recorded[model] += s.Usage.CostTotal
+	}
+	return recorded
+}
+
+// printReconciliation writes the reconciliation summary and the models
+// it flagged.
+func printReconciliation(w io.Writer, r reconcile.Result) {
+	fmt.Fprintf(w, "Reconciled with provider usage: recorded $%.2f, billed $%.2f (%+.2f)\n", r.Recorded, r.Provider, r.Difference)
+	if r.Flagged == 0 {
+		fmt.Fprintf(w, "  every model within %.0f%%\n", r.Tolerance*100)
+		return
+	}
+	fmt.Fprintf(w, "  %d models differ by more than %.0f%%:\n", r.Flagged, r.Tolerance*100)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  MODEL\tRECORDED\tBILLED\tDIFFERENCE")
+	for _, l := range r.Lines {
+		if l.Flagged {
+			fmt.Fprintf(tw, "  %s\t$%.2f\t$%.2f\t%+.2f\n", l.Model, l.Recorded, l.Provider, l.Difference)
+		}
+	}
+	tw.Flush()
+}
+
+// checksumFiles lists the files under dir with their SHA-256, by path
+// relative to dir with forward slashes, in lexical order.
+func checksumFiles(dir string) ([]manifestFile, error) {
+	var files []manifestFile
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		files = append(files, manifestFile{Path: filepath.ToSlash(rel), Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum archive: %w", err)
+	}
+	return files, nil
+}
+
+// writeFile creates path and writes it with write.
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeJSONFile writes v to path as indented JSON.
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/misty-step/costctl/config"
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/report"
+	"github.com/spf13/cobra"
+)
+
+// completeAgents completes --agent values with the agents found in the
+// agents directories and their display names.
+func completeAgents(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	roots, err := resolveAgentsRoots()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	settings, err := loadSettings()
+	if err != nil {
+		settings = &config.Config{}
+	}
+	displayNames := settings.AgentNames
+
+	var names []string
+	for _, root := range roots {
+		p := newParser(root.Dir, settings)
+		agents, err := p.ListAgents()
+		if err != nil {
+			continue
+		}
+		for _, agent := range agents {
+			names = append(names, agent, report.DisplayName(displayNames, agent))
+		}
+	}
+	return completionCandidates(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWorkspaces completes --workspace values with the workspaces of
+// the agents found in the agents directories.
+func completeWorkspaces(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	roots, err := resolveAgentsRoots()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	settings, err := loadSettings()
+	if err != nil {
+		settings = &config.Config{}
+	}
+
+	var names []string
+	for _, root := range roots {
+		agents, err := newParser(root.Dir, settings).ListAgents()
+		if err != nil {
+			continue
+		}
+		for _, agent := range agents {
+			if workspace := parser.WorkspaceOf(agent); workspace != "" {
+				names = append(names, workspace)
+			}
+		}
+	}
+	return completionCandidates(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeCrons completes --cron values with the cron names found in the
+// agents directories, narrowed to --agent when it is already set. Only file
+// and index names are listed, so completion stays fast on large histories.
+func completeCrons(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	roots, err := resolveAgentsRoots()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	agent := ""
+	if flag := cmd.Flags().Lookup("agent"); flag != nil {
+		agent = flag.Value.String()
+	}
+
+	settings, err := loadSettings()
+	if err != nil {
+		settings = &config.Config{}
+	}
+	displayNames := settings.AgentNames
+
+	var names []string
+	for _, root := range roots {
+		p := newParser(root.Dir, settings)
+		for _, dir := range report.AgentDirs(displayNames, agent) {
+			crons, err := p.ListCronNames(dir)
+			if err != nil {
+				continue
+			}
+			names = append(names, crons...)
+		}
+	}
+	return completionCandidates(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionCandidates returns the sorted, de-duplicated names that start
+// with prefix.
+func completionCandidates(names []string, prefix string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, name := range names {
+		if seen[name] || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,421 @@
+// Package config loads costctl's settings file (~/.costctl/config.json).
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultPath is the settings file used when --config is not given.
+const DefaultPath = "~/.costctl/config.json"
+
+// Config is the contents of the settings file. Every section is optional.
+type Config struct {
+	// Tenants maps tenant names to their agents directories, for operators
+	// running OpenClaw for several customers on one machine.
+	Tenants map[string]string `json:"tenants,omitempty"`
+	// AgentNames maps agent directory names to display names. Several
+	// directories given the same name are reported as one agent.
+	AgentNames map[string]string `json:"agent_names,omitempty"`
+	// CronSchedules mirrors the schedules configured in OpenClaw, by cron
+	// name, for display in `costctl crons list`.
+	CronSchedules map[string]string `json:"cron_schedules,omitempty"`
+	// CronOwners records who is responsible for each cron, by cron name.
+	CronOwners map[string]CronOwner `json:"cron_owners,omitempty"`
+	Rules      Rules                `json:"rules,omitempty"`
+	Webhook    Webhook              `json:"webhook,omitempty"`
+	Budgets    Budgets              `json:"budgets,omitempty"`
+	Display    Display              `json:"display,omitempty"`
+	KPIs       []KPI                `json:"kpis,omitempty"`
+	// Sections limits reports to these sections (summary, agents,
+	// anomalies, ...) unless --sections is given.
+	Sections []string `json:"sections,omitempty"`
+	// Detectors are external anomaly detectors run on every report.
+	Detectors []Detector `json:"detectors,omitempty"`
+	// SelfHosted prices models whose transcripts report no cost, such as
+	// self-hosted ones, by model name or glob (ollama/*).
+	SelfHosted map[string]SelfHostedRate `json:"self_hosted,omitempty"`
+	// Filter leaves test and experimental agents and sessions out of every
+	// report.
+	Filter Filter `json:"filter,omitempty"`
+	// Topics map sessions to conversation topics ("code review",
+	// "research") by keyword, for the topic breakdown. The first matching
+	// rule wins.
+	Topics []TopicRule `json:"topics,omitempty"`
+	// Discovery lists globs, relative to each agents directory, locating
+	// agent directories in nested layouts (workspaces/*/agents/*). Empty
+	// means the flat layout, *.
+	Discovery []string `json:"discovery,omitempty"`
+	// Maintenance declares windows during which alert rules are muted,
+	// such as a planned batch run that is expected to be expensive.
+	Maintenance []MaintenanceWindow `json:"maintenance,omitempty"`
+	// ModelPolicies restrict the models each agent may use, by agent name
+	// or "*" for agents without a policy of their own.
+	ModelPolicies map[string]ModelPolicy `json:"model_policies,omitempty"`
+	// CronExpectedCosts records what a single run of each cron should
+	// cost, by cron name, for comparison with actuals.
+	CronExpectedCosts map[string]ExpectedCost `json:"cron_expected_costs,omitempty"`
+	// Close configures `costctl close`, which freezes a month's reports.
+	Close Close `json:"close,omitempty"`
+	// SessionControl configures `costctl sessions stop`.
+	SessionControl SessionControl `json:"session_control,omitempty"`
+	// Profiles bundle report settings for one audience, such as finance
+	// or engineering, by name, selected with `costctl report --profile`.
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+}
+
+// Profile is the report settings of one audience. Settings it leaves unset
+// keep their values from the rest of the config file.
+type Profile struct {
+	// Sections replaces Config.Sections.
+	Sections []string `json:"sections,omitempty"`
+	// Format is the report format used when --format is not given.
+	Format string `json:"format,omitempty"`
+	// Display replaces Config.Display.
+	Display *Display `json:"display,omitempty"`
+	// Redact hides parts of reports: none (default), sessions (session
+	// IDs and transcript paths) or internals (models as well).
+	Redact string `json:"redact,omitempty"`
+}
+
+// ApplyProfile applies the sections and display settings of the named
+// profile to c, and returns the profile for the rest.
+func (c *Config) ApplyProfile(name string) (Profile, error) {
+	p, ok := c.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(c.Profiles))
+		for n := range c.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return Profile{}, fmt.Errorf("unknown profile: %s (no profiles are configured)", name)
+		}
+		return Profile{}, fmt.Errorf("unknown profile: %s (valid: %s)", name, strings.Join(names, ", "))
+	}
+	if len(p.Sections) > 0 {
+		c.Sections = p.Sections
+	}
+	if p.Display != nil {
+		c.Display = *p.Display
+	}
+	return p, nil
+}
+
+// SessionControl hands session termination to OpenClaw's own controls.
+type SessionControl struct {
+	// Stop is the program and its arguments that stop a running session,
+	// such as a client of OpenClaw's control socket or API. It is run
+	// directly, not through a shell. "{agent}", "{session}" and
+	// "{transcript}" in arguments are replaced by the session's agent,
+	// transcript name without .jsonl, and transcript path.
+	Stop []string `json:"stop,omitempty"`
+	// Timeout is how many seconds Stop may run. Defaults to
+	// DefaultStopTimeout.
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// Close configures the monthly close.
+type Close struct {
+	// Archive is the directory closed months are stored under, one
+	// directory per month. Defaults to DefaultArchiveDir.
+	Archive string `json:"archive,omitempty"`
+	// ProviderUsage lists CSV exports of provider-billed usage (date,
+	// model and cost columns) to reconcile the month against. "{month}" in
+	// a path is replaced by the month closed, e.g. 2026-06.
+	ProviderUsage []string `json:"provider_usage,omitempty"`
+	// Tolerance is the share by which a model's recorded and billed spend
+	// may differ before reconciliation flags it. Defaults to
+	// DefaultReconcileTolerance.
+	Tolerance float64 `json:"tolerance,omitempty"`
+	// SigningKey signs the archive's manifest with HMAC-SHA256. The
+	// archive is checksummed but unsigned when empty.
+	SigningKey string `json:"signing_key,omitempty"`
+}
+
+// DefaultArchiveDir is where closed months are stored.
+const DefaultArchiveDir = "~/.costctl/archive"
+
+// DefaultReconcileTolerance flags models whose recorded and billed spend
+// differ by more than 5%.
+const DefaultReconcileTolerance = 0.05
+
+// ModelPolicy lists the models an agent may or may not use, by name or
+// glob (anthropic/claude-opus-*). Deny wins over Allow; an empty Allow
+// allows every model not denied.
+type ModelPolicy struct {
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+}
+
+// Filter selects agents and session transcripts by glob (*-test,
+// scratch-*). Agents match on their name or directory name and sessions
+// on their transcript file name without .jsonl. Empty include lists select
+// everything; excludes win.
+type Filter struct {
+	Agents          []string `json:"agents,omitempty"`
+	ExcludeAgents   []string `json:"exclude_agents,omitempty"`
+	Sessions        []string `json:"sessions,omitempty"`
+	ExcludeSessions []string `json:"exclude_sessions,omitempty"`
+}
+
+// TopicRule assigns a topic to sessions whose first prompt or cron name
+// contains any of its keywords, ignoring case.
+type TopicRule struct {
+	Topic    string   `json:"topic"`
+	Keywords []string `json:"keywords"`
+}
+
+// ExpectedCost is the expected dollar cost of a single cron run. Either
+// bound may be left out.
+type ExpectedCost struct {
+	Min float64 `json:"min,omitempty"`
+	Max float64 `json:"max,omitempty"`
+	// Note explains the expectation ("reads the whole repo") and is shown
+	// with the cron like a note from the notes file.
+	Note string `json:"note,omitempty"`
+}
+
+// MaintenanceWindow mutes anomalies while it is active. It either recurs,
+// starting on every match of Schedule and lasting Duration, or covers the
+// range From until Until.
+type MaintenanceWindow struct {
+	Name string `json:"name"`
+	// Rules lists the anomaly types muted (expensive_cron, ...). Empty
+	// mutes every rule.
+	Rules []string `json:"rules,omitempty"`
+	// Crons limits the window to anomalies about these crons.
+	Crons []string `json:"crons,omitempty"`
+	// Schedule is a five-field cron expression ("0 2 * * 6") and Duration
+	// how long each window lasts ("4h").
+	Schedule string `json:"schedule,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	// From and Until are YYYY-MM-DD dates or RFC 3339 times; an Until
+	// date includes the whole day. Either may be left open.
+	From  string `json:"from,omitempty"`
+	Until string `json:"until,omitempty"`
+	// Timezone is the IANA zone dates and Schedule are read in. Defaults
+	// to local time.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// SelfHostedRate is the synthetic price of a self-hosted model, so its
+// sessions can be compared with API models. Rates may be combined.
+type SelfHostedRate struct {
+	// InputPrice and OutputPrice are dollars per million tokens, as in the
+	// model catalog. Cache reads and writes are charged as input.
+	InputPrice  float64 `json:"input_price,omitempty"`
+	OutputPrice float64 `json:"output_price,omitempty"`
+	// HourlyPrice is dollars per hour of session time (first to last
+	// message), e.g. the cost of the GPU serving the model.
+	HourlyPrice float64 `json:"hourly_price,omitempty"`
+}
+
+// Detector is an external anomaly detector: a program given the report's
+// sessions as JSON on stdin that prints the anomalies it finds as JSON on
+// stdout (see package detect).
+type Detector struct {
+	Name string `json:"name"`
+	// Command is the program and its arguments. It is run directly, not
+	// through a shell.
+	Command []string `json:"command"`
+	// Timeout is how many seconds the detector may run. Defaults to
+	// DefaultDetectorTimeout.
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// CronOwner is the team responsible for a cron. Anomalies about the cron
+// are posted to Webhook, when set, instead of webhook.url.
+type CronOwner struct {
+	Team    string `json:"team,omitempty"`
+	Email   string `json:"email,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	Webhook string `json:"webhook,omitempty"`
+}
+
+// KPI is a target for one of the report's key metrics: total_cost,
+// avg_session_cost, avg_cron_run_cost or cache_ratio (0-1). Either bound
+// may be omitted.
+type KPI struct {
+	Metric string   `json:"metric"`
+	Min    *float64 `json:"min,omitempty"`
+	Max    *float64 `json:"max,omitempty"`
+}
+
+// Display controls how dates and costs appear in text, HTML and Markdown
+// reports.
+type Display struct {
+	// DateFormat is one of iso (default), us, eu, uk or long.
+	DateFormat string `json:"date_format,omitempty"`
+	// ISOWeeks adds ISO-8601 week numbers (2026-W10) to daily rows.
+	ISOWeeks bool `json:"iso_weeks,omitempty"`
+	// Costs sets the decimals and rounding of amounts.
+	Costs CostDisplay `json:"costs,omitempty"`
+}
+
+// CostDisplay sets how amounts are rounded for display. Reports sum costs
+// at full precision whatever these are.
+type CostDisplay struct {
+	// Decimals shown; defaults to 2. 0 shows whole dollars.
+	Decimals *int `json:"decimals,omitempty"`
+	// SmallDecimals are shown for amounts under SmallUnder dollars;
+	// default 4 under $0.01.
+	SmallDecimals *int    `json:"small_decimals,omitempty"`
+	SmallUnder    float64 `json:"small_under,omitempty"`
+	// Rounding is half-up (default) or half-even, banker's rounding.
+	// TotalRounding applies to report totals instead, if set.
+	Rounding      string `json:"rounding,omitempty"`
+	TotalRounding string `json:"total_rounding,omitempty"`
+}
+
+// Rules tunes anomaly detection.
+type Rules struct {
+	// CronThresholds overrides --threshold per cron name (dollars per run).
+	CronThresholds map[string]float64 `json:"cron_thresholds,omitempty"`
+	// CronTokenThresholds flag cron runs using more tokens than a limit,
+	// alongside the dollar thresholds, by cron name; "*" applies to every
+	// other cron.
+	CronTokenThresholds map[string]TokenThreshold `json:"cron_token_thresholds,omitempty"`
+	// SessionCaps override the per-session spend limits read from each
+	// agent's OpenClaw config, by agent name; "*" applies to sessions
+	// without a limit.
+	SessionCaps map[string]float64 `json:"session_caps,omitempty"`
+	// CronCaps override per-run spend limits by cron name. They take
+	// precedence over SessionCaps for cron runs.
+	CronCaps map[string]float64 `json:"cron_caps,omitempty"`
+	// CapMargin is how close to a cap (as a fraction of it) a session must
+	// come to be reported. Defaults to DefaultCapMargin.
+	CapMargin float64 `json:"cap_margin,omitempty"`
+	// ContextGrowthTokens is the prompt size, in tokens, past which a cron
+	// whose prompt grows run over run is flagged. Defaults to
+	// DefaultContextGrowthTokens.
+	ContextGrowthTokens int `json:"context_growth_tokens,omitempty"`
+}
+
+// TokenThreshold limits the tokens of a single run, as counted in
+// transcripts: input (uncached prompt tokens), output and total (including
+// cache reads and writes). Zero leaves a count unlimited.
+type TokenThreshold struct {
+	Input  int `json:"input,omitempty"`
+	Output int `json:"output,omitempty"`
+	Total  int `json:"total,omitempty"`
+}
+
+// Budgets are spending limits in dollars. Agents, Crons and Total apply to
+// each calendar day, as proposed by `costctl budget allocate`; Weekly and
+// Monthly to each calendar week (from Monday) and month.
+type Budgets struct {
+	// Agents maps agent display names to their daily budget.
+	Agents map[string]float64 `json:"agents,omitempty"`
+	// Crons maps cron names to their daily budget.
+	Crons map[string]float64 `json:"crons,omitempty"`
+	// Total is the daily budget of all agents together.
+	Total   float64      `json:"total,omitempty"`
+	Weekly  BudgetLimits `json:"weekly,omitempty"`
+	Monthly BudgetLimits `json:"monthly,omitempty"`
+}
+
+// BudgetLimits are spending limits in dollars over one calendar week or
+// month.
+type BudgetLimits struct {
+	Agents map[string]float64 `json:"agents,omitempty"`
+	Crons  map[string]float64 `json:"crons,omitempty"`
+	Total  float64            `json:"total,omitempty"`
+}
+
+// Webhook configures delivery of anomalies to a generic HTTP endpoint.
+type Webhook struct {
+	// URL receives one POST per anomaly. Delivery is disabled when empty.
+	URL string `json:"url,omitempty"`
+	// Secret signs each payload with HMAC-SHA256 (X-Costctl-Signature).
+	Secret string `json:"secret,omitempty"`
+	// Retries is how many times a failed delivery is retried with
+	// exponential backoff. Defaults to DefaultWebhookRetries.
+	Retries int `json:"retries,omitempty"`
+	// DeadLetter is the file undeliverable payloads are appended to.
+	// Defaults to DefaultDeadLetterPath.
+	DeadLetter string `json:"dead_letter,omitempty"`
+	// Links are included in every payload, e.g. {"dashboard": "https://..."}.
+	Links map[string]string `json:"links,omitempty"`
+}
+
+// DefaultWebhookRetries retries a failed delivery three times.
+const DefaultWebhookRetries = 3
+
+// DefaultDeadLetterPath collects webhook payloads that could not be delivered.
+const DefaultDeadLetterPath = "~/.costctl/webhook-dead-letter.jsonl"
+
+// DefaultCapMargin reports sessions that spent at least 90% of their cap.
+const DefaultCapMargin = 0.1
+
+// DefaultContextGrowthTokens flags growing crons once prompts reach 50k
+// tokens.
+const DefaultContextGrowthTokens = 50000
+
+// DefaultDetectorTimeout gives detectors 30 seconds to run.
+const DefaultDetectorTimeout = 30
+
+// DefaultStopTimeout gives session stop commands 30 seconds to run.
+const DefaultStopTimeout = 30
+
+// Load reads the settings file at path. A missing file yields an empty
+// Config.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes the settings file, creating its directory if needed. The
+// file is replaced through a temporary file in the same directory, so a
+// failed write leaves the old settings in place, and keeps its mode: new
+// files are only readable by their owner, since they may hold secrets.
+func (c *Config) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(append(data, '\n'))
+	if err == nil {
+		err = tmp.Chmod(mode)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
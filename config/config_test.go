@@ -0,0 +1,119 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadMissing(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Rules.CronThresholds != nil {
+		t.Errorf("expected empty config, got %+v", cfg)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config.json")
+	whole := 0
+	cfg := &Config{
+		AgentNames: map[string]string{"urza-prod": "urza"},
+		Rules:      Rules{CronThresholds: map[string]float64{"daily-kickoff": 0.42}},
+		Display:    Display{Costs: CostDisplay{Decimals: &whole}},
+	}
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Rules.CronThresholds["daily-kickoff"] != 0.42 {
+		t.Errorf("expected threshold 0.42, got %+v", loaded.Rules)
+	}
+	if loaded.AgentNames["urza-prod"] != "urza" {
+		t.Errorf("expected agent name urza, got %+v", loaded.AgentNames)
+	}
+	if d := loaded.Display.Costs; d.Decimals == nil || *d.Decimals != 0 || d.SmallDecimals != nil {
+		t.Errorf("expected 0 decimals kept and small decimals unset, got %+v", d)
+	}
+}
+
+func TestSaveKeepsMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	cfg := &Config{Webhook: Webhook{Secret: "s3cret"}}
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("expected a new config readable by its owner only, got %v (%v)", info.Mode(), err)
+	}
+
+	if err := os.Chmod(path, 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0640 {
+		t.Errorf("expected the existing mode kept, got %v (%v)", info.Mode(), err)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 1 {
+		t.Errorf("expected no temporary files left behind, got %v (%v)", entries, err)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for invalid config")
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+  "sections": ["summary", "agents", "sessions"],
+  "display": {"date_format": "us"},
+  "profiles": {
+    "finance": {"sections": ["summary", "agents"], "format": "markdown", "display": {"date_format": "eu"}, "redact": "internals"},
+    "engineer": {}
+  }
+}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	p, err := cfg.ApplyProfile("finance")
+	if err != nil {
+		t.Fatalf("ApplyProfile failed: %v", err)
+	}
+	if p.Format != "markdown" || p.Redact != "internals" || len(cfg.Sections) != 2 || cfg.Display.DateFormat != "eu" {
+		t.Errorf("expected the finance profile applied, got %+v and %+v, %+v", p, cfg.Sections, cfg.Display)
+	}
+
+	// A profile leaving settings unset keeps the config file's.
+	if cfg, err = Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.ApplyProfile("engineer"); err != nil || len(cfg.Sections) != 3 || cfg.Display.DateFormat != "us" {
+		t.Errorf("expected the config file's settings kept, got %+v, %+v (%v)", cfg.Sections, cfg.Display, err)
+	}
+
+	if _, err := cfg.ApplyProfile("marketing"); err == nil || !strings.Contains(err.Error(), "engineer, finance") {
+		t.Errorf("expected an unknown profile to list the valid ones, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/misty-step/costctl/config"
+	"github.com/misty-step/costctl/formats"
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/reporter"
+	"github.com/spf13/cobra"
+)
+
+// crons command flags
+var (
+	cronsAgent  string
+	cronsFormat string
+	cronsWide   bool
+)
+
+var cronsCmd = &cobra.Command{
+	Use:   "crons",
+	Short: "Inspect cron jobs",
+}
+
+var cronsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List crons with their schedule, last run, and weekly spend",
+	Long: `List every cron with runs in the transcripts or a schedule in the config
+file: its schedule, when it last ran and what that run cost, and its spend
+over the last 7 days with the trend against the 7 days before.
+
+Schedules are not stored in transcripts; mirror them from OpenClaw under
+cron_schedules in the config file.
+
+Examples:
+  costctl crons list
+  costctl crons list --agent urza
+  costctl crons list --format json`,
+	RunE: runCronsList,
+}
+
+func init() {
+	cronsListCmd.Flags().StringVar(&cronsAgent, "agent", "", "Only list this agent's crons")
+	cronsListCmd.Flags().StringVar(&cronsFormat, "format", "text", "Output format: json|text")
+	cronsListCmd.Flags().BoolVar(&cronsWide, "wide", false, "Size the cron name column to the longest name instead of truncating")
+	cronsListCmd.Flags().BoolVar(&cronsWide, "no-truncate", false, "Alias for --wide")
+
+	cronsListCmd.RegisterFlagCompletionFunc("agent", completeAgents)
+
+	cronsCmd.AddCommand(cronsListCmd)
+}
+
+func runCronsList(cmd *cobra.Command, args []string) error {
+	if cronsFormat != "json" && cronsFormat != "text" {
+		return fmt.Errorf("invalid format: %s (valid: json, text)", cronsFormat)
+	}
+
+	sessions, _, err := parseSessions(cronsAgent, time.Time{}, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	settings, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	crons := reporter.CronOverview(sessions, settings.CronSchedules, time.Now())
+
+	if cronsFormat == "json" {
+		data, err := json.MarshalIndent(crons, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format crons: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(crons) == 0 {
+		fmt.Println("No crons found")
+		return nil
+	}
+
+	dates, err := formats.NewDateStyle(settings.Display.DateFormat, settings.Display.ISOWeeks)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(crons))
+	for i, c := range crons {
+		names[i] = c.CronName
+	}
+	width := formats.ColumnWidth("CRON NAME", names, 40, cronsWide)
+	fmt.Printf("  %-*s %-15s %-22s %10s %7s %10s %s\n", width, "CRON NAME", "SCHEDULE", "LAST RUN", "LAST COST", "7D RUNS", "7D COST", "TREND")
+	for _, c := range crons {
+		schedule := c.Schedule
+		if schedule == "" {
+			schedule = "-"
+		}
+		lastRun, lastCost := "never", "-"
+		if c.LastRunAt != nil {
+			lastRun = dates.Time(c.LastRunAt.Local())
+			lastCost = parser.FormatCost(c.LastRunCost)
+		}
+		fmt.Printf("  %-*s %-15s %-22s %10s %7d %10s %s\n",
+			width, formats.Truncate(c.CronName, width), schedule, lastRun, lastCost, c.WeekRuns, parser.FormatCost(c.WeekCost), cronTrend(c))
+	}
+	return nil
+}
+
+// cronTrend renders the week-over-week change, e.g. "↑ +35%".
+func cronTrend(c reporter.CronStatus) string {
+	arrow := map[string]string{"up": "↑", "down": "↓", "flat": "→"}[c.Trend]
+	if c.PrevWeekCost == 0 {
+		if c.WeekCost == 0 {
+			return arrow
+		}
+		return arrow + " new"
+	}
+	return fmt.Sprintf("%s %+.0f%%", arrow, (c.WeekCost-c.PrevWeekCost)/c.PrevWeekCost*100)
+}
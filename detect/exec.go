@@ -0,0 +1,128 @@
+// Package detect runs external anomaly detectors.
+//
+// A detector is an executable registered in the config file. For each
+// report it is started with the report's sessions as a JSON Input on
+// stdin, and prints the anomalies it finds on stdout as a JSON array of
+// objects with the fields of reporter.Anomaly:
+//
+//	[{"type": "seasonal_spike", "severity": "warning",
+//	  "description": "urza spent 3x its forecast", "agent": "urza", "cost": 4.2}]
+//
+// Type and description are required; severity defaults to warning. A
+// detector that finds nothing prints [] or nothing at all. A non-zero
+// exit status, a timeout or unparseable output fails the detector, and its
+// stderr is included in the error.
+package detect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/misty-step/costctl/reporter"
+)
+
+// InputVersion is bumped on incompatible changes to Input.
+const InputVersion = 1
+
+// Input is the JSON document written to a detector's stdin. Fields are
+// only ever added within an InputVersion.
+type Input struct {
+	Version  int    `json:"version"`
+	Detector string `json:"detector"` // name the detector is registered under
+	reporter.DetectorInput
+}
+
+// Severities lists the valid severities of a detector's anomalies.
+var Severities = []string{"info", "warning", "error"}
+
+// maxStderr bounds the stderr quoted in a failed detector's error.
+const maxStderr = 500
+
+// Exec is a detector run as an external program.
+type Exec struct {
+	name    string
+	command []string
+	timeout time.Duration
+}
+
+// NewExec creates a detector running command, the program followed by its
+// arguments, killed if it runs longer than timeout.
+func NewExec(name string, command []string, timeout time.Duration) *Exec {
+	return &Exec{name: name, command: command, timeout: timeout}
+}
+
+// Name returns the name the detector is registered under.
+func (e *Exec) Name() string {
+	return e.name
+}
+
+// Detect runs the program once over input.
+func (e *Exec) Detect(input reporter.DetectorInput) ([]reporter.Anomaly, error) {
+	if len(e.command) == 0 {
+		return nil, errors.New("no command configured")
+	}
+	stdin, err := json.Marshal(Input{Version: InputVersion, Detector: e.name, DetectorInput: input})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, e.command[0], e.command[1:]...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", e.timeout)
+		}
+		if msg := quoteStderr(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return parseAnomalies(stdout.Bytes())
+}
+
+// parseAnomalies decodes and checks a detector's output.
+func parseAnomalies(output []byte) ([]reporter.Anomaly, error) {
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil, nil
+	}
+	var anomalies []reporter.Anomaly
+	if err := json.Unmarshal(output, &anomalies); err != nil {
+		return nil, fmt.Errorf("invalid output: %w", err)
+	}
+	for i, a := range anomalies {
+		if a.Type == "" || a.Description == "" {
+			return nil, fmt.Errorf("invalid output: anomaly %d needs a type and description", i)
+		}
+		if a.Severity == "" {
+			anomalies[i].Severity = "warning"
+		} else if !slices.Contains(Severities, a.Severity) {
+			return nil, fmt.Errorf("invalid output: anomaly %d has unknown severity %q (valid: %s)", i, a.Severity, strings.Join(Severities, ", "))
+		}
+		// Owners come from the config file, not the detector.
+		anomalies[i].Owner = nil
+	}
+	return anomalies, nil
+}
+
+// quoteStderr returns the end of a detector's stderr, on one line.
+func quoteStderr(stderr string) string {
+	stderr = strings.Join(strings.Fields(stderr), " ")
+	if len(stderr) > maxStderr {
+		stderr = "..." + stderr[len(stderr)-maxStderr:]
+	}
+	return stderr
+}
@@ -0,0 +1,73 @@
+package detect
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/reporter"
+)
+
+var testInput = reporter.DetectorInput{
+	Period:   "week",
+	AsOf:     time.Date(2026, 6, 12, 0, 0, 0, 0, time.UTC),
+	Sessions: []reporter.SessionDetail{{ID: "s1", Agent: "urza", Cost: 4.2}},
+}
+
+func TestExecDetect(t *testing.T) {
+	stdin := filepath.Join(t.TempDir(), "stdin.json")
+	script := `cat > "$0"; echo '[{"type":"seasonal_spike","description":"urza spent 3x its forecast","agent":"urza","cost":4.2,"owner":{"team":"x"}}]'`
+	d := NewExec("arima", []string{"sh", "-c", script, stdin}, 5*time.Second)
+
+	anomalies, err := d.Detect(testInput)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if len(anomalies) != 1 || anomalies[0].Type != "seasonal_spike" || anomalies[0].Severity != "warning" ||
+		anomalies[0].Cost != 4.2 || anomalies[0].Owner != nil {
+		t.Errorf("unexpected anomalies: %+v", anomalies)
+	}
+
+	data, err := os.ReadFile(stdin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Input
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid input: %v", err)
+	}
+	if got.Version != InputVersion || got.Detector != "arima" || got.Period != "week" ||
+		len(got.Sessions) != 1 || got.Sessions[0].Cost != 4.2 {
+		t.Errorf("unexpected input: %s", data)
+	}
+}
+
+func TestExecDetectFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		timeout time.Duration
+		want    string
+	}{
+		{"exit status", "echo 'model not fitted' >&2; exit 3", time.Second, "model not fitted"},
+		{"timeout", "sleep 5", 100 * time.Millisecond, "timed out"},
+		{"invalid json", "echo nope", time.Second, "invalid output"},
+		{"missing type", `echo '[{"description":"x"}]'`, time.Second, "needs a type"},
+		{"bad severity", `echo '[{"type":"t","description":"x","severity":"dire"}]'`, time.Second, "unknown severity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewExec("arima", []string{"sh", "-c", tt.script}, tt.timeout).Detect(testInput)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if anomalies, err := NewExec("quiet", []string{"true"}, time.Second).Detect(testInput); err != nil || anomalies != nil {
+		t.Errorf("expected no anomalies from empty output, got %v, %v", anomalies, err)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/misty-step/costctl/config"
+	"github.com/misty-step/costctl/formats"
+	"github.com/misty-step/costctl/reporter"
+	"github.com/spf13/cobra"
+)
+
+// diff command flags
+var (
+	diffPeriod string
+	diffAgent  string
+	diffFormat string
+	diffTop    int
+	diffNotes  string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Explain the change in spend between a period and the one before it",
+	Long: `Compare spend in a period with the window of the same length just before
+it, and break the change down as waterfalls by agent, cron, and model,
+largest contribution first. Notes dated within each window (see report
+--notes) are listed beneath it.
+
+Examples:
+  costctl diff                    # last 7 days vs the 7 days before
+  costctl diff --period today     # today so far vs yesterday at this time
+  costctl diff --period month --top 5
+  costctl diff --format json`,
+	RunE: runDiff,
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffPeriod, "period", "week", "Time period: today|yesterday|week|month|ytd, a month (2026-03) or months (2026-01..2026-06)")
+	diffCmd.Flags().StringVar(&diffAgent, "agent", "", "Filter by agent")
+	diffCmd.Flags().StringVar(&diffFormat, "format", "text", "Output format: json|text")
+	diffCmd.Flags().IntVar(&diffTop, "top", 10, "Steps per waterfall; the rest are folded together (0 for all)")
+	diffCmd.Flags().StringVar(&diffNotes, "notes", "~/.costctl/notes.txt", "Annotations file (\"YYYY-MM-DD: text\" / \"cron:NAME: text\" lines)")
+
+	diffCmd.RegisterFlagCompletionFunc("agent", completeAgents)
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	if diffFormat != "json" && diffFormat != "text" {
+		return fmt.Errorf("invalid format: %s (valid: json, text)", diffFormat)
+	}
+
+	sessions, _, err := parseSessions(diffAgent, time.Time{}, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	d, err := reporter.ComparePeriods(sessions, diffPeriod, time.Now())
+	if err != nil {
+		return err
+	}
+
+	annotations, err := loadNotes(diffNotes)
+	if err != nil {
+		return err
+	}
+	d.AttachNotes(annotations)
+
+	if diffFormat == "json" {
+		data, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format diff: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	settings, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	dates, err := formats.NewDateStyle(settings.Display.DateFormat, settings.Display.ISOWeeks)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(formats.FormatDiff(d, diffTop, dates))
+	return nil
+}
@@ -0,0 +1,317 @@
+// Package e2e runs the compiled costctl binary against fixture agents
+// directories and compares its output with golden files, covering how
+// flags combine (period, agent, format, sections) and the exit codes
+// scripts rely on, beyond what the unit tests of each package see.
+package e2e
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/internal/fixtures"
+	"github.com/misty-step/costctl/synth"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// binary is the costctl binary built by TestMain.
+var binary string
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	dir, err := os.MkdirTemp("", "costctl-e2e")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	binary = filepath.Join(dir, "costctl")
+	build := exec.Command("go", "build", "-o", binary, "..")
+	build.Stdout, build.Stderr = os.Stderr, os.Stderr
+	if err := build.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to build costctl: %v\n", err)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// asOf pins reports over the fixtures, which --seeded also stamps them with.
+var asOf = fixtures.AsOf.Format(time.RFC3339)
+
+// synthAgents generates a small, seeded agents directory ending at
+// fixtures.AsOf, with crons and sub-agents the recorded fixtures lack.
+func synthAgents(t testing.TB) string {
+	t.Helper()
+	dir := t.TempDir()
+	opts := synth.DefaultOptions()
+	opts.Agents = 3
+	opts.Days = 14
+	opts.End = fixtures.AsOf
+	if _, err := synth.Generate(dir, opts); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// run is one costctl invocation. Arguments may refer to the agents
+// directory as {agents}.
+type run struct {
+	name   string
+	agents func(testing.TB) string
+	args   []string
+	config string // config file contents, if any
+	stdin  string // standard input, if any
+	// before lists costctl invocations run first, in the same home
+	// directory, which must succeed; edit, when set, then changes the home
+	// directory before the run.
+	before [][]string
+	edit   func(t testing.TB, home string)
+	code   int // expected exit code
+	// stderr, when set, is expected in the error output of a failing run,
+	// whose output is not compared with a golden file: it includes usage
+	// text that changes with every new flag.
+	stderr string
+}
+
+func TestCLI(t *testing.T) {
+	seeded := []string{"--agents-dir", "{agents}", "--as-of", asOf, "--seeded"}
+	report := func(args ...string) []string {
+		return append(append([]string{"report"}, seeded...), args...)
+	}
+	runs := []run{
+		{name: "report-all-text", agents: fixtures.Agents, args: report("--period", "all", "--full")},
+		{name: "report-week-agent-json", agents: fixtures.Agents, args: report("--period", "week", "--agent", "urza", "--format", "json")},
+		{name: "report-crons-csv", agents: fixtures.Agents, args: report("--period", "all", "--crons", "--format", "csv")},
+		{name: "report-sections-markdown", agents: fixtures.Agents, args: report("--period", "month", "--sections", "summary,agents,models", "--format", "markdown")},
+		{name: "report-cron-porcelain", agents: fixtures.Agents, args: report("--period", "all", "--cron", "daily-kickoff", "--porcelain")},
+		{name: "report-since-until-json", agents: fixtures.Agents, args: report("--since", "2026-03-02", "--until", "2026-03-03", "--sections", "summary,days", "--format", "json", "--compact")},
+		{name: "report-synth-week-text", agents: synthAgents, args: report("--period", "week", "--crons", "--models")},
+		{name: "report-synth-agent-json", agents: synthAgents, args: report("--period", "month", "--agent", "kaylee", "--sections", "summary,crons", "--format", "json")},
+		{
+			name:   "report-over-budget",
+			agents: fixtures.Agents,
+			args:   report("--period", "all", "--sections", "summary,budgets", "--fail-over-budget"),
+			config: `{"budgets": {"monthly": {"total": 0.05}}}`,
+			code:   4,
+		},
+		{
+			name:   "report-budget-porcelain",
+			agents: fixtures.Agents,
+			args:   report("--period", "all", "--sections", "summary,budgets", "--porcelain"),
+			config: `{"budgets": {"monthly": {"agents": {"urza": 0.1}}}}`,
+			code:   4,
+		},
+		{name: "report-no-notes", agents: fixtures.Agents, args: report("--period", "all", "--sections", "summary", "--notes", "")},
+		{name: "report-invalid-format", agents: fixtures.Agents, args: report("--format", "yaml"), code: 1, stderr: "invalid format: yaml"},
+		{name: "report-invalid-period", agents: fixtures.Agents, args: report("--period", "fortnight"), code: 1, stderr: "invalid period: fortnight"},
+		{name: "report-porcelain-format", agents: fixtures.Agents, args: report("--porcelain", "--format", "json"), code: 1, stderr: "--porcelain cannot be combined"},
+		{name: "report-compact-text", agents: fixtures.Agents, args: report("--compact"), code: 1, stderr: "--compact and --stream require --format json"},
+		{name: "report-missing-agents-dir", agents: func(t testing.TB) string { return filepath.Join(t.TempDir(), "missing") }, args: report(), code: 1, stderr: "failed to read agents directory"},
+	}
+	runAll(t, runs)
+}
+
+// liveAgents writes an agents directory holding urza's session "live",
+// whose last turn was a minute ago, so it is still running.
+func liveAgents(t testing.TB) string {
+	t.Helper()
+	dir := t.TempDir()
+	sessionsDir := filepath.Join(dir, "urza", "sessions")
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	line := `{"type":"message","timestamp":"%s","message":{"role":"assistant","usage":{"input":1000,"output":200,"totalTokens":1200,"cost":{"total":0.25}},"model":"anthropic/claude-sonnet-4-5"}}` + "\n"
+	now := time.Now().UTC()
+	data := fmt.Sprintf(line, now.Add(-2*time.Minute).Format(time.RFC3339)) + fmt.Sprintf(line, now.Add(-time.Minute).Format(time.RFC3339))
+	if err := os.WriteFile(filepath.Join(sessionsDir, "live.jsonl"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestSessionsStop(t *testing.T) {
+	stop := func(args ...string) []string {
+		return append([]string{"sessions", "stop", "--agents-dir", "{agents}"}, args...)
+	}
+	echo := `{"session_control": {"stop": ["echo", "stopping", "{agent}", "{session}", "{transcript}"]}}`
+	runs := []run{
+		{name: "stop-yes", agents: liveAgents, args: stop("live", "--yes"), config: echo},
+		{name: "stop-confirmed", agents: liveAgents, args: stop("live", "--agent", "urza"), config: echo, stdin: "y\n"},
+		{name: "stop-declined", agents: liveAgents, args: stop("live"), config: echo, stdin: "n\n"},
+		{name: "stop-no-answer", agents: liveAgents, args: stop("live"), config: echo, code: 1, stderr: "aborted: no answer given"},
+		{
+			name:   "stop-timeout",
+			agents: liveAgents,
+			args:   stop("live", "--yes"),
+			config: `{"session_control": {"stop": ["sleep", "10"], "timeout": 1}}`,
+			code:   1,
+			stderr: "stop command timed out after 1s",
+		},
+		{name: "stop-failed", agents: liveAgents, args: stop("live", "--yes"), config: `{"session_control": {"stop": ["false"]}}`, code: 1, stderr: "stop command failed"},
+		{name: "stop-ended", agents: fixtures.Agents, args: stop("2026-03-02-planning", "--yes"), config: echo, code: 1, stderr: "has likely ended (use --force"},
+		{name: "stop-ended-force", agents: fixtures.Agents, args: stop("2026-03-02-planning", "--yes", "--force"), config: echo},
+		{name: "stop-unconfigured", agents: liveAgents, args: stop("live", "--yes"), code: 1, stderr: "no stop command configured"},
+		{name: "stop-missing", agents: liveAgents, args: stop("gone", "--yes"), config: echo, code: 1, stderr: "no session gone found"},
+	}
+	runAll(t, runs)
+}
+
+func TestClose(t *testing.T) {
+	closeMonth := []string{"close", "--month", "2026-03", "--agents-dir", "{agents}"}
+	verify := []string{"close", "--month", "2026-03", "--verify"}
+	signed := `{"close": {"signing_key": "k1"}}`
+	archived := func(home string, name ...string) string {
+		return filepath.Join(append([]string{home, ".costctl", "archive", "2026-03"}, name...)...)
+	}
+	runs := []run{
+		{name: "close-month", agents: fixtures.Agents, args: closeMonth},
+		{name: "close-month-signed", agents: fixtures.Agents, args: closeMonth, config: signed},
+		{name: "close-verify", agents: fixtures.Agents, args: verify, config: signed, before: [][]string{closeMonth}},
+		{name: "close-verify-unsigned", agents: fixtures.Agents, args: verify, before: [][]string{closeMonth}},
+		{
+			name:   "close-verify-tampered",
+			agents: fixtures.Agents,
+			args:   verify,
+			config: signed,
+			before: [][]string{closeMonth},
+			edit: func(t testing.TB, home string) {
+				if err := os.WriteFile(archived(home, "report.txt"), []byte("nothing to see\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Remove(archived(home, "sessions.ndjson")); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(archived(home, "notes.txt"), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			code: 1,
+		},
+		{
+			name:   "close-verify-wrong-key",
+			agents: fixtures.Agents,
+			args:   verify,
+			config: signed,
+			before: [][]string{closeMonth},
+			edit: func(t testing.TB, home string) {
+				if err := os.WriteFile(filepath.Join(home, ".costctl", "config.json"), []byte(`{"close": {"signing_key": "k2"}}`), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			code: 1,
+		},
+		{name: "close-again", agents: fixtures.Agents, args: closeMonth, before: [][]string{closeMonth}, code: 1, stderr: "2026-03 is already closed"},
+		{name: "close-again-force", agents: fixtures.Agents, args: append(closeMonth, "--force"), before: [][]string{closeMonth}},
+		{name: "close-not-ended", agents: fixtures.Agents, args: []string{"close", "--month", "2999-01", "--agents-dir", "{agents}"}, code: 1, stderr: "2999-01 has not ended yet"},
+		{name: "close-verify-not-closed", agents: fixtures.Agents, args: verify, code: 1, stderr: "failed to read manifest"},
+		{name: "close-verify-force", agents: fixtures.Agents, args: append(verify, "--force"), code: 1, stderr: "--verify cannot be combined"},
+	}
+	runAll(t, runs)
+}
+
+// runAll runs each costctl invocation in parallel, checking its exit code
+// and comparing its output with the golden file of its name.
+func runAll(t *testing.T, runs []run) {
+	for _, r := range runs {
+		t.Run(r.name, func(t *testing.T) {
+			t.Parallel()
+			stdout, stderr, code := r.exec(t)
+			if code != r.code {
+				t.Fatalf("exit code %d, want %d\nstdout:\n%s\nstderr:\n%s", code, r.code, stdout, stderr)
+			}
+			if r.stderr != "" {
+				if !strings.Contains(stderr, r.stderr) {
+					t.Errorf("expected %q in stderr:\n%s", r.stderr, stderr)
+				}
+				return
+			}
+			got := stdout
+			if stderr != "" {
+				got += "[stderr]\n" + stderr
+			}
+			compareGolden(t, r.name+".golden", got)
+		})
+	}
+}
+
+// exec runs costctl with a fresh home directory, so no config file, cache
+// or history store of the machine is used, and in UTC, so days and run
+// slots match anywhere. Paths of temporary directories in the output are
+// replaced by their placeholders.
+func (r run) exec(t *testing.T) (stdout, stderr string, code int) {
+	t.Helper()
+	home := t.TempDir()
+	agents := r.agents(t)
+	if r.config != "" {
+		if err := os.MkdirAll(filepath.Join(home, ".costctl"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(home, ".costctl", "config.json"), []byte(r.config), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	command := func(args []string) *exec.Cmd {
+		expanded := make([]string, len(args))
+		for i, arg := range args {
+			expanded[i] = strings.ReplaceAll(arg, "{agents}", agents)
+		}
+		cmd := exec.Command(binary, expanded...)
+		cmd.Env = []string{"HOME=" + home, "TZ=UTC", "PATH=" + os.Getenv("PATH")}
+		return cmd
+	}
+	for _, args := range r.before {
+		if out, err := command(args).CombinedOutput(); err != nil {
+			t.Fatalf("costctl %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	if r.edit != nil {
+		r.edit(t, home)
+	}
+
+	cmd := command(r.args)
+	cmd.Stdin = strings.NewReader(r.stdin)
+	var out, errOut bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	err := cmd.Run()
+	var exit *exec.ExitError
+	switch {
+	case errors.As(err, &exit):
+		code = exit.ExitCode()
+	case err != nil:
+		t.Fatalf("failed to run costctl: %v", err)
+	}
+	placeholders := strings.NewReplacer(agents, "{agents}", home, "{home}")
+	return placeholders.Replace(out.String()), placeholders.Replace(errOut.String()), code
+}
+
+// compareGolden compares output with the golden file name, rewriting it
+// first with -update.
+func compareGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test ./e2e -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from the golden file; run go test ./e2e -update and review the diff\n%s", name, got)
+	}
+}
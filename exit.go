@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/misty-step/costctl/reporter"
+)
+
+// Exit codes guaranteed in --porcelain mode. When several conditions hold,
+// the highest code wins.
+const (
+	exitOK             = 0
+	exitFailure        = 1 // usage or runtime error
+	exitAnomalies      = 3 // warning- or error-severity anomalies detected, outside maintenance windows
+	exitBudgetExceeded = 4 // a budget in the config file was exceeded
+	exitParseErrors    = 5 // some transcripts could not be parsed; totals may be incomplete
+	exitPartial        = 6 // --max-duration stopped parsing; totals are incomplete
+)
+
+// exitCodeError carries a process exit code out of a command without
+// printing an error message.
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// porcelainExitCode maps report conditions to the documented exit codes.
+func porcelainExitCode(report reporter.Report, parseErrors int) int {
+	if report.Partial != nil {
+		return exitPartial
+	}
+	if parseErrors > 0 {
+		return exitParseErrors
+	}
+	if report.OverBudget() {
+		return exitBudgetExceeded
+	}
+	for _, a := range report.Anomalies {
+		if a.Muted == "" && (a.Severity == "warning" || a.Severity == "error") {
+			return exitAnomalies
+		}
+	}
+	return exitOK
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/misty-step/costctl/config"
+	"github.com/misty-step/costctl/formats"
+	"github.com/misty-step/costctl/report"
+	"github.com/misty-step/costctl/reporter"
+	"github.com/misty-step/costctl/store"
+	"github.com/spf13/cobra"
+)
+
+// fleet command flags
+var (
+	fleetSource string
+	fleetPeriod string
+	fleetFormat string
+	fleetTop    int
+	fleetWide   bool
+)
+
+var fleetCmd = &cobra.Command{
+	Use:   "fleet",
+	Short: "Report across the hosts of a fleet",
+}
+
+var fleetReportCmd = &cobra.Command{
+	Use:   "report [host=]report.json...",
+	Short: "Roll per-host reports up into one fleet report",
+	Long: `Combine the reports of several hosts into one rollup: spend per host, each
+agent's spend with a column per host, the costliest crons across the fleet,
+and anomalies. Besides each host's own anomalies, hosts spending more than
+twice the median host and crons costing more than twice as much per run on
+one host as on the others are flagged.
+
+Reports are either JSON reports written on each host with
+"costctl report --format json", named host=path or named after the file,
+or read from the shared store hosts push to (see "costctl push").
+
+Examples:
+  costctl fleet report web-1.json web-2.json batch-1.json
+  costctl fleet report prod=reports/prod.json staging=reports/staging.json
+  costctl fleet report --source postgres://costctl@warehouse/costs --period month
+  costctl fleet report --source postgres --format json`,
+	RunE: runFleetReport,
+}
+
+func init() {
+	fleetReportCmd.Flags().StringVar(&fleetSource, "source", "", "Store DSN to read every pushed host from, e.g. postgres://user@host/db")
+	fleetReportCmd.Flags().StringVar(&fleetPeriod, "period", "week", "Time period for --source: today|yesterday|week|month|all")
+	fleetReportCmd.Flags().StringVar(&fleetFormat, "format", "text", "Output format: json|text")
+	fleetReportCmd.Flags().IntVar(&fleetTop, "top", 10, "Crons to list (0 for all)")
+	fleetReportCmd.Flags().BoolVar(&fleetWide, "wide", false, "Size name columns to the longest name instead of truncating")
+
+	fleetCmd.AddCommand(fleetReportCmd)
+}
+
+func runFleetReport(cmd *cobra.Command, args []string) error {
+	if fleetFormat != "json" && fleetFormat != "text" {
+		return fmt.Errorf("invalid format: %s (valid: json, text)", fleetFormat)
+	}
+	if fleetTop < 0 {
+		return fmt.Errorf("--top must not be negative")
+	}
+
+	var hosts []reporter.HostReport
+	var err error
+	switch {
+	case len(args) > 0 && fleetSource != "":
+		return fmt.Errorf("report files cannot be combined with --source")
+	case len(args) > 0:
+		hosts, err = readHostReports(args)
+	case fleetSource != "":
+		hosts, err = storedHostReports(fleetSource, fleetPeriod)
+	default:
+		return fmt.Errorf("pass report files or --source")
+	}
+	if err != nil {
+		return err
+	}
+	if len(hosts) == 0 {
+		return fmt.Errorf("no hosts to report on")
+	}
+
+	fleet := reporter.Rollup(hosts, fleetTop)
+	if fleetFormat == "json" {
+		data, err := json.MarshalIndent(fleet, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format fleet report: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	fmt.Print(formats.FormatFleet(fleet, fleetWide))
+	return nil
+}
+
+// readHostReports reads JSON reports named host=path, or after their file
+// name without its extension.
+func readHostReports(args []string) ([]reporter.HostReport, error) {
+	seen := make(map[string]string)
+	var hosts []reporter.HostReport
+	for _, arg := range args {
+		host, path, ok := strings.Cut(arg, "=")
+		if !ok {
+			path = arg
+			host = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		if host == "" {
+			return nil, fmt.Errorf("empty host name for %s", path)
+		}
+		if prev, dup := seen[host]; dup {
+			return nil, fmt.Errorf("host %s given twice (%s and %s); name them with host=path", host, prev, path)
+		}
+		seen[host] = path
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read report: %w", err)
+		}
+		var r reporter.Report
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+		}
+		if len(hosts) > 0 && r.Period != hosts[0].Report.Period {
+			fmt.Fprintf(os.Stderr, "Warning: %s covers period %q but %s covers %q\n", path, r.Period, seen[hosts[0].Host], hosts[0].Report.Period)
+		}
+		hosts = append(hosts, reporter.HostReport{Host: host, Report: r})
+	}
+	return hosts, nil
+}
+
+// storedHostReports generates a report for every host with aggregates in
+// the store at dsn during the period. Rows without a host were not pushed
+// and are left out.
+func storedHostReports(dsn, period string) ([]reporter.HostReport, error) {
+	if err := validatePeriod(period); err != nil {
+		return nil, err
+	}
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	settings, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	s, err := store.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	var query store.Query
+	if since := periodSince(period, time.Now()); !since.IsZero() {
+		query.Since = since.Format("2006-01-02")
+	}
+	rows, err := s.Query(ctx, query)
+	s.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query store: %w", err)
+	}
+	names := make(map[string]bool)
+	for _, row := range rows {
+		if row.Host != "" {
+			names[row.Host] = true
+		}
+	}
+
+	var hosts []reporter.HostReport
+	for name := range names {
+		r, err := report.Generate(ctx, report.Options{
+			Source:   dsn,
+			Host:     name,
+			Period:   period,
+			Sections: []string{reporter.SectionSummary, reporter.SectionAgents, reporter.SectionCrons, reporter.SectionAnomalies},
+			Settings: settings,
+		})
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, reporter.HostReport{Host: name, Report: r.Report})
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Host < hosts[j].Host })
+	return hosts, nil
+}
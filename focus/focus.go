@@ -0,0 +1,245 @@
+// Package focus converts sessions to FinOps FOCUS 1.0 cost and usage
+// records, so OpenClaw spend can be loaded into FinOps tooling next to
+// cloud bills.
+package focus
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// Values shared by every record.
+const (
+	ProviderName    = "OpenClaw"
+	ServiceName     = "OpenClaw Agents"
+	ServiceCategory = "AI and Machine Learning"
+	Currency        = "USD"
+)
+
+// Columns are the CSV header: FOCUS columns, then x_ prefixed custom
+// columns with the token breakdown.
+var Columns = []string{
+	"BillingAccountId",
+	"BillingPeriodStart",
+	"BillingPeriodEnd",
+	"ChargePeriodStart",
+	"ChargePeriodEnd",
+	"ChargeCategory",
+	"ChargeClass",
+	"ChargeFrequency",
+	"ChargeDescription",
+	"BilledCost",
+	"EffectiveCost",
+	"ListCost",
+	"ContractedCost",
+	"BillingCurrency",
+	"ProviderName",
+	"PublisherName",
+	"InvoiceIssuerName",
+	"ServiceName",
+	"ServiceCategory",
+	"SubAccountId",
+	"ResourceId",
+	"ResourceName",
+	"ResourceType",
+	"SkuId",
+	"ConsumedQuantity",
+	"ConsumedUnit",
+	"PricingQuantity",
+	"PricingUnit",
+	"Tags",
+	"x_InputTokens",
+	"x_OutputTokens",
+	"x_CacheReadTokens",
+	"x_CacheWriteTokens",
+	"x_Estimated",
+}
+
+// Record is one FOCUS usage charge: a session's spend on one day.
+type Record struct {
+	BillingAccountID   string
+	BillingPeriodStart time.Time
+	BillingPeriodEnd   time.Time
+	ChargePeriodStart  time.Time
+	ChargePeriodEnd    time.Time
+	ChargeDescription  string
+	// BilledCost is what the provider invoiced. Self-hosted models are not
+	// invoiced, so their estimated cost only counts as EffectiveCost.
+	BilledCost    float64
+	EffectiveCost float64
+	PublisherName string
+	SubAccountID  string
+	ResourceID    string
+	ResourceName  string
+	ResourceType  string
+	SkuID         string
+	Tokens        int
+	Tags          map[string]string
+	Usage         parser.Usage
+	Estimated     bool
+}
+
+// Records converts sessions to FOCUS records attributed to host, which is
+// the billing account. Charge periods are the UTC day a session started
+// and billing periods its calendar month. Sessions without a start time
+// are skipped.
+func Records(sessions []parser.Session, host string) []Record {
+	records := make([]Record, 0, len(sessions))
+	for _, s := range sessions {
+		if s.StartedAt.IsZero() {
+			continue
+		}
+		start := s.StartedAt.UTC()
+		day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+		month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+		model := s.Usage.Model
+		if model == "" {
+			model = "unknown"
+		}
+		billed := s.Usage.CostTotal
+		if s.Estimated {
+			billed = 0
+		}
+		tags := map[string]string{"agent": s.Agent, "session_type": string(s.Type)}
+		if s.Tenant != "" {
+			tags["tenant"] = s.Tenant
+		}
+		if s.CronName != "" {
+			tags["cron"] = s.CronName
+		}
+
+		records = append(records, Record{
+			BillingAccountID:   host,
+			BillingPeriodStart: month,
+			BillingPeriodEnd:   month.AddDate(0, 1, 0),
+			ChargePeriodStart:  day,
+			ChargePeriodEnd:    day.AddDate(0, 0, 1),
+			ChargeDescription:  description(s, model),
+			BilledCost:         billed,
+			EffectiveCost:      s.Usage.CostTotal,
+			PublisherName:      publisher(model),
+			SubAccountID:       subAccount(s),
+			ResourceID:         resourceID(s, host),
+			ResourceName:       s.Agent,
+			ResourceType:       "Agent Session",
+			SkuID:              model,
+			Tokens:             s.Usage.Total,
+			Tags:               tags,
+			Usage:              s.Usage,
+			Estimated:          s.Estimated,
+		})
+	}
+	return records
+}
+
+// WriteCSV writes records as CSV with a Columns header.
+func WriteCSV(w io.Writer, records []Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(Columns); err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := cw.Write(r.row()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (r Record) row() []string {
+	tags, _ := json.Marshal(r.Tags)
+	return []string{
+		r.BillingAccountID,
+		timestamp(r.BillingPeriodStart),
+		timestamp(r.BillingPeriodEnd),
+		timestamp(r.ChargePeriodStart),
+		timestamp(r.ChargePeriodEnd),
+		"Usage",
+		"",
+		"Usage-Based",
+		r.ChargeDescription,
+		cost(r.BilledCost),
+		cost(r.EffectiveCost),
+		cost(r.EffectiveCost),
+		cost(r.EffectiveCost),
+		Currency,
+		ProviderName,
+		r.PublisherName,
+		ProviderName,
+		ServiceName,
+		ServiceCategory,
+		r.SubAccountID,
+		r.ResourceID,
+		r.ResourceName,
+		r.ResourceType,
+		r.SkuID,
+		strconv.Itoa(r.Tokens),
+		"Tokens",
+		strconv.Itoa(r.Tokens),
+		"Tokens",
+		string(tags),
+		strconv.Itoa(r.Usage.Input),
+		strconv.Itoa(r.Usage.Output),
+		strconv.Itoa(r.Usage.CacheRead),
+		strconv.Itoa(r.Usage.CacheWrite),
+		strconv.FormatBool(r.Estimated),
+	}
+}
+
+// description summarizes the charge, e.g. "cron daily-kickoff on
+// claude-opus-4-6".
+func description(s parser.Session, model string) string {
+	kind := string(s.Type)
+	if s.CronName != "" {
+		kind += " " + s.CronName
+	}
+	return kind + " on " + model
+}
+
+// publisher returns the vendor of a provider-prefixed model
+// (anthropic/claude-opus-4-6), or "" when the model has no prefix.
+func publisher(model string) string {
+	if vendor, _, ok := strings.Cut(model, "/"); ok {
+		return vendor
+	}
+	return ""
+}
+
+// subAccount groups an agent's charges: tenant/agent, or the agent alone.
+func subAccount(s parser.Session) string {
+	if s.Tenant != "" {
+		return s.Tenant + "/" + s.Agent
+	}
+	return s.Agent
+}
+
+// resourceID identifies the session across hosts and tenants. Sessions
+// rebuilt from daily aggregates have no ID and are identified by their
+// grouping instead.
+func resourceID(s parser.Session, host string) string {
+	id := s.ID
+	if id == "" {
+		id = string(s.Type)
+		if s.CronName != "" {
+			id += ":" + s.CronName
+		}
+		id += ":" + s.Usage.Model
+	}
+	return strings.Join([]string{host, subAccount(s), id}, "/")
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func cost(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,108 @@
+package focus
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestRecords(t *testing.T) {
+	sessions := []parser.Session{
+		{
+			ID:        "run-1",
+			Agent:     "urza",
+			Tenant:    "acme",
+			Type:      parser.SessionTypeCron,
+			CronName:  "daily-kickoff",
+			StartedAt: time.Date(2026, 10, 31, 23, 30, 0, 0, time.UTC),
+			Usage:     parser.Usage{Input: 100, Output: 50, Total: 150, CostTotal: 1.25, Model: "anthropic/claude-opus-4-6"},
+		},
+		{
+			ID:        "main",
+			Agent:     "pepper",
+			Type:      parser.SessionTypeInteractive,
+			StartedAt: time.Date(2026, 10, 2, 9, 0, 0, 0, time.UTC),
+			Usage:     parser.Usage{Total: 1000, CostTotal: 0.5, Model: "ollama/qwen"},
+			Estimated: true,
+		},
+		{ID: "undated", Agent: "amos"},
+	}
+
+	records := Records(sessions, "host-1")
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	cron := records[0]
+	if !cron.ChargePeriodStart.Equal(time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)) ||
+		!cron.ChargePeriodEnd.Equal(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected charge period %v - %v", cron.ChargePeriodStart, cron.ChargePeriodEnd)
+	}
+	if !cron.BillingPeriodStart.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) ||
+		!cron.BillingPeriodEnd.Equal(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected billing period %v - %v", cron.BillingPeriodStart, cron.BillingPeriodEnd)
+	}
+	if cron.ResourceID != "host-1/acme/urza/run-1" || cron.SubAccountID != "acme/urza" {
+		t.Errorf("unexpected resource %q in %q", cron.ResourceID, cron.SubAccountID)
+	}
+	if cron.PublisherName != "anthropic" || cron.ChargeDescription != "cron daily-kickoff on anthropic/claude-opus-4-6" {
+		t.Errorf("unexpected publisher %q or description %q", cron.PublisherName, cron.ChargeDescription)
+	}
+	if cron.BilledCost != 1.25 || cron.EffectiveCost != 1.25 {
+		t.Errorf("unexpected costs %v/%v", cron.BilledCost, cron.EffectiveCost)
+	}
+
+	// Self-hosted estimates are not billed.
+	if records[1].BilledCost != 0 || records[1].EffectiveCost != 0.5 {
+		t.Errorf("expected estimated cost to be effective only, got %v/%v", records[1].BilledCost, records[1].EffectiveCost)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	records := Records([]parser.Session{{
+		ID:        "main",
+		Agent:     "pepper",
+		Type:      parser.SessionTypeInteractive,
+		StartedAt: time.Date(2026, 10, 2, 9, 0, 0, 0, time.UTC),
+		Usage:     parser.Usage{Input: 10, Output: 5, Total: 15, CostTotal: 0.02, Model: "claude-sonnet-4-5"},
+	}}, "host-1")
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, records); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected header and one row, got %d rows", len(rows))
+	}
+
+	row := make(map[string]string)
+	for i, column := range rows[0] {
+		row[column] = rows[1][i]
+	}
+	want := map[string]string{
+		"BillingAccountId":   "host-1",
+		"BillingPeriodStart": "2026-10-01T00:00:00Z",
+		"ChargePeriodStart":  "2026-10-02T00:00:00Z",
+		"ChargeCategory":     "Usage",
+		"BilledCost":         "0.02",
+		"BillingCurrency":    "USD",
+		"ServiceName":        ServiceName,
+		"SkuId":              "claude-sonnet-4-5",
+		"ConsumedQuantity":   "15",
+		"ConsumedUnit":       "Tokens",
+		"Tags":               `{"agent":"pepper","session_type":"interactive"}`,
+		"x_InputTokens":      "10",
+	}
+	for column, value := range want {
+		if row[column] != value {
+			t.Errorf("%s = %q, want %q", column, row[column], value)
+		}
+	}
+}
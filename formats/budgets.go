@@ -0,0 +1,25 @@
+package formats
+
+import (
+	"fmt"
+
+	"github.com/misty-step/costctl/reporter"
+)
+
+// budgetName names a budget's scope: "all agents", or the agent or cron.
+func budgetName(b reporter.BudgetStatus) string {
+	if b.Scope == "total" {
+		return "all agents"
+	}
+	return b.Name
+}
+
+// budgetUsed formats a budget's consumption, marking exceeded budgets,
+// e.g. "62.5%" or "114.0% over".
+func budgetUsed(b reporter.BudgetStatus) string {
+	used := fmt.Sprintf("%.1f%%", b.Percent)
+	if b.Exceeded {
+		used += " over"
+	}
+	return used
+}
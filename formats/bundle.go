@@ -0,0 +1,67 @@
+package formats
+
+import (
+	"archive/zip"
+	"bytes"
+
+	"github.com/misty-step/costctl/reporter"
+)
+
+// BundleFormatter packs every rendering of a report into one zip archive,
+// to attach as evidence: report.json, report.md, report.html and one CSV
+// per table under csv/ (see CSVTables). Entries are dated at the report's
+// generation time, so the same report always yields the same archive.
+type BundleFormatter struct {
+	Dates DateStyle
+	Costs CostStyle
+	CSV   CSVStyle
+}
+
+// NewBundleFormatter creates a new bundle formatter.
+func NewBundleFormatter() *BundleFormatter {
+	return &BundleFormatter{}
+}
+
+// Format returns the bytes of the zip archive.
+func (f *BundleFormatter) Format(r reporter.Report) (string, error) {
+	files := []struct {
+		name      string
+		formatter Formatter
+	}{
+		{"report.json", NewJSONFormatter()},
+		{"report.md", &MarkdownFormatter{Dates: f.Dates, Costs: f.Costs}},
+		{"report.html", &HTMLFormatter{Dates: f.Dates, Costs: f.Costs}},
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	add := func(name string, data []byte) error {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: r.GeneratedAt})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	for _, file := range files {
+		output, err := file.formatter.Format(r)
+		if err != nil {
+			return "", err
+		}
+		if err := add(file.name, []byte(output)); err != nil {
+			return "", err
+		}
+	}
+	tables, err := CSVTables(r, f.CSV)
+	if err != nil {
+		return "", err
+	}
+	for _, t := range tables {
+		if err := add("csv/"+t.Name, t.Data); err != nil {
+			return "", err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,83 @@
+package formats
+
+import (
+	"math"
+	"strings"
+)
+
+// barWidth is the width of text bar charts, in terminal cells.
+const barWidth = 16
+
+// sparkWidth is the widest a text sparkline gets, in terminal cells.
+const sparkWidth = 24
+
+// barEighths are the partial blocks for 1/8 through 7/8 of a cell.
+var barEighths = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// textBar renders value as a horizontal bar of up to width cells, scaled so
+// that max fills the whole width. Any positive value gets a visible sliver.
+func textBar(value, max float64, width int) string {
+	if value <= 0 || max <= 0 {
+		return ""
+	}
+	eighths := int(math.Round(value / max * float64(width*8)))
+	if eighths < 1 {
+		eighths = 1
+	}
+	if eighths > width*8 {
+		eighths = width * 8
+	}
+	return strings.Repeat("█", eighths/8) + barEighths[eighths%8]
+}
+
+// barFraction returns value as a fraction of max, clamped to [0, 1].
+func barFraction(value, max float64) float64 {
+	if value <= 0 || max <= 0 {
+		return 0
+	}
+	return math.Min(value/max, 1)
+}
+
+// sparkBlocks are the eight bar heights of a text sparkline.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders values as a line of bar heights scaled from the
+// smallest value to the largest. More than width values are averaged into
+// width buckets, so the line's length is at most width.
+func sparkline(values []int, width int) string {
+	points := bucket(values, width)
+	if len(points) == 0 {
+		return ""
+	}
+	lo, hi := points[0], points[0]
+	for _, p := range points {
+		lo, hi = math.Min(lo, p), math.Max(hi, p)
+	}
+	var b strings.Builder
+	for _, p := range points {
+		level := 0
+		if hi > lo {
+			level = int(math.Round((p - lo) / (hi - lo) * float64(len(sparkBlocks)-1)))
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+// bucket averages values into at most n consecutive buckets of nearly
+// equal size.
+func bucket(values []int, n int) []float64 {
+	if len(values) < n {
+		n = len(values)
+	}
+	points := make([]float64, n)
+	for i := range points {
+		start, end := i*len(values)/n, (i+1)*len(values)/n
+		sum := 0
+		for _, v := range values[start:end] {
+			sum += v
+		}
+		points[i] = float64(sum) / float64(end-start)
+	}
+	return points
+}
@@ -0,0 +1,50 @@
+package formats
+
+import "strings"
+
+// Widest the name columns of text tables grow before values are truncated,
+// unless the formatter is wide.
+const (
+	maxAgentWidth = 24
+	maxNameWidth  = 40
+)
+
+// ellipsis marks the characters Truncate removed.
+const ellipsis = "…"
+
+// ColumnWidth returns the width of a text table column: its longest value
+// or header, capped at limit unless wide.
+func ColumnWidth(header string, values []string, limit int, wide bool) int {
+	width := len([]rune(header))
+	for _, v := range values {
+		width = max(width, len([]rune(v)))
+	}
+	if !wide && width > limit {
+		return limit
+	}
+	return width
+}
+
+// Truncate shortens s to at most width characters by replacing its middle
+// with an ellipsis. The trailing segment after the last separator (-, _,
+// :, / or .), typically a hash, ID or version that tells similar names
+// apart, is kept whole when it fits in two thirds of the width.
+func Truncate(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	if width <= 1 {
+		return string(r[:max(width, 0)])
+	}
+
+	keep := width - 1
+	tail := keep / 2
+	if i := strings.LastIndexAny(s, "-_:/."); i > 0 {
+		segment := len([]rune(s[i:]))
+		if segment > tail && segment <= keep*2/3 {
+			tail = segment
+		}
+	}
+	return string(r[:keep-tail]) + ellipsis + string(r[len(r)-tail:])
+}
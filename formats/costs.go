@@ -0,0 +1,87 @@
+package formats
+
+import (
+	"fmt"
+	"math"
+)
+
+// Rounding modes for CostStyle.
+const (
+	RoundHalfUp   = "half-up"   // ties away from zero
+	RoundHalfEven = "half-even" // ties to the even digit (banker's rounding)
+)
+
+// CostStyle controls how the text, HTML and Markdown formatters display
+// dollar amounts. Amounts are only rounded here, when rendered: reports
+// keep and sum them at full precision. The zero value shows two decimals,
+// four under a cent, rounding half up.
+type CostStyle struct {
+	Decimals      *int    // decimals shown; 2 when nil
+	SmallDecimals *int    // decimals shown below SmallUnder; 4 when nil
+	SmallUnder    float64 // amount below which SmallDecimals apply; $0.01 when zero
+	Rounding      string  // RoundHalfUp (default) or RoundHalfEven
+	TotalRounding string  // rounding of report totals; Rounding when empty
+}
+
+// maxCostDecimals is the most decimals a CostStyle shows: costs are
+// recorded to the millionth of a dollar.
+const maxCostDecimals = 6
+
+// Validate reports a rounding mode or number of decimals that is not
+// supported.
+func (s CostStyle) Validate() error {
+	for _, d := range []*int{s.Decimals, s.SmallDecimals} {
+		if d != nil && (*d < 0 || *d > maxCostDecimals) {
+			return fmt.Errorf("invalid cost decimals: %d (valid: 0 to %d)", *d, maxCostDecimals)
+		}
+	}
+	if s.SmallUnder < 0 {
+		return fmt.Errorf("invalid small cost threshold: %g (must not be negative)", s.SmallUnder)
+	}
+	for _, mode := range []string{s.Rounding, s.TotalRounding} {
+		if mode != "" && mode != RoundHalfUp && mode != RoundHalfEven {
+			return fmt.Errorf("invalid cost rounding: %s (valid: %s, %s)", mode, RoundHalfUp, RoundHalfEven)
+		}
+	}
+	return nil
+}
+
+// Cost formats an amount, e.g. "$1.25".
+func (s CostStyle) Cost(v float64) string {
+	return s.format(v, s.Rounding)
+}
+
+// Total formats a report total, using TotalRounding.
+func (s CostStyle) Total(v float64) string {
+	mode := s.TotalRounding
+	if mode == "" {
+		mode = s.Rounding
+	}
+	return s.format(v, mode)
+}
+
+func (s CostStyle) format(v float64, mode string) string {
+	decimals, small, under := 2, 4, s.SmallUnder
+	if s.Decimals != nil {
+		decimals = *s.Decimals
+	}
+	if s.SmallDecimals != nil {
+		small = *s.SmallDecimals
+	}
+	if under == 0 {
+		under = 0.01
+	}
+	if math.Abs(v) < under {
+		decimals = small
+	}
+	scale := math.Pow10(decimals)
+	// Drop binary noise first, so that 0.285 is the tie it is written as
+	// rather than 0.28499999999999998.
+	scaled := math.Round(v*scale*1e6) / 1e6
+	if mode == RoundHalfEven {
+		scaled = math.RoundToEven(scaled)
+	} else {
+		scaled = math.Round(scaled)
+	}
+	return fmt.Sprintf("$%.*f", decimals, scaled/scale)
+}
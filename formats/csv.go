@@ -0,0 +1,194 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/misty-step/costctl/reporter"
+)
+
+// CSVTable is one table of a report as CSV, with a header row.
+type CSVTable struct {
+	Name string // file name, e.g. agents.csv
+	Data []byte
+}
+
+// CSVStyle adapts CSV to the spreadsheet opening it. The zero value is
+// RFC 4180 CSV: commas, \n line endings and no byte order mark. Excel in
+// many European locales expects semicolons, and only reads UTF-8 with a
+// byte order mark. Numbers always use a decimal point.
+type CSVStyle struct {
+	Comma rune // field delimiter; ',' when zero
+	BOM   bool // start each file with a UTF-8 byte order mark
+	CRLF  bool // end lines with \r\n
+}
+
+// utf8BOM is the UTF-8 byte order mark.
+const utf8BOM = "\ufeff"
+
+// Validate reports a delimiter CSV cannot use.
+func (s CSVStyle) Validate() error {
+	if s.Comma == 0 {
+		return nil
+	}
+	w := csv.NewWriter(io.Discard)
+	w.Comma = s.Comma
+	if err := w.Write(nil); err != nil {
+		return fmt.Errorf("invalid CSV delimiter: %q", s.Comma)
+	}
+	return nil
+}
+
+// CSVTables renders each table present in the report as CSV, for
+// spreadsheets. Costs are dollars with six decimals, tokens integers and
+// times RFC 3339, as in porcelain output.
+func CSVTables(r reporter.Report, style CSVStyle) ([]CSVTable, error) {
+	var tables []CSVTable
+	add := func(name string, header []string, rows [][]string) error {
+		if len(rows) == 0 {
+			return nil
+		}
+		var buf bytes.Buffer
+		if style.BOM {
+			buf.WriteString(utf8BOM)
+		}
+		w := csv.NewWriter(&buf)
+		if style.Comma != 0 {
+			w.Comma = style.Comma
+		}
+		w.UseCRLF = style.CRLF
+		w.Write(header)
+		w.WriteAll(rows)
+		if err := w.Error(); err != nil {
+			return err
+		}
+		tables = append(tables, CSVTable{Name: name, Data: buf.Bytes()})
+		return nil
+	}
+	itoa := strconv.Itoa
+	cost := porcelainCost
+
+	summary := [][]string{{r.Period, itoa(r.TotalSessions), cost(r.TotalCost), itoa(r.TotalTokens)}}
+	budgets := make([][]string, 0, len(r.Budgets))
+	for _, s := range r.Budgets {
+		budgets = append(budgets, []string{s.Period, s.Scope, s.Name, s.From.Format(time.RFC3339), cost(s.Spent), cost(s.Limit),
+			porcelainPercent(s.Percent), strconv.FormatBool(s.Exceeded)})
+	}
+	var forecast [][]string
+	if fc := r.Forecast; fc != nil {
+		row := func(agent, tenant string, p reporter.Projection) []string {
+			return []string{fc.Month, agent, tenant, cost(p.MonthToDate), cost(p.Linear), cost(p.TrailingDaily), cost(p.Trailing)}
+		}
+		forecast = append(forecast, row("", "", fc.Projection))
+		for _, a := range fc.ByAgent {
+			forecast = append(forecast, row(a.Agent, a.Tenant, a.Projection))
+		}
+	}
+	tenants := make([][]string, 0, len(r.ByTenant))
+	for _, t := range r.ByTenant {
+		tenants = append(tenants, []string{t.Tenant, itoa(t.Agents), itoa(t.Sessions), cost(t.TotalCost), itoa(t.TotalTokens)})
+	}
+	workspaces := make([][]string, 0, len(r.ByWorkspace))
+	for _, w := range r.ByWorkspace {
+		workspaces = append(workspaces, []string{w.Workspace, w.Tenant, itoa(w.Agents), itoa(w.Sessions), cost(w.TotalCost), itoa(w.TotalTokens)})
+	}
+	agents := make([][]string, 0, len(r.ByAgent))
+	for _, a := range r.ByAgent {
+		agents = append(agents, []string{a.Agent, a.Tenant, itoa(a.Sessions), cost(a.TotalCost), itoa(a.InputTokens), itoa(a.OutputTokens), itoa(a.TotalTokens)})
+	}
+	types := make([][]string, 0, len(r.BySessionType))
+	for _, t := range r.BySessionType {
+		types = append(types, []string{string(t.Type), itoa(t.Sessions), cost(t.TotalCost), itoa(t.TotalTokens)})
+	}
+	crons := make([][]string, 0, len(r.ByCron))
+	for _, c := range r.ByCron {
+		crons = append(crons, []string{c.CronName, itoa(c.Runs), cost(c.TotalCost), cost(c.AvgCost), cost(c.MaxCost), itoa(c.TotalTokens)})
+	}
+	models := make([][]string, 0, len(r.ByModel))
+	for _, m := range r.ByModel {
+		models = append(models, []string{m.Model, itoa(m.Sessions), cost(m.TotalCost), itoa(m.InputTokens), itoa(m.OutputTokens), itoa(m.TotalTokens)})
+	}
+	days := make([][]string, 0, len(r.ByDay))
+	for _, d := range r.ByDay {
+		days = append(days, []string{d.Date, itoa(d.Sessions), cost(d.TotalCost), itoa(d.TotalTokens)})
+	}
+	months := make([][]string, 0, len(r.ByMonth))
+	for _, m := range r.ByMonth {
+		months = append(months, []string{m.Month, itoa(m.Sessions), cost(m.TotalCost), itoa(m.TotalTokens)})
+	}
+	anomalies := make([][]string, 0, len(r.Anomalies))
+	for _, a := range r.Anomalies {
+		anomalies = append(anomalies, []string{a.ID, a.Type, a.Severity, a.Agent, a.Cron, a.SessionID, cost(a.Cost), a.Muted, a.Description})
+	}
+	sessions := make([][]string, 0, len(r.Sessions))
+	for _, s := range r.Sessions {
+		sessions = append(sessions, []string{s.ID, s.Agent, s.Tenant, string(s.Type), s.CronName, s.Model, cost(s.Cost), itoa(s.Tokens), itoa(s.Turns),
+			s.StartedAt.Format(time.RFC3339), s.EndedAt.Format(time.RFC3339)})
+	}
+
+	for _, t := range []struct {
+		name   string
+		header []string
+		rows   [][]string
+	}{
+		{"summary.csv", []string{"period", "sessions", "cost", "tokens"}, summary},
+		{"budgets.csv", []string{"period", "scope", "name", "from", "spent", "limit", "percent", "exceeded"}, budgets},
+		{"forecast.csv", []string{"month", "agent", "tenant", "month_to_date", "linear", "trailing_daily", "trailing"}, forecast},
+		{"tenants.csv", []string{"tenant", "agents", "sessions", "cost", "tokens"}, tenants},
+		{"workspaces.csv", []string{"workspace", "tenant", "agents", "sessions", "cost", "tokens"}, workspaces},
+		{"agents.csv", []string{"agent", "tenant", "sessions", "cost", "input_tokens", "output_tokens", "tokens"}, agents},
+		{"session_types.csv", []string{"type", "sessions", "cost", "tokens"}, types},
+		{"crons.csv", []string{"cron", "runs", "cost", "avg_cost", "max_cost", "tokens"}, crons},
+		{"models.csv", []string{"model", "sessions", "cost", "input_tokens", "output_tokens", "tokens"}, models},
+		{"days.csv", []string{"date", "sessions", "cost", "tokens"}, days},
+		{"months.csv", []string{"month", "sessions", "cost", "tokens"}, months},
+		{"anomalies.csv", []string{"id", "type", "severity", "agent", "cron", "session_id", "cost", "muted", "description"}, anomalies},
+		{"sessions.csv", []string{"id", "agent", "tenant", "type", "cron", "model", "cost", "tokens", "turns", "started_at", "ended_at"}, sessions},
+	} {
+		if err := add(t.name, t.header, t.rows); err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// CSVFormatter outputs a report's tables (see CSVTables) as CSV blocks,
+// for spreadsheet import: each block is a row naming the table, e.g.
+// "agents", followed by the table, and blocks are separated by a blank
+// line. With Style.BOM, the output starts with one byte order mark.
+type CSVFormatter struct {
+	Style CSVStyle
+}
+
+// NewCSVFormatter creates a new CSV formatter.
+func NewCSVFormatter() *CSVFormatter {
+	return &CSVFormatter{}
+}
+
+// Format formats the report's tables as CSV blocks.
+func (f *CSVFormatter) Format(r reporter.Report) (string, error) {
+	style := f.Style
+	style.BOM = false
+	tables, err := CSVTables(r, style)
+	if err != nil {
+		return "", err
+	}
+	eol := "\n"
+	if style.CRLF {
+		eol = "\r\n"
+	}
+	blocks := make([]string, len(tables))
+	for i, t := range tables {
+		blocks[i] = strings.TrimSuffix(t.Name, ".csv") + eol + string(t.Data)
+	}
+	out := strings.Join(blocks, eol)
+	if f.Style.BOM {
+		out = utf8BOM + out
+	}
+	return out, nil
+}
@@ -0,0 +1,78 @@
+package formats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DateLayouts are the named date formats that can be chosen for
+// human-readable output. JSON, Grafana and porcelain output always use
+// ISO 8601.
+var DateLayouts = map[string]string{
+	"iso":  "2006-01-02",
+	"us":   "01/02/2006",
+	"eu":   "02.01.2006",
+	"uk":   "02/01/2006",
+	"long": "2 Jan 2006",
+}
+
+// DateStyle controls how the text, HTML and diff formatters display dates.
+// The zero value shows ISO 8601 dates without week numbers.
+type DateStyle struct {
+	Layout   string // time layout for dates; ISO 8601 when empty
+	ISOWeeks bool   // show ISO-8601 week numbers (2026-W10) beside days
+}
+
+// NewDateStyle returns the style for a named layout (see DateLayouts); an
+// empty name selects "iso".
+func NewDateStyle(name string, isoWeeks bool) (DateStyle, error) {
+	if name == "" {
+		name = "iso"
+	}
+	layout, ok := DateLayouts[name]
+	if !ok {
+		names := make([]string, 0, len(DateLayouts))
+		for n := range DateLayouts {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return DateStyle{}, fmt.Errorf("invalid date format: %s (valid: %s)", name, strings.Join(names, ", "))
+	}
+	return DateStyle{Layout: layout, ISOWeeks: isoWeeks}, nil
+}
+
+// Date formats the date part of t.
+func (s DateStyle) Date(t time.Time) string {
+	if s.Layout == "" {
+		return t.Format(DateLayouts["iso"])
+	}
+	return t.Format(s.Layout)
+}
+
+// Time formats t as a date followed by the time of day and zone.
+func (s DateStyle) Time(t time.Time) string {
+	return s.Date(t) + t.Format(" 15:04 MST")
+}
+
+// Day reformats a YYYY-MM-DD date as used in reports. Values that do not
+// parse are returned unchanged.
+func (s DateStyle) Day(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return s.Date(t)
+}
+
+// Week returns the ISO-8601 week of a YYYY-MM-DD date, e.g. "2026-W10",
+// or "" if it does not parse.
+func (s DateStyle) Week(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return ""
+	}
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
@@ -0,0 +1,88 @@
+package formats
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/reporter"
+)
+
+// FormatDiff renders a period comparison as text waterfalls: the starting
+// total, each group's contribution to the change (largest first), and the
+// ending total. Groups beyond top are folded into one "others" step.
+func FormatDiff(d reporter.Diff, top int, dates DateStyle) string {
+	var b strings.Builder
+
+	b.WriteString("╔════════════════════════════════════════════════════════════════╗\n")
+	b.WriteString("║              OpenClaw Cost Diff                                ║\n")
+	b.WriteString("╚════════════════════════════════════════════════════════════════╝\n\n")
+
+	b.WriteString(fmt.Sprintf("Period:  %s\n", d.Period))
+	b.WriteString(fmt.Sprintf("Before:  %s → %s  %s\n",
+		dates.Time(d.BeforeFrom), dates.Time(d.BeforeUntil), parser.FormatCost(d.BeforeCost)))
+	writeNotes(&b, d.BeforeNotes)
+	b.WriteString(fmt.Sprintf("After:   %s → %s  %s\n",
+		dates.Time(d.AfterFrom), dates.Time(d.AfterUntil), parser.FormatCost(d.AfterCost)))
+	writeNotes(&b, d.AfterNotes)
+	b.WriteString(fmt.Sprintf("Change:  %s", formatDelta(d.Delta)))
+	if d.BeforeCost > 0 {
+		b.WriteString(fmt.Sprintf(" (%+.1f%%)", d.Delta/d.BeforeCost*100))
+	}
+	b.WriteString("\n\n")
+
+	writeWaterfall(&b, "WATERFALL BY AGENT", d, d.ByAgent, top)
+	writeWaterfall(&b, "WATERFALL BY CRON JOB", d, d.ByCron, top)
+	writeWaterfall(&b, "WATERFALL BY MODEL", d, d.ByModel, top)
+
+	return b.String()
+}
+
+func writeWaterfall(b *strings.Builder, title string, d reporter.Diff, steps []reporter.Contribution, top int) {
+	if len(steps) == 0 {
+		return
+	}
+	if top > 0 && len(steps) > top+1 {
+		others := reporter.Contribution{Key: fmt.Sprintf("(%d others)", len(steps)-top)}
+		for _, c := range steps[top:] {
+			others.Before += c.Before
+			others.After += c.After
+			others.Delta += c.Delta
+			others.Share += c.Share
+		}
+		steps = append(steps[:top:top], others)
+	}
+
+	var maxDelta float64
+	for _, c := range steps {
+		maxDelta = math.Max(maxDelta, math.Abs(c.Delta))
+	}
+
+	b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	b.WriteString(fmt.Sprintf(" %s\n", title))
+	b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	b.WriteString(fmt.Sprintf("  %-25s %10s\n", "Before", parser.FormatCost(d.BeforeCost)))
+	for _, c := range steps {
+		name := c.Key
+		if len(name) > 25 {
+			name = name[:22] + "..."
+		}
+		share := ""
+		if d.Delta != 0 {
+			share = fmt.Sprintf("%+.0f%%", c.Share*100)
+		}
+		b.WriteString(fmt.Sprintf("  %-25s %10s %10s %7s  %s\n",
+			name, "", formatDelta(c.Delta), share, textBar(math.Abs(c.Delta), maxDelta, barWidth)))
+	}
+	b.WriteString(fmt.Sprintf("  %-25s %10s\n", "After", parser.FormatCost(d.AfterCost)))
+	b.WriteString("\n")
+}
+
+// formatDelta formats a signed cost change, e.g. "+$1.20" or "-$0.0040".
+func formatDelta(delta float64) string {
+	if delta < 0 {
+		return "-" + parser.FormatCost(-delta)
+	}
+	return "+" + parser.FormatCost(delta)
+}
@@ -0,0 +1,157 @@
+package formats
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/reporter"
+)
+
+// fleetAnomalyLines is how many anomalies of one type a host may list in
+// a text fleet report before they are summarized.
+const fleetAnomalyLines = 3
+
+// FormatFleet renders a fleet rollup as text: spend per host, each agent's
+// spend with a column per host, the costliest crons across the fleet, and
+// anomalies. Unless wide, long host, agent and cron names are truncated.
+func FormatFleet(f reporter.FleetReport, wide bool) string {
+	var b strings.Builder
+
+	b.WriteString("╔════════════════════════════════════════════════════════════════╗\n")
+	b.WriteString("║              OpenClaw Fleet Report                             ║\n")
+	b.WriteString("╚════════════════════════════════════════════════════════════════╝\n\n")
+
+	b.WriteString(fmt.Sprintf("Generated: %s\n", f.GeneratedAt.Format(time.RFC3339)))
+	if f.Period != "" {
+		b.WriteString(fmt.Sprintf("Period:    %s\n", f.Period))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	b.WriteString(" SUMMARY\n")
+	b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	b.WriteString(fmt.Sprintf("  Hosts:          %d\n", len(f.Hosts)))
+	b.WriteString(fmt.Sprintf("  Total Sessions: %d\n", f.TotalSessions))
+	b.WriteString(fmt.Sprintf("  Total Cost:     %s\n", parser.FormatCost(f.TotalCost)))
+	b.WriteString(fmt.Sprintf("  Total Tokens:   %s\n", parser.FormatTokens(f.TotalTokens)))
+	b.WriteString("\n")
+
+	hosts := make([]string, len(f.Hosts))
+	for i, h := range f.Hosts {
+		hosts[i] = h.Host
+	}
+
+	if len(f.Hosts) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" BY HOST\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		width := ColumnWidth("HOST", hosts, maxAgentWidth, wide)
+		b.WriteString(fmt.Sprintf("  %-*s %6s %8s %12s %6s %12s %9s\n", width, "HOST", "AGENTS", "SESSIONS", "COST", "SHARE", "TOKENS", "ANOMALIES"))
+		maxCost := f.Hosts[0].TotalCost
+		for _, h := range f.Hosts {
+			b.WriteString(fmt.Sprintf("  %-*s %6d %8d %12s %5.0f%% %12s %9d  %s\n",
+				width, Truncate(h.Host, width),
+				h.Agents,
+				h.Sessions,
+				parser.FormatCost(h.TotalCost),
+				h.Share*100,
+				parser.FormatTokens(h.TotalTokens),
+				h.Anomalies,
+				textBar(h.TotalCost, maxCost, barWidth)))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(f.ByAgent) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" BY AGENT AND HOST\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		names := make([]string, len(f.ByAgent))
+		for i, a := range f.ByAgent {
+			names[i] = a.Agent
+		}
+		width := ColumnWidth("AGENT", names, maxAgentWidth, wide)
+		hostWidths := make([]int, len(hosts))
+		b.WriteString(fmt.Sprintf("  %-*s", width, "AGENT"))
+		for i, h := range hosts {
+			hostWidths[i] = max(10, ColumnWidth(h, nil, maxAgentWidth, wide))
+			b.WriteString(fmt.Sprintf(" %*s", hostWidths[i], Truncate(h, hostWidths[i])))
+		}
+		b.WriteString(fmt.Sprintf(" %10s\n", "TOTAL"))
+		for _, a := range f.ByAgent {
+			b.WriteString(fmt.Sprintf("  %-*s", width, Truncate(a.Agent, width)))
+			for i, cost := range a.ByHost {
+				cell := "-"
+				if cost > 0 {
+					cell = parser.FormatCost(cost)
+				}
+				b.WriteString(fmt.Sprintf(" %*s", hostWidths[i], cell))
+			}
+			b.WriteString(fmt.Sprintf(" %10s\n", parser.FormatCost(a.TotalCost)))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(f.TopCrons) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" TOP CRON JOBS\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		names := make([]string, len(f.TopCrons))
+		for i, c := range f.TopCrons {
+			names[i] = c.CronName
+		}
+		width := ColumnWidth("CRON NAME", names, maxNameWidth, wide)
+		b.WriteString(fmt.Sprintf("  %-*s %5s %6s %10s %10s\n", width, "CRON NAME", "HOSTS", "RUNS", "TOTAL", "AVG"))
+		maxCost := f.TopCrons[0].TotalCost
+		for _, c := range f.TopCrons {
+			b.WriteString(fmt.Sprintf("  %-*s %5d %6d %10s %10s  %s\n",
+				width, Truncate(c.CronName, width),
+				len(c.Hosts),
+				c.Runs,
+				parser.FormatCost(c.TotalCost),
+				parser.FormatCost(c.AvgCost),
+				textBar(c.TotalCost, maxCost, barWidth)))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(f.Anomalies) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" ANOMALIES\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		// A host's anomalies of one type are counted rather than listed
+		// when there are more than fleetAnomalyLines of them.
+		type group struct{ host, kind string }
+		counts := make(map[group]int)
+		costs := make(map[group]float64)
+		summarized := make(map[group]bool)
+		for _, a := range f.Anomalies {
+			g := group{a.Host, a.Type}
+			counts[g]++
+			costs[g] += a.Cost
+		}
+		for _, a := range f.Anomalies {
+			severity := "⚠️ "
+			if a.Severity == "error" {
+				severity = "❌"
+			}
+			g := group{a.Host, a.Type}
+			if n := counts[g]; n > fleetAnomalyLines {
+				if !summarized[g] {
+					b.WriteString(fmt.Sprintf("  %s [%s] %s: %d anomalies (%s)\n", severity, a.Type, a.Host, n, parser.FormatCost(costs[g])))
+					summarized[g] = true
+				}
+				continue
+			}
+			b.WriteString(fmt.Sprintf("  %s [%s] %s: %s\n", severity, a.Type, a.Host, a.Description))
+			if a.Owner != nil {
+				b.WriteString(fmt.Sprintf("     Owner: %s\n", a.Owner))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -5,6 +5,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"slices"
 	"strings"
 	"time"
 
@@ -27,19 +29,219 @@ func NewJSONFormatter() *JSONFormatter {
 	return &JSONFormatter{Pretty: true}
 }
 
+// NewCompactJSONFormatter creates a JSON formatter that emits minified output.
+func NewCompactJSONFormatter() *JSONFormatter {
+	return &JSONFormatter{Pretty: false}
+}
+
 // Format formats the report as JSON.
 func (f *JSONFormatter) Format(report reporter.Report) (string, error) {
 	var buf bytes.Buffer
 	encoder := json.NewEncoder(&buf)
-	encoder.SetIndent("", "  ")
+	if f.Pretty {
+		encoder.SetIndent("", "  ")
+	}
 	if err := encoder.Encode(report); err != nil {
 		return "", err
 	}
 	return buf.String(), nil
 }
 
+// StreamRecord is a single line of streamed (NDJSON) report output.
+type StreamRecord struct {
+	Section string      `json:"section"`
+	Data    interface{} `json:"data"`
+}
+
+// StreamSummary is the first record emitted when streaming a report. It
+// carries the report's fields that are not rows, with the names they have
+// in the report.
+type StreamSummary struct {
+	GeneratedAt   time.Time  `json:"generated_at"`
+	AsOf          *time.Time `json:"as_of,omitempty"`
+	Period        string     `json:"period"`
+	Since         *time.Time `json:"since,omitempty"`
+	Until         *time.Time `json:"until,omitempty"`
+	TotalCost     float64    `json:"total_cost"`
+	TotalTokens   int        `json:"total_tokens"`
+	TotalSessions int        `json:"total_sessions"`
+	EstimatedCost float64    `json:"estimated_cost,omitempty"`
+	TypeMixBucket string     `json:"type_mix_bucket,omitempty"`
+	Sections      []string   `json:"sections,omitempty"`
+
+	Compared   *reporter.Comparison `json:"compared,omitempty"`
+	Partial    *reporter.Coverage   `json:"partial,omitempty"`
+	Provenance *reporter.Provenance `json:"provenance,omitempty"`
+}
+
+// Stream writes the report as newline-delimited JSON, one record per array
+// element, so consumers can process rows without buffering the whole report.
+func (f *JSONFormatter) Stream(w io.Writer, r reporter.Report) error {
+	encoder := json.NewEncoder(w)
+	emit := func(section string, data interface{}) error {
+		return encoder.Encode(StreamRecord{Section: section, Data: data})
+	}
+
+	if err := emit("summary", StreamSummary{
+		GeneratedAt:   r.GeneratedAt,
+		AsOf:          r.AsOf,
+		Period:        r.Period,
+		Since:         r.Since,
+		Until:         r.Until,
+		TotalCost:     r.TotalCost,
+		TotalTokens:   r.TotalTokens,
+		TotalSessions: r.TotalSessions,
+		EstimatedCost: r.EstimatedCost,
+		TypeMixBucket: r.TypeMixBucket,
+		Sections:      r.Sections,
+		Compared:      r.Compared,
+		Partial:       r.Partial,
+		Provenance:    r.Provenance,
+	}); err != nil {
+		return err
+	}
+	for _, k := range r.KPIs {
+		if err := emit("kpis", k); err != nil {
+			return err
+		}
+	}
+	for _, s := range r.Budgets {
+		if err := emit("budgets", s); err != nil {
+			return err
+		}
+	}
+	if r.Forecast != nil {
+		if err := emit("forecast", r.Forecast); err != nil {
+			return err
+		}
+	}
+	for _, t := range r.ByTenant {
+		if err := emit("by_tenant", t); err != nil {
+			return err
+		}
+	}
+	for _, w := range r.ByWorkspace {
+		if err := emit("by_workspace", w); err != nil {
+			return err
+		}
+	}
+	for _, a := range r.ByAgent {
+		if err := emit("by_agent", a); err != nil {
+			return err
+		}
+	}
+	for _, t := range r.BySessionType {
+		if err := emit("by_session_type", t); err != nil {
+			return err
+		}
+	}
+	for _, t := range r.ByTopic {
+		if err := emit("by_topic", t); err != nil {
+			return err
+		}
+	}
+	for _, c := range r.ByCron {
+		if err := emit("by_cron", c); err != nil {
+			return err
+		}
+	}
+	for _, c := range r.CronSlots {
+		if err := emit("cron_slots", c); err != nil {
+			return err
+		}
+	}
+	for _, c := range r.CronCache {
+		if err := emit("cron_cache", c); err != nil {
+			return err
+		}
+	}
+	for _, c := range r.CronGrowth {
+		if err := emit("cron_growth", c); err != nil {
+			return err
+		}
+	}
+	for _, m := range r.ByModel {
+		if err := emit("by_model", m); err != nil {
+			return err
+		}
+	}
+	for _, d := range r.ByDay {
+		if err := emit("by_day", d); err != nil {
+			return err
+		}
+	}
+	for _, m := range r.ByMonth {
+		if err := emit("by_month", m); err != nil {
+			return err
+		}
+	}
+	for _, s := range r.ByRole {
+		if err := emit("by_role", s); err != nil {
+			return err
+		}
+	}
+	for _, t := range r.ByTurn {
+		if err := emit("by_turn", t); err != nil {
+			return err
+		}
+	}
+	for _, e := range r.Efficiency {
+		if err := emit("efficiency", e); err != nil {
+			return err
+		}
+	}
+	for _, e := range r.ByError {
+		if err := emit("by_error", e); err != nil {
+			return err
+		}
+	}
+	for _, c := range r.ByCascade {
+		if err := emit("by_cascade", c); err != nil {
+			return err
+		}
+	}
+	for _, m := range r.TypeMix {
+		if err := emit("type_mix", m); err != nil {
+			return err
+		}
+	}
+	for _, d := range r.ByAgentDay {
+		if err := emit("by_agent_day", d); err != nil {
+			return err
+		}
+	}
+	for _, a := range r.Anomalies {
+		if err := emit("anomalies", a); err != nil {
+			return err
+		}
+	}
+	for _, d := range r.Deprecations {
+		if err := emit("deprecations", d); err != nil {
+			return err
+		}
+	}
+	for _, v := range r.Compliance {
+		if err := emit("compliance", v); err != nil {
+			return err
+		}
+	}
+	for _, s := range r.Sessions {
+		if err := emit("sessions", s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // TextFormatter outputs reports in human-readable text format.
-type TextFormatter struct{}
+type TextFormatter struct {
+	Dates DateStyle
+	// Wide sizes name columns to their longest value instead of truncating
+	// long names.
+	Wide bool
+	// Costs sets the decimals and rounding of amounts.
+	Costs CostStyle
+}
 
 // NewTextFormatter creates a new text formatter.
 func NewTextFormatter() *TextFormatter {
@@ -56,32 +258,174 @@ func (f *TextFormatter) Format(r reporter.Report) (string, error) {
 	b.WriteString("╚════════════════════════════════════════════════════════════════╝\n\n")
 
 	b.WriteString(fmt.Sprintf("Generated: %s\n", r.GeneratedAt.Format(time.RFC3339)))
+	if r.AsOf != nil {
+		b.WriteString(fmt.Sprintf("As of:     %s\n", r.AsOf.Format(time.RFC3339)))
+	}
 	if r.Period != "" {
 		b.WriteString(fmt.Sprintf("Period:    %s\n", r.Period))
 	}
+	if r.Since != nil || r.Until != nil {
+		b.WriteString(fmt.Sprintf("Range:     %s\n", rangeLabel(r)))
+	}
 	b.WriteString("\n")
 
+	// Partial report
+	if c := r.Partial; c != nil {
+		b.WriteString(fmt.Sprintf("⚠ PARTIAL REPORT: parsing stopped after %s; %d of %d transcripts (%.1f%%) read, totals are understated\n",
+			c.MaxDuration, c.Parsed, c.Transcripts, c.Percent))
+		for _, a := range c.ByAgent {
+			if a.Parsed == a.Transcripts {
+				continue
+			}
+			name := a.Agent
+			if a.Tenant != "" {
+				name = a.Tenant + "/" + a.Agent
+			}
+			b.WriteString(fmt.Sprintf("  %-*s %5.1f%%  (%d of %d)\n", maxAgentWidth, Truncate(name, maxAgentWidth), a.Percent, a.Parsed, a.Transcripts))
+		}
+		b.WriteString("\n")
+	}
+
 	// Summary
-	b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-	b.WriteString(" SUMMARY\n")
-	b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-	b.WriteString(fmt.Sprintf("  Total Sessions: %d\n", r.TotalSessions))
-	b.WriteString(fmt.Sprintf("  Total Cost:     %s\n", parser.FormatCost(r.TotalCost)))
-	b.WriteString(fmt.Sprintf("  Total Tokens:   %s\n", parser.FormatTokens(r.TotalTokens)))
-	b.WriteString("\n")
+	if r.Includes(reporter.SectionSummary) {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" SUMMARY\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(fmt.Sprintf("  Total Sessions: %d\n", r.TotalSessions))
+		b.WriteString(fmt.Sprintf("  Total Cost:     %s\n", f.Costs.Total(r.TotalCost)))
+		if r.EstimatedCost > 0 {
+			b.WriteString(fmt.Sprintf("  Estimated:      %s (self-hosted models at configured rates)\n", f.Costs.Total(r.EstimatedCost)))
+		}
+		b.WriteString(fmt.Sprintf("  Total Tokens:   %s\n", parser.FormatTokens(r.TotalTokens)))
+		if len(r.KPIs) > 0 {
+			b.WriteString("  KPIs:\n")
+			for _, k := range r.KPIs {
+				b.WriteString(fmt.Sprintf("    %s %-18s %10s  target %-16s %s\n",
+					kpiMark(k), k.Metric, kpiValue(k.Metric, k.Value), kpiTarget(k), kpiTrend(k)))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	// Budgets
+	if len(r.Budgets) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" BUDGETS\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		names := make([]string, len(r.Budgets))
+		for i, s := range r.Budgets {
+			names[i] = budgetName(s)
+		}
+		width := ColumnWidth("NAME", names, maxNameWidth, f.Wide)
+		b.WriteString(fmt.Sprintf("  %-8s %-5s %-*s %10s %10s %12s\n", "PERIOD", "SCOPE", width, "NAME", "SPENT", "LIMIT", "USED"))
+		for i, s := range r.Budgets {
+			b.WriteString(fmt.Sprintf("  %-8s %-5s %-*s %10s %10s %12s  %s\n",
+				s.Period, s.Scope,
+				width, Truncate(names[i], width),
+				f.Costs.Cost(s.Spent),
+				f.Costs.Cost(s.Limit),
+				budgetUsed(s),
+				textBar(s.Spent, s.Limit, barWidth)))
+		}
+		b.WriteString("\n")
+	}
+
+	// Forecast
+	if fc := r.Forecast; fc != nil {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(fmt.Sprintf(" FORECAST (%s, day %d of %d)\n", fc.Month, fc.Day, fc.DaysInMonth))
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		names := make([]string, len(fc.ByAgent))
+		for i, a := range fc.ByAgent {
+			names[i] = forecastAgent(a)
+		}
+		width := ColumnWidth("AGENT", names, maxAgentWidth, f.Wide)
+		row := func(name string, p reporter.Projection) {
+			b.WriteString(fmt.Sprintf("  %-*s %12s %12s %12s %12s\n",
+				width, Truncate(name, width),
+				f.Costs.Cost(p.MonthToDate),
+				f.Costs.Cost(p.Linear),
+				f.Costs.Cost(p.TrailingDaily),
+				f.Costs.Cost(p.Trailing)))
+		}
+		b.WriteString(fmt.Sprintf("  %-*s %12s %12s %12s %12s\n", width, "AGENT", "SPENT", "LINEAR", "7D DAILY", "7D TREND"))
+		row("Total", fc.Projection)
+		for i, a := range fc.ByAgent {
+			row(names[i], a.Projection)
+		}
+		b.WriteString("\n")
+	}
+
+	// By Tenant
+	if len(r.ByTenant) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" BY TENANT\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		names := make([]string, len(r.ByTenant))
+		for i, t := range r.ByTenant {
+			names[i] = t.Tenant
+		}
+		width := ColumnWidth("TENANT", names, maxAgentWidth, f.Wide)
+		b.WriteString(fmt.Sprintf("  %-*s %6s %8s %12s %12s\n", width, "TENANT", "AGENTS", "SESSIONS", "COST", "TOKENS"))
+		for _, t := range r.ByTenant {
+			b.WriteString(fmt.Sprintf("  %-*s %6d %8d %12s %12s\n",
+				width, Truncate(t.Tenant, width),
+				t.Agents,
+				t.Sessions,
+				f.Costs.Cost(t.TotalCost),
+				parser.FormatTokens(t.TotalTokens)))
+		}
+		b.WriteString("\n")
+	}
+
+	// By Workspace
+	if len(r.ByWorkspace) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" BY WORKSPACE\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		names := make([]string, len(r.ByWorkspace))
+		for i, w := range r.ByWorkspace {
+			names[i] = workspaceName(w)
+		}
+		width := ColumnWidth("WORKSPACE", names, maxAgentWidth, f.Wide)
+		b.WriteString(fmt.Sprintf("  %-*s %6s %8s %12s %12s\n", width, "WORKSPACE", "AGENTS", "SESSIONS", "COST", "TOKENS"))
+		for i, w := range r.ByWorkspace {
+			b.WriteString(fmt.Sprintf("  %-*s %6d %8d %12s %12s\n",
+				width, Truncate(names[i], width),
+				w.Agents,
+				w.Sessions,
+				f.Costs.Cost(w.TotalCost),
+				parser.FormatTokens(w.TotalTokens)))
+		}
+		b.WriteString("\n")
+	}
 
 	// By Agent
 	if len(r.ByAgent) > 0 {
 		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 		b.WriteString(" BY AGENT\n")
 		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		b.WriteString(fmt.Sprintf("  %-12s %8s %12s %12s\n", "AGENT", "SESSIONS", "COST", "TOKENS"))
-		for _, a := range r.ByAgent {
-			b.WriteString(fmt.Sprintf("  %-12s %8d %12s %12s\n",
-				a.Agent,
+		names := make([]string, len(r.ByAgent))
+		for i, a := range r.ByAgent {
+			names[i] = a.Agent
+			if a.Tenant != "" {
+				names[i] = a.Tenant + "/" + a.Agent
+			}
+		}
+		width := ColumnWidth("AGENT", names, maxAgentWidth, f.Wide)
+		b.WriteString(fmt.Sprintf("  %-*s %8s %12s %12s\n", width, "AGENT", "SESSIONS", "COST", "TOKENS"))
+		maxCost := r.ByAgent[0].TotalCost
+		for i, a := range r.ByAgent {
+			bar := textBar(a.TotalCost, maxCost, barWidth)
+			if a.Idle {
+				bar = "idle"
+			}
+			b.WriteString(fmt.Sprintf("  %-*s %8d %12s %12s  %s\n",
+				width, Truncate(names[i], width),
 				a.Sessions,
-				parser.FormatCost(a.TotalCost),
-				parser.FormatTokens(a.TotalTokens)))
+				f.Costs.Cost(a.TotalCost),
+				parser.FormatTokens(a.TotalTokens),
+				bar))
 		}
 		b.WriteString("\n")
 	}
@@ -96,29 +440,168 @@ func (f *TextFormatter) Format(r reporter.Report) (string, error) {
 			b.WriteString(fmt.Sprintf("  %-15s %8d %12s %12s\n",
 				t.Type,
 				t.Sessions,
-				parser.FormatCost(t.TotalCost),
+				f.Costs.Cost(t.TotalCost),
 				parser.FormatTokens(t.TotalTokens)))
 		}
 		b.WriteString("\n")
 	}
 
+	// By Topic
+	if len(r.ByTopic) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" BY TOPIC\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		names := make([]string, len(r.ByTopic))
+		for i, t := range r.ByTopic {
+			names[i] = t.Topic
+		}
+		width := ColumnWidth("TOPIC", names, maxAgentWidth, f.Wide)
+		b.WriteString(fmt.Sprintf("  %-*s %8s %12s %12s %6s\n", width, "TOPIC", "SESSIONS", "COST", "TOKENS", "SHARE"))
+		for _, t := range r.ByTopic {
+			b.WriteString(fmt.Sprintf("  %-*s %8d %12s %12s %5.1f%%\n",
+				width, Truncate(t.Topic, width),
+				t.Sessions,
+				f.Costs.Cost(t.TotalCost),
+				parser.FormatTokens(t.TotalTokens),
+				t.Share))
+		}
+		b.WriteString("\n")
+	}
+
 	// By Cron
 	if len(r.ByCron) > 0 {
 		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 		b.WriteString(" BY CRON JOB\n")
 		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		b.WriteString(fmt.Sprintf("  %-25s %6s %10s %10s %10s\n", "CRON NAME", "RUNS", "TOTAL", "AVG", "MAX"))
+		names := make([]string, len(r.ByCron))
+		for i, c := range r.ByCron {
+			names[i] = c.CronName
+		}
+		width := ColumnWidth("CRON NAME", names, maxNameWidth, f.Wide)
+		expected := slices.ContainsFunc(r.ByCron, func(c reporter.CronSummary) bool { return c.Expected != nil })
+		if expected {
+			b.WriteString(fmt.Sprintf("  %-*s %6s %10s %11s %10s %13s\n", width, "CRON NAME", "RUNS", "TOTAL", "AVG", "MAX", "EXPECTED"))
+		} else {
+			b.WriteString(fmt.Sprintf("  %-*s %6s %10s %10s %10s\n", width, "CRON NAME", "RUNS", "TOTAL", "AVG", "MAX"))
+		}
+		maxCost := r.ByCron[0].TotalCost
 		for _, c := range r.ByCron {
-			name := c.CronName
-			if len(name) > 25 {
-				name = name[:22] + "..."
+			if expected {
+				b.WriteString(fmt.Sprintf("  %-*s %6d %10s %10s%s %10s %13s  %s\n",
+					width, Truncate(c.CronName, width),
+					c.Runs,
+					f.Costs.Cost(c.TotalCost),
+					f.Costs.Cost(c.AvgCost),
+					deviationMark(c.Deviation),
+					f.Costs.Cost(c.MaxCost),
+					expectedCost(c.Expected),
+					textBar(c.TotalCost, maxCost, barWidth)))
+			} else {
+				b.WriteString(fmt.Sprintf("  %-*s %6d %10s %10s %10s  %s\n",
+					width, Truncate(c.CronName, width),
+					c.Runs,
+					f.Costs.Cost(c.TotalCost),
+					f.Costs.Cost(c.AvgCost),
+					f.Costs.Cost(c.MaxCost),
+					textBar(c.TotalCost, maxCost, barWidth)))
+			}
+			if c.Owner != nil {
+				b.WriteString(fmt.Sprintf("    owner: %s\n", c.Owner))
+			}
+			writeNotes(&b, c.Notes)
+		}
+		if expected {
+			b.WriteString("  ↑/↓ average run above/below its expected cost\n")
+		}
+		b.WriteString("\n")
+	}
+
+	// Cron run slots
+	if len(r.CronSlots) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" CRON RUN SLOTS\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		names := make([]string, len(r.CronSlots))
+		agents := make([]string, len(r.CronSlots))
+		for i, c := range r.CronSlots {
+			names[i] = c.CronName
+			agents[i] = c.Agent
+		}
+		width := ColumnWidth("CRON NAME", names, maxNameWidth, f.Wide)
+		agentWidth := ColumnWidth("AGENT", agents, maxAgentWidth, f.Wide)
+		b.WriteString(fmt.Sprintf("  %-*s %-*s %6s %6s %10s %10s %6s\n", width, "CRON NAME", agentWidth, "AGENT", "SLOT", "RUNS", "AVG", "MAX", "REL"))
+		for _, c := range r.CronSlots {
+			b.WriteString(fmt.Sprintf("  %-*s %-*s %6s %6d %10s %10s %5.1fx\n",
+				width, Truncate(c.CronName, width),
+				agentWidth, Truncate(c.Agent, agentWidth),
+				c.Slot,
+				c.Runs,
+				f.Costs.Cost(c.AvgCost),
+				f.Costs.Cost(c.MaxCost),
+				c.Relative))
+		}
+		b.WriteString("\n")
+	}
+
+	// Cron cache warm-up
+	if len(r.CronCache) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" CRON CACHE WARM-UP\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		names := make([]string, len(r.CronCache))
+		agents := make([]string, len(r.CronCache))
+		for i, c := range r.CronCache {
+			names[i] = c.CronName
+			agents[i] = c.Agent
+		}
+		width := ColumnWidth("CRON NAME", names, maxNameWidth, f.Wide)
+		agentWidth := ColumnWidth("AGENT", agents, maxAgentWidth, f.Wide)
+		b.WriteString(fmt.Sprintf("  %-*s %-*s %5s %10s %5s %10s %8s\n", width, "CRON NAME", agentWidth, "AGENT", "COLD", "COLD AVG", "WARM", "WARM AVG", "PAYOFF"))
+		for _, c := range r.CronCache {
+			coldAvg, warmAvg, payoff := "-", "-", "-"
+			if c.ColdRuns > 0 {
+				coldAvg = f.Costs.Cost(c.ColdAvgCost)
+			}
+			if c.WarmRuns > 0 {
+				warmAvg = f.Costs.Cost(c.WarmAvgCost)
+			}
+			if c.PayoffRuns > 0 {
+				payoff = fmt.Sprintf("%.1f runs", c.PayoffRuns)
 			}
-			b.WriteString(fmt.Sprintf("  %-25s %6d %10s %10s %10s\n",
-				name,
+			b.WriteString(fmt.Sprintf("  %-*s %-*s %5d %10s %5d %10s %8s\n",
+				width, Truncate(c.CronName, width), agentWidth, Truncate(c.Agent, agentWidth), c.ColdRuns, coldAvg, c.WarmRuns, warmAvg, payoff))
+		}
+		b.WriteString("\n")
+	}
+
+	// Cron context growth
+	if len(r.CronGrowth) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" CRON CONTEXT GROWTH (prompt tokens per run)\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		names := make([]string, len(r.CronGrowth))
+		agents := make([]string, len(r.CronGrowth))
+		for i, c := range r.CronGrowth {
+			names[i] = c.CronName
+			agents[i] = c.Agent
+		}
+		width := ColumnWidth("CRON NAME", names, maxNameWidth, f.Wide)
+		agentWidth := ColumnWidth("AGENT", agents, maxAgentWidth, f.Wide)
+		b.WriteString(fmt.Sprintf("  %-*s %-*s %6s %8s %8s %10s %10s  %s\n", width, "CRON NAME", agentWidth, "AGENT", "RUNS", "PROMPT", "PER RUN", "RUN NOW", "IN 30D", "TREND"))
+		for _, c := range r.CronGrowth {
+			b.WriteString(fmt.Sprintf("  %-*s %-*s %6d %8s %8s %10s %10s  %s",
+				width, Truncate(c.CronName, width),
+				agentWidth, Truncate(c.Agent, agentWidth),
 				c.Runs,
-				parser.FormatCost(c.TotalCost),
-				parser.FormatCost(c.AvgCost),
-				parser.FormatCost(c.MaxCost)))
+				parser.FormatTokens(c.PromptTokens[len(c.PromptTokens)-1]),
+				fmt.Sprintf("%+.0f", c.GrowthPerRun),
+				f.Costs.Cost(c.LastRunCost),
+				f.Costs.Cost(c.ProjectedRun),
+				sparkline(c.PromptTokens, sparkWidth)))
+			if c.Growing {
+				b.WriteString("  growing")
+			}
+			b.WriteString("\n")
 		}
 		b.WriteString("\n")
 	}
@@ -128,17 +611,23 @@ func (f *TextFormatter) Format(r reporter.Report) (string, error) {
 		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 		b.WriteString(" BY MODEL\n")
 		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		b.WriteString(fmt.Sprintf("  %-35s %8s %10s %10s\n", "MODEL", "SESSIONS", "COST", "TOKENS"))
+		names := make([]string, len(r.ByModel))
+		for i, m := range r.ByModel {
+			names[i] = m.Model
+		}
+		width := ColumnWidth("MODEL", names, maxNameWidth, f.Wide)
+		b.WriteString(fmt.Sprintf("  %-*s %8s %10s %10s\n", width, "MODEL", "SESSIONS", "COST", "TOKENS"))
 		for _, m := range r.ByModel {
-			model := m.Model
-			if len(model) > 35 {
-				model = model[:32] + "..."
+			estimated := ""
+			if m.Estimated {
+				estimated = "  estimated"
 			}
-			b.WriteString(fmt.Sprintf("  %-35s %8d %10s %10s\n",
-				model,
+			b.WriteString(fmt.Sprintf("  %-*s %8d %10s %10s%s\n",
+				width, Truncate(m.Model, width),
 				m.Sessions,
-				parser.FormatCost(m.TotalCost),
-				parser.FormatTokens(m.TotalTokens)))
+				f.Costs.Cost(m.TotalCost),
+				parser.FormatTokens(m.TotalTokens),
+				estimated))
 		}
 		b.WriteString("\n")
 	}
@@ -148,13 +637,186 @@ func (f *TextFormatter) Format(r reporter.Report) (string, error) {
 		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 		b.WriteString(" DAILY TREND\n")
 		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		b.WriteString(fmt.Sprintf("  %-12s %8s %12s %12s\n", "DATE", "SESSIONS", "COST", "TOKENS"))
+		if f.Dates.ISOWeeks {
+			b.WriteString(fmt.Sprintf("  %-12s %-8s %8s %12s %12s\n", "DATE", "WEEK", "SESSIONS", "COST", "TOKENS"))
+		} else {
+			b.WriteString(fmt.Sprintf("  %-12s %8s %12s %12s\n", "DATE", "SESSIONS", "COST", "TOKENS"))
+		}
 		for _, d := range r.ByDay {
-			b.WriteString(fmt.Sprintf("  %-12s %8d %12s %12s\n",
-				d.Date,
+			b.WriteString(fmt.Sprintf("  %-12s ", f.Dates.Day(d.Date)))
+			if f.Dates.ISOWeeks {
+				b.WriteString(fmt.Sprintf("%-8s ", f.Dates.Week(d.Date)))
+			}
+			b.WriteString(fmt.Sprintf("%8d %12s %12s\n",
 				d.Sessions,
-				parser.FormatCost(d.TotalCost),
+				f.Costs.Cost(d.TotalCost),
 				parser.FormatTokens(d.TotalTokens)))
+			writeNotes(&b, d.Notes)
+		}
+		b.WriteString("\n")
+	}
+
+	// By Month (calendar periods)
+	if len(r.ByMonth) > 1 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" BY MONTH\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(fmt.Sprintf("  %-12s %8s %12s %12s\n", "MONTH", "SESSIONS", "COST", "TOKENS"))
+		for _, m := range r.ByMonth {
+			b.WriteString(fmt.Sprintf("  %-12s %8d %12s %12s\n",
+				m.Month,
+				m.Sessions,
+				f.Costs.Cost(m.TotalCost),
+				parser.FormatTokens(m.TotalTokens)))
+		}
+		b.WriteString("\n")
+	}
+
+	// By message role
+	if len(r.ByRole) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" TOKENS BY MESSAGE ROLE (estimated)\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		names := make([]string, len(r.ByRole))
+		for i, s := range r.ByRole {
+			names[i] = s.Agent
+			if names[i] == "" {
+				names[i] = "(all)"
+			}
+		}
+		width := ColumnWidth("AGENT", names, maxAgentWidth, f.Wide)
+		b.WriteString(fmt.Sprintf("  %-*s %9s %9s %9s %9s %9s %9s\n", width, "AGENT", "SYSTEM", "USER", "TOOL RES", "TEXT", "THINKING", "TOOL CALL"))
+		for i, s := range r.ByRole {
+			b.WriteString(fmt.Sprintf("  %-*s", width, Truncate(names[i], width)))
+			for _, role := range parser.Roles {
+				b.WriteString(fmt.Sprintf(" %8.1f%%", s.Share(role)*100))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	// Turn efficiency
+	if len(r.ByTurn) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" TURN EFFICIENCY\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		names := make([]string, len(r.ByTurn))
+		for i, t := range r.ByTurn {
+			names[i] = t.Agent
+			if names[i] == "" {
+				names[i] = "(all)"
+			}
+		}
+		width := ColumnWidth("AGENT", names, maxAgentWidth, f.Wide)
+		b.WriteString(fmt.Sprintf("  %-*s %8s %8s %10s %11s %8s\n", width, "AGENT", "SESSIONS", "TURNS", "TURNS/SESS", "TOKENS/TURN", "OUT/IN"))
+		for i, t := range r.ByTurn {
+			b.WriteString(fmt.Sprintf("  %-*s %8d %8d %10.1f %11s %8.2f",
+				width, Truncate(names[i], width), t.Sessions, t.Turns, t.TurnsPerSession, parser.FormatTokens(int(t.AvgTokensPerTurn)), t.OutputInputRatio))
+			if t.Chatty {
+				b.WriteString("  chatty")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	// Model efficiency
+	if len(r.Efficiency) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" MODEL EFFICIENCY (per message)\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		names := make([]string, len(r.Efficiency))
+		for i, e := range r.Efficiency {
+			names[i] = e.Model
+		}
+		width := ColumnWidth("MODEL", names, maxNameWidth, f.Wide)
+		b.WriteString(fmt.Sprintf("  %-*s %8s %8s %10s %8s\n", width, "MODEL", "SESSIONS", "MESSAGES", "COST/MSG", "SEC/MSG"))
+		for _, e := range r.Efficiency {
+			b.WriteString(fmt.Sprintf("  %-*s %8d %8d %10s %8.1f",
+				width, Truncate(e.Model, width), e.Sessions, e.Messages, f.Costs.Cost(e.CostPerMessage), e.SecondsPerMessage))
+			if e.Frontier {
+				b.WriteString("  frontier")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("  frontier: no other model is both cheaper and faster per message\n")
+		b.WriteString("\n")
+	}
+
+	// Failed requests
+	if len(r.ByError) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" API ERRORS AND RETRIES\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		agents := make([]string, len(r.ByError))
+		models := make([]string, len(r.ByError))
+		for i, e := range r.ByError {
+			agents[i], models[i] = e.Agent, e.Model
+		}
+		agentWidth := ColumnWidth("AGENT", agents, maxAgentWidth, f.Wide)
+		modelWidth := ColumnWidth("MODEL", models, maxNameWidth, f.Wide)
+		b.WriteString(fmt.Sprintf("  %-*s %-*s %6s %7s %6s %6s %10s %10s\n",
+			agentWidth, "AGENT", modelWidth, "MODEL", "ERRORS", "RETRIED", "429", "529", "COST", "DUPLICATED"))
+		for _, e := range r.ByError {
+			b.WriteString(fmt.Sprintf("  %-*s %-*s %6d %7d %6d %6d %10s %10s\n",
+				agentWidth, Truncate(e.Agent, agentWidth),
+				modelWidth, Truncate(e.Model, modelWidth),
+				e.Errors, e.Retried, e.RateLimited, e.Overloaded,
+				f.Costs.Cost(e.Cost), f.Costs.Cost(e.RetriedCost)))
+		}
+		b.WriteString("\n")
+	}
+
+	// Model escalations
+	if len(r.ByCascade) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" MODEL ESCALATIONS\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		agents := make([]string, len(r.ByCascade))
+		models := make([]string, 0, 2*len(r.ByCascade))
+		for i, c := range r.ByCascade {
+			agents[i] = c.Agent
+			models = append(models, c.From, c.To)
+		}
+		agentWidth := ColumnWidth("AGENT", agents, maxAgentWidth, f.Wide)
+		modelWidth := ColumnWidth("FROM", models, maxNameWidth, f.Wide)
+		b.WriteString(fmt.Sprintf("  %-*s %-*s %-*s %8s %8s %10s %10s\n",
+			agentWidth, "AGENT", modelWidth, "FROM", modelWidth, "TO", "SESSIONS", "REQUESTS", "COST", "EXTRA"))
+		for _, c := range r.ByCascade {
+			b.WriteString(fmt.Sprintf("  %-*s %-*s %-*s %8d %8d %10s %10s\n",
+				agentWidth, Truncate(c.Agent, agentWidth),
+				modelWidth, Truncate(c.From, modelWidth),
+				modelWidth, Truncate(c.To, modelWidth),
+				c.Sessions, c.Requests, f.Costs.Cost(c.Cost), f.Costs.Cost(c.ExtraCost)))
+		}
+		b.WriteString("  extra: the escalated requests' cost over the same tokens on the model they replaced\n")
+		b.WriteString("\n")
+	}
+
+	// Session type mix
+	if len(r.TypeMix) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(fmt.Sprintf(" SESSION TYPE MIX BY %s\n", strings.ToUpper(r.TypeMixBucket)))
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		agents := make([]string, len(r.TypeMix))
+		for i, m := range r.TypeMix {
+			agents[i] = mixAgent(m)
+		}
+		width := ColumnWidth("AGENT", agents, maxAgentWidth, f.Wide)
+		b.WriteString(fmt.Sprintf("  %-*s %-10s %11s %6s %8s %10s\n", width, "AGENT", strings.ToUpper(r.TypeMixBucket), "INTERACTIVE", "CRON", "SUBAGENT", "COST"))
+		for i, m := range r.TypeMix {
+			name := agents[i]
+			if i > 0 && agents[i-1] == name {
+				name = ""
+			}
+			b.WriteString(fmt.Sprintf("  %-*s %-10s %10.1f%% %5.1f%% %7.1f%% %10s\n",
+				width, Truncate(name, width),
+				f.Dates.Day(m.Start),
+				m.Share(parser.SessionTypeInteractive)*100,
+				m.Share(parser.SessionTypeCron)*100,
+				m.Share(parser.SessionTypeSubagent)*100,
+				f.Costs.Cost(m.TotalCost)))
 		}
 		b.WriteString("\n")
 	}
@@ -173,35 +835,101 @@ func (f *TextFormatter) Format(r reporter.Report) (string, error) {
 			}
 			b.WriteString(fmt.Sprintf("  %s [%s] %s\n", severity, a.Type, a.Description))
 			if a.Cost > 0 {
-				b.WriteString(fmt.Sprintf("     Cost: %s", parser.FormatCost(a.Cost)))
+				b.WriteString(fmt.Sprintf("     Cost: %s", f.Costs.Cost(a.Cost)))
 				if a.Agent != "" {
 					b.WriteString(fmt.Sprintf(" | Agent: %s", a.Agent))
 				}
 				b.WriteString("\n")
 			}
+			if a.Owner != nil {
+				b.WriteString(fmt.Sprintf("     Owner: %s\n", a.Owner))
+			}
+			if a.Muted != "" {
+				b.WriteString(fmt.Sprintf("     Muted: maintenance window %s\n", a.Muted))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	// Deprecated models
+	if len(r.Deprecations) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" DEPRECATED MODELS\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		for _, d := range r.Deprecations {
+			b.WriteString(fmt.Sprintf("  ⚠️  %s is %s", d.Model, d.Status))
+			if d.RetiresAt != "" {
+				b.WriteString(fmt.Sprintf(" (retires %s)", f.Dates.Day(d.RetiresAt)))
+			}
+			b.WriteString("\n")
+			b.WriteString(fmt.Sprintf("     Sessions: %d | Cost: %s | Agents: %s\n",
+				d.Sessions, f.Costs.Cost(d.TotalCost), strings.Join(d.Agents, ", ")))
+			if d.Replacement != "" {
+				sign := "+"
+				delta := d.CostDelta
+				if delta < 0 {
+					sign = "-"
+					delta = -delta
+				}
+				b.WriteString(fmt.Sprintf("     Migrate to %s: est. %s (%s%s)\n",
+					d.Replacement, f.Costs.Cost(d.MigratedCost), sign, f.Costs.Cost(delta)))
+			}
 		}
 		b.WriteString("\n")
 	}
 
+	// Model policy violations
+	if len(r.Compliance) > 0 {
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		b.WriteString(" MODEL POLICY VIOLATIONS\n")
+		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+		total := 0.0
+		for _, v := range r.Compliance {
+			total += v.Cost
+			reason := "not on allow list"
+			if v.Reason == reporter.ViolationDenied {
+				reason = "denied"
+			}
+			b.WriteString(fmt.Sprintf("  ❌ %s used %s (%s) on %s\n", v.Agent, v.Model, reason, f.Dates.Day(v.Date)))
+			b.WriteString(fmt.Sprintf("     Cost: %s | Tokens: %s", f.Costs.Cost(v.Cost), parser.FormatTokens(v.Tokens)))
+			if v.SessionID != "" {
+				b.WriteString(fmt.Sprintf(" | Session: %s", v.SessionID))
+			} else {
+				b.WriteString(fmt.Sprintf(" | Sessions: %d", v.Sessions))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(fmt.Sprintf("  Total out of policy: %s\n", f.Costs.Total(total)))
+		b.WriteString("\n")
+	}
+
 	// Top Sessions (if full report)
 	if len(r.Sessions) > 0 && len(r.Sessions) <= 20 {
 		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 		b.WriteString(" TOP EXPENSIVE SESSIONS\n")
 		b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		b.WriteString(fmt.Sprintf("  %-12s %-15s %10s %10s %s\n", "AGENT", "TYPE", "COST", "TOKENS", "MODEL"))
-		for i, s := range r.Sessions {
-			if i >= 10 {
-				break
-			}
+		top := r.Sessions[:min(len(r.Sessions), 10)]
+		names := make([]string, len(top))
+		for i, s := range top {
+			names[i] = s.Agent
+		}
+		width := ColumnWidth("AGENT", names, maxAgentWidth, f.Wide)
+		b.WriteString(fmt.Sprintf("  %-*s %-15s %10s %10s %10s %10s %10s %10s %s\n",
+			width, "AGENT", "TYPE", "COST", "IN COST", "OUT COST", "TOKENS", "CACHE RD", "CACHE WR", "MODEL"))
+		for _, s := range top {
 			model := s.Model
-			if len(model) > 20 {
-				model = model[:17] + "..."
+			if !f.Wide {
+				model = Truncate(model, maxNameWidth)
 			}
-			b.WriteString(fmt.Sprintf("  %-12s %-15s %10s %10s %s\n",
-				s.Agent,
+			b.WriteString(fmt.Sprintf("  %-*s %-15s %10s %10s %10s %10s %10s %10s %s\n",
+				width, Truncate(s.Agent, width),
 				s.Type,
-				parser.FormatCost(s.Cost),
+				f.Costs.Cost(s.Cost),
+				f.Costs.Cost(s.CostInput),
+				f.Costs.Cost(s.CostOutput),
 				parser.FormatTokens(s.Tokens),
+				parser.FormatTokens(s.CacheRead),
+				parser.FormatTokens(s.CacheWrite),
 				model))
 		}
 		b.WriteString("\n")
@@ -210,6 +938,50 @@ func (f *TextFormatter) Format(r reporter.Report) (string, error) {
 	return b.String(), nil
 }
 
+// rangeLabel describes a report's since/until range, e.g.
+// "2026-01-15T00:00:00Z to 2026-02-15T00:00:00Z".
+func rangeLabel(r reporter.Report) string {
+	since, until := "the start", "now"
+	if r.Since != nil {
+		since = r.Since.Format(time.RFC3339)
+	}
+	if r.Until != nil {
+		until = r.Until.Format(time.RFC3339)
+	}
+	return since + " to " + until
+}
+
+// writeNotes renders annotations beneath a table row.
+func writeNotes(b *strings.Builder, notes []string) {
+	for _, n := range notes {
+		b.WriteString(fmt.Sprintf("    ↳ %s\n", n))
+	}
+}
+
+// mixAgent names the agent of a type mix row, with its tenant if any.
+func mixAgent(m reporter.TypeMixSummary) string {
+	if m.Tenant != "" {
+		return m.Tenant + "/" + m.Agent
+	}
+	return m.Agent
+}
+
+// forecastAgent names the agent of a forecast row, with its tenant if any.
+func forecastAgent(a reporter.AgentForecast) string {
+	if a.Tenant != "" {
+		return a.Tenant + "/" + a.Agent
+	}
+	return a.Agent
+}
+
+// workspaceName names a workspace row, with its tenant if any.
+func workspaceName(w reporter.WorkspaceSummary) string {
+	if w.Tenant != "" {
+		return w.Tenant + "/" + w.Workspace
+	}
+	return w.Workspace
+}
+
 // Helper to format session type for display
 func formatSessionType(t parser.SessionType) string {
 	switch t {
@@ -223,3 +995,22 @@ func formatSessionType(t parser.SessionType) string {
 		return string(t)
 	}
 }
+
+// deviationMark flags an average run cost outside its expected range.
+func deviationMark(deviation string) string {
+	switch deviation {
+	case reporter.DeviationAbove:
+		return "↑"
+	case reporter.DeviationBelow:
+		return "↓"
+	}
+	return " "
+}
+
+// expectedCost formats a cron's expected run cost, or "-" if it has none.
+func expectedCost(c *reporter.CostRange) string {
+	if c == nil {
+		return "-"
+	}
+	return c.String()
+}
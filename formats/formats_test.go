@@ -0,0 +1,818 @@
+package formats
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/reporter"
+)
+
+func testReport() reporter.Report {
+	return reporter.Report{
+		Period:        "week",
+		TotalCost:     3.5,
+		TotalTokens:   4000,
+		TotalSessions: 3,
+		ByAgent: []reporter.AgentSummary{
+			{Agent: "urza", Sessions: 2, TotalCost: 2.0, TotalTokens: 3000},
+			{Agent: "amos", Sessions: 1, TotalCost: 1.5, TotalTokens: 1000},
+		},
+		ByModel: []reporter.ModelSummary{
+			{Model: "moonshotai/kimi-k2.5", Sessions: 3, TotalCost: 3.5, TotalTokens: 4000},
+		},
+	}
+}
+
+func TestJSONFormatterCompact(t *testing.T) {
+	out, err := NewCompactJSONFormatter().Format(testReport())
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	if strings.Count(out, "\n") != 1 {
+		t.Errorf("expected single-line output, got %q", out)
+	}
+
+	var decoded reporter.Report
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if decoded.TotalCost != 3.5 {
+		t.Errorf("expected total cost 3.5, got %f", decoded.TotalCost)
+	}
+}
+
+func TestJSONFormatterStream(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewCompactJSONFormatter().Stream(&buf, testReport()); err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	var sections []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var rec StreamRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", scanner.Text(), err)
+		}
+		sections = append(sections, rec.Section)
+	}
+
+	expected := []string{"summary", "by_agent", "by_agent", "by_model"}
+	if strings.Join(sections, ",") != strings.Join(expected, ",") {
+		t.Errorf("sections = %v, want %v", sections, expected)
+	}
+}
+
+func TestJSONFormatterStreamCoversReport(t *testing.T) {
+	// Fill every field of a report: each must be streamed, as a row
+	// section or in the summary.
+	var r reporter.Report
+	v := reflect.ValueOf(&r).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		switch f := v.Field(i); f.Kind() {
+		case reflect.Slice:
+			f.Set(reflect.MakeSlice(f.Type(), 1, 1))
+		case reflect.Pointer:
+			f.Set(reflect.New(f.Type().Elem()))
+		case reflect.String:
+			f.SetString("x")
+		case reflect.Float64:
+			f.SetFloat(1)
+		case reflect.Int:
+			f.SetInt(1)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := NewCompactJSONFormatter().Stream(&buf, r); err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	streamed := make(map[string]bool)
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var rec struct {
+			Section string                     `json:"section"`
+			Data    map[string]json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", scanner.Text(), err)
+		}
+		streamed[rec.Section] = true
+		if rec.Section == "summary" {
+			for key := range rec.Data {
+				streamed[key] = true
+			}
+		}
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		if !streamed[name] {
+			t.Errorf("expected %s in the stream", name)
+		}
+	}
+}
+
+func TestPorcelainFormatter(t *testing.T) {
+	r := testReport()
+	r.ByTenant = []reporter.TenantSummary{{Tenant: "acme", Agents: 1, Sessions: 2, TotalCost: 2.0, TotalTokens: 3000}}
+	r.ByWorkspace = []reporter.WorkspaceSummary{{Workspace: "hq", Tenant: "acme", Agents: 1, Sessions: 2, TotalCost: 2.0, TotalTokens: 3000}}
+	r.ByAgent[0].Tenant = "acme"
+	r.Budgets = []reporter.BudgetStatus{{Period: reporter.BudgetDaily, Scope: "agent", Name: "urza", Limit: 1.6, Spent: 2, Percent: 125, Exceeded: true}}
+	r.Forecast = &reporter.Forecast{
+		Month: "2026-04", Day: 10, DaysInMonth: 30,
+		Projection: reporter.Projection{MonthToDate: 3, Linear: 9, TrailingDaily: 0.5, Trailing: 13},
+		ByAgent: []reporter.AgentForecast{
+			{Agent: "urza", Tenant: "acme", Projection: reporter.Projection{MonthToDate: 3, Linear: 9, TrailingDaily: 0.5, Trailing: 13}},
+		},
+	}
+	r.Anomalies = []reporter.Anomaly{
+		{ID: "9f2c41d07ab3e615", Type: "expensive_cron", Severity: "warning", Agent: "urza", SessionID: "run\t1", Cost: 0.75},
+	}
+	r.Partial = reporter.NewCoverage("30s", []reporter.AgentCoverage{
+		{Tenant: "acme", Agent: "urza", Transcripts: 4, Parsed: 3},
+		{Agent: "amos", Transcripts: 1, Parsed: 1},
+	})
+
+	out, err := NewPorcelainFormatter().Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	expected := "costctl-porcelain v1\n" +
+		"summary\tweek\t3\t3.500000\t4000\n" +
+		"budget\tdaily\tagent\turza\t2.000000\t1.600000\t125.0\texceeded\n" +
+		"forecast\t2026-04\t\t3.000000\t9.000000\t0.500000\t13.000000\t\n" +
+		"forecast\t2026-04\turza\t3.000000\t9.000000\t0.500000\t13.000000\tacme\n" +
+		"tenant\tacme\t1\t2\t2.000000\t3000\n" +
+		"workspace\thq\t1\t2\t2.000000\t3000\tacme\n" +
+		"agent\turza\t2\t2.000000\t0\t0\t3000\tacme\n" +
+		"agent\tamos\t1\t1.500000\t0\t0\t1000\t\n" +
+		"model\tmoonshotai/kimi-k2.5\t3\t3.500000\t0\t0\t4000\n" +
+		"anomaly\texpensive_cron\twarning\turza\trun 1\t0.750000\t\t9f2c41d07ab3e615\n" +
+		"partial\t\t80.0\t4\t5\t\n" +
+		"partial\turza\t75.0\t3\t4\tacme\n"
+	if out != expected {
+		t.Errorf("unexpected porcelain output:\n%s\nwant:\n%s", out, expected)
+	}
+}
+
+func TestTextBar(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    float64
+		max      float64
+		expected string
+	}{
+		{"full", 2.0, 2.0, "████"},
+		{"half", 1.0, 2.0, "██"},
+		{"fraction", 1.5, 2.0, "███"},
+		{"eighths", 0.25, 2.0, "▌"},
+		{"sliver", 0.0001, 2.0, "▏"},
+		{"zero", 0, 2.0, ""},
+		{"no max", 1.0, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := textBar(tt.value, tt.max, 4); got != tt.expected {
+				t.Errorf("textBar(%v, %v) = %q, want %q", tt.value, tt.max, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHTMLFormatter(t *testing.T) {
+	r := testReport()
+	r.ByAgent[0].Agent = "<urza>"
+	r.TypeMixBucket = reporter.BucketWeek
+	r.TypeMix = []reporter.TypeMixSummary{{Agent: "urza", Start: "2026-03-02", Interactive: 3, Cron: 1, TotalCost: 4}}
+	r.Efficiency = []reporter.ModelEfficiency{
+		{Model: "kimi", CostPerMessage: 0.01, SecondsPerMessage: 40, Frontier: true},
+		{Model: "opus", CostPerMessage: 0.04, SecondsPerMessage: 10, Frontier: true},
+		{Model: "gpt", CostPerMessage: 0.04, SecondsPerMessage: 20},
+	}
+
+	out, err := NewHTMLFormatter().Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	if !strings.Contains(out, "&lt;urza&gt;") {
+		t.Error("expected agent names to be escaped")
+	}
+	// Bars are scaled to the costliest agent: $2.00 → full width, $1.50 → 3/4.
+	if !strings.Contains(out, `width="160.0"`) || !strings.Contains(out, `width="120.0"`) {
+		t.Errorf("expected proportional SVG bars, got:\n%s", out)
+	}
+	// Type mix columns stack to the full 40px height: 3/4 interactive, 1/4 cron.
+	if !strings.Contains(out, `class="mix-interactive" x="0" y="0.0" width="10" height="30.0"`) ||
+		!strings.Contains(out, `class="mix-cron" x="0" y="30.0" width="10" height="10.0"`) {
+		t.Errorf("expected stacked type mix columns, got:\n%s", out)
+	}
+	// The scatter plots seconds (x) against cost (y, from the top) per
+	// message, joining the frontier from the fastest model.
+	if !strings.Contains(out, `class="point frontier" cx="360.0" cy="150.0"`) ||
+		!strings.Contains(out, `class="point" cx="180.0" cy="0.0"`) ||
+		!strings.Contains(out, `points="90.0,0.0 360.0,150.0"`) {
+		t.Errorf("expected a model efficiency scatter chart, got:\n%s", out)
+	}
+
+	out, err = (&HTMLFormatter{Nav: []NavLink{{Label: "week", URL: "?period=week&agent=a%26b", Current: true}, {Label: "month", URL: "?period=month&agent=a%26b"}}}).Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if !strings.Contains(out, `<nav><strong>week</strong><a href="?period=month&amp;agent=a%26b">month</a></nav>`) {
+		t.Errorf("expected period navigation, got:\n%s", out)
+	}
+}
+
+func TestMarkdownFormatter(t *testing.T) {
+	r := testReport()
+	r.Anomalies = []reporter.Anomaly{{Type: "expensive_cron", Severity: "warning", Agent: "urza", Description: "Cron a|b exceeded $0.50 threshold"}}
+	r.ByError = []reporter.ErrorSummary{{Agent: "urza", Model: "sonnet", Errors: 3, Retried: 2, RateLimited: 1, Cost: 0.05, RetriedCost: 0.04}}
+
+	out, err := NewMarkdownFormatter().Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	for _, want := range []string{
+		"# OpenClaw Cost Report\n",
+		"- Cost: $3.50\n",
+		"## By Agent\n\n| Agent | Sessions | Cost | Tokens |\n| --- | ---: | ---: | ---: |\n| urza | 2 | $2.00 | 3.0k |\n",
+		"| warning | expensive_cron | urza | Cron a\\|b exceeded $0.50 threshold |",
+		"## API Errors and Retries\n\n| Agent | Model | Errors | Retried | 429 | 529 | Cost | Duplicated |\n| --- | --- | ---: |",
+		"| urza | sonnet | 3 | 2 | 1 | 0 | $0.05 | $0.04 |\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "## By Cron Job") {
+		t.Error("expected empty tables to be left out")
+	}
+}
+
+func TestCSVFormatter(t *testing.T) {
+	r := testReport()
+	r.ByAgent[0].Agent = "urza, prod"
+
+	out, err := NewCSVFormatter().Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	want := "summary\nperiod,sessions,cost,tokens\nweek,3,3.500000,4000\n\n" +
+		"agents\nagent,tenant,sessions,cost,input_tokens,output_tokens,tokens\n\"urza, prod\",,2,2.000000,0,0,3000\namos,,1,1.500000,0,0,1000\n\n" +
+		"models\nmodel,sessions,cost,input_tokens,output_tokens,tokens\nmoonshotai/kimi-k2.5,3,3.500000,0,0,4000\n"
+	if out != want {
+		t.Errorf("unexpected CSV:\n got %q\nwant %q", out, want)
+	}
+
+	// For Excel in European locales: semicolons, CRLF and a single BOM.
+	excel := CSVStyle{Comma: ';', BOM: true, CRLF: true}
+	out, err = (&CSVFormatter{Style: excel}).Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if !strings.HasPrefix(out, "\ufeffsummary\r\nperiod;sessions;cost;tokens\r\nweek;3;3.500000;4000\r\n\r\nagents\r\n") || strings.Count(out, "\ufeff") != 1 {
+		t.Errorf("unexpected Excel CSV: %q", out)
+	}
+	tables, err := CSVTables(r, excel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range tables {
+		if !bytes.HasPrefix(table.Data, []byte("\ufeff")) {
+			t.Errorf("expected %s to start with a BOM", table.Name)
+		}
+	}
+	if err := (CSVStyle{Comma: '"'}).Validate(); err == nil {
+		t.Error("expected a quote delimiter to be invalid")
+	}
+}
+
+func TestSARIFFormatter(t *testing.T) {
+	r := testReport()
+	r.Provenance = &reporter.Provenance{Version: "1.2.3"}
+	r.Anomalies = []reporter.Anomaly{
+		{ID: "9f2c41d07ab3e615", Type: "expensive_cron", Severity: "warning", Agent: "urza", Cron: "sync", SessionID: "r1",
+			Cost: 0.75, Description: "Cron sync exceeded $0.50 threshold", File: "/agents/urza/sessions/agent:urza:cron:sync:run:r1.jsonl"},
+		{Type: "chatty_agent", Severity: "info", Agent: "amos", Description: "amos averages short turns", Muted: "batch"},
+		{Type: "seasonal_spike", Severity: "error", Agent: "urza", Description: "over forecast"},
+	}
+
+	out, err := NewSARIFFormatter().Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal([]byte(out), &log); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("expected one SARIF 2.1.0 run, got %+v", log)
+	}
+	run := log.Runs[0]
+	if d := run.Tool.Driver; d.Name != "costctl" || d.Version != "1.2.3" || len(d.Rules) != 3 || d.Rules[0].ID != "chatty_agent" || d.Rules[2].ShortDescription.Text != "seasonal_spike" {
+		t.Errorf("expected the anomaly types as sorted rules, got %+v", d)
+	}
+	if len(run.Results) != 3 {
+		t.Fatalf("expected 3 results, got %+v", run.Results)
+	}
+	cron := run.Results[0]
+	if cron.RuleID != "expensive_cron" || cron.Level != "warning" || cron.PartialFingerprints[sarifFingerprint] != "9f2c41d07ab3e615" || cron.Properties.Cron != "sync" {
+		t.Errorf("unexpected cron result: %+v", cron)
+	}
+	if len(cron.Locations) != 1 || cron.Locations[0].PhysicalLocation.ArtifactLocation.URI != "file:///agents/urza/sessions/agent:urza:cron:sync:run:r1.jsonl" {
+		t.Errorf("expected the transcript as location, got %+v", cron.Locations)
+	}
+	if chatty := run.Results[1]; chatty.Level != "note" || len(chatty.Suppressions) != 1 || chatty.Locations != nil {
+		t.Errorf("expected a suppressed note without location, got %+v", chatty)
+	}
+	if level := run.Results[2].Level; level != "error" {
+		t.Errorf("expected error level, got %s", level)
+	}
+
+	empty, err := NewSARIFFormatter().Format(testReport())
+	if err != nil || !strings.Contains(empty, `"results": []`) {
+		t.Errorf("expected an empty result list, got %s, %v", empty, err)
+	}
+}
+
+func TestBundleFormatter(t *testing.T) {
+	r := testReport()
+	r.Anomalies = []reporter.Anomaly{{ID: "9f2c41d07ab3e615", Type: "expensive_cron", Severity: "warning", Agent: "urza", Description: "over, by far"}}
+
+	out, err := NewBundleFormatter().Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	zr, err := zip.NewReader(strings.NewReader(out), int64(len(out)))
+	if err != nil {
+		t.Fatalf("expected a zip archive: %v", err)
+	}
+	files := make(map[string]string)
+	var names []string
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(data)
+		names = append(names, f.Name)
+	}
+	want := "report.json report.md report.html csv/summary.csv csv/agents.csv csv/models.csv csv/anomalies.csv"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("expected entries %q, got %q", want, got)
+	}
+	if !json.Valid([]byte(files["report.json"])) {
+		t.Error("expected report.json to be valid JSON")
+	}
+	if files["csv/agents.csv"] != "agent,tenant,sessions,cost,input_tokens,output_tokens,tokens\nurza,,2,2.000000,0,0,3000\namos,,1,1.500000,0,0,1000\n" {
+		t.Errorf("unexpected agents.csv:\n%s", files["csv/agents.csv"])
+	}
+	if !strings.Contains(files["csv/anomalies.csv"], `9f2c41d07ab3e615,expensive_cron,warning,urza,,,0.000000,,"over, by far"`) {
+		t.Errorf("unexpected anomalies.csv:\n%s", files["csv/anomalies.csv"])
+	}
+
+	again, err := NewBundleFormatter().Format(r)
+	if err != nil || again != out {
+		t.Error("expected the same report to yield the same archive")
+	}
+}
+
+func TestFormatDiff(t *testing.T) {
+	d := reporter.Diff{
+		Period:     "week",
+		BeforeCost: 2.0,
+		AfterCost:  5.0,
+		Delta:      3.0,
+		ByAgent: []reporter.Contribution{
+			{Key: "urza", Delta: 4.0, Share: 4.0 / 3},
+			{Key: "amos", Delta: -2.0, Share: -2.0 / 3},
+			{Key: "pepper", Delta: 0.5, Share: 0.5 / 3},
+			{Key: "kaylee", Delta: 0.5, Share: 0.5 / 3},
+		},
+		AfterNotes: []string{"2026-03-05: sync: doubled context"},
+	}
+
+	out := FormatDiff(d, 2, DateStyle{})
+	for _, want := range []string{"Change:  +$3.00 (+150.0%)", "+$4.00", "-$2.00", "(2 others)", "+$1.00", "↳ 2026-03-05: sync: doubled context"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "pepper") {
+		t.Error("expected steps beyond --top to be folded")
+	}
+}
+
+func TestFormatSimulation(t *testing.T) {
+	sim := reporter.Simulation{
+		Limits:    reporter.Limits{MaxOutputTokens: 2000, MaxTurns: 20},
+		Sessions:  3,
+		Affected:  1,
+		Cost:      4.0,
+		Simulated: 3.0,
+		Savings:   1.0,
+		ByAgent: []reporter.SimulatedGroup{
+			{Key: "urza", Sessions: 2, Affected: 1, Cost: 3.0, Simulated: 2.0, Savings: 1.0},
+			{Key: "amos", Sessions: 1, Cost: 0.5, Simulated: 0.5},
+			{Key: "pepper", Sessions: 1, Cost: 0.5, Simulated: 0.5},
+		},
+	}
+
+	out := FormatSimulation(sim, 1)
+	for _, want := range []string{
+		"max 2000 output tokens per request, max 20 requests per session",
+		"Savings:    $1.00 (25.0%)", "1/2", "(2 others)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "SAVINGS BY CRON JOB") {
+		t.Error("expected no cron table without crons")
+	}
+}
+
+func TestFormatCapSimulation(t *testing.T) {
+	hit := time.Date(2026, 6, 10, 11, 30, 0, 0, time.UTC)
+	sim := reporter.CapSimulation{
+		Cap: 5, DaysHit: 1, Earliest: "11:30", MaxDaily: 10, Spent: 12, Blocked: 3,
+		Days: []reporter.CapDay{
+			{Date: "2026-06-10", Spent: 10, HitAt: &hit, Blocked: 3},
+			{Date: "2026-06-11", Spent: 2},
+		},
+	}
+
+	out := FormatCapSimulation(sim)
+	for _, want := range []string{
+		"Cap:        $5.00 per day", "Days hit:   1 of 2 (earliest at 11:30)", "Blocked:    $3.00 of $12.00 (25.0%)",
+		"2026-06-10     $10.00    11:30      $3.00", "2026-06-11      $2.00        -          -",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestGrafanaTimeseries(t *testing.T) {
+	r := reporter.Report{ByAgentDay: []reporter.AgentDaySummary{
+		{Date: "2026-02-10", Agent: "urza", TotalCost: 1.0},
+		{Date: "2026-02-10", Agent: "amos", TotalCost: 2.0},
+		{Date: "2026-02-12", Agent: "urza", TotalCost: 3.0},
+	}}
+
+	series := GrafanaTimeseries(r)
+	if len(series) != 2 || series[0].Target != "amos" || series[1].Target != "urza" {
+		t.Fatalf("expected amos and urza series, got %+v", series)
+	}
+
+	// The gap on 2026-02-11 is filled so both series span three days.
+	urza := series[1].Datapoints
+	if len(urza) != 3 || urza[0][0] != 1.0 || urza[1][0] != 0 || urza[2][0] != 3.0 {
+		t.Errorf("unexpected urza datapoints: %v", urza)
+	}
+	if day := int64(urza[1][1] - urza[0][1]); day != 24*60*60*1000 {
+		t.Errorf("expected daily timestamps, got step %dms", day)
+	}
+	if len(series[0].Datapoints) != 3 || series[0].Datapoints[2][0] != 0 {
+		t.Errorf("unexpected amos datapoints: %v", series[0].Datapoints)
+	}
+}
+
+func TestDateStyle(t *testing.T) {
+	if _, err := NewDateStyle("martian", false); err == nil {
+		t.Error("expected an error for an unknown date format")
+	}
+
+	eu, err := NewDateStyle("eu", true)
+	if err != nil {
+		t.Fatalf("NewDateStyle failed: %v", err)
+	}
+	if got := eu.Day("2026-03-02"); got != "02.03.2026" {
+		t.Errorf("expected 02.03.2026, got %q", got)
+	}
+	// 2027-01-01 falls in the last ISO week of 2026.
+	if got := eu.Week("2027-01-01"); got != "2026-W53" {
+		t.Errorf("expected 2026-W53, got %q", got)
+	}
+	if got := (DateStyle{}).Day("not a date"); got != "not a date" {
+		t.Errorf("expected unparsable dates unchanged, got %q", got)
+	}
+
+	r := testReport()
+	r.ByDay = []reporter.DaySummary{
+		{Date: "2026-03-02", Sessions: 1, TotalCost: 1.0},
+		{Date: "2026-03-03", Sessions: 2, TotalCost: 2.5},
+	}
+	out, err := (&TextFormatter{Dates: eu}).Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	for _, want := range []string{"WEEK", "02.03.2026   2026-W10"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	html, err := (&HTMLFormatter{Dates: eu}).Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if !strings.Contains(html, "<td>02.03.2026</td><td>2026-W10</td>") {
+		t.Errorf("expected EU dates and ISO weeks in HTML:\n%s", html)
+	}
+}
+
+func decimals(n int) *int { return &n }
+
+func TestCostStyle(t *testing.T) {
+	for _, tt := range []struct {
+		style CostStyle
+		cost  float64
+		want  string
+	}{
+		{CostStyle{}, 1.5, "$1.50"},
+		{CostStyle{}, 0.045, "$0.05"},
+		{CostStyle{}, 0.00445, "$0.0045"},
+		{CostStyle{Rounding: RoundHalfEven}, 0.045, "$0.04"},
+		{CostStyle{Rounding: RoundHalfEven}, 0.055, "$0.06"},
+		{CostStyle{SmallDecimals: decimals(4), SmallUnder: 1}, 0.125, "$0.1250"},
+		{CostStyle{Decimals: decimals(3)}, 12.3456, "$12.346"},
+		{CostStyle{Decimals: decimals(0)}, 12.5, "$13"},
+		{CostStyle{Decimals: decimals(0), SmallDecimals: decimals(0)}, 0.004, "$0"},
+	} {
+		if got := tt.style.Cost(tt.cost); got != tt.want {
+			t.Errorf("%+v: expected %g as %s, got %s", tt.style, tt.cost, tt.want, got)
+		}
+	}
+	if got := (CostStyle{TotalRounding: RoundHalfEven}).Total(2.125); got != "$2.12" {
+		t.Errorf("expected totals rounded half to even, got %s", got)
+	}
+	for _, style := range []CostStyle{{Rounding: "up"}, {TotalRounding: "bankers"}, {Decimals: decimals(7)}, {SmallDecimals: decimals(-1)}, {SmallUnder: -1}} {
+		if err := style.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", style)
+		}
+	}
+
+	// The total is rounded from its full precision, once.
+	r := testReport()
+	r.TotalCost = 0.012
+	out, err := (&TextFormatter{Costs: CostStyle{TotalRounding: RoundHalfEven}}).Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if !strings.Contains(out, "Total Cost:     $0.01\n") {
+		t.Errorf("expected the total rounded from full precision:\n%s", out)
+	}
+}
+
+func TestTextFormatterSessionComposition(t *testing.T) {
+	r := testReport()
+	r.Sessions = []reporter.SessionDetail{
+		{Agent: "urza", Type: "cron", Cost: 0.5, CostInput: 0.2, CostOutput: 0.3, Tokens: 12000, CacheRead: 9000, CacheWrite: 1500, Model: "kimi"},
+	}
+
+	out, err := NewTextFormatter().Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	for _, want := range []string{"CACHE RD", "CACHE WR", "$0.20", "$0.30", "9.0k", "1.5k"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestTextFormatterIdleAgents(t *testing.T) {
+	r := testReport()
+	r.ByAgent = append(r.ByAgent, reporter.AgentSummary{Agent: "pepper", Idle: true})
+
+	out, err := NewTextFormatter().Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if !strings.Contains(out, "  pepper        0      $0.0000            0  idle\n") {
+		t.Errorf("expected pepper marked idle:\n%s", out)
+	}
+}
+
+func TestTextFormatterEstimated(t *testing.T) {
+	r := testReport()
+	r.EstimatedCost = 1.25
+	r.ByModel = append(r.ByModel, reporter.ModelSummary{Model: "ollama/llama3", Sessions: 1, TotalCost: 1.25, Estimated: true})
+
+	out, err := NewTextFormatter().Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	for _, want := range []string{"Estimated:      $1.25 (self-hosted models at configured rates)", "0  estimated\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestTextFormatterExpectedCronCost(t *testing.T) {
+	r := testReport()
+	r.ByCron = []reporter.CronSummary{
+		{CronName: "sync", Runs: 2, TotalCost: 1, AvgCost: 0.5, MaxCost: 0.6, Expected: &reporter.CostRange{Min: 0.1, Max: 0.2}, Deviation: reporter.DeviationAbove},
+		{CronName: "digest", Runs: 1, TotalCost: 0.1, AvgCost: 0.1, MaxCost: 0.1},
+	}
+
+	out, err := NewTextFormatter().Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	for _, want := range []string{"EXPECTED", "$0.50↑", "$0.10-$0.20", "↑/↓ average run above/below its expected cost"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestTextFormatterMonths(t *testing.T) {
+	r := testReport()
+	r.Period = "ytd"
+	r.ByMonth = []reporter.MonthSummary{
+		{Month: "2026-01", Sessions: 3, TotalCost: 1.5, TotalTokens: 3000},
+		{Month: "2026-02", Sessions: 1, TotalCost: 0.25, TotalTokens: 1000},
+	}
+
+	out, err := NewTextFormatter().Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	for _, want := range []string{" BY MONTH\n", "  2026-01             3        $1.50", "  2026-02             1        $0.25"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	html, err := (&HTMLFormatter{}).Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if !strings.Contains(html, "<h2>By Month</h2>") {
+		t.Error("expected a By Month table in HTML")
+	}
+}
+
+func TestTextFormatterKPIs(t *testing.T) {
+	limit, prev := 0.10, 0.08
+	r := testReport()
+	r.KPIs = []reporter.KPIStatus{
+		{Metric: reporter.KPIAvgSessionCost, Value: 0.12, Max: &limit, Previous: &prev, Trend: "up"},
+	}
+
+	out, err := NewTextFormatter().Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	for _, want := range []string{"✗ avg_session_cost", "$0.12", "target ≤ $0.10", "↑ was $0.08"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestTextFormatterBudgets(t *testing.T) {
+	r := testReport()
+	r.Budgets = []reporter.BudgetStatus{
+		{Period: reporter.BudgetDaily, Scope: "total", Limit: 10, Spent: 2.5, Percent: 25},
+		{Period: reporter.BudgetMonthly, Scope: "cron", Name: "backup", Limit: 1, Spent: 1.14, Percent: 114, Exceeded: true},
+	}
+
+	out, err := NewTextFormatter().Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	for _, want := range []string{"BUDGETS", "all agents", "25.0%", "backup", "114.0% over"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"abcdefghij", 5, "ab…ij"},
+		{"nightly-dependency-audit-a1b2c3d4", 20, "nightly-de…-a1b2c3d4"},
+		{"weekly-report-generator-0123456789ab", 24, "weekly-rep…-0123456789ab"},
+		{"a-bcdefghijklmnop", 8, "a-bc…nop"},
+		{"abc", 1, "a"},
+	}
+	for _, tt := range tests {
+		if got := Truncate(tt.s, tt.width); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestTextFormatterWide(t *testing.T) {
+	long := "nightly-dependency-audit-for-every-repository-a1b2c3d4"
+	r := testReport()
+	r.ByCron = []reporter.CronSummary{
+		{CronName: long, Runs: 2, TotalCost: 1, AvgCost: 0.5, MaxCost: 0.6},
+		{CronName: "sync", Runs: 1, TotalCost: 0.5, AvgCost: 0.5, MaxCost: 0.5},
+	}
+
+	out, err := NewTextFormatter().Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if strings.Contains(out, long) || !strings.Contains(out, "…repository-a1b2c3d4 ") {
+		t.Errorf("expected the cron name truncated in the middle:\n%s", out)
+	}
+
+	out, err = (&TextFormatter{Wide: true}).Format(r)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if !strings.Contains(out, "  "+long+"      2 ") || !strings.Contains(out, "  sync"+strings.Repeat(" ", len(long)-len("sync"))+"      1 ") {
+		t.Errorf("expected the cron column sized to the full name:\n%s", out)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]int{1, 2, 3, 4, 5, 6, 7, 8}, 24); got != "▁▂▃▄▅▆▇█" {
+		t.Errorf("sparkline = %q", got)
+	}
+	if got := sparkline([]int{5, 5, 5}, 24); got != "▁▁▁" {
+		t.Errorf("flat sparkline = %q", got)
+	}
+	// 48 values average into 24 buckets.
+	values := make([]int, 48)
+	for i := range values {
+		values[i] = i
+	}
+	if got := []rune(sparkline(values, 24)); len(got) != 24 || got[0] != '▁' || got[23] != '█' {
+		t.Errorf("bucketed sparkline = %q", string(got))
+	}
+}
+
+func TestFormatFleet(t *testing.T) {
+	f := reporter.FleetReport{
+		Hosts:   []reporter.HostSummary{{Host: "web-1", TotalCost: 5}, {Host: "web-2", TotalCost: 1}},
+		ByAgent: []reporter.FleetAgent{{Agent: "urza", ByHost: []float64{5, 0}, TotalCost: 5}},
+		Anomalies: []reporter.FleetAnomaly{
+			{Host: "web-1", Anomaly: reporter.Anomaly{Type: "cron_divergence", Description: "Cron x diverges", Severity: "warning"}},
+		},
+	}
+	for i := 0; i < 5; i++ {
+		f.Anomalies = append(f.Anomalies, reporter.FleetAnomaly{Host: "web-2", Anomaly: reporter.Anomaly{Type: "high_token_count", Description: "Session is big", Severity: "warning", Cost: 0.5}})
+	}
+
+	out := FormatFleet(f, false)
+	if !strings.Contains(out, "  urza       $5.00          -      $5.00\n") {
+		t.Errorf("expected a cost column per host, got:\n%s", out)
+	}
+	if !strings.Contains(out, "web-1: Cron x diverges") {
+		t.Errorf("expected the divergence listed, got:\n%s", out)
+	}
+	if strings.Contains(out, "Session is big") || !strings.Contains(out, "[high_token_count] web-2: 5 anomalies ($2.50)") {
+		t.Errorf("expected repeated anomalies summarized, got:\n%s", out)
+	}
+}
+
+func TestFormatSections(t *testing.T) {
+	r := reporter.Report{
+		TotalCost: 1,
+		ByAgent:   []reporter.AgentSummary{{Agent: "urza", TotalCost: 1}},
+		Sections:  []string{reporter.SectionAgents},
+	}
+	text, err := NewTextFormatter().Format(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(text, "SUMMARY") || !strings.Contains(text, "BY AGENT") {
+		t.Errorf("expected only the agents section, got:\n%s", text)
+	}
+	html, err := NewHTMLFormatter().Format(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html, "<h2>Summary</h2>") || !strings.Contains(html, "<h2>By Agent</h2>") {
+		t.Errorf("expected only the agents section, got:\n%s", html)
+	}
+}
@@ -0,0 +1,75 @@
+package formats_test
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/formats"
+	"github.com/misty-step/costctl/internal/fixtures"
+	"github.com/misty-step/costctl/report"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// TestGolden formats a full report over the recorded fixtures and compares
+// it with the golden files, so format changes show up in review as golden
+// diffs. Run with -update to accept them.
+func TestGolden(t *testing.T) {
+	// Days and run slots are local time; pin it so goldens match anywhere.
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+
+	result, err := report.Generate(context.Background(), report.Options{
+		Roots:  []report.Root{{Dir: fixtures.Agents(t)}},
+		Period: "all",
+		AsOf:   fixtures.AsOf,
+		Full:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Skipped) > 0 {
+		t.Fatalf("fixtures failed to parse: %v", result.Skipped)
+	}
+	r := result.Report
+	r.GeneratedAt = fixtures.AsOf
+	r.Provenance = nil // host and paths differ by machine
+
+	golden := []struct {
+		name      string
+		formatter formats.Formatter
+	}{
+		{"report.txt", formats.NewTextFormatter()},
+		{"report.json", formats.NewJSONFormatter()},
+		{"report.md", formats.NewMarkdownFormatter()},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			got, err := g.formatter.Format(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join("testdata", "golden", g.name)
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run go test ./formats -run TestGolden -update to create it)", err)
+			}
+			if got != string(want) {
+				t.Errorf("%s differs from the golden file; run go test ./formats -run TestGolden -update and review the diff\n%s", g.name, got)
+			}
+		})
+	}
+}
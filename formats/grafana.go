@@ -0,0 +1,87 @@
+package formats
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/misty-step/costctl/reporter"
+)
+
+// GrafanaSeries is one time series in the response format of the Grafana
+// JSON (SimpleJSON) datasource, also readable by the Infinity datasource.
+// Datapoints are [value, unix milliseconds] pairs in time order.
+type GrafanaSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// GrafanaFormatter outputs daily cost per agent as Grafana time series.
+// Days without spend are filled with zeros so every series covers the same
+// range. The report must be generated with reporter.Config.AgentDays.
+type GrafanaFormatter struct{}
+
+// NewGrafanaFormatter creates a new Grafana formatter.
+func NewGrafanaFormatter() *GrafanaFormatter {
+	return &GrafanaFormatter{}
+}
+
+// Format formats the report as a JSON array of Grafana time series.
+func (f *GrafanaFormatter) Format(r reporter.Report) (string, error) {
+	data, err := json.Marshal(GrafanaTimeseries(r))
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// GrafanaTimeseries builds one cost series per agent from the report's
+// per-agent daily totals.
+func GrafanaTimeseries(r reporter.Report) []GrafanaSeries {
+	costs := make(map[string]map[string]float64)
+	var days []string
+	seenDay := make(map[string]bool)
+	for _, d := range r.ByAgentDay {
+		target := d.Agent
+		if d.Tenant != "" {
+			target = d.Tenant + "/" + d.Agent
+		}
+		if costs[target] == nil {
+			costs[target] = make(map[string]float64)
+		}
+		costs[target][d.Date] += d.TotalCost
+		if !seenDay[d.Date] {
+			seenDay[d.Date] = true
+			days = append(days, d.Date)
+		}
+	}
+	sort.Strings(days)
+
+	// Every day from the first to the last, so gaps chart as zero spend.
+	var timestamps []time.Time
+	if len(days) > 0 {
+		first, err1 := time.ParseInLocation("2006-01-02", days[0], time.Local)
+		last, err2 := time.ParseInLocation("2006-01-02", days[len(days)-1], time.Local)
+		if err1 == nil && err2 == nil {
+			for t := first; !t.After(last); t = t.AddDate(0, 0, 1) {
+				timestamps = append(timestamps, t)
+			}
+		}
+	}
+
+	targets := make([]string, 0, len(costs))
+	for target := range costs {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	series := make([]GrafanaSeries, 0, len(targets))
+	for _, target := range targets {
+		s := GrafanaSeries{Target: target, Datapoints: make([][2]float64, 0, len(timestamps))}
+		for _, t := range timestamps {
+			s.Datapoints = append(s.Datapoints, [2]float64{costs[target][t.Format("2006-01-02")], float64(t.UnixMilli())})
+		}
+		series = append(series, s)
+	}
+	return series
+}
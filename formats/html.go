@@ -0,0 +1,247 @@
+package formats
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/reporter"
+)
+
+//go:embed report.html
+var reportHTML string
+
+// svgBarWidth is the width of HTML bar charts, in pixels.
+const svgBarWidth = 160
+
+// svgSparkHeight is the height of HTML sparklines, in pixels.
+const svgSparkHeight = 24
+
+// svgMixHeight and svgMixColumn are the height of the session type mix
+// charts and the width of each of their columns, in pixels.
+const (
+	svgMixHeight = 40
+	svgMixColumn = 10
+)
+
+// svgScatterWidth and svgScatterHeight are the size of the model
+// efficiency scatter chart's plot area, and svgScatterMargin the room
+// around it for axis labels, in pixels.
+const (
+	svgScatterWidth  = 360
+	svgScatterHeight = 200
+	svgScatterMargin = 40
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"cost":   parser.FormatCost,
+	"total":  parser.FormatCost,
+	"tokens": parser.FormatTokens,
+	"bar": func(value, max float64) string {
+		return fmt.Sprintf("%.1f", barFraction(value, max)*svgBarWidth)
+	},
+	"barWidth":    func() int { return svgBarWidth },
+	"mixHeight":   func() int { return svgMixHeight },
+	"spark":       svgSparkline,
+	"sparkHeight": func() int { return svgSparkHeight },
+	"last":        func(values []int) int { return values[len(values)-1] },
+	"roles":       func() []string { return parser.Roles },
+	"percent":     func(f float64) string { return fmt.Sprintf("%.1f%%", f*100) },
+	"kpiValue":    kpiValue,
+	"kpiTarget":   kpiTarget,
+	"kpiTrend":    kpiTrend,
+	"kpiMark":     kpiMark,
+	"budgetName":  budgetName,
+	"budgetUsed":  budgetUsed,
+	"rangeLabel":  rangeLabel,
+}).Parse(reportHTML))
+
+// HTMLFormatter outputs reports as a self-contained HTML page, with SVG bar
+// charts beside the agent and cron tables.
+type HTMLFormatter struct {
+	Dates DateStyle
+	Costs CostStyle
+	// Nav, when set, is a row of links under the title to other views of
+	// the report, such as a served dashboard's periods.
+	Nav []NavLink
+}
+
+// NavLink is a link of HTMLFormatter.Nav. The current view is shown
+// unlinked.
+type NavLink struct {
+	Label   string
+	URL     string
+	Current bool
+}
+
+// NewHTMLFormatter creates a new HTML formatter.
+func NewHTMLFormatter() *HTMLFormatter {
+	return &HTMLFormatter{}
+}
+
+// htmlView is the data handed to the HTML template.
+type htmlView struct {
+	reporter.Report
+	Dates        DateStyle
+	Nav          []NavLink
+	MaxAgentCost float64
+	MaxCronCost  float64
+	CronExpected bool // some cron has an expected run cost
+	TypeMix      []mixChart
+	Efficiency   *scatterChart
+}
+
+// scatterChart plots each model's time per message (x) against its cost
+// per message (y), joining the models on the efficiency frontier.
+type scatterChart struct {
+	Width, Height, Margin int // of the plot area, and around it
+	Points                []scatterPoint
+	Frontier              string // polyline points
+	MaxCost               float64
+	MaxTime               float64
+}
+
+// OuterWidth and OuterHeight are the size of the chart with its margins.
+func (c *scatterChart) OuterWidth() int  { return c.Width + 2*c.Margin }
+func (c *scatterChart) OuterHeight() int { return c.Height + 2*c.Margin }
+
+// LabelY is where the x axis label sits, below the axis.
+func (c *scatterChart) LabelY() int { return c.Height + 16 }
+
+type scatterPoint struct {
+	reporter.ModelEfficiency
+	X, Y float64
+}
+
+// efficiencyChart lays out the model efficiency rows as a scatter chart,
+// from zero to the largest cost and time per message.
+func efficiencyChart(rows []reporter.ModelEfficiency) *scatterChart {
+	if len(rows) == 0 {
+		return nil
+	}
+	chart := &scatterChart{Width: svgScatterWidth, Height: svgScatterHeight, Margin: svgScatterMargin}
+	for _, e := range rows {
+		chart.MaxCost = max(chart.MaxCost, e.CostPerMessage)
+		chart.MaxTime = max(chart.MaxTime, e.SecondsPerMessage)
+	}
+	var frontier []scatterPoint
+	for _, e := range rows {
+		p := scatterPoint{
+			ModelEfficiency: e,
+			X:               barFraction(e.SecondsPerMessage, chart.MaxTime) * svgScatterWidth,
+			Y:               (1 - barFraction(e.CostPerMessage, chart.MaxCost)) * svgScatterHeight,
+		}
+		chart.Points = append(chart.Points, p)
+		if e.Frontier {
+			frontier = append(frontier, p)
+		}
+	}
+	// Frontier models get cheaper as they get slower, so ordering them by
+	// time traces the frontier from left to right.
+	sort.Slice(frontier, func(i, j int) bool { return frontier[i].X < frontier[j].X })
+	coords := make([]string, len(frontier))
+	for i, p := range frontier {
+		coords[i] = fmt.Sprintf("%.1f,%.1f", p.X, p.Y)
+	}
+	chart.Frontier = strings.Join(coords, " ")
+	return chart
+}
+
+// mixChart is an agent's session type mix as 100% stacked columns, one per
+// time bucket.
+type mixChart struct {
+	Agent   string
+	Width   int
+	Columns []mixColumn
+}
+
+type mixColumn struct {
+	reporter.TypeMixSummary
+	X        int
+	Segments []mixSegment
+}
+
+type mixSegment struct {
+	Type      string
+	Y, Height float64
+}
+
+// typeMixCharts lays out the report's type mix rows, which come grouped by
+// agent, as one chart per agent.
+func typeMixCharts(rows []reporter.TypeMixSummary) []mixChart {
+	var charts []mixChart
+	for _, m := range rows {
+		if len(charts) == 0 || charts[len(charts)-1].Agent != mixAgent(m) {
+			charts = append(charts, mixChart{Agent: mixAgent(m)})
+		}
+		chart := &charts[len(charts)-1]
+		column := mixColumn{TypeMixSummary: m, X: len(chart.Columns) * (svgMixColumn + 2)}
+		y := 0.0
+		for _, t := range []parser.SessionType{parser.SessionTypeInteractive, parser.SessionTypeCron, parser.SessionTypeSubagent} {
+			if h := m.Share(t) * svgMixHeight; h > 0 {
+				column.Segments = append(column.Segments, mixSegment{Type: string(t), Y: y, Height: h})
+				y += h
+			}
+		}
+		chart.Columns = append(chart.Columns, column)
+		chart.Width = column.X + svgMixColumn
+	}
+	return charts
+}
+
+// Format formats the report as an HTML page.
+func (f *HTMLFormatter) Format(r reporter.Report) (string, error) {
+	view := htmlView{Report: r, Dates: f.Dates, Nav: f.Nav}
+	for _, a := range r.ByAgent {
+		view.MaxAgentCost = max(view.MaxAgentCost, a.TotalCost)
+	}
+	for _, c := range r.ByCron {
+		view.MaxCronCost = max(view.MaxCronCost, c.TotalCost)
+		view.CronExpected = view.CronExpected || c.Expected != nil
+	}
+	view.TypeMix = typeMixCharts(r.TypeMix)
+	view.Efficiency = efficiencyChart(r.Efficiency)
+
+	tmpl, err := htmlTemplate.Clone()
+	if err != nil {
+		return "", err
+	}
+	tmpl.Funcs(template.FuncMap{"cost": f.Costs.Cost, "total": f.Costs.Total})
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, view); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// svgSparkline returns the points of an SVG polyline plotting values (at
+// most svgBarWidth/2 of them, averaged into buckets beyond that) across a
+// svgBarWidth by svgSparkHeight box.
+func svgSparkline(values []int) string {
+	points := bucket(values, svgBarWidth/2)
+	if len(points) == 0 {
+		return ""
+	}
+	lo, hi := points[0], points[0]
+	for _, p := range points {
+		lo, hi = min(lo, p), max(hi, p)
+	}
+	coords := make([]string, len(points))
+	for i, p := range points {
+		x := 0.0
+		if len(points) > 1 {
+			x = float64(i) / float64(len(points)-1) * svgBarWidth
+		}
+		y := float64(svgSparkHeight) / 2
+		if hi > lo {
+			y = (1-(p-lo)/(hi-lo))*(svgSparkHeight-2) + 1
+		}
+		coords[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+	}
+	return strings.Join(coords, " ")
+}
@@ -0,0 +1,88 @@
+package formats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/itchyny/gojq"
+)
+
+// JQFilter projects JSON output with a jq program (run by an embedded
+// gojq), so simple extractions need no jq binary. Like jq, it writes each
+// result on its own, strings included as JSON.
+type JQFilter struct {
+	// Compact writes each result on one line instead of indented.
+	Compact bool
+
+	code *gojq.Code
+}
+
+// NewJQFilter compiles a jq program such as
+// '.by_agent[] | {agent, total_cost}'.
+func NewJQFilter(program string) (*JQFilter, error) {
+	query, err := gojq.Parse(program)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq filter: %w", err)
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq filter: %w", err)
+	}
+	return &JQFilter{code: code}, nil
+}
+
+// Apply runs the filter over the JSON document and writes its results.
+func (f *JQFilter) Apply(w io.Writer, document []byte) error {
+	var input any
+	if err := json.Unmarshal(document, &input); err != nil {
+		return err
+	}
+	iter := f.code.Run(input)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			return nil
+		}
+		if err, ok := v.(error); ok {
+			var halt *gojq.HaltError
+			if errors.As(err, &halt) && halt.Value() == nil {
+				return nil
+			}
+			return fmt.Errorf("jq: %w", err)
+		}
+		data, err := gojq.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if !f.Compact {
+			var indented bytes.Buffer
+			if err := json.Indent(&indented, data, "", "  "); err != nil {
+				return err
+			}
+			data = indented.Bytes()
+		}
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+}
+
+// ApplyLines runs the filter over each line of newline-delimited JSON, as
+// written by JSONFormatter.Stream.
+func (f *JQFilter) ApplyLines(w io.Writer, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		if err := f.Apply(w, scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
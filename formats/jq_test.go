@@ -0,0 +1,44 @@
+package formats
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJQFilter(t *testing.T) {
+	report := []byte(`{"total_cost": 3.5, "by_agent": [{"agent": "urza", "total_cost": 2, "sessions": 2}, {"agent": "amos", "total_cost": 1.5, "sessions": 1}]}`)
+
+	f, err := NewJQFilter(".by_agent[] | {agent, total_cost}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Compact = true
+	var out strings.Builder
+	if err := f.Apply(&out, report); err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"agent\":\"urza\",\"total_cost\":2}\n{\"agent\":\"amos\",\"total_cost\":1.5}\n"; out.String() != want {
+		t.Errorf("unexpected projection:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	f, err = NewJQFilter(`select(.section == "by_agent") | .data.agent`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	stream := "{\"section\":\"summary\",\"data\":{}}\n{\"section\":\"by_agent\",\"data\":{\"agent\":\"urza\"}}\n"
+	if err := f.ApplyLines(&out, strings.NewReader(stream)); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "\"urza\"\n" {
+		t.Errorf("expected each streamed record filtered, got %q", out.String())
+	}
+
+	if _, err := NewJQFilter(".by_agent["); err == nil {
+		t.Error("expected a syntax error")
+	}
+	f, _ = NewJQFilter(`error("boom")`)
+	if err := f.Apply(&out, report); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected the filter's error, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package formats
+
+import (
+	"fmt"
+
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/reporter"
+)
+
+// trendArrows maps KPI trends to arrows.
+var trendArrows = map[string]string{"up": "↑", "down": "↓", "flat": "→"}
+
+// kpiValue formats a KPI value in its metric's unit.
+func kpiValue(metric string, v float64) string {
+	if metric == reporter.KPICacheRatio {
+		return fmt.Sprintf("%.1f%%", v*100)
+	}
+	return parser.FormatCost(v)
+}
+
+// kpiTarget describes a KPI's bounds, e.g. "≤ $0.10" or "≥ 60.0%".
+func kpiTarget(k reporter.KPIStatus) string {
+	switch {
+	case k.Min != nil && k.Max != nil:
+		return kpiValue(k.Metric, *k.Min) + " – " + kpiValue(k.Metric, *k.Max)
+	case k.Min != nil:
+		return "≥ " + kpiValue(k.Metric, *k.Min)
+	case k.Max != nil:
+		return "≤ " + kpiValue(k.Metric, *k.Max)
+	default:
+		return "-"
+	}
+}
+
+// kpiTrend renders the trend arrow and previous value, or "" when there is
+// nothing to compare with.
+func kpiTrend(k reporter.KPIStatus) string {
+	if k.Previous == nil {
+		return ""
+	}
+	return trendArrows[k.Trend] + " was " + kpiValue(k.Metric, *k.Previous)
+}
+
+// kpiMark is ✓ for met targets and ✗ for missed ones.
+func kpiMark(k reporter.KPIStatus) string {
+	if k.Met {
+		return "✓"
+	}
+	return "✗"
+}
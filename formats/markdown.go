@@ -0,0 +1,330 @@
+package formats
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/reporter"
+)
+
+// MarkdownFormatter outputs reports as GitHub-flavored Markdown, to paste
+// into tickets, pull requests, wikis and chat. Each section the report has
+// data for is a table, in the order of the text report.
+type MarkdownFormatter struct {
+	Dates DateStyle
+	Costs CostStyle
+}
+
+// NewMarkdownFormatter creates a new Markdown formatter.
+func NewMarkdownFormatter() *MarkdownFormatter {
+	return &MarkdownFormatter{}
+}
+
+// Format formats the report as Markdown.
+func (f *MarkdownFormatter) Format(r reporter.Report) (string, error) {
+	var b strings.Builder
+	// table writes a table whose first text columns are left-aligned and
+	// the rest, numbers, right-aligned.
+	table := func(title string, text int, header []string, rows [][]string) {
+		if len(rows) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "## %s\n\n", title)
+		b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+		b.WriteString("|")
+		for i := range header {
+			if i < text {
+				b.WriteString(" --- |")
+			} else {
+				b.WriteString(" ---: |")
+			}
+		}
+		b.WriteString("\n")
+		for _, row := range rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = markdownCell(cell)
+			}
+			b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+		b.WriteString("\n")
+	}
+	cost := f.Costs.Cost
+	tokens := parser.FormatTokens
+	itoa := strconv.Itoa
+	percent := func(v float64) string { return fmt.Sprintf("%.1f%%", v) }
+
+	b.WriteString("# OpenClaw Cost Report\n\n")
+	fmt.Fprintf(&b, "- Generated: %s\n", r.GeneratedAt.Format(time.RFC3339))
+	if r.AsOf != nil {
+		fmt.Fprintf(&b, "- As of: %s\n", r.AsOf.Format(time.RFC3339))
+	}
+	if r.Period != "" {
+		fmt.Fprintf(&b, "- Period: %s\n", r.Period)
+	}
+	if r.Since != nil || r.Until != nil {
+		fmt.Fprintf(&b, "- Range: %s\n", rangeLabel(r))
+	}
+	if r.Includes(reporter.SectionSummary) {
+		fmt.Fprintf(&b, "- Sessions: %d\n", r.TotalSessions)
+		fmt.Fprintf(&b, "- Cost: %s\n", f.Costs.Total(r.TotalCost))
+		if r.EstimatedCost > 0 {
+			fmt.Fprintf(&b, "- Estimated: %s (self-hosted models at configured rates)\n", f.Costs.Total(r.EstimatedCost))
+		}
+		fmt.Fprintf(&b, "- Tokens: %s\n", tokens(r.TotalTokens))
+	}
+	b.WriteString("\n")
+	if c := r.Partial; c != nil {
+		fmt.Fprintf(&b, "> **Partial report:** parsing stopped after %s; %d of %d transcripts (%.1f%%) read, totals are understated.\n\n",
+			c.MaxDuration, c.Parsed, c.Transcripts, c.Percent)
+	}
+
+	var rows [][]string
+	for _, k := range r.KPIs {
+		rows = append(rows, []string{k.Metric, kpiValue(k.Metric, k.Value), kpiTarget(k), kpiMark(k), kpiTrend(k)})
+	}
+	table("KPIs", 1, []string{"Metric", "Value", "Target", "Met", "Trend"}, rows)
+
+	rows = nil
+	for _, s := range r.Budgets {
+		rows = append(rows, []string{s.Period, s.Scope, budgetName(s), cost(s.Spent), cost(s.Limit), budgetUsed(s)})
+	}
+	table("Budgets", 3, []string{"Period", "Scope", "Name", "Spent", "Limit", "Used"}, rows)
+
+	rows = nil
+	title := "Forecast"
+	if fc := r.Forecast; fc != nil {
+		title = fmt.Sprintf("Forecast (%s, day %d of %d)", fc.Month, fc.Day, fc.DaysInMonth)
+		rows = append(rows, []string{"Total", cost(fc.MonthToDate), cost(fc.Linear), cost(fc.TrailingDaily), cost(fc.Trailing)})
+		for _, a := range fc.ByAgent {
+			rows = append(rows, []string{forecastAgent(a), cost(a.MonthToDate), cost(a.Linear), cost(a.TrailingDaily), cost(a.Trailing)})
+		}
+	}
+	table(title, 1, []string{"Agent", "Spent", "Linear", "7d daily", "7d trend"}, rows)
+
+	rows = nil
+	for _, t := range r.ByTenant {
+		rows = append(rows, []string{t.Tenant, itoa(t.Agents), itoa(t.Sessions), cost(t.TotalCost), tokens(t.TotalTokens)})
+	}
+	table("By Tenant", 1, []string{"Tenant", "Agents", "Sessions", "Cost", "Tokens"}, rows)
+
+	rows = nil
+	for _, w := range r.ByWorkspace {
+		rows = append(rows, []string{workspaceName(w), itoa(w.Agents), itoa(w.Sessions), cost(w.TotalCost), tokens(w.TotalTokens)})
+	}
+	table("By Workspace", 1, []string{"Workspace", "Agents", "Sessions", "Cost", "Tokens"}, rows)
+
+	rows = nil
+	for _, a := range r.ByAgent {
+		name := a.Agent
+		if a.Tenant != "" {
+			name = a.Tenant + "/" + a.Agent
+		}
+		rows = append(rows, []string{name, itoa(a.Sessions), cost(a.TotalCost), tokens(a.TotalTokens)})
+	}
+	table("By Agent", 1, []string{"Agent", "Sessions", "Cost", "Tokens"}, rows)
+
+	rows = nil
+	for _, t := range r.BySessionType {
+		rows = append(rows, []string{string(t.Type), itoa(t.Sessions), cost(t.TotalCost), tokens(t.TotalTokens)})
+	}
+	table("By Session Type", 1, []string{"Type", "Sessions", "Cost", "Tokens"}, rows)
+
+	rows = nil
+	for _, t := range r.ByTopic {
+		rows = append(rows, []string{t.Topic, itoa(t.Sessions), cost(t.TotalCost), tokens(t.TotalTokens), percent(t.Share)})
+	}
+	table("By Topic", 1, []string{"Topic", "Sessions", "Cost", "Tokens", "Share"}, rows)
+
+	// Owners and expected costs are only shown when configured.
+	owners := slices.ContainsFunc(r.ByCron, func(c reporter.CronSummary) bool { return c.Owner != nil })
+	expected := slices.ContainsFunc(r.ByCron, func(c reporter.CronSummary) bool { return c.Expected != nil })
+	header, text := []string{"Cron"}, 1
+	if owners {
+		header, text = append(header, "Owner"), 2
+	}
+	header = append(header, "Runs", "Cost", "Avg", "Max")
+	if expected {
+		header = append(header, "Expected")
+	}
+	rows = nil
+	for _, c := range r.ByCron {
+		row := []string{c.CronName}
+		if owners {
+			owner := ""
+			if c.Owner != nil {
+				owner = c.Owner.String()
+			}
+			row = append(row, owner)
+		}
+		row = append(row, itoa(c.Runs), cost(c.TotalCost), cost(c.AvgCost), cost(c.MaxCost))
+		if expected {
+			row = append(row, expectedCost(c.Expected))
+		}
+		rows = append(rows, row)
+	}
+	table("By Cron Job", text, header, rows)
+
+	rows = nil
+	for _, c := range r.CronSlots {
+		rows = append(rows, []string{c.CronName, c.Agent, c.Slot, itoa(c.Runs), cost(c.AvgCost), cost(c.MaxCost), fmt.Sprintf("%.1fx", c.Relative)})
+	}
+	table("Cron Run Slots", 3, []string{"Cron", "Agent", "Slot", "Runs", "Avg", "Max", "Relative"}, rows)
+
+	rows = nil
+	for _, c := range r.CronCache {
+		coldAvg, warmAvg, payoff := "-", "-", "-"
+		if c.ColdRuns > 0 {
+			coldAvg = cost(c.ColdAvgCost)
+		}
+		if c.WarmRuns > 0 {
+			warmAvg = cost(c.WarmAvgCost)
+		}
+		if c.PayoffRuns > 0 {
+			payoff = fmt.Sprintf("%.1f runs", c.PayoffRuns)
+		}
+		rows = append(rows, []string{c.CronName, c.Agent, itoa(c.ColdRuns), coldAvg, itoa(c.WarmRuns), warmAvg, payoff})
+	}
+	table("Cron Cache Warm-up", 2, []string{"Cron", "Agent", "Cold", "Cold Avg", "Warm", "Warm Avg", "Payoff"}, rows)
+
+	rows = nil
+	for _, c := range r.CronGrowth {
+		growing := ""
+		if c.Growing {
+			growing = "growing"
+		}
+		rows = append(rows, []string{c.CronName, c.Agent, growing, itoa(c.Runs), tokens(c.PromptTokens[len(c.PromptTokens)-1]),
+			fmt.Sprintf("%+.0f", c.GrowthPerRun), cost(c.LastRunCost), cost(c.ProjectedRun)})
+	}
+	table("Cron Context Growth", 3, []string{"Cron", "Agent", "Trend", "Runs", "Prompt", "Per Run", "Run Now", "In 30d"}, rows)
+
+	rows = nil
+	for _, m := range r.ByModel {
+		rows = append(rows, []string{m.Model, itoa(m.Sessions), cost(m.TotalCost), tokens(m.TotalTokens)})
+	}
+	table("By Model", 1, []string{"Model", "Sessions", "Cost", "Tokens"}, rows)
+
+	rows = nil
+	for _, d := range r.ByDay {
+		rows = append(rows, []string{f.Dates.Day(d.Date), itoa(d.Sessions), cost(d.TotalCost), tokens(d.TotalTokens)})
+	}
+	table("By Day", 1, []string{"Date", "Sessions", "Cost", "Tokens"}, rows)
+
+	rows = nil
+	for _, m := range r.ByMonth {
+		rows = append(rows, []string{m.Month, itoa(m.Sessions), cost(m.TotalCost), tokens(m.TotalTokens)})
+	}
+	table("By Month", 1, []string{"Month", "Sessions", "Cost", "Tokens"}, rows)
+
+	rows = nil
+	for _, s := range r.ByRole {
+		name := s.Agent
+		if name == "" {
+			name = "(all)"
+		}
+		row := []string{name}
+		for _, role := range parser.Roles {
+			row = append(row, percent(s.Share(role)*100))
+		}
+		rows = append(rows, row)
+	}
+	table("Tokens by Message Role (estimated)", 1, []string{"Agent", "System", "User", "Tool Results", "Text", "Thinking", "Tool Calls"}, rows)
+
+	rows = nil
+	for _, t := range r.ByTurn {
+		name := t.Agent
+		if name == "" {
+			name = "(all)"
+		}
+		if t.Chatty {
+			name += " (chatty)"
+		}
+		rows = append(rows, []string{name, itoa(t.Sessions), itoa(t.Turns), fmt.Sprintf("%.1f", t.TurnsPerSession),
+			tokens(int(t.AvgTokensPerTurn)), fmt.Sprintf("%.2f", t.OutputInputRatio)})
+	}
+	table("Turn Efficiency", 1, []string{"Agent", "Sessions", "Turns", "Turns/Session", "Tokens/Turn", "Out/In"}, rows)
+
+	rows = nil
+	for _, e := range r.Efficiency {
+		name := e.Model
+		if e.Frontier {
+			name += " (frontier)"
+		}
+		rows = append(rows, []string{name, itoa(e.Sessions), itoa(e.Messages), cost(e.CostPerMessage), fmt.Sprintf("%.1f", e.SecondsPerMessage)})
+	}
+	table("Model Efficiency (per message)", 1, []string{"Model", "Sessions", "Messages", "Cost/Message", "Seconds/Message"}, rows)
+
+	rows = nil
+	for _, e := range r.ByError {
+		rows = append(rows, []string{e.Agent, e.Model, itoa(e.Errors), itoa(e.Retried), itoa(e.RateLimited), itoa(e.Overloaded), cost(e.Cost), cost(e.RetriedCost)})
+	}
+	table("API Errors and Retries", 2, []string{"Agent", "Model", "Errors", "Retried", "429", "529", "Cost", "Duplicated"}, rows)
+
+	rows = nil
+	for _, c := range r.ByCascade {
+		rows = append(rows, []string{c.Agent, c.From, c.To, itoa(c.Sessions), itoa(c.Requests), cost(c.Cost), cost(c.ExtraCost)})
+	}
+	table("Model Escalations", 3, []string{"Agent", "From", "To", "Sessions", "Requests", "Cost", "Extra"}, rows)
+
+	rows = nil
+	for _, m := range r.TypeMix {
+		rows = append(rows, []string{mixAgent(m), m.Start, percent(m.Share(parser.SessionTypeInteractive) * 100),
+			percent(m.Share(parser.SessionTypeCron) * 100), percent(m.Share(parser.SessionTypeSubagent) * 100), cost(m.TotalCost)})
+	}
+	table("Session Type Mix by "+r.TypeMixBucket, 2, []string{"Agent", "Start", "Interactive", "Cron", "Subagent", "Cost"}, rows)
+
+	rows = nil
+	for _, a := range r.Anomalies {
+		description := a.Description
+		if a.Muted != "" {
+			description += " (muted: " + a.Muted + ")"
+		}
+		rows = append(rows, []string{a.Severity, a.Type, a.Agent, description})
+	}
+	table("Anomalies", 4, []string{"Severity", "Type", "Agent", "Description"}, rows)
+
+	rows = nil
+	for _, d := range r.Deprecations {
+		retires, replacement, migrated := "-", "-", "-"
+		if d.RetiresAt != "" {
+			retires = f.Dates.Day(d.RetiresAt)
+		}
+		if d.Replacement != "" {
+			replacement = d.Replacement
+			migrated = cost(d.MigratedCost)
+		}
+		rows = append(rows, []string{d.Model, d.Status, retires, strings.Join(d.Agents, ", "), replacement, itoa(d.Sessions), cost(d.TotalCost), migrated})
+	}
+	table("Deprecated Models", 5, []string{"Model", "Status", "Retires", "Agents", "Replacement", "Sessions", "Cost", "Migrated"}, rows)
+
+	rows = nil
+	for _, v := range r.Compliance {
+		reason := "not on allow list"
+		if v.Reason == reporter.ViolationDenied {
+			reason = "denied"
+		}
+		session := v.SessionID
+		if session == "" {
+			session = fmt.Sprintf("%d sessions", v.Sessions)
+		}
+		rows = append(rows, []string{v.Agent, v.Model, reason, f.Dates.Day(v.Date), session, cost(v.Cost), tokens(v.Tokens)})
+	}
+	table("Model Policy Violations", 5, []string{"Agent", "Model", "Reason", "Date", "Session", "Cost", "Tokens"}, rows)
+
+	rows = nil
+	for _, s := range r.Sessions[:min(len(r.Sessions), 10)] {
+		rows = append(rows, []string{s.Agent, string(s.Type), s.Model, cost(s.Cost), cost(s.CostInput), cost(s.CostOutput), tokens(s.Tokens)})
+	}
+	table("Top Expensive Sessions", 3, []string{"Agent", "Type", "Model", "Cost", "Input Cost", "Output Cost", "Tokens"}, rows)
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// markdownCell keeps a value inside its table cell.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ", "\r", " ").Replace(s)
+}
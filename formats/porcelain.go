@@ -0,0 +1,154 @@
+package formats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/reporter"
+)
+
+// PorcelainVersion is bumped whenever porcelain output changes in a way
+// that could break existing scripts. Fields are only ever appended to a
+// record type within a version.
+const PorcelainVersion = 1
+
+// PorcelainFormatter outputs reports as stable, tab-separated records for
+// scripting. The first line is "costctl-porcelain v<N>"; every following
+// line starts with its record type:
+//
+//	summary  period  sessions  cost  tokens
+//	budget   period  scope     name  spent  limit  percent  exceeded
+//	forecast month   agent     month_to_date  linear  trailing_daily  trailing  tenant
+//	tenant   name    agents    sessions  cost  tokens
+//	workspace name   agents    sessions  cost  tokens  tenant
+//	agent    name    sessions  cost  input_tokens  output_tokens  tokens  tenant
+//	type     type    sessions  cost  tokens
+//	topic    topic   sessions  cost  tokens
+//	cron     name    runs      cost  avg_cost      max_cost       tokens
+//	model    model   sessions  cost  input_tokens  output_tokens  tokens
+//	day      date    sessions  cost  tokens
+//	month    month   sessions  cost  tokens
+//	role     agent   system    user  tool_result   text           thinking  tool_call  tokens
+//	anomaly  type    severity  agent session_id    cost  muted  id
+//	violation agent  model     reason  session_id   cost  tokens  date  tenant
+//	partial  agent   percent   parsed  transcripts  tenant
+//
+// Costs are dollars with six decimals; tokens are integers. The role record
+// for all agents combined has an empty agent. Partial records, one per
+// agent with unread transcripts plus one with an empty agent for the whole
+// report, appear only when --max-duration stopped parsing early. Muted
+// names the maintenance window that muted an anomaly, if any, and id is
+// its stable ID (see reporter.AnomalyID). A budget's name is empty for the
+// total, and exceeded is "exceeded" or empty. The forecast record for all
+// agents combined comes first, with an empty agent.
+type PorcelainFormatter struct{}
+
+// NewPorcelainFormatter creates a new porcelain formatter.
+func NewPorcelainFormatter() *PorcelainFormatter {
+	return &PorcelainFormatter{}
+}
+
+// Format formats the report as porcelain records.
+func (f *PorcelainFormatter) Format(r reporter.Report) (string, error) {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("costctl-porcelain v%d\n", PorcelainVersion))
+
+	record := func(fields ...string) {
+		for i, field := range fields {
+			if i > 0 {
+				b.WriteByte('\t')
+			}
+			b.WriteString(porcelainField(field))
+		}
+		b.WriteByte('\n')
+	}
+
+	record("summary", r.Period, strconv.Itoa(r.TotalSessions), porcelainCost(r.TotalCost), strconv.Itoa(r.TotalTokens))
+	for _, s := range r.Budgets {
+		exceeded := ""
+		if s.Exceeded {
+			exceeded = "exceeded"
+		}
+		record("budget", s.Period, s.Scope, s.Name, porcelainCost(s.Spent), porcelainCost(s.Limit), porcelainPercent(s.Percent), exceeded)
+	}
+	if fc := r.Forecast; fc != nil {
+		forecast := func(agent, tenant string, p reporter.Projection) {
+			record("forecast", fc.Month, agent, porcelainCost(p.MonthToDate), porcelainCost(p.Linear),
+				porcelainCost(p.TrailingDaily), porcelainCost(p.Trailing), tenant)
+		}
+		forecast("", "", fc.Projection)
+		for _, a := range fc.ByAgent {
+			forecast(a.Agent, a.Tenant, a.Projection)
+		}
+	}
+	for _, t := range r.ByTenant {
+		record("tenant", t.Tenant, strconv.Itoa(t.Agents), strconv.Itoa(t.Sessions),
+			porcelainCost(t.TotalCost), strconv.Itoa(t.TotalTokens))
+	}
+	for _, w := range r.ByWorkspace {
+		record("workspace", w.Workspace, strconv.Itoa(w.Agents), strconv.Itoa(w.Sessions),
+			porcelainCost(w.TotalCost), strconv.Itoa(w.TotalTokens), w.Tenant)
+	}
+	for _, a := range r.ByAgent {
+		record("agent", a.Agent, strconv.Itoa(a.Sessions), porcelainCost(a.TotalCost),
+			strconv.Itoa(a.InputTokens), strconv.Itoa(a.OutputTokens), strconv.Itoa(a.TotalTokens), a.Tenant)
+	}
+	for _, t := range r.BySessionType {
+		record("type", string(t.Type), strconv.Itoa(t.Sessions), porcelainCost(t.TotalCost), strconv.Itoa(t.TotalTokens))
+	}
+	for _, t := range r.ByTopic {
+		record("topic", t.Topic, strconv.Itoa(t.Sessions), porcelainCost(t.TotalCost), strconv.Itoa(t.TotalTokens))
+	}
+	for _, c := range r.ByCron {
+		record("cron", c.CronName, strconv.Itoa(c.Runs), porcelainCost(c.TotalCost),
+			porcelainCost(c.AvgCost), porcelainCost(c.MaxCost), strconv.Itoa(c.TotalTokens))
+	}
+	for _, m := range r.ByModel {
+		record("model", m.Model, strconv.Itoa(m.Sessions), porcelainCost(m.TotalCost),
+			strconv.Itoa(m.InputTokens), strconv.Itoa(m.OutputTokens), strconv.Itoa(m.TotalTokens))
+	}
+	for _, d := range r.ByDay {
+		record("day", d.Date, strconv.Itoa(d.Sessions), porcelainCost(d.TotalCost), strconv.Itoa(d.TotalTokens))
+	}
+	for _, m := range r.ByMonth {
+		record("month", m.Month, strconv.Itoa(m.Sessions), porcelainCost(m.TotalCost), strconv.Itoa(m.TotalTokens))
+	}
+	for _, s := range r.ByRole {
+		fields := []string{"role", s.Agent}
+		for _, role := range parser.Roles {
+			fields = append(fields, strconv.Itoa(s.Tokens[role]))
+		}
+		record(append(fields, strconv.Itoa(s.Total))...)
+	}
+	for _, a := range r.Anomalies {
+		record("anomaly", a.Type, a.Severity, a.Agent, a.SessionID, porcelainCost(a.Cost), a.Muted, a.ID)
+	}
+	for _, v := range r.Compliance {
+		record("violation", v.Agent, v.Model, v.Reason, v.SessionID, porcelainCost(v.Cost), strconv.Itoa(v.Tokens), v.Date, v.Tenant)
+	}
+	if c := r.Partial; c != nil {
+		record("partial", "", porcelainPercent(c.Percent), strconv.Itoa(c.Parsed), strconv.Itoa(c.Transcripts), "")
+		for _, a := range c.ByAgent {
+			if a.Parsed < a.Transcripts {
+				record("partial", a.Agent, porcelainPercent(a.Percent), strconv.Itoa(a.Parsed), strconv.Itoa(a.Transcripts), a.Tenant)
+			}
+		}
+	}
+
+	return b.String(), nil
+}
+
+func porcelainCost(cost float64) string {
+	return strconv.FormatFloat(cost, 'f', 6, 64)
+}
+
+func porcelainPercent(percent float64) string {
+	return strconv.FormatFloat(percent, 'f', 1, 64)
+}
+
+// porcelainField keeps a value on one line and inside its column.
+func porcelainField(s string) string {
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(s)
+}
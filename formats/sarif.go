@@ -0,0 +1,184 @@
+package formats
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"sort"
+
+	"github.com/misty-step/costctl/reporter"
+)
+
+// sarifSchema and sarifVersion identify the SARIF release written.
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// sarifFingerprint is the partial fingerprint holding an anomaly's stable
+// ID, which triage tools match findings across runs by.
+const sarifFingerprint = "costctlAnomaly/v1"
+
+// sarifRules describes the built-in anomaly rules. Other types, such as
+// those of custom detectors, are described by their name.
+var sarifRules = map[string]string{
+	"expensive_cron":        "Cron run over its cost threshold",
+	"expensive_cron_tokens": "Cron run over its token threshold",
+	"high_token_count":      "Session with an unusually high token count",
+	"zero_cost":             "Session with substantial usage but no reported cost",
+	"session_cap_hit":       "Session reached its spend cap",
+	"session_cap_near":      "Session came close to its spend cap",
+	"model_override":        "Session on a pricier model than its agent is configured for",
+	"opus_overkill":         "Opus model used for a small request",
+	"chatty_agent":          "Agent taking many short turns",
+	"context_growth":        "Cron whose prompt grows run after run",
+	"detector_failed":       "Custom anomaly detector failed",
+}
+
+// sarifLevels maps anomaly severities to SARIF levels.
+var sarifLevels = map[string]string{"error": "error", "warning": "warning", "info": "note"}
+
+// SARIFFormatter outputs a report's anomalies as a SARIF 2.1.0 log, the
+// finding format of code scanning and security triage tools. Each anomaly
+// is a result of the rule named by its type, located at its session's
+// transcript when it has one. Muted anomalies are marked suppressed.
+type SARIFFormatter struct{}
+
+// NewSARIFFormatter creates a new SARIF formatter.
+func NewSARIFFormatter() *SARIFFormatter {
+	return &SARIFFormatter{}
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string             `json:"ruleId"`
+	Level               string             `json:"level"`
+	Message             sarifMessage       `json:"message"`
+	Locations           []sarifLocation    `json:"locations,omitempty"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints,omitempty"`
+	Suppressions        []sarifSuppression `json:"suppressions,omitempty"`
+	Properties          sarifProperties    `json:"properties"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification"`
+}
+
+// sarifProperties carries what SARIF has no place for.
+type sarifProperties struct {
+	Agent     string  `json:"agent,omitempty"`
+	Cron      string  `json:"cron,omitempty"`
+	SessionID string  `json:"session_id,omitempty"`
+	Cost      float64 `json:"cost,omitempty"`
+}
+
+// Format formats the report's anomalies as a SARIF log.
+func (f *SARIFFormatter) Format(r reporter.Report) (string, error) {
+	driver := sarifDriver{Name: "costctl", InformationURI: "https://github.com/misty-step/costctl", Rules: []sarifRule{}}
+	if r.Provenance != nil {
+		driver.Version = r.Provenance.Version
+	}
+	results := []sarifResult{}
+	seen := make(map[string]bool)
+	for _, a := range r.Anomalies {
+		if !seen[a.Type] {
+			seen[a.Type] = true
+			description, ok := sarifRules[a.Type]
+			if !ok {
+				description = a.Type
+			}
+			driver.Rules = append(driver.Rules, sarifRule{ID: a.Type, ShortDescription: sarifMessage{Text: description}})
+		}
+
+		level, ok := sarifLevels[a.Severity]
+		if !ok {
+			level = "warning"
+		}
+		result := sarifResult{
+			RuleID:     a.Type,
+			Level:      level,
+			Message:    sarifMessage{Text: a.Description},
+			Properties: sarifProperties{Agent: a.Agent, Cron: a.Cron, SessionID: a.SessionID, Cost: a.Cost},
+		}
+		if a.File != "" {
+			result.Locations = []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: fileURI(a.File)},
+			}}}
+		}
+		if a.ID != "" {
+			result.PartialFingerprints = map[string]string{sarifFingerprint: a.ID}
+		}
+		if a.Muted != "" {
+			result.Suppressions = []sarifSuppression{{Kind: "external", Justification: "maintenance window " + a.Muted}}
+		}
+		results = append(results, result)
+	}
+	sort.Slice(driver.Rules, func(i, j int) bool { return driver.Rules[i].ID < driver.Rules[j].ID })
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// fileURI returns a file URI for an absolute path, or the path as a
+// relative reference otherwise.
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !filepath.IsAbs(filepath.FromSlash(path)) {
+		return (&url.URL{Path: path}).String()
+	}
+	if path[0] != '/' {
+		path = "/" + path // Windows drive letters
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,122 @@
+package formats
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/reporter"
+)
+
+// FormatSimulation renders what history would have cost under stricter
+// limits: totals, then savings by agent and by cron. Groups beyond top are
+// folded into one "others" row.
+func FormatSimulation(sim reporter.Simulation, top int) string {
+	var b strings.Builder
+
+	b.WriteString("╔════════════════════════════════════════════════════════════════╗\n")
+	b.WriteString("║              OpenClaw Cost Simulation                          ║\n")
+	b.WriteString("╚════════════════════════════════════════════════════════════════╝\n\n")
+
+	var limits []string
+	if sim.Limits.MaxOutputTokens > 0 {
+		limits = append(limits, fmt.Sprintf("max %d output tokens per request", sim.Limits.MaxOutputTokens))
+	}
+	if sim.Limits.MaxInputTokens > 0 {
+		limits = append(limits, fmt.Sprintf("max %d input tokens per request", sim.Limits.MaxInputTokens))
+	}
+	if sim.Limits.MaxTurns > 0 {
+		limits = append(limits, fmt.Sprintf("max %d requests per session", sim.Limits.MaxTurns))
+	}
+	b.WriteString(fmt.Sprintf("Limits:     %s\n", strings.Join(limits, ", ")))
+	b.WriteString(fmt.Sprintf("Sessions:   %d (%d affected)\n", sim.Sessions, sim.Affected))
+	b.WriteString(fmt.Sprintf("Requests:   %d (%d capped, %d dropped)\n", sim.Requests, sim.Capped, sim.Dropped))
+	b.WriteString(fmt.Sprintf("Actual:     %s\n", parser.FormatCost(sim.Cost)))
+	b.WriteString(fmt.Sprintf("Simulated:  %s\n", parser.FormatCost(sim.Simulated)))
+	b.WriteString(fmt.Sprintf("Savings:    %s", parser.FormatCost(sim.Savings)))
+	if sim.Cost > 0 {
+		b.WriteString(fmt.Sprintf(" (%.1f%%)", sim.Savings/sim.Cost*100))
+	}
+	b.WriteString("\n\n")
+
+	writeSimulated(&b, "SAVINGS BY AGENT", sim.ByAgent, top)
+	writeSimulated(&b, "SAVINGS BY CRON JOB", sim.ByCron, top)
+
+	b.WriteString("Estimates scale each request's cost by the tokens a limit cuts off;\n")
+	b.WriteString("shorter replies shrinking later context are not modeled.\n")
+	return b.String()
+}
+
+func writeSimulated(b *strings.Builder, title string, groups []reporter.SimulatedGroup, top int) {
+	if len(groups) == 0 {
+		return
+	}
+	if top > 0 && len(groups) > top+1 {
+		others := reporter.SimulatedGroup{Key: fmt.Sprintf("(%d others)", len(groups)-top)}
+		for _, g := range groups[top:] {
+			others.Sessions += g.Sessions
+			others.Affected += g.Affected
+			others.Cost += g.Cost
+			others.Simulated += g.Simulated
+			others.Savings += g.Savings
+		}
+		groups = append(groups[:top:top], others)
+	}
+
+	b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	b.WriteString(fmt.Sprintf(" %s\n", title))
+	b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	b.WriteString(fmt.Sprintf("  %-25s %9s %10s %10s %10s\n", "Name", "Affected", "Actual", "Simulated", "Savings"))
+	for _, g := range groups {
+		name := g.Key
+		if len(name) > 25 {
+			name = name[:22] + "..."
+		}
+		b.WriteString(fmt.Sprintf("  %-25s %9s %10s %10s %10s\n",
+			name, fmt.Sprintf("%d/%d", g.Affected, g.Sessions),
+			parser.FormatCost(g.Cost), parser.FormatCost(g.Simulated), parser.FormatCost(g.Savings)))
+	}
+	b.WriteString("\n")
+}
+
+// FormatCapSimulation renders when a daily spend cap would have been hit:
+// totals, then each day with the time the cap was reached and the spend
+// it would have blocked.
+func FormatCapSimulation(sim reporter.CapSimulation) string {
+	var b strings.Builder
+
+	b.WriteString("╔════════════════════════════════════════════════════════════════╗\n")
+	b.WriteString("║              OpenClaw Daily Cap Simulation                     ║\n")
+	b.WriteString("╚════════════════════════════════════════════════════════════════╝\n\n")
+
+	b.WriteString(fmt.Sprintf("Cap:        %s per day\n", parser.FormatCost(sim.Cap)))
+	b.WriteString(fmt.Sprintf("Days hit:   %d of %d", sim.DaysHit, len(sim.Days)))
+	if sim.Earliest != "" {
+		b.WriteString(fmt.Sprintf(" (earliest at %s)", sim.Earliest))
+	}
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("Highest:    %s per day\n", parser.FormatCost(sim.MaxDaily)))
+	b.WriteString(fmt.Sprintf("Blocked:    %s of %s", parser.FormatCost(sim.Blocked), parser.FormatCost(sim.Spent)))
+	if sim.Spent > 0 {
+		b.WriteString(fmt.Sprintf(" (%.1f%%)", sim.Blocked/sim.Spent*100))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	b.WriteString(" BY DAY\n")
+	b.WriteString("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	b.WriteString(fmt.Sprintf("  %-10s %10s %8s %10s\n", "DATE", "SPENT", "CAP HIT", "BLOCKED"))
+	for _, d := range sim.Days {
+		hit, blocked := "-", "-"
+		if d.HitAt != nil {
+			hit = d.HitAt.Format("15:04")
+			blocked = parser.FormatCost(d.Blocked)
+		}
+		b.WriteString(fmt.Sprintf("  %-10s %10s %8s %10s\n", d.Date, parser.FormatCost(d.Spent), hit, blocked))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("The request that reaches the cap counts as allowed; a cap above the\n")
+	b.WriteString("highest day would never have been hit.\n")
+	return b.String()
+}
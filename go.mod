@@ -2,8 +2,25 @@ module github.com/misty-step/costctl
 
 go 1.23
 
-require github.com/spf13/cobra v1.8.1
+require (
+	github.com/itchyny/gojq v0.12.17
+	github.com/lib/pq v1.10.9
+	github.com/spf13/cobra v1.8.1
+	modernc.org/sqlite v1.34.5
+)
 
 require github.com/inconshreveable/mousetrap v1.1.0 // indirect
 
-require github.com/spf13/pflag v1.0.5 // indirect
+require (
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/itchyny/timefmt-go v0.1.6 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/ncruces/go-strftime v0.1.9 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	github.com/spf13/pflag v1.0.5 // indirect
+	golang.org/x/sys v0.22.0 // indirect
+	modernc.org/libc v1.55.3 // indirect
+	modernc.org/mathutil v1.6.0 // indirect
+	modernc.org/memory v1.8.0 // indirect
+)
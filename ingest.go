@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/misty-step/costctl/reporter"
+	"github.com/misty-step/costctl/store"
+	"github.com/spf13/cobra"
+)
+
+// ledgerSource is the report --source value reading the session ledger of
+// the default store; "db:DSN" reads another store's.
+const ledgerSource = "db"
+
+// ingest command flags
+var (
+	ingestPeriod string
+	ingestAgent  string
+	ingestStore  string
+)
+
+var ingestCmd = &cobra.Command{
+	Use:   "ingest",
+	Short: "Record parsed sessions in the session ledger",
+	Long: `Parse session transcripts and upsert every session into the session ledger
+of a history store, so reports keep their per-session detail after OpenClaw
+rotates the transcripts away.
+
+Sessions are keyed by host, tenant, agent and session ID: re-ingesting
+replaces sessions that grew since, and sessions whose transcripts are gone
+stay. Run it at least as often as OpenClaw rotates transcripts, e.g. daily
+from cron, then report from the ledger with --source db.
+
+Stores:
+  sqlite:/path/to/db   local SQLite database (default: ` + defaultStoreDSN + `)
+  postgres://...       shared Postgres database
+
+Examples:
+  costctl ingest
+  costctl ingest --period all
+  costctl report --source db --period month --full
+  costctl report --source db:postgres://costctl@warehouse/costs --crons`,
+	RunE: runIngest,
+}
+
+func init() {
+	ingestCmd.Flags().StringVar(&ingestPeriod, "period", "week", "Time period to ingest: today|yesterday|week|month|all")
+	ingestCmd.Flags().StringVar(&ingestAgent, "agent", "", "Only ingest this agent")
+	ingestCmd.Flags().StringVar(&ingestStore, "store", defaultStoreDSN, "History store DSN holding the ledger")
+
+	ingestCmd.RegisterFlagCompletionFunc("agent", completeAgents)
+}
+
+func runIngest(cmd *cobra.Command, args []string) error {
+	if err := validatePeriod(ingestPeriod); err != nil {
+		return err
+	}
+
+	s, err := store.Open(ingestStore)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	ledger, ok := s.(store.SessionStore)
+	if !ok {
+		return fmt.Errorf("store %s has no session ledger (use sqlite: or postgres:)", ingestStore)
+	}
+
+	sessions, _, err := parseSessions(ingestAgent, periodSince(ingestPeriod, time.Now()), time.Time{})
+	if err != nil {
+		return err
+	}
+	sessions = reporter.New(sessions, reporter.Config{Period: ingestPeriod}).Filtered()
+
+	host, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("failed to get hostname: %w", err)
+	}
+
+	added, err := ledger.PutSessions(context.Background(), host, sessions)
+	if err != nil {
+		return fmt.Errorf("failed to ingest sessions: %w", err)
+	}
+	fmt.Printf("Ingested %d sessions (%d new)\n", len(sessions), added)
+	return nil
+}
+
+// ledgerDSN returns the store whose session ledger a report --source value
+// names, if it names one: "db" is the default store, "db:DSN" another.
+func ledgerDSN(source string) (string, bool) {
+	if source == ledgerSource {
+		return defaultStoreDSN, true
+	}
+	dsn, ok := strings.CutPrefix(source, ledgerSource+":")
+	return dsn, ok && dsn != ""
+}
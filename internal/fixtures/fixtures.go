@@ -0,0 +1,66 @@
+// Package fixtures holds sanitized OpenClaw agents directories, covering
+// each transcript schema costctl reads, for integration and golden-file
+// tests:
+//
+//   - urza: version 3 transcripts, with the model inside the message, the
+//     full cost breakdown, prompt caching, thinking and tool calls, and an
+//     interactive session, a cron run and a sub-agent.
+//   - pepper: version 2 transcripts, with the model beside the message and
+//     no cache costs, and a cron run retrying a rate-limited request.
+//   - amos: a version 1 transcript, with no session header, plain string
+//     user content and only a total cost.
+//
+// Message text is replaced by x's of the original length.
+package fixtures
+
+import (
+	"embed"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Session keys contain colons, which module file names cannot, so fixture
+// file names are URL-escaped (%3A) and unescaped when copied.
+//
+//go:embed agents
+var agents embed.FS
+
+// AsOf is an instant after every fixture session. Reports generated as of
+// it over the fixtures do not change from day to day.
+var AsOf = time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+
+// Agents copies the fixture agents directory into a temporary directory
+// removed after the test, and returns its path.
+func Agents(t testing.TB) string {
+	t.Helper()
+	dir := t.TempDir()
+	err := fs.WalkDir(agents, "agents", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel("agents", filepath.FromSlash(path))
+		if err != nil {
+			return err
+		}
+		if rel, err = url.PathUnescape(rel); err != nil {
+			return err
+		}
+		target := filepath.Join(dir, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := agents.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0644)
+	})
+	if err != nil {
+		t.Fatalf("failed to copy fixtures: %v", err)
+	}
+	return dir
+}
@@ -1,12 +1,25 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/misty-step/costctl/config"
 	"github.com/misty-step/costctl/formats"
+	"github.com/misty-step/costctl/notes"
+	"github.com/misty-step/costctl/notify"
 	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/report"
 	"github.com/misty-step/costctl/reporter"
+	"github.com/misty-step/costctl/store"
 	"github.com/spf13/cobra"
 )
 
@@ -18,8 +31,12 @@ var (
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
+		var exit *exitCodeError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 }
 
@@ -37,8 +54,32 @@ Each message contains: usage.cost.total (dollars), model, usage.input/output (to
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", config.DefaultPath, "Path to the costctl config file")
+	rootCmd.PersistentFlags().StringVar(&agentsDir, "agents-dir", "", "Path to agents directory; ~ and $VAR/%VAR% are expanded (default: ~/.openclaw/agents)")
+	rootCmd.PersistentFlags().StringVar(&tenantName, "tenant", "", "Only use this tenant's agents directory (see tenants in the config file)")
+	rootCmd.PersistentFlags().StringSliceVar(&excludeAgents, "exclude-agent", nil, "Leave out agents whose directory name matches this glob, e.g. '*-test' (repeatable)")
+	rootCmd.PersistentFlags().StringSliceVar(&excludeSessions, "exclude-session", nil, "Leave out session transcripts whose file name (without .jsonl) matches this glob, e.g. 'scratch-*' (repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Parse every transcript instead of reusing sessions cached in "+defaultCachePath)
+	rootCmd.PersistentFlags().StringVar(&progressFormat, "progress", "", "Write transcript parsing progress and warnings to stderr: json (newline-delimited events)")
+
 	rootCmd.AddCommand(reportCmd)
 	rootCmd.AddCommand(agentsCmd)
+	rootCmd.AddCommand(snapshotCmd)
+	rootCmd.AddCommand(pushCmd)
+	rootCmd.AddCommand(ingestCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(closeCmd)
+	rootCmd.AddCommand(fleetCmd)
+	rootCmd.AddCommand(tuneCmd)
+	rootCmd.AddCommand(anomaliesCmd)
+	rootCmd.AddCommand(budgetCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(cronsCmd)
+	rootCmd.AddCommand(sessionsCmd)
+	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(simulateCmd)
+	rootCmd.AddCommand(selftestCmd)
 	rootCmd.AddCommand(versionCmd)
 }
 
@@ -54,12 +95,52 @@ var versionCmd = &cobra.Command{
 var (
 	reportPeriod    string
 	reportAgent     string
+	reportCron      string
+	reportWorkspace string
 	reportCrons     bool
 	reportModels    bool
+	reportRoles     bool
+	reportTurns     bool
+	reportErrors    bool
+	reportCascades  bool
+	reportMix       bool
+	reportForecast  bool
 	reportFull      bool
+	reportSections  []string
+	reportCompare   bool
+	reportIdle      bool
 	reportFormat    string
 	reportThreshold float64
+	reportCompact   bool
+	reportStream    bool
+	reportJQ        string
+	reportPorcelain bool
+	reportWide      bool
+	reportNotify    bool
+	reportTracking  string
+	reportSource    string
+	reportHistory   string
+	reportAsOf      string
+	reportSince     string
+	reportUntil     string
+	reportMaxTime   time.Duration
+	reportNotes     string
+	reportFiles     []string
+	reportStdin     bool
+	reportInput     string
+	reportBundle    string
+	reportSeeded    bool
+	reportOutputDir string
+	reportDelimiter string
+	reportBOM       bool
+	reportCRLF      bool
+	reportFailOver  bool
+	reportProfile   string
 	agentsDir       string
+	configFile      string
+	tenantName      string
+	excludeAgents   []string
+	excludeSessions []string
 )
 
 var reportCmd = &cobra.Command{
@@ -70,112 +151,688 @@ var reportCmd = &cobra.Command{
 Examples:
   costctl report --period today
   costctl report --period week --agent urza
+  costctl report --cron daily-kickoff
   costctl report --crons
   costctl report --models --format json
-  costctl report --full --format text`,
+  costctl report --period week --crons --compare --format json
+  costctl report --period today --include-idle
+  costctl report --period month --mix
+  costctl report --period week --forecast
+  costctl report --full --format text
+  costctl report --sections summary,agents,anomalies
+  costctl report --full --format html > report.html
+  costctl report --period month --full --bundle 2026-06-costs.zip
+  costctl report --period month --format grafana
+  costctl report --period week --format sarif > costs.sarif
+  costctl report --period month --full --format csv --output-dir costs/
+  costctl report --period month --format csv --delimiter ';' --bom --crlf > costs.csv
+  costctl report --period today --fail-over-budget
+  costctl report --period month --profile finance
+  costctl report --full --format json --compact
+  costctl report --full --format json --stream | jq 'select(.section == "by_cron")'
+  costctl report --period week --jq '.by_agent[] | {agent, total_cost}'
+  costctl report --source postgres://costctl@warehouse/costs --period month
+  costctl report --source db --period month --full
+  costctl report --tenant acme --period month
+  costctl report --files a.jsonl b.jsonl
+  find ~/.openclaw -name '*.jsonl' -mtime -1 -print0 | costctl report --stdin
+  costctl report --input sessions.ndjson --period month
+  costctl report --period today --porcelain
+  costctl report --period today --notify
+  costctl report --period month --as-of 2026-06-30T23:59Z
+  costctl report --since 2026-01-15 --until 2026-02-14
+  costctl report --period ytd --max-duration 30s
+
+Exit codes with --porcelain (the highest applies):
+  0  ok
+  1  usage or runtime error
+  3  warning- or error-severity anomalies detected outside maintenance windows
+  4  a budget in the config file was exceeded (also with --fail-over-budget)
+  5  some transcripts could not be parsed; totals may be incomplete
+  6  --max-duration stopped parsing before every transcript was read`,
 	RunE: runReport,
 }
 
 func init() {
-	reportCmd.Flags().StringVar(&reportPeriod, "period", "", "Time period: today|yesterday|week|month|all")
+	reportCmd.Flags().StringVar(&reportPeriod, "period", "", "Time period: today|yesterday|week|month|ytd|all, a month (2026-03) or months (2026-01..2026-06)")
 	reportCmd.Flags().StringVar(&reportAgent, "agent", "", "Filter by agent: amos|kaylee|pepper|urza|...")
+	reportCmd.Flags().StringVar(&reportCron, "cron", "", "Only report runs of this cron")
+	reportCmd.Flags().StringVar(&reportWorkspace, "workspace", "", "Only report agents of this workspace (nested layouts)")
 	reportCmd.Flags().BoolVar(&reportCrons, "crons", false, "Show cron cost ranking")
 	reportCmd.Flags().BoolVar(&reportModels, "models", false, "Show model cost comparison")
+	reportCmd.Flags().BoolVar(&reportRoles, "roles", false, "Show estimated token share by message role")
+	reportCmd.Flags().BoolVar(&reportTurns, "turns", false, "Show turns per session, tokens per turn and output/input ratio by agent")
+	reportCmd.Flags().BoolVar(&reportErrors, "errors", false, "Show failed requests (rate limits, overloaded providers) and their cost by agent and model")
+	reportCmd.Flags().BoolVar(&reportCascades, "cascades", false, "Show sessions escalating mid-session to a pricier model (fallbacks, agent switches) and the extra cost by agent")
+	reportCmd.Flags().BoolVar(&reportForecast, "forecast", false, "Project month-to-date spend to the end of the month, overall and by agent, from the month's and the last 7 days' daily averages")
+	reportCmd.Flags().BoolVar(&reportMix, "mix", false, "Show how each agent's spend splits across interactive, cron and subagent sessions over time")
 	reportCmd.Flags().BoolVar(&reportFull, "full", false, "Show all dimensions")
-	reportCmd.Flags().StringVar(&reportFormat, "format", "text", "Output format: json|text")
+	reportCmd.Flags().BoolVar(&reportCompare, "compare", false, "Add each agent, cron and model's change since the previous period (today|yesterday|week|month|ytd|months)")
+	reportCmd.Flags().BoolVar(&reportIdle, "include-idle", false, "List agents without sessions in the period as idle, zero rows")
+	reportCmd.Flags().StringSliceVar(&reportSections, "sections", nil, "Only generate these sections, replacing --crons, --full etc.: "+strings.Join(reporter.Sections, ","))
+	reportCmd.Flags().StringVar(&reportFormat, "format", "text", "Output format: json|text|html|markdown|grafana|sarif|csv")
 	reportCmd.Flags().Float64Var(&reportThreshold, "threshold", 0.50, "Anomaly threshold for expensive crons ($)")
-	reportCmd.Flags().StringVar(&agentsDir, "agents-dir", "", "Path to agents directory (default: ~/.openclaw/agents)")
+	reportCmd.Flags().BoolVar(&reportCompact, "compact", false, "Emit minified JSON (json format only)")
+	reportCmd.Flags().StringVar(&reportJQ, "jq", "", "Print only what this jq filter extracts from the JSON report, e.g. '.by_agent[] | {agent, total_cost}' (implies --format json)")
+	reportCmd.Flags().BoolVar(&reportStream, "stream", false, "Stream report rows as newline-delimited JSON (json format only)")
+	reportCmd.Flags().BoolVar(&reportWide, "wide", false, "Size text table columns to their longest value instead of truncating names")
+	reportCmd.Flags().BoolVar(&reportWide, "no-truncate", false, "Alias for --wide")
+	reportCmd.Flags().BoolVar(&reportPorcelain, "porcelain", false, "Stable tab-separated output with scripting exit codes (see README)")
+	reportCmd.Flags().StringVar(&reportProfile, "profile", "", "Use this profile's sections, format, display and redaction from the config file")
+	reportCmd.Flags().BoolVar(&reportFailOver, "fail-over-budget", false, "Exit with status 4 when a configured budget is exceeded")
+	reportCmd.Flags().StringVar(&reportNotes, "notes", "~/.costctl/notes.txt", "Annotations file (\"YYYY-MM-DD: text\" / \"cron:NAME: text\" lines)")
+	reportCmd.Flags().StringSliceVar(&reportFiles, "files", nil, "Report over these transcripts instead of the agents directory (further arguments are also files)")
+	reportCmd.Flags().BoolVar(&reportStdin, "stdin", false, "Read transcript paths from stdin, NUL- or newline-separated")
+	reportCmd.Flags().StringVar(&reportInput, "input", "", "Report over session records written by 'costctl export --to sessions' instead of transcripts (- for stdin)")
+	reportCmd.Flags().StringVar(&reportBundle, "bundle", "", "Write the report as JSON, Markdown, HTML and CSV in one zip archive to this file")
+	reportCmd.Flags().StringVar(&reportOutputDir, "output-dir", "", "With --format csv, write each table to its own file in this directory instead of stdout")
+	reportCmd.Flags().StringVar(&reportDelimiter, "delimiter", ",", "CSV field delimiter for --format csv and --bundle, e.g. ';' for Excel in European locales, or tab")
+	reportCmd.Flags().BoolVar(&reportBOM, "bom", false, "Start CSV files with a UTF-8 byte order mark, so Excel reads them as UTF-8")
+	reportCmd.Flags().BoolVar(&reportCRLF, "crlf", false, "End CSV lines with CRLF")
+	reportCmd.Flags().BoolVar(&reportNotify, "notify", false, "Post anomalies to the webhook configured in the config file")
+	reportCmd.Flags().StringVar(&reportTracking, "anomaly-store", defaultStoreDSN, "Store --notify tracks anomalies in, so each is posted once while open (\"\" to post every run)")
+	reportCmd.Flags().BoolVar(&reportSeeded, "seeded", false, "Pin the generation time to --as-of (or the Unix epoch) and leave the host out, so reruns over the same input are byte-identical")
+	reportCmd.Flags().StringVar(&reportSince, "since", "", "Only include sessions that started at or after this time, e.g. 2026-01-15 (start of day, UTC) or 2026-01-15T09:00Z")
+	reportCmd.Flags().StringVar(&reportUntil, "until", "", "Only include sessions that started before this time; a date (UTC) includes that whole day")
+	reportCmd.Flags().StringVar(&reportAsOf, "as-of", "", "Report as of this instant, ignoring later transcript lines, e.g. 2026-06-30T23:59Z or 2026-06-30 (end of day, local time)")
+	reportCmd.Flags().DurationVar(&reportMaxTime, "max-duration", 0, "Stop parsing transcripts after this long and report what was read, marked partial with coverage per agent (0 for no limit)")
+	reportCmd.Flags().StringVar(&reportSource, "source", "files", "Data source: files (transcripts), db (the session ledger written by ingest; db:DSN for another store's) or a store DSN, e.g. postgres://user@host/db")
+	reportCmd.Flags().StringVar(&reportHistory, "history", defaultStoreDSN, "Snapshot store filling in rotated transcripts for ytd and month periods, if it exists (\"\" to disable)")
+
+	reportCmd.RegisterFlagCompletionFunc("agent", completeAgents)
+	reportCmd.RegisterFlagCompletionFunc("cron", completeCrons)
+	reportCmd.RegisterFlagCompletionFunc("workspace", completeWorkspaces)
+	reportCmd.RegisterFlagCompletionFunc("sections", cobra.FixedCompletions(reporter.Sections, cobra.ShellCompDirectiveNoFileComp))
 }
 
 func runReport(cmd *cobra.Command, args []string) error {
-	// Resolve agents directory
-	dir := agentsDir
-	if dir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return fmt.Errorf("failed to get home directory: %w", err)
+	// Validate period if specified
+	if err := validatePeriod(reportPeriod); err != nil {
+		return err
+	}
+
+	// Load settings, with the profile's on top
+	settings, err := loadSettings()
+	if err != nil {
+		return err
+	}
+	var profile config.Profile
+	if reportProfile != "" {
+		if profile, err = settings.ApplyProfile(reportProfile); err != nil {
+			return err
+		}
+		if err := reporter.ValidateRedact(profile.Redact); err != nil {
+			return fmt.Errorf("profile %s: %w", reportProfile, err)
+		}
+		if profile.Format != "" && !cmd.Flags().Changed("format") && reportJQ == "" && !reportPorcelain && !reportCompact && !reportStream && reportBundle == "" {
+			reportFormat = profile.Format
 		}
-		dir = home + "/.openclaw/agents"
 	}
 
-	// Validate period if specified
-	if reportPeriod != "" {
-		validPeriods := map[string]bool{"today": true, "yesterday": true, "week": true, "month": true, "all": true}
-		if !validPeriods[reportPeriod] {
-			return fmt.Errorf("invalid period: %s (valid: today, yesterday, week, month, all)", reportPeriod)
+	// A jq filter projects the JSON report
+	var jq *formats.JQFilter
+	if reportJQ != "" {
+		if cmd.Flags().Changed("format") && reportFormat != "json" || reportPorcelain {
+			return fmt.Errorf("--jq requires --format json")
+		}
+		reportFormat = "json"
+		var err error
+		if jq, err = formats.NewJQFilter(reportJQ); err != nil {
+			return err
 		}
+		jq.Compact = reportCompact
 	}
 
 	// Validate format
-	if reportFormat != "json" && reportFormat != "text" {
-		return fmt.Errorf("invalid format: %s (valid: json, text)", reportFormat)
+	switch reportFormat {
+	case "json", "text", "html", "markdown", "grafana", "sarif", "csv":
+	default:
+		return fmt.Errorf("invalid format: %s (valid: json, text, html, markdown, grafana, sarif, csv)", reportFormat)
+	}
+	if (reportCompact || reportStream) && reportFormat != "json" {
+		return fmt.Errorf("--compact and --stream require --format json")
+	}
+	if reportPorcelain && (reportCompact || reportStream || cmd.Flags().Changed("format")) {
+		return fmt.Errorf("--porcelain cannot be combined with --format, --compact, or --stream")
+	}
+	if reportOutputDir != "" && reportFormat != "csv" {
+		return fmt.Errorf("--output-dir requires --format csv")
+	}
+	csvStyle, err := parseCSVStyle(reportDelimiter, reportBOM, reportCRLF)
+	if err != nil {
+		return err
+	}
+	if (cmd.Flags().Changed("delimiter") || reportBOM || reportCRLF) && reportFormat != "csv" && reportBundle == "" {
+		return fmt.Errorf("--delimiter, --bom and --crlf require --format csv or --bundle")
+	}
+	if reportBundle != "" && (reportPorcelain || reportCompact || reportStream || reportJQ != "" || cmd.Flags().Changed("format")) {
+		return fmt.Errorf("--bundle cannot be combined with --format, --porcelain, --compact, --stream or --jq")
+	}
+
+	asOf, err := parseAsOf(reportAsOf)
+	if err != nil {
+		return err
+	}
+	since, until, err := parseRange(reportSince, reportUntil)
+	if err != nil {
+		return err
+	}
+
+	// Collect explicitly listed transcripts
+	paths, err := reportPaths(cmd, args)
+	if err != nil {
+		return err
+	}
+	if paths != nil && reportSource != "" && reportSource != "files" {
+		return fmt.Errorf("--files and --stdin cannot be combined with --source")
+	}
+	if reportInput != "" && (paths != nil || cmd.Flags().Changed("source")) {
+		return fmt.Errorf("--input cannot be combined with --files, --stdin or --source")
+	}
+
+	progress, err := newProgress()
+	if err != nil {
+		return err
 	}
+	cache := openCache()
+	defer saveCache(cache)
 
-	// Parse all sessions
-	p := parser.New(dir)
-	sessions, err := p.ParseAll(reportAgent)
+	// Load annotations
+	annotations, err := loadNotes(reportNotes)
 	if err != nil {
-		return fmt.Errorf("failed to parse sessions: %w", err)
+		return err
 	}
 
-	// Build report configuration
-	cfg := reporter.Config{
+	opts := report.Options{
+		Files:     paths,
+		Input:     reportInput,
 		Period:    reportPeriod,
 		Agent:     reportAgent,
+		Cron:      reportCron,
+		Workspace: reportWorkspace,
+		AsOf:      asOf,
+		Since:     since,
+		Until:     until,
+		Seeded:    reportSeeded,
 		Crons:     reportCrons,
 		Models:    reportModels,
+		Roles:     reportRoles,
+		Turns:     reportTurns,
+		Errors:    reportErrors,
+		Cascades:  reportCascades,
+		TypeMix:   reportMix,
+		Forecast:  reportForecast,
+		AgentDays: reportFormat == "grafana",
 		Full:      reportFull,
+		Sections:  reportSections,
+		Compare:   reportCompare,
 		Threshold: reportThreshold,
+		Settings:  settings,
+		Notes:     annotations,
+		Progress:  parserProgress(progress),
+		Cache:     cache,
+
+		IncludeIdle: reportIdle,
+		MaxDuration: reportMaxTime,
+		Version:     rootCmd.Version,
+	}
+	if paths == nil && reportInput == "" {
+		if reportSource == "" || reportSource == "files" {
+			if opts.Roots, err = resolveAgentsRoots(); err != nil {
+				return err
+			}
+			if reporter.CalendarPeriod(reportPeriod) && storeExists(reportHistory) {
+				opts.History = reportHistory
+				opts.Tenant = tenantName
+			}
+		} else if dsn, ok := ledgerDSN(reportSource); ok {
+			opts.Ledger = dsn
+			opts.Tenant = tenantName
+		} else {
+			opts.Source = reportSource
+			opts.Tenant = tenantName
+		}
 	}
 
 	// Generate report
-	r := reporter.New(sessions, cfg)
-	report := r.Generate()
+	result, err := report.Generate(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+	if progress != nil {
+		progress.Done()
+	}
+	if reportNotify && !result.Report.Includes(reporter.SectionAnomalies) {
+		return fmt.Errorf("--notify requires the anomalies section")
+	}
+	// Only the output is redacted; notifications and exit codes see all.
+	shown := result.Report.Redacted(profile.Redact)
 
 	// Output report
+	if reportStream {
+		if jq != nil {
+			var buf bytes.Buffer
+			if err := formats.NewCompactJSONFormatter().Stream(&buf, shown); err != nil {
+				return err
+			}
+			return jq.ApplyLines(os.Stdout, &buf)
+		}
+		return formats.NewCompactJSONFormatter().Stream(os.Stdout, shown)
+	}
+
+	dates, err := formats.NewDateStyle(settings.Display.DateFormat, settings.Display.ISOWeeks)
+	if err != nil {
+		return err
+	}
+	costs, err := costStyle(settings.Display.Costs)
+	if err != nil {
+		return err
+	}
+
 	var formatter formats.Formatter
-	if reportFormat == "json" {
+	if reportBundle != "" {
+		formatter = &formats.BundleFormatter{Dates: dates, Costs: costs, CSV: csvStyle}
+	} else if reportPorcelain {
+		formatter = formats.NewPorcelainFormatter()
+	} else if reportFormat == "json" && reportCompact {
+		formatter = formats.NewCompactJSONFormatter()
+	} else if reportFormat == "json" {
 		formatter = formats.NewJSONFormatter()
+	} else if reportFormat == "html" {
+		formatter = &formats.HTMLFormatter{Dates: dates, Costs: costs}
+	} else if reportFormat == "markdown" {
+		formatter = &formats.MarkdownFormatter{Dates: dates, Costs: costs}
+	} else if reportFormat == "grafana" {
+		formatter = formats.NewGrafanaFormatter()
+	} else if reportFormat == "sarif" {
+		formatter = formats.NewSARIFFormatter()
+	} else if reportFormat == "csv" {
+		formatter = &formats.CSVFormatter{Style: csvStyle}
 	} else {
-		formatter = formats.NewTextFormatter()
+		formatter = &formats.TextFormatter{Dates: dates, Wide: reportWide, Costs: costs}
 	}
 
-	output, err := formatter.Format(report)
+	output, err := formatter.Format(shown)
 	if err != nil {
 		return fmt.Errorf("failed to format report: %w", err)
 	}
 
-	fmt.Print(output)
+	if jq != nil {
+		if err := jq.Apply(os.Stdout, []byte(output)); err != nil {
+			return err
+		}
+	} else if reportBundle != "" {
+		if err := os.WriteFile(reportBundle, []byte(output), 0644); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote report bundle to %s\n", reportBundle)
+	} else if reportOutputDir != "" {
+		if err := writeCSVTables(reportOutputDir, shown, csvStyle); err != nil {
+			return err
+		}
+	} else {
+		fmt.Print(output)
+	}
+
+	if reportNotify {
+		partial := reportAgent != "" || reportCron != "" || reportWorkspace != "" || paths != nil || reportInput != ""
+		if err := notifyAnomalies(settings.Webhook, settings.CronOwners, result.Report, reportTracking, partial); err != nil {
+			return err
+		}
+	}
+
+	if reportPorcelain {
+		if code := porcelainExitCode(result.Report, len(result.Skipped)); code != exitOK {
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+			return &exitCodeError{code: code}
+		}
+	}
+	if reportFailOver && result.Report.OverBudget() {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &exitCodeError{code: exitBudgetExceeded}
+	}
+	return nil
+}
+
+// loadNotes reads the --notes file at path. An empty path means no notes
+// file.
+func loadNotes(path string) ([]notes.Note, error) {
+	if path == "" {
+		return nil, nil
+	}
+	path, err := parser.ExpandPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return notes.Load(path)
+}
+
+// reportPaths returns the transcripts selected with --files (plus any
+// positional arguments) and --stdin, or nil when neither is used.
+func reportPaths(cmd *cobra.Command, args []string) ([]string, error) {
+	if !cmd.Flags().Changed("files") && !reportStdin {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("unexpected arguments: %s (use --files to list transcripts)", strings.Join(args, " "))
+		}
+		return nil, nil
+	}
+
+	paths := append([]string{}, reportFiles...)
+	paths = append(paths, args...)
+	if reportStdin {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read paths from stdin: %w", err)
+		}
+		paths = append(paths, splitPathList(data)...)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no transcripts given")
+	}
+	return paths, nil
+}
+
+// splitPathList splits NUL-separated paths (find -print0) or, when the
+// input has no NUL bytes, newline-separated ones. Blank entries are dropped.
+func splitPathList(data []byte) []string {
+	sep := "\n"
+	if bytes.IndexByte(data, 0) >= 0 {
+		sep = "\x00"
+	}
+
+	var paths []string
+	for _, path := range strings.Split(string(data), sep) {
+		path = strings.TrimRight(path, "\r\n")
+		if strings.TrimSpace(path) != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// notifyAnomalies posts the report's anomalies to the configured webhook.
+// Deliveries that fail are dead-lettered rather than failing the report.
+// When tracking names a store, anomalies are tracked in it and only those
+// not yet posted since they opened are sent; partial reports resolve none.
+func notifyAnomalies(cfg config.Webhook, owners map[string]config.CronOwner, report reporter.Report, tracking string, partial bool) error {
+	routes := make(map[string]string)
+	for name, owner := range owners {
+		if owner.Webhook != "" {
+			routes[name] = owner.Webhook
+		}
+	}
+	if cfg.URL == "" && len(routes) == 0 {
+		return fmt.Errorf("--notify requires webhook.url or a cron_owners webhook in the config file")
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = config.DefaultWebhookRetries
+	}
+	if cfg.DeadLetter == "" {
+		cfg.DeadLetter = config.DefaultDeadLetterPath
+	}
+	deadLetter, err := parser.ExpandPath(cfg.DeadLetter)
+	if err != nil {
+		return err
+	}
+
+	anomalies := report.Anomalies
+	var delivered []string
+	if tracking != "" {
+		st, tracker, err := openAnomalyStore(tracking)
+		if err != nil {
+			return err
+		}
+		defer st.Close()
+		ctx := context.Background()
+		now := time.Now()
+		records, err := trackAnomalies(ctx, tracker, report.Anomalies, now, partial)
+		if err != nil {
+			return err
+		}
+		due := make(map[string]store.AnomalyRecord)
+		for _, r := range records {
+			if r.Open() && !r.Notified() {
+				due[r.ID] = r
+			}
+		}
+		anomalies = nil
+		for _, a := range report.Anomalies {
+			if _, ok := due[a.ID]; ok {
+				anomalies = append(anomalies, a)
+			}
+		}
+		// Only delivered anomalies are recorded as notified; those that
+		// failed or had no URL to go to are sent again next time.
+		defer func() {
+			var notified []store.AnomalyRecord
+			for _, id := range delivered {
+				r := due[id]
+				r.NotifiedAt = now
+				notified = append(notified, r)
+			}
+			if err := tracker.PutAnomalies(ctx, notified); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to record notified anomalies: %v\n", err)
+			}
+		}()
+		if repeats := len(report.Anomalies) - len(anomalies); repeats > 0 {
+			fmt.Fprintf(os.Stderr, "%d anomalies already notified while open were not sent again\n", repeats)
+		}
+	}
+
+	webhook := notify.NewWebhook(cfg.URL, cfg.Secret, cfg.Retries, deadLetter, cfg.Links)
+	webhook.Routes = routes
+	var failed int
+	delivered, failed, err = webhook.Send(anomalies, report.Period)
+	if err != nil {
+		return err
+	}
+	muted := 0
+	for _, a := range anomalies {
+		if a.Muted != "" {
+			muted++
+		}
+	}
+	if muted > 0 {
+		fmt.Fprintf(os.Stderr, "%d anomalies muted by maintenance windows were not sent\n", muted)
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d of %d anomalies could not be delivered; see %s\n", failed, len(anomalies)-muted, deadLetter)
+	}
 	return nil
 }
 
+// configPath resolves the --config flag.
+func configPath() (string, error) {
+	return parser.ExpandPath(configFile)
+}
+
+// validatePeriod checks a --period value; empty means all time.
+func validatePeriod(period string) error {
+	return reporter.ValidatePeriod(period)
+}
+
+// storeExists reports whether the history store at dsn can hold data:
+// local SQLite and file stores must exist, other stores are assumed to.
+func storeExists(dsn string) bool {
+	if dsn == "" {
+		return false
+	}
+	scheme, location, ok := strings.Cut(dsn, ":")
+	switch {
+	case !ok || len(scheme) == 1:
+		location = dsn
+	case scheme != "sqlite" && scheme != "file":
+		return true
+	}
+	path, err := parser.ExpandPath(location)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// writeCSVTables writes each table of a report to its own CSV file in
+// dir, creating it if needed.
+func writeCSVTables(dir string, r reporter.Report, style formats.CSVStyle) error {
+	tables, err := formats.CSVTables(r, style)
+	if err != nil {
+		return fmt.Errorf("failed to format report: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	for _, t := range tables {
+		if err := os.WriteFile(filepath.Join(dir, t.Name), t.Data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", t.Name, err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d CSV files to %s\n", len(tables), dir)
+	return nil
+}
+
+// parseCSVStyle returns the CSV style for --delimiter, --bom and --crlf.
+// The delimiter is one character, or tab.
+func parseCSVStyle(delimiter string, bom, crlf bool) (formats.CSVStyle, error) {
+	if delimiter == "tab" || delimiter == `\t` {
+		delimiter = "\t"
+	}
+	comma, size := utf8.DecodeRuneInString(delimiter)
+	if size == 0 || size != len(delimiter) {
+		return formats.CSVStyle{}, fmt.Errorf("invalid --delimiter: %q (use one character, or tab)", delimiter)
+	}
+	style := formats.CSVStyle{Comma: comma, BOM: bom, CRLF: crlf}
+	if err := style.Validate(); err != nil {
+		return formats.CSVStyle{}, err
+	}
+	return style, nil
+}
+
+// costStyle returns the display style for the config file's cost settings.
+func costStyle(d config.CostDisplay) (formats.CostStyle, error) {
+	s := formats.CostStyle{
+		Decimals:      d.Decimals,
+		SmallDecimals: d.SmallDecimals,
+		SmallUnder:    d.SmallUnder,
+		Rounding:      d.Rounding,
+		TotalRounding: d.TotalRounding,
+	}
+	return s, s.Validate()
+}
+
+// asOfLayouts are the accepted --as-of, --since and --until formats. Times
+// without a zone are local for --as-of and UTC for --since and --until.
+var asOfLayouts = []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+// parseAsOf parses an --as-of value in local time, like the calendar
+// periods it anchors. A bare date means the end of that day, so --as-of
+// 2026-06-30 includes all of June 30. Empty yields the zero time.
+func parseAsOf(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if day, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	for _, layout := range asOfLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --as-of: %s (use e.g. 2026-06-30T23:59Z or 2026-06-30)", value)
+}
+
+// parseRange parses --since and --until values, either of which may be
+// empty. A bare date is the start of that day (UTC) for since, and the
+// start of the next one for until, which is exclusive, so that
+// --until 2026-02-01 includes all of February 1.
+func parseRange(sinceValue, untilValue string) (since, until time.Time, err error) {
+	parse := func(flag, value string, days int) (time.Time, error) {
+		if value == "" {
+			return time.Time{}, nil
+		}
+		if day, err := time.Parse("2006-01-02", value); err == nil {
+			return day.AddDate(0, 0, days), nil
+		}
+		for _, layout := range asOfLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("invalid --%s: %s (use e.g. 2026-01-15 or 2026-01-15T09:00Z)", flag, value)
+	}
+	if since, err = parse("since", sinceValue, 0); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if until, err = parse("until", untilValue, 1); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !since.IsZero() && !until.IsZero() && !until.After(since) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--until %s must be after --since %s", untilValue, sinceValue)
+	}
+	return since, until, nil
+}
+
+// periodSince returns when period starts as of now, or the zero time for
+// all time. Transcripts that start earlier need not be parsed.
+func periodSince(period string, now time.Time) time.Time {
+	from, _, err := reporter.PeriodWindow(period, now)
+	if err != nil {
+		return time.Time{}
+	}
+	return from
+}
+
 var agentsCmd = &cobra.Command{
 	Use:   "agents",
 	Short: "List available agents",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dir := agentsDir
-		if dir == "" {
-			home, err := os.UserHomeDir()
-			if err != nil {
-				return fmt.Errorf("failed to get home directory: %w", err)
-			}
-			dir = home + "/.openclaw/agents"
+		roots, err := resolveAgentsRoots()
+		if err != nil {
+			return err
 		}
-
-		p := parser.New(dir)
-		agents, err := p.ListAgents()
+		settings, err := loadSettings()
 		if err != nil {
-			return fmt.Errorf("failed to list agents: %w", err)
+			return err
 		}
+		names := settings.AgentNames
 
-		if len(agents) == 0 {
-			fmt.Println("No agents found")
-			return nil
+		found := false
+		for _, root := range roots {
+			p := newParser(root.Dir, settings)
+			agents, err := p.ListAgents()
+			if err != nil {
+				if len(roots) == 1 {
+					return fmt.Errorf("failed to list agents: %w", err)
+				}
+				fmt.Fprintf(os.Stderr, "Warning: failed to list agents for tenant %s: %v\n", root.Tenant, err)
+				continue
+			}
+			if len(agents) == 0 {
+				continue
+			}
+
+			if !found {
+				fmt.Println("Available agents:")
+				found = true
+			}
+			for _, agent := range agents {
+				label := agent
+				if name := report.DisplayName(names, agent); name != agent {
+					label = fmt.Sprintf("%s as %s", agent, name)
+				}
+				if root.Tenant != "" {
+					fmt.Printf("  - %s (%s)\n", label, root.Tenant)
+				} else {
+					fmt.Printf("  - %s\n", label)
+				}
+			}
 		}
 
-		fmt.Println("Available agents:")
-		for _, agent := range agents {
-			fmt.Printf("  - %s\n", agent)
+		if !found {
+			fmt.Println("No agents found")
 		}
 		return nil
 	},
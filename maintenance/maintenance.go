@@ -0,0 +1,219 @@
+// Package maintenance declares windows during which alert rules are muted,
+// so planned expensive work such as a weekly batch run doesn't page anyone.
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Window mutes anomalies of its rules while it is active. A window is
+// either recurring, starting at every time matching Schedule and lasting
+// Duration, or a fixed range from From until Until.
+type Window struct {
+	Name  string
+	Rules []string // anomaly types muted; empty mutes every rule
+	Crons []string // crons whose anomalies are muted; empty for any cron
+	// Schedule and Duration define a recurring window.
+	Schedule *Schedule
+	Duration time.Duration
+	// From and Until define a fixed window; Until is exclusive. A zero
+	// bound leaves that end open.
+	From  time.Time
+	Until time.Time
+	// Location is the time zone Schedule is read in; nil means local time.
+	Location *time.Location
+}
+
+// maxDuration bounds recurring windows, which are found by searching back
+// from the time being checked.
+const maxDuration = 31 * 24 * time.Hour
+
+// Validate reports a window that can never be active or is ambiguous.
+func (w Window) Validate() error {
+	if w.Schedule != nil {
+		if !w.From.IsZero() || !w.Until.IsZero() {
+			return errors.New("use either a schedule or from/until, not both")
+		}
+		if w.Duration <= 0 || w.Duration > maxDuration {
+			return fmt.Errorf("a schedule needs a positive duration of at most %s", maxDuration)
+		}
+		return nil
+	}
+	if w.From.IsZero() && w.Until.IsZero() {
+		return errors.New("a schedule or from/until is required")
+	}
+	if !w.From.IsZero() && !w.Until.IsZero() && !w.Until.After(w.From) {
+		return errors.New("until must be after from")
+	}
+	return nil
+}
+
+// Active reports whether t falls inside the window.
+func (w Window) Active(t time.Time) bool {
+	if w.Schedule == nil {
+		return (w.From.IsZero() || !t.Before(w.From)) && (w.Until.IsZero() || t.Before(w.Until))
+	}
+	loc := w.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	// A start in (t-Duration, t] puts t inside the window.
+	t = t.In(loc)
+	for start := t.Truncate(time.Minute); start.After(t.Add(-w.Duration)); start = start.Add(-time.Minute) {
+		if w.Schedule.Matches(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// Mutes reports whether the window silences an anomaly of rule about cron
+// (empty for anomalies not about a cron run) observed at t.
+func (w Window) Mutes(rule, cron string, t time.Time) bool {
+	if len(w.Rules) > 0 && !slices.Contains(w.Rules, rule) {
+		return false
+	}
+	if len(w.Crons) > 0 && !slices.Contains(w.Crons, cron) {
+		return false
+	}
+	return w.Active(t)
+}
+
+// Muting returns the first of windows that mutes an anomaly of rule about
+// cron observed at t.
+func Muting(windows []Window, rule, cron string, t time.Time) (Window, bool) {
+	for _, w := range windows {
+		if w.Mutes(rule, cron, t) {
+			return w, true
+		}
+	}
+	return Window{}, false
+}
+
+// ParseTime reads a window bound as RFC 3339 or a YYYY-MM-DD date in loc
+// (nil for local time). With end set, a date means the end of that day,
+// so "until": "2026-07-03" includes all of July 3.
+func ParseTime(s string, loc *time.Location, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	t, err := time.ParseInLocation("2006-01-02", s, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q (use YYYY-MM-DD or RFC 3339)", s)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// Schedule is a standard five-field cron expression: minute, hour, day of
+// month, month and day of week. Fields accept *, numbers, ranges (1-5),
+// lists (1,15) and steps (*/15, 0-30/10); day of week runs from 0
+// (Sunday) to 6, with 7 also meaning Sunday. As in cron, when both day
+// fields are restricted a time matching either one matches.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64 // bit n set when value n matches
+	domAny, dowAny                bool
+}
+
+// shorthands are the @-prefixed aliases cron accepts.
+var shorthands = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+	"@yearly":  "0 0 1 1 *",
+}
+
+// ParseSchedule parses a cron expression such as "0 2 * * 6" (02:00 every
+// Saturday) or one of @hourly, @daily, @weekly, @monthly and @yearly.
+func ParseSchedule(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if s, ok := shorthands[spec]; ok {
+		spec = s
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: want 5 fields (minute hour day month weekday)", expr)
+	}
+
+	var s Schedule
+	for i, f := range []struct {
+		bits     *uint64
+		min, max int
+	}{{&s.minute, 0, 59}, {&s.hour, 0, 23}, {&s.dom, 1, 31}, {&s.month, 1, 12}, {&s.dow, 0, 7}} {
+		bits, err := parseField(fields[i], f.min, f.max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+		}
+		*f.bits = bits
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny = strings.HasPrefix(fields[2], "*")
+	s.dowAny = strings.HasPrefix(fields[4], "*")
+	return &s, nil
+}
+
+// parseField returns the values a field matches as a bit set.
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if before, after, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(after)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rng, step = before, n
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			first, last, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(first); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(last); err != nil {
+					return 0, fmt.Errorf("invalid value %q", part)
+				}
+			} else if step > 1 {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// Matches reports whether the minute t falls in matches the schedule, in
+// t's location.
+func (s *Schedule) Matches(t time.Time) bool {
+	if s.minute&(1<<t.Minute()) == 0 || s.hour&(1<<t.Hour()) == 0 || s.month&(1<<int(t.Month())) == 0 {
+		return false
+	}
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<int(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
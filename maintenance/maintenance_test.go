@@ -0,0 +1,140 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	sat0230 := time.Date(2026, 10, 17, 2, 30, 0, 0, time.UTC) // a Saturday
+	tests := []struct {
+		expr string
+		at   time.Time
+		want bool
+	}{
+		{"* * * * *", sat0230, true},
+		{"30 2 * * 6", sat0230, true},
+		{"30 2 * * 5", sat0230, false},
+		{"*/15 * * * *", sat0230, true},
+		{"*/20 * * * *", sat0230, false},
+		{"0-30/10 1-3 * * *", sat0230, true},
+		{"30 2 * 10 *", sat0230, true},
+		{"30 2 * 1,2 *", sat0230, false},
+		// Both day fields restricted: either one matching is enough.
+		{"30 2 17 * 1", sat0230, true},
+		{"30 2 1 * 6", sat0230, true},
+		{"30 2 1 * 1", sat0230, false},
+		{"30 2 * * 7", time.Date(2026, 10, 18, 2, 30, 0, 0, time.UTC), true},
+		{"@daily", time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), true},
+		{"@daily", sat0230, false},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q): %v", tt.expr, err)
+		}
+		if got := s.Matches(tt.at); got != tt.want {
+			t.Errorf("%q matches %s = %v, want %v", tt.expr, tt.at, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "x * * * *"} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestWindowActive(t *testing.T) {
+	schedule, err := ParseSchedule("0 2 * * 6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recurring := Window{Name: "batch", Schedule: schedule, Duration: 4 * time.Hour, Location: time.UTC}
+	fixed := Window{
+		Name:  "migration",
+		From:  time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2026, 7, 3, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		w    Window
+		at   time.Time
+		want bool
+	}{
+		{recurring, time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC), true},
+		{recurring, time.Date(2026, 10, 17, 5, 59, 0, 0, time.UTC), true},
+		{recurring, time.Date(2026, 10, 17, 6, 0, 0, 0, time.UTC), false},
+		{recurring, time.Date(2026, 10, 17, 1, 59, 0, 0, time.UTC), false},
+		{recurring, time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC), false},
+		{fixed, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), true},
+		{fixed, time.Date(2026, 7, 2, 23, 59, 0, 0, time.UTC), true},
+		{fixed, time.Date(2026, 7, 3, 0, 0, 0, 0, time.UTC), false},
+		{fixed, time.Date(2026, 6, 30, 23, 59, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		if got := tt.w.Active(tt.at); got != tt.want {
+			t.Errorf("%s active at %s = %v, want %v", tt.w.Name, tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestMuting(t *testing.T) {
+	at := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	windows := []Window{
+		{Name: "reindex", Rules: []string{"expensive_cron"}, Crons: []string{"reindex"}, From: at.Add(-time.Hour)},
+		{Name: "freeze", Rules: []string{"zero_cost"}, Until: at.Add(-time.Hour)},
+	}
+
+	if w, ok := Muting(windows, "expensive_cron", "reindex", at); !ok || w.Name != "reindex" {
+		t.Errorf("expensive reindex run muted by %q, %v; want reindex", w.Name, ok)
+	}
+	if _, ok := Muting(windows, "high_token_count", "reindex", at); ok {
+		t.Error("rule outside the window's rules was muted")
+	}
+	if _, ok := Muting(windows, "expensive_cron", "backup", at); ok {
+		t.Error("cron outside the window's crons was muted")
+	}
+	if _, ok := Muting(windows, "expensive_cron", "reindex", at.Add(-2*time.Hour)); ok {
+		t.Error("anomaly before the window was muted")
+	}
+	if w, ok := Muting(windows, "zero_cost", "", at.Add(-2*time.Hour)); !ok || w.Name != "freeze" {
+		t.Errorf("anomaly during freeze muted by %q, %v; want freeze", w.Name, ok)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schedule, _ := ParseSchedule("@daily")
+	from := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	invalid := []Window{
+		{Name: "empty"},
+		{Name: "no-duration", Schedule: schedule},
+		{Name: "too-long", Schedule: schedule, Duration: 32 * 24 * time.Hour},
+		{Name: "both", Schedule: schedule, Duration: time.Hour, From: from},
+		{Name: "backwards", From: from, Until: from.Add(-time.Hour)},
+	}
+	for _, w := range invalid {
+		if err := w.Validate(); err == nil {
+			t.Errorf("window %s validated, want an error", w.Name)
+		}
+	}
+	if err := (Window{Name: "ok", Schedule: schedule, Duration: time.Hour}).Validate(); err != nil {
+		t.Errorf("valid window: %v", err)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	got, err := ParseTime("2026-07-03", time.UTC, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 7, 4, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("until date = %s, want %s", got, want)
+	}
+	if got, _ := ParseTime("2026-07-03T10:00:00Z", nil, true); !got.Equal(time.Date(2026, 7, 3, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("RFC 3339 time = %s", got)
+	}
+	if _, err := ParseTime("July 3", nil, false); err == nil {
+		t.Error("ParseTime accepted an invalid time")
+	}
+}
@@ -0,0 +1,130 @@
+// Package notes loads freeform annotations that explain cost changes,
+// such as "2026-03-02: enabled prompt caching".
+package notes
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Note is a single annotation scoped to a date, a cron, or both.
+type Note struct {
+	Date string // YYYY-MM-DD, empty for notes that apply to a cron at any date
+	Cron string // cron name, empty for notes that apply to a whole day
+	Text string
+}
+
+// linePattern matches "[YYYY-MM-DD] [cron:NAME]: text".
+var linePattern = regexp.MustCompile(`^(?:(\d{4}-\d{2}-\d{2})\s*)?(?:cron:([^\s:]+)\s*)?:\s*(.+)$`)
+
+// Parse reads notes, one per line. Blank lines and lines starting with #
+// are ignored. Examples:
+//
+//	2026-03-02: enabled prompt caching
+//	cron:daily-kickoff: expected to cost ~$0.20 per run
+//	2026-03-05 cron:code-reviewer: doubled review context
+func Parse(r io.Reader) ([]Note, error) {
+	var notes []Note
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		matches := linePattern.FindStringSubmatch(line)
+		if matches == nil || (matches[1] == "" && matches[2] == "") {
+			return nil, fmt.Errorf("line %d: expected \"YYYY-MM-DD: text\" or \"cron:NAME: text\", got %q", lineNum, line)
+		}
+		notes = append(notes, Note{Date: matches[1], Cron: matches[2], Text: matches[3]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// Load reads a notes file. A missing file is not an error.
+func Load(path string) ([]Note, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open notes file: %w", err)
+	}
+	defer file.Close()
+
+	notes, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse notes file %s: %w", path, err)
+	}
+	return notes, nil
+}
+
+// ForDate returns the text of notes dated date. Cron-scoped notes are
+// prefixed with the cron name.
+func ForDate(notes []Note, date string) []string {
+	var result []string
+	for _, n := range notes {
+		if n.Date != date {
+			continue
+		}
+		if n.Cron != "" {
+			result = append(result, n.Cron+": "+n.Text)
+		} else {
+			result = append(result, n.Text)
+		}
+	}
+	return result
+}
+
+// ForCron returns the text of notes scoped to cron. Dated notes are
+// prefixed with their date.
+func ForCron(notes []Note, cron string) []string {
+	var result []string
+	for _, n := range notes {
+		if n.Cron != cron {
+			continue
+		}
+		if n.Date != "" {
+			result = append(result, n.Date+": "+n.Text)
+		} else {
+			result = append(result, n.Text)
+		}
+	}
+	return result
+}
+
+// InWindow returns the text of dated notes whose day starts within
+// [from, until), in from's location, oldest first. Each is prefixed with
+// its date, and cron-scoped notes with the cron name as well.
+func InWindow(notes []Note, from, until time.Time) []string {
+	var dated []Note
+	for _, n := range notes {
+		day, err := time.ParseInLocation("2006-01-02", n.Date, from.Location())
+		if err != nil || day.Before(from) || !day.Before(until) {
+			continue
+		}
+		dated = append(dated, n)
+	}
+	sort.SliceStable(dated, func(i, j int) bool { return dated[i].Date < dated[j].Date })
+
+	result := make([]string, 0, len(dated))
+	for _, n := range dated {
+		if n.Cron != "" {
+			result = append(result, n.Date+": "+n.Cron+": "+n.Text)
+		} else {
+			result = append(result, n.Date+": "+n.Text)
+		}
+	}
+	return result
+}
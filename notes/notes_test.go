@@ -0,0 +1,87 @@
+package notes
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	input := `# cost notes
+2026-03-02: enabled prompt caching
+
+cron:daily-kickoff: expected to cost ~$0.20 per run
+2026-03-05 cron:code-reviewer: doubled review context: now 200k
+`
+	notes, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := []Note{
+		{Date: "2026-03-02", Text: "enabled prompt caching"},
+		{Cron: "daily-kickoff", Text: "expected to cost ~$0.20 per run"},
+		{Date: "2026-03-05", Cron: "code-reviewer", Text: "doubled review context: now 200k"},
+	}
+	if len(notes) != len(expected) {
+		t.Fatalf("expected %d notes, got %d: %+v", len(expected), len(notes), notes)
+	}
+	for i, exp := range expected {
+		if notes[i] != exp {
+			t.Errorf("note %d = %+v, want %+v", i, notes[i], exp)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, line := range []string{"just some text", ": no scope", "2026-03-02 missing colon"} {
+		if _, err := Parse(strings.NewReader(line)); err == nil {
+			t.Errorf("expected error for %q", line)
+		}
+	}
+}
+
+func TestForDateAndCron(t *testing.T) {
+	notes := []Note{
+		{Date: "2026-03-02", Text: "enabled caching"},
+		{Date: "2026-03-02", Cron: "sync", Text: "switched to haiku"},
+		{Cron: "sync", Text: "runs hourly"},
+	}
+
+	day := ForDate(notes, "2026-03-02")
+	if len(day) != 2 || day[1] != "sync: switched to haiku" {
+		t.Errorf("ForDate = %v", day)
+	}
+
+	cron := ForCron(notes, "sync")
+	if len(cron) != 2 || cron[0] != "2026-03-02: switched to haiku" || cron[1] != "runs hourly" {
+		t.Errorf("ForCron = %v", cron)
+	}
+}
+
+func TestInWindow(t *testing.T) {
+	notes := []Note{
+		{Date: "2026-03-05", Cron: "sync", Text: "doubled context"},
+		{Date: "2026-03-02", Text: "enabled caching"},
+		{Date: "2026-03-09", Text: "too late"},
+		{Cron: "sync", Text: "runs hourly"},
+	}
+	from := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)
+
+	got := InWindow(notes, from, until)
+	if len(got) != 2 || got[0] != "2026-03-02: enabled caching" || got[1] != "2026-03-05: sync: doubled context" {
+		t.Errorf("InWindow = %v", got)
+	}
+	// A day starting before the window belongs to the window before it.
+	if got := InWindow(notes, time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC), until); len(got) != 1 {
+		t.Errorf("expected only the note of March 5, got %v", got)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	notes, err := Load(t.TempDir() + "/missing.txt")
+	if err != nil || notes != nil {
+		t.Errorf("expected missing file to yield no notes, got %v, %v", notes, err)
+	}
+}
@@ -0,0 +1,255 @@
+// Package notify delivers anomalies to external systems.
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/misty-step/costctl/reporter"
+)
+
+// PayloadVersion is bumped on incompatible changes to Payload.
+const PayloadVersion = 1
+
+// Payload is the JSON body posted for each anomaly. Fields are only ever
+// added within a PayloadVersion.
+type Payload struct {
+	Version        int               `json:"version"`
+	ID             string            `json:"id"`                   // stable per anomaly and period, for de-duplication
+	AnomalyID      string            `json:"anomaly_id,omitempty"` // stable per anomaly across periods; see reporter.AnomalyID
+	SentAt         time.Time         `json:"sent_at"`
+	Rule           string            `json:"rule"` // anomaly type, e.g. expensive_cron
+	Severity       string            `json:"severity"`
+	Description    string            `json:"description"`
+	Scope          Scope             `json:"scope"`
+	Value          float64           `json:"value"`                     // cost in dollars
+	Threshold      float64           `json:"threshold,omitempty"`       // dollar limit, when the rule has one
+	Tokens         int               `json:"tokens,omitempty"`          // tokens counted, for token-denominated rules
+	TokenThreshold int               `json:"token_threshold,omitempty"` // token limit, when the rule has one
+	Period         string            `json:"period"`                    // report period; "all" when unbounded
+	Owner          *Owner            `json:"owner,omitempty"`           // owner of the cron, when configured
+	Links          map[string]string `json:"links,omitempty"`
+}
+
+// Owner is the team responsible for the cron an anomaly is about.
+type Owner struct {
+	Team    string `json:"team,omitempty"`
+	Email   string `json:"email,omitempty"`
+	Channel string `json:"channel,omitempty"`
+}
+
+// Scope identifies what an anomaly is about.
+type Scope struct {
+	Agent     string `json:"agent,omitempty"`
+	Cron      string `json:"cron,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// Signature headers. The signature is "sha256=" followed by the hex
+// HMAC-SHA256 of the request body keyed with the configured secret.
+const (
+	SignatureHeader = "X-Costctl-Signature"
+	DeliveryHeader  = "X-Costctl-Delivery"
+)
+
+// Webhook posts anomaly payloads to a URL.
+type Webhook struct {
+	URL        string
+	Secret     string
+	Retries    int
+	DeadLetter string // file undeliverable payloads are appended to
+	Links      map[string]string
+	Routes     map[string]string // cron name to the URL its anomalies go to instead of URL
+
+	Client  *http.Client
+	Backoff time.Duration       // delay before the first retry; doubles after each
+	Sleep   func(time.Duration) // replaced in tests
+	Now     func() time.Time    // replaced in tests
+}
+
+// NewWebhook creates a Webhook with a 10s request timeout and a 1s initial
+// backoff.
+func NewWebhook(url, secret string, retries int, deadLetter string, links map[string]string) *Webhook {
+	return &Webhook{
+		URL:        url,
+		Secret:     secret,
+		Retries:    retries,
+		DeadLetter: deadLetter,
+		Links:      links,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+		Backoff:    time.Second,
+		Sleep:      time.Sleep,
+		Now:        time.Now,
+	}
+}
+
+// NewPayload builds the payload for an anomaly found in a report over
+// period.
+func (w *Webhook) NewPayload(a reporter.Anomaly, period string) Payload {
+	if period == "" {
+		period = "all"
+	}
+	id := sha256.Sum256([]byte(reporter.AnomalyID(a) + "\x00" + period))
+	payload := Payload{
+		Version:        PayloadVersion,
+		ID:             hex.EncodeToString(id[:8]),
+		AnomalyID:      a.ID,
+		SentAt:         w.Now().UTC(),
+		Rule:           a.Type,
+		Severity:       a.Severity,
+		Description:    a.Description,
+		Scope:          Scope{Agent: a.Agent, Cron: a.Cron, SessionID: a.SessionID},
+		Value:          a.Cost,
+		Threshold:      a.Threshold,
+		Tokens:         a.Tokens,
+		TokenThreshold: a.TokenThreshold,
+		Period:         period,
+		Links:          w.Links,
+	}
+	if a.Owner != nil {
+		payload.Owner = &Owner{Team: a.Owner.Team, Email: a.Owner.Email, Channel: a.Owner.Channel}
+	}
+	return payload
+}
+
+// route returns the URL an anomaly is delivered to: its cron's route if one
+// is configured, otherwise URL.
+func (w *Webhook) route(a reporter.Anomaly) string {
+	if url := w.Routes[a.Cron]; a.Cron != "" && url != "" {
+		return url
+	}
+	return w.URL
+}
+
+// Send delivers the anomalies of a report one by one, routing anomalies
+// about a cron to its owner's URL when one is configured. Anomalies with no
+// URL to go to, and those muted by a maintenance window, are not sent.
+// Payloads that still fail after all retries are appended to the
+// dead-letter file. Send returns the IDs of the anomalies delivered, so
+// only those are recorded as notified, and the number of failures; it
+// returns an error only if the dead-letter file cannot be written.
+func (w *Webhook) Send(anomalies []reporter.Anomaly, period string) ([]string, int, error) {
+	var delivered []string
+	failed := 0
+	for _, a := range anomalies {
+		if a.Muted != "" {
+			continue
+		}
+		payload := w.NewPayload(a, period)
+		url := w.route(a)
+		if url == "" {
+			continue
+		}
+		attempts, err := w.deliver(url, payload)
+		if err == nil {
+			delivered = append(delivered, a.ID)
+			continue
+		}
+		failed++
+		fmt.Fprintf(os.Stderr, "Warning: webhook delivery of %s %s failed after %d attempts: %v\n", payload.Rule, payload.ID, attempts, err)
+		if err := w.deadLetter(url, payload, attempts, err); err != nil {
+			return delivered, failed, err
+		}
+	}
+	return delivered, failed, nil
+}
+
+// deliver posts a payload to url, retrying network errors, 5xx and 429
+// responses with exponential backoff. It returns the number of attempts made.
+func (w *Webhook) deliver(url string, payload Payload) (int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	backoff := w.Backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := w.post(url, body, payload.ID)
+		if err == nil {
+			return attempt, nil
+		}
+		if !retry || attempt > w.Retries {
+			return attempt, err
+		}
+		w.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post makes a single delivery attempt and reports whether a failure is
+// worth retrying.
+func (w *Webhook) post(url string, body []byte, id string) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryHeader, id)
+	if w.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+	}
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected status %s", resp.Status)
+}
+
+// deadLetterEntry is one line of the dead-letter file.
+type deadLetterEntry struct {
+	FailedAt time.Time `json:"failed_at"`
+	URL      string    `json:"url"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+	Payload  Payload   `json:"payload"`
+}
+
+// deadLetter appends an undeliverable payload to the dead-letter file.
+func (w *Webhook) deadLetter(url string, payload Payload, attempts int, cause error) error {
+	line, err := json.Marshal(deadLetterEntry{
+		FailedAt: w.Now().UTC(),
+		URL:      url,
+		Attempts: attempts,
+		Error:    cause.Error(),
+		Payload:  payload,
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(w.DeadLetter), 0755); err != nil {
+		return fmt.Errorf("failed to create dead-letter directory: %w", err)
+	}
+	file, err := os.OpenFile(w.DeadLetter, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write dead-letter file: %w", err)
+	}
+	return nil
+}
+
+// Sign returns the signature header value for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
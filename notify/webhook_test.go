@@ -0,0 +1,211 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/reporter"
+)
+
+var testAnomaly = reporter.Anomaly{
+	Type:        "expensive_cron",
+	Description: "Cron sync exceeded $0.50 threshold",
+	Severity:    "warning",
+	Cost:        1.25,
+	Threshold:   0.5,
+	SessionID:   "s1",
+	Agent:       "urza",
+}
+
+func newTestWebhook(url string, t *testing.T) (*Webhook, *[]time.Duration) {
+	var sleeps []time.Duration
+	w := NewWebhook(url, "s3cret", 2, filepath.Join(t.TempDir(), "dead.jsonl"), map[string]string{"dashboard": "https://costs.example"})
+	w.Sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	w.Now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return w, &sleeps
+}
+
+func TestWebhookSendSignsPayload(t *testing.T) {
+	var got Payload
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		if sig := req.Header.Get(SignatureHeader); sig != Sign("s3cret", body) {
+			t.Errorf("bad signature %q", sig)
+		}
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		if req.Header.Get(DeliveryHeader) != got.ID {
+			t.Errorf("expected delivery header %q, got %q", got.ID, req.Header.Get(DeliveryHeader))
+		}
+	}))
+	defer server.Close()
+
+	w, _ := newTestWebhook(server.URL, t)
+	_, failed, err := w.Send([]reporter.Anomaly{testAnomaly}, "")
+	if err != nil || failed != 0 {
+		t.Fatalf("Send: failed=%d err=%v", failed, err)
+	}
+	if got.Version != PayloadVersion || got.Rule != "expensive_cron" || got.Scope.Agent != "urza" ||
+		got.Value != 1.25 || got.Threshold != 0.5 || got.Period != "all" || got.Links["dashboard"] == "" {
+		t.Errorf("unexpected payload: %+v", got)
+	}
+	if again := w.NewPayload(testAnomaly, "all"); again.ID != got.ID {
+		t.Errorf("expected a stable ID, got %q and %q", got.ID, again.ID)
+	}
+}
+
+func TestNewPayloadIDs(t *testing.T) {
+	w, _ := newTestWebhook("", t)
+	// Anomalies about different crons, without a session, are different
+	// deliveries.
+	growth := reporter.Anomaly{Type: "context_growth", Cron: "memory-sync"}
+	other := growth
+	other.Cron = "backup-check"
+	ids := map[string]bool{
+		w.NewPayload(growth, "week").ID:      true,
+		w.NewPayload(other, "week").ID:       true,
+		w.NewPayload(growth, "month").ID:     true,
+		w.NewPayload(testAnomaly, "week").ID: true,
+	}
+	if len(ids) != 4 {
+		t.Errorf("expected a delivery ID per anomaly and period, got %v", ids)
+	}
+}
+
+func TestWebhookRetriesWithBackoff(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+		if calls < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	w, sleeps := newTestWebhook(server.URL, t)
+	_, failed, err := w.Send([]reporter.Anomaly{testAnomaly}, "week")
+	if err != nil || failed != 0 {
+		t.Fatalf("Send: failed=%d err=%v", failed, err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+	if len(*sleeps) != 2 || (*sleeps)[0] != time.Second || (*sleeps)[1] != 2*time.Second {
+		t.Errorf("expected 1s then 2s backoff, got %v", *sleeps)
+	}
+}
+
+func TestWebhookDeadLetters(t *testing.T) {
+	tests := []struct {
+		status   int
+		attempts int
+	}{
+		{http.StatusInternalServerError, 3}, // retried until exhausted
+		{http.StatusBadRequest, 1},          // permanent, not retried
+	}
+
+	for _, tt := range tests {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			calls++
+			rw.WriteHeader(tt.status)
+		}))
+
+		w, _ := newTestWebhook(server.URL, t)
+		delivered, failed, err := w.Send([]reporter.Anomaly{testAnomaly}, "today")
+		server.Close()
+		if err != nil || failed != 1 || len(delivered) != 0 {
+			t.Fatalf("status %d: delivered=%v failed=%d err=%v", tt.status, delivered, failed, err)
+		}
+		if calls != tt.attempts {
+			t.Errorf("status %d: expected %d attempts, got %d", tt.status, tt.attempts, calls)
+		}
+
+		data, err := os.ReadFile(w.DeadLetter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var entry deadLetterEntry
+		if err := json.Unmarshal([]byte(strings.TrimSpace(string(data))), &entry); err != nil {
+			t.Fatalf("invalid dead-letter line: %v", err)
+		}
+		if entry.Attempts != tt.attempts || entry.Payload.Rule != "expensive_cron" || entry.Payload.Period != "today" {
+			t.Errorf("status %d: unexpected dead-letter entry %+v", tt.status, entry)
+		}
+	}
+}
+
+func TestWebhookRoutesCronOwners(t *testing.T) {
+	var firehose, owned []Payload
+	record := func(into *[]Payload) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			var p Payload
+			json.NewDecoder(req.Body).Decode(&p)
+			*into = append(*into, p)
+		}))
+	}
+	firehoseServer, ownedServer := record(&firehose), record(&owned)
+	defer firehoseServer.Close()
+	defer ownedServer.Close()
+
+	routed := testAnomaly
+	routed.ID = "a1"
+	routed.Cron = "sync"
+	routed.Owner = &reporter.CronOwner{Team: "platform", Channel: "#platform-alerts"}
+	other := testAnomaly
+	other.ID = "a2"
+	other.Cron = "digest"
+
+	w, _ := newTestWebhook(firehoseServer.URL, t)
+	w.Routes = map[string]string{"sync": ownedServer.URL}
+	if _, failed, err := w.Send([]reporter.Anomaly{routed, other}, "week"); err != nil || failed != 0 {
+		t.Fatalf("Send: failed=%d err=%v", failed, err)
+	}
+	if len(owned) != 1 || owned[0].Scope.Cron != "sync" || owned[0].Owner == nil || owned[0].Owner.Channel != "#platform-alerts" {
+		t.Errorf("expected the sync anomaly at its owner's URL, got %+v", owned)
+	}
+	if len(firehose) != 1 || firehose[0].Scope.Cron != "digest" || firehose[0].Owner != nil {
+		t.Errorf("expected the digest anomaly at the default URL, got %+v", firehose)
+	}
+
+	// Without a default URL, unrouted anomalies are not sent.
+	owned = nil
+	w.URL = ""
+	delivered, failed, err := w.Send([]reporter.Anomaly{routed, other}, "week")
+	if err != nil || failed != 0 || len(delivered) != 1 || delivered[0] != "a1" {
+		t.Fatalf("Send: delivered=%v failed=%d err=%v", delivered, failed, err)
+	}
+	if len(owned) != 1 || len(firehose) != 1 {
+		t.Errorf("expected only the routed anomaly to be sent, got %d routed and %d default", len(owned), len(firehose))
+	}
+}
+
+func TestWebhookSkipsMutedAnomalies(t *testing.T) {
+	var got []Payload
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var p Payload
+		json.NewDecoder(req.Body).Decode(&p)
+		got = append(got, p)
+	}))
+	defer server.Close()
+
+	muted := testAnomaly
+	muted.SessionID = "s2"
+	muted.Muted = "weekly-batch"
+
+	w, _ := newTestWebhook(server.URL, t)
+	if _, failed, err := w.Send([]reporter.Anomaly{testAnomaly, muted}, "week"); err != nil || failed != 0 {
+		t.Fatalf("Send: failed=%d err=%v", failed, err)
+	}
+	if len(got) != 1 || got[0].Scope.SessionID != "s1" {
+		t.Errorf("expected only the unmuted anomaly to be sent, got %+v", got)
+	}
+}
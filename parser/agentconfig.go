@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// agentConfigFile is the per-agent settings file, relative to the agent's
+// directory.
+const agentConfigFile = "config.json"
+
+// readAgentConfig returns the contents of {agentsDir}/{agent}/config.json
+// and its path. The contents are nil when the file is missing.
+func (p *Parser) readAgentConfig(agent string) ([]byte, string, error) {
+	path := filepath.Join(p.AgentDir(agent), agentConfigFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, path, nil
+	}
+	return data, path, err
+}
+
+// DefaultModel returns the model an agent is configured to use, read from
+// {agentsDir}/{agent}/config.json. The model may be a string or an object
+// with a "primary" field. It returns "" when the file or the setting is
+// missing.
+func (p *Parser) DefaultModel(agent string) (string, error) {
+	data, path, err := p.readAgentConfig(agent)
+	if data == nil || err != nil {
+		return "", err
+	}
+
+	var cfg struct {
+		Model json.RawMessage `json:"model"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(cfg.Model) == 0 {
+		return "", nil
+	}
+
+	var model string
+	if err := json.Unmarshal(cfg.Model, &model); err == nil {
+		return model, nil
+	}
+	var models struct {
+		Primary string `json:"primary"`
+	}
+	if err := json.Unmarshal(cfg.Model, &models); err != nil {
+		return "", fmt.Errorf("failed to parse model in %s: %w", path, err)
+	}
+	return models.Primary, nil
+}
+
+// SpendCaps are the spend limits, in dollars, an agent is configured
+// with: Session for each session, and Crons for each run of the named
+// crons. Zero means no limit.
+type SpendCaps struct {
+	Session float64            `json:"session_cost"`
+	Crons   map[string]float64 `json:"cron_run_cost"`
+}
+
+// For returns the cap that applies to s: its cron's per-run cap, else the
+// per-session cap.
+func (c SpendCaps) For(s Session) float64 {
+	if s.Type == SessionTypeCron {
+		if limit, ok := c.Crons[s.CronName]; ok {
+			return limit
+		}
+	}
+	return c.Session
+}
+
+// SpendCaps returns the spend limits an agent is configured with, read
+// from the "limits" object of {agentsDir}/{agent}/config.json:
+//
+//	"limits": {"session_cost": 2.00, "cron_run_cost": {"daily-kickoff": 0.50}}
+//
+// It returns no limits when the file or the setting is missing.
+func (p *Parser) SpendCaps(agent string) (SpendCaps, error) {
+	data, path, err := p.readAgentConfig(agent)
+	if data == nil || err != nil {
+		return SpendCaps{}, err
+	}
+
+	var cfg struct {
+		Limits SpendCaps `json:"limits"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return SpendCaps{}, fmt.Errorf("failed to parse limits in %s: %w", path, err)
+	}
+	return cfg.Limits, nil
+}
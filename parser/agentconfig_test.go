@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultModel(t *testing.T) {
+	tempDir := t.TempDir()
+	configs := map[string]string{
+		"urza":   `{"model": "moonshotai/kimi-k2.5"}`,
+		"amos":   `{"model": {"primary": "anthropic/claude-sonnet-4-5", "fallbacks": ["kimi-k2.5"]}}`,
+		"pepper": `{"name": "pepper"}`,
+		"broken": `{"model": 42}`,
+	}
+	for agent, content := range configs {
+		if err := os.MkdirAll(filepath.Join(tempDir, agent), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tempDir, agent, "config.json"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := New(tempDir)
+	for agent, want := range map[string]string{
+		"urza":    "moonshotai/kimi-k2.5",
+		"amos":    "anthropic/claude-sonnet-4-5",
+		"pepper":  "",
+		"missing": "",
+	} {
+		got, err := p.DefaultModel(agent)
+		if err != nil || got != want {
+			t.Errorf("%s: expected %q, got %q (%v)", agent, want, got, err)
+		}
+	}
+	if _, err := p.DefaultModel("broken"); err == nil {
+		t.Error("expected an error for a malformed model")
+	}
+}
+
+func TestSpendCaps(t *testing.T) {
+	tempDir := t.TempDir()
+	configs := map[string]string{
+		"urza":   `{"model": "moonshotai/kimi-k2.5", "limits": {"session_cost": 2, "cron_run_cost": {"sync": 0.5}}}`,
+		"pepper": `{"model": "moonshotai/kimi-k2.5"}`,
+		"broken": `{"limits": {"session_cost": "2"}}`,
+	}
+	for agent, content := range configs {
+		if err := os.MkdirAll(filepath.Join(tempDir, agent), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tempDir, agent, "config.json"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := New(tempDir)
+	caps, err := p.SpendCaps("urza")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := caps.For(Session{Type: SessionTypeCron, CronName: "sync"}); got != 0.5 {
+		t.Errorf("expected sync's per-run cap, got %v", got)
+	}
+	if got := caps.For(Session{Type: SessionTypeCron, CronName: "digest"}); got != 2 {
+		t.Errorf("expected the session cap for other crons, got %v", got)
+	}
+	for _, agent := range []string{"pepper", "missing"} {
+		if caps, err := p.SpendCaps(agent); err != nil || caps.For(Session{}) != 0 {
+			t.Errorf("%s: expected no caps, got %+v (%v)", agent, caps, err)
+		}
+	}
+	if _, err := p.SpendCaps("broken"); err == nil {
+		t.Error("expected an error for a malformed limit")
+	}
+}
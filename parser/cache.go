@@ -0,0 +1,221 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache file layout: magic, format version (uint32), payload length
+// (uint64), SHA-256 of the payload, then the gob-encoded entries. All
+// integers are big-endian.
+const (
+	cacheMagic      = "costctlc"
+	cacheHeaderSize = len(cacheMagic) + 4 + 8 + sha256.Size
+)
+
+// cacheSettleTime is how long a transcript must be left unmodified before
+// its session is cached, covering coarse file system timestamps.
+const cacheSettleTime = 2 * time.Second
+
+// CacheVersion is the version of the cache format and of the sessions it
+// holds. Bump it whenever Session or what parseSessionFile derives from a
+// transcript changes, so caches written by older builds are rebuilt rather
+// than trusted.
+const CacheVersion = 5
+
+// ErrCacheCorrupt marks a cache file that failed its integrity checks.
+var ErrCacheCorrupt = errors.New("session cache is corrupt")
+
+// Cache holds sessions parsed from transcripts, keyed by path and
+// validated by the file's size and modification time, so unchanged
+// transcripts need not be read again. Message content is not kept; it is
+// only needed while parsing. It is safe for concurrent use, and Save
+// replaces the file atomically, so concurrent runs never see a partly
+// written cache.
+//
+// Only sessions parsed without Parser.AsOf should be cached: they are the
+// whole transcript as of its modification time.
+type Cache struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	dirty   bool
+}
+
+type cacheEntry struct {
+	Size    int64
+	ModTime int64 // Unix nanoseconds
+	Session Session
+}
+
+// NewCache returns an empty cache that saves to path.
+func NewCache(path string) *Cache {
+	return &Cache{path: path, entries: make(map[string]cacheEntry)}
+}
+
+// LoadCache reads the cache at path. A missing file, or one written in
+// another CacheVersion, yields an empty cache. A file that fails its
+// checks returns an error wrapping ErrCacheCorrupt; callers should report
+// it and rebuild from NewCache, which overwrites the file on Save.
+func LoadCache(path string) (*Cache, error) {
+	c := NewCache(path)
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session cache: %w", err)
+	}
+	defer f.Close()
+
+	header := make([]byte, cacheHeaderSize)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return nil, fmt.Errorf("%w: short header: %v", ErrCacheCorrupt, err)
+	}
+	if string(header[:len(cacheMagic)]) != cacheMagic {
+		return nil, fmt.Errorf("%w: not a session cache", ErrCacheCorrupt)
+	}
+	rest := header[len(cacheMagic):]
+	if binary.BigEndian.Uint32(rest) != CacheVersion {
+		return c, nil
+	}
+	length := binary.BigEndian.Uint64(rest[4:])
+	sum := rest[12:]
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat session cache: %w", err)
+	}
+	if uint64(info.Size()-int64(cacheHeaderSize)) != length {
+		return nil, fmt.Errorf("%w: payload is %d bytes, header says %d", ErrCacheCorrupt, info.Size()-int64(cacheHeaderSize), length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(f, payload); err != nil {
+		return nil, fmt.Errorf("failed to read session cache: %w", err)
+	}
+	if actual := sha256.Sum256(payload); !bytes.Equal(actual[:], sum) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrCacheCorrupt)
+	}
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&c.entries); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCacheCorrupt, err)
+	}
+	return c, nil
+}
+
+// Lookup returns the cached session for the transcript at path if info,
+// the transcript's current stat, matches the one it was cached with.
+func (c *Cache) Lookup(path string, info fs.FileInfo) (Session, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[path]
+	if !ok || e.Size != info.Size() || e.ModTime != info.ModTime().UnixNano() {
+		return Session{}, false
+	}
+	return e.Session, true
+}
+
+// Put caches the session parsed from the transcript at path, which had
+// the stat info when it was read. Transcripts modified within
+// cacheSettleTime are not cached: a write landing in the same modification
+// time tick would go unnoticed.
+func (c *Cache) Put(path string, info fs.FileInfo, s Session) {
+	if time.Since(info.ModTime()) < cacheSettleTime {
+		return
+	}
+	messages := make([]Message, len(s.Messages))
+	for i, msg := range s.Messages {
+		msg.Message.Content = nil
+		messages[i] = msg
+	}
+	s.Messages = messages
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[path] = cacheEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano(), Session: s}
+	c.dirty = true
+}
+
+// Prune drops the sessions of transcripts that no longer exist, such as
+// rotated or archived ones, and returns how many were dropped.
+func (c *Cache) Prune() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pruned := 0
+	for path := range c.entries {
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			delete(c.entries, path)
+			pruned++
+		}
+	}
+	if pruned > 0 {
+		c.dirty = true
+	}
+	return pruned
+}
+
+// Len returns the number of cached sessions.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Save writes the cache if it changed since it was loaded. The file is
+// written beside its final path and renamed into place.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+
+	var payload bytes.Buffer
+	if err := gob.NewEncoder(&payload).Encode(c.entries); err != nil {
+		return fmt.Errorf("failed to encode session cache: %w", err)
+	}
+	header := make([]byte, 0, cacheHeaderSize)
+	header = append(header, cacheMagic...)
+	header = binary.BigEndian.AppendUint32(header, CacheVersion)
+	header = binary.BigEndian.AppendUint64(header, uint64(payload.Len()))
+	sum := sha256.Sum256(payload.Bytes())
+	header = append(header, sum[:]...)
+
+	dir := filepath.Dir(c.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write session cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(header)
+	if err == nil {
+		_, err = payload.WriteTo(tmp)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write session cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write session cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
@@ -0,0 +1,223 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeCachedTranscript writes a transcript last modified an hour ago and
+// returns its path and stat.
+func writeCachedTranscript(t *testing.T, dir, name, content string) (string, os.FileInfo) {
+	t.Helper()
+	path := filepath.Join(dir, name+".jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, info
+}
+
+const cacheTranscript = `{"type":"message","timestamp":"2026-02-10T16:53:15.420Z","message":{"role":"assistant","content":[{"type":"text","text":"Hello"}],"usage":{"input":100,"output":50,"totalTokens":150,"cost":{"total":0.00125}},"model":"moonshotai/kimi-k2.5"}}
+{"type":"message","timestamp":"2026-02-10T16:54:00.000Z","message":{"role":"assistant","content":[],"usage":{"input":200,"output":100,"totalTokens":300,"cost":{"total":0.0025}},"model":"moonshotai/kimi-k2.5","stopReason":"error","errorMessage":"429 rate limit"}}`
+
+func TestCacheRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	transcript, info := writeCachedTranscript(t, dir, "agent:urza:cron:backup-abc123:run:r1", cacheTranscript)
+	session, err := New(dir).parseSessionFile("urza", "agent:urza:cron:backup-abc123:run:r1", transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "cache", "sessions.cache")
+	c := NewCache(path)
+	c.Put(transcript, info, session)
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadCache(path)
+	if err != nil {
+		t.Fatalf("LoadCache failed: %v", err)
+	}
+	cached, ok := loaded.Lookup(transcript, info)
+	if !ok {
+		t.Fatal("expected a cache hit for an unchanged transcript")
+	}
+	if !reflect.DeepEqual(cached.Usage, session.Usage) || !reflect.DeepEqual(cached.Errors, session.Errors) ||
+		!reflect.DeepEqual(cached.TokensByRole, session.TokensByRole) || !cached.StartedAt.Equal(session.StartedAt) ||
+		cached.CronName != "backup" || cached.Duration != session.Duration {
+		t.Errorf("cached session differs:\n got %+v\nwant %+v", cached, session)
+	}
+	if len(cached.Messages) != 2 || cached.Messages[0].Message.Content != nil || cached.Messages[1].Message.StopReason != "error" {
+		t.Errorf("expected messages kept without content, got %+v", cached.Messages)
+	}
+
+	// Appending to the transcript changes its size.
+	if err := os.WriteFile(transcript, []byte(cacheTranscript+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed, _ := os.Stat(transcript)
+	if _, ok := loaded.Lookup(transcript, changed); ok {
+		t.Error("expected a miss for a changed transcript")
+	}
+	// Nor is it cached while it may still be written to.
+	loaded.Put(transcript, changed, session)
+	if _, ok := loaded.Lookup(transcript, changed); ok {
+		t.Error("expected a just-modified transcript not to be cached")
+	}
+}
+
+func TestLoadCacheMissing(t *testing.T) {
+	c, err := LoadCache(filepath.Join(t.TempDir(), "none.cache"))
+	if err != nil || c.Len() != 0 {
+		t.Fatalf("expected an empty cache, got %v entries, err %v", c, err)
+	}
+}
+
+func TestLoadCacheCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	transcript, info := writeCachedTranscript(t, dir, "s1", cacheTranscript)
+	path := filepath.Join(dir, "sessions.cache")
+	c := NewCache(path)
+	c.Put(transcript, info, Session{ID: "s1", Usage: Usage{CostTotal: 1.5}})
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	valid, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flipped := append([]byte(nil), valid...)
+	flipped[len(flipped)-3] ^= 0x40
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"flipped bit", flipped},
+		{"truncated", valid[:len(valid)-10]},
+		{"appended", append(append([]byte(nil), valid...), 0)},
+		{"short header", valid[:10]},
+		{"not a cache", []byte("{\"sessions\": []}                                                      ")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(path, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadCache(path); !errors.Is(err, ErrCacheCorrupt) {
+				t.Errorf("expected ErrCacheCorrupt, got %v", err)
+			}
+		})
+	}
+
+	// A cache from another version is rebuilt, not reported.
+	other := append([]byte(nil), valid...)
+	binary.BigEndian.PutUint32(other[len(cacheMagic):], CacheVersion+1)
+	if err := os.WriteFile(path, other, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := LoadCache(path); err != nil || c.Len() != 0 {
+		t.Errorf("expected an empty cache for another version, got err %v", err)
+	}
+}
+
+func TestCacheConcurrentUse(t *testing.T) {
+	dir := t.TempDir()
+	transcript, info := writeCachedTranscript(t, dir, "s1", cacheTranscript)
+	path := filepath.Join(dir, "sessions.cache")
+	c := NewCache(path)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Put(transcript, info, Session{ID: "s1"})
+			c.Lookup(transcript, info)
+			if err := c.Save(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if loaded, err := LoadCache(path); err != nil || loaded.Len() != 1 {
+		t.Errorf("expected one cached session, got err %v", err)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(leftovers) != 0 {
+		t.Errorf("expected temporary files removed, got %v", leftovers)
+	}
+}
+
+func TestParserCache(t *testing.T) {
+	dir := t.TempDir()
+	sessionsDir := filepath.Join(dir, "urza", "sessions")
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	transcript, info := writeCachedTranscript(t, sessionsDir, "chat", cacheTranscript)
+
+	cache := NewCache(filepath.Join(dir, "sessions.cache"))
+	p := New(dir)
+	p.Cache = cache
+	sessions, err := p.ParseAll("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || cache.Len() != 1 {
+		t.Fatalf("expected 1 session parsed and cached, got %d and %d", len(sessions), cache.Len())
+	}
+
+	// A transcript with the same size and modification time is taken from
+	// the cache unread: here its cost was doubled in place.
+	rewritten := []byte(cacheTranscript)
+	copy(rewritten[bytes.Index(rewritten, []byte("0.00125")):], "0.00250")
+	if err := os.WriteFile(transcript, rewritten, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(transcript, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	p = New(dir)
+	p.Cache = cache
+	if sessions, _ = p.ParseAll(""); len(sessions) != 1 || sessions[0].Usage.CostTotal != 0.00375 {
+		t.Errorf("expected the cached cost of $0.00375, got %+v", sessions)
+	}
+
+	// As-of reports, and changed transcripts, are read again.
+	p = New(dir)
+	p.Cache = cache
+	p.AsOf = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	if sessions, _ = p.ParseAll(""); len(sessions) != 1 || sessions[0].Usage.CostTotal != 0.005 {
+		t.Errorf("expected the as-of report to read the transcript, got %+v", sessions)
+	}
+	writeCachedTranscript(t, sessionsDir, "chat", string(rewritten)+"\n")
+	p = New(dir)
+	p.Cache = cache
+	if sessions, _ = p.ParseAll(""); len(sessions) != 1 || sessions[0].Usage.CostTotal != 0.005 {
+		t.Errorf("expected the changed transcript to be read, got %+v", sessions)
+	}
+
+	if err := os.Remove(transcript); err != nil {
+		t.Fatal(err)
+	}
+	if pruned := cache.Prune(); pruned != 1 || cache.Len() != 0 {
+		t.Errorf("expected the removed transcript's session pruned, got %d pruned, %d left", pruned, cache.Len())
+	}
+}
@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultDiscovery finds agents in the flat layout, {agent}/sessions.
+var DefaultDiscovery = []string{"*"}
+
+// maxDiscoveryDepth bounds how many directories a ** segment descends, so
+// symlink loops and deep trees cannot stall discovery.
+const maxDiscoveryDepth = 8
+
+// ValidateDiscovery checks discovery patterns: relative, slash-separated
+// globs in path.Match syntax, where a ** segment matches any number of
+// directories.
+func ValidateDiscovery(patterns []string) error {
+	for _, pattern := range patterns {
+		if pattern == "" || path.IsAbs(pattern) || strings.Contains(pattern, `\`) {
+			return fmt.Errorf("invalid discovery pattern %q: must be a relative, slash-separated path", pattern)
+		}
+		for _, segment := range strings.Split(pattern, "/") {
+			if segment == ".." {
+				return fmt.Errorf("invalid discovery pattern %q: must stay within the agents directory", pattern)
+			}
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid discovery pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// WorkspaceOf returns the workspace of an agent named by discovery: the
+// segments before its last, so agent "acme/urza" of the nested layout is
+// in workspace "acme". Agents of the flat layout have none.
+func WorkspaceOf(agent string) string {
+	if i := strings.LastIndex(agent, "/"); i >= 0 {
+		return agent[:i]
+	}
+	return ""
+}
+
+// discovered is an agent directory found by a discovery pattern.
+type discovered struct {
+	name string // wildcard-matched segments, joined by /
+	dir  string
+}
+
+// discover finds the agent directories below the agents directory that
+// match p.Discovery and have a sessions subdirectory. Each agent is named
+// by the path segments its pattern's wildcards matched, so
+// workspaces/*/agents/* names workspaces/acme/agents/urza "acme/urza" and
+// the default * names it by its directory. When patterns name two agents
+// alike, the first pattern wins. Directories are listed concurrently, at
+// most parseWorkers at a time.
+func (p *Parser) discover() ([]discovered, error) {
+	if _, err := os.ReadDir(p.agentsDir); err != nil {
+		return nil, fmt.Errorf("failed to read agents directory: %w", err)
+	}
+	patterns := p.Discovery
+	if len(patterns) == 0 {
+		patterns = DefaultDiscovery
+	}
+
+	w := &discoverer{sem: make(chan struct{}, parseWorkers())}
+	found := make([][]discovered, len(patterns))
+	for i, pattern := range patterns {
+		w.wg.Add(1)
+		go func(i int, segments []string) {
+			defer w.wg.Done()
+			var mu sync.Mutex
+			w.walk(p.agentsDir, segments, nil, 0, func(d discovered) {
+				mu.Lock()
+				found[i] = append(found[i], d)
+				mu.Unlock()
+			})
+		}(i, strings.Split(pattern, "/"))
+	}
+	w.wg.Wait()
+
+	seen := make(map[string]bool)
+	var agents []discovered
+	for _, matches := range found {
+		sort.Slice(matches, func(a, b int) bool { return matches[a].name < matches[b].name })
+		for _, d := range matches {
+			if d.name == "" || seen[d.name] || !p.Filter.Agent(d.name) {
+				continue
+			}
+			seen[d.name] = true
+			agents = append(agents, d)
+		}
+	}
+	return agents, nil
+}
+
+// discoverer walks discovery patterns with a bounded number of concurrent
+// directory reads.
+type discoverer struct {
+	sem chan struct{}
+	wg  sync.WaitGroup
+}
+
+// walk matches the remaining pattern segments below dir, reporting each
+// agent directory found. name holds the segments wildcards have matched so
+// far and depth the directories ** has descended.
+func (w *discoverer) walk(dir string, segments, name []string, depth int, found func(discovered)) {
+	if len(segments) == 0 {
+		if info, err := os.Stat(filepath.Join(dir, "sessions")); err == nil && info.IsDir() {
+			found(discovered{name: strings.Join(name, "/"), dir: dir})
+		}
+		return
+	}
+
+	segment := segments[0]
+	if segment != "**" && !strings.ContainsAny(segment, `*?[`) {
+		w.walk(filepath.Join(dir, segment), segments[1:], name, 0, found)
+		return
+	}
+
+	w.sem <- struct{}{}
+	entries, err := os.ReadDir(dir)
+	<-w.sem
+	if err != nil {
+		return
+	}
+
+	if segment == "**" {
+		// ** may match no directory at all.
+		w.walk(dir, segments[1:], name, 0, found)
+		if depth >= maxDiscoveryDepth {
+			return
+		}
+	}
+	for _, entry := range entries {
+		if !isDir(dir, entry) {
+			continue
+		}
+		sub := append(append([]string(nil), name...), entry.Name())
+		next := filepath.Join(dir, entry.Name())
+		switch {
+		case segment == "**":
+			w.spawn(func() { w.walk(next, segments, sub, depth+1, found) })
+		default:
+			if ok, _ := path.Match(segment, entry.Name()); ok {
+				w.spawn(func() { w.walk(next, segments[1:], sub, 0, found) })
+			}
+		}
+	}
+}
+
+// spawn runs fn on its own goroutine, tracked by the walk's wait group.
+func (w *discoverer) spawn(fn func()) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		fn()
+	}()
+}
+
+// isDir reports whether entry is a directory or a symlink to one.
+func isDir(dir string, entry os.DirEntry) bool {
+	if entry.IsDir() {
+		return true
+	}
+	if entry.Type()&os.ModeSymlink == 0 {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(dir, entry.Name()))
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestListAgentsDiscovery(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, dir := range []string{
+		"urza/sessions",
+		"workspaces/acme/agents/pepper/sessions",
+		"workspaces/acme/agents/pepper-test/sessions",
+		"workspaces/globex/agents/urza/sessions",
+		"workspaces/globex/agents/notes", // no sessions directory
+		"archive/2025/workspaces/old/agents/amos/sessions",
+	} {
+		if err := os.MkdirAll(filepath.Join(tempDir, filepath.FromSlash(dir)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		discovery []string
+		filter    Filter
+		want      []string
+	}{
+		{"default flat layout", nil, Filter{}, []string{"urza"}},
+		{"nested in workspaces", []string{"*", "workspaces/*/agents/*"}, Filter{}, []string{"urza", "acme/pepper", "acme/pepper-test", "globex/urza"}},
+		{"recursive", []string{"**/agents/*"}, Filter{}, []string{"archive/2025/workspaces/old/amos", "workspaces/acme/pepper", "workspaces/acme/pepper-test", "workspaces/globex/urza"}},
+		{"filter by directory name", []string{"workspaces/*/agents/*"}, Filter{ExcludeAgents: []string{"*-test"}}, []string{"acme/pepper", "globex/urza"}},
+		{"filter by full name", []string{"workspaces/*/agents/*"}, Filter{Agents: []string{"globex/*"}}, []string{"globex/urza"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(tempDir)
+			p.Discovery = tt.discovery
+			p.Filter = tt.filter
+			agents, err := p.ListAgents()
+			if err != nil {
+				t.Fatalf("ListAgents failed: %v", err)
+			}
+			if !reflect.DeepEqual(agents, tt.want) {
+				t.Errorf("ListAgents() = %v, want %v", agents, tt.want)
+			}
+		})
+	}
+
+	// Nested agents are parsed from their own directories.
+	transcript := `{"type":"message","timestamp":"2026-10-15T10:00:00Z","message":{"role":"assistant","usage":{"totalTokens":10,"cost":{"total":0.1}}}}`
+	sessionsDir := filepath.Join(tempDir, "workspaces", "globex", "agents", "urza", "sessions")
+	if err := os.WriteFile(filepath.Join(sessionsDir, "main.jsonl"), []byte(transcript), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p := New(tempDir)
+	p.Discovery = []string{"workspaces/*/agents/*"}
+	sessions, err := p.ParseAll("globex/urza")
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Agent != "globex/urza" || sessions[0].Workspace != "globex" {
+		t.Errorf("expected one session for globex/urza in workspace globex, got %+v", sessions)
+	}
+}
+
+func TestValidateDiscovery(t *testing.T) {
+	for _, pattern := range []string{"*", "workspaces/*/agents/*", "**/agents/*"} {
+		if err := ValidateDiscovery([]string{pattern}); err != nil {
+			t.Errorf("ValidateDiscovery(%q) = %v", pattern, err)
+		}
+	}
+	for _, pattern := range []string{"", "/abs/*", "../agents/*", `ws\*`, "ws/[/agents"} {
+		if err := ValidateDiscovery([]string{pattern}); err == nil {
+			t.Errorf("ValidateDiscovery(%q) should fail", pattern)
+		}
+	}
+}
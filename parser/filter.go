@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"fmt"
+	"path"
+)
+
+// Filter selects the agents and transcripts a Parser reads, by glob in
+// path.Match syntax, so test harnesses and experiments stay out of
+// reports. Agents match on their name or directory name and transcripts
+// on their file name without .jsonl. Empty include lists select everything;
+// excludes win over includes.
+type Filter struct {
+	Agents          []string
+	ExcludeAgents   []string
+	Sessions        []string
+	ExcludeSessions []string
+}
+
+// Validate checks that every pattern is well-formed.
+func (f Filter) Validate() error {
+	for _, patterns := range [][]string{f.Agents, f.ExcludeAgents, f.Sessions, f.ExcludeSessions} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid filter pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Agent reports whether the agent is selected, by its name or, for agents
+// discovered in nested layouts (acme/urza), by its directory name alone.
+func (f Filter) Agent(name string) bool {
+	return selects(f.Agents, f.ExcludeAgents, name, path.Base(name))
+}
+
+// Session reports whether the transcript named sessionID (its file name
+// without .jsonl) is selected.
+func (f Filter) Session(sessionID string) bool {
+	return selects(f.Sessions, f.ExcludeSessions, sessionID)
+}
+
+// selects reports whether any of names is included and none excluded.
+func selects(include, exclude []string, names ...string) bool {
+	if matchAny(exclude, names) {
+		return false
+	}
+	return len(include) == 0 || matchAny(include, names)
+}
+
+// matchAny reports whether any name matches any pattern. Malformed
+// patterns match nothing; see Validate.
+func matchAny(patterns, names []string) bool {
+	for _, pattern := range patterns {
+		for _, name := range names {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
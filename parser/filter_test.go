@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	f := Filter{
+		Agents:          []string{"urza*", "pepper"},
+		ExcludeAgents:   []string{"*-test"},
+		ExcludeSessions: []string{"scratch-*"},
+	}
+
+	agents := map[string]bool{
+		"urza":      true,
+		"urza-prod": true,
+		"urza-test": false,
+		"pepper":    true,
+		"amos":      false,
+	}
+	for name, want := range agents {
+		if got := f.Agent(name); got != want {
+			t.Errorf("Agent(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	sessions := map[string]bool{
+		"main":             true,
+		"scratch-1":        false,
+		"cron:nightly:run": true,
+	}
+	for id, want := range sessions {
+		if got := f.Session(id); got != want {
+			t.Errorf("Session(%q) = %v, want %v", id, got, want)
+		}
+	}
+
+	if !(Filter{}).Agent("anything") || !(Filter{}).Session("anything") {
+		t.Error("empty filter should select everything")
+	}
+	if err := f.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if err := (Filter{ExcludeSessions: []string{"scratch-["}}).Validate(); err == nil {
+		t.Error("expected malformed pattern to be rejected")
+	}
+}
+
+func TestParseAllFilter(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, agent := range []string{"urza", "urza-test"} {
+		if err := os.MkdirAll(filepath.Join(tempDir, agent, "sessions"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Unreadable transcripts prove filtered files are never opened.
+	for _, name := range []string{"urza/sessions/main.jsonl", "urza/sessions/scratch-1.jsonl", "urza-test/sessions/main.jsonl"} {
+		if err := os.Symlink(filepath.Join(tempDir, "missing"), filepath.Join(tempDir, name)); err != nil {
+			t.Skip("symlinks unsupported:", err)
+		}
+	}
+
+	p := New(tempDir)
+	p.Filter = Filter{ExcludeAgents: []string{"*-test"}, ExcludeSessions: []string{"scratch-*"}}
+	agents, err := p.ListAgents()
+	if err != nil {
+		t.Fatalf("ListAgents failed: %v", err)
+	}
+	if len(agents) != 1 || agents[0] != "urza" {
+		t.Errorf("expected only urza, got %v", agents)
+	}
+	if _, err := p.ParseAll(""); err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	if len(p.Errors()) != 1 {
+		t.Errorf("expected only urza's main transcript to be opened, got %v", p.Errors())
+	}
+}
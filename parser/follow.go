@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Follower reads the assistant turns appended to a transcript that is
+// still being written, keeping a running total of their usage.
+type Follower struct {
+	// Usage is the total usage of the turns read so far.
+	Usage Usage
+	// Turns is the number of assistant turns read so far.
+	Turns int
+
+	path    string
+	offset  int64
+	partial []byte
+}
+
+// NewFollower returns a Follower that starts at the beginning of the
+// transcript at path.
+func NewFollower(path string) *Follower {
+	return &Follower{path: path}
+}
+
+// Poll returns the assistant turns completed since the last call, oldest
+// first, and adds them to the running totals. A trailing line that is
+// still being written is kept for the next call. A transcript that shrank
+// was rewritten, so it is read again from the start with fresh totals.
+func (f *Follower) Poll() ([]Message, error) {
+	turns, _, err := f.poll(0)
+	return turns, err
+}
+
+// poll is Poll reading at most limit bytes, or everything appended when
+// limit is zero, and also returns the number of bytes read. The
+// transcript is only opened once it has grown, so polling an idle one
+// costs a stat.
+func (f *Follower) poll(limit int64) ([]Message, int64, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if info.Size() < f.offset {
+		*f = Follower{path: f.path}
+	}
+	if info.Size() == f.offset {
+		return nil, 0, nil
+	}
+	n := info.Size() - f.offset
+	if limit > 0 && n > limit {
+		n = limit
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(f.offset, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+	data, err := io.ReadAll(io.LimitReader(file, n))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s: %w", f.path, err)
+	}
+	read := int64(len(data))
+	f.offset += read
+	data = append(f.partial, data...)
+
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		f.partial = data
+		return nil, read, nil
+	}
+	f.partial = append([]byte(nil), data[end+1:]...)
+
+	var turns []Message
+	for _, line := range bytes.Split(data[:end], []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var msg Message
+		if err := json.Unmarshal(line, &msg); err != nil {
+			continue
+		}
+		if msg.Type != "message" || msg.Message.Role != "assistant" {
+			continue
+		}
+		if msg.Message.Model == "" {
+			msg.Message.Model = msg.Model
+		}
+		f.add(msg)
+		turns = append(turns, msg)
+	}
+	return turns, read, nil
+}
+
+func (f *Follower) add(msg Message) {
+	u := msg.Message.Usage
+	f.Turns++
+	f.Usage.Input += u.Input
+	f.Usage.Output += u.Output
+	f.Usage.Total += u.Total
+	f.Usage.CacheRead += u.CacheRead
+	f.Usage.CacheWrite += u.CacheWrite
+	f.Usage.CostInput += u.Cost.Input
+	f.Usage.CostOutput += u.Cost.Output
+	f.Usage.CostTotal += u.Cost.Total
+	if msg.Message.Model != "" {
+		f.Usage.Model = msg.Message.Model
+	}
+}
@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFollower(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.jsonl")
+	turn := `{"type":"message","timestamp":"2026-10-15T10:00:00Z","message":{"role":"assistant","usage":{"input":100,"output":20,"totalTokens":120,"cost":{"total":0.5}},"model":"claude-opus-4-6"}}`
+	user := `{"type":"message","message":{"role":"user"}}`
+	if err := os.WriteFile(path, []byte(`{"type":"session","timestamp":"2026-10-15T09:59:00Z"}`+"\n"+turn+"\n"+user+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewFollower(path)
+	turns, err := f.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(turns) != 1 || f.Turns != 1 || f.Usage.CostTotal != 0.5 || f.Usage.Model != "claude-opus-4-6" {
+		t.Fatalf("expected one turn costing $0.50, got %d turns, %+v", len(turns), f.Usage)
+	}
+
+	// A turn still being written is held back until its line is complete.
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	file.WriteString(turn[:40])
+	if turns, err := f.Poll(); err != nil || len(turns) != 0 {
+		t.Fatalf("expected no complete turns, got %d (%v)", len(turns), err)
+	}
+	file.WriteString(turn[40:] + "\n")
+	if turns, err := f.Poll(); err != nil || len(turns) != 1 {
+		t.Fatalf("expected the completed turn, got %d (%v)", len(turns), err)
+	}
+	if f.Turns != 2 || f.Usage.CostTotal != 1 || f.Usage.Total != 240 {
+		t.Errorf("expected running totals of 2 turns and $1, got %d turns, %+v", f.Turns, f.Usage)
+	}
+
+	// A rewritten, shorter transcript starts over.
+	if err := os.WriteFile(path, []byte(turn+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if turns, err := f.Poll(); err != nil || len(turns) != 1 || f.Turns != 1 {
+		t.Errorf("expected totals to restart, got %d turns (%v)", f.Turns, err)
+	}
+}
@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func FuzzParseSessionKey(f *testing.F) {
+	for _, seed := range []string{
+		"agent:urza",
+		"agent:urza:cron:daily-kickoff-abc123:run:sid",
+		"agent:urza:subagent:sid",
+		"agent::cron::run:",
+		"agent:urza:cron:a:run:b:run:c",
+		"",
+		":::::",
+		"agent:\x00:cron:\xff:run:x",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, key string) {
+		var s Session
+		s.parseSessionKey(key)
+		switch s.Type {
+		case SessionTypeInteractive, SessionTypeSubagent:
+		case SessionTypeCron:
+			if s.CronID == "" {
+				t.Errorf("cron session without cron ID for %q", key)
+			}
+		default:
+			t.Errorf("unexpected session type %q for %q", s.Type, key)
+		}
+	})
+}
+
+func FuzzParseSessionFile(f *testing.F) {
+	corpus, err := os.ReadFile(filepath.Join("testdata", "malformed.jsonl"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(corpus)
+	for _, seed := range []string{
+		"",
+		"\n\n\n",
+		`{"type":"message","message":{"role":"assistant","usage":{"input":-5,"cacheRead":-1,"output":-3}}}`,
+		`{"type":"message","message":{"role":"assistant","usage":{"input":9223372036854775807,"output":1}}}`,
+		`{"type":"message","message":{"role":"user","content":[{"type":"text","text":"hi"}]}}` + "\n" +
+			`{"type":"message","message":{"role":"assistant","content":[{"type":"thinking","thinking":"x"}],"usage":{"input":1}}}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "s.jsonl")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		// Must not panic, and must always return the session's identity.
+		session, _ := New(dir).parseSessionFile("urza", "s", path)
+		if session.ID != "s" || session.Agent != "urza" || session.FilePath != path {
+			t.Errorf("expected a partial session, got %+v", session)
+		}
+		if len(session.Messages) > 0 && session.Usage.Model == "" && session.Messages[0].Message.Model != "" {
+			t.Errorf("model not tracked")
+		}
+	})
+}
+
+func TestParseSessionFileMalformedCorpus(t *testing.T) {
+	path := filepath.Join("testdata", "malformed.jsonl")
+	session, err := New("").parseSessionFile("urza", "malformed", path)
+	if err != nil {
+		t.Fatalf("parseSessionFile failed: %v", err)
+	}
+	if len(session.Messages) != 2 {
+		t.Errorf("expected 2 messages, got %d", len(session.Messages))
+	}
+	if session.Usage.Total != 450 {
+		t.Errorf("expected 450 total tokens, got %d", session.Usage.Total)
+	}
+	if session.SkippedLines != 6 {
+		t.Errorf("expected 6 skipped lines, got %d", session.SkippedLines)
+	}
+}
+
+func TestParseFilesKeepsPartialSession(t *testing.T) {
+	good := `{"type":"message","timestamp":"2026-02-10T16:53:15.420Z","message":{"role":"assistant","usage":{"totalTokens":10,"cost":{"total":0.01}}}}`
+	// A line past the scanner's 10MB limit stops parsing.
+	huge := `{"type":"message","message":{"role":"assistant","content":[{"type":"text","text":"` +
+		strings.Repeat("x", 11*1024*1024) + `"}]}}`
+	path := filepath.Join(t.TempDir(), "s.jsonl")
+	if err := os.WriteFile(path, []byte(good+"\n"+huge+"\n"+good), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := New("")
+	sessions := p.ParseFiles([]string{path}, "")
+	if len(sessions) != 1 {
+		t.Fatalf("expected the partial session, got %d sessions", len(sessions))
+	}
+	if sessions[0].Usage.Total != 10 {
+		t.Errorf("expected tokens read before the failure, got %d", sessions[0].Usage.Total)
+	}
+	if want := time.Date(2026, 2, 10, 16, 53, 15, 420000000, time.UTC); !sessions[0].StartedAt.Equal(want) || !sessions[0].EndedAt.Equal(want) {
+		t.Errorf("expected the partial session to start and end at %s, got %s to %s", want, sessions[0].StartedAt, sessions[0].EndedAt)
+	}
+	if len(p.Errors()) != 1 || !strings.Contains(p.Errors()[0].Error(), "line 2") {
+		t.Errorf("expected 1 error naming line 2, got %v", p.Errors())
+	}
+}
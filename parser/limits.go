@@ -0,0 +1,32 @@
+package parser
+
+import "runtime"
+
+// openFileHeadroom is the number of descriptors left free for everything
+// else the process holds open: stdio, the config file, history stores and
+// the directory being listed.
+const openFileHeadroom = 32
+
+// dirBatchSize is how many directory entries are read at a time, so huge
+// sessions directories are listed without one giant allocation.
+const dirBatchSize = 1024
+
+// parseWorkers returns how many transcripts may be parsed concurrently.
+// Each worker holds at most one transcript open, so the count is bounded by
+// the process's open-file limit as well as the number of CPUs.
+func parseWorkers() int {
+	return workersFor(openFileLimit(), runtime.NumCPU())
+}
+
+// workersFor sizes the worker pool for an open-file limit (0 if unknown)
+// and CPU count.
+func workersFor(limit, cpus int) int {
+	n := 2 * cpus
+	if limit > 0 && limit-openFileHeadroom < n {
+		n = limit - openFileHeadroom
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
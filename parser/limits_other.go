@@ -0,0 +1,8 @@
+//go:build !unix
+
+package parser
+
+// openFileLimit returns 0: there is no RLIMIT_NOFILE to respect here.
+func openFileLimit() int {
+	return 0
+}
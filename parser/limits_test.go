@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorkersFor(t *testing.T) {
+	tests := []struct {
+		limit, cpus, want int
+	}{
+		{0, 8, 16},     // unknown limit: CPU bound
+		{10240, 8, 16}, // generous limit: CPU bound
+		{48, 8, 16},    // 48 - headroom = 16
+		{40, 8, 8},     // limit binds
+		{16, 8, 1},     // never below one worker
+	}
+	for _, tt := range tests {
+		if got := workersFor(tt.limit, tt.cpus); got != tt.want {
+			t.Errorf("workersFor(%d, %d) = %d, want %d", tt.limit, tt.cpus, got, tt.want)
+		}
+	}
+}
+
+func TestParseAllManyFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	sessionsDir := filepath.Join(tempDir, "urza", "sessions")
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// More files than one directory batch, to exercise batched listing.
+	n := dirBatchSize + 100
+	for i := 0; i < n; i++ {
+		line := fmt.Sprintf(`{"type":"message","timestamp":"2026-02-10T16:53:15.420Z","message":{"role":"assistant","usage":{"totalTokens":%d,"cost":{"total":0.01}}}}`, i)
+		name := filepath.Join(sessionsDir, fmt.Sprintf("s%05d.jsonl", i))
+		if err := os.WriteFile(name, []byte(line), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := New(tempDir)
+	sessions, err := p.ParseAll("")
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	if len(sessions) != n {
+		t.Fatalf("expected %d sessions, got %d", n, len(sessions))
+	}
+	for i, s := range sessions {
+		if s.ID != fmt.Sprintf("s%05d", i) || s.Usage.Total != i {
+			t.Fatalf("session %d out of order: %s with %d tokens", i, s.ID, s.Usage.Total)
+		}
+	}
+}
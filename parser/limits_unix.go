@@ -0,0 +1,20 @@
+//go:build unix
+
+package parser
+
+import (
+	"math"
+	"syscall"
+)
+
+// openFileLimit returns the soft RLIMIT_NOFILE, or 0 if it cannot be read.
+func openFileLimit() int {
+	var rl syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rl); err != nil {
+		return 0
+	}
+	if rl.Cur > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int(rl.Cur)
+}
@@ -3,13 +3,18 @@ package parser
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -25,8 +30,10 @@ type Message struct {
 	Message   struct {
 		Role    string `json:"role"`
 		Content []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
+			Type      string          `json:"type"`
+			Text      string          `json:"text"`
+			Thinking  string          `json:"thinking"`
+			Arguments json.RawMessage `json:"arguments"`
 		} `json:"content"`
 		Usage struct {
 			Input      int `json:"input"`
@@ -42,22 +49,84 @@ type Message struct {
 				Total      float64 `json:"total"`
 			} `json:"cost"`
 		} `json:"usage"`
-		Model string `json:"model"`
+		Model        string `json:"model"`
+		StopReason   string `json:"stopReason"`
+		ErrorMessage string `json:"errorMessage"`
 	} `json:"message"`
 	Model string `json:"model"`
 }
 
+// Failed reports whether the message records a model request that failed.
+func (m *Message) Failed() bool {
+	return m.Message.StopReason == "error" || m.Message.ErrorMessage != ""
+}
+
+// Kinds of failed requests.
+const (
+	ErrorRateLimit  = "rate_limit"
+	ErrorOverloaded = "overloaded"
+	ErrorServer     = "server"
+	ErrorOther      = "other"
+)
+
+// RequestError is a model request that failed, e.g. on a rate limit or an
+// overloaded provider. Providers may still bill the tokens streamed before
+// the failure, so its cost is included in the session's usage.
+type RequestError struct {
+	Timestamp time.Time
+	Model     string
+	Kind      string // one of the Error* kinds
+	Message   string
+	Cost      float64
+	Tokens    int
+	// Retried is set when another request followed in the session.
+	Retried bool
+}
+
+// ModelSwitch is a change of model between requests of a session, e.g. a
+// provider falling back to another model or an agent escalating to a
+// stronger one. Its usage is that of the requests made on To, up to the
+// next switch.
+type ModelSwitch struct {
+	Timestamp  time.Time
+	From       string
+	To         string
+	Requests   int
+	Input      int
+	Output     int
+	CacheRead  int
+	CacheWrite int
+	Cost       float64
+}
+
+var serverErrorPattern = regexp.MustCompile(`\b5\d\d\b`)
+
+// classifyError derives the kind of a failed request from its message.
+func classifyError(message string) string {
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "429") || strings.Contains(lower, "rate limit") || strings.Contains(lower, "rate_limit"):
+		return ErrorRateLimit
+	case strings.Contains(lower, "529") || strings.Contains(lower, "overloaded"):
+		return ErrorOverloaded
+	case serverErrorPattern.MatchString(lower):
+		return ErrorServer
+	default:
+		return ErrorOther
+	}
+}
+
 // Usage contains token and cost information.
 type Usage struct {
-	Input       int
-	Output      int
-	Total       int
-	CacheRead   int
-	CacheWrite  int
-	CostInput   float64
-	CostOutput  float64
-	CostTotal   float64
-	Model       string
+	Input      int
+	Output     int
+	Total      int
+	CacheRead  int
+	CacheWrite int
+	CostInput  float64
+	CostOutput float64
+	CostTotal  float64
+	Model      string
 }
 
 // SessionType categorizes the session.
@@ -73,6 +142,8 @@ const (
 type Session struct {
 	ID         string
 	Agent      string
+	Tenant     string // Set when agents roots are configured per tenant
+	Workspace  string // Set for agents nested in workspaces (see WorkspaceOf)
 	Type       SessionType
 	CronID     string // For cron sessions
 	CronName   string // For cron sessions (derived from cron ID)
@@ -80,13 +151,150 @@ type Session struct {
 	FilePath   string
 	Messages   []Message
 	Usage      Usage
-	StartedAt  time.Time
-	Duration   time.Duration
+	// StartedAt is the transcript's session header timestamp, or else its
+	// first assistant message's; EndedAt is its last assistant message's.
+	// Duration is the time between them.
+	StartedAt time.Time
+	EndedAt   time.Time
+	Duration  time.Duration
+	// TokensByRole estimates how the session's tokens split across message
+	// roles (see Roles). It is nil for sessions rebuilt from aggregates.
+	TokensByRole map[string]int
+	// Title is the session's first user prompt on one line, cut to
+	// maxTitleLength runes. Topic rules match against it.
+	Title string
+	// SkippedLines is the number of non-blank transcript lines that could
+	// not be decoded and were left out of Messages and Usage.
+	SkippedLines int
+	// Errors are the session's failed model requests, oldest first.
+	Errors []RequestError
+	// Count is the number of sessions this value stands for. It is zero
+	// for sessions parsed from transcripts and set when sessions are rebuilt
+	// from stored daily aggregates.
+	Count int
+	// Estimated is set when the session reported no cost and Usage was
+	// priced at configured self-hosted rates instead.
+	Estimated bool
+	// MessageCount is the number of messages of a session kept without
+	// them, such as one read back from a Record. Use Turns.
+	MessageCount int
+	// Switches are the session's changes of model, oldest first.
+	Switches []ModelSwitch
+	// ConfiguredModel is the default model configured for the session's
+	// agent directory (see Parser.DefaultModel), when known.
+	ConfiguredModel string
+	// SpendCap is the spend limit configured for the session in its agent
+	// directory (see Parser.SpendCaps); 0 for none.
+	SpendCap float64
+}
+
+// Weight returns the number of real sessions s represents.
+func (s *Session) Weight() int {
+	if s.Count > 0 {
+		return s.Count
+	}
+	return 1
+}
+
+// Turns returns the number of messages in the session, whether or not
+// Messages were kept.
+func (s *Session) Turns() int {
+	if len(s.Messages) > 0 {
+		return len(s.Messages)
+	}
+	return s.MessageCount
 }
 
 // Parser handles parsing of session files.
 type Parser struct {
+	// AsOf, when set, ignores transcript lines timestamped after it (or not
+	// timestamped at all) and sessions with no lines before it, so a past
+	// report can be reproduced after transcripts have grown. The session
+	// index is not consulted, as its timestamps move on.
+	AsOf time.Time
+	// Since, when set, skips transcripts without opening them if their
+	// session index entry or date-prefixed file name shows that the report
+	// would drop them as starting before it.
+	Since time.Time
+	// Progress, when set, is told of each parsed transcript and receives
+	// the warnings otherwise printed to stderr.
+	Progress Progress
+	// Filter leaves agents and transcripts out of ListAgents, ParseAll and
+	// ListCronNames. Explicitly listed files (ParseFiles) are read anyway.
+	Filter Filter
+	// Deadline, when set, stops parsing once passed: transcripts not yet
+	// started are left unread and counted in Coverage.
+	Deadline time.Time
+	// Discovery lists the globs, relative to the agents directory, that
+	// locate agent directories, e.g. workspaces/*/agents/* for agents
+	// nested in workspaces (see ValidateDiscovery). Empty means
+	// DefaultDiscovery.
+	Discovery []string
+	// Cache, when set, supplies the sessions of transcripts unchanged since
+	// they were cached instead of reading them again, and receives those
+	// parsed. It is not used with AsOf.
+	Cache *Cache
+	// DropMessages releases each session's Messages as soon as its
+	// transcript is parsed, keeping only their count (see Session.Turns),
+	// for callers that only need totals: parsing then holds the messages
+	// of one transcript per worker instead of every transcript's.
+	DropMessages bool
+
 	agentsDir string
+	agentDirs map[string]string // by agent name, set by ListAgents
+	errors    []error
+	coverage  map[string]Coverage
+
+	progressMu    sync.Mutex
+	progressDir   string
+	parsed, total int
+}
+
+// maxLineSize is the longest transcript line read (10MB).
+const maxLineSize = 10 * 1024 * 1024
+
+// errAfterAsOf marks a transcript with nothing to report as of Parser.AsOf.
+var errAfterAsOf = errors.New("session starts after the as-of time")
+
+// errDeadline marks a transcript left unread because Parser.Deadline passed.
+var errDeadline = errors.New("parsing deadline passed")
+
+// ErrClockSkew marks a transcript whose timestamps cannot be trusted, as
+// written by a host with a wrong clock: a line dated in the future, or one
+// dated well before a line preceding it. Such sessions are excluded rather
+// than counted on whatever days their timestamps claim.
+var ErrClockSkew = errors.New("clock skew")
+
+const (
+	// maxClockAhead is how far past the current time, or Parser.AsOf, a
+	// line may be dated before its clock is considered wrong.
+	maxClockAhead = 5 * time.Minute
+	// maxClockStep is how far before the latest line so far a line may be
+	// dated. Small steps back are normal when hosts resync their clocks.
+	maxClockStep = time.Hour
+)
+
+// Coverage counts the transcripts of an agent that were due to be read and
+// those parsed before Parser.Deadline.
+type Coverage struct {
+	Transcripts int
+	Parsed      int
+}
+
+// Coverage returns the transcripts due and parsed so far, by agent.
+// Transcripts skipped as before Parser.Since are not counted.
+func (p *Parser) Coverage() map[string]Coverage {
+	return p.coverage
+}
+
+// Partial reports whether the deadline left any transcript unread.
+func (p *Parser) Partial() bool {
+	for _, c := range p.coverage {
+		if c.Parsed < c.Transcripts {
+			return true
+		}
+	}
+	return false
 }
 
 // New creates a new Parser.
@@ -94,111 +302,406 @@ func New(agentsDir string) *Parser {
 	return &Parser{agentsDir: agentsDir}
 }
 
-// ListAgents returns a list of available agents.
+// ListAgents returns the agents found by p.Discovery: directories with a
+// sessions subdirectory, named as described for discover.
 func (p *Parser) ListAgents() ([]string, error) {
-	entries, err := os.ReadDir(p.agentsDir)
+	found, err := p.discover()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read agents directory: %w", err)
+		return nil, err
 	}
 
-	var agents []string
-	for _, entry := range entries {
-		if entry.IsDir() {
-			// Check if it has a sessions directory
-			sessionsDir := filepath.Join(p.agentsDir, entry.Name(), "sessions")
-			if _, err := os.Stat(sessionsDir); err == nil {
-				agents = append(agents, entry.Name())
+	agents := make([]string, 0, len(found))
+	p.agentDirs = make(map[string]string, len(found))
+	for _, d := range found {
+		agents = append(agents, d.name)
+		p.agentDirs[d.name] = d.dir
+	}
+	return agents, nil
+}
+
+// AgentDir returns the directory of an agent listed by ListAgents. Other
+// agents are assumed to be directly in the agents directory.
+func (p *Parser) AgentDir(agent string) string {
+	if dir, ok := p.agentDirs[agent]; ok {
+		return dir
+	}
+	return filepath.Join(p.agentsDir, agent)
+}
+
+// ListCronNames returns the sorted cron names found for all agents or a
+// specific agent. Names come from transcript file names and session index
+// keys only, so no transcript is read.
+func (p *Parser) ListCronNames(agentFilter string) ([]string, error) {
+	agents, err := p.ListAgents()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, agent := range agents {
+		if agentFilter != "" && agent != agentFilter {
+			continue
+		}
+
+		sessionsDir := filepath.Join(p.AgentDir(agent), "sessions")
+		var keys []string
+		for key := range readSessionIndex(sessionsDir) {
+			keys = append(keys, key)
+		}
+		entries, err := os.ReadDir(sessionsDir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".jsonl") {
+				keys = append(keys, strings.TrimSuffix(entry.Name(), ".jsonl"))
+			}
+		}
+
+		for _, key := range keys {
+			var s Session
+			s.parseSessionKey(key)
+			if s.Type == SessionTypeCron {
+				seen[s.CronName] = true
 			}
 		}
 	}
 
-	return agents, nil
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Errors returns the errors for agents and session files that were skipped
+// because they could not be parsed.
+func (p *Parser) Errors() []error {
+	return p.errors
 }
 
 // ParseAll parses all sessions for all agents or a specific agent.
 func (p *Parser) ParseAll(agentFilter string) ([]Session, error) {
-	var sessions []Session
-
 	agents, err := p.ListAgents()
 	if err != nil {
 		return nil, err
 	}
 
+	// Transcripts are listed up front so progress can be reported against
+	// the total.
+	type agentJobs struct {
+		index map[string]SessionIndexEntry
+		jobs  []parseJob
+	}
+	var batches []agentJobs
+	total := 0
 	for _, agent := range agents {
 		if agentFilter != "" && agent != agentFilter {
 			continue
 		}
 
-		agentSessions, err := p.parseAgentSessions(agent)
+		index, jobs, err := p.agentJobs(agent)
 		if err != nil {
 			// Log error but continue with other agents
-			fmt.Fprintf(os.Stderr, "Warning: failed to parse sessions for agent %s: %v\n", agent, err)
+			p.warn(fmt.Errorf("agent %s: %w", agent, err), "failed to parse sessions for agent %s: %v", agent, err)
 			continue
 		}
-
-		sessions = append(sessions, agentSessions...)
+		batches = append(batches, agentJobs{index: index, jobs: jobs})
+		total += len(jobs)
 	}
 
+	var sessions []Session
+	p.startProgress(p.agentsDir, total)
+	for _, batch := range batches {
+		sessions = append(sessions, p.collect(p.parseJobs(batch.jobs), func(string) map[string]SessionIndexEntry { return batch.index })...)
+	}
 	return sessions, nil
 }
 
-// parseAgentSessions parses all sessions for a specific agent.
-func (p *Parser) parseAgentSessions(agent string) ([]Session, error) {
-	sessionsDir := filepath.Join(p.agentsDir, agent, "sessions")
+// agentJobs lists the transcripts of an agent to parse, along with its
+// session index.
+func (p *Parser) agentJobs(agent string) (map[string]SessionIndexEntry, []parseJob, error) {
+	sessionsDir := filepath.Join(p.AgentDir(agent), "sessions")
 
 	// Read session index if available
-	indexPath := filepath.Join(sessionsDir, "sessions.json")
-	sessionIndex := make(map[string]SessionIndexEntry)
-	if data, err := os.ReadFile(indexPath); err == nil {
-		var index map[string]interface{}
-		if err := json.Unmarshal(data, &index); err == nil {
-			for key, val := range index {
-				if entryMap, ok := val.(map[string]interface{}); ok {
-					entry := SessionIndexEntry{Key: key}
-					if id, ok := entryMap["sessionId"].(string); ok {
-						entry.SessionID = id
-					}
-					if ts, ok := entryMap["updatedAt"].(float64); ok {
-						entry.UpdatedAt = int64(ts)
-					}
-					sessionIndex[key] = entry
-				}
-			}
-		}
-	}
+	sessionIndex := readSessionIndex(sessionsDir)
 
-	entries, err := os.ReadDir(sessionsDir)
+	names, err := listTranscripts(sessionsDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read sessions directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to read sessions directory: %w", err)
+	}
+
+	jobs := make([]parseJob, 0, len(names))
+	for _, name := range names {
+		sessionID := strings.TrimSuffix(name, ".jsonl")
+		if !p.Filter.Session(sessionID) || p.beforeSince(agent, sessionID, sessionIndex) {
+			continue
+		}
+		jobs = append(jobs, parseJob{
+			agent:     agent,
+			sessionID: sessionID,
+			path:      filepath.Join(sessionsDir, name),
+		})
 	}
+	return sessionIndex, jobs, nil
+}
 
+// collect gathers parsed sessions, recording failures. Sessions whose
+// transcripts carry no timestamps take their times from the session index
+// of the transcript's directory.
+func (p *Parser) collect(results []parseResult, index func(dir string) map[string]SessionIndexEntry) []Session {
 	var sessions []Session
-	for _, entry := range entries {
-		if entry.IsDir() {
+	if p.coverage == nil {
+		p.coverage = make(map[string]Coverage)
+	}
+	for _, r := range results {
+		session, err := r.session, r.err
+		c := p.coverage[r.agent]
+		c.Transcripts++
+		if !errors.Is(err, errDeadline) {
+			c.Parsed++
+		}
+		p.coverage[r.agent] = c
+		if errors.Is(err, errAfterAsOf) || errors.Is(err, errDeadline) {
 			continue
 		}
-		if !strings.HasSuffix(entry.Name(), ".jsonl") {
+		if errors.Is(err, ErrClockSkew) {
+			p.warn(fmt.Errorf("session %s: %w", r.path, err), "excluded session %s: %v", r.path, err)
 			continue
 		}
-
-		sessionID := strings.TrimSuffix(entry.Name(), ".jsonl")
-		filePath := filepath.Join(sessionsDir, entry.Name())
-
-		session, err := p.parseSessionFile(agent, sessionID, filePath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to parse session %s: %v\n", filePath, err)
-			continue
+			p.warn(fmt.Errorf("session %s: %w", r.path, err), "failed to parse session %s: %v", r.path, err)
+			// Keep whatever was read before the failure
+			if session.Turns() == 0 {
+				continue
+			}
 		}
 
-		// Try to get additional metadata from index
-		if indexEntry, ok := sessionIndex[session.Key()]; ok {
-			session.StartedAt = time.UnixMilli(indexEntry.UpdatedAt)
+		if session.StartedAt.IsZero() && p.AsOf.IsZero() {
+			if indexEntry, ok := index(filepath.Dir(r.path))[session.Key()]; ok && indexEntry.UpdatedAt > 0 {
+				session.StartedAt = time.UnixMilli(indexEntry.UpdatedAt)
+				session.EndedAt = session.StartedAt
+			}
 		}
 
 		sessions = append(sessions, session)
 	}
+	return sessions
+}
 
-	return sessions, nil
+// fileDateSlack is how long after the date in its file name a session may
+// still record its first message.
+const fileDateSlack = 48 * time.Hour
+
+// beforeSince reports whether a transcript can be skipped unopened because
+// it is known to start before p.Since. An index entry decides by its last
+// update, which no session starts after, but only for cron and subagent
+// keys: every interactive transcript of an agent shares the agent:{name}
+// key, so its entry says nothing about any one of them. Otherwise a date
+// prefix on the session ID (2026-10-15 or 20261015) bounds the first
+// message.
+func (p *Parser) beforeSince(agent, sessionID string, index map[string]SessionIndexEntry) bool {
+	if p.Since.IsZero() {
+		return false
+	}
+
+	s := Session{ID: sessionID, Agent: agent}
+	s.parseSessionKey(sessionID)
+	if entry, ok := index[s.Key()]; ok && s.Type != SessionTypeInteractive && p.AsOf.IsZero() {
+		return !time.UnixMilli(entry.UpdatedAt).After(p.Since)
+	}
+	if day, ok := fileDate(sessionID); ok {
+		return !day.Add(fileDateSlack).After(p.Since)
+	}
+	return false
+}
+
+// fileDate returns the date a session ID starts with. For session keys
+// (agent:...:run:{sid}) the last segment is checked.
+func fileDate(sessionID string) (time.Time, bool) {
+	if i := strings.LastIndex(sessionID, ":"); i >= 0 {
+		sessionID = sessionID[i+1:]
+	}
+	m := datePrefixPattern.FindStringSubmatch(sessionID)
+	if m == nil {
+		return time.Time{}, false
+	}
+	day, err := time.Parse("20060102", m[1]+m[2]+m[3])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day, true
+}
+
+// listTranscripts returns the sorted .jsonl file names in dir. Entries are
+// read in batches of dirBatchSize.
+func listTranscripts(dir string) ([]string, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var names []string
+	for {
+		entries, err := f.ReadDir(dirBatchSize)
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".jsonl") {
+				names = append(names, entry.Name())
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// parseJob is a transcript to parse; parseResult is its outcome.
+type parseJob struct {
+	agent, sessionID, path string
+}
+
+type parseResult struct {
+	parseJob
+	session Session
+	err     error
+}
+
+// parseJobs parses transcripts concurrently and returns the results in job
+// order. At most parseWorkers files are open at once, which keeps large
+// directories within the open-file limit. Once p.Deadline passes, the
+// remaining jobs fail with errDeadline unread.
+func (p *Parser) parseJobs(jobs []parseJob) []parseResult {
+	results := make([]parseResult, len(jobs))
+	workers := parseWorkers()
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				job := jobs[i]
+				if !p.Deadline.IsZero() && !time.Now().Before(p.Deadline) {
+					results[i] = parseResult{parseJob: job, err: errDeadline}
+					p.advanceProgress()
+					continue
+				}
+				session, err := p.parse(job)
+				if p.DropMessages {
+					session.MessageCount = session.Turns()
+					session.Messages = nil
+				}
+				results[i] = parseResult{parseJob: job, session: session, err: err}
+				p.advanceProgress()
+			}
+		}()
+	}
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return results
+}
+
+// parse parses a job's transcript, or takes its session from p.Cache if
+// the transcript is unchanged since it was cached. Only sessions parsed
+// without error are cached.
+func (p *Parser) parse(job parseJob) (Session, error) {
+	if p.Cache == nil || !p.AsOf.IsZero() {
+		return p.parseSessionFile(job.agent, job.sessionID, job.path)
+	}
+	info, err := os.Stat(job.path)
+	if err != nil {
+		return p.parseSessionFile(job.agent, job.sessionID, job.path)
+	}
+	if session, ok := p.Cache.Lookup(job.path, info); ok {
+		// The same transcript may be reached as another agent, e.g. by
+		// ParseFiles outside its agents directory.
+		session.Agent = job.agent
+		session.Workspace = WorkspaceOf(job.agent)
+		return session, nil
+	}
+	session, err := p.parseSessionFile(job.agent, job.sessionID, job.path)
+	if err == nil {
+		p.Cache.Put(job.path, info, session)
+	}
+	return session, err
+}
+
+// ParseFiles parses an explicit list of session transcripts, bypassing the
+// agents directory layout. The agent is taken from the conventional
+// {agent}/sessions/{id}.jsonl location and is "unknown" for files stored
+// elsewhere. Files that cannot be parsed are recorded in Errors and skipped
+// unless some of their messages were read before the failure.
+func (p *Parser) ParseFiles(paths []string, agentFilter string) []Session {
+	indexes := make(map[string]map[string]SessionIndexEntry)
+
+	var jobs []parseJob
+	for _, path := range paths {
+		dir := filepath.Dir(path)
+		agent := "unknown"
+		if filepath.Base(dir) == "sessions" {
+			agent = filepath.Base(filepath.Dir(dir))
+		}
+		if agentFilter != "" && agent != agentFilter {
+			continue
+		}
+		if _, ok := indexes[dir]; !ok {
+			indexes[dir] = readSessionIndex(dir)
+		}
+		sessionID := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+		if p.beforeSince(agent, sessionID, indexes[dir]) {
+			continue
+		}
+		jobs = append(jobs, parseJob{
+			agent:     agent,
+			sessionID: sessionID,
+			path:      path,
+		})
+	}
+
+	p.startProgress("", len(jobs))
+	return p.collect(p.parseJobs(jobs), func(dir string) map[string]SessionIndexEntry { return indexes[dir] })
+}
+
+// readSessionIndex reads sessions.json from a sessions directory. A missing
+// or malformed index yields an empty map.
+func readSessionIndex(sessionsDir string) map[string]SessionIndexEntry {
+	sessionIndex := make(map[string]SessionIndexEntry)
+	data, err := os.ReadFile(filepath.Join(sessionsDir, "sessions.json"))
+	if err != nil {
+		return sessionIndex
+	}
+	var index map[string]interface{}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return sessionIndex
+	}
+	for key, val := range index {
+		if entryMap, ok := val.(map[string]interface{}); ok {
+			entry := SessionIndexEntry{Key: key}
+			if id, ok := entryMap["sessionId"].(string); ok {
+				entry.SessionID = id
+			}
+			if ts, ok := entryMap["updatedAt"].(float64); ok {
+				entry.UpdatedAt = int64(ts)
+			}
+			sessionIndex[key] = entry
+		}
+	}
+	return sessionIndex
 }
 
 // SessionIndexEntry represents an entry in sessions.json.
@@ -208,49 +711,141 @@ type SessionIndexEntry struct {
 	UpdatedAt int64
 }
 
-// parseSessionFile parses a single session file.
-func (p *Parser) parseSessionFile(agent, sessionID, filePath string) (Session, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return Session{}, err
+// maxTitleLength caps Session.Title, keeping cached sessions small.
+const maxTitleLength = 200
+
+// promptTitle returns the text of a user message on one line, cut to
+// maxTitleLength runes.
+func promptTitle(msg Message) string {
+	var parts []string
+	for _, c := range msg.Message.Content {
+		if c.Type == "text" {
+			parts = append(parts, c.Text)
+		}
 	}
-	defer file.Close()
+	title := strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+	if runes := []rune(title); len(runes) > maxTitleLength {
+		title = string(runes[:maxTitleLength])
+	}
+	return title
+}
 
-	session := Session{
-		ID:       sessionID,
-		Agent:    agent,
-		FilePath: filePath,
-		Messages: []Message{},
+// parseSessionFile parses a single session file. It never panics: on any
+// failure it returns the session as read so far, which always carries its
+// identity, together with an error describing where parsing stopped.
+// Undecodable lines are not errors; they are counted in SkippedLines.
+func (p *Parser) parseSessionFile(agent, sessionID, filePath string) (session Session, err error) {
+	session = Session{
+		ID:        sessionID,
+		Agent:     agent,
+		Workspace: WorkspaceOf(agent),
+		FilePath:  filePath,
+		Messages:  []Message{},
 	}
 
+	line := 0
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic at line %d: %v", line, r)
+		}
+	}()
+
 	// Parse session type from session ID format
 	session.parseSessionKey(sessionID)
 
+	file, err := os.Open(filePath)
+	if err != nil {
+		return session, err
+	}
+	defer file.Close()
+
 	scanner := bufio.NewScanner(file)
-	// Increase buffer size to handle long lines (up to 10MB)
-	const maxCapacity = 10 * 1024 * 1024 // 10MB
+	// Increase buffer size to handle long lines
 	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, maxCapacity)
+	scanner.Buffer(buf, maxLineSize)
+
+	var headerTimestamp, firstTimestamp, lastTimestamp, latest time.Time
+	var beforeAsOf bool
+	roles := newRoleTracker()
+	now := p.AsOf
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	// Times and role shares are set however parsing ends, so a session
+	// kept after a failure still falls into its period.
+	defer func() {
+		if len(roles.tokens) > 0 {
+			session.TokensByRole = roles.tokens
+		}
 
-	var firstTimestamp, lastTimestamp time.Time
+		session.StartedAt = headerTimestamp
+		if session.StartedAt.IsZero() || (!firstTimestamp.IsZero() && firstTimestamp.Before(session.StartedAt)) {
+			session.StartedAt = firstTimestamp
+		}
+		session.EndedAt = lastTimestamp
+		if session.EndedAt.Before(session.StartedAt) {
+			session.EndedAt = session.StartedAt
+		}
+		session.Duration = session.EndedAt.Sub(session.StartedAt)
+	}()
 
 	for scanner.Scan() {
+		line++
+		data := scanner.Bytes()
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+
 		var msg Message
-		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+		if err := json.Unmarshal(data, &msg); err != nil {
 			// Skip malformed lines
+			session.SkippedLines++
 			continue
 		}
 
+		if !p.AsOf.IsZero() {
+			if msg.Timestamp.IsZero() || msg.Timestamp.After(p.AsOf) {
+				continue
+			}
+			beforeAsOf = true
+		}
+
+		if ts := msg.Timestamp; !ts.IsZero() {
+			if ahead := ts.Sub(now); ahead > maxClockAhead {
+				return session, fmt.Errorf("%w: line %d is dated %s, %s in the future", ErrClockSkew, line, ts.Format(time.RFC3339), ahead.Round(time.Second))
+			}
+			if back := latest.Sub(ts); back > maxClockStep {
+				return session, fmt.Errorf("%w: line %d is dated %s, %s before an earlier line", ErrClockSkew, line, ts.Format(time.RFC3339), back.Round(time.Second))
+			}
+			if ts.After(latest) {
+				latest = ts
+			}
+		}
+
+		if msg.Type == "session" && headerTimestamp.IsZero() {
+			headerTimestamp = msg.Timestamp
+		}
+		if msg.Type == "message" {
+			roles.add(msg)
+			if msg.Message.Role == "user" && session.Title == "" {
+				session.Title = promptTitle(msg)
+			}
+		}
+
 		// Only process assistant messages with usage
 		if msg.Type == "message" && msg.Message.Role == "assistant" {
 			session.Messages = append(session.Messages, msg)
 
-			// Track timestamps
+			// Track timestamps. Lines are not always in order, e.g. when
+			// clocks step backwards, so keep the earliest and latest.
 			if !msg.Timestamp.IsZero() {
-				if firstTimestamp.IsZero() {
+				if firstTimestamp.IsZero() || msg.Timestamp.Before(firstTimestamp) {
 					firstTimestamp = msg.Timestamp
 				}
-				lastTimestamp = msg.Timestamp
+				if msg.Timestamp.After(lastTimestamp) {
+					lastTimestamp = msg.Timestamp
+				}
 			}
 
 			// Aggregate usage
@@ -264,21 +859,49 @@ func (p *Parser) parseSessionFile(agent, sessionID, filePath string) (Session, e
 			session.Usage.CostTotal += msg.Message.Usage.Cost.Total
 
 			// Track model
-			if msg.Message.Model != "" {
-				session.Usage.Model = msg.Message.Model
-			} else if msg.Model != "" {
-				session.Usage.Model = msg.Model
+			model := msg.Message.Model
+			if model == "" {
+				model = msg.Model
+			}
+			if model != "" {
+				if previous := session.Usage.Model; previous != "" && previous != model {
+					session.Switches = append(session.Switches, ModelSwitch{Timestamp: msg.Timestamp, From: previous, To: model})
+				}
+				session.Usage.Model = model
+			}
+			if n := len(session.Switches); n > 0 {
+				sw := &session.Switches[n-1]
+				u := msg.Message.Usage
+				sw.Requests++
+				sw.Input += u.Input
+				sw.Output += u.Output
+				sw.CacheRead += u.CacheRead
+				sw.CacheWrite += u.CacheWrite
+				sw.Cost += u.Cost.Total
+			}
+
+			// A failure is retried if any request follows it.
+			if n := len(session.Errors); n > 0 {
+				session.Errors[n-1].Retried = true
+			}
+			if msg.Failed() {
+				session.Errors = append(session.Errors, RequestError{
+					Timestamp: msg.Timestamp,
+					Model:     model,
+					Kind:      classifyError(msg.Message.ErrorMessage),
+					Message:   msg.Message.ErrorMessage,
+					Cost:      msg.Message.Usage.Cost.Total,
+					Tokens:    msg.Message.Usage.Total,
+				})
 			}
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return session, err
+		return session, fmt.Errorf("line %d: %w", line+1, err)
 	}
-
-	if !firstTimestamp.IsZero() && !lastTimestamp.IsZero() {
-		session.StartedAt = firstTimestamp
-		session.Duration = lastTimestamp.Sub(firstTimestamp)
+	if !p.AsOf.IsZero() && !beforeAsOf {
+		return session, errAfterAsOf
 	}
 
 	return session, nil
@@ -301,6 +924,7 @@ var (
 	cronPattern        = regexp.MustCompile(`^agent:([^:]+):cron:([^:]+):run:(.+)$`)
 	subagentPattern    = regexp.MustCompile(`^agent:([^:]+):subagent:(.+)$`)
 	interactivePattern = regexp.MustCompile(`^agent:([^:]+)$`)
+	datePrefixPattern  = regexp.MustCompile(`^(\d{4})-?(\d{2})-?(\d{2})(?:[T_.-]|$)`)
 )
 
 // parseSessionKey parses the session key to extract metadata.
@@ -1,9 +1,11 @@
 package parser
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestListAgents(t *testing.T) {
@@ -88,6 +90,7 @@ func TestParseSessionFile(t *testing.T) {
 
 	// Create test session file
 	sessionContent := `{"type":"session","version":3,"id":"test-session","timestamp":"2026-02-10T16:53:15.416Z"}
+{"type":"message","id":"msg0","timestamp":"2026-02-10T16:53:15.418Z","message":{"role":"user","content":[{"type":"text","text":"Review\n  PR #12"}]}}
 {"type":"message","id":"msg1","timestamp":"2026-02-10T16:53:15.420Z","message":{"role":"assistant","content":[{"type":"text","text":"Hello"}],"usage":{"input":100,"output":50,"totalTokens":150,"cost":{"input":0.0005,"output":0.00075,"total":0.00125}},"model":"moonshotai/kimi-k2.5"}}
 {"type":"message","id":"msg2","timestamp":"2026-02-10T16:54:00.000Z","message":{"role":"assistant","content":[{"type":"text","text":"World"}],"usage":{"input":200,"output":100,"totalTokens":300,"cost":{"input":0.001,"output":0.0015,"total":0.0025}},"model":"moonshotai/kimi-k2.5"}}`
 
@@ -116,6 +119,142 @@ func TestParseSessionFile(t *testing.T) {
 	if session.Usage.Total != expectedTokens {
 		t.Errorf("expected total tokens %d, got %d", expectedTokens, session.Usage.Total)
 	}
+
+	// The session header starts the session, to the millisecond.
+	startedAt := time.Date(2026, 2, 10, 16, 53, 15, 416000000, time.UTC)
+	endedAt := time.Date(2026, 2, 10, 16, 54, 0, 0, time.UTC)
+	if !session.StartedAt.Equal(startedAt) || !session.EndedAt.Equal(endedAt) {
+		t.Errorf("expected %v to %v, got %v to %v", startedAt, endedAt, session.StartedAt, session.EndedAt)
+	}
+	if session.Duration != endedAt.Sub(startedAt) {
+		t.Errorf("expected duration %v, got %v", endedAt.Sub(startedAt), session.Duration)
+	}
+	if session.Title != "Review PR #12" {
+		t.Errorf("expected the first prompt on one line as the title, got %q", session.Title)
+	}
+}
+
+func TestParseSessionTimes(t *testing.T) {
+	tempDir := t.TempDir()
+	sessionsDir := filepath.Join(tempDir, "urza", "sessions")
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// No header, and messages out of order: the earliest and latest win.
+	outOfOrder := `{"type":"message","timestamp":"2026-02-10T17:00:00Z","message":{"role":"assistant","usage":{"totalTokens":10}}}
+{"type":"message","timestamp":"2026-02-10T16:00:00Z","message":{"role":"assistant","usage":{"totalTokens":10}}}
+{"type":"message","timestamp":"2026-02-10T16:30:00Z","message":{"role":"assistant","usage":{"totalTokens":10}}}`
+	// No timestamps at all: the session index supplies the time.
+	untimed := `{"type":"message","message":{"role":"assistant","usage":{"totalTokens":10}}}`
+	files := map[string]string{
+		"r1.jsonl":   outOfOrder,
+		"main.jsonl": untimed,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(sessionsDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	index := `{"agent:urza": {"updatedAt": 1770739200000}}`
+	if err := os.WriteFile(filepath.Join(sessionsDir, "sessions.json"), []byte(index), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := New(tempDir).ParseAll("")
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	byID := make(map[string]Session)
+	for _, s := range sessions {
+		byID[s.ID] = s
+	}
+
+	r1 := byID["r1"]
+	if !r1.StartedAt.Equal(time.Date(2026, 2, 10, 16, 0, 0, 0, time.UTC)) || r1.Duration != time.Hour {
+		t.Errorf("expected r1 to start at 16:00 and last an hour, got %v for %v", r1.StartedAt, r1.Duration)
+	}
+	main := byID["main"]
+	if !main.StartedAt.Equal(time.UnixMilli(1770739200000)) || !main.EndedAt.Equal(main.StartedAt) {
+		t.Errorf("expected main to take its time from the index, got %v to %v", main.StartedAt, main.EndedAt)
+	}
+}
+
+func TestParseSessionFileRequestErrors(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sessionContent := `{"type":"message","timestamp":"2026-02-10T16:53:00Z","message":{"role":"assistant","content":[],"stopReason":"error","errorMessage":"429 rate_limit_error: too many requests","usage":{"totalTokens":0,"cost":{"total":0}},"model":"anthropic/claude-sonnet-4-5"}}
+{"type":"message","timestamp":"2026-02-10T16:53:10Z","message":{"role":"assistant","content":[{"type":"text","text":"Hel"}],"stopReason":"error","errorMessage":"529 Overloaded","usage":{"totalTokens":900,"cost":{"total":0.004}},"model":"anthropic/claude-sonnet-4-5"}}
+{"type":"message","timestamp":"2026-02-10T16:53:30Z","message":{"role":"assistant","content":[{"type":"text","text":"Hello"}],"stopReason":"stop","usage":{"totalTokens":1000,"cost":{"total":0.005}},"model":"anthropic/claude-sonnet-4-5"}}
+{"type":"message","timestamp":"2026-02-10T16:54:00Z","message":{"role":"assistant","content":[],"stopReason":"error","errorMessage":"502 Bad Gateway","usage":{"totalTokens":0,"cost":{"total":0}},"model":"anthropic/claude-sonnet-4-5"}}`
+
+	sessionFile := filepath.Join(tempDir, "test-session.jsonl")
+	if err := os.WriteFile(sessionFile, []byte(sessionContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := New(tempDir)
+	session, err := p.parseSessionFile("urza", "test-session", sessionFile)
+	if err != nil {
+		t.Fatalf("parseSessionFile failed: %v", err)
+	}
+
+	if len(session.Errors) != 3 {
+		t.Fatalf("expected 3 request errors, got %+v", session.Errors)
+	}
+	wantKinds := []string{ErrorRateLimit, ErrorOverloaded, ErrorServer}
+	wantRetried := []bool{true, true, false}
+	for i, e := range session.Errors {
+		if e.Kind != wantKinds[i] || e.Retried != wantRetried[i] || e.Model != "anthropic/claude-sonnet-4-5" {
+			t.Errorf("error %d: unexpected %+v", i, e)
+		}
+	}
+	if session.Errors[1].Cost != 0.004 || session.Errors[1].Tokens != 900 {
+		t.Errorf("expected the partially streamed failure to carry its cost, got %+v", session.Errors[1])
+	}
+	// Billed failures count toward the session's usage.
+	if session.Usage.Total != 1900 {
+		t.Errorf("expected 1900 total tokens, got %d", session.Usage.Total)
+	}
+}
+
+func TestParseSessionFileModelSwitches(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sessionContent := `{"type":"message","timestamp":"2026-02-10T16:53:00Z","message":{"role":"assistant","content":[],"usage":{"input":100,"output":10,"totalTokens":110,"cost":{"total":0.001}},"model":"anthropic/claude-haiku-4-5"}}
+{"type":"message","timestamp":"2026-02-10T16:53:10Z","message":{"role":"assistant","content":[],"usage":{"input":200,"output":20,"totalTokens":220,"cost":{"total":0.002}},"model":"anthropic/claude-sonnet-4-5"}}
+{"type":"message","timestamp":"2026-02-10T16:53:20Z","message":{"role":"assistant","content":[],"usage":{"input":300,"output":30,"cacheRead":50,"totalTokens":380,"cost":{"total":0.003}}}}
+{"type":"message","timestamp":"2026-02-10T16:53:30Z","message":{"role":"assistant","content":[],"usage":{"input":400,"output":40,"totalTokens":440,"cost":{"total":0.02}},"model":"anthropic/claude-opus-4-6"}}`
+
+	sessionFile := filepath.Join(tempDir, "test-session.jsonl")
+	if err := os.WriteFile(sessionFile, []byte(sessionContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := New(tempDir)
+	session, err := p.parseSessionFile("urza", "test-session", sessionFile)
+	if err != nil {
+		t.Fatalf("parseSessionFile failed: %v", err)
+	}
+
+	if len(session.Switches) != 2 {
+		t.Fatalf("expected 2 model switches, got %+v", session.Switches)
+	}
+	// Requests without a model stay with the one before them.
+	sonnet := session.Switches[0]
+	if sonnet.From != "anthropic/claude-haiku-4-5" || sonnet.To != "anthropic/claude-sonnet-4-5" || sonnet.Requests != 2 {
+		t.Errorf("unexpected first switch: %+v", sonnet)
+	}
+	if sonnet.Input != 500 || sonnet.Output != 50 || sonnet.CacheRead != 50 || sonnet.Cost != 0.005 {
+		t.Errorf("expected the first switch to carry both requests' usage, got %+v", sonnet)
+	}
+	opus := session.Switches[1]
+	if opus.From != "anthropic/claude-sonnet-4-5" || opus.To != "anthropic/claude-opus-4-6" || opus.Requests != 1 || opus.Cost != 0.02 {
+		t.Errorf("unexpected second switch: %+v", opus)
+	}
+	if !opus.Timestamp.Equal(time.Date(2026, 2, 10, 16, 53, 30, 0, time.UTC)) {
+		t.Errorf("expected the switch dated at its first request, got %v", opus.Timestamp)
+	}
 }
 
 func TestDeriveCronName(t *testing.T) {
@@ -210,3 +349,325 @@ func TestSessionKey(t *testing.T) {
 		})
 	}
 }
+
+func TestParseAllRecordsErrors(t *testing.T) {
+	tempDir := t.TempDir()
+	sessionsDir := filepath.Join(tempDir, "urza", "sessions")
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	good := `{"type":"message","timestamp":"2026-02-10T16:53:15.420Z","message":{"role":"assistant","usage":{"totalTokens":10,"cost":{"total":0.01}}}}`
+	if err := os.WriteFile(filepath.Join(sessionsDir, "good.jsonl"), []byte(good), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A dangling symlink is listed as a transcript but cannot be opened.
+	if err := os.Symlink(filepath.Join(tempDir, "missing"), filepath.Join(sessionsDir, "dangling.jsonl")); err != nil {
+		t.Skip("symlinks unsupported:", err)
+	}
+
+	p := New(tempDir)
+	sessions, err := p.ParseAll("")
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Errorf("expected 1 parsed session, got %d", len(sessions))
+	}
+	if len(p.Errors()) != 1 {
+		t.Errorf("expected 1 recorded error, got %v", p.Errors())
+	}
+}
+
+func TestParseAllClockSkew(t *testing.T) {
+	tempDir := t.TempDir()
+	sessionsDir := filepath.Join(tempDir, "urza", "sessions")
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	line := func(ts string) string {
+		return `{"type":"message","timestamp":"` + ts + `","message":{"role":"assistant","usage":{"totalTokens":10,"cost":{"total":0.01}}}}` + "\n"
+	}
+	future := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+	files := map[string]string{
+		"good.jsonl":    line("2026-02-10T16:00:00Z") + line("2026-02-10T15:30:00Z"),
+		"future.jsonl":  line("2026-02-10T16:00:00Z") + line(future),
+		"stepped.jsonl": line("2026-02-10T16:00:00Z") + line("2026-02-09T16:00:00Z"),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(sessionsDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := New(tempDir)
+	sessions, err := p.ParseAll("")
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != "good" {
+		t.Errorf("expected only the good session, got %+v", sessions)
+	}
+	if len(p.Errors()) != 2 {
+		t.Fatalf("expected 2 recorded errors, got %v", p.Errors())
+	}
+	for _, err := range p.Errors() {
+		if !errors.Is(err, ErrClockSkew) {
+			t.Errorf("expected a clock skew error, got %v", err)
+		}
+	}
+}
+
+func TestParseFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	sessionsDir := filepath.Join(tempDir, "urza", "sessions")
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	content := `{"type":"message","timestamp":"2026-02-10T16:53:15.420Z","message":{"role":"assistant","usage":{"totalTokens":10,"cost":{"total":0.01}}}}`
+	inLayout := filepath.Join(sessionsDir, "a.jsonl")
+	loose := filepath.Join(tempDir, "b.jsonl")
+	for _, path := range []string{inLayout, loose} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := New("")
+	sessions := p.ParseFiles([]string{inLayout, loose, filepath.Join(tempDir, "missing.jsonl")}, "")
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	if sessions[0].Agent != "urza" || sessions[1].Agent != "unknown" {
+		t.Errorf("unexpected agents: %q, %q", sessions[0].Agent, sessions[1].Agent)
+	}
+	if len(p.Errors()) != 1 {
+		t.Errorf("expected 1 recorded error, got %v", p.Errors())
+	}
+
+	if got := New("").ParseFiles([]string{inLayout, loose}, "urza"); len(got) != 1 {
+		t.Errorf("expected agent filter to keep 1 session, got %d", len(got))
+	}
+}
+
+func TestParseAllAsOf(t *testing.T) {
+	tempDir := t.TempDir()
+	sessionsDir := filepath.Join(tempDir, "urza", "sessions")
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	early := `{"type":"message","timestamp":"2026-06-30T10:00:00Z","message":{"role":"assistant","usage":{"totalTokens":10,"cost":{"total":0.01}}}}`
+	late := `{"type":"message","timestamp":"2026-07-01T10:00:00Z","message":{"role":"assistant","usage":{"totalTokens":20,"cost":{"total":0.02}}}}`
+	files := map[string]string{
+		"grown.jsonl": early + "\n" + late,
+		"new.jsonl":   late,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(sessionsDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := New(tempDir)
+	p.AsOf = time.Date(2026, 6, 30, 23, 59, 0, 0, time.UTC)
+	sessions, err := p.ParseAll("")
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != "grown" {
+		t.Fatalf("expected only the grown session, got %+v", sessions)
+	}
+	if sessions[0].Usage.Total != 10 || len(sessions[0].Messages) != 1 {
+		t.Errorf("expected lines after as-of to be ignored, got %+v", sessions[0].Usage)
+	}
+	if len(p.Errors()) != 0 {
+		t.Errorf("expected no errors, got %v", p.Errors())
+	}
+}
+
+func TestFileDate(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"2026-10-15T08-30-00-abc", "2026-10-15"},
+		{"20261015_abc", "2026-10-15"},
+		{"2026-10-15", "2026-10-15"},
+		{"agent:urza:cron:daily-kickoff-a1b2c3:run:20261015-x", "2026-10-15"},
+		{"1732xzb5bskb", ""},
+		{"20261015abc", ""},
+		{"20261399-x", ""},
+	}
+	for _, tt := range tests {
+		day, ok := fileDate(tt.id)
+		got := ""
+		if ok {
+			got = day.Format("2006-01-02")
+		}
+		if got != tt.want {
+			t.Errorf("fileDate(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestParseAllSince(t *testing.T) {
+	tempDir := t.TempDir()
+	sessionsDir := filepath.Join(tempDir, "urza", "sessions")
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unreadable transcripts prove skipped files are never opened.
+	for _, name := range []string{"2026-09-01-old.jsonl", "2026-10-15-recent.jsonl", "undated.jsonl"} {
+		if err := os.Symlink(filepath.Join(tempDir, "missing"), filepath.Join(sessionsDir, name)); err != nil {
+			t.Skip("symlinks unsupported:", err)
+		}
+	}
+
+	p := New(tempDir)
+	p.Since = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := p.ParseAll(""); err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	if len(p.Errors()) != 2 {
+		t.Errorf("expected the recent and undated transcripts to be opened, got %v", p.Errors())
+	}
+
+	// The agent's interactive index entry is shared by all its
+	// transcripts, so a stale one skips none of them.
+	index := `{"agent:urza": {"sessionId": "main", "updatedAt": 1756684800000}}`
+	if err := os.WriteFile(filepath.Join(sessionsDir, "sessions.json"), []byte(index), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p = New(tempDir)
+	p.Since = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := p.ParseAll(""); err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	if len(p.Errors()) != 2 {
+		t.Errorf("expected the recent and undated transcripts to be opened, got %v", p.Errors())
+	}
+}
+
+func TestParseAllSinceSharedIndexKey(t *testing.T) {
+	tempDir := t.TempDir()
+	sessionsDir := filepath.Join(tempDir, "amos", "sessions")
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"sessions.json": `{"agent:amos": {"sessionId": "a", "updatedAt": 1700000000000}}`,
+		"a.jsonl":       `{"type":"message","timestamp":"2026-10-14T16:00:00Z","message":{"role":"assistant","usage":{"totalTokens":10}}}`,
+		"b.jsonl":       `{"type":"message","timestamp":"2026-10-15T16:00:00Z","message":{"role":"assistant","usage":{"totalTokens":20}}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(sessionsDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := New(tempDir)
+	p.Since = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	sessions, err := p.ParseAll("")
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Errorf("expected both interactive transcripts despite the stale index entry, got %d", len(sessions))
+	}
+}
+
+func TestParseAllDropMessages(t *testing.T) {
+	tempDir := t.TempDir()
+	sessionsDir := filepath.Join(tempDir, "urza", "sessions")
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	line := `{"type":"message","timestamp":"2026-02-10T16:00:00Z","message":{"role":"assistant","usage":{"totalTokens":10}}}` + "\n"
+	if err := os.WriteFile(filepath.Join(sessionsDir, "a.jsonl"), []byte(line+line+line), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := New(tempDir)
+	p.DropMessages = true
+	sessions, err := p.ParseAll("")
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Messages != nil || sessions[0].Turns() != 3 || sessions[0].Usage.Total != 30 {
+		t.Errorf("expected 3 turns and their totals without messages, got %+v", sessions)
+	}
+}
+
+func TestParseAllDeadline(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, agent := range []string{"urza", "amos"} {
+		sessionsDir := filepath.Join(tempDir, agent, "sessions")
+		if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"a.jsonl", "b.jsonl"} {
+			content := `{"type":"message","timestamp":"2026-02-10T16:00:00Z","message":{"role":"assistant","usage":{"totalTokens":10}}}`
+			if err := os.WriteFile(filepath.Join(sessionsDir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	p := New(tempDir)
+	if _, err := p.ParseAll(""); err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	if p.Partial() || p.Coverage()["urza"] != (Coverage{Transcripts: 2, Parsed: 2}) {
+		t.Errorf("expected full coverage without a deadline, got %v", p.Coverage())
+	}
+
+	p = New(tempDir)
+	p.Deadline = time.Now().Add(-time.Second)
+	sessions, err := p.ParseAll("")
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	if len(sessions) != 0 || len(p.Errors()) != 0 {
+		t.Errorf("expected nothing read past the deadline, got %d sessions and %v", len(sessions), p.Errors())
+	}
+	if !p.Partial() || p.Coverage()["amos"] != (Coverage{Transcripts: 2}) {
+		t.Errorf("expected amos unread, got %v", p.Coverage())
+	}
+}
+
+func TestListCronNames(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, agent := range []string{"urza", "amos"} {
+		if err := os.MkdirAll(filepath.Join(tempDir, agent, "sessions"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		"urza/sessions/agent:urza:cron:daily-kickoff-abc123:run:a.jsonl": "",
+		"urza/sessions/agent:urza:cron:daily-kickoff-abc123:run:b.jsonl": "",
+		"urza/sessions/agent:urza.jsonl":                                 "",
+		"amos/sessions/sessions.json":                                    `{"agent:amos:cron:code-reviewer-xyz789:run:c":{"sessionId":"c"}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := New(tempDir)
+	names, err := p.ListCronNames("")
+	if err != nil {
+		t.Fatalf("ListCronNames failed: %v", err)
+	}
+	if len(names) != 2 || names[0] != "code-reviewer" || names[1] != "daily-kickoff" {
+		t.Errorf("unexpected cron names: %v", names)
+	}
+
+	if names, _ := p.ListCronNames("urza"); len(names) != 1 || names[0] != "daily-kickoff" {
+		t.Errorf("expected agent filter to keep daily-kickoff, got %v", names)
+	}
+}
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// DefaultAgentsDir returns the default OpenClaw agents directory,
+// ~/.openclaw/agents (%USERPROFILE%\.openclaw\agents on Windows).
+func DefaultAgentsDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".openclaw", "agents"), nil
+}
+
+// ResolveAgentsDir turns a user-supplied --agents-dir value into a usable
+// path, falling back to DefaultAgentsDir when empty.
+func ResolveAgentsDir(dir string) (string, error) {
+	if dir == "" {
+		return DefaultAgentsDir()
+	}
+	return ExpandPath(dir)
+}
+
+// ExpandPath expands a leading ~ and environment variable references
+// ($VAR on Unix, %VAR% on Windows) and normalizes path separators for the
+// current platform.
+func ExpandPath(path string) (string, error) {
+	var home string
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		h, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		home = h
+	}
+	return expandPath(path, home, os.Getenv, runtime.GOOS == "windows"), nil
+}
+
+var windowsEnvPattern = regexp.MustCompile(`%([^%]+)%`)
+
+// expandPath implements ExpandPath with the platform inputs injected so
+// Windows semantics can be tested on any OS.
+func expandPath(path, home string, getenv func(string) string, windows bool) string {
+	if windows {
+		// Like cmd.exe, leave unknown %VAR% references untouched.
+		path = windowsEnvPattern.ReplaceAllStringFunc(path, func(ref string) string {
+			if val := getenv(ref[1 : len(ref)-1]); val != "" {
+				return val
+			}
+			return ref
+		})
+	} else {
+		path = os.Expand(path, getenv)
+	}
+
+	switch {
+	case path == "~":
+		path = home
+	case strings.HasPrefix(path, "~/"):
+		path = home + path[1:]
+	case windows && strings.HasPrefix(path, `~\`):
+		path = home + path[1:]
+	}
+
+	if windows {
+		path = strings.ReplaceAll(path, "/", `\`)
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandPath(t *testing.T) {
+	env := map[string]string{
+		"USERPROFILE": `C:\Users\urza`,
+		"APPDATA":     `C:\Users\urza\AppData\Roaming`,
+		"OPENCLAW":    "/srv/openclaw",
+	}
+	getenv := func(key string) string { return env[key] }
+
+	tests := []struct {
+		name     string
+		path     string
+		home     string
+		windows  bool
+		expected string
+	}{
+		{"unix tilde", "~/.openclaw/agents", "/home/urza", false, "/home/urza/.openclaw/agents"},
+		{"unix bare tilde", "~", "/home/urza", false, "/home/urza"},
+		{"unix env var", "$OPENCLAW/agents", "", false, "/srv/openclaw/agents"},
+		{"unix braced env var", "${OPENCLAW}/agents/", "", false, "/srv/openclaw/agents"},
+		{"unix absolute", "/var/lib/openclaw//agents", "", false, "/var/lib/openclaw/agents"},
+		{"unix tilde in middle untouched", "/data/~backup", "/home/urza", false, "/data/~backup"},
+		{"windows userprofile", `%USERPROFILE%\.openclaw\agents`, "", true, `C:\Users\urza\.openclaw\agents`},
+		{"windows forward slashes", "C:/Users/urza/.openclaw/agents", "", true, `C:\Users\urza\.openclaw\agents`},
+		{"windows mixed separators", `%APPDATA%/openclaw\agents`, "", true, `C:\Users\urza\AppData\Roaming\openclaw\agents`},
+		{"windows tilde backslash", `~\.openclaw\agents`, `C:\Users\urza`, true, `C:\Users\urza\.openclaw\agents`},
+		{"windows tilde slash", "~/.openclaw/agents", `C:\Users\urza`, true, `C:\Users\urza\.openclaw\agents`},
+		{"windows unknown var kept", `%NOPE%\agents`, "", true, `%NOPE%\agents`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := expandPath(tt.path, tt.home, getenv, tt.windows)
+			if result != tt.expected {
+				t.Errorf("expandPath(%q) = %q, want %q", tt.path, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveAgentsDirDefault(t *testing.T) {
+	t.Setenv("HOME", "/home/urza")
+	t.Setenv("USERPROFILE", "/home/urza")
+
+	dir, err := ResolveAgentsDir("")
+	if err != nil {
+		t.Fatalf("ResolveAgentsDir failed: %v", err)
+	}
+	if dir != filepath.Join("/home/urza", ".openclaw", "agents") {
+		t.Errorf("ResolveAgentsDir(\"\") = %q", dir)
+	}
+}
@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+)
+
+// Progress receives a Parser's progress. Parsed is called once per
+// transcript, from the goroutines parsing them but never concurrently, with
+// the count parsed so far out of total in the current ParseAll or
+// ParseFiles call over dir ("" for ParseFiles). Warn receives the problems
+// that are otherwise printed to stderr as warnings.
+type Progress interface {
+	Parsed(dir string, parsed, total int)
+	Warn(err error)
+}
+
+// startProgress resets the progress count for a parse of total transcripts.
+func (p *Parser) startProgress(dir string, total int) {
+	p.progressMu.Lock()
+	defer p.progressMu.Unlock()
+	p.progressDir, p.parsed, p.total = dir, 0, total
+}
+
+// advanceProgress counts a parsed transcript.
+func (p *Parser) advanceProgress() {
+	if p.Progress == nil {
+		return
+	}
+	p.progressMu.Lock()
+	defer p.progressMu.Unlock()
+	p.parsed++
+	p.Progress.Parsed(p.progressDir, p.parsed, p.total)
+}
+
+// warn records err as skipped and reports the problem: to Progress when
+// set, otherwise as a warning on stderr.
+func (p *Parser) warn(err error, format string, args ...any) {
+	p.errors = append(p.errors, err)
+	if p.Progress != nil {
+		p.Progress.Warn(fmt.Errorf(format, args...))
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Warning: "+format+"\n", args...)
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingProgress records the progress a Parser reports.
+type recordingProgress struct {
+	mu       sync.Mutex
+	parsed   []int
+	total    int
+	warnings []string
+}
+
+func (r *recordingProgress) Parsed(dir string, parsed, total int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.parsed = append(r.parsed, parsed)
+	r.total = total
+}
+
+func (r *recordingProgress) Warn(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.warnings = append(r.warnings, err.Error())
+}
+
+func TestParseAllProgress(t *testing.T) {
+	dir := t.TempDir()
+	for _, agent := range []string{"amos", "urza"} {
+		sessionsDir := filepath.Join(dir, agent, "sessions")
+		if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"s1", "s2", "s3"} {
+			if err := os.WriteFile(filepath.Join(sessionsDir, name+".jsonl"), []byte(cacheTranscript), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// A dangling symlink is listed as a transcript but cannot be opened.
+	if err := os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "urza", "sessions", "dangling.jsonl")); err != nil {
+		t.Skip("symlinks unsupported:", err)
+	}
+
+	progress := &recordingProgress{}
+	p := New(dir)
+	p.Progress = progress
+	if _, err := p.ParseAll(""); err != nil {
+		t.Fatal(err)
+	}
+
+	if progress.total != 7 || len(progress.parsed) != 7 {
+		t.Fatalf("expected 7 transcripts counted against the total, got %v of %d", progress.parsed, progress.total)
+	}
+	for i, parsed := range progress.parsed {
+		if parsed != i+1 {
+			t.Fatalf("expected counts in order, got %v", progress.parsed)
+		}
+	}
+	if len(progress.warnings) != 1 || !strings.Contains(progress.warnings[0], "dangling.jsonl") || len(p.Errors()) != 1 {
+		t.Errorf("expected the dangling transcript reported once, got %v and %v", progress.warnings, p.Errors())
+	}
+}
@@ -0,0 +1,210 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// RecordVersion is the version of the session record format written by
+// WriteRecords. Readers reject records of other versions rather than
+// misreading them, except that version 1 records, which predate Switches,
+// are read without them.
+const RecordVersion = 2
+
+// SupportedRecordVersion reports whether records of version v can be read.
+func SupportedRecordVersion(v int) bool {
+	return v >= 1 && v <= RecordVersion
+}
+
+// maxRecordLine bounds one record line, covering sessions with many
+// failed requests.
+const maxRecordLine = 16 << 20
+
+// Record is a parsed session as exported for reporting elsewhere: on
+// another host, or long after its transcript has rotated away. It holds
+// everything reports use; message content is not kept, only the number of
+// messages.
+type Record struct {
+	Version      int            `json:"v"`
+	ID           string         `json:"id,omitempty"`
+	Agent        string         `json:"agent"`
+	Tenant       string         `json:"tenant,omitempty"`
+	Workspace    string         `json:"workspace,omitempty"`
+	Type         SessionType    `json:"type"`
+	CronID       string         `json:"cron_id,omitempty"`
+	CronName     string         `json:"cron_name,omitempty"`
+	SubagentID   string         `json:"subagent_id,omitempty"`
+	FilePath     string         `json:"file,omitempty"`
+	Model        string         `json:"model,omitempty"`
+	Input        int            `json:"input"`
+	Output       int            `json:"output"`
+	Total        int            `json:"total"`
+	CacheRead    int            `json:"cache_read,omitempty"`
+	CacheWrite   int            `json:"cache_write,omitempty"`
+	CostInput    float64        `json:"cost_input"`
+	CostOutput   float64        `json:"cost_output"`
+	CostTotal    float64        `json:"cost"`
+	StartedAt    time.Time      `json:"started_at"`
+	EndedAt      time.Time      `json:"ended_at"`
+	Duration     time.Duration  `json:"duration_ns"`
+	TokensByRole map[string]int `json:"tokens_by_role,omitempty"`
+	Title        string         `json:"title,omitempty"`
+	SkippedLines int            `json:"skipped_lines,omitempty"`
+	Errors       []RecordError  `json:"errors,omitempty"`
+	Count        int            `json:"count,omitempty"`
+	Estimated    bool           `json:"estimated,omitempty"`
+	Messages     int            `json:"messages"`
+	Switches     []RecordSwitch `json:"switches,omitempty"`
+}
+
+// RecordError is a failed model request in a Record.
+type RecordError struct {
+	Timestamp time.Time `json:"timestamp"`
+	Model     string    `json:"model,omitempty"`
+	Kind      string    `json:"kind"`
+	Message   string    `json:"message,omitempty"`
+	Cost      float64   `json:"cost"`
+	Tokens    int       `json:"tokens"`
+	Retried   bool      `json:"retried,omitempty"`
+}
+
+// RecordSwitch is a change of model in a Record.
+type RecordSwitch struct {
+	Timestamp  time.Time `json:"timestamp"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Requests   int       `json:"requests"`
+	Input      int       `json:"input"`
+	Output     int       `json:"output"`
+	CacheRead  int       `json:"cache_read,omitempty"`
+	CacheWrite int       `json:"cache_write,omitempty"`
+	Cost       float64   `json:"cost"`
+}
+
+// NewRecord returns the record of s.
+func NewRecord(s Session) Record {
+	r := Record{
+		Version:      RecordVersion,
+		ID:           s.ID,
+		Agent:        s.Agent,
+		Tenant:       s.Tenant,
+		Workspace:    s.Workspace,
+		Type:         s.Type,
+		CronID:       s.CronID,
+		CronName:     s.CronName,
+		SubagentID:   s.SubagentID,
+		FilePath:     s.FilePath,
+		Model:        s.Usage.Model,
+		Input:        s.Usage.Input,
+		Output:       s.Usage.Output,
+		Total:        s.Usage.Total,
+		CacheRead:    s.Usage.CacheRead,
+		CacheWrite:   s.Usage.CacheWrite,
+		CostInput:    s.Usage.CostInput,
+		CostOutput:   s.Usage.CostOutput,
+		CostTotal:    s.Usage.CostTotal,
+		StartedAt:    s.StartedAt,
+		EndedAt:      s.EndedAt,
+		Duration:     s.Duration,
+		TokensByRole: s.TokensByRole,
+		Title:        s.Title,
+		SkippedLines: s.SkippedLines,
+		Count:        s.Count,
+		Estimated:    s.Estimated,
+		Messages:     s.Turns(),
+	}
+	for _, e := range s.Errors {
+		r.Errors = append(r.Errors, RecordError(e))
+	}
+	for _, sw := range s.Switches {
+		r.Switches = append(r.Switches, RecordSwitch(sw))
+	}
+	return r
+}
+
+// Session returns the session r records, without messages; its Turns are
+// the recorded count.
+func (r Record) Session() Session {
+	s := Session{
+		ID:         r.ID,
+		Agent:      r.Agent,
+		Tenant:     r.Tenant,
+		Workspace:  r.Workspace,
+		Type:       r.Type,
+		CronID:     r.CronID,
+		CronName:   r.CronName,
+		SubagentID: r.SubagentID,
+		FilePath:   r.FilePath,
+		Usage: Usage{
+			Input:      r.Input,
+			Output:     r.Output,
+			Total:      r.Total,
+			CacheRead:  r.CacheRead,
+			CacheWrite: r.CacheWrite,
+			CostInput:  r.CostInput,
+			CostOutput: r.CostOutput,
+			CostTotal:  r.CostTotal,
+			Model:      r.Model,
+		},
+		StartedAt:    r.StartedAt,
+		EndedAt:      r.EndedAt,
+		Duration:     r.Duration,
+		TokensByRole: r.TokensByRole,
+		Title:        r.Title,
+		SkippedLines: r.SkippedLines,
+		Count:        r.Count,
+		Estimated:    r.Estimated,
+		MessageCount: r.Messages,
+	}
+	for _, e := range r.Errors {
+		s.Errors = append(s.Errors, RequestError(e))
+	}
+	for _, sw := range r.Switches {
+		s.Switches = append(s.Switches, ModelSwitch(sw))
+	}
+	return s
+}
+
+// WriteRecords writes sessions to w as newline-delimited JSON records, one
+// per line.
+func WriteRecords(w io.Writer, sessions []Session) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, s := range sessions {
+		if err := enc.Encode(NewRecord(s)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadRecords reads the sessions of newline-delimited JSON records written
+// by WriteRecords. Blank lines are ignored; a line that is not a record of
+// a supported version fails the read, naming the line.
+func ReadRecords(r io.Reader) ([]Session, error) {
+	var sessions []Session
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordLine)
+	for line := 1; scanner.Scan(); line++ {
+		data := scanner.Bytes()
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("line %d: invalid session record: %w", line, err)
+		}
+		if !SupportedRecordVersion(rec.Version) {
+			return nil, fmt.Errorf("line %d: unsupported session record version %d (want %d)", line, rec.Version, RecordVersion)
+		}
+		sessions = append(sessions, rec.Session())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read session records: %w", err)
+	}
+	return sessions, nil
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordsRoundTrip(t *testing.T) {
+	started := time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	sessions := []Session{
+		{
+			ID: "s1", Agent: "hq/urza", Tenant: "acme", Workspace: "hq", Type: SessionTypeCron, CronID: "sync-1", CronName: "sync", FilePath: "/a/s1.jsonl",
+			Messages:     make([]Message, 4),
+			Usage:        Usage{Input: 500, Output: 200, Total: 700, CacheRead: 50, CostInput: 0.5, CostOutput: 0.7, CostTotal: 1.2, Model: "anthropic/claude-opus-4-6"},
+			StartedAt:    started,
+			EndedAt:      started.Add(time.Hour),
+			Duration:     time.Hour,
+			TokensByRole: map[string]int{RoleUser: 100},
+			Title:        "sync the mirrors",
+			Errors:       []RequestError{{Timestamp: started, Model: "anthropic/claude-opus-4-6", Kind: ErrorRateLimit, Tokens: 3, Retried: true}},
+			Switches: []ModelSwitch{{Timestamp: started.Add(time.Minute), From: "anthropic/claude-haiku-4-5", To: "anthropic/claude-opus-4-6",
+				Requests: 2, Input: 400, Output: 150, CacheRead: 50, Cost: 1.1}},
+		},
+		{Agent: "amos", Type: SessionTypeSubagent, Usage: Usage{Total: 10, CostTotal: 2}, StartedAt: started, Count: 4, Estimated: true},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteRecords(&buf, sessions); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != len(sessions) {
+		t.Fatalf("expected %d record lines, got %d", len(sessions), lines)
+	}
+	got, err := ReadRecords(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range sessions {
+		want.MessageCount = want.Turns()
+		want.Messages = nil
+		if !reflect.DeepEqual(got[i], want) {
+			t.Errorf("session %d:\n got %+v\nwant %+v", i, got[i], want)
+		}
+	}
+}
+
+func TestReadRecordsVersion1(t *testing.T) {
+	sessions, err := ReadRecords(strings.NewReader(`{"v":1,"agent":"urza","cost":0.5,"messages":3}` + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Usage.CostTotal != 0.5 || sessions[0].Switches != nil {
+		t.Errorf("expected a version 1 record read without switches, got %+v", sessions)
+	}
+}
+
+func TestReadRecordsErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"{\"v\":1,\"agent\":\"urza\"}\n\nnot json\n", "line 3: invalid session record"},
+		{"{\"v\":3,\"agent\":\"urza\"}\n", "line 1: unsupported session record version 3"},
+		{"{\"agent\":\"urza\"}\n", "unsupported session record version 0"},
+	}
+	for _, tt := range tests {
+		_, err := ReadRecords(strings.NewReader(tt.input))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ReadRecords(%q) error = %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Redacted replaces text removed by RedactTranscript that costs do not
+// depend on.
+const Redacted = "[redacted]"
+
+// Fields kept verbatim by RedactTranscript, at the top level of a line and
+// in its message. Usage is kept whole; errorMessage is kept because failed
+// requests are classified by it.
+var (
+	keptLineFields    = map[string]bool{"type": true, "timestamp": true, "model": true, "id": true, "parentId": true}
+	keptMessageFields = map[string]bool{"role": true, "model": true, "usage": true, "stopReason": true, "errorMessage": true, "provider": true, "api": true}
+)
+
+// RedactTranscript removes the content of a transcript while keeping what
+// costs are computed from, so a redacted transcript parses to the same
+// session. Message text, thinking and tool call arguments are replaced by
+// x's of the same size, which keeps the token share by role unchanged;
+// other strings become Redacted. Lines that cannot be decoded are replaced
+// by a placeholder that cannot be decoded either.
+func RedactTranscript(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			out.WriteByte('\n')
+			continue
+		}
+		out.Write(redactLine(line))
+		out.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// RedactIndexEntry redacts a session index entry, keeping its session ID
+// and numbers such as updatedAt.
+func RedactIndexEntry(entry json.RawMessage) json.RawMessage {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(entry, &fields); err != nil {
+		return json.RawMessage(`"` + Redacted + `"`)
+	}
+	for key, value := range fields {
+		if key != "sessionId" {
+			fields[key] = redactValue(value)
+		}
+	}
+	return encode(fields)
+}
+
+func redactLine(line []byte) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return []byte(Redacted + " (undecodable line)")
+	}
+	for key, value := range fields {
+		switch {
+		case key == "message":
+			fields[key] = redactMessage(value)
+		case !keptLineFields[key]:
+			fields[key] = redactValue(value)
+		}
+	}
+	return encode(fields)
+}
+
+func redactMessage(message json.RawMessage) json.RawMessage {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(message, &fields); err != nil {
+		return redactValue(message)
+	}
+	for key, value := range fields {
+		switch {
+		case key == "content":
+			fields[key] = redactContent(value)
+		case !keptMessageFields[key]:
+			fields[key] = redactValue(value)
+		}
+	}
+	return encode(fields)
+}
+
+// redactContent redacts a message's content blocks, keeping their type and
+// the size of what roles are estimated from.
+func redactContent(content json.RawMessage) json.RawMessage {
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return sameSize(len(text))
+	}
+	var blocks []map[string]json.RawMessage
+	if err := json.Unmarshal(content, &blocks); err != nil {
+		return redactValue(content)
+	}
+	for _, block := range blocks {
+		for key, value := range block {
+			switch key {
+			case "type":
+			case "text", "thinking":
+				var s string
+				if err := json.Unmarshal(value, &s); err == nil {
+					block[key] = sameSize(len(s))
+				} else {
+					block[key] = redactValue(value)
+				}
+			case "arguments":
+				// Measured raw: a string of x's is the same size with its
+				// quotes.
+				block[key] = sameSize(max(len(value)-2, 0))
+			default:
+				block[key] = redactValue(value)
+			}
+		}
+	}
+	return encode(blocks)
+}
+
+// redactValue replaces every string in a JSON value with Redacted, keeping
+// numbers, booleans and structure.
+func redactValue(value json.RawMessage) json.RawMessage {
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return encode(Redacted)
+	}
+	return encode(redactAny(v))
+}
+
+func redactAny(v any) any {
+	switch v := v.(type) {
+	case string:
+		return Redacted
+	case []any:
+		for i := range v {
+			v[i] = redactAny(v[i])
+		}
+	case map[string]any:
+		for key := range v {
+			v[key] = redactAny(v[key])
+		}
+	}
+	return v
+}
+
+// sameSize is a JSON string of n x's.
+func sameSize(n int) json.RawMessage {
+	return encode(strings.Repeat("x", n))
+}
+
+// mustMarshal encodes values that always encode: strings, numbers and
+// decoded JSON.
+func encode(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const secretTranscript = `{"type":"session","id":"s1","cwd":"/home/alice/secret-project"}
+{"type":"message","timestamp":"2026-02-10T16:53:00.000Z","message":{"role":"user","content":[{"type":"text","text":"my password is hunter2"}]}}
+{"type":"message","timestamp":"2026-02-10T16:53:15.420Z","message":{"role":"assistant","content":[{"type":"thinking","thinking":"the user said hunter2 é"},{"type":"toolCall","name":"read","arguments":{"path":"/home/alice/hunter2.txt","lines":[1,2]}}],"usage":{"input":100,"output":50,"cacheRead":20,"totalTokens":170,"cost":{"total":0.00125}},"model":"moonshotai/kimi-k2.5"}}
+{"type":"message","timestamp":"2026-02-10T16:53:20.000Z","message":{"role":"toolResult","toolName":"read","content":"hunter2 contents\n"}}
+not json hunter2
+{"type":"message","timestamp":"2026-02-10T16:54:00.000Z","message":{"role":"assistant","content":[],"usage":{"input":300,"output":10,"totalTokens":310,"cost":{"total":0.0025}},"model":"moonshotai/kimi-k2.5","stopReason":"error","errorMessage":"429 rate limit"}}
+`
+
+func TestRedactTranscript(t *testing.T) {
+	redacted, err := RedactTranscript([]byte(secretTranscript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"hunter2", "alice"} {
+		if strings.Contains(string(redacted), secret) {
+			t.Errorf("expected %q redacted:\n%s", secret, redacted)
+		}
+	}
+
+	dir := t.TempDir()
+	parse := func(name string, data []byte) Session {
+		t.Helper()
+		path := filepath.Join(dir, name+".jsonl")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		s, err := New(dir).parseSessionFile("urza", name, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	original := parse("original", []byte(secretTranscript))
+	copy := parse("redacted", redacted)
+	if !reflect.DeepEqual(copy.Usage, original.Usage) || !reflect.DeepEqual(copy.TokensByRole, original.TokensByRole) ||
+		!reflect.DeepEqual(copy.Errors, original.Errors) || copy.SkippedLines != original.SkippedLines ||
+		!copy.StartedAt.Equal(original.StartedAt) || copy.Duration != original.Duration {
+		t.Errorf("redacted transcript parses differently:\n got %+v\nwant %+v", copy, original)
+	}
+}
+
+func TestRedactIndexEntry(t *testing.T) {
+	entry := RedactIndexEntry(json.RawMessage(`{"sessionId":"s1","updatedAt":1770742395420,"label":"alice's chat"}`))
+	var got map[string]any
+	if err := json.Unmarshal(entry, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["sessionId"] != "s1" || got["updatedAt"] != float64(1770742395420) || got["label"] != Redacted {
+		t.Errorf("unexpected entry: %s", entry)
+	}
+}
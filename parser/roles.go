@@ -0,0 +1,114 @@
+package parser
+
+// Message role categories used by Session.TokensByRole.
+const (
+	RoleSystem     = "system"      // system prompt and other context not visible in the transcript
+	RoleUser       = "user"        // user messages
+	RoleToolResult = "tool_result" // tool output fed back to the model
+	RoleText       = "text"        // assistant replies
+	RoleThinking   = "thinking"    // assistant reasoning
+	RoleToolCall   = "tool_call"   // assistant tool invocations
+)
+
+// Roles lists the role categories in display order.
+var Roles = []string{RoleSystem, RoleUser, RoleToolResult, RoleText, RoleThinking, RoleToolCall}
+
+// charsPerToken approximates token counts from content size.
+const charsPerToken = 4
+
+// roleTracker attributes each assistant turn's tokens to message roles.
+// Prompt tokens are split across the conversation so far in proportion to
+// each role's share of it, with whatever the visible transcript cannot
+// account for attributed to the system prompt. Output tokens are split
+// across the turn's own content types.
+type roleTracker struct {
+	context map[string]int // visible conversation size by role, in characters
+	tokens  map[string]int // attributed tokens by role
+}
+
+func newRoleTracker() *roleTracker {
+	return &roleTracker{context: make(map[string]int), tokens: make(map[string]int)}
+}
+
+// add folds a transcript message into the tracker.
+func (t *roleTracker) add(msg Message) {
+	sizes := contentSizes(msg)
+	if msg.Message.Role == "assistant" {
+		usage := msg.Message.Usage
+		prompt := usage.Input + usage.CacheRead + usage.CacheWrite
+
+		visible := 0
+		for _, chars := range t.context {
+			visible += chars / charsPerToken
+		}
+		if prompt > visible {
+			t.tokens[RoleSystem] += prompt - visible
+			prompt = visible
+		}
+		distribute(t.tokens, prompt, t.context, RoleSystem)
+		distribute(t.tokens, usage.Output, sizes, RoleText)
+	}
+	for role, chars := range sizes {
+		t.context[role] += chars
+	}
+}
+
+// contentSizes measures a message's content by role category.
+func contentSizes(msg Message) map[string]int {
+	sizes := make(map[string]int)
+	for _, c := range msg.Message.Content {
+		size := len(c.Text) + len(c.Thinking) + len(c.Arguments)
+		if size == 0 {
+			continue
+		}
+		sizes[roleCategory(msg.Message.Role, c.Type)] += size
+	}
+	return sizes
+}
+
+func roleCategory(role, contentType string) string {
+	switch role {
+	case "assistant":
+		switch contentType {
+		case "thinking":
+			return RoleThinking
+		case "toolCall", "tool_use":
+			return RoleToolCall
+		default:
+			return RoleText
+		}
+	case "user":
+		if contentType == "tool_result" {
+			return RoleToolResult
+		}
+		return RoleUser
+	case "toolResult", "tool":
+		return RoleToolResult
+	default:
+		return RoleSystem
+	}
+}
+
+// distribute adds tokens to dst in proportion to weights, giving rounding
+// leftovers (or everything, if there are no weights) to fallback.
+func distribute(dst map[string]int, tokens int, weights map[string]int, fallback string) {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	if tokens <= 0 {
+		return
+	}
+	if total == 0 {
+		dst[fallback] += tokens
+		return
+	}
+
+	assigned := 0
+	for role, w := range weights {
+		share := int(int64(tokens) * int64(w) / int64(total))
+		dst[role] += share
+		assigned += share
+	}
+	dst[fallback] += tokens - assigned
+}
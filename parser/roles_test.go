@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTokensByRole(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// 400 chars of user text (~100 tokens) precede a turn whose 1000 prompt
+	// tokens therefore include ~900 tokens of system prompt. The turn's 60
+	// output tokens split 2:1 between its thinking and its tool call.
+	user := strings.Repeat("u", 400)
+	thinking := strings.Repeat("t", 200)
+	lines := []string{
+		`{"type":"message","message":{"role":"user","content":[{"type":"text","text":"` + user + `"}]}}`,
+		`{"type":"message","message":{"role":"assistant","content":[{"type":"thinking","thinking":"` + thinking + `"},{"type":"toolCall","arguments":{"q":"` + strings.Repeat("a", 92) + `"}}],"usage":{"input":200,"cacheRead":800,"output":60,"totalTokens":1060}}}`,
+		`{"type":"message","message":{"role":"toolResult","content":[{"type":"text","text":"` + strings.Repeat("r", 800) + `"}]}}`,
+	}
+	sessionFile := filepath.Join(tempDir, "s.jsonl")
+	if err := os.WriteFile(sessionFile, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := New(tempDir).parseSessionFile("urza", "s", sessionFile)
+	if err != nil {
+		t.Fatalf("parseSessionFile failed: %v", err)
+	}
+
+	expected := map[string]int{RoleSystem: 900, RoleUser: 100, RoleThinking: 40, RoleToolCall: 20}
+	for role, tokens := range expected {
+		if got := session.TokensByRole[role]; got != tokens {
+			t.Errorf("%s: expected %d tokens, got %d (all: %v)", role, tokens, got, session.TokensByRole)
+		}
+	}
+
+	total := 0
+	for _, tokens := range session.TokensByRole {
+		total += tokens
+	}
+	if total != session.Usage.Total {
+		t.Errorf("expected attributed tokens to sum to %d, got %d", session.Usage.Total, total)
+	}
+}
+
+func TestDistribute(t *testing.T) {
+	dst := make(map[string]int)
+	distribute(dst, 10, map[string]int{RoleUser: 1, RoleText: 2}, RoleSystem)
+	// 10*1/3 = 3 and 10*2/3 = 6; the rounding leftover goes to the fallback.
+	if dst[RoleUser] != 3 || dst[RoleText] != 6 || dst[RoleSystem] != 1 {
+		t.Errorf("unexpected distribution: %v", dst)
+	}
+
+	distribute(dst, 5, nil, RoleSystem)
+	if dst[RoleSystem] != 6 {
+		t.Errorf("expected unweighted tokens to go to the fallback, got %v", dst)
+	}
+}
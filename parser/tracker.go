@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// Tracker follows many transcripts at once, such as every active session
+// of a fleet, for a long-running process that polls them on a timer. It
+// remembers how far it has read each transcript and only parses what was
+// appended since, so a poll over idle sessions costs one stat each.
+type Tracker struct {
+	// MaxBytes caps the bytes read by one Poll across all transcripts,
+	// spreading a large backlog, e.g. on startup, over several polls
+	// instead of spiking on the first. Zero means no limit.
+	MaxBytes int64
+
+	followers map[string]*Follower
+	next      int // transcript index the next throttled Poll starts at
+}
+
+// NewTracker returns a Tracker following no transcripts yet.
+func NewTracker() *Tracker {
+	return &Tracker{followers: make(map[string]*Follower)}
+}
+
+// Poll reads the transcripts at paths and returns the assistant turns
+// completed in each since the last call, keyed by path; transcripts
+// without new turns are left out. Transcripts seen for the first time are
+// read from the start. Transcripts no longer in paths, or removed since
+// being listed, are forgotten. Other read errors are returned together
+// once every transcript has been polled.
+//
+// When MaxBytes runs out, the remaining transcripts are polled first on
+// the next call, so that every transcript makes progress.
+func (t *Tracker) Poll(paths []string) (map[string][]Message, error) {
+	listed := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		listed[path] = true
+		if t.followers[path] == nil {
+			t.followers[path] = NewFollower(path)
+		}
+	}
+	for path := range t.followers {
+		if !listed[path] {
+			delete(t.followers, path)
+		}
+	}
+
+	result := make(map[string][]Message)
+	var errs []error
+	budget := t.MaxBytes
+	start := 0
+	if len(paths) > 0 {
+		start = t.next % len(paths)
+	}
+	t.next = 0
+	for i := range paths {
+		index := (start + i) % len(paths)
+		path := paths[index]
+		limit := int64(0)
+		if t.MaxBytes > 0 {
+			if budget <= 0 {
+				t.next = index
+				break
+			}
+			limit = budget
+		}
+		turns, read, err := t.followers[path].poll(limit)
+		budget -= read
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				delete(t.followers, path)
+			} else {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if len(turns) > 0 {
+			result[path] = turns
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// Follower returns the Follower of a tracked transcript, with its running
+// totals, or nil if the transcript is not tracked.
+func (t *Tracker) Follower(path string) *Follower {
+	return t.followers[path]
+}
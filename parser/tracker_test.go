@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTracker(t *testing.T) {
+	dir := t.TempDir()
+	turn := `{"type":"message","timestamp":"2026-10-15T10:00:00Z","message":{"role":"assistant","usage":{"input":100,"output":20,"totalTokens":120,"cost":{"total":0.5}},"model":"claude-opus-4-6"}}` + "\n"
+	a, b := filepath.Join(dir, "a.jsonl"), filepath.Join(dir, "b.jsonl")
+	for _, path := range []string{a, b} {
+		if err := os.WriteFile(path, []byte(turn), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tracker := NewTracker()
+	updates, err := tracker.Poll([]string{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates[a]) != 1 || len(updates[b]) != 1 {
+		t.Fatalf("expected one turn in each transcript, got %v", updates)
+	}
+
+	// Only what was appended is read; idle transcripts are left out.
+	file, err := os.OpenFile(a, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(turn)
+	file.Close()
+	updates, err = tracker.Poll([]string{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 1 || len(updates[a]) != 1 {
+		t.Fatalf("expected only a's new turn, got %v", updates)
+	}
+	if f := tracker.Follower(a); f.Turns != 2 || f.Usage.CostTotal != 1 {
+		t.Errorf("expected a's running totals of 2 turns and $1, got %d turns, %+v", f.Turns, f.Usage)
+	}
+
+	// Removed and unlisted transcripts are forgotten.
+	if err := os.Remove(b); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tracker.Poll([]string{a, b}); err != nil {
+		t.Fatalf("expected a removed transcript to be skipped, got %v", err)
+	}
+	if tracker.Follower(b) != nil {
+		t.Error("expected the removed transcript to be forgotten")
+	}
+	tracker.Poll(nil)
+	if tracker.Follower(a) != nil {
+		t.Error("expected the unlisted transcript to be forgotten")
+	}
+}
+
+func TestTrackerMaxBytes(t *testing.T) {
+	dir := t.TempDir()
+	turn := `{"type":"message","message":{"role":"assistant","usage":{"totalTokens":10,"cost":{"total":0.1}}}}` + "\n"
+	var paths []string
+	for _, name := range []string{"a", "b", "c"} {
+		path := filepath.Join(dir, name+".jsonl")
+		if err := os.WriteFile(path, []byte(strings.Repeat(turn, 2)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	// A budget of three lines a poll reads the six lines over two polls,
+	// resuming where the first left off.
+	tracker := NewTracker()
+	tracker.MaxBytes = int64(3 * len(turn))
+	total := 0
+	for poll := 1; poll <= 2; poll++ {
+		updates, err := tracker.Poll(paths)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for _, turns := range updates {
+			n += len(turns)
+		}
+		if n != 3 {
+			t.Errorf("poll %d: expected 3 turns, got %d", poll, n)
+		}
+		total += n
+	}
+	if total != 6 {
+		t.Errorf("expected all 6 turns, got %d", total)
+	}
+	for _, path := range paths {
+		if f := tracker.Follower(path); f.Turns != 2 {
+			t.Errorf("%s: expected 2 turns, got %d", path, f.Turns)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// progressFormat is the --progress flag.
+var progressFormat string
+
+// progressEvent is one line of --progress json output. Fields are only ever
+// added.
+type progressEvent struct {
+	Event   string    `json:"event"` // progress, warning or done
+	Time    time.Time `json:"time"`
+	Dir     string    `json:"dir,omitempty"` // agents directory being parsed; empty for --files
+	Parsed  int       `json:"parsed,omitempty"`
+	Total   int       `json:"total,omitempty"`
+	Percent float64   `json:"percent,omitempty"`
+	Warning string    `json:"warning,omitempty"`
+	// Warnings is the number of warnings reported, on done events.
+	Warnings int `json:"warnings,omitempty"`
+}
+
+// jsonProgress writes parsing progress as NDJSON. Progress events are
+// written at most once per percent, plus the last one of each directory.
+type jsonProgress struct {
+	mu       sync.Mutex
+	enc      *json.Encoder
+	now      func() time.Time
+	percent  int // last percent written, -1 for none
+	parsed   int // transcripts parsed across directories
+	warnings int
+}
+
+// newProgress returns the progress sink selected by --progress, or nil for
+// none.
+func newProgress() (*jsonProgress, error) {
+	switch progressFormat {
+	case "":
+		return nil, nil
+	case "json":
+		return newJSONProgress(os.Stderr), nil
+	default:
+		return nil, fmt.Errorf("invalid progress format: %s (valid: json)", progressFormat)
+	}
+}
+
+func newJSONProgress(w io.Writer) *jsonProgress {
+	return &jsonProgress{enc: json.NewEncoder(w), now: time.Now, percent: -1}
+}
+
+// Parsed implements parser.Progress.
+func (p *jsonProgress) Parsed(dir string, parsed, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if parsed == 1 {
+		p.percent = -1
+	}
+	p.parsed++
+	percent := parsed * 100 / total
+	if percent == p.percent && parsed < total {
+		return
+	}
+	p.percent = percent
+	p.write(progressEvent{
+		Event:   "progress",
+		Dir:     dir,
+		Parsed:  parsed,
+		Total:   total,
+		Percent: float64(parsed*1000/total) / 10,
+	})
+}
+
+// Warn implements parser.Progress.
+func (p *jsonProgress) Warn(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.warnings++
+	p.write(progressEvent{Event: "warning", Warning: err.Error()})
+}
+
+// Done writes the final event, with the transcripts parsed and warnings
+// reported in total.
+func (p *jsonProgress) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.write(progressEvent{Event: "done", Parsed: p.parsed, Warnings: p.warnings})
+}
+
+func (p *jsonProgress) write(e progressEvent) {
+	e.Time = p.now().UTC()
+	p.enc.Encode(e)
+}
+
+// parserProgress converts a possibly nil sink for report.Options, where a
+// nil *jsonProgress must not become a non-nil interface.
+func parserProgress(p *jsonProgress) parser.Progress {
+	if p == nil {
+		return nil
+	}
+	return p
+}
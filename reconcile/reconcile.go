@@ -0,0 +1,180 @@
+// Package reconcile compares the spend costctl recorded from transcripts
+// with the usage providers bill for, so a month can be closed knowing how
+// far the two agree.
+package reconcile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Usage is what a provider billed for one model on one day.
+type Usage struct {
+	Date  string // YYYY-MM-DD
+	Model string
+	Cost  float64
+}
+
+// ReadCSV reads provider usage as CSV with a header row naming at least
+// date, model and cost columns, in any order and case; other columns are
+// ignored. Dates may be YYYY-MM-DD or RFC 3339, of which only the date is
+// kept.
+func ReadCSV(r io.Reader) ([]Usage, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{"date": -1, "model": -1, "cost": -1}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; ok {
+			columns[name] = i
+		}
+	}
+	for _, name := range []string{"date", "model", "cost"} {
+		if columns[name] < 0 {
+			return nil, fmt.Errorf("provider usage has no %s column", name)
+		}
+	}
+
+	var usage []Usage
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return usage, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		date, err := parseDate(field("date"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		cost, err := strconv.ParseFloat(strings.TrimPrefix(field("cost"), "$"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid cost %q", line, field("cost"))
+		}
+		usage = append(usage, Usage{Date: date, Model: field("model"), Cost: cost})
+	}
+}
+
+func parseDate(s string) (string, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.Format("2006-01-02"), nil
+	}
+	if _, err := time.Parse("2006-01-02", s); err == nil {
+		return s, nil
+	}
+	return "", fmt.Errorf("invalid date %q (use YYYY-MM-DD or RFC 3339)", s)
+}
+
+// InMonth returns the usage dated within month (YYYY-MM).
+func InMonth(usage []Usage, month string) []Usage {
+	var kept []Usage
+	for _, u := range usage {
+		if strings.HasPrefix(u.Date, month+"-") {
+			kept = append(kept, u)
+		}
+	}
+	return kept
+}
+
+// Line compares one model's recorded and billed spend.
+type Line struct {
+	Model      string  `json:"model"`
+	Recorded   float64 `json:"recorded"`
+	Provider   float64 `json:"provider"`
+	Difference float64 `json:"difference"` // Recorded - Provider
+	Flagged    bool    `json:"flagged"`
+}
+
+// Result is a reconciliation of recorded spend with provider usage.
+type Result struct {
+	Lines      []Line  `json:"lines"`
+	Recorded   float64 `json:"recorded"`
+	Provider   float64 `json:"provider"`
+	Difference float64 `json:"difference"`
+	// Tolerance is the share of the larger amount by which a model's
+	// figures may differ before the line is flagged.
+	Tolerance float64 `json:"tolerance"`
+	Flagged   int     `json:"flagged"`
+}
+
+// minDifference is the difference below which lines are never flagged,
+// absorbing providers rounding to the cent.
+const minDifference = 0.01
+
+// Compare reconciles recorded spend by model with provider usage. Models
+// match by name ignoring case and any provider prefix, so
+// anthropic/claude-opus-4-6 in transcripts matches claude-opus-4-6 on an
+// Anthropic bill. Lines are sorted by the size of their difference.
+func Compare(recorded map[string]float64, usage []Usage, tolerance float64) Result {
+	lines := make(map[string]*Line)
+	line := func(model string) *Line {
+		key := modelKey(model)
+		l, ok := lines[key]
+		if !ok {
+			l = &Line{Model: model}
+			lines[key] = l
+		}
+		return l
+	}
+	for model, cost := range recorded {
+		l := line(model)
+		l.Model = model
+		l.Recorded += cost
+	}
+	for _, u := range usage {
+		line(u.Model).Provider += u.Cost
+	}
+
+	result := Result{Tolerance: tolerance}
+	for _, l := range lines {
+		l.Difference = l.Recorded - l.Provider
+		diff := math.Abs(l.Difference)
+		l.Flagged = diff >= minDifference && diff > tolerance*math.Max(math.Abs(l.Recorded), math.Abs(l.Provider))
+		if l.Flagged {
+			result.Flagged++
+		}
+		result.Recorded += l.Recorded
+		result.Provider += l.Provider
+		result.Lines = append(result.Lines, *l)
+	}
+	result.Difference = result.Recorded - result.Provider
+	sort.Slice(result.Lines, func(i, j int) bool {
+		a, b := math.Abs(result.Lines[i].Difference), math.Abs(result.Lines[j].Difference)
+		if a != b {
+			return a > b
+		}
+		return result.Lines[i].Model < result.Lines[j].Model
+	})
+	return result
+}
+
+// modelKey is the name models are matched by.
+func modelKey(model string) string {
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+	return strings.ToLower(model)
+}
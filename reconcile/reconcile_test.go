@@ -0,0 +1,76 @@
+package reconcile
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	input := "Model,Date,Cost,Requests\n" +
+		"claude-opus-4-6,2026-06-01,12.50,40\n" +
+		"claude-opus-4-6,2026-06-02T00:00:00Z,$2.5,3\n" +
+		"kimi-k2.5,2026-07-01,1,1\n"
+	usage, err := ReadCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Usage{
+		{Date: "2026-06-01", Model: "claude-opus-4-6", Cost: 12.5},
+		{Date: "2026-06-02", Model: "claude-opus-4-6", Cost: 2.5},
+		{Date: "2026-07-01", Model: "kimi-k2.5", Cost: 1},
+	}
+	if len(usage) != len(want) {
+		t.Fatalf("expected %d rows, got %+v", len(want), usage)
+	}
+	for i := range want {
+		if usage[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, usage[i], want[i])
+		}
+	}
+	if june := InMonth(usage, "2026-06"); len(june) != 2 {
+		t.Errorf("expected 2 rows in June, got %+v", june)
+	}
+
+	for _, bad := range []string{
+		"date,cost\n2026-06-01,1\n",
+		"date,model,cost\nJune 1,opus,1\n",
+		"date,model,cost\n2026-06-01,opus,lots\n",
+	} {
+		if _, err := ReadCSV(strings.NewReader(bad)); err == nil {
+			t.Errorf("ReadCSV(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	recorded := map[string]float64{
+		"anthropic/claude-opus-4-6": 100,
+		"moonshotai/kimi-k2.5":      10,
+		"openai/gpt-5":              0.004,
+	}
+	usage := []Usage{
+		{Date: "2026-06-01", Model: "claude-opus-4-6", Cost: 60},
+		{Date: "2026-06-02", Model: "Claude-Opus-4-6", Cost: 38},
+		{Date: "2026-06-01", Model: "kimi-k2.5", Cost: 12},
+		{Date: "2026-06-01", Model: "gemini-2.5-pro", Cost: 3},
+	}
+	result := Compare(recorded, usage, 0.05)
+
+	if result.Recorded != 110.004 || result.Provider != 113 || result.Flagged != 2 {
+		t.Errorf("unexpected totals: %+v", result)
+	}
+	want := []Line{
+		{Model: "gemini-2.5-pro", Provider: 3, Difference: -3, Flagged: true},
+		{Model: "anthropic/claude-opus-4-6", Recorded: 100, Provider: 98, Difference: 2},
+		{Model: "moonshotai/kimi-k2.5", Recorded: 10, Provider: 12, Difference: -2, Flagged: true},
+		{Model: "openai/gpt-5", Recorded: 0.004, Difference: 0.004},
+	}
+	if len(result.Lines) != len(want) {
+		t.Fatalf("expected %d lines, got %+v", len(want), result.Lines)
+	}
+	for i := range want {
+		if result.Lines[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, result.Lines[i], want[i])
+		}
+	}
+}
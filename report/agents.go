@@ -0,0 +1,77 @@
+package report
+
+import (
+	"sort"
+
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/reporter"
+)
+
+// DisplayName returns the display name of an agent directory given the
+// config file's agent_names.
+func DisplayName(names map[string]string, dir string) string {
+	if name, ok := names[dir]; ok && name != "" {
+		return name
+	}
+	return dir
+}
+
+// AgentDirs returns the agent directories an agent filter selects: the
+// filter itself and every directory displayed under it. An empty filter
+// selects everything and yields [""].
+func AgentDirs(names map[string]string, filter string) []string {
+	if filter == "" {
+		return []string{""}
+	}
+	dirs := []string{filter}
+	for dir, name := range names {
+		if name == filter && dir != filter {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs[1:])
+	return dirs
+}
+
+// matchesAgent reports whether the agent directory dir is selected by an
+// agent filter, which may be a display name or a directory name.
+func matchesAgent(names map[string]string, dir, filter string) bool {
+	return filter == "" || dir == filter || DisplayName(names, dir) == filter
+}
+
+// renameAgents keeps the sessions selected by the agent filter and replaces
+// their directory names with display names. Sessions read from stores and
+// records without a workspace take it from their directory name first.
+func renameAgents(sessions []parser.Session, names map[string]string, filter string) []parser.Session {
+	kept := sessions[:0]
+	for _, s := range sessions {
+		if !matchesAgent(names, s.Agent, filter) {
+			continue
+		}
+		if s.Workspace == "" {
+			s.Workspace = parser.WorkspaceOf(s.Agent)
+		}
+		s.Agent = DisplayName(names, s.Agent)
+		kept = append(kept, s)
+	}
+	return kept
+}
+
+// knownAgents lists the agents scan finds in the roots that the agent
+// filter selects, by tenant and display name. Unreadable roots are left
+// out; parsing them reports the problem.
+func knownAgents(roots []Root, names map[string]string, scan scan, agent string) []reporter.KnownAgent {
+	known := []reporter.KnownAgent{}
+	for _, root := range roots {
+		agents, err := scan.parser(root.Dir).ListAgents()
+		if err != nil {
+			continue
+		}
+		for _, dir := range agents {
+			if matchesAgent(names, dir, agent) {
+				known = append(known, reporter.KnownAgent{Tenant: root.Tenant, Agent: DisplayName(names, dir)})
+			}
+		}
+	}
+	return known
+}
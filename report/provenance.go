@@ -0,0 +1,77 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/misty-step/costctl/config"
+	"github.com/misty-step/costctl/reporter"
+)
+
+// provenance describes how a report with opts and settings is produced.
+// Explicit files are recorded by directory, keeping reports over thousands
+// of transcripts small.
+func provenance(opts Options, settings *config.Config) (*reporter.Provenance, error) {
+	p := &reporter.Provenance{Version: opts.Version}
+	p.Host, _ = os.Hostname()
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	p.ConfigHash = "sha256:" + hex.EncodeToString(sum[:])
+
+	switch {
+	case opts.Files != nil:
+		var dirs []string
+		for _, f := range opts.Files {
+			if abs, err := filepath.Abs(f); err == nil {
+				f = abs
+			}
+			dirs = append(dirs, filepath.Dir(f))
+		}
+		slices.Sort(dirs)
+		for _, dir := range slices.Compact(dirs) {
+			p.Sources = append(p.Sources, reporter.Source{Kind: "files", Path: dir})
+		}
+	case opts.Input == "-":
+		p.Sources = append(p.Sources, reporter.Source{Kind: "input", Path: "stdin"})
+	case opts.Input != "":
+		path := opts.Input
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		p.Sources = append(p.Sources, reporter.Source{Kind: "input", Path: path})
+	case opts.Source != "":
+		p.Sources = append(p.Sources, reporter.Source{Kind: "store", Path: redactDSN(opts.Source), Tenant: opts.Tenant})
+	case opts.Ledger != "":
+		p.Sources = append(p.Sources, reporter.Source{Kind: "ledger", Path: redactDSN(opts.Ledger), Tenant: opts.Tenant})
+	default:
+		roots, err := opts.roots()
+		if err != nil {
+			return nil, err
+		}
+		for _, root := range roots {
+			p.Sources = append(p.Sources, reporter.Source{Kind: "agents", Path: root.Dir, Tenant: root.Tenant})
+		}
+		if opts.History != "" && reporter.CalendarPeriod(opts.Period) {
+			p.Sources = append(p.Sources, reporter.Source{Kind: "history", Path: redactDSN(opts.History), Tenant: opts.Tenant})
+		}
+	}
+	return p, nil
+}
+
+// redactDSN hides the password of a URL-style DSN.
+func redactDSN(dsn string) string {
+	u, err := url.Parse(dsn)
+	if err != nil || u.User == nil {
+		return dsn
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,884 @@
+// Package report generates cost reports for embedding in other programs.
+//
+// It runs the same pipeline as `costctl report`: reading transcripts from
+// agents directories, explicit files or a history store, applying the
+// config file's display names, rules and targets, and filtering by period,
+// agent, cron and as-of time. The API follows semantic versioning
+// independently of the CLI: within a major version, existing Options
+// fields keep their meaning and Report fields are only added.
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/misty-step/costctl/config"
+	"github.com/misty-step/costctl/detect"
+	"github.com/misty-step/costctl/maintenance"
+	"github.com/misty-step/costctl/notes"
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/reporter"
+	"github.com/misty-step/costctl/store"
+)
+
+// DefaultThreshold is the expensive-cron threshold (dollars per run) used
+// when Options.Threshold is zero.
+const DefaultThreshold = 0.50
+
+// Periods lists the named values of Options.Period besides "". Calendar
+// months such as 2026-03 and 2026-01..2026-06 are valid too.
+var Periods = []string{"today", "yesterday", "week", "month", "ytd", "all"}
+
+// Root is an agents directory and the tenant it belongs to ("" when tenants
+// are not used).
+type Root struct {
+	Tenant string
+	Dir    string
+}
+
+// Options selects the data and sections of a report. The zero value reports
+// all time over the default agents directory.
+type Options struct {
+	// Roots are the agents directories to read. Defaults to
+	// ~/.openclaw/agents.
+	Roots []Root
+	// Files lists transcripts to read instead of Roots.
+	Files []string
+	// Source is a history store DSN (see package store) to read instead of
+	// transcripts. Tenant and Host, if set, limit it to one tenant's or
+	// one host's rows.
+	Source string
+	Tenant string
+	Host   string
+	// Ledger is a store DSN whose session ledger, filled by costctl
+	// ingest, is read instead of transcripts. Unlike Source's aggregates it
+	// keeps each session's parser.Record, so per-session sections such as
+	// crons, cascades and session lists work; model overrides and caps
+	// read from the agents' configs are not checked. Tenant and Host limit it as
+	// they do Source.
+	Ledger string
+	// Input is a file of session records (see parser.ReadRecords), as
+	// written by costctl export --to sessions, to read instead of
+	// transcripts; "-" reads standard input.
+	Input string
+	// History is a history store DSN whose aggregates stand in for
+	// transcripts in calendar periods (see reporter.CalendarPeriod), so
+	// year-to-date and multi-month reports survive transcript rotation.
+	// Only this host's rows (and with Tenant, that tenant's) are used, and
+	// only for days before the last stored one; transcripts cover the
+	// rest. Ignored with Files, Source and other periods.
+	History string
+
+	// Period is today, yesterday, week, month, ytd, all ("" means all) or
+	// calendar months (see reporter.ValidatePeriod).
+	Period string
+	// Agent keeps one agent, by directory or display name.
+	Agent string
+	// Cron keeps the runs of one cron, by name.
+	Cron string
+	// Workspace keeps the agents of one workspace of a nested layout (see
+	// parser.WorkspaceOf).
+	Workspace string
+	// AsOf reports as of a past instant, ignoring transcript lines after
+	// it. Zero means now. Calendar periods are measured in local time
+	// whatever AsOf's location.
+	AsOf time.Time
+	// Since and Until, when set, limit the report to sessions that started
+	// in [Since, Until), a range the report shows, such as one no period
+	// covers. The period still applies within it.
+	Since time.Time
+	Until time.Time
+
+	// Sections, when set, lists the sections to generate (see
+	// reporter.Sections) instead of the optional ones below. Defaults to
+	// the config file's sections.
+	Sections []string
+
+	// Compare attaches each agent, cron and model's change since the
+	// previous period. Period must be one of reporter.ComparablePeriods or
+	// a range of months.
+	Compare bool
+	// IncludeIdle lists agents without sessions in the period as idle, zero
+	// rows. It needs agents directories to find them in, and no Cron.
+	IncludeIdle bool
+
+	// Optional sections; Full includes them all.
+	Crons     bool
+	Models    bool
+	Roles     bool
+	Turns     bool
+	Errors    bool
+	Cascades  bool
+	TypeMix   bool
+	Forecast  bool
+	AgentDays bool
+	Full      bool
+
+	// Threshold is the expensive-cron threshold in dollars per run.
+	// Defaults to DefaultThreshold.
+	Threshold float64
+	// Settings supplies display names, rules, KPI targets, cron owners and
+	// anomaly detectors, as in the config file. Nil uses none.
+	Settings *config.Config
+	// Notes are annotations attached to days and crons.
+	Notes []notes.Note
+	// Progress, when set, is told of each parsed transcript and receives
+	// the warnings otherwise printed to stderr.
+	Progress parser.Progress
+	// Cache, when set, keeps the sessions of transcripts across runs, so
+	// only new and changed transcripts are parsed (see parser.Cache). The
+	// caller loads and saves it.
+	Cache *parser.Cache
+	// Version is the build of the program generating the report, recorded
+	// in its provenance.
+	Version string
+	// MaxDuration, when set, stops parsing transcripts that long after
+	// loading starts. The report then covers what was parsed and is marked
+	// partial (see reporter.Report.Partial).
+	MaxDuration time.Duration
+	// Sessions, when set, are reported instead of reading the sources
+	// again: the sessions Load returned for these options, for callers
+	// that also use them otherwise.
+	Sessions []parser.Session
+	// Seeded pins what otherwise differs between two runs over the same
+	// input, so their reports compare byte for byte: the report is
+	// generated at AsOf (the Unix epoch without it) and its provenance
+	// leaves out the host.
+	Seeded bool
+}
+
+// Report is a generated report.
+type Report struct {
+	reporter.Report
+	// Skipped lists the agents and transcripts that could not be parsed.
+	Skipped []error `json:"-"`
+	// Scanned counts the transcripts read, or taken from Options.Cache,
+	// including those whose sessions fall outside the period.
+	Scanned int `json:"-"`
+}
+
+// Generate builds a report. It fails on invalid options, unreadable
+// sources and a single unreadable agents directory; with several roots,
+// unreadable ones are recorded in Skipped instead.
+func Generate(ctx context.Context, opts Options) (Report, error) {
+	settings := opts.Settings
+	if settings == nil {
+		settings = &config.Config{}
+	}
+	cfg := reporter.Config{
+		Period:         opts.Period,
+		Agent:          opts.Agent,
+		Crons:          opts.Crons,
+		Models:         opts.Models,
+		Roles:          opts.Roles,
+		Turns:          opts.Turns,
+		Errors:         opts.Errors,
+		Cascades:       opts.Cascades,
+		TypeMix:        opts.TypeMix,
+		Forecast:       opts.Forecast,
+		AgentDays:      opts.AgentDays,
+		Full:           opts.Full,
+		Threshold:      opts.Threshold,
+		Notes:          opts.Notes,
+		CronThresholds: settings.Rules.CronThresholds,
+		SessionCaps:    settings.Rules.SessionCaps,
+		CronCaps:       settings.Rules.CronCaps,
+		CapMargin:      settings.Rules.CapMargin,
+		AsOf:           opts.AsOf,
+		Since:          opts.Since,
+		Until:          opts.Until,
+
+		ContextGrowthTokens: settings.Rules.ContextGrowthTokens,
+		Sections:            opts.Sections,
+		Compare:             opts.Compare,
+	}
+	if opts.Compare && !slices.Contains(reporter.ComparablePeriods, opts.Period) && !reporter.CalendarPeriod(opts.Period) {
+		return Report{}, fmt.Errorf("comparing needs a period of %s or months", strings.Join(reporter.ComparablePeriods, ", "))
+	}
+	if opts.Compare && (!opts.Since.IsZero() || !opts.Until.IsZero()) {
+		return Report{}, fmt.Errorf("comparing needs a period, not a since/until range")
+	}
+	if opts.IncludeIdle && (opts.Files != nil || opts.Source != "" || opts.Ledger != "" || opts.Input != "" || opts.Cron != "" || opts.Workspace != "") {
+		return Report{}, fmt.Errorf("idle agents can only be listed from agents directories, without a cron or workspace filter")
+	}
+	if cfg.Sections == nil {
+		cfg.Sections = settings.Sections
+	}
+	for _, section := range cfg.Sections {
+		if !slices.Contains(reporter.Sections, section) {
+			return Report{}, fmt.Errorf("unknown section: %s (valid: %s)", section, strings.Join(reporter.Sections, ", "))
+		}
+	}
+	if cfg.Threshold == 0 {
+		cfg.Threshold = DefaultThreshold
+	}
+	if cfg.CapMargin == 0 {
+		cfg.CapMargin = config.DefaultCapMargin
+	}
+	if cfg.ContextGrowthTokens == 0 {
+		cfg.ContextGrowthTokens = config.DefaultContextGrowthTokens
+	}
+	for name, t := range settings.Rules.CronTokenThresholds {
+		if t.Input < 0 || t.Output < 0 || t.Total < 0 {
+			return Report{}, fmt.Errorf("token threshold for cron %s must not be negative", name)
+		}
+		if cfg.CronTokens == nil {
+			cfg.CronTokens = make(map[string]reporter.TokenThreshold)
+		}
+		cfg.CronTokens[name] = reporter.TokenThreshold{Input: t.Input, Output: t.Output, Total: t.Total}
+	}
+	for name, owner := range settings.CronOwners {
+		if cfg.CronOwners == nil {
+			cfg.CronOwners = make(map[string]reporter.CronOwner)
+		}
+		cfg.CronOwners[name] = reporter.CronOwner{Team: owner.Team, Email: owner.Email, Channel: owner.Channel}
+	}
+	for name, e := range settings.CronExpectedCosts {
+		if e.Min < 0 || e.Max < 0 || (e.Min == 0 && e.Max == 0) || (e.Max > 0 && e.Max < e.Min) {
+			return Report{}, fmt.Errorf("expected cost for cron %s needs a min and/or max with min <= max", name)
+		}
+		if cfg.CronExpected == nil {
+			cfg.CronExpected = make(map[string]reporter.CostRange)
+		}
+		cfg.CronExpected[name] = reporter.CostRange{Min: e.Min, Max: e.Max}
+		if e.Note != "" {
+			cfg.Notes = append(slices.Clip(cfg.Notes), notes.Note{Cron: name, Text: e.Note})
+		}
+	}
+	for i, t := range settings.Topics {
+		if t.Topic == "" || len(t.Keywords) == 0 {
+			return Report{}, fmt.Errorf("topic rule %d needs a topic and keywords", i+1)
+		}
+		cfg.Topics = append(cfg.Topics, reporter.TopicRule{Topic: t.Topic, Keywords: t.Keywords})
+	}
+	for i, m := range settings.Maintenance {
+		w, err := maintenanceWindow(m)
+		if err != nil {
+			return Report{}, fmt.Errorf("maintenance window %d: %w", i+1, err)
+		}
+		cfg.Maintenance = append(cfg.Maintenance, w)
+	}
+	for agent, p := range settings.ModelPolicies {
+		if len(p.Allow) == 0 && len(p.Deny) == 0 {
+			return Report{}, fmt.Errorf("model policy for %s needs allow or deny", agent)
+		}
+		for _, pattern := range append(slices.Clone(p.Allow), p.Deny...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return Report{}, fmt.Errorf("invalid model policy pattern %q for %s: %w", pattern, agent, err)
+			}
+		}
+		if cfg.ModelPolicies == nil {
+			cfg.ModelPolicies = make(map[string]reporter.ModelPolicy)
+		}
+		cfg.ModelPolicies[agent] = reporter.ModelPolicy{Allow: p.Allow, Deny: p.Deny}
+	}
+	var err error
+	if cfg.Budgets, err = budgets(settings.Budgets, opts); err != nil {
+		return Report{}, err
+	}
+	for _, kpi := range settings.KPIs {
+		if !slices.Contains(reporter.KPIMetrics, kpi.Metric) {
+			return Report{}, fmt.Errorf("unknown KPI metric: %s (valid: %s)", kpi.Metric, strings.Join(reporter.KPIMetrics, ", "))
+		}
+		cfg.KPIs = append(cfg.KPIs, reporter.KPITarget{Metric: kpi.Metric, Min: kpi.Min, Max: kpi.Max})
+	}
+	for i, d := range settings.Detectors {
+		if d.Name == "" || len(d.Command) == 0 {
+			return Report{}, fmt.Errorf("detector %d needs a name and a command", i+1)
+		}
+		timeout := d.Timeout
+		if timeout == 0 {
+			timeout = config.DefaultDetectorTimeout
+		}
+		cfg.Detectors = append(cfg.Detectors, detect.NewExec(d.Name, d.Command, time.Duration(timeout)*time.Second))
+	}
+
+	var loaded loaded
+	if opts.Sessions != nil {
+		if err = reporter.ValidatePeriod(opts.Period); err != nil {
+			return Report{}, err
+		}
+		loaded.sessions = opts.Sessions
+	} else if loaded, err = load(ctx, opts, false); err != nil {
+		return Report{}, err
+	}
+	if opts.Files == nil && opts.Source == "" && opts.Ledger == "" && opts.Input == "" {
+		roots, err := opts.roots()
+		if err != nil {
+			return Report{}, err
+		}
+		if opts.IncludeIdle {
+			cfg.Agents = knownAgents(roots, settings.AgentNames, opts.scan(), opts.Agent)
+		}
+	}
+
+	r := reporter.New(loaded.sessions, cfg).Generate()
+	if loaded.partial {
+		r.Partial = reporter.NewCoverage(opts.MaxDuration.String(), loaded.coverage)
+	}
+	if r.Provenance, err = provenance(opts, settings); err != nil {
+		return Report{}, err
+	}
+	if opts.Seeded {
+		r.GeneratedAt = time.Unix(0, 0).UTC()
+		if !opts.AsOf.IsZero() {
+			r.GeneratedAt = opts.AsOf.UTC()
+		}
+		r.Provenance.Host = ""
+	}
+	scanned := 0
+	for _, c := range loaded.coverage {
+		scanned += c.Parsed
+	}
+	return Report{Report: r, Skipped: loaded.skipped, Scanned: scanned}, nil
+}
+
+// Load returns the sessions a report with opts would cover, before period
+// filtering, along with the agents and transcripts that were skipped.
+// Transcripts known to start before the period are not read. Sessions on
+// self-hosted models are priced at the configured rates.
+func Load(ctx context.Context, opts Options) ([]parser.Session, []error, error) {
+	loaded, err := load(ctx, opts, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return loaded.sessions, loaded.skipped, nil
+}
+
+// loaded is what Load read, with the share of transcripts parsed when
+// Options.MaxDuration cut parsing short.
+type loaded struct {
+	sessions []parser.Session
+	skipped  []error
+	coverage []reporter.AgentCoverage
+	partial  bool
+}
+
+// load reads the sessions opts select, with their transcripts' messages
+// if messages is set.
+func load(ctx context.Context, opts Options, messages bool) (loaded, error) {
+	if err := reporter.ValidatePeriod(opts.Period); err != nil {
+		return loaded{}, err
+	}
+	if opts.Files != nil && opts.Source != "" {
+		return loaded{}, fmt.Errorf("files cannot be combined with a store source")
+	}
+	if opts.Input != "" && (opts.Files != nil || opts.Source != "") {
+		return loaded{}, fmt.Errorf("session records cannot be combined with files or a store source")
+	}
+	if opts.Ledger != "" && (opts.Files != nil || opts.Source != "" || opts.Input != "") {
+		return loaded{}, fmt.Errorf("a session ledger cannot be combined with files, a store source or session records")
+	}
+	if opts.MaxDuration < 0 {
+		return loaded{}, fmt.Errorf("max duration must not be negative")
+	}
+	if !opts.Since.IsZero() && !opts.Until.IsZero() && !opts.Until.After(opts.Since) {
+		return loaded{}, fmt.Errorf("until (%s) must be after since (%s)", opts.Until.Format(time.RFC3339), opts.Since.Format(time.RFC3339))
+	}
+	var names map[string]string
+	if opts.Settings != nil {
+		names = opts.Settings.AgentNames
+	}
+	scan := opts.scan()
+	if err := scan.validate(); err != nil {
+		return loaded{}, err
+	}
+	if opts.MaxDuration > 0 {
+		scan.deadline = time.Now().Add(opts.MaxDuration)
+	}
+	scan.cache = opts.Cache
+	// Reports only need the sessions' totals, so only Load keeps messages.
+	scan.dropMessages = !messages
+
+	since := opts.Since
+	now := opts.AsOf.In(time.Local)
+	if opts.AsOf.IsZero() {
+		now = time.Now()
+	}
+	window := reporter.PeriodWindow
+	if opts.Compare || (opts.Settings != nil && len(opts.Settings.KPIs) > 0) {
+		// Comparisons and KPI trends need the previous period as well.
+		window = reporter.PreviousWindow
+	}
+	if from, _, err := window(opts.Period, now); err == nil {
+		if start, ok := budgetStart(opts, now); ok && start.Before(from) {
+			// Budgets count spend since the start of their day, week or
+			// month, whatever the period.
+			from = start
+		}
+		if start := reporter.ForecastStart(now); forecasts(opts) && start.Before(from) {
+			// So do forecasts, since the start of the month.
+			from = start
+		}
+		if from.After(since) {
+			since = from
+		}
+	}
+
+	var l loaded
+	switch {
+	case opts.Files != nil:
+		p := parser.New("")
+		p.AsOf = opts.AsOf
+		p.Since = since
+		p.Progress = opts.Progress
+		p.Deadline = scan.deadline
+		p.Cache = scan.cache
+		p.DropMessages = scan.dropMessages
+		l.sessions = renameAgents(p.ParseFiles(opts.Files, ""), names, opts.Agent)
+		l.skipped = p.Errors()
+		l.addCoverage(p, "", names)
+	case opts.Source != "":
+		var err error
+		if l.sessions, err = loadStored(ctx, opts.Source, opts.Tenant, opts.Host, names, opts.Agent); err != nil {
+			return loaded{}, err
+		}
+	case opts.Ledger != "":
+		var err error
+		if l.sessions, err = loadLedger(ctx, opts.Ledger, opts.Tenant, opts.Host, names, opts.Agent, since); err != nil {
+			return loaded{}, err
+		}
+	case opts.Input != "":
+		sessions, err := readRecords(opts.Input)
+		if err != nil {
+			return loaded{}, err
+		}
+		l.sessions = renameAgents(sessions, names, opts.Agent)
+	default:
+		roots, err := opts.roots()
+		if err != nil {
+			return loaded{}, err
+		}
+		if l, err = parseRoots(ctx, roots, names, scan, opts.Agent, since, opts.AsOf, opts.Progress); err != nil {
+			return loaded{}, err
+		}
+		if opts.History != "" && reporter.CalendarPeriod(opts.Period) {
+			if l.sessions, err = withHistory(ctx, opts.History, opts.Tenant, l.sessions, names, opts.Agent); err != nil {
+				return loaded{}, err
+			}
+		}
+	}
+
+	if opts.Settings != nil && len(opts.Settings.SelfHosted) > 0 {
+		rates, err := newSelfHostedRates(opts.Settings.SelfHosted)
+		if err != nil {
+			return loaded{}, err
+		}
+		rates.estimate(l.sessions)
+	}
+
+	if opts.Cron != "" || opts.Workspace != "" || !opts.Since.IsZero() || !opts.Until.IsZero() {
+		kept := l.sessions[:0]
+		for _, s := range l.sessions {
+			if opts.Cron != "" && (s.Type != parser.SessionTypeCron || s.CronName != opts.Cron) {
+				continue
+			}
+			if opts.Workspace != "" && s.Workspace != opts.Workspace {
+				continue
+			}
+			if !opts.Since.IsZero() && (s.StartedAt.IsZero() || s.StartedAt.Before(opts.Since)) {
+				continue
+			}
+			if !opts.Until.IsZero() && (s.StartedAt.IsZero() || !s.StartedAt.Before(opts.Until)) {
+				continue
+			}
+			kept = append(kept, s)
+		}
+		l.sessions = kept
+	}
+	return l, nil
+}
+
+// readRecords reads the session records in path, or standard input for
+// "-".
+func readRecords(path string) ([]parser.Session, error) {
+	if path == "-" {
+		sessions, err := parser.ReadRecords(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("stdin: %w", err)
+		}
+		return sessions, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session records: %w", err)
+	}
+	defer f.Close()
+	sessions, err := parser.ReadRecords(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return sessions, nil
+}
+
+// addCoverage adds the transcripts p parsed, and those its deadline left
+// unread, to the coverage of each agent by tenant and display name.
+func (l *loaded) addCoverage(p *parser.Parser, tenant string, names map[string]string) {
+	l.partial = l.partial || p.Partial()
+	for dir, c := range p.Coverage() {
+		agent := DisplayName(names, dir)
+		i := slices.IndexFunc(l.coverage, func(a reporter.AgentCoverage) bool { return a.Tenant == tenant && a.Agent == agent })
+		if i < 0 {
+			l.coverage = append(l.coverage, reporter.AgentCoverage{Tenant: tenant, Agent: agent})
+			i = len(l.coverage) - 1
+		}
+		l.coverage[i].Transcripts += c.Transcripts
+		l.coverage[i].Parsed += c.Parsed
+	}
+}
+
+// roots returns the configured agents directories, or the default one.
+func (o Options) roots() ([]Root, error) {
+	if len(o.Roots) > 0 {
+		return o.Roots, nil
+	}
+	dir, err := parser.ResolveAgentsDir("")
+	if err != nil {
+		return nil, err
+	}
+	return []Root{{Dir: dir}}, nil
+}
+
+// scan describes how agents directories are read: where agents are found
+// in them and which agents and transcripts are left out.
+type scan struct {
+	discovery    []string
+	filter       parser.Filter
+	deadline     time.Time     // stops parsing when set; see parser.Parser.Deadline
+	cache        *parser.Cache // see parser.Parser.Cache
+	dropMessages bool          // see parser.Parser.DropMessages
+}
+
+// scan returns the configured discovery patterns and filter.
+func (o Options) scan() scan {
+	if o.Settings == nil {
+		return scan{}
+	}
+	return scan{discovery: o.Settings.Discovery, filter: parser.Filter(o.Settings.Filter)}
+}
+
+func (s scan) validate() error {
+	if err := parser.ValidateDiscovery(s.discovery); err != nil {
+		return err
+	}
+	return s.filter.Validate()
+}
+
+// parser returns a parser for the agents directory dir.
+func (s scan) parser(dir string) *parser.Parser {
+	p := parser.New(dir)
+	p.Discovery = s.discovery
+	p.Filter = s.filter
+	p.Deadline = s.deadline
+	p.Cache = s.cache
+	p.DropMessages = s.dropMessages
+	return p
+}
+
+// parseRoots parses every agents root as of asOf (zero for now), tagging
+// sessions with their tenant and giving agents their display names.
+// Agents and transcripts left out by scan's filter are not read.
+// Transcripts known to start before since (zero for all time) are not
+// read. When several roots are read, an unreadable one is skipped rather
+// than failing the whole run.
+func parseRoots(ctx context.Context, roots []Root, names map[string]string, scan scan, agent string, since, asOf time.Time, progress parser.Progress) (loaded, error) {
+	var l loaded
+	for _, root := range roots {
+		if err := ctx.Err(); err != nil {
+			return loaded{}, err
+		}
+
+		p := scan.parser(root.Dir)
+		p.AsOf = asOf
+		p.Since = since
+		p.Progress = progress
+		var rootSessions []parser.Session
+		var err error
+		for _, dir := range AgentDirs(names, agent) {
+			var dirSessions []parser.Session
+			if dirSessions, err = p.ParseAll(dir); err != nil {
+				break
+			}
+			rootSessions = append(rootSessions, dirSessions...)
+		}
+		if err != nil {
+			if len(roots) == 1 {
+				return loaded{}, fmt.Errorf("failed to parse sessions: %w", err)
+			}
+			warn(progress, "failed to parse sessions for tenant %s: %v", root.Tenant, err)
+			l.skipped = append(l.skipped, fmt.Errorf("tenant %s: %w", root.Tenant, err))
+			continue
+		}
+		setAgentConfigs(p, rootSessions, progress)
+		for i := range rootSessions {
+			rootSessions[i].Tenant = root.Tenant
+		}
+		l.sessions = append(l.sessions, rootSessions...)
+		l.skipped = append(l.skipped, p.Errors()...)
+		l.addCoverage(p, root.Tenant, names)
+	}
+	l.sessions = renameAgents(l.sessions, names, agent)
+	return l, nil
+}
+
+// setAgentConfigs sets the model and spend caps configured for each
+// session's agent directory, before directories are grouped under display
+// names that may configure them differently. Unreadable configs are
+// reported and skipped.
+func setAgentConfigs(p *parser.Parser, sessions []parser.Session, progress parser.Progress) {
+	type agentConfig struct {
+		model string
+		caps  parser.SpendCaps
+	}
+	configs := make(map[string]agentConfig)
+	for i := range sessions {
+		dir := sessions[i].Agent
+		cfg, ok := configs[dir]
+		if !ok {
+			var err error
+			if cfg.model, err = p.DefaultModel(dir); err != nil {
+				warn(progress, "failed to read config for agent %s: %v", dir, err)
+			} else if cfg.caps, err = p.SpendCaps(dir); err != nil {
+				warn(progress, "failed to read config for agent %s: %v", dir, err)
+			}
+			configs[dir] = cfg
+		}
+		sessions[i].ConfiguredModel = cfg.model
+		sessions[i].SpendCap = cfg.caps.For(sessions[i])
+	}
+}
+
+// loadStored reads aggregates from the store at dsn and rebuilds them as
+// weighted sessions for the reporter.
+func loadStored(ctx context.Context, dsn, tenant, host string, names map[string]string, agent string) ([]parser.Session, error) {
+	s, err := store.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	// Display names are applied after the query, as stored rows may carry
+	// either directory or display names.
+	query := store.Query{Agent: agent, Tenant: tenant, Host: host}
+	if len(names) > 0 {
+		query.Agent = ""
+	}
+	rows, err := s.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query store: %w", err)
+	}
+	sessions, err := store.Sessions(rows)
+	if err != nil {
+		return nil, err
+	}
+	return renameAgents(sessions, names, agent), nil
+}
+
+// loadLedger reads the sessions started since since (if set) from the
+// session ledger of the store at dsn.
+func loadLedger(ctx context.Context, dsn, tenant, host string, names map[string]string, agent string, since time.Time) ([]parser.Session, error) {
+	s, err := store.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	ledger, ok := s.(store.SessionStore)
+	if !ok {
+		return nil, fmt.Errorf("store %s has no session ledger (use sqlite: or postgres:)", dsn)
+	}
+
+	// As with aggregates, display names are applied after the query.
+	query := store.SessionQuery{Since: since, Agent: agent, Tenant: tenant, Host: host}
+	if len(names) > 0 {
+		query.Agent = ""
+	}
+	sessions, err := ledger.Sessions(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session ledger: %w", err)
+	}
+	return renameAgents(sessions, names, agent), nil
+}
+
+// withHistory replaces parsed sessions with this host's stored aggregates
+// from the store at dsn on every day before the last stored one. The last
+// stored day may have been snapshotted part way through, so it and later
+// days come from transcripts.
+func withHistory(ctx context.Context, dsn, tenant string, parsed []parser.Session, names map[string]string, agent string) ([]parser.Session, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hostname: %w", err)
+	}
+	stored, err := loadStored(ctx, dsn, tenant, host, names, agent)
+	if err != nil {
+		return nil, err
+	}
+
+	var last time.Time
+	for _, s := range stored {
+		if s.StartedAt.After(last) {
+			last = s.StartedAt
+		}
+	}
+	if last.IsZero() {
+		return parsed, nil
+	}
+
+	var sessions []parser.Session
+	for _, s := range stored {
+		if s.StartedAt.Before(last) {
+			sessions = append(sessions, s)
+		}
+	}
+	for _, s := range parsed {
+		if !s.StartedAt.Before(last) {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions, nil
+}
+
+// warn reports a problem to progress when set, otherwise as a warning on
+// stderr.
+func warn(progress parser.Progress, format string, args ...any) {
+	if progress != nil {
+		progress.Warn(fmt.Errorf(format, args...))
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Warning: "+format+"\n", args...)
+}
+
+// maintenanceWindow converts a configured maintenance window.
+func maintenanceWindow(m config.MaintenanceWindow) (maintenance.Window, error) {
+	if m.Name == "" {
+		return maintenance.Window{}, errors.New("a name is required")
+	}
+	w := maintenance.Window{Name: m.Name, Rules: m.Rules, Crons: m.Crons}
+	if m.Timezone != "" {
+		loc, err := time.LoadLocation(m.Timezone)
+		if err != nil {
+			return maintenance.Window{}, err
+		}
+		w.Location = loc
+	}
+	var err error
+	if m.Schedule != "" {
+		if w.Schedule, err = maintenance.ParseSchedule(m.Schedule); err != nil {
+			return maintenance.Window{}, err
+		}
+	}
+	if m.Duration != "" {
+		if w.Duration, err = time.ParseDuration(m.Duration); err != nil {
+			return maintenance.Window{}, err
+		}
+	}
+	if m.From != "" {
+		if w.From, err = maintenance.ParseTime(m.From, w.Location, false); err != nil {
+			return maintenance.Window{}, err
+		}
+	}
+	if m.Until != "" {
+		if w.Until, err = maintenance.ParseTime(m.Until, w.Location, true); err != nil {
+			return maintenance.Window{}, err
+		}
+	}
+	return w, w.Validate()
+}
+
+// budgets lists the configured budgets a report with opts measures, by
+// period, then the total, agents and crons by name. With an agent or cron
+// filter, only that agent's or cron's budgets are kept: the others would
+// only count the filtered spend.
+func budgets(b config.Budgets, opts Options) ([]reporter.Budget, error) {
+	limits := map[string]config.BudgetLimits{
+		reporter.BudgetDaily:   {Agents: b.Agents, Crons: b.Crons, Total: b.Total},
+		reporter.BudgetWeekly:  b.Weekly,
+		reporter.BudgetMonthly: b.Monthly,
+	}
+	var result []reporter.Budget
+	for _, period := range reporter.BudgetPeriods {
+		l := limits[period]
+		var scoped []reporter.Budget
+		if l.Total != 0 {
+			scoped = append(scoped, reporter.Budget{Period: period, Scope: "total", Limit: l.Total})
+		}
+		for _, scope := range []struct {
+			name   string
+			limits map[string]float64
+		}{{"agent", l.Agents}, {"cron", l.Crons}} {
+			for _, name := range slices.Sorted(maps.Keys(scope.limits)) {
+				scoped = append(scoped, reporter.Budget{Period: period, Scope: scope.name, Name: name, Limit: scope.limits[name]})
+			}
+		}
+		for _, budget := range scoped {
+			if budget.Limit <= 0 {
+				return nil, fmt.Errorf("%s budget for %s must be positive", period, budgetName(budget))
+			}
+			switch {
+			case opts.Cron != "" && (budget.Scope != "cron" || budget.Name != opts.Cron):
+				continue
+			case opts.Workspace != "" && budget.Scope == "total":
+				continue
+			case opts.Agent != "" && (budget.Scope != "agent" || !sameAgent(budget.Name, opts.Agent, opts.Settings)):
+				continue
+			}
+			result = append(result, budget)
+		}
+	}
+	return result, nil
+}
+
+// budgetName describes the scope of a budget, e.g. "agent urza".
+func budgetName(b reporter.Budget) string {
+	if b.Scope == "total" {
+		return "the total"
+	}
+	return b.Scope + " " + b.Name
+}
+
+// sameAgent reports whether a display name is the agent selected by the
+// agent filter, which may be a directory or display name.
+func sameAgent(name, filter string, settings *config.Config) bool {
+	if name == filter {
+		return true
+	}
+	return settings != nil && settings.AgentNames[filter] == name
+}
+
+// forecasts reports whether a report with opts includes the forecast
+// section.
+func forecasts(opts Options) bool {
+	sections := opts.Sections
+	if sections == nil && opts.Settings != nil {
+		sections = opts.Settings.Sections
+	}
+	if sections != nil {
+		return slices.Contains(sections, reporter.SectionForecast)
+	}
+	return opts.Forecast || opts.Full
+}
+
+// budgetStart returns the start of the longest current budget period
+// configured in opts, if any.
+func budgetStart(opts Options, now time.Time) (time.Time, bool) {
+	if opts.Settings == nil {
+		return time.Time{}, false
+	}
+	budgets, err := budgets(opts.Settings.Budgets, opts)
+	if err != nil || len(budgets) == 0 {
+		return time.Time{}, false
+	}
+	var start time.Time
+	for _, b := range budgets {
+		if from := reporter.BudgetStart(b.Period, now); start.IsZero() || from.Before(start) {
+			start = from
+		}
+	}
+	return start, true
+}
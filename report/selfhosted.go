@@ -0,0 +1,70 @@
+package report
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/misty-step/costctl/config"
+	"github.com/misty-step/costctl/parser"
+)
+
+// selfHostedRates matches models to configured self-hosted rates: exact
+// names first, then globs in name order.
+type selfHostedRates struct {
+	exact map[string]config.SelfHostedRate
+	globs []string
+	rates map[string]config.SelfHostedRate
+}
+
+// newSelfHostedRates checks the configured model globs.
+func newSelfHostedRates(rates map[string]config.SelfHostedRate) (*selfHostedRates, error) {
+	r := &selfHostedRates{exact: make(map[string]config.SelfHostedRate), rates: rates}
+	for pattern, rate := range rates {
+		if !strings.ContainsAny(pattern, "*?[") {
+			r.exact[pattern] = rate
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid self_hosted model pattern %q: %w", pattern, err)
+		}
+		r.globs = append(r.globs, pattern)
+	}
+	sort.Strings(r.globs)
+	return r, nil
+}
+
+// rate returns the rate configured for model.
+func (r *selfHostedRates) rate(model string) (config.SelfHostedRate, bool) {
+	if rate, ok := r.exact[model]; ok {
+		return rate, true
+	}
+	for _, pattern := range r.globs {
+		if ok, _ := path.Match(pattern, model); ok {
+			return r.rates[pattern], true
+		}
+	}
+	return config.SelfHostedRate{}, false
+}
+
+// estimate prices sessions that report no cost on models with a configured
+// rate, marking them Estimated. Sessions rebuilt from aggregates have no
+// duration, so only token rates apply to them.
+func (r *selfHostedRates) estimate(sessions []parser.Session) {
+	for i := range sessions {
+		s := &sessions[i]
+		if s.Usage.CostTotal != 0 {
+			continue
+		}
+		rate, ok := r.rate(s.Usage.Model)
+		if !ok {
+			continue
+		}
+		u := &s.Usage
+		u.CostInput = float64(u.Input+u.CacheRead+u.CacheWrite) * rate.InputPrice / 1e6
+		u.CostOutput = float64(u.Output) * rate.OutputPrice / 1e6
+		u.CostTotal = u.CostInput + u.CostOutput + s.Duration.Hours()*rate.HourlyPrice
+		s.Estimated = true
+	}
+}
@@ -0,0 +1,180 @@
+package reporter
+
+import (
+	"sort"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// BudgetSuggestion proposes a daily budget for one agent or cron derived
+// from its daily spend.
+type BudgetSuggestion struct {
+	Scope      string  `json:"scope"` // agent or cron
+	Name       string  `json:"name"`
+	ActiveDays int     `json:"active_days"` // days with any spend
+	Percentile float64 `json:"percentile"`  // daily spend at the requested percentile
+	Suggested  float64 `json:"suggested"`   // Percentile plus headroom, in dollars per day
+}
+
+// BudgetOptions controls how budgets are derived.
+type BudgetOptions struct {
+	Days          int // days of history ending at Until
+	Until         time.Time
+	Percentile    float64 // e.g. 90
+	Headroom      float64 // fraction added to the percentile, e.g. 0.2
+	MinActiveDays int     // agents and crons active on fewer days get no suggestion
+}
+
+// SuggestBudgets computes daily budgets per agent and per cron as the given
+// percentile of their daily spend plus headroom. Days without spend count
+// as $0, so an agent busy one day a week is not budgeted as if it ran
+// daily; those whose percentile day has no spend get no suggestion. Agents
+// are listed first, each scope sorted by name.
+func SuggestBudgets(sessions []parser.Session, opts BudgetOptions) []BudgetSuggestion {
+	from := opts.Until.AddDate(0, 0, -opts.Days)
+	agents := make(map[string][]float64)
+	crons := make(map[string][]float64)
+	add := func(spend map[string][]float64, name string, day int, cost float64) {
+		if spend[name] == nil {
+			spend[name] = make([]float64, opts.Days)
+		}
+		spend[name][day] += cost
+	}
+	for _, s := range sessions {
+		if s.StartedAt.IsZero() || !s.StartedAt.After(from) || s.StartedAt.After(opts.Until) {
+			continue
+		}
+		day := min(int(s.StartedAt.Sub(from)/(24*time.Hour)), opts.Days-1)
+		add(agents, s.Agent, day, s.Usage.CostTotal)
+		if s.Type == parser.SessionTypeCron {
+			add(crons, s.CronName, day, s.Usage.CostTotal)
+		}
+	}
+
+	var result []BudgetSuggestion
+	for _, scope := range []struct {
+		name  string
+		spend map[string][]float64
+	}{{"agent", agents}, {"cron", crons}} {
+		var suggestions []BudgetSuggestion
+		for name, days := range scope.spend {
+			active := 0
+			for _, cost := range days {
+				if cost > 0 {
+					active++
+				}
+			}
+			if active == 0 || active < opts.MinActiveDays {
+				continue
+			}
+			p := percentile(days, opts.Percentile)
+			if p == 0 {
+				continue
+			}
+			suggestions = append(suggestions, BudgetSuggestion{
+				Scope:      scope.name,
+				Name:       name,
+				ActiveDays: active,
+				Percentile: p,
+				Suggested:  roundCents(p * (1 + opts.Headroom)),
+			})
+		}
+		sort.Slice(suggestions, func(i, j int) bool {
+			return suggestions[i].Name < suggestions[j].Name
+		})
+		result = append(result, suggestions...)
+	}
+	return result
+}
+
+// Budget periods.
+const (
+	BudgetDaily   = "daily"
+	BudgetWeekly  = "weekly"
+	BudgetMonthly = "monthly"
+)
+
+// BudgetPeriods lists the budget periods, shortest first.
+var BudgetPeriods = []string{BudgetDaily, BudgetWeekly, BudgetMonthly}
+
+// Budget is a spending limit in dollars over each calendar day, week (from
+// Monday) or month, for all agents together or for one agent or cron.
+type Budget struct {
+	Period string  // BudgetDaily, BudgetWeekly or BudgetMonthly
+	Scope  string  // total, agent or cron
+	Name   string  // agent or cron name; "" for the total
+	Limit  float64 // dollars
+}
+
+// BudgetStatus is a budget's consumption over its current period, the day,
+// week or month the report is as of.
+type BudgetStatus struct {
+	Period   string    `json:"period"`
+	Scope    string    `json:"scope"`
+	Name     string    `json:"name,omitempty"`
+	From     time.Time `json:"from"` // start of the current period
+	Limit    float64   `json:"limit"`
+	Spent    float64   `json:"spent"`
+	Percent  float64   `json:"percent"` // Spent as a percentage of Limit
+	Exceeded bool      `json:"exceeded,omitempty"`
+}
+
+// BudgetStart returns the start of the calendar day, week (from Monday) or
+// month containing now, in now's location.
+func BudgetStart(period string, now time.Time) time.Time {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case BudgetWeekly:
+		return midnight.AddDate(0, 0, -(int(now.Weekday())+6)%7)
+	case BudgetMonthly:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	default:
+		return midnight
+	}
+}
+
+// OverBudget reports whether any budget of the report was exceeded.
+func (r Report) OverBudget() bool {
+	for _, b := range r.Budgets {
+		if b.Exceeded {
+			return true
+		}
+	}
+	return false
+}
+
+// checkBudgets measures each configured budget's spend since the start of
+// its current period, in the order configured. Spend is that of sessions
+// starting in the period, whatever the report's period; the report's
+// filters (agent, cron, range) apply.
+func (r *Reporter) checkBudgets(now time.Time) []BudgetStatus {
+	byPeriod := make(map[string][]parser.Session)
+	var result []BudgetStatus
+	for _, b := range r.config.Budgets {
+		from := BudgetStart(b.Period, now)
+		sessions, ok := byPeriod[b.Period]
+		if !ok {
+			sessions = r.selectSessions(func(t time.Time) bool {
+				return !t.IsZero() && !t.Before(from) && !t.After(now)
+			})
+			byPeriod[b.Period] = sessions
+		}
+		status := BudgetStatus{Period: b.Period, Scope: b.Scope, Name: b.Name, From: from, Limit: b.Limit}
+		for _, s := range sessions {
+			switch {
+			case b.Scope == "agent" && s.Agent != b.Name:
+				continue
+			case b.Scope == "cron" && (s.Type != parser.SessionTypeCron || s.CronName != b.Name):
+				continue
+			}
+			status.Spent += s.Usage.CostTotal
+		}
+		if b.Limit > 0 {
+			status.Percent = status.Spent / b.Limit * 100
+		}
+		status.Exceeded = status.Spent > b.Limit
+		result = append(result, status)
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+package reporter
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestSuggestBudgets(t *testing.T) {
+	until := time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC)
+	var sessions []parser.Session
+	// urza runs its backup cron daily for $1, and $5 more on weekdays.
+	for day := 0; day < 10; day++ {
+		started := until.AddDate(0, 0, -day).Add(-time.Hour)
+		sessions = append(sessions, parser.Session{Agent: "urza", Type: parser.SessionTypeCron, CronName: "backup",
+			StartedAt: started, Usage: parser.Usage{CostTotal: 1}})
+		if day%7 < 5 {
+			sessions = append(sessions, parser.Session{Agent: "urza", StartedAt: started, Usage: parser.Usage{CostTotal: 5}})
+		}
+	}
+	sessions = append(sessions,
+		// A one-off, and spend outside the window.
+		parser.Session{Agent: "amos", StartedAt: until.Add(-time.Hour), Usage: parser.Usage{CostTotal: 3}},
+		parser.Session{Agent: "urza", StartedAt: until.AddDate(0, 0, -20), Usage: parser.Usage{CostTotal: 100}},
+	)
+
+	result := SuggestBudgets(sessions, BudgetOptions{Days: 10, Until: until, Percentile: 90, Headroom: 0.2, MinActiveDays: 3})
+	if len(result) != 2 {
+		t.Fatalf("expected urza and backup, got %+v", result)
+	}
+	if a := result[0]; a.Scope != "agent" || a.Name != "urza" || a.ActiveDays != 10 || a.Percentile != 6 || a.Suggested != 7.2 {
+		t.Errorf("unexpected agent budget: %+v", a)
+	}
+	if c := result[1]; c.Scope != "cron" || c.Name != "backup" || c.Percentile != 1 || c.Suggested != 1.2 {
+		t.Errorf("unexpected cron budget: %+v", c)
+	}
+
+	// Days without spend count: an agent busy every other day spends
+	// nothing on its median day.
+	var sparse []parser.Session
+	for day := 0; day < 10; day += 2 {
+		sparse = append(sparse, parser.Session{Agent: "amos", StartedAt: until.AddDate(0, 0, -day).Add(-time.Hour), Usage: parser.Usage{CostTotal: 2}})
+	}
+	if median := SuggestBudgets(sparse, BudgetOptions{Days: 10, Until: until, Percentile: 50}); len(median) != 0 {
+		t.Errorf("expected no budget at the median, got %+v", median)
+	}
+	if p60 := SuggestBudgets(sparse, BudgetOptions{Days: 10, Until: until, Percentile: 60}); len(p60) != 1 || p60[0].ActiveDays != 5 || p60[0].Suggested != 2 {
+		t.Errorf("expected a $2 budget at p60, got %+v", p60)
+	}
+}
+
+func TestBudgetStart(t *testing.T) {
+	// June 10, 2026 is a Wednesday.
+	now := time.Date(2026, 6, 10, 15, 30, 0, 0, time.UTC)
+	for period, want := range map[string]time.Time{
+		BudgetDaily:   time.Date(2026, 6, 10, 0, 0, 0, 0, time.UTC),
+		BudgetWeekly:  time.Date(2026, 6, 8, 0, 0, 0, 0, time.UTC),
+		BudgetMonthly: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		if got := BudgetStart(period, now); !got.Equal(want) {
+			t.Errorf("%s: expected %s, got %s", period, want, got)
+		}
+	}
+	// A Sunday belongs to the week that started the Monday before.
+	if got := BudgetStart(BudgetWeekly, time.Date(2026, 6, 14, 9, 0, 0, 0, time.UTC)); !got.Equal(time.Date(2026, 6, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected Sunday's week to start June 8, got %s", got)
+	}
+}
+
+func TestCheckBudgets(t *testing.T) {
+	localUTC(t)
+	now := time.Date(2026, 6, 10, 15, 0, 0, 0, time.UTC)
+	sessions := []parser.Session{
+		{Agent: "urza", StartedAt: now.Add(-time.Hour), Usage: parser.Usage{CostTotal: 4}},
+		{Agent: "urza", Type: parser.SessionTypeCron, CronName: "backup", StartedAt: now.Add(-2 * time.Hour), Usage: parser.Usage{CostTotal: 1}},
+		{Agent: "amos", StartedAt: now.AddDate(0, 0, -1), Usage: parser.Usage{CostTotal: 3}},
+		{Agent: "urza", StartedAt: now.AddDate(0, 0, -8), Usage: parser.Usage{CostTotal: 20}},
+		// Not yet started as of now.
+		{Agent: "urza", StartedAt: now.Add(time.Hour), Usage: parser.Usage{CostTotal: 50}},
+	}
+	r := New(sessions, Config{AsOf: now, Budgets: []Budget{
+		{Period: BudgetDaily, Scope: "total", Limit: 10},
+		{Period: BudgetDaily, Scope: "agent", Name: "urza", Limit: 4},
+		{Period: BudgetDaily, Scope: "cron", Name: "backup", Limit: 2},
+		{Period: BudgetWeekly, Scope: "agent", Name: "amos", Limit: 5},
+		{Period: BudgetMonthly, Scope: "total", Limit: 25},
+	}})
+	report := r.Generate()
+	want := []struct {
+		spent, percent float64
+		exceeded       bool
+	}{
+		{5, 50, false},
+		{5, 125, true},
+		{1, 50, false},
+		{3, 60, false},
+		{28, 112, true},
+	}
+	if len(report.Budgets) != len(want) {
+		t.Fatalf("expected %d budgets, got %+v", len(want), report.Budgets)
+	}
+	for i, w := range want {
+		b := report.Budgets[i]
+		if b.Spent != w.spent || math.Abs(b.Percent-w.percent) > 1e-9 || b.Exceeded != w.exceeded {
+			t.Errorf("budget %d (%s %s %s): expected %v spent, %v%%, exceeded %v, got %+v", i, b.Period, b.Scope, b.Name, w.spent, w.percent, w.exceeded, b)
+		}
+	}
+	if !report.OverBudget() {
+		t.Error("expected the report to be over budget")
+	}
+
+	if none := New(sessions, Config{AsOf: now}).Generate(); none.Budgets != nil || none.OverBudget() {
+		t.Errorf("expected no budgets without any configured, got %+v", none.Budgets)
+	}
+}
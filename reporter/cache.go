@@ -0,0 +1,120 @@
+package reporter
+
+import (
+	"sort"
+
+	"github.com/misty-step/costctl/catalog"
+	"github.com/misty-step/costctl/parser"
+)
+
+// CronCacheSummary compares a cron's cold-start runs (which mostly write
+// the prompt cache) with its warm runs (which mostly read it). Premium and
+// savings are relative to the same run priced without caching, estimated
+// from the catalog; they are zero when the model is not in the catalog.
+type CronCacheSummary struct {
+	CronName    string  `json:"cron_name"`
+	CronID      string  `json:"cron_id,omitempty"`
+	Agent       string  `json:"agent,omitempty"` // agent the cron runs on
+	ColdRuns    int     `json:"cold_runs"`
+	ColdAvgCost float64 `json:"cold_avg_cost"`
+	WarmRuns    int     `json:"warm_runs"`
+	WarmAvgCost float64 `json:"warm_avg_cost"`
+	ColdPremium float64 `json:"cold_premium"` // avg extra cost of a cold run vs uncached
+	WarmSavings float64 `json:"warm_savings"` // avg saving of a warm run vs uncached
+	PayoffRuns  float64 `json:"payoff_runs"`  // warm runs needed to recoup one cold start
+}
+
+type cacheTally struct {
+	runs     int
+	cost     float64
+	uncached float64
+	priced   bool
+}
+
+func (t *cacheTally) add(s parser.Session) {
+	t.runs++
+	t.cost += s.Usage.CostTotal
+	if uncached, ok := uncachedCost(s); ok {
+		t.uncached += uncached
+		t.priced = true
+	}
+}
+
+func (t *cacheTally) avg(v float64) float64 {
+	if t.runs == 0 {
+		return 0
+	}
+	return v / float64(t.runs)
+}
+
+// aggregateCronCache splits each cron's cached runs into cold (more cache
+// writes than reads) and warm, for crons that use prompt caching. Crons
+// sharing a name on different agents are tallied separately.
+func (r *Reporter) aggregateCronCache(sessions []parser.Session) []CronCacheSummary {
+	type tallies struct {
+		agent      string
+		cold, warm cacheTally
+	}
+	byCron := make(map[cronKey]*tallies)
+
+	for _, s := range individualSessions(sessions) {
+		if s.Type != parser.SessionTypeCron || s.Usage.CacheRead+s.Usage.CacheWrite == 0 {
+			continue
+		}
+		key := cronKey{name: s.CronName, id: s.CronID}
+		t, ok := byCron[key]
+		if !ok {
+			t = &tallies{agent: s.Agent}
+			byCron[key] = t
+		}
+		if s.Usage.CacheWrite > s.Usage.CacheRead {
+			t.cold.add(s)
+		} else {
+			t.warm.add(s)
+		}
+	}
+
+	result := make([]CronCacheSummary, 0, len(byCron))
+	for key, t := range byCron {
+		c := CronCacheSummary{
+			CronName:    key.name,
+			CronID:      key.id,
+			Agent:       t.agent,
+			ColdRuns:    t.cold.runs,
+			ColdAvgCost: t.cold.avg(t.cold.cost),
+			WarmRuns:    t.warm.runs,
+			WarmAvgCost: t.warm.avg(t.warm.cost),
+		}
+		if t.cold.priced {
+			c.ColdPremium = t.cold.avg(t.cold.cost - t.cold.uncached)
+		}
+		if t.warm.priced {
+			c.WarmSavings = t.warm.avg(t.warm.uncached - t.warm.cost)
+		}
+		if c.ColdPremium > 0 && c.WarmSavings > 0 {
+			c.PayoffRuns = c.ColdPremium / c.WarmSavings
+		}
+		result = append(result, c)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CronName != result[j].CronName {
+			return result[i].CronName < result[j].CronName
+		}
+		return result[i].CronID < result[j].CronID
+	})
+	return result
+}
+
+// uncachedCost estimates what a session would have cost had its cache
+// reads and writes been billed as plain input tokens.
+func uncachedCost(s parser.Session) (float64, bool) {
+	m, ok := catalog.Lookup(s.Usage.Model)
+	if !ok {
+		return 0, false
+	}
+	p := m.PriceAt(s.StartedAt)
+	cached := p.Cost(0, 0, s.Usage.CacheRead, s.Usage.CacheWrite)
+	asInput := p.Cost(s.Usage.CacheRead+s.Usage.CacheWrite, 0, 0, 0)
+	return s.Usage.CostTotal - cached + asInput, true
+}
@@ -0,0 +1,49 @@
+package reporter
+
+import (
+	"math"
+	"testing"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestAggregateCronCache(t *testing.T) {
+	// claude-sonnet-4-5: input $3, cache write $3.75, cache read $0.30 per MTok.
+	cold := parser.Usage{CacheWrite: 1_000_000, CostTotal: 3.75, Model: "claude-sonnet-4-5"}
+	warm := parser.Usage{CacheRead: 1_000_000, CostTotal: 0.30, Model: "claude-sonnet-4-5"}
+	sessions := []parser.Session{
+		{Type: parser.SessionTypeCron, CronName: "sync", Usage: cold},
+		{Type: parser.SessionTypeCron, CronName: "sync", Usage: warm},
+		{Type: parser.SessionTypeCron, CronName: "sync", Usage: warm},
+		{Type: parser.SessionTypeCron, CronName: "nocache", Usage: parser.Usage{CostTotal: 1.0}},
+		{Type: parser.SessionTypeInteractive, Usage: warm},
+	}
+
+	result := New(sessions, Config{}).aggregateCronCache(sessions)
+	if len(result) != 1 {
+		t.Fatalf("expected only the caching cron, got %+v", result)
+	}
+
+	c := result[0]
+	if c.ColdRuns != 1 || c.WarmRuns != 2 || c.ColdAvgCost != 3.75 || c.WarmAvgCost != 0.30 {
+		t.Errorf("unexpected run split: %+v", c)
+	}
+	// A cold run costs $0.75 more than uncached; a warm run saves $2.70, so a
+	// cold start pays for itself after 0.75/2.70 warm runs.
+	if math.Abs(c.ColdPremium-0.75) > 1e-9 || math.Abs(c.WarmSavings-2.70) > 1e-9 {
+		t.Errorf("unexpected premium/savings: %+v", c)
+	}
+	if math.Abs(c.PayoffRuns-0.75/2.70) > 1e-9 {
+		t.Errorf("expected payoff %f runs, got %f", 0.75/2.70, c.PayoffRuns)
+	}
+
+	// A cron of the same name on another agent is tallied on its own.
+	sessions = append(sessions,
+		parser.Session{Agent: "amos", Type: parser.SessionTypeCron, CronName: "sync", CronID: "amos-sync", Usage: cold},
+		parser.Session{Agent: "amos", Type: parser.SessionTypeCron, CronName: "sync", CronID: "amos-sync", Usage: cold})
+	result = New(sessions, Config{}).aggregateCronCache(sessions)
+	if len(result) != 2 || result[0].CronID != "" || result[0].ColdRuns != 1 || result[0].WarmRuns != 2 ||
+		result[1].CronID != "amos-sync" || result[1].Agent != "amos" || result[1].ColdRuns != 2 || result[1].WarmRuns != 0 {
+		t.Errorf("expected each sync cron's runs kept apart, got %+v", result)
+	}
+}
@@ -0,0 +1,99 @@
+package reporter
+
+import (
+	"sort"
+
+	"github.com/misty-step/costctl/catalog"
+	"github.com/misty-step/costctl/parser"
+)
+
+// CascadeSummary is the spend of one agent's sessions escalating mid-session
+// from one model to a pricier one, whether a provider fell back to it or the
+// agent switched up. Cost is what the requests made on To after the switch
+// were billed; ExtraCost is the part of it due to the escalation, estimated
+// as those requests at To's catalog rates less the same tokens at From's.
+type CascadeSummary struct {
+	Agent       string  `json:"agent"`
+	From        string  `json:"from"`
+	To          string  `json:"to"`
+	Sessions    int     `json:"sessions"`
+	Escalations int     `json:"escalations"`
+	Requests    int     `json:"requests"` // requests made on To
+	Cost        float64 `json:"cost"`
+	ExtraCost   float64 `json:"extra_cost"`
+}
+
+// aggregateByCascade summarizes model escalations by agent and model pair,
+// costliest escalation first. Each step of a cascade, such as haiku to
+// sonnet to opus, is priced against the model it replaced; switches back
+// to a cheaper model and models missing from the catalog are not counted.
+// Sessions rebuilt from stored aggregates carry no model switches.
+func (r *Reporter) aggregateByCascade(sessions []parser.Session) []CascadeSummary {
+	type key struct{ agent, from, to string }
+	byKey := make(map[key]*CascadeSummary)
+	for _, s := range individualSessions(sessions) {
+		agent := s.Agent
+		if s.Tenant != "" {
+			agent = s.Tenant + "/" + s.Agent
+		}
+		counted := make(map[key]bool)
+		for _, sw := range s.Switches {
+			extra, ok := escalationCost(sw)
+			if !ok {
+				continue
+			}
+			k := key{agent, sw.From, sw.To}
+			summary, ok := byKey[k]
+			if !ok {
+				summary = &CascadeSummary{Agent: agent, From: sw.From, To: sw.To}
+				byKey[k] = summary
+			}
+			if !counted[k] {
+				counted[k] = true
+				summary.Sessions++
+			}
+			summary.Escalations++
+			summary.Requests += sw.Requests
+			summary.Cost += sw.Cost
+			summary.ExtraCost += extra
+		}
+	}
+
+	result := make([]CascadeSummary, 0, len(byKey))
+	for _, summary := range byKey {
+		result = append(result, *summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ExtraCost != result[j].ExtraCost {
+			return result[i].ExtraCost > result[j].ExtraCost
+		}
+		if result[i].Agent != result[j].Agent {
+			return result[i].Agent < result[j].Agent
+		}
+		if result[i].From != result[j].From {
+			return result[i].From < result[j].From
+		}
+		return result[i].To < result[j].To
+	})
+	return result
+}
+
+// escalationCost reports whether a model switch moved to a model that is
+// more expensive for the same tokens, and the estimated extra cost of the
+// requests made after it.
+func escalationCost(sw parser.ModelSwitch) (float64, bool) {
+	from, ok := catalog.Lookup(sw.From)
+	if !ok {
+		return 0, false
+	}
+	to, ok := catalog.Lookup(sw.To)
+	if !ok || to.ID == from.ID {
+		return 0, false
+	}
+	extra := to.EstimateCostAt(sw.Timestamp, sw.Input, sw.Output, sw.CacheRead, sw.CacheWrite) -
+		from.EstimateCostAt(sw.Timestamp, sw.Input, sw.Output, sw.CacheRead, sw.CacheWrite)
+	if extra <= 0 {
+		return 0, false
+	}
+	return extra, true
+}
@@ -0,0 +1,49 @@
+package reporter
+
+import (
+	"math"
+	"testing"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestAggregateByCascade(t *testing.T) {
+	const haiku, sonnet, opus = "anthropic/claude-haiku-4-5", "anthropic/claude-sonnet-4-5", "anthropic/claude-opus-4-6"
+	sessions := []parser.Session{
+		{Agent: "urza", Switches: []parser.ModelSwitch{
+			{From: haiku, To: sonnet, Requests: 2, Input: 1_000_000, Cost: 3},
+			{From: sonnet, To: haiku, Requests: 1, Input: 1_000_000, Cost: 1}, // back down
+			{From: haiku, To: sonnet, Requests: 1, Output: 100_000, Cost: 1.5},
+		}},
+		{Agent: "urza", Switches: []parser.ModelSwitch{
+			{From: haiku, To: sonnet, Requests: 1, Input: 1_000_000, Cost: 3},
+			{From: sonnet, To: opus, Requests: 1, Input: 1_000_000, Cost: 5},
+		}},
+		{Agent: "amos", Tenant: "acme", Switches: []parser.ModelSwitch{
+			{From: "local/llama", To: opus, Requests: 1, Input: 1_000_000, Cost: 5}, // not in the catalog
+			{From: sonnet, To: "anthropic/claude-sonnet-4-5-20250929", Requests: 1, Input: 1_000_000, Cost: 3},
+		}},
+		{Agent: "kaylee"}, // no switches
+	}
+
+	byCascade := New(sessions, Config{Cascades: true}).Generate().ByCascade
+	if len(byCascade) != 2 {
+		t.Fatalf("expected 2 agent/model rows, got %+v", byCascade)
+	}
+
+	// haiku to sonnet: $2/M more input, $10/M more output.
+	up := byCascade[0]
+	if up.Agent != "urza" || up.From != haiku || up.To != sonnet {
+		t.Fatalf("expected the costliest escalation first, got %+v", up)
+	}
+	if up.Sessions != 2 || up.Requests != 4 || up.Cost != 7.5 || math.Abs(up.ExtraCost-5) > 1e-9 {
+		t.Errorf("unexpected haiku to sonnet totals: %+v", up)
+	}
+	if next := byCascade[1]; next.From != sonnet || next.To != opus || next.Sessions != 1 || math.Abs(next.ExtraCost-2) > 1e-9 {
+		t.Errorf("expected the cascade's second step priced against sonnet, got %+v", next)
+	}
+
+	if got := New(sessions, Config{}).Generate().ByCascade; got != nil {
+		t.Errorf("expected no cascade section without Cascades, got %+v", got)
+	}
+}
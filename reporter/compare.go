@@ -0,0 +1,98 @@
+package reporter
+
+import "time"
+
+// Change compares a row's values with the same window one period earlier.
+// Percent changes are nil when the earlier value is zero.
+type Change struct {
+	PreviousCost     float64  `json:"previous_cost"`
+	PreviousSessions int      `json:"previous_sessions"`
+	PreviousTokens   int      `json:"previous_tokens"`
+	CostPct          *float64 `json:"cost_change_pct,omitempty"`
+	SessionsPct      *float64 `json:"sessions_change_pct,omitempty"`
+	TokensPct        *float64 `json:"tokens_change_pct,omitempty"`
+}
+
+// Comparison is the window a report was compared with and the change in
+// its totals.
+type Comparison struct {
+	From  time.Time `json:"from"`
+	Until time.Time `json:"until"`
+	Change
+}
+
+// ComparablePeriods lists the named periods Config.Compare supports. Ranges
+// of calendar months are comparable too.
+var ComparablePeriods = []string{"today", "yesterday", "week", "month", "ytd"}
+
+// newChange compares current values with previous ones.
+func newChange(cost, prevCost float64, sessions, prevSessions, tokens, prevTokens int) *Change {
+	return &Change{
+		PreviousCost:     prevCost,
+		PreviousSessions: prevSessions,
+		PreviousTokens:   prevTokens,
+		CostPct:          percentChange(prevCost, cost),
+		SessionsPct:      percentChange(float64(prevSessions), float64(sessions)),
+		TokensPct:        percentChange(float64(prevTokens), float64(tokens)),
+	}
+}
+
+// percentChange is the change from before to after in percent, or nil
+// when before is zero.
+func percentChange(before, after float64) *float64 {
+	if before == 0 {
+		return nil
+	}
+	pct := (after - before) / before * 100
+	return &pct
+}
+
+// compare attaches to the report's totals and its agent, cron and model
+// rows their change since the same window one period earlier. Rows with
+// no spend in the report's period are not listed; see ComparePeriods for
+// a full attribution of the change.
+func (r *Reporter) compare(report *Report) {
+	from, until, err := PreviousWindow(r.config.Period, r.now())
+	if err != nil {
+		return
+	}
+	previous := r.window(from, until)
+
+	comparison := &Comparison{From: from, Until: until}
+	var prevCost float64
+	var prevSessions, prevTokens int
+	for _, s := range previous {
+		prevCost += s.Usage.CostTotal
+		prevSessions += s.Weight()
+		prevTokens += s.Usage.Total
+	}
+	comparison.Change = *newChange(report.TotalCost, prevCost, report.TotalSessions, prevSessions, report.TotalTokens, prevTokens)
+	report.Compared = comparison
+
+	agents := make(map[agentKey]AgentSummary)
+	for _, a := range agentDimension.Aggregate(previous) {
+		agents[agentKey{tenant: a.Tenant, agent: a.Agent}] = a
+	}
+	for i, a := range report.ByAgent {
+		prev := agents[agentKey{tenant: a.Tenant, agent: a.Agent}]
+		report.ByAgent[i].Change = newChange(a.TotalCost, prev.TotalCost, a.Sessions, prev.Sessions, a.TotalTokens, prev.TotalTokens)
+	}
+
+	crons := make(map[cronKey]CronSummary)
+	for _, c := range cronDimension.Aggregate(previous) {
+		crons[cronKey{name: c.CronName, id: c.CronID}] = c
+	}
+	for i, c := range report.ByCron {
+		prev := crons[cronKey{name: c.CronName, id: c.CronID}]
+		report.ByCron[i].Change = newChange(c.TotalCost, prev.TotalCost, c.Runs, prev.Runs, c.TotalTokens, prev.TotalTokens)
+	}
+
+	models := make(map[string]ModelSummary)
+	for _, m := range modelDimension.Aggregate(previous) {
+		models[m.Model] = m
+	}
+	for i, m := range report.ByModel {
+		prev := models[m.Model]
+		report.ByModel[i].Change = newChange(m.TotalCost, prev.TotalCost, m.Sessions, prev.Sessions, m.TotalTokens, prev.TotalTokens)
+	}
+}
@@ -0,0 +1,52 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestGenerateCompare(t *testing.T) {
+	now := time.Date(2026, 6, 12, 12, 0, 0, 0, time.UTC)
+	sessions := []parser.Session{
+		// The week before.
+		{Agent: "urza", Type: parser.SessionTypeCron, CronName: "backup", StartedAt: now.AddDate(0, 0, -10),
+			Usage: parser.Usage{CostTotal: 2, Total: 100, Model: "kimi"}},
+		// This week.
+		{Agent: "urza", Type: parser.SessionTypeCron, CronName: "backup", StartedAt: now.AddDate(0, 0, -2),
+			Usage: parser.Usage{CostTotal: 1, Total: 100, Model: "kimi"}},
+		{Agent: "amos", StartedAt: now.AddDate(0, 0, -1), Usage: parser.Usage{CostTotal: 4, Total: 300, Model: "opus"}},
+	}
+
+	report := New(sessions, Config{Period: "week", AsOf: now, Crons: true, Compare: true}).Generate()
+	if report.Compared == nil || report.Compared.PreviousCost != 2 || *report.Compared.CostPct != 150 {
+		t.Fatalf("expected totals compared, got %+v", report.Compared)
+	}
+	if !report.Compared.Until.Equal(now.AddDate(0, 0, -7)) {
+		t.Errorf("expected the previous week, got %v", report.Compared.Until)
+	}
+
+	for _, a := range report.ByAgent {
+		switch a.Agent {
+		case "urza":
+			if *a.Change.CostPct != -50 || *a.Change.TokensPct != 0 {
+				t.Errorf("unexpected urza change: %+v", a.Change)
+			}
+		case "amos":
+			if a.Change.PreviousCost != 0 || a.Change.CostPct != nil {
+				t.Errorf("expected no percent change from zero, got %+v", a.Change)
+			}
+		}
+	}
+	if c := report.ByCron[0].Change; c == nil || c.PreviousSessions != 1 || *c.SessionsPct != 0 {
+		t.Errorf("unexpected cron change: %+v", c)
+	}
+	if m := report.ByModel[1]; m.Model != "kimi" || m.Change.PreviousCost != 2 {
+		t.Errorf("unexpected model change: %+v", m)
+	}
+
+	if plain := New(sessions, Config{Period: "week", AsOf: now}).Generate(); plain.Compared != nil || plain.ByAgent[0].Change != nil {
+		t.Error("expected no comparison without Compare")
+	}
+}
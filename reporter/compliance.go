@@ -0,0 +1,116 @@
+package reporter
+
+import (
+	"path"
+	"sort"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// Reasons a session violates its agent's model policy.
+const (
+	ViolationDenied     = "denied"      // the model matches the deny list
+	ViolationNotAllowed = "not_allowed" // the model is missing from the allow list
+)
+
+// ModelPolicy restricts the models an agent may use. Entries are model
+// names or globs in path.Match syntax (anthropic/claude-opus-*). A model
+// matching Deny is never allowed; with Allow set, only models matching it
+// are.
+type ModelPolicy struct {
+	Allow []string
+	Deny  []string
+}
+
+// Check returns why model violates the policy, or "" if it complies.
+func (p ModelPolicy) Check(model string) string {
+	if matchesAny(p.Deny, model) {
+		return ViolationDenied
+	}
+	if len(p.Allow) > 0 && !matchesAny(p.Allow, model) {
+		return ViolationNotAllowed
+	}
+	return ""
+}
+
+func matchesAny(patterns []string, model string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, model); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// PolicyViolation is a session that ran on a model its agent's policy does
+// not allow. Sessions rebuilt from stored aggregates have no ID and may
+// stand for several sessions.
+type PolicyViolation struct {
+	Agent     string  `json:"agent"`
+	Tenant    string  `json:"tenant,omitempty"`
+	SessionID string  `json:"session_id,omitempty"`
+	Sessions  int     `json:"sessions"`
+	Date      string  `json:"date"`
+	Model     string  `json:"model"`
+	Reason    string  `json:"reason"` // ViolationDenied or ViolationNotAllowed
+	Cost      float64 `json:"cost"`
+	Tokens    int     `json:"tokens"`
+}
+
+// modelPolicy returns the policy for agent, falling back to "*".
+func (r *Reporter) modelPolicy(agent string) (ModelPolicy, bool) {
+	if p, ok := r.config.ModelPolicies[agent]; ok {
+		return p, true
+	}
+	p, ok := r.config.ModelPolicies["*"]
+	return p, ok
+}
+
+// checkCompliance lists the sessions that used a model their agent's
+// policy disallows, costliest first. Sessions without a known model are
+// not checked.
+func (r *Reporter) checkCompliance(sessions []parser.Session) []PolicyViolation {
+	var violations []PolicyViolation
+	for _, s := range sessions {
+		policy, ok := r.modelPolicy(s.Agent)
+		if !ok || s.Usage.Model == "" {
+			continue
+		}
+		reason := policy.Check(s.Usage.Model)
+		if reason == "" {
+			continue
+		}
+		v := PolicyViolation{
+			Agent:     s.Agent,
+			Tenant:    s.Tenant,
+			SessionID: s.ID,
+			Date:      s.StartedAt.Format("2006-01-02"),
+			Model:     s.Usage.Model,
+			Reason:    reason,
+			Cost:      s.Usage.CostTotal,
+			Tokens:    s.Usage.Total,
+			Sessions:  1,
+		}
+		if s.Count > 0 {
+			v.SessionID, v.Sessions = "", s.Count
+		}
+		violations = append(violations, v)
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		if a.Cost != b.Cost {
+			return a.Cost > b.Cost
+		}
+		if a.Agent != b.Agent {
+			return a.Agent < b.Agent
+		}
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.SessionID != b.SessionID {
+			return a.SessionID < b.SessionID
+		}
+		return a.Model < b.Model
+	})
+	return violations
+}
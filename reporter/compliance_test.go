@@ -0,0 +1,59 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestModelPolicyCheck(t *testing.T) {
+	policy := ModelPolicy{Allow: []string{"anthropic/claude-*"}, Deny: []string{"anthropic/claude-opus-*"}}
+	tests := map[string]string{
+		"anthropic/claude-sonnet-4-5": "",
+		"anthropic/claude-opus-4-6":   ViolationDenied,
+		"moonshotai/kimi-k2.5":        ViolationNotAllowed,
+	}
+	for model, want := range tests {
+		if got := policy.Check(model); got != want {
+			t.Errorf("Check(%s) = %q, want %q", model, got, want)
+		}
+	}
+	if got := (ModelPolicy{Deny: []string{"gpt-4o"}}).Check("moonshotai/kimi-k2.5"); got != "" {
+		t.Errorf("expected a deny-only policy to allow other models, got %q", got)
+	}
+}
+
+func TestGenerateCompliance(t *testing.T) {
+	day := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	sessions := []parser.Session{
+		{Agent: "urza", ID: "s1", StartedAt: day, Usage: parser.Usage{Model: "anthropic/claude-opus-4-6", CostTotal: 3, Total: 1000}},
+		{Agent: "urza", ID: "s2", StartedAt: day, Usage: parser.Usage{Model: "anthropic/claude-sonnet-4-5", CostTotal: 1, Total: 1000}},
+		{Agent: "amos", ID: "s3", StartedAt: day, Usage: parser.Usage{Model: "anthropic/claude-opus-4-6", CostTotal: 2, Total: 500}},
+		{Agent: "mishra", ID: "s4", StartedAt: day, Usage: parser.Usage{Model: "openai/gpt-4o", CostTotal: 5, Total: 800}},
+		{Agent: "mishra", StartedAt: day, Count: 4, Usage: parser.Usage{Model: "openai/gpt-4o", CostTotal: 1, Total: 300}},
+	}
+	policies := map[string]ModelPolicy{
+		"urza": {Deny: []string{"anthropic/claude-opus-*"}},
+		"*":    {Allow: []string{"anthropic/*"}},
+	}
+
+	report := New(sessions, Config{ModelPolicies: policies}).Generate()
+	if len(report.Compliance) != 3 {
+		t.Fatalf("expected 3 violations, got %+v", report.Compliance)
+	}
+	first := report.Compliance[0]
+	if first.Agent != "mishra" || first.SessionID != "s4" || first.Reason != ViolationNotAllowed || first.Cost != 5 || first.Date != "2026-10-01" {
+		t.Errorf("expected mishra's gpt-4o session first, got %+v", first)
+	}
+	if v := report.Compliance[1]; v.SessionID != "s1" || v.Reason != ViolationDenied {
+		t.Errorf("expected urza's denied opus session second, got %+v", v)
+	}
+	if v := report.Compliance[2]; v.SessionID != "" || v.Sessions != 4 {
+		t.Errorf("expected the aggregated row last without an ID, got %+v", v)
+	}
+
+	if report := New(sessions, Config{}).Generate(); report.Compliance != nil {
+		t.Errorf("expected no compliance section without policies, got %+v", report.Compliance)
+	}
+}
@@ -0,0 +1,52 @@
+package reporter
+
+import "sort"
+
+// Coverage marks a partial report: parsing stopped at a time limit before
+// every transcript due was read, so totals are understated.
+type Coverage struct {
+	MaxDuration string          `json:"max_duration"`
+	Transcripts int             `json:"transcripts"`
+	Parsed      int             `json:"parsed"`
+	Percent     float64         `json:"percent"`
+	ByAgent     []AgentCoverage `json:"by_agent"`
+}
+
+// AgentCoverage is the share of one agent's transcripts that were parsed.
+type AgentCoverage struct {
+	Tenant      string  `json:"tenant,omitempty"`
+	Agent       string  `json:"agent"`
+	Transcripts int     `json:"transcripts"`
+	Parsed      int     `json:"parsed"`
+	Percent     float64 `json:"percent"`
+}
+
+// NewCoverage totals per-agent coverage, listing the least covered agents
+// first.
+func NewCoverage(maxDuration string, agents []AgentCoverage) *Coverage {
+	c := &Coverage{MaxDuration: maxDuration, ByAgent: agents}
+	for i := range agents {
+		a := &agents[i]
+		a.Percent = coveragePercent(a.Parsed, a.Transcripts)
+		c.Transcripts += a.Transcripts
+		c.Parsed += a.Parsed
+	}
+	c.Percent = coveragePercent(c.Parsed, c.Transcripts)
+	sort.SliceStable(agents, func(i, j int) bool {
+		if agents[i].Percent != agents[j].Percent {
+			return agents[i].Percent < agents[j].Percent
+		}
+		if agents[i].Tenant != agents[j].Tenant {
+			return agents[i].Tenant < agents[j].Tenant
+		}
+		return agents[i].Agent < agents[j].Agent
+	})
+	return c
+}
+
+func coveragePercent(parsed, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(parsed) / float64(total) * 100
+}
@@ -0,0 +1,28 @@
+package reporter
+
+import "testing"
+
+func TestNewCoverage(t *testing.T) {
+	c := NewCoverage("30s", []AgentCoverage{
+		{Agent: "urza", Transcripts: 4, Parsed: 4},
+		{Agent: "amos", Transcripts: 4, Parsed: 1},
+		{Tenant: "acme", Agent: "pepper", Transcripts: 2, Parsed: 1},
+	})
+	if c.Transcripts != 10 || c.Parsed != 6 || c.Percent != 60 {
+		t.Errorf("expected 6 of 10 transcripts (60%%), got %d of %d (%v%%)", c.Parsed, c.Transcripts, c.Percent)
+	}
+	var order []string
+	for _, a := range c.ByAgent {
+		order = append(order, a.Agent)
+	}
+	if len(order) != 3 || order[0] != "amos" || order[1] != "pepper" || order[2] != "urza" {
+		t.Errorf("expected the least covered agents first, got %v", order)
+	}
+	if c.ByAgent[0].Percent != 25 {
+		t.Errorf("expected amos at 25%%, got %v", c.ByAgent[0].Percent)
+	}
+
+	if empty := NewCoverage("1s", nil); empty.Percent != 100 {
+		t.Errorf("expected nothing due to count as covered, got %v", empty.Percent)
+	}
+}
@@ -0,0 +1,78 @@
+package reporter
+
+import (
+	"sort"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// CronStatus is the operational view of one cron: its schedule, its most
+// recent run, and its spend over the last seven days compared with the
+// seven days before.
+type CronStatus struct {
+	CronName     string     `json:"cron_name"`
+	Schedule     string     `json:"schedule,omitempty"`
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"` // nil if the cron has no timed runs
+	LastRunCost  float64    `json:"last_run_cost"`
+	WeekRuns     int        `json:"week_runs"`
+	WeekCost     float64    `json:"week_cost"`
+	PrevWeekCost float64    `json:"prev_week_cost"`
+	Trend        string     `json:"trend"` // up, down or flat, week over week
+}
+
+// CronOverview lists every cron that has runs or a configured schedule,
+// costliest over the last seven days first. Crons that are scheduled but
+// never ran are included so they stand out.
+func CronOverview(sessions []parser.Session, schedules map[string]string, now time.Time) []CronStatus {
+	weekStart := now.AddDate(0, 0, -7)
+	prevStart := now.AddDate(0, 0, -14)
+
+	byCron := make(map[string]*CronStatus)
+	status := func(name string) *CronStatus {
+		c, ok := byCron[name]
+		if !ok {
+			c = &CronStatus{CronName: name, Schedule: schedules[name]}
+			byCron[name] = c
+		}
+		return c
+	}
+	for name := range schedules {
+		status(name)
+	}
+
+	for _, s := range individualSessions(sessions) {
+		if s.Type != parser.SessionTypeCron {
+			continue
+		}
+		c := status(s.CronName)
+		if s.StartedAt.IsZero() {
+			continue
+		}
+		if c.LastRunAt == nil || s.StartedAt.After(*c.LastRunAt) {
+			startedAt := s.StartedAt
+			c.LastRunAt = &startedAt
+			c.LastRunCost = s.Usage.CostTotal
+		}
+		switch {
+		case !s.StartedAt.Before(weekStart) && s.StartedAt.Before(now):
+			c.WeekRuns++
+			c.WeekCost += s.Usage.CostTotal
+		case !s.StartedAt.Before(prevStart) && s.StartedAt.Before(weekStart):
+			c.PrevWeekCost += s.Usage.CostTotal
+		}
+	}
+
+	result := make([]CronStatus, 0, len(byCron))
+	for _, c := range byCron {
+		c.Trend = trend(c.PrevWeekCost, c.WeekCost)
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].WeekCost != result[j].WeekCost {
+			return result[i].WeekCost > result[j].WeekCost
+		}
+		return result[i].CronName < result[j].CronName
+	})
+	return result
+}
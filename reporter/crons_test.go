@@ -0,0 +1,50 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestCronOverview(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	run := func(name string, daysAgo int, cost float64) parser.Session {
+		return parser.Session{
+			Type:      parser.SessionTypeCron,
+			CronName:  name,
+			StartedAt: now.AddDate(0, 0, -daysAgo),
+			Usage:     parser.Usage{CostTotal: cost},
+		}
+	}
+	sessions := []parser.Session{
+		run("sync", 1, 0.30),
+		run("sync", 3, 0.20),
+		run("sync", 10, 0.10),
+		run("digest", 9, 0.40),
+		{Type: parser.SessionTypeInteractive, StartedAt: now, Usage: parser.Usage{CostTotal: 5}},
+	}
+	schedules := map[string]string{"sync": "0 */6 * * *", "backup": "0 3 * * *"}
+
+	crons := CronOverview(sessions, schedules, now)
+	if len(crons) != 3 {
+		t.Fatalf("expected 3 crons, got %+v", crons)
+	}
+
+	sync := crons[0]
+	if sync.CronName != "sync" || sync.Schedule != "0 */6 * * *" || sync.WeekRuns != 2 ||
+		sync.WeekCost != 0.5 || sync.PrevWeekCost != 0.1 || sync.Trend != "up" {
+		t.Errorf("unexpected sync status: %+v", sync)
+	}
+	if sync.LastRunAt == nil || !sync.LastRunAt.Equal(now.AddDate(0, 0, -1)) || sync.LastRunCost != 0.30 {
+		t.Errorf("expected the latest sync run, got %v %v", sync.LastRunAt, sync.LastRunCost)
+	}
+
+	// Scheduled crons without runs are listed; ties sort by name.
+	if crons[1].CronName != "backup" || crons[1].LastRunAt != nil || crons[1].Trend != "flat" {
+		t.Errorf("expected backup with no runs, got %+v", crons[1])
+	}
+	if crons[2].CronName != "digest" || crons[2].WeekRuns != 0 || crons[2].Trend != "down" {
+		t.Errorf("expected digest trending down, got %+v", crons[2])
+	}
+}
@@ -0,0 +1,57 @@
+package reporter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// Detector finds anomalies the built-in rules do not, such as deviations
+// from a team's own forecast. See package detect for detectors run as
+// external programs.
+type Detector interface {
+	Name() string
+	Detect(input DetectorInput) ([]Anomaly, error)
+}
+
+// DetectorInput is what a Detector is given: the report's period and its
+// individual sessions, most expensive first. Sessions rebuilt from stored
+// aggregates are left out.
+type DetectorInput struct {
+	Period   string          `json:"period"`
+	AsOf     time.Time       `json:"as_of"`
+	Sessions []SessionDetail `json:"sessions"`
+}
+
+// runDetectors runs the configured detectors over sessions. A detector
+// that fails is reported as a detector_failed anomaly instead of failing
+// the report.
+func (r *Reporter) runDetectors(sessions []parser.Session) []Anomaly {
+	if len(r.config.Detectors) == 0 {
+		return nil
+	}
+	input := DetectorInput{
+		Period:   r.config.Period,
+		AsOf:     r.now().UTC(),
+		Sessions: r.getSessionDetails(sessions),
+	}
+	if input.Period == "" {
+		input.Period = "all"
+	}
+
+	var anomalies []Anomaly
+	for _, d := range r.config.Detectors {
+		found, err := d.Detect(input)
+		if err != nil {
+			anomalies = append(anomalies, Anomaly{
+				Type:        "detector_failed",
+				Description: fmt.Sprintf("Detector %s failed: %v", d.Name(), err),
+				Severity:    "error",
+			})
+			continue
+		}
+		anomalies = append(anomalies, found...)
+	}
+	return anomalies
+}
@@ -0,0 +1,74 @@
+package reporter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// fakeDetector flags the sessions it is given, or fails with err.
+type fakeDetector struct {
+	err   error
+	input DetectorInput
+}
+
+func (d *fakeDetector) Name() string { return "fake" }
+
+func (d *fakeDetector) Detect(input DetectorInput) ([]Anomaly, error) {
+	d.input = input
+	if d.err != nil {
+		return nil, d.err
+	}
+	var anomalies []Anomaly
+	for _, s := range input.Sessions {
+		anomalies = append(anomalies, Anomaly{Type: "forecast", Description: "over forecast", Severity: "warning", SessionID: s.ID, Agent: s.Agent})
+	}
+	return anomalies, nil
+}
+
+func TestGenerateDetectors(t *testing.T) {
+	now := time.Date(2026, 6, 12, 12, 0, 0, 0, time.UTC)
+	sessions := []parser.Session{
+		{ID: "r1", Agent: "urza", Type: parser.SessionTypeCron, CronName: "backup", StartedAt: now.Add(-time.Hour),
+			Usage: parser.Usage{CostTotal: 0.1}},
+		{ID: "old", Agent: "amos", StartedAt: now.AddDate(0, 0, -10), Usage: parser.Usage{CostTotal: 0.1}},
+	}
+	found := &fakeDetector{}
+	failing := &fakeDetector{err: errors.New("exit status 1")}
+	cfg := Config{
+		Period:     "week",
+		AsOf:       now,
+		Threshold:  10,
+		CronOwners: map[string]CronOwner{"backup": {Team: "infra"}},
+		Detectors:  []Detector{found, failing},
+	}
+
+	report := New(sessions, cfg).Generate()
+	if len(found.input.Sessions) != 1 || found.input.Period != "week" || !found.input.AsOf.Equal(now) {
+		t.Errorf("expected the week's session, got %+v", found.input)
+	}
+	var forecast, failed *Anomaly
+	for i, a := range report.Anomalies {
+		switch a.Type {
+		case "forecast":
+			forecast = &report.Anomalies[i]
+		case "detector_failed":
+			failed = &report.Anomalies[i]
+		}
+	}
+	if forecast == nil || forecast.Cron != "backup" || forecast.Owner == nil || forecast.Owner.Team != "infra" {
+		t.Errorf("expected the detector's anomaly tagged with its cron, got %+v", forecast)
+	}
+	if failed == nil || failed.Severity != "error" || failed.Description != "Detector fake failed: exit status 1" {
+		t.Errorf("expected the failure reported, got %+v", failed)
+	}
+
+	found.input = DetectorInput{}
+	cfg.Sections = []string{SectionSummary}
+	New(sessions, cfg).Generate()
+	if found.input.Sessions != nil {
+		t.Error("expected detectors not run without the anomalies section")
+	}
+}
@@ -0,0 +1,199 @@
+package reporter
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/misty-step/costctl/notes"
+	"github.com/misty-step/costctl/parser"
+)
+
+// Diff compares spend between two windows and attributes the change in
+// total cost to individual agents, crons, and models.
+type Diff struct {
+	Period      string         `json:"period"`
+	BeforeFrom  time.Time      `json:"before_from"`
+	BeforeUntil time.Time      `json:"before_until"`
+	AfterFrom   time.Time      `json:"after_from"`
+	AfterUntil  time.Time      `json:"after_until"`
+	BeforeCost  float64        `json:"before_cost"`
+	AfterCost   float64        `json:"after_cost"`
+	Delta       float64        `json:"delta"`
+	ByAgent     []Contribution `json:"by_agent"`
+	ByCron      []Contribution `json:"by_cron"`
+	ByModel     []Contribution `json:"by_model"`
+	// BeforeNotes and AfterNotes are the dated notes of each window, set
+	// by AttachNotes.
+	BeforeNotes []string `json:"before_notes,omitempty"`
+	AfterNotes  []string `json:"after_notes,omitempty"`
+}
+
+// AttachNotes sets the notes dated within each window of d, so the diff
+// shows what was recorded about the change next to it.
+func (d *Diff) AttachNotes(all []notes.Note) {
+	d.BeforeNotes = notes.InWindow(all, d.BeforeFrom, d.BeforeUntil)
+	d.AfterNotes = notes.InWindow(all, d.AfterFrom, d.AfterUntil)
+}
+
+// Contribution is one group's share of the change in total cost. The
+// contributions of a dimension sum to the total delta.
+type Contribution struct {
+	Key    string  `json:"key"`
+	Before float64 `json:"before"`
+	After  float64 `json:"after"`
+	Delta  float64 `json:"delta"`
+	Share  float64 `json:"share"` // Delta / total delta; 0 when the total is unchanged
+}
+
+// PeriodWindow returns the [from, until) window covered by a period as of
+// now, matching the report's --period semantics.
+func PeriodWindow(period string, now time.Time) (time.Time, time.Time, error) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case "today":
+		return midnight, now, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), midnight, nil
+	case "week":
+		return now.AddDate(0, 0, -7), now, nil
+	case "month":
+		return now.AddDate(0, -1, 0), now, nil
+	case PeriodYTD:
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location()), now, nil
+	}
+	if from, until, err := monthRange(period, now.Location()); err == nil {
+		return from, until, nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("invalid period for comparison: %s (valid: today, yesterday, week, month, ytd, YYYY-MM, YYYY-MM..YYYY-MM)", period)
+}
+
+// ComparePeriods compares the period as of now against the same window
+// one period earlier, so "today" is compared with yesterday up to the same
+// time of day and "week" with the seven days before that.
+func ComparePeriods(sessions []parser.Session, period string, now time.Time) (Diff, error) {
+	from, until, err := PeriodWindow(period, now)
+	if err != nil {
+		return Diff{}, err
+	}
+	prevFrom, prevUntil, err := PreviousWindow(period, now)
+	if err != nil {
+		return Diff{}, err
+	}
+
+	d := Compare(inWindow(sessions, prevFrom, prevUntil), inWindow(sessions, from, until))
+	d.Period = period
+	d.BeforeFrom, d.BeforeUntil = prevFrom, prevUntil
+	d.AfterFrom, d.AfterUntil = from, until
+	return d, nil
+}
+
+// PreviousWindow returns the window one period before the period as of
+// now, which ComparePeriods, KPI trends and Config.Compare compare with.
+// Year to date is compared with the same dates a year earlier, and a range
+// of months with as many months just before it.
+func PreviousWindow(period string, now time.Time) (time.Time, time.Time, error) {
+	if from, until, err := monthRange(period, now.Location()); err == nil {
+		months := (until.Year()-from.Year())*12 + int(until.Month()-from.Month())
+		return from.AddDate(0, -months, 0), from, nil
+	}
+	return PeriodWindow(period, previousPeriod(period, now))
+}
+
+// previousPeriod moves now back by one period.
+func previousPeriod(period string, now time.Time) time.Time {
+	switch period {
+	case "week":
+		return now.AddDate(0, 0, -7)
+	case "month":
+		return now.AddDate(0, -1, 0)
+	case PeriodYTD:
+		return now.AddDate(-1, 0, 0)
+	default:
+		return now.AddDate(0, 0, -1)
+	}
+}
+
+// Compare attributes the change in cost from before to after.
+func Compare(before, after []parser.Session) Diff {
+	var d Diff
+	for _, s := range before {
+		d.BeforeCost += s.Usage.CostTotal
+	}
+	for _, s := range after {
+		d.AfterCost += s.Usage.CostTotal
+	}
+	d.Delta = d.AfterCost - d.BeforeCost
+
+	d.ByAgent = contributions(before, after, d.Delta, func(s parser.Session) (string, bool) {
+		if s.Tenant != "" {
+			return s.Tenant + "/" + s.Agent, true
+		}
+		return s.Agent, true
+	})
+	d.ByCron = contributions(before, after, d.Delta, func(s parser.Session) (string, bool) {
+		if s.Type != parser.SessionTypeCron {
+			return "(not cron)", true
+		}
+		return s.CronName, true
+	})
+	d.ByModel = contributions(before, after, d.Delta, modelDimension.Key)
+	return d
+}
+
+// contributions computes each key's change in cost, largest absolute
+// contribution first.
+func contributions(before, after []parser.Session, total float64, key func(parser.Session) (string, bool)) []Contribution {
+	type keyCost struct {
+		key  string
+		cost float64
+	}
+	dim := Dimension[string, keyCost]{
+		Key: key,
+		Build: func(k string, acc *Accumulator) keyCost {
+			return keyCost{key: k, cost: acc.TotalCost}
+		},
+	}
+
+	byKey := make(map[string]*Contribution)
+	get := func(k string) *Contribution {
+		if _, ok := byKey[k]; !ok {
+			byKey[k] = &Contribution{Key: k}
+		}
+		return byKey[k]
+	}
+	for _, kc := range dim.Aggregate(before) {
+		get(kc.key).Before = kc.cost
+	}
+	for _, kc := range dim.Aggregate(after) {
+		get(kc.key).After = kc.cost
+	}
+
+	result := make([]Contribution, 0, len(byKey))
+	for _, c := range byKey {
+		c.Delta = c.After - c.Before
+		if total != 0 {
+			c.Share = c.Delta / total
+		}
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if a, b := math.Abs(result[i].Delta), math.Abs(result[j].Delta); a != b {
+			return a > b
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// inWindow returns the sessions that started within [from, until).
+func inWindow(sessions []parser.Session, from, until time.Time) []parser.Session {
+	var result []parser.Session
+	for _, s := range sessions {
+		if !s.StartedAt.IsZero() && !s.StartedAt.Before(from) && s.StartedAt.Before(until) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package reporter
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestCompare(t *testing.T) {
+	before := []parser.Session{
+		{Agent: "urza", Type: parser.SessionTypeCron, CronName: "sync", Usage: parser.Usage{CostTotal: 1.0, Model: "kimi"}},
+		{Agent: "amos", Type: parser.SessionTypeInteractive, Usage: parser.Usage{CostTotal: 2.0, Model: "opus"}},
+	}
+	after := []parser.Session{
+		{Agent: "urza", Type: parser.SessionTypeCron, CronName: "sync", Usage: parser.Usage{CostTotal: 4.0, Model: "kimi"}},
+		{Agent: "amos", Type: parser.SessionTypeInteractive, Usage: parser.Usage{CostTotal: 1.0, Model: "opus"}},
+		{Agent: "pepper", Type: parser.SessionTypeInteractive, Usage: parser.Usage{CostTotal: 1.0, Model: "kimi"}},
+	}
+
+	d := Compare(before, after)
+	if d.BeforeCost != 3.0 || d.AfterCost != 6.0 || d.Delta != 3.0 {
+		t.Fatalf("unexpected totals: %+v", d)
+	}
+
+	// Sorted by absolute contribution: urza +3, amos -1 and pepper +1 (by name).
+	expected := []struct {
+		key   string
+		delta float64
+	}{{"urza", 3}, {"amos", -1}, {"pepper", 1}}
+	if len(d.ByAgent) != len(expected) {
+		t.Fatalf("expected %d agents, got %+v", len(expected), d.ByAgent)
+	}
+	for i, e := range expected {
+		if d.ByAgent[i].Key != e.key || d.ByAgent[i].Delta != e.delta {
+			t.Errorf("ByAgent[%d] = %+v, want %s %+.0f", i, d.ByAgent[i], e.key, e.delta)
+		}
+	}
+	if d.ByAgent[0].Share != 1.0 {
+		t.Errorf("expected urza to explain 100%% of the delta, got %f", d.ByAgent[0].Share)
+	}
+
+	// Every waterfall sums to the total delta.
+	for name, steps := range map[string][]Contribution{"agent": d.ByAgent, "cron": d.ByCron, "model": d.ByModel} {
+		var sum float64
+		for _, c := range steps {
+			sum += c.Delta
+		}
+		if math.Abs(sum-d.Delta) > 1e-9 {
+			t.Errorf("%s contributions sum to %f, want %f", name, sum, d.Delta)
+		}
+	}
+}
+
+func TestComparePeriods(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	sessions := []parser.Session{
+		{Agent: "urza", StartedAt: now.Add(-2 * time.Hour), Usage: parser.Usage{CostTotal: 2.0}},                 // today
+		{Agent: "urza", StartedAt: now.Add(-26 * time.Hour), Usage: parser.Usage{CostTotal: 1.0}},                // yesterday, before this time
+		{Agent: "urza", StartedAt: now.Add(-23*time.Hour + 30*time.Minute), Usage: parser.Usage{CostTotal: 5.0}}, // yesterday, after this time
+	}
+
+	d, err := ComparePeriods(sessions, "today", now)
+	if err != nil {
+		t.Fatalf("ComparePeriods failed: %v", err)
+	}
+	if d.BeforeCost != 1.0 || d.AfterCost != 2.0 {
+		t.Errorf("expected today so far vs yesterday at this time, got %+v", d)
+	}
+
+	if _, err := ComparePeriods(sessions, "all", now); err == nil {
+		t.Error("expected error for period without a previous window")
+	}
+}
@@ -0,0 +1,362 @@
+package reporter
+
+import (
+	"sort"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// Accumulator folds the standard cost and token metrics of a group of
+// sessions. Dimensions project it into their summary type.
+type Accumulator struct {
+	Sessions     int
+	TotalCost    float64
+	MaxCost      float64
+	InputTokens  int
+	OutputTokens int
+	TotalTokens  int
+	CacheRead    int
+	CacheWrite   int
+}
+
+// Add folds a single session into the accumulator.
+func (a *Accumulator) Add(s parser.Session) {
+	a.Sessions += s.Weight()
+	a.TotalCost += s.Usage.CostTotal
+	if s.Usage.CostTotal > a.MaxCost {
+		a.MaxCost = s.Usage.CostTotal
+	}
+	a.InputTokens += s.Usage.Input
+	a.OutputTokens += s.Usage.Output
+	a.TotalTokens += s.Usage.Total
+	a.CacheRead += s.Usage.CacheRead
+	a.CacheWrite += s.Usage.CacheWrite
+}
+
+// AvgCost returns the mean cost per session.
+func (a *Accumulator) AvgCost() float64 {
+	if a.Sessions == 0 {
+		return 0
+	}
+	return a.TotalCost / float64(a.Sessions)
+}
+
+// Dimension groups sessions by a key and builds one summary per group.
+// K may be a struct to group by several fields at once.
+type Dimension[K comparable, S any] struct {
+	// Key extracts the grouping key; returning false excludes the session.
+	Key func(s parser.Session) (K, bool)
+	// Build projects the accumulated metrics for a key into a summary.
+	Build func(key K, acc *Accumulator) S
+	// Less orders the resulting summaries. It must be a total order, ties
+	// broken on names, so that reports are the same whatever order the
+	// sessions were read in.
+	Less func(a, b S) bool
+}
+
+// Aggregate groups the sessions and returns the ordered summaries.
+func (d Dimension[K, S]) Aggregate(sessions []parser.Session) []S {
+	agg := make(map[K]*Accumulator)
+	for _, s := range sessions {
+		key, ok := d.Key(s)
+		if !ok {
+			continue
+		}
+		acc, ok := agg[key]
+		if !ok {
+			acc = &Accumulator{}
+			agg[key] = acc
+		}
+		acc.Add(s)
+	}
+
+	result := make([]S, 0, len(agg))
+	for key, acc := range agg {
+		result = append(result, d.Build(key, acc))
+	}
+
+	if d.Less != nil {
+		sort.Slice(result, func(i, j int) bool {
+			return d.Less(result[i], result[j])
+		})
+	}
+
+	return result
+}
+
+var tenantDimension = Dimension[string, TenantSummary]{
+	Key: func(s parser.Session) (string, bool) { return s.Tenant, s.Tenant != "" },
+	Build: func(tenant string, acc *Accumulator) TenantSummary {
+		return TenantSummary{
+			Tenant:      tenant,
+			Sessions:    acc.Sessions,
+			TotalCost:   acc.TotalCost,
+			TotalTokens: acc.TotalTokens,
+		}
+	},
+	Less: func(a, b TenantSummary) bool {
+		if a.TotalCost != b.TotalCost {
+			return a.TotalCost > b.TotalCost
+		}
+		return a.Tenant < b.Tenant
+	},
+}
+
+// agentKey includes the tenant: agent names are only unique within one.
+type agentKey struct {
+	tenant string
+	agent  string
+}
+
+var agentDimension = Dimension[agentKey, AgentSummary]{
+	Key: func(s parser.Session) (agentKey, bool) {
+		return agentKey{tenant: s.Tenant, agent: s.Agent}, true
+	},
+	Build: func(key agentKey, acc *Accumulator) AgentSummary {
+		return AgentSummary{
+			Agent:        key.agent,
+			Tenant:       key.tenant,
+			Sessions:     acc.Sessions,
+			TotalCost:    acc.TotalCost,
+			InputTokens:  acc.InputTokens,
+			OutputTokens: acc.OutputTokens,
+			TotalTokens:  acc.TotalTokens,
+		}
+	},
+	Less: func(a, b AgentSummary) bool {
+		if a.TotalCost != b.TotalCost {
+			return a.TotalCost > b.TotalCost
+		}
+		if a.Tenant != b.Tenant {
+			return a.Tenant < b.Tenant
+		}
+		return a.Agent < b.Agent
+	},
+}
+
+// workspaceKey includes the tenant: each tenant's agents directory has
+// workspaces of its own.
+type workspaceKey struct {
+	tenant    string
+	workspace string
+}
+
+var workspaceDimension = Dimension[workspaceKey, WorkspaceSummary]{
+	Key: func(s parser.Session) (workspaceKey, bool) {
+		return workspaceKey{tenant: s.Tenant, workspace: s.Workspace}, s.Workspace != ""
+	},
+	Build: func(key workspaceKey, acc *Accumulator) WorkspaceSummary {
+		return WorkspaceSummary{
+			Workspace:   key.workspace,
+			Tenant:      key.tenant,
+			Sessions:    acc.Sessions,
+			TotalCost:   acc.TotalCost,
+			TotalTokens: acc.TotalTokens,
+		}
+	},
+	Less: func(a, b WorkspaceSummary) bool {
+		if a.TotalCost != b.TotalCost {
+			return a.TotalCost > b.TotalCost
+		}
+		if a.Tenant != b.Tenant {
+			return a.Tenant < b.Tenant
+		}
+		return a.Workspace < b.Workspace
+	},
+}
+
+// sessionTypeOrder fixes the display order: interactive, cron, subagent.
+var sessionTypeOrder = map[parser.SessionType]int{
+	parser.SessionTypeInteractive: 0,
+	parser.SessionTypeCron:        1,
+	parser.SessionTypeSubagent:    2,
+}
+
+var sessionTypeDimension = Dimension[parser.SessionType, SessionTypeSummary]{
+	Key: func(s parser.Session) (parser.SessionType, bool) { return s.Type, true },
+	Build: func(t parser.SessionType, acc *Accumulator) SessionTypeSummary {
+		return SessionTypeSummary{
+			Type:        t,
+			Sessions:    acc.Sessions,
+			TotalCost:   acc.TotalCost,
+			TotalTokens: acc.TotalTokens,
+		}
+	},
+	Less: func(a, b SessionTypeSummary) bool {
+		if sessionTypeOrder[a.Type] != sessionTypeOrder[b.Type] {
+			return sessionTypeOrder[a.Type] < sessionTypeOrder[b.Type]
+		}
+		return a.Type < b.Type
+	},
+}
+
+type cronKey struct {
+	name string
+	id   string
+}
+
+var cronDimension = Dimension[cronKey, CronSummary]{
+	Key: func(s parser.Session) (cronKey, bool) {
+		return cronKey{name: s.CronName, id: s.CronID}, s.Type == parser.SessionTypeCron
+	},
+	Build: func(key cronKey, acc *Accumulator) CronSummary {
+		return CronSummary{
+			CronName:    key.name,
+			CronID:      key.id,
+			Runs:        acc.Sessions,
+			TotalCost:   acc.TotalCost,
+			AvgCost:     acc.AvgCost(),
+			MaxCost:     acc.MaxCost,
+			TotalTokens: acc.TotalTokens,
+		}
+	},
+	Less: func(a, b CronSummary) bool {
+		if a.TotalCost != b.TotalCost {
+			return a.TotalCost > b.TotalCost
+		}
+		if a.CronName != b.CronName {
+			return a.CronName < b.CronName
+		}
+		return a.CronID < b.CronID
+	},
+}
+
+type cronSlotKey struct {
+	cron  cronKey
+	agent string
+	slot  string
+}
+
+// cronSlotDimension groups cron runs by cron and by slotOf, the scheduled
+// slot each run belongs to.
+func cronSlotDimension(slotOf func(s parser.Session) string) Dimension[cronSlotKey, CronSlotSummary] {
+	return Dimension[cronSlotKey, CronSlotSummary]{
+		Key: func(s parser.Session) (cronSlotKey, bool) {
+			if s.Type != parser.SessionTypeCron || s.StartedAt.IsZero() {
+				return cronSlotKey{}, false
+			}
+			return cronSlotKey{cron: cronKey{name: s.CronName, id: s.CronID}, agent: s.Agent, slot: slotOf(s)}, true
+		},
+		Build: func(key cronSlotKey, acc *Accumulator) CronSlotSummary {
+			return CronSlotSummary{
+				CronName:  key.cron.name,
+				CronID:    key.cron.id,
+				Agent:     key.agent,
+				Slot:      key.slot,
+				Runs:      acc.Sessions,
+				TotalCost: acc.TotalCost,
+				AvgCost:   acc.AvgCost(),
+				MaxCost:   acc.MaxCost,
+			}
+		},
+		Less: func(a, b CronSlotSummary) bool {
+			if a.CronName != b.CronName {
+				return a.CronName < b.CronName
+			}
+			if a.Slot != b.Slot {
+				return a.Slot < b.Slot
+			}
+			if a.Agent != b.Agent {
+				return a.Agent < b.Agent
+			}
+			return a.CronID < b.CronID
+		},
+	}
+}
+
+var modelDimension = Dimension[string, ModelSummary]{
+	Key: func(s parser.Session) (string, bool) {
+		if s.Usage.Model == "" {
+			return "unknown", true
+		}
+		return s.Usage.Model, true
+	},
+	Build: func(model string, acc *Accumulator) ModelSummary {
+		return ModelSummary{
+			Model:        model,
+			Sessions:     acc.Sessions,
+			TotalCost:    acc.TotalCost,
+			InputTokens:  acc.InputTokens,
+			OutputTokens: acc.OutputTokens,
+			TotalTokens:  acc.TotalTokens,
+		}
+	},
+	Less: func(a, b ModelSummary) bool {
+		if a.TotalCost != b.TotalCost {
+			return a.TotalCost > b.TotalCost
+		}
+		return a.Model < b.Model
+	},
+}
+
+var dayDimension = Dimension[string, DaySummary]{
+	Key: func(s parser.Session) (string, bool) {
+		if s.StartedAt.IsZero() {
+			return "", false
+		}
+		return s.StartedAt.Format("2006-01-02"), true
+	},
+	Build: func(date string, acc *Accumulator) DaySummary {
+		return DaySummary{
+			Date:        date,
+			Sessions:    acc.Sessions,
+			TotalCost:   acc.TotalCost,
+			TotalTokens: acc.TotalTokens,
+		}
+	},
+	Less: func(a, b DaySummary) bool { return a.Date < b.Date },
+}
+
+var monthDimension = Dimension[string, MonthSummary]{
+	Key: func(s parser.Session) (string, bool) {
+		if s.StartedAt.IsZero() {
+			return "", false
+		}
+		return s.StartedAt.Format(monthLayout), true
+	},
+	Build: func(month string, acc *Accumulator) MonthSummary {
+		return MonthSummary{
+			Month:       month,
+			Sessions:    acc.Sessions,
+			TotalCost:   acc.TotalCost,
+			TotalTokens: acc.TotalTokens,
+		}
+	},
+	Less: func(a, b MonthSummary) bool { return a.Month < b.Month },
+}
+
+type agentDayKey struct {
+	date string
+	agentKey
+}
+
+var agentDayDimension = Dimension[agentDayKey, AgentDaySummary]{
+	Key: func(s parser.Session) (agentDayKey, bool) {
+		if s.StartedAt.IsZero() {
+			return agentDayKey{}, false
+		}
+		return agentDayKey{
+			date:     s.StartedAt.Format("2006-01-02"),
+			agentKey: agentKey{tenant: s.Tenant, agent: s.Agent},
+		}, true
+	},
+	Build: func(key agentDayKey, acc *Accumulator) AgentDaySummary {
+		return AgentDaySummary{
+			Date:        key.date,
+			Agent:       key.agent,
+			Tenant:      key.tenant,
+			Sessions:    acc.Sessions,
+			TotalCost:   acc.TotalCost,
+			TotalTokens: acc.TotalTokens,
+		}
+	},
+	Less: func(a, b AgentDaySummary) bool {
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Tenant != b.Tenant {
+			return a.Tenant < b.Tenant
+		}
+		return a.Agent < b.Agent
+	},
+}
@@ -0,0 +1,101 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestDimensionMultiKey(t *testing.T) {
+	type agentModel struct {
+		agent string
+		model string
+	}
+	type row struct {
+		Agent    string
+		Model    string
+		Sessions int
+		Cost     float64
+	}
+
+	dim := Dimension[agentModel, row]{
+		Key: func(s parser.Session) (agentModel, bool) {
+			return agentModel{agent: s.Agent, model: s.Usage.Model}, true
+		},
+		Build: func(k agentModel, acc *Accumulator) row {
+			return row{Agent: k.agent, Model: k.model, Sessions: acc.Sessions, Cost: acc.TotalCost}
+		},
+		Less: func(a, b row) bool { return a.Cost > b.Cost },
+	}
+
+	sessions := []parser.Session{
+		{Agent: "urza", Usage: parser.Usage{CostTotal: 1.0, Model: "kimi"}},
+		{Agent: "urza", Usage: parser.Usage{CostTotal: 2.0, Model: "opus"}},
+		{Agent: "urza", Usage: parser.Usage{CostTotal: 0.5, Model: "kimi"}},
+		{Agent: "amos", Usage: parser.Usage{CostTotal: 0.25, Model: "kimi"}},
+	}
+
+	result := dim.Aggregate(sessions)
+	if len(result) != 3 {
+		t.Fatalf("expected 3 groups, got %d", len(result))
+	}
+	if result[0].Model != "opus" || result[0].Cost != 2.0 {
+		t.Errorf("expected urza/opus first at 2.0, got %+v", result[0])
+	}
+	if result[1].Agent != "urza" || result[1].Model != "kimi" || result[1].Sessions != 2 {
+		t.Errorf("expected urza/kimi with 2 sessions second, got %+v", result[1])
+	}
+}
+
+func TestDimensionExcludesSessions(t *testing.T) {
+	dim := Dimension[string, int]{
+		Key: func(s parser.Session) (string, bool) { return s.Agent, s.Agent != "" },
+		Build: func(_ string, acc *Accumulator) int {
+			return acc.Sessions
+		},
+	}
+
+	result := dim.Aggregate([]parser.Session{{Agent: "urza"}, {}, {Agent: "urza"}})
+	if len(result) != 1 || result[0] != 2 {
+		t.Errorf("expected one group with 2 sessions, got %v", result)
+	}
+}
+
+func TestAccumulator(t *testing.T) {
+	var acc Accumulator
+	acc.Add(parser.Session{Usage: parser.Usage{CostTotal: 1.0, Input: 10, Output: 5, Total: 15, CacheRead: 100}})
+	acc.Add(parser.Session{Usage: parser.Usage{CostTotal: 3.0, Input: 20, Output: 10, Total: 30, CacheWrite: 50}})
+
+	if acc.Sessions != 2 || acc.TotalCost != 4.0 || acc.MaxCost != 3.0 {
+		t.Errorf("unexpected cost metrics: %+v", acc)
+	}
+	if acc.AvgCost() != 2.0 {
+		t.Errorf("expected avg cost 2.0, got %f", acc.AvgCost())
+	}
+	if acc.TotalTokens != 45 || acc.CacheRead != 100 || acc.CacheWrite != 50 {
+		t.Errorf("unexpected token metrics: %+v", acc)
+	}
+}
+
+func TestAgentDayDimension(t *testing.T) {
+	day1 := time.Date(2026, 2, 10, 9, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	result := agentDayDimension.Aggregate([]parser.Session{
+		{Agent: "urza", StartedAt: day2, Usage: parser.Usage{CostTotal: 1.0}},
+		{Agent: "urza", StartedAt: day1, Usage: parser.Usage{CostTotal: 2.0}},
+		{Agent: "urza", StartedAt: day1, Usage: parser.Usage{CostTotal: 0.5}},
+		{Agent: "urza", Tenant: "acme", StartedAt: day1, Usage: parser.Usage{CostTotal: 4.0}},
+		{Agent: "urza"}, // no timestamp
+	})
+
+	if len(result) != 3 {
+		t.Fatalf("expected 3 agent-days, got %+v", result)
+	}
+	if result[0].Date != "2026-02-10" || result[0].Tenant != "" || result[0].TotalCost != 2.5 {
+		t.Errorf("unexpected first row: %+v", result[0])
+	}
+	if result[1].Tenant != "acme" || result[2].Date != "2026-02-11" {
+		t.Errorf("unexpected ordering: %+v", result)
+	}
+}
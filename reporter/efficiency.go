@@ -0,0 +1,87 @@
+package reporter
+
+import (
+	"sort"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// ModelEfficiency weighs what a model costs per message against how long
+// it takes per message, to compare models on both at once. Seconds per
+// message is session time (first to last assistant message) over the
+// session's messages, so it covers the model's latency and the tool calls
+// between its turns alike.
+type ModelEfficiency struct {
+	Model             string  `json:"model"`
+	Sessions          int     `json:"sessions"`
+	Messages          int     `json:"messages"`
+	TotalCost         float64 `json:"total_cost"`
+	CostPerMessage    float64 `json:"cost_per_message"`
+	SecondsPerMessage float64 `json:"seconds_per_message"`
+	// Frontier is set when no other model is both cheaper and faster per
+	// message: the models worth choosing between. The rest are dominated.
+	Frontier bool `json:"frontier"`
+}
+
+// aggregateEfficiency computes the cost and time per message of each
+// model, cheapest first. Only sessions with messages spanning some time
+// count: single-message sessions have no duration, and sessions rebuilt
+// from stored aggregates have neither.
+func (r *Reporter) aggregateEfficiency(sessions []parser.Session) []ModelEfficiency {
+	type totals struct {
+		sessions, messages int
+		cost               float64
+		duration           time.Duration
+	}
+	byModel := make(map[string]*totals)
+	for _, s := range individualSessions(sessions) {
+		if s.Usage.Model == "" || s.Turns() == 0 || s.Duration <= 0 {
+			continue
+		}
+		t, ok := byModel[s.Usage.Model]
+		if !ok {
+			t = &totals{}
+			byModel[s.Usage.Model] = t
+		}
+		t.sessions++
+		t.messages += s.Turns()
+		t.cost += s.Usage.CostTotal
+		t.duration += s.Duration
+	}
+
+	result := make([]ModelEfficiency, 0, len(byModel))
+	for model, t := range byModel {
+		result = append(result, ModelEfficiency{
+			Model:             model,
+			Sessions:          t.sessions,
+			Messages:          t.messages,
+			TotalCost:         t.cost,
+			CostPerMessage:    t.cost / float64(t.messages),
+			SecondsPerMessage: t.duration.Seconds() / float64(t.messages),
+		})
+	}
+	for i := range result {
+		result[i].Frontier = true
+		for j := range result {
+			if i != j && dominates(result[j], result[i]) {
+				result[i].Frontier = false
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CostPerMessage != result[j].CostPerMessage {
+			return result[i].CostPerMessage < result[j].CostPerMessage
+		}
+		return result[i].Model < result[j].Model
+	})
+	return result
+}
+
+// dominates reports whether a is at least as cheap and as fast as b per
+// message, and strictly better on one of them.
+func dominates(a, b ModelEfficiency) bool {
+	return a.CostPerMessage <= b.CostPerMessage && a.SecondsPerMessage <= b.SecondsPerMessage &&
+		(a.CostPerMessage < b.CostPerMessage || a.SecondsPerMessage < b.SecondsPerMessage)
+}
@@ -0,0 +1,61 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestAggregateEfficiency(t *testing.T) {
+	now := time.Date(2026, 6, 10, 12, 0, 0, 0, time.UTC)
+	session := func(model string, cost float64, messages int, duration time.Duration) parser.Session {
+		return parser.Session{
+			Agent: "urza", StartedAt: now, Duration: duration, MessageCount: messages,
+			Usage: parser.Usage{Model: model, CostTotal: cost},
+		}
+	}
+	sessions := []parser.Session{
+		session("opus", 0.25, 10, 100*time.Second),
+		session("opus", 0.25, 10, 300*time.Second),
+		session("kimi", 0.05, 10, 200*time.Second),
+		session("gpt", 0.5, 10, 400*time.Second),
+		session("gpt", 9, 1, 0), // no duration
+		{Agent: "urza", StartedAt: now, Count: 3, Usage: parser.Usage{Model: "gpt", CostTotal: 5}}, // aggregated
+	}
+	r := New(sessions, Config{Period: "all", Full: true})
+	got := r.Generate().Efficiency
+
+	want := []ModelEfficiency{
+		{Model: "kimi", Sessions: 1, Messages: 10, TotalCost: 0.05, CostPerMessage: 0.005, SecondsPerMessage: 20, Frontier: true},
+		{Model: "opus", Sessions: 2, Messages: 20, TotalCost: 0.5, CostPerMessage: 0.025, SecondsPerMessage: 20},
+		{Model: "gpt", Sessions: 1, Messages: 10, TotalCost: 0.5, CostPerMessage: 0.05, SecondsPerMessage: 40},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d models, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("model %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestEfficiencyFrontier(t *testing.T) {
+	models := []ModelEfficiency{
+		{Model: "cheap", CostPerMessage: 0.01, SecondsPerMessage: 30},
+		{Model: "fast", CostPerMessage: 0.05, SecondsPerMessage: 5},
+		{Model: "middle", CostPerMessage: 0.03, SecondsPerMessage: 10},
+		{Model: "worse", CostPerMessage: 0.04, SecondsPerMessage: 10},
+	}
+	for _, tt := range []struct {
+		a, b int
+		want bool
+	}{
+		{2, 3, true}, {3, 2, false}, {0, 1, false}, {1, 0, false}, {2, 2, false},
+	} {
+		if got := dominates(models[tt.a], models[tt.b]); got != tt.want {
+			t.Errorf("%s dominates %s = %v, want %v", models[tt.a].Model, models[tt.b].Model, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package reporter
+
+import (
+	"sort"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// ErrorSummary counts failed model requests (rate limits, overloaded
+// providers, server errors) for one agent and model, with the cost billed
+// for them. Retried failures were followed by another request, so their
+// cost was spent twice for the same work.
+type ErrorSummary struct {
+	Agent       string  `json:"agent"`
+	Model       string  `json:"model"`
+	Errors      int     `json:"errors"`
+	Retried     int     `json:"retried"`
+	RateLimited int     `json:"rate_limited"`
+	Overloaded  int     `json:"overloaded"`
+	Cost        float64 `json:"cost"`         // billed for failed requests
+	RetriedCost float64 `json:"retried_cost"` // billed for failed requests that were retried
+	Tokens      int     `json:"tokens"`
+}
+
+// aggregateByError summarizes failed requests by agent and model, costliest
+// first. Sessions rebuilt from stored aggregates carry no request errors.
+func (r *Reporter) aggregateByError(sessions []parser.Session) []ErrorSummary {
+	type key struct{ agent, model string }
+	byKey := make(map[key]*ErrorSummary)
+	for _, s := range individualSessions(sessions) {
+		agent := s.Agent
+		if s.Tenant != "" {
+			agent = s.Tenant + "/" + s.Agent
+		}
+		for _, e := range s.Errors {
+			model := e.Model
+			if model == "" {
+				model = "unknown"
+			}
+			k := key{agent, model}
+			summary, ok := byKey[k]
+			if !ok {
+				summary = &ErrorSummary{Agent: agent, Model: model}
+				byKey[k] = summary
+			}
+			summary.Errors++
+			summary.Cost += e.Cost
+			summary.Tokens += e.Tokens
+			if e.Retried {
+				summary.Retried++
+				summary.RetriedCost += e.Cost
+			}
+			switch e.Kind {
+			case parser.ErrorRateLimit:
+				summary.RateLimited++
+			case parser.ErrorOverloaded:
+				summary.Overloaded++
+			}
+		}
+	}
+
+	result := make([]ErrorSummary, 0, len(byKey))
+	for _, summary := range byKey {
+		result = append(result, *summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Cost != result[j].Cost {
+			return result[i].Cost > result[j].Cost
+		}
+		if result[i].Errors != result[j].Errors {
+			return result[i].Errors > result[j].Errors
+		}
+		if result[i].Agent != result[j].Agent {
+			return result[i].Agent < result[j].Agent
+		}
+		return result[i].Model < result[j].Model
+	})
+	return result
+}
@@ -0,0 +1,47 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestAggregateByError(t *testing.T) {
+	sessions := []parser.Session{
+		{Agent: "urza", Errors: []parser.RequestError{
+			{Model: "sonnet", Kind: parser.ErrorRateLimit, Retried: true},
+			{Model: "sonnet", Kind: parser.ErrorOverloaded, Cost: 0.04, Tokens: 900, Retried: true},
+			{Model: "sonnet", Kind: parser.ErrorServer, Cost: 0.01},
+		}},
+		{Agent: "urza", Errors: []parser.RequestError{
+			{Model: "opus", Kind: parser.ErrorOverloaded, Cost: 0.20, Retried: true},
+		}},
+		{Agent: "amos", Tenant: "acme", Errors: []parser.RequestError{
+			{Kind: parser.ErrorOther},
+		}},
+		{Agent: "kaylee"}, // no errors
+	}
+
+	byError := New(sessions, Config{Errors: true}).Generate().ByError
+	if len(byError) != 3 {
+		t.Fatalf("expected 3 agent/model rows, got %+v", byError)
+	}
+
+	if opus := byError[0]; opus.Model != "opus" || opus.Cost != 0.20 || opus.RetriedCost != 0.20 || opus.Overloaded != 1 {
+		t.Errorf("expected the costliest row first, got %+v", opus)
+	}
+	sonnet := byError[1]
+	if sonnet.Agent != "urza" || sonnet.Errors != 3 || sonnet.Retried != 2 || sonnet.RateLimited != 1 || sonnet.Overloaded != 1 {
+		t.Errorf("unexpected sonnet counts: %+v", sonnet)
+	}
+	if sonnet.Cost != 0.05 || sonnet.RetriedCost != 0.04 || sonnet.Tokens != 900 {
+		t.Errorf("unexpected sonnet cost: %+v", sonnet)
+	}
+	if other := byError[2]; other.Agent != "acme/amos" || other.Model != "unknown" {
+		t.Errorf("expected the tenant-qualified agent and unknown model, got %+v", other)
+	}
+
+	if got := New(sessions, Config{}).Generate().ByError; got != nil {
+		t.Errorf("expected no error section without Errors, got %+v", got)
+	}
+}
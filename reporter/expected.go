@@ -0,0 +1,53 @@
+package reporter
+
+import "github.com/misty-step/costctl/parser"
+
+// Deviations of a cron's average run cost from its expected range.
+const (
+	DeviationAbove = "above"
+	DeviationBelow = "below"
+)
+
+// CostRange is what a single cron run is expected to cost, in dollars. A
+// zero bound is open.
+type CostRange struct {
+	Min float64 `json:"min,omitempty"`
+	Max float64 `json:"max,omitempty"`
+}
+
+// String formats the range as "$0.15-$0.25", ">=$0.15" or "<=$0.25".
+func (c CostRange) String() string {
+	switch {
+	case c.Min > 0 && c.Max > 0:
+		return parser.FormatCost(c.Min) + "-" + parser.FormatCost(c.Max)
+	case c.Max > 0:
+		return "<=" + parser.FormatCost(c.Max)
+	default:
+		return ">=" + parser.FormatCost(c.Min)
+	}
+}
+
+// Deviation returns DeviationAbove or DeviationBelow when cost falls
+// outside the range, and "" when it is within.
+func (c CostRange) Deviation(cost float64) string {
+	if c.Max > 0 && cost > c.Max {
+		return DeviationAbove
+	}
+	if cost < c.Min {
+		return DeviationBelow
+	}
+	return ""
+}
+
+// expectCronCosts attaches each cron's expected run cost, if configured,
+// and flags crons whose average run falls outside it.
+func (r *Reporter) expectCronCosts(crons []CronSummary) {
+	for i := range crons {
+		expected, ok := r.config.CronExpected[crons[i].CronName]
+		if !ok {
+			continue
+		}
+		crons[i].Expected = &expected
+		crons[i].Deviation = expected.Deviation(crons[i].AvgCost)
+	}
+}
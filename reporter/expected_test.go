@@ -0,0 +1,57 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestCostRange(t *testing.T) {
+	tests := []struct {
+		r         CostRange
+		cost      float64
+		deviation string
+		text      string
+	}{
+		{CostRange{Min: 0.15, Max: 0.25}, 0.2, "", "$0.15-$0.25"},
+		{CostRange{Min: 0.15, Max: 0.25}, 0.3, DeviationAbove, "$0.15-$0.25"},
+		{CostRange{Min: 0.15, Max: 0.25}, 0.1, DeviationBelow, "$0.15-$0.25"},
+		{CostRange{Max: 0.25}, 0, "", "<=$0.25"},
+		{CostRange{Min: 0.15}, 5, "", ">=$0.15"},
+	}
+	for _, tt := range tests {
+		if got := tt.r.Deviation(tt.cost); got != tt.deviation {
+			t.Errorf("%v deviation of %.2f = %q, want %q", tt.r, tt.cost, got, tt.deviation)
+		}
+		if got := tt.r.String(); got != tt.text {
+			t.Errorf("String() = %q, want %q", got, tt.text)
+		}
+	}
+}
+
+func TestCronExpectedCosts(t *testing.T) {
+	sessions := []parser.Session{
+		{Type: parser.SessionTypeCron, CronName: "sync", Agent: "urza", ID: "run1", Usage: parser.Usage{CostTotal: 0.4}},
+		{Type: parser.SessionTypeCron, CronName: "sync", Agent: "urza", ID: "run2", Usage: parser.Usage{CostTotal: 0.6}},
+		{Type: parser.SessionTypeCron, CronName: "digest", Agent: "urza", ID: "run3", Usage: parser.Usage{CostTotal: 0.2}},
+		{Type: parser.SessionTypeCron, CronName: "backup", Agent: "urza", ID: "run4", Usage: parser.Usage{CostTotal: 0.1}},
+	}
+	r := New(sessions, Config{Crons: true, CronExpected: map[string]CostRange{
+		"sync":   {Min: 0.15, Max: 0.25},
+		"digest": {Max: 0.25},
+	}})
+
+	byName := make(map[string]CronSummary)
+	for _, c := range r.Generate().ByCron {
+		byName[c.CronName] = c
+	}
+	if c := byName["sync"]; c.Expected == nil || c.Deviation != DeviationAbove {
+		t.Errorf("expected sync's $0.50 average flagged above its range, got %+v", c)
+	}
+	if c := byName["digest"]; c.Expected == nil || c.Deviation != "" {
+		t.Errorf("expected digest within its range, got %+v", c)
+	}
+	if c := byName["backup"]; c.Expected != nil || c.Deviation != "" {
+		t.Errorf("expected backup without an expectation, got %+v", c)
+	}
+}
@@ -0,0 +1,263 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// HostReport is the report of one host in a fleet.
+type HostReport struct {
+	Host   string
+	Report Report
+}
+
+// FleetReport rolls the reports of several hosts up into one view for
+// platform teams: spend per host, each agent's spend across hosts, the
+// costliest crons fleet-wide, and anomalies.
+type FleetReport struct {
+	GeneratedAt   time.Time      `json:"generated_at"`
+	Period        string         `json:"period"`
+	TotalCost     float64        `json:"total_cost"`
+	TotalTokens   int            `json:"total_tokens"`
+	TotalSessions int            `json:"total_sessions"`
+	Hosts         []HostSummary  `json:"hosts"`
+	ByAgent       []FleetAgent   `json:"by_agent"`
+	TopCrons      []FleetCron    `json:"top_crons,omitempty"`
+	Anomalies     []FleetAnomaly `json:"anomalies,omitempty"`
+}
+
+// HostSummary is one host's totals. Share is its fraction of fleet spend.
+type HostSummary struct {
+	Host        string  `json:"host"`
+	Agents      int     `json:"agents"`
+	Sessions    int     `json:"sessions"`
+	TotalCost   float64 `json:"total_cost"`
+	TotalTokens int     `json:"total_tokens"`
+	Share       float64 `json:"share"`
+	Anomalies   int     `json:"anomalies"`
+}
+
+// FleetAgent is an agent's spend on each host, in the order of
+// FleetReport.Hosts.
+type FleetAgent struct {
+	Agent     string    `json:"agent"`
+	ByHost    []float64 `json:"by_host"`
+	TotalCost float64   `json:"total_cost"`
+}
+
+// FleetCron is a cron's spend across the hosts it runs on.
+type FleetCron struct {
+	CronName  string   `json:"cron_name"`
+	Hosts     []string `json:"hosts"`
+	Runs      int      `json:"runs"`
+	TotalCost float64  `json:"total_cost"`
+	AvgCost   float64  `json:"avg_cost"`
+}
+
+// FleetAnomaly is an anomaly concerning one host: either reported by the
+// host itself, or found by comparing it with the rest of the fleet
+// (host_outlier and cron_divergence).
+type FleetAnomaly struct {
+	Host string `json:"host"`
+	Anomaly
+}
+
+const (
+	// hostOutlierFactor flags hosts spending more than this multiple of
+	// the median host, in fleets of at least hostOutlierMinHosts.
+	hostOutlierFactor   = 2.0
+	hostOutlierMinHosts = 3
+	// cronDivergenceFactor flags a cron whose runs on one host average
+	// this multiple of its runs elsewhere, for averages above
+	// cronDivergenceMinCost.
+	cronDivergenceFactor  = 2.0
+	cronDivergenceMinCost = 0.01
+)
+
+// Rollup combines host reports into a fleet report. The costliest top
+// crons are kept (all when top is 0). Anomalies are the hosts' own, except
+// info-level ones, plus fleet-level host_outlier and cron_divergence
+// anomalies comparing hosts with each other.
+func Rollup(hosts []HostReport, top int) FleetReport {
+	fleet := FleetReport{GeneratedAt: time.Now().UTC()}
+	sorted := append([]HostReport(nil), hosts...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Report.TotalCost != sorted[j].Report.TotalCost {
+			return sorted[i].Report.TotalCost > sorted[j].Report.TotalCost
+		}
+		return sorted[i].Host < sorted[j].Host
+	})
+
+	agents := make(map[string]*FleetAgent)
+	type cronTally struct {
+		FleetCron
+		byHost map[string]CronSummary
+	}
+	crons := make(map[string]*cronTally)
+	for i, h := range sorted {
+		r := h.Report
+		if fleet.Period == "" {
+			fleet.Period = r.Period
+		}
+		fleet.TotalCost += r.TotalCost
+		fleet.TotalTokens += r.TotalTokens
+		fleet.TotalSessions += r.TotalSessions
+		fleet.Hosts = append(fleet.Hosts, HostSummary{
+			Host:        h.Host,
+			Agents:      len(r.ByAgent),
+			Sessions:    r.TotalSessions,
+			TotalCost:   r.TotalCost,
+			TotalTokens: r.TotalTokens,
+			Anomalies:   len(r.Anomalies),
+		})
+
+		for _, a := range r.ByAgent {
+			name := a.Agent
+			if a.Tenant != "" {
+				name = a.Tenant + "/" + a.Agent
+			}
+			agent, ok := agents[name]
+			if !ok {
+				agent = &FleetAgent{Agent: name, ByHost: make([]float64, len(sorted))}
+				agents[name] = agent
+			}
+			agent.ByHost[i] += a.TotalCost
+			agent.TotalCost += a.TotalCost
+		}
+
+		for _, c := range r.ByCron {
+			cron, ok := crons[c.CronName]
+			if !ok {
+				cron = &cronTally{FleetCron: FleetCron{CronName: c.CronName}, byHost: make(map[string]CronSummary)}
+				crons[c.CronName] = cron
+			}
+			// Agents on one host may each run a cron of the same name.
+			onHost, ok := cron.byHost[h.Host]
+			if !ok {
+				cron.Hosts = append(cron.Hosts, h.Host)
+				onHost.Owner = c.Owner
+			}
+			onHost.Runs += c.Runs
+			onHost.TotalCost += c.TotalCost
+			cron.byHost[h.Host] = onHost
+			cron.Runs += c.Runs
+			cron.TotalCost += c.TotalCost
+		}
+
+		for _, a := range r.Anomalies {
+			if a.Severity != "info" {
+				fleet.Anomalies = append(fleet.Anomalies, FleetAnomaly{Host: h.Host, Anomaly: a})
+			}
+		}
+	}
+	for i := range fleet.Hosts {
+		if fleet.TotalCost > 0 {
+			fleet.Hosts[i].Share = fleet.Hosts[i].TotalCost / fleet.TotalCost
+		}
+	}
+
+	for _, a := range agents {
+		fleet.ByAgent = append(fleet.ByAgent, *a)
+	}
+	sort.Slice(fleet.ByAgent, func(i, j int) bool {
+		if fleet.ByAgent[i].TotalCost != fleet.ByAgent[j].TotalCost {
+			return fleet.ByAgent[i].TotalCost > fleet.ByAgent[j].TotalCost
+		}
+		return fleet.ByAgent[i].Agent < fleet.ByAgent[j].Agent
+	})
+
+	var divergent []FleetAnomaly
+	for _, c := range crons {
+		if c.Runs > 0 {
+			c.AvgCost = c.TotalCost / float64(c.Runs)
+		}
+		fleet.TopCrons = append(fleet.TopCrons, c.FleetCron)
+		divergent = append(divergent, cronDivergence(c.CronName, c.byHost)...)
+	}
+	sort.Slice(fleet.TopCrons, func(i, j int) bool {
+		if fleet.TopCrons[i].TotalCost != fleet.TopCrons[j].TotalCost {
+			return fleet.TopCrons[i].TotalCost > fleet.TopCrons[j].TotalCost
+		}
+		return fleet.TopCrons[i].CronName < fleet.TopCrons[j].CronName
+	})
+	if top > 0 && len(fleet.TopCrons) > top {
+		fleet.TopCrons = fleet.TopCrons[:top]
+	}
+
+	sort.Slice(divergent, func(i, j int) bool {
+		if divergent[i].Cost != divergent[j].Cost {
+			return divergent[i].Cost > divergent[j].Cost
+		}
+		return divergent[i].Cron+"\x00"+divergent[i].Host < divergent[j].Cron+"\x00"+divergent[j].Host
+	})
+	fleet.Anomalies = append(append(hostOutliers(fleet.Hosts), divergent...), fleet.Anomalies...)
+	return fleet
+}
+
+// hostOutliers flags hosts spending far more than the median host.
+func hostOutliers(hosts []HostSummary) []FleetAnomaly {
+	if len(hosts) < hostOutlierMinHosts {
+		return nil
+	}
+	// Hosts are sorted by cost, highest first.
+	median := hosts[len(hosts)/2].TotalCost
+	if len(hosts)%2 == 0 {
+		median = (hosts[len(hosts)/2-1].TotalCost + median) / 2
+	}
+	if median <= 0 {
+		return nil
+	}
+
+	var anomalies []FleetAnomaly
+	for _, h := range hosts {
+		if h.TotalCost <= hostOutlierFactor*median {
+			break
+		}
+		anomalies = append(anomalies, FleetAnomaly{Host: h.Host, Anomaly: Anomaly{
+			Type:        "host_outlier",
+			Description: fmt.Sprintf("Host %s spent $%.2f, %.1fx the median host's $%.2f", h.Host, h.TotalCost, h.TotalCost/median, median),
+			Severity:    "warning",
+			Cost:        h.TotalCost,
+			Threshold:   hostOutlierFactor * median,
+		}})
+	}
+	return anomalies
+}
+
+// cronDivergence flags hosts where a cron's runs cost far more on average
+// than the same cron's runs on the other hosts.
+func cronDivergence(name string, byHost map[string]CronSummary) []FleetAnomaly {
+	if len(byHost) < 2 {
+		return nil
+	}
+	var totalCost float64
+	var totalRuns int
+	for _, c := range byHost {
+		totalCost += c.TotalCost
+		totalRuns += c.Runs
+	}
+
+	var anomalies []FleetAnomaly
+	for host, c := range byHost {
+		otherRuns := totalRuns - c.Runs
+		if c.Runs == 0 || otherRuns == 0 {
+			continue
+		}
+		avg := c.TotalCost / float64(c.Runs)
+		elsewhere := (totalCost - c.TotalCost) / float64(otherRuns)
+		if avg < cronDivergenceMinCost || avg < cronDivergenceFactor*elsewhere {
+			continue
+		}
+		anomalies = append(anomalies, FleetAnomaly{Host: host, Anomaly: Anomaly{
+			Type:        "cron_divergence",
+			Description: fmt.Sprintf("Cron %s averages $%.2f per run on %s but $%.2f on other hosts", name, avg, host, elsewhere),
+			Severity:    "warning",
+			Cost:        c.TotalCost,
+			Threshold:   cronDivergenceFactor * elsewhere,
+			Cron:        name,
+			Owner:       c.Owner,
+		}})
+	}
+	return anomalies
+}
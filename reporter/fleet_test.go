@@ -0,0 +1,97 @@
+package reporter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRollup(t *testing.T) {
+	hosts := []HostReport{
+		{Host: "web-1", Report: Report{
+			Period: "week", TotalCost: 10, TotalTokens: 1000, TotalSessions: 20,
+			ByAgent: []AgentSummary{{Agent: "urza", TotalCost: 6}, {Agent: "amos", TotalCost: 4}},
+			ByCron: []CronSummary{
+				{CronName: "backup", Runs: 4, TotalCost: 0.40},
+				{CronName: "digest", Runs: 2, TotalCost: 2},
+			},
+			Anomalies: []Anomaly{
+				{Type: "expensive_cron", Severity: "warning"},
+				{Type: "cap_approaching", Severity: "info"},
+			},
+		}},
+		{Host: "web-2", Report: Report{
+			Period: "week", TotalCost: 12, TotalTokens: 1200, TotalSessions: 24,
+			ByAgent: []AgentSummary{{Agent: "urza", TotalCost: 12}},
+			ByCron: []CronSummary{
+				{CronName: "backup", Runs: 4, TotalCost: 0.40},
+				{CronName: "digest", Runs: 2, TotalCost: 2},
+			},
+		}},
+		{Host: "batch", Report: Report{
+			Period: "week", TotalCost: 60, TotalTokens: 9000, TotalSessions: 30,
+			ByAgent: []AgentSummary{{Agent: "amos", Tenant: "acme", TotalCost: 60}},
+			ByCron: []CronSummary{
+				// Two agents on one host run a cron of the same name.
+				{CronName: "backup", Runs: 2, TotalCost: 1},
+				{CronName: "backup", Runs: 2, TotalCost: 1},
+			},
+		}},
+	}
+
+	fleet := Rollup(hosts, 1)
+	if fleet.Period != "week" || fleet.TotalCost != 82 || fleet.TotalTokens != 11200 || fleet.TotalSessions != 74 {
+		t.Errorf("unexpected totals: %+v", fleet)
+	}
+
+	if len(fleet.Hosts) != 3 || fleet.Hosts[0].Host != "batch" || fleet.Hosts[2].Host != "web-1" {
+		t.Fatalf("expected hosts costliest first, got %+v", fleet.Hosts)
+	}
+	if web1 := fleet.Hosts[2]; web1.Agents != 2 || web1.Anomalies != 2 || web1.Share != 10.0/82 {
+		t.Errorf("unexpected web-1 summary: %+v", web1)
+	}
+
+	if len(fleet.ByAgent) != 3 {
+		t.Fatalf("expected 3 agents, got %+v", fleet.ByAgent)
+	}
+	urza := fleet.ByAgent[1]
+	if urza.Agent != "urza" || urza.TotalCost != 18 || urza.ByHost[0] != 0 || urza.ByHost[1] != 12 || urza.ByHost[2] != 6 {
+		t.Errorf("expected urza's cost per host in host order, got %+v", urza)
+	}
+	if fleet.ByAgent[0].Agent != "acme/amos" {
+		t.Errorf("expected the tenant-qualified agent first, got %+v", fleet.ByAgent[0])
+	}
+
+	if len(fleet.TopCrons) != 1 {
+		t.Fatalf("expected the top cron only, got %+v", fleet.TopCrons)
+	}
+	if digest := fleet.TopCrons[0]; digest.CronName != "digest" || digest.Runs != 4 || digest.AvgCost != 1 {
+		t.Errorf("unexpected top cron: %+v", digest)
+	}
+	if backup := Rollup(hosts, 0).TopCrons[1]; backup.Runs != 12 || len(backup.Hosts) != 3 {
+		t.Errorf("expected backup counted once per host, got %+v", backup)
+	}
+
+	var types []string
+	for _, a := range fleet.Anomalies {
+		types = append(types, a.Type+"@"+a.Host)
+	}
+	want := "host_outlier@batch cron_divergence@batch expensive_cron@web-1"
+	if got := strings.Join(types, " "); got != want {
+		t.Errorf("expected anomalies %q, got %q", want, got)
+	}
+}
+
+func TestRollupSmallFleet(t *testing.T) {
+	// Two hosts have no meaningful median; crons run on one host only have
+	// nothing to diverge from.
+	fleet := Rollup([]HostReport{
+		{Host: "a", Report: Report{TotalCost: 100, ByCron: []CronSummary{{CronName: "x", Runs: 1, TotalCost: 5}}}},
+		{Host: "b", Report: Report{TotalCost: 1}},
+	}, 0)
+	if len(fleet.Anomalies) != 0 {
+		t.Errorf("expected no fleet anomalies, got %+v", fleet.Anomalies)
+	}
+	if len(fleet.TopCrons) != 1 || fleet.TopCrons[0].AvgCost != 5 {
+		t.Errorf("unexpected crons: %+v", fleet.TopCrons)
+	}
+}
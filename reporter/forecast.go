@@ -0,0 +1,121 @@
+package reporter
+
+import (
+	"sort"
+	"time"
+)
+
+// forecastTrailingDays is the window of the trailing-average projection.
+const forecastTrailingDays = 7
+
+// Forecast projects month-to-date spend to the end of the month, overall
+// and per agent, whatever the report's period.
+type Forecast struct {
+	Month       string `json:"month"` // YYYY-MM
+	Day         int    `json:"day"`   // of the month as of the report, counting today as spent
+	DaysInMonth int    `json:"days_in_month"`
+	Projection
+	ByAgent []AgentForecast `json:"by_agent,omitempty"`
+}
+
+// Projection is spend so far this month and two projections of the
+// month's total: Linear continues the month's daily average so far,
+// Trailing adds the daily average of the 7 days before today (reaching
+// into the previous month early on) for each day after today, which
+// follows recent changes in spend sooner.
+type Projection struct {
+	MonthToDate   float64 `json:"month_to_date"`
+	Linear        float64 `json:"linear"`
+	TrailingDaily float64 `json:"trailing_daily"` // daily average of the 7 days before today
+	Trailing      float64 `json:"trailing"`
+}
+
+// AgentForecast is one agent's Projection.
+type AgentForecast struct {
+	Agent  string `json:"agent"`
+	Tenant string `json:"tenant,omitempty"`
+	Projection
+}
+
+// ForecastStart returns when the sessions a forecast as of now reads
+// start: the start of the month or of the trailing window, whichever is
+// earlier.
+func ForecastStart(now time.Time) time.Time {
+	monthStart := BudgetStart(BudgetMonthly, now)
+	if trailing := trailingStart(now); trailing.Before(monthStart) {
+		return trailing
+	}
+	return monthStart
+}
+
+// trailingStart returns the start of the trailing window: midnight
+// forecastTrailingDays days before today.
+func trailingStart(now time.Time) time.Time {
+	return BudgetStart(BudgetDaily, now).AddDate(0, 0, -forecastTrailingDays)
+}
+
+// forecast projects spend as of now to the end of its month, costliest
+// agent (by linear projection) first. Days are whole calendar days, so the
+// projections only move as sessions are added.
+func (r *Reporter) forecast(now time.Time) *Forecast {
+	monthStart := BudgetStart(BudgetMonthly, now)
+	today := BudgetStart(BudgetDaily, now)
+	trailingFrom := trailingStart(now)
+	from := ForecastStart(now)
+	sessions := r.selectSessions(func(t time.Time) bool {
+		return !t.IsZero() && !t.Before(from) && !t.After(now)
+	})
+
+	f := &Forecast{
+		Month:       monthStart.Format("2006-01"),
+		Day:         now.Day(),
+		DaysInMonth: monthStart.AddDate(0, 1, -1).Day(),
+	}
+	remaining := float64(f.DaysInMonth - f.Day)
+
+	var trailing float64
+	byAgent := make(map[agentKey]*AgentForecast)
+	agentTrailing := make(map[agentKey]float64)
+	for _, s := range sessions {
+		key := agentKey{tenant: s.Tenant, agent: s.Agent}
+		a, ok := byAgent[key]
+		if !ok {
+			a = &AgentForecast{Agent: s.Agent, Tenant: s.Tenant}
+			byAgent[key] = a
+		}
+		cost := s.Usage.CostTotal
+		if !s.StartedAt.Before(monthStart) {
+			f.MonthToDate += cost
+			a.MonthToDate += cost
+		}
+		if !s.StartedAt.Before(trailingFrom) && s.StartedAt.Before(today) {
+			trailing += cost
+			agentTrailing[key] += cost
+		}
+	}
+
+	project := func(p *Projection, trailing float64) {
+		p.Linear = p.MonthToDate / float64(f.Day) * float64(f.DaysInMonth)
+		p.TrailingDaily = trailing / forecastTrailingDays
+		p.Trailing = p.MonthToDate + p.TrailingDaily*remaining
+	}
+	project(&f.Projection, trailing)
+	for key, a := range byAgent {
+		project(&a.Projection, agentTrailing[key])
+		f.ByAgent = append(f.ByAgent, *a)
+	}
+	sort.Slice(f.ByAgent, func(i, j int) bool {
+		a, b := f.ByAgent[i], f.ByAgent[j]
+		if a.Linear != b.Linear {
+			return a.Linear > b.Linear
+		}
+		if a.Trailing != b.Trailing {
+			return a.Trailing > b.Trailing
+		}
+		if a.Tenant != b.Tenant {
+			return a.Tenant < b.Tenant
+		}
+		return a.Agent < b.Agent
+	})
+	return f
+}
@@ -0,0 +1,62 @@
+package reporter
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestGenerateForecast(t *testing.T) {
+	localUTC(t)
+	// Late on April 10, with 20 days to go.
+	now := time.Date(2026, 4, 10, 23, 0, 0, 0, time.UTC)
+	at := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 10, 0, 0, 0, time.UTC) }
+	sessions := []parser.Session{
+		{Agent: "urza", StartedAt: at(time.March, 30), Usage: parser.Usage{CostTotal: 7}}, // before the trailing week
+		{Agent: "urza", StartedAt: at(time.April, 2), Usage: parser.Usage{CostTotal: 10}},
+		{Agent: "urza", StartedAt: at(time.April, 9), Usage: parser.Usage{CostTotal: 7}},
+		{Agent: "amos", StartedAt: at(time.April, 10), Usage: parser.Usage{CostTotal: 2}}, // today, not trailing
+		{Agent: "amos", StartedAt: at(time.April, 12), Usage: parser.Usage{CostTotal: 100}},
+	}
+
+	report := New(sessions, Config{Period: "today", AsOf: now, Forecast: true}).Generate()
+	f := report.Forecast
+	if f == nil || f.Month != "2026-04" || f.Day != 10 || f.DaysInMonth != 30 {
+		t.Fatalf("expected a forecast on day 10 of April's 30, got %+v", f)
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	if !near(f.MonthToDate, 19) || !near(f.Linear, 57) || !near(f.TrailingDaily, 1) || !near(f.Trailing, 39) {
+		t.Errorf("expected $19 so far, $57 linear and $39 at $1 a day, got %+v", f.Projection)
+	}
+	if len(f.ByAgent) != 2 {
+		t.Fatalf("expected 2 agents, got %+v", f.ByAgent)
+	}
+	if a := f.ByAgent[0]; a.Agent != "urza" || !near(a.MonthToDate, 17) || !near(a.Linear, 51) || !near(a.Trailing, 37) {
+		t.Errorf("expected urza's $17 projected to $51 linear and $37 trailing, got %+v", a)
+	}
+	if a := f.ByAgent[1]; a.Agent != "amos" || !near(a.Linear, 6) || a.TrailingDaily != 0 || !near(a.Trailing, 2) {
+		t.Errorf("expected amos's $2 projected to $6 linear and nothing more trailing, got %+v", a)
+	}
+
+	if report := New(sessions, Config{Period: "today", AsOf: now}).Generate(); report.Forecast != nil {
+		t.Errorf("expected no forecast unless asked for, got %+v", report.Forecast)
+	}
+
+	// Early on, the trailing week reaches into the previous month.
+	early := time.Date(2026, 4, 1, 6, 0, 0, 0, time.UTC)
+	report = New(sessions, Config{Period: "today", AsOf: early, Sections: []string{SectionForecast}}).Generate()
+	if f := report.Forecast; f == nil || f.MonthToDate != 0 || f.Linear != 0 || !near(f.TrailingDaily, 1) || !near(f.Trailing, 29) {
+		t.Errorf("expected nothing spent in April yet and $29 at $1 a day, got %+v", f)
+	}
+}
+
+func TestForecastStart(t *testing.T) {
+	if got := ForecastStart(time.Date(2026, 4, 20, 12, 0, 0, 0, time.UTC)); !got.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the start of the month, got %v", got)
+	}
+	if got := ForecastStart(time.Date(2026, 4, 3, 12, 0, 0, 0, time.UTC)); !got.Equal(time.Date(2026, 3, 27, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the start of the trailing week, got %v", got)
+	}
+}
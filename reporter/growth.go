@@ -0,0 +1,147 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// CronGrowth tracks how much context a cron sends run over run. Crons that
+// carry state between runs (memory files, accumulated notes) resend an
+// ever larger prompt, so their cost creeps up until someone trims it.
+// Prompt tokens are input plus cache reads and writes.
+type CronGrowth struct {
+	CronName      string  `json:"cron_name"`
+	CronID        string  `json:"cron_id,omitempty"`
+	Agent         string  `json:"agent,omitempty"` // agent the cron runs on
+	Runs          int     `json:"runs"`
+	PromptTokens  []int   `json:"prompt_tokens"` // per run, oldest first
+	GrowthPerRun  float64 `json:"growth_per_run"`
+	Monotonic     float64 `json:"monotonic"` // share of runs with a larger prompt than the run before
+	LastRunCost   float64 `json:"last_run_cost"`
+	ProjectedRun  float64 `json:"projected_run_cost"` // estimated cost of a run growthHorizon from now
+	ProjectedCost float64 `json:"projected_cost"`     // estimated spend over the next growthHorizon
+	Growing       bool    `json:"growing,omitempty"`
+}
+
+const (
+	// growthMinRuns is the fewest runs a trend is computed from.
+	growthMinRuns = 5
+	// growthMonotonic is the share of run-over-run increases above which a
+	// cron's context counts as growing steadily.
+	growthMonotonic = 0.8
+	// growthHorizon is how far ahead costs are projected.
+	growthHorizon = 30 * 24 * time.Hour
+)
+
+// aggregateCronGrowth fits a linear trend to each cron's prompt size per
+// run and projects its cost growthHorizon ahead at the observed run rate.
+// Crons whose prompt grows steadily and has passed ContextGrowthTokens are
+// marked Growing. Growing crons come first, then by growth per run. Crons
+// sharing a name on different agents are separate series.
+func (r *Reporter) aggregateCronGrowth(sessions []parser.Session) []CronGrowth {
+	byCron := make(map[cronKey][]parser.Session)
+	for _, s := range individualSessions(sessions) {
+		if s.Type == parser.SessionTypeCron && !s.StartedAt.IsZero() {
+			key := cronKey{name: s.CronName, id: s.CronID}
+			byCron[key] = append(byCron[key], s)
+		}
+	}
+
+	var result []CronGrowth
+	for key, runs := range byCron {
+		if len(runs) < growthMinRuns {
+			continue
+		}
+		sort.Slice(runs, func(i, j int) bool { return runs[i].StartedAt.Before(runs[j].StartedAt) })
+
+		g := CronGrowth{CronName: key.name, CronID: key.id, Runs: len(runs), PromptTokens: make([]int, len(runs))}
+		increases := 0
+		for i, s := range runs {
+			g.PromptTokens[i] = promptTokens(s)
+			if i > 0 && g.PromptTokens[i] > g.PromptTokens[i-1] {
+				increases++
+			}
+		}
+		g.Monotonic = float64(increases) / float64(len(runs)-1)
+		g.GrowthPerRun = slope(g.PromptTokens)
+
+		last := runs[len(runs)-1]
+		g.Agent = last.Agent
+		lastPrompt := g.PromptTokens[len(runs)-1]
+		g.LastRunCost = last.Usage.CostTotal
+		g.ProjectedRun = g.LastRunCost
+		if span := last.StartedAt.Sub(runs[0].StartedAt); span > 0 {
+			// Runs expected over the horizon, at the observed rate.
+			future := float64(len(runs)-1) * float64(growthHorizon) / float64(span)
+			if lastPrompt > 0 && g.GrowthPerRun > 0 {
+				g.ProjectedRun = g.LastRunCost * (float64(lastPrompt) + g.GrowthPerRun*future) / float64(lastPrompt)
+			}
+			g.ProjectedCost = future * (g.LastRunCost + g.ProjectedRun) / 2
+		}
+		g.Growing = g.GrowthPerRun > 0 && g.Monotonic >= growthMonotonic &&
+			lastPrompt >= r.config.ContextGrowthTokens
+		result = append(result, g)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Growing != result[j].Growing {
+			return result[i].Growing
+		}
+		if result[i].GrowthPerRun != result[j].GrowthPerRun {
+			return result[i].GrowthPerRun > result[j].GrowthPerRun
+		}
+		if result[i].CronName != result[j].CronName {
+			return result[i].CronName < result[j].CronName
+		}
+		return result[i].CronID < result[j].CronID
+	})
+	return result
+}
+
+// detectContextGrowth flags crons whose context grows steadily past
+// ContextGrowthTokens.
+func (r *Reporter) detectContextGrowth(sessions []parser.Session) []Anomaly {
+	var anomalies []Anomaly
+	for _, g := range r.aggregateCronGrowth(sessions) {
+		if !g.Growing {
+			continue
+		}
+		anomalies = append(anomalies, Anomaly{
+			Type: "context_growth",
+			Description: fmt.Sprintf("Cron %s's prompt grew in %.0f%% of its last %d runs, to %s tokens (+%s per run); a run is projected to cost $%.2f in 30 days, with $%.2f spent until then",
+				g.CronName, g.Monotonic*100, g.Runs, parser.FormatTokens(g.PromptTokens[g.Runs-1]), parser.FormatTokens(int(g.GrowthPerRun)), g.ProjectedRun, g.ProjectedCost),
+			Severity:  "warning",
+			Cost:      g.LastRunCost,
+			Threshold: float64(r.config.ContextGrowthTokens),
+			Agent:     g.Agent,
+			Cron:      g.CronName,
+			Owner:     r.cronOwner(g.CronName),
+		})
+	}
+	return anomalies
+}
+
+// promptTokens is the size of the context a session sent.
+func promptTokens(s parser.Session) int {
+	return s.Usage.Input + s.Usage.CacheRead + s.Usage.CacheWrite
+}
+
+// slope is the least-squares slope of values against their index.
+func slope(values []int) float64 {
+	n := float64(len(values))
+	if n < 2 {
+		return 0
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for i, v := range values {
+		x, y := float64(i), float64(v)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	return (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+}
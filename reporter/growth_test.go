@@ -0,0 +1,98 @@
+package reporter
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// cronRuns builds daily runs of a cron with the given prompt sizes, costing
+// $0.01 per thousand prompt tokens.
+func cronRuns(name string, prompts ...int) []parser.Session {
+	return agentCronRuns("ops", name, prompts...)
+}
+
+// agentCronRuns is cronRuns for a cron on another agent, with its own ID.
+func agentCronRuns(agent, name string, prompts ...int) []parser.Session {
+	start := time.Date(2026, 6, 1, 3, 0, 0, 0, time.UTC)
+	var runs []parser.Session
+	for i, p := range prompts {
+		runs = append(runs, parser.Session{
+			ID:        agent + name + string(rune('a'+i)),
+			Agent:     agent,
+			Type:      parser.SessionTypeCron,
+			CronName:  name,
+			CronID:    agent + "-" + name,
+			StartedAt: start.AddDate(0, 0, i),
+			Usage:     parser.Usage{Input: p / 10, CacheRead: p - p/10, CostTotal: float64(p) / 100000},
+		})
+	}
+	return runs
+}
+
+func TestAggregateCronGrowth(t *testing.T) {
+	var sessions []parser.Session
+	memory := make([]int, 10)
+	for i := range memory {
+		memory[i] = 40000 + 5000*i
+	}
+	sessions = append(sessions, cronRuns("memory-sync", memory...)...)
+	sessions = append(sessions, cronRuns("steady", 30000, 31000, 30000, 31000, 30000, 31000)...)
+	sessions = append(sessions, cronRuns("small", 1000, 1100, 1200, 1300, 1400, 1500)...)
+	sessions = append(sessions, cronRuns("rare", 60000, 70000, 80000)...)
+
+	r := New(sessions, Config{Crons: true, ContextGrowthTokens: 50000, CronOwners: map[string]CronOwner{"memory-sync": {Team: "ops"}}})
+	report := r.Generate()
+	growth := report.CronGrowth
+	if len(growth) != 3 {
+		t.Fatalf("expected 3 crons with enough runs, got %+v", growth)
+	}
+
+	g := growth[0]
+	if g.CronName != "memory-sync" || !g.Growing || g.Monotonic != 1 || g.GrowthPerRun != 5000 {
+		t.Fatalf("expected memory-sync to be growing by 5000 tokens per run, got %+v", g)
+	}
+	// 9 runs in 9 days makes 30 runs over the next 30 days, ending at
+	// 85k + 30×5k = 235k tokens per run.
+	if math.Abs(g.ProjectedRun-2.35) > 1e-9 || math.Abs(g.ProjectedCost-48) > 1e-9 {
+		t.Errorf("expected a $2.35 run and $48 over 30 days, got %v and %v", g.ProjectedRun, g.ProjectedCost)
+	}
+	for _, other := range growth[1:] {
+		if other.Growing {
+			t.Errorf("expected %s not to be flagged: %+v", other.CronName, other)
+		}
+	}
+
+	var flagged []Anomaly
+	for _, a := range report.Anomalies {
+		if a.Type == "context_growth" {
+			flagged = append(flagged, a)
+		}
+	}
+	if len(flagged) != 1 || flagged[0].Cron != "memory-sync" || flagged[0].Agent != "ops" || flagged[0].Owner == nil || flagged[0].Owner.Team != "ops" {
+		t.Errorf("expected one context_growth anomaly for ops's memory-sync, got %+v", flagged)
+	}
+}
+
+func TestAggregateCronGrowthByCronID(t *testing.T) {
+	// Two crons of the same name on different agents, one with a large and
+	// one with a small prompt. Merged, their runs would zig-zag.
+	var sessions []parser.Session
+	sessions = append(sessions, agentCronRuns("urza", "backup-check", 80000, 80000, 80000, 80000, 80000)...)
+	sessions = append(sessions, agentCronRuns("amos", "backup-check", 2000, 2100, 2200, 2300, 2400)...)
+
+	report := New(sessions, Config{Crons: true, ContextGrowthTokens: 50000}).Generate()
+	if len(report.CronGrowth) != 2 {
+		t.Fatalf("expected a series per cron, got %+v", report.CronGrowth)
+	}
+	for _, g := range report.CronGrowth {
+		if g.Runs != 5 || g.Growing || g.Agent == "" || g.CronID != g.Agent+"-backup-check" {
+			t.Errorf("expected 5 runs of one agent's cron, not growing, got %+v", g)
+		}
+	}
+	if g := report.CronGrowth[0]; g.Agent != "amos" || g.GrowthPerRun != 100 {
+		t.Errorf("expected amos's cron growing by 100 tokens a run first, got %+v", g)
+	}
+}
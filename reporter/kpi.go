@@ -0,0 +1,131 @@
+package reporter
+
+import (
+	"math"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// KPI metrics that targets can be set on.
+const (
+	KPITotalCost      = "total_cost"        // dollars over the period
+	KPIAvgSessionCost = "avg_session_cost"  // dollars per session
+	KPIAvgCronRunCost = "avg_cron_run_cost" // dollars per cron run
+	KPICacheRatio     = "cache_ratio"       // share of prompt tokens read from cache, 0-1
+)
+
+// KPIMetrics lists the supported metrics.
+var KPIMetrics = []string{KPITotalCost, KPIAvgSessionCost, KPIAvgCronRunCost, KPICacheRatio}
+
+// KPITarget is a goal for a metric: its value should stay at or above Min
+// and at or below Max, whichever are set.
+type KPITarget struct {
+	Metric string
+	Min    *float64
+	Max    *float64
+}
+
+// KPIStatus is a metric's value for the report period against its target,
+// with the trend since the previous period when one can be compared.
+type KPIStatus struct {
+	Metric   string   `json:"metric"`
+	Value    float64  `json:"value"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	Met      bool     `json:"met"`
+	Previous *float64 `json:"previous,omitempty"` // value over the previous period
+	Trend    string   `json:"trend,omitempty"`    // up, down or flat
+}
+
+// kpiFlat is the relative change below which a trend counts as flat.
+const kpiFlat = 0.01
+
+// evaluateKPIs measures each target over the report's sessions and, for
+// periods that can be compared, over the same window one period earlier.
+// Targets on metrics with no data in the period are left out.
+func (r *Reporter) evaluateKPIs(filtered []parser.Session, now time.Time) []KPIStatus {
+	var previous []parser.Session
+	from, until, err := PreviousWindow(r.config.Period, now)
+	comparable := err == nil
+	if comparable {
+		previous = r.window(from, until)
+	}
+
+	var result []KPIStatus
+	for _, target := range r.config.KPIs {
+		value, ok := kpiValue(target.Metric, filtered)
+		if !ok {
+			continue
+		}
+		status := KPIStatus{
+			Metric: target.Metric,
+			Value:  value,
+			Min:    target.Min,
+			Max:    target.Max,
+			Met:    (target.Min == nil || value >= *target.Min) && (target.Max == nil || value <= *target.Max),
+		}
+		if comparable {
+			if prev, ok := kpiValue(target.Metric, previous); ok {
+				status.Previous = &prev
+				status.Trend = trend(prev, value)
+			}
+		}
+		result = append(result, status)
+	}
+	return result
+}
+
+// kpiValue computes a metric over sessions. It reports false for unknown
+// metrics and for averages and ratios with nothing to divide by.
+func kpiValue(metric string, sessions []parser.Session) (float64, bool) {
+	var cost float64
+	var count int
+	switch metric {
+	case KPITotalCost:
+		for _, s := range sessions {
+			cost += s.Usage.CostTotal
+		}
+		return cost, true
+	case KPIAvgSessionCost:
+		for _, s := range sessions {
+			cost += s.Usage.CostTotal
+			count += s.Weight()
+		}
+	case KPIAvgCronRunCost:
+		for _, s := range sessions {
+			if s.Type == parser.SessionTypeCron {
+				cost += s.Usage.CostTotal
+				count += s.Weight()
+			}
+		}
+	case KPICacheRatio:
+		var read, prompt int
+		for _, s := range sessions {
+			read += s.Usage.CacheRead
+			prompt += s.Usage.Input + s.Usage.CacheRead + s.Usage.CacheWrite
+		}
+		if prompt == 0 {
+			return 0, false
+		}
+		return float64(read) / float64(prompt), true
+	default:
+		return 0, false
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return cost / float64(count), true
+}
+
+// trend classifies the change from prev to value.
+func trend(prev, value float64) string {
+	delta := value - prev
+	if math.Abs(delta) <= kpiFlat*math.Max(math.Abs(prev), math.Abs(value)) {
+		return "flat"
+	}
+	if delta > 0 {
+		return "up"
+	}
+	return "down"
+}
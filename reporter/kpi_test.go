@@ -0,0 +1,62 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestEvaluateKPIs(t *testing.T) {
+	now := time.Now()
+	current := now.Add(-time.Hour)
+	previous := now.AddDate(0, 0, -8)
+	sessions := []parser.Session{
+		{StartedAt: current, Type: parser.SessionTypeCron, Usage: parser.Usage{CostTotal: 0.30, Input: 200, CacheRead: 800}},
+		{StartedAt: current, Usage: parser.Usage{CostTotal: 0.10, Input: 1000}},
+		{StartedAt: previous, Usage: parser.Usage{CostTotal: 0.05, Input: 100}},
+	}
+
+	maxCost, minRatio := 0.10, 0.6
+	r := New(sessions, Config{Period: "week", KPIs: []KPITarget{
+		{Metric: KPIAvgSessionCost, Max: &maxCost},
+		{Metric: KPICacheRatio, Min: &minRatio},
+		{Metric: "unknown"},
+	}})
+	kpis := r.Generate().KPIs
+	if len(kpis) != 2 {
+		t.Fatalf("expected 2 KPIs, got %+v", kpis)
+	}
+
+	avg := kpis[0]
+	if avg.Value != 0.20 || avg.Met || avg.Previous == nil || *avg.Previous != 0.05 || avg.Trend != "up" {
+		t.Errorf("unexpected avg_session_cost status: %+v", avg)
+	}
+	// 800 of 2000 prompt tokens came from cache; the previous week had none.
+	ratio := kpis[1]
+	if ratio.Value != 0.4 || ratio.Met || ratio.Trend != "up" {
+		t.Errorf("unexpected cache_ratio status: %+v", ratio)
+	}
+
+	// Without a comparable period there is no trend.
+	r = New(sessions, Config{KPIs: []KPITarget{{Metric: KPIAvgCronRunCost, Max: &maxCost}}})
+	if kpis := r.Generate().KPIs; len(kpis) != 1 || kpis[0].Previous != nil || kpis[0].Trend != "" || kpis[0].Value != 0.30 {
+		t.Errorf("expected an all-time KPI without trend, got %+v", kpis)
+	}
+}
+
+func TestTrend(t *testing.T) {
+	for _, tt := range []struct {
+		prev, value float64
+		want        string
+	}{
+		{1.0, 1.005, "flat"},
+		{1.0, 1.5, "up"},
+		{1.0, 0.5, "down"},
+		{0, 0, "flat"},
+	} {
+		if got := trend(tt.prev, tt.value); got != tt.want {
+			t.Errorf("trend(%v, %v) = %q, want %q", tt.prev, tt.value, got, tt.want)
+		}
+	}
+}
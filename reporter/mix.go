@@ -0,0 +1,124 @@
+package reporter
+
+import (
+	"sort"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// Type mix buckets: the time span each TypeMixSummary covers.
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week" // starting Monday
+	BucketMonth = "month"
+)
+
+// TypeMixSummary is one agent's spend in one time bucket, split by
+// session type. A shift from interactive to cron and subagent spend shows
+// an agent's work moving from supervised to autonomous.
+type TypeMixSummary struct {
+	Agent       string  `json:"agent"`
+	Tenant      string  `json:"tenant,omitempty"`
+	Start       string  `json:"start"` // first day of the bucket, YYYY-MM-DD
+	Interactive float64 `json:"interactive"`
+	Cron        float64 `json:"cron"`
+	Subagent    float64 `json:"subagent"`
+	TotalCost   float64 `json:"total_cost"`
+}
+
+// Share returns the fraction of the bucket's cost spent on sessions of
+// type t.
+func (m TypeMixSummary) Share(t parser.SessionType) float64 {
+	if m.TotalCost == 0 {
+		return 0
+	}
+	switch t {
+	case parser.SessionTypeInteractive:
+		return m.Interactive / m.TotalCost
+	case parser.SessionTypeCron:
+		return m.Cron / m.TotalCost
+	case parser.SessionTypeSubagent:
+		return m.Subagent / m.TotalCost
+	}
+	return 0
+}
+
+// typeMixBucket picks buckets that give a period a handful of columns:
+// days within a week, weeks within a month and months beyond.
+func typeMixBucket(period string) string {
+	switch period {
+	case "today", "yesterday", "week":
+		return BucketDay
+	case "month":
+		return BucketWeek
+	default:
+		return BucketMonth
+	}
+}
+
+// bucketStart returns the first day of the bucket t falls in.
+func bucketStart(t time.Time, bucket string) string {
+	switch bucket {
+	case BucketDay:
+	case BucketWeek:
+		t = t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+	default:
+		t = t.AddDate(0, 0, 1-t.Day())
+	}
+	return t.Format("2006-01-02")
+}
+
+// aggregateTypeMix splits each agent's spend by session type per bucket,
+// costliest agent first and then oldest bucket first. Sessions without a
+// start time are left out.
+func (r *Reporter) aggregateTypeMix(sessions []parser.Session, bucket string) []TypeMixSummary {
+	type key struct {
+		agentKey
+		start string
+	}
+	byKey := make(map[key]*TypeMixSummary)
+	agentCost := make(map[agentKey]float64)
+	for _, s := range sessions {
+		if s.StartedAt.IsZero() {
+			continue
+		}
+		k := key{agentKey{tenant: s.Tenant, agent: s.Agent}, bucketStart(s.StartedAt, bucket)}
+		m, ok := byKey[k]
+		if !ok {
+			m = &TypeMixSummary{Agent: s.Agent, Tenant: s.Tenant, Start: k.start}
+			byKey[k] = m
+		}
+		cost := s.Usage.CostTotal
+		switch s.Type {
+		case parser.SessionTypeCron:
+			m.Cron += cost
+		case parser.SessionTypeSubagent:
+			m.Subagent += cost
+		default:
+			m.Interactive += cost
+		}
+		m.TotalCost += cost
+		agentCost[k.agentKey] += cost
+	}
+
+	result := make([]TypeMixSummary, 0, len(byKey))
+	for _, m := range byKey {
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		ka, kb := agentKey{tenant: a.Tenant, agent: a.Agent}, agentKey{tenant: b.Tenant, agent: b.Agent}
+		if ka != kb {
+			if agentCost[ka] != agentCost[kb] {
+				return agentCost[ka] > agentCost[kb]
+			}
+			if a.Tenant != b.Tenant {
+				return a.Tenant < b.Tenant
+			}
+			return a.Agent < b.Agent
+		}
+		return a.Start < b.Start
+	})
+	return result
+}
@@ -0,0 +1,59 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestGenerateTypeMix(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 10, 0, 0, 0, time.UTC) }
+	sessions := []parser.Session{
+		// Week of March 2: urza is supervised.
+		{Agent: "urza", Type: parser.SessionTypeInteractive, StartedAt: day(3), Usage: parser.Usage{CostTotal: 3}},
+		{Agent: "urza", Type: parser.SessionTypeCron, StartedAt: day(8), Usage: parser.Usage{CostTotal: 1}},
+		// Week of March 16: mostly autonomous.
+		{Agent: "urza", Type: parser.SessionTypeCron, StartedAt: day(16), Usage: parser.Usage{CostTotal: 3}},
+		{Agent: "urza", Type: parser.SessionTypeSubagent, StartedAt: day(17), Usage: parser.Usage{CostTotal: 1}},
+		{Agent: "amos", Type: parser.SessionTypeInteractive, StartedAt: day(10), Usage: parser.Usage{CostTotal: 1}},
+	}
+
+	report := New(sessions, Config{Period: "month", AsOf: now, TypeMix: true}).Generate()
+	if report.TypeMixBucket != BucketWeek || len(report.TypeMix) != 3 {
+		t.Fatalf("expected 3 weekly rows, got %s %+v", report.TypeMixBucket, report.TypeMix)
+	}
+	first, second := report.TypeMix[0], report.TypeMix[1]
+	if first.Agent != "urza" || first.Start != "2026-03-02" || first.Share(parser.SessionTypeInteractive) != 0.75 {
+		t.Errorf("expected urza's week of March 2 mostly interactive, got %+v", first)
+	}
+	if second.Start != "2026-03-16" || second.Share(parser.SessionTypeCron) != 0.75 || second.Subagent != 1 {
+		t.Errorf("expected urza's week of March 16 mostly cron, got %+v", second)
+	}
+	if report.TypeMix[2].Agent != "amos" {
+		t.Errorf("expected the cheaper agent last, got %+v", report.TypeMix[2])
+	}
+
+	if report := New(sessions, Config{Period: "month", AsOf: now}).Generate(); report.TypeMix != nil || report.TypeMixBucket != "" {
+		t.Errorf("expected no type mix unless asked for, got %+v", report.TypeMix)
+	}
+}
+
+func TestTypeMixBucket(t *testing.T) {
+	sunday := time.Date(2026, 3, 8, 23, 0, 0, 0, time.UTC)
+	tests := []struct {
+		period, bucket, start string
+	}{
+		{"week", BucketDay, "2026-03-08"},
+		{"month", BucketWeek, "2026-03-02"},
+		{"ytd", BucketMonth, "2026-03-01"},
+		{"", BucketMonth, "2026-03-01"},
+	}
+	for _, tt := range tests {
+		bucket := typeMixBucket(tt.period)
+		if start := bucketStart(sunday, bucket); bucket != tt.bucket || start != tt.start {
+			t.Errorf("period %q: expected %s buckets starting %s, got %s starting %s", tt.period, tt.bucket, tt.start, bucket, start)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PeriodYTD is the period from the start of the current year.
+const PeriodYTD = "ytd"
+
+// monthLayout is the format of calendar month periods.
+const monthLayout = "2006-01"
+
+// ValidatePeriod checks a period: "" or all for all time, today,
+// yesterday, week, month, ytd, a calendar month such as 2026-03, or an
+// inclusive range of months such as 2026-01..2026-06.
+func ValidatePeriod(period string) error {
+	switch period {
+	case "", "all", "today", "yesterday", "week", "month", PeriodYTD:
+		return nil
+	}
+	if _, _, err := monthRange(period, time.Local); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CalendarPeriod reports whether period is aligned to calendar months:
+// ytd or a range of months. Such reports get month subtotals, and their
+// windows include sessions starting exactly at midnight on the first day,
+// as stored daily aggregates do.
+func CalendarPeriod(period string) bool {
+	if period == PeriodYTD {
+		return true
+	}
+	_, _, err := monthRange(period, time.Local)
+	return err == nil
+}
+
+// monthRange returns the [from, until) window of a calendar month period
+// in loc: from the start of its first month to the start of the month
+// after its last.
+func monthRange(period string, loc *time.Location) (time.Time, time.Time, error) {
+	first, last, isRange := strings.Cut(period, "..")
+	if !isRange {
+		last = first
+	}
+	from, err := time.ParseInLocation(monthLayout, first, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, invalidPeriod(period)
+	}
+	end, err := time.ParseInLocation(monthLayout, last, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, invalidPeriod(period)
+	}
+	if end.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period: %s (range ends before it starts)", period)
+	}
+	return from, end.AddDate(0, 1, 0), nil
+}
+
+func invalidPeriod(period string) error {
+	return fmt.Errorf("invalid period: %s (valid: today, yesterday, week, month, ytd, all, YYYY-MM, YYYY-MM..YYYY-MM)", period)
+}
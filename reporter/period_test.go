@@ -0,0 +1,87 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestValidatePeriod(t *testing.T) {
+	for _, period := range []string{"", "all", "today", "week", "ytd", "2026-03", "2026-01..2026-06", "2025-11..2026-02"} {
+		if err := ValidatePeriod(period); err != nil {
+			t.Errorf("ValidatePeriod(%q) = %v", period, err)
+		}
+	}
+	for _, period := range []string{"fortnight", "2026-13", "2026-3", "2026-06..2026-01", "2026-01..", "2026-01..2026-02..2026-03"} {
+		if err := ValidatePeriod(period); err == nil {
+			t.Errorf("ValidatePeriod(%q) succeeded, want an error", period)
+		}
+	}
+	if CalendarPeriod("month") || !CalendarPeriod("ytd") || !CalendarPeriod("2026-03") {
+		t.Error("expected only ytd and months to be calendar periods")
+	}
+}
+
+func TestCalendarPeriodWindows(t *testing.T) {
+	now := time.Date(2026, 6, 12, 15, 0, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		period              string
+		from, until         time.Time
+		prevFrom, prevUntil time.Time
+	}{
+		{"ytd", day(2026, 1, 1), now, day(2025, 1, 1), now.AddDate(-1, 0, 0)},
+		{"2026-03", day(2026, 3, 1), day(2026, 4, 1), day(2026, 2, 1), day(2026, 3, 1)},
+		{"2025-11..2026-02", day(2025, 11, 1), day(2026, 3, 1), day(2025, 7, 1), day(2025, 11, 1)},
+	}
+	for _, tt := range tests {
+		from, until, err := PeriodWindow(tt.period, now)
+		if err != nil || !from.Equal(tt.from) || !until.Equal(tt.until) {
+			t.Errorf("PeriodWindow(%q) = %v, %v, %v; want %v, %v", tt.period, from, until, err, tt.from, tt.until)
+		}
+		from, until, err = PreviousWindow(tt.period, now)
+		if err != nil || !from.Equal(tt.prevFrom) || !until.Equal(tt.prevUntil) {
+			t.Errorf("PreviousWindow(%q) = %v, %v, %v; want %v, %v", tt.period, from, until, err, tt.prevFrom, tt.prevUntil)
+		}
+	}
+}
+
+// localUTC makes local time UTC for the rest of the test, for tests whose
+// calendar days, read in local time, are written in UTC.
+func localUTC(t *testing.T) {
+	local := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() { time.Local = local })
+}
+
+func TestMonthSubtotals(t *testing.T) {
+	localUTC(t)
+	sessions := []parser.Session{
+		// Stored aggregates are dated at midnight on their day.
+		{Agent: "urza", StartedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Count: 3, Usage: parser.Usage{CostTotal: 3}},
+		{Agent: "urza", StartedAt: time.Date(2026, 1, 20, 9, 0, 0, 0, time.UTC), Usage: parser.Usage{CostTotal: 1}},
+		{Agent: "urza", StartedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), Usage: parser.Usage{CostTotal: 2}},
+		{Agent: "urza", StartedAt: time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC), Usage: parser.Usage{CostTotal: 9}},
+	}
+	asOf := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	report := New(sessions, Config{Period: "ytd", AsOf: asOf}).Generate()
+	if report.TotalCost != 6 || report.TotalSessions != 5 {
+		t.Errorf("expected $6 over 5 sessions since Jan 1, got %v over %d", report.TotalCost, report.TotalSessions)
+	}
+	if len(report.ByMonth) != 2 || report.ByMonth[0].Month != "2026-01" || report.ByMonth[0].TotalCost != 4 ||
+		report.ByMonth[1].Month != "2026-03" || report.ByMonth[1].Sessions != 1 {
+		t.Errorf("unexpected month subtotals: %+v", report.ByMonth)
+	}
+
+	report = New(sessions, Config{Period: "2025-12", AsOf: asOf}).Generate()
+	if report.TotalCost != 9 {
+		t.Errorf("expected December's $9, got %v", report.TotalCost)
+	}
+
+	report = New(sessions, Config{Period: "month", AsOf: asOf}).Generate()
+	if report.ByMonth != nil {
+		t.Errorf("expected no month subtotals for a rolling month, got %+v", report.ByMonth)
+	}
+}
@@ -0,0 +1,19 @@
+package reporter
+
+// Provenance records how a report was produced, so archived reports from
+// many machines can be traced back to their host, build, settings and data.
+type Provenance struct {
+	Host    string `json:"host"`
+	Version string `json:"version,omitempty"` // costctl build
+	// ConfigHash is "sha256:" and the hex digest of the effective settings
+	// as JSON: the config file with command-line overrides applied.
+	ConfigHash string   `json:"config_hash"`
+	Sources    []Source `json:"sources"`
+}
+
+// Source is one input a report was read from.
+type Source struct {
+	Kind   string `json:"kind"` // agents (directory), files (directory of listed transcripts), input (session records), store or history
+	Path   string `json:"path"` // directory, file or store DSN, without its password
+	Tenant string `json:"tenant,omitempty"`
+}
@@ -0,0 +1,62 @@
+package reporter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Redaction levels, each hiding more of a report than the last, for
+// audiences that need the spend but not how it was incurred.
+const (
+	RedactNone      = "none"      // everything is shown
+	RedactSessions  = "sessions"  // session IDs and transcript paths are removed
+	RedactInternals = "internals" // models too: by-model, efficiency, escalation, deprecation and compliance sections are dropped
+)
+
+// RedactLevels lists the redaction levels, least hidden first.
+var RedactLevels = []string{RedactNone, RedactSessions, RedactInternals}
+
+// ValidateRedact reports a redaction level that does not exist. Empty
+// means RedactNone.
+func ValidateRedact(level string) error {
+	if level != "" && !slices.Contains(RedactLevels, level) {
+		return fmt.Errorf("invalid redaction level: %s (valid: %s)", level, strings.Join(RedactLevels, ", "))
+	}
+	return nil
+}
+
+// Redacted returns a copy of the report with what level hides removed. The
+// report itself is left as is, so it can still be alerted on in full.
+func (r Report) Redacted(level string) Report {
+	if level == "" || level == RedactNone {
+		return r
+	}
+
+	r.Sessions = slices.Clone(r.Sessions)
+	for i := range r.Sessions {
+		r.Sessions[i].ID = ""
+	}
+	r.Anomalies = slices.Clone(r.Anomalies)
+	for i := range r.Anomalies {
+		r.Anomalies[i].SessionID = ""
+		r.Anomalies[i].File = ""
+	}
+	r.Compliance = slices.Clone(r.Compliance)
+	for i := range r.Compliance {
+		r.Compliance[i].SessionID = ""
+	}
+	if level == RedactSessions {
+		return r
+	}
+
+	for i := range r.Sessions {
+		r.Sessions[i].Model = ""
+	}
+	r.ByModel = nil
+	r.Efficiency = nil
+	r.ByCascade = nil
+	r.Deprecations = nil
+	r.Compliance = nil
+	return r
+}
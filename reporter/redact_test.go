@@ -0,0 +1,38 @@
+package reporter
+
+import "testing"
+
+func TestReportRedacted(t *testing.T) {
+	r := Report{
+		ByModel:    []ModelSummary{{Model: "claude-opus-4-6", TotalCost: 3}},
+		Sessions:   []SessionDetail{{ID: "chat-1", Agent: "urza", Model: "claude-opus-4-6", Cost: 3}},
+		Anomalies:  []Anomaly{{Type: "high_token_count", SessionID: "chat-1", Agent: "urza", File: "/agents/urza/sessions/chat-1.jsonl"}},
+		Compliance: []PolicyViolation{{Agent: "urza", SessionID: "chat-1", Model: "claude-opus-4-6"}},
+	}
+
+	if got := r.Redacted(RedactNone); got.Sessions[0].ID != "chat-1" || got.Anomalies[0].File == "" {
+		t.Errorf("expected nothing redacted, got %+v", got)
+	}
+
+	sessions := r.Redacted(RedactSessions)
+	if sessions.Sessions[0].ID != "" || sessions.Anomalies[0].SessionID != "" || sessions.Anomalies[0].File != "" || sessions.Compliance[0].SessionID != "" {
+		t.Errorf("expected session IDs and transcripts removed, got %+v", sessions)
+	}
+	if sessions.Sessions[0].Model == "" || len(sessions.ByModel) != 1 || sessions.Sessions[0].Agent != "urza" {
+		t.Errorf("expected models and agents kept, got %+v", sessions)
+	}
+
+	internals := r.Redacted(RedactInternals)
+	if internals.Sessions[0].Model != "" || internals.ByModel != nil || internals.Compliance != nil || internals.Sessions[0].Cost != 3 {
+		t.Errorf("expected models removed but costs kept, got %+v", internals)
+	}
+
+	// The original report is untouched.
+	if r.Sessions[0].ID != "chat-1" || r.Anomalies[0].SessionID != "chat-1" || r.Compliance[0].SessionID != "chat-1" {
+		t.Errorf("expected the report itself unchanged, got %+v", r)
+	}
+
+	if err := ValidateRedact("everything"); err == nil {
+		t.Error("expected an unknown level to be rejected")
+	}
+}
@@ -2,47 +2,209 @@
 package reporter
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/misty-step/costctl/catalog"
+	"github.com/misty-step/costctl/maintenance"
+	"github.com/misty-step/costctl/notes"
 	"github.com/misty-step/costctl/parser"
 )
 
 // Config configures report generation.
 type Config struct {
-	Period    string  // today, yesterday, week, month, all
-	Agent     string  // filter by agent
-	Crons     bool    // show cron ranking
-	Models    bool    // show model comparison
-	Full      bool    // show all dimensions
-	Threshold float64 // anomaly threshold for expensive crons
+	Period         string                    // today, yesterday, week, month, ytd, all or months (see ValidatePeriod)
+	Agent          string                    // filter by agent
+	Crons          bool                      // show cron ranking
+	Models         bool                      // show model comparison
+	Roles          bool                      // show token share by message role
+	Turns          bool                      // show turn efficiency per agent
+	Errors         bool                      // show failed requests and their cost
+	Cascades       bool                      // show mid-session escalations to pricier models
+	Budgets        []Budget                  // spending limits to report consumption of
+	TypeMix        bool                      // show each agent's spend by session type over time
+	Forecast       bool                      // project month-to-date spend to the end of the month
+	AgentDays      bool                      // include per-agent daily totals (time series)
+	Full           bool                      // show all dimensions
+	Threshold      float64                   // anomaly threshold for expensive crons
+	Notes          []notes.Note              // annotations attached to days and crons
+	CronThresholds map[string]float64        // per-cron overrides of Threshold
+	CronTokens     map[string]TokenThreshold // per-cron token limits per run; "*" for any cron
+	SessionCaps    map[string]float64        // per-agent spend limit per session, over the session's SpendCap; "*" for sessions without one
+	CronCaps       map[string]float64        // per-cron spend limit per run, over the session's SpendCap
+	CapMargin      float64                   // report sessions within this fraction of their cap
+	KPIs           []KPITarget               // goals shown against the period's values
+	AgentModels    map[string]string         // default model per agent, for sessions without a ConfiguredModel
+	AsOf           time.Time                 // report as of this instant instead of now
+	CronOwners     map[string]CronOwner      // who is responsible for each cron, by name
+	CronExpected   map[string]CostRange      // expected cost of a single run, by cron name
+	Topics         []TopicRule               // map sessions to topics; the first matching rule wins
+	Maintenance    []maintenance.Window      // mute alert rules during these windows
+	ModelPolicies  map[string]ModelPolicy    // models each agent may use; "*" for any agent
+	// ContextGrowthTokens is the prompt size a steadily growing cron must
+	// reach to be flagged.
+	ContextGrowthTokens int
+	// Sections, when set, lists the sections to generate (see Sections),
+	// replacing Crons, Models, Roles, Turns, Errors and Full.
+	Sections []string
+	// Compare attaches each agent, cron and model's change since the
+	// previous period (see ComparablePeriods).
+	Compare bool
+	// Detectors add their anomalies to the built-in ones.
+	Detectors []Detector
+	// Agents, when set, lists every agent; those without sessions in the
+	// period are added to the agent breakdown as idle.
+	Agents []KnownAgent
+	// Since and Until, when set, further limit the report to sessions that
+	// started in [Since, Until), for ranges no period covers such as
+	// billing cycles.
+	Since time.Time
+	Until time.Time
+}
+
+// TokenThreshold limits the tokens of a single run: input (uncached
+// prompt), output and total tokens as counted in transcripts. Zero leaves
+// a count unlimited.
+type TokenThreshold struct {
+	Input  int
+	Output int
+	Total  int
+}
+
+// exceeded returns the first count in u over its limit, with the count and
+// the limit.
+func (t TokenThreshold) exceeded(u parser.Usage) (kind string, used, limit int, over bool) {
+	for _, c := range []struct {
+		kind        string
+		used, limit int
+	}{{"input", u.Input, t.Input}, {"output", u.Output, t.Output}, {"total", u.Total, t.Total}} {
+		if c.limit > 0 && c.used > c.limit {
+			return c.kind, c.used, c.limit, true
+		}
+	}
+	return "", 0, 0, false
+}
+
+// CronOwner identifies who is responsible for a cron.
+type CronOwner struct {
+	Team    string `json:"team,omitempty"`
+	Email   string `json:"email,omitempty"`
+	Channel string `json:"channel,omitempty"` // chat channel, e.g. #ops-alerts
+}
+
+// String describes the owner as "team (#channel, email)".
+func (o CronOwner) String() string {
+	var contacts []string
+	for _, c := range []string{o.Channel, o.Email} {
+		if c != "" {
+			contacts = append(contacts, c)
+		}
+	}
+	switch {
+	case o.Team == "":
+		return strings.Join(contacts, ", ")
+	case len(contacts) == 0:
+		return o.Team
+	default:
+		return fmt.Sprintf("%s (%s)", o.Team, strings.Join(contacts, ", "))
+	}
 }
 
 // Report contains all report data.
 type Report struct {
 	GeneratedAt   time.Time            `json:"generated_at"`
+	AsOf          *time.Time           `json:"as_of,omitempty"`
 	Period        string               `json:"period"`
+	Since         *time.Time           `json:"since,omitempty"`
+	Until         *time.Time           `json:"until,omitempty"`
 	TotalCost     float64              `json:"total_cost"`
 	TotalTokens   int                  `json:"total_tokens"`
 	TotalSessions int                  `json:"total_sessions"`
+	EstimatedCost float64              `json:"estimated_cost,omitempty"` // part of TotalCost priced at self-hosted rates
+	KPIs          []KPIStatus          `json:"kpis,omitempty"`
+	Budgets       []BudgetStatus       `json:"budgets,omitempty"`
+	Forecast      *Forecast            `json:"forecast,omitempty"`
+	ByTenant      []TenantSummary      `json:"by_tenant,omitempty"`
+	ByWorkspace   []WorkspaceSummary   `json:"by_workspace,omitempty"`
 	ByAgent       []AgentSummary       `json:"by_agent"`
 	BySessionType []SessionTypeSummary `json:"by_session_type"`
+	ByTopic       []TopicSummary       `json:"by_topic,omitempty"`
 	ByCron        []CronSummary        `json:"by_cron,omitempty"`
+	CronSlots     []CronSlotSummary    `json:"cron_slots,omitempty"`
+	CronCache     []CronCacheSummary   `json:"cron_cache,omitempty"`
+	CronGrowth    []CronGrowth         `json:"cron_growth,omitempty"`
 	ByModel       []ModelSummary       `json:"by_model"`
 	ByDay         []DaySummary         `json:"by_day,omitempty"`
+	ByMonth       []MonthSummary       `json:"by_month,omitempty"`
+	ByRole        []RoleSummary        `json:"by_role,omitempty"`
+	ByTurn        []TurnSummary        `json:"by_turn,omitempty"`
+	Efficiency    []ModelEfficiency    `json:"efficiency,omitempty"`
+	ByError       []ErrorSummary       `json:"by_error,omitempty"`
+	ByCascade     []CascadeSummary     `json:"by_cascade,omitempty"`
+	TypeMix       []TypeMixSummary     `json:"type_mix,omitempty"`
+	TypeMixBucket string               `json:"type_mix_bucket,omitempty"` // span of each TypeMix row: day, week or month
+	ByAgentDay    []AgentDaySummary    `json:"by_agent_day,omitempty"`
 	Anomalies     []Anomaly            `json:"anomalies,omitempty"`
+	Deprecations  []DeprecationNotice  `json:"deprecations,omitempty"`
+	Compliance    []PolicyViolation    `json:"compliance,omitempty"` // sessions on models their agent\'s policy disallows
 	Sessions      []SessionDetail      `json:"sessions,omitempty"`
+	// Sections lists the sections generated, when limited by
+	// Config.Sections.
+	Sections []string `json:"sections,omitempty"`
+	// Compared is the previous period the report was compared with, when
+	// Config.Compare is set.
+	Compared *Comparison `json:"compared,omitempty"`
+	// Partial is set when parsing stopped at a time limit, leaving
+	// transcripts unread.
+	Partial *Coverage `json:"partial,omitempty"`
+	// Provenance records the host, build, settings and data the report was
+	// produced from.
+	Provenance *Provenance `json:"provenance,omitempty"`
+}
+
+// TenantSummary aggregates costs by tenant.
+type TenantSummary struct {
+	Tenant      string  `json:"tenant"`
+	Agents      int     `json:"agents"`
+	Sessions    int     `json:"sessions"`
+	TotalCost   float64 `json:"total_cost"`
+	TotalTokens int     `json:"total_tokens"`
+}
+
+// WorkspaceSummary aggregates costs by OpenClaw workspace, for agents
+// found in nested layouts.
+type WorkspaceSummary struct {
+	Workspace   string  `json:"workspace"`
+	Tenant      string  `json:"tenant,omitempty"`
+	Agents      int     `json:"agents"`
+	Sessions    int     `json:"sessions"`
+	TotalCost   float64 `json:"total_cost"`
+	TotalTokens int     `json:"total_tokens"`
 }
 
 // AgentSummary aggregates costs by agent.
 type AgentSummary struct {
 	Agent        string  `json:"agent"`
+	Tenant       string  `json:"tenant,omitempty"`
 	Sessions     int     `json:"sessions"`
 	TotalCost    float64 `json:"total_cost"`
 	InputTokens  int     `json:"input_tokens"`
 	OutputTokens int     `json:"output_tokens"`
 	TotalTokens  int     `json:"total_tokens"`
+	Change       *Change `json:"change,omitempty"`
+	// Idle marks an agent listed from Config.Agents that had no sessions
+	// in the period.
+	Idle bool `json:"idle,omitempty"`
+}
+
+// KnownAgent is an agent known to exist, whether or not it has sessions.
+type KnownAgent struct {
+	Tenant string
+	Agent  string
 }
 
 // SessionTypeSummary aggregates costs by session type.
@@ -55,13 +217,32 @@ type SessionTypeSummary struct {
 
 // CronSummary aggregates costs by cron job.
 type CronSummary struct {
-	CronName    string  `json:"cron_name"`
-	CronID      string  `json:"cron_id,omitempty"`
-	Runs        int     `json:"runs"`
-	TotalCost   float64 `json:"total_cost"`
-	AvgCost     float64 `json:"avg_cost"`
-	MaxCost     float64 `json:"max_cost"`
-	TotalTokens int     `json:"total_tokens"`
+	CronName    string     `json:"cron_name"`
+	CronID      string     `json:"cron_id,omitempty"`
+	Runs        int        `json:"runs"`
+	TotalCost   float64    `json:"total_cost"`
+	AvgCost     float64    `json:"avg_cost"`
+	MaxCost     float64    `json:"max_cost"`
+	TotalTokens int        `json:"total_tokens"`
+	Owner       *CronOwner `json:"owner,omitempty"`
+	Notes       []string   `json:"notes,omitempty"`
+	Expected    *CostRange `json:"expected,omitempty"`  // configured cost of a single run
+	Deviation   string     `json:"deviation,omitempty"` // DeviationAbove or DeviationBelow when AvgCost is outside Expected
+	Change      *Change    `json:"change,omitempty"`
+}
+
+// CronSlotSummary aggregates a cron's runs by their scheduled time of day,
+// so runs in one slot that are consistently pricier than another stand out.
+type CronSlotSummary struct {
+	CronName  string  `json:"cron_name"`
+	CronID    string  `json:"cron_id,omitempty"`
+	Agent     string  `json:"agent,omitempty"` // agent the cron runs on
+	Slot      string  `json:"slot"`            // HH:MM of the slot's earliest run start, local time
+	Runs      int     `json:"runs"`
+	TotalCost float64 `json:"total_cost"`
+	AvgCost   float64 `json:"avg_cost"`
+	MaxCost   float64 `json:"max_cost"`
+	Relative  float64 `json:"relative"` // slot avg cost / cron avg cost
 }
 
 // ModelSummary aggregates costs by model.
@@ -72,37 +253,115 @@ type ModelSummary struct {
 	InputTokens  int     `json:"input_tokens"`
 	OutputTokens int     `json:"output_tokens"`
 	TotalTokens  int     `json:"total_tokens"`
+	Change       *Change `json:"change,omitempty"`
+	// Estimated is set when some of the model's cost was priced at
+	// self-hosted rates.
+	Estimated bool `json:"estimated,omitempty"`
 }
 
 // DaySummary aggregates costs by day.
 type DaySummary struct {
+	Date        string   `json:"date"`
+	Sessions    int      `json:"sessions"`
+	TotalCost   float64  `json:"total_cost"`
+	TotalTokens int      `json:"total_tokens"`
+	Notes       []string `json:"notes,omitempty"`
+}
+
+// MonthSummary aggregates costs by calendar month.
+type MonthSummary struct {
+	Month       string  `json:"month"` // YYYY-MM
+	Sessions    int     `json:"sessions"`
+	TotalCost   float64 `json:"total_cost"`
+	TotalTokens int     `json:"total_tokens"`
+}
+
+// AgentDaySummary aggregates an agent's costs for one day.
+type AgentDaySummary struct {
 	Date        string  `json:"date"`
+	Agent       string  `json:"agent"`
+	Tenant      string  `json:"tenant,omitempty"`
 	Sessions    int     `json:"sessions"`
 	TotalCost   float64 `json:"total_cost"`
 	TotalTokens int     `json:"total_tokens"`
 }
 
+// RoleSummary splits an agent's tokens across message roles (system,
+// user, tool_result, text, thinking, tool_call). The split is estimated
+// from transcript content; see parser.Session.TokensByRole.
+type RoleSummary struct {
+	Agent  string         `json:"agent"` // "" for all agents combined
+	Tokens map[string]int `json:"tokens"`
+	Total  int            `json:"total"`
+}
+
+// Share returns the fraction of the agent's tokens attributed to role.
+func (s RoleSummary) Share(role string) float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Tokens[role]) / float64(s.Total)
+}
+
 // Anomaly represents an anomalous session or pattern.
 type Anomaly struct {
-	Type        string  `json:"type"`
-	Description string  `json:"description"`
-	Severity    string  `json:"severity"` // warning, error
-	Cost        float64 `json:"cost,omitempty"`
-	SessionID   string  `json:"session_id,omitempty"`
-	Agent       string  `json:"agent,omitempty"`
+	ID             string     `json:"id,omitempty"` // stable across runs; see AnomalyID
+	Type           string     `json:"type"`
+	Description    string     `json:"description"`
+	Severity       string     `json:"severity"` // warning, error
+	Cost           float64    `json:"cost,omitempty"`
+	Threshold      float64    `json:"threshold,omitempty"`       // dollar limit the rule compared Cost against
+	Tokens         int        `json:"tokens,omitempty"`          // tokens counted, for token-denominated rules
+	TokenThreshold int        `json:"token_threshold,omitempty"` // token limit the rule compared Tokens against
+	SessionID      string     `json:"session_id,omitempty"`
+	Agent          string     `json:"agent,omitempty"`
+	Cron           string     `json:"cron,omitempty"`  // cron name, for anomalies about a cron run
+	Owner          *CronOwner `json:"owner,omitempty"` // the cron's owner, when configured
+	Muted          string     `json:"muted,omitempty"` // maintenance window that muted the anomaly; not alerted on
+	File           string     `json:"file,omitempty"`  // transcript of the session, when known
+}
+
+// AnomalyID identifies an anomaly by its rule and scope, so the same
+// anomaly found by later runs, or over other periods, has the same ID.
+func AnomalyID(a Anomaly) string {
+	sum := sha256.Sum256([]byte(a.Type + "\x00" + a.Agent + "\x00" + a.Cron + "\x00" + a.SessionID))
+	return hex.EncodeToString(sum[:8])
+}
+
+// DeprecationNotice flags observed usage of a deprecated or retiring model.
+type DeprecationNotice struct {
+	Model        string   `json:"model"`
+	Status       string   `json:"status"` // deprecated, retiring, retired
+	RetiresAt    string   `json:"retires_at,omitempty"`
+	Replacement  string   `json:"replacement,omitempty"`
+	Sessions     int      `json:"sessions"`
+	Agents       []string `json:"agents"`
+	TotalCost    float64  `json:"total_cost"`
+	MigratedCost float64  `json:"migrated_cost"` // estimated cost of the same tokens on the replacement
+	CostDelta    float64  `json:"cost_delta"`    // estimated change in cost if migrated, both sides at the report's rates
 }
 
 // SessionDetail contains detailed session information.
 type SessionDetail struct {
-	ID        string             `json:"id"`
-	Agent     string             `json:"agent"`
-	Type      parser.SessionType `json:"type"`
-	CronName  string             `json:"cron_name,omitempty"`
-	Model     string             `json:"model"`
-	Cost      float64            `json:"cost"`
-	Tokens    int                `json:"tokens"`
-	StartedAt time.Time          `json:"started_at"`
-	Duration  time.Duration      `json:"duration"`
+	ID         string             `json:"id"`
+	Agent      string             `json:"agent"`
+	Tenant     string             `json:"tenant,omitempty"`
+	Type       parser.SessionType `json:"type"`
+	CronName   string             `json:"cron_name,omitempty"`
+	Model      string             `json:"model"`
+	Cost       float64            `json:"cost"`
+	CostInput  float64            `json:"cost_input"`
+	CostOutput float64            `json:"cost_output"`
+	Tokens     int                `json:"tokens"`
+	Turns      int                `json:"turns"`            // assistant messages
+	Errors     int                `json:"errors,omitempty"` // failed requests
+	CacheRead  int                `json:"cache_read"`
+	CacheWrite int                `json:"cache_write"`
+	Roles      map[string]int     `json:"roles,omitempty"`
+	StartedAt  time.Time          `json:"started_at"`
+	EndedAt    time.Time          `json:"ended_at"`
+	Duration   time.Duration      `json:"duration"`
+	Estimated  bool               `json:"estimated,omitempty"` // priced at self-hosted rates
 }
 
 // Reporter generates reports from parsed sessions.
@@ -122,49 +381,185 @@ func New(sessions []parser.Session, config Config) *Reporter {
 // Generate produces a complete report.
 func (r *Reporter) Generate() Report {
 	// Filter sessions by period
-	filtered := r.filterByPeriod(r.sessions)
+	filtered := r.selectSessions(r.inPeriod())
 
 	report := Report{
 		GeneratedAt: time.Now().UTC(),
 		Period:      r.config.Period,
 	}
+	if !r.config.AsOf.IsZero() {
+		asOf := r.config.AsOf.UTC()
+		report.AsOf = &asOf
+	}
+	if !r.config.Since.IsZero() {
+		since := r.config.Since.UTC()
+		report.Since = &since
+	}
+	if !r.config.Until.IsZero() {
+		until := r.config.Until.UTC()
+		report.Until = &until
+	}
 
 	// Calculate totals
 	for _, s := range filtered {
 		report.TotalCost += s.Usage.CostTotal
 		report.TotalTokens += s.Usage.Total
-		report.TotalSessions++
+		report.TotalSessions += s.Weight()
+		if s.Estimated {
+			report.EstimatedCost += s.Usage.CostTotal
+		}
+	}
+
+	if r.config.Sections != nil {
+		report.Sections = r.config.Sections
+	}
+	if r.include(SectionSummary, true) {
+		report.KPIs = r.evaluateKPIs(filtered, r.now())
+	}
+	if r.include(SectionBudgets, len(r.config.Budgets) > 0) {
+		report.Budgets = r.checkBudgets(r.now())
+	}
+	if r.include(SectionForecast, r.config.Forecast || r.config.Full) {
+		report.Forecast = r.forecast(r.now())
 	}
 
 	// Generate dimensions
-	report.ByAgent = r.aggregateByAgent(filtered)
-	report.BySessionType = r.aggregateBySessionType(filtered)
-	report.ByModel = r.aggregateByModel(filtered)
-	report.ByDay = r.aggregateByDay(filtered)
+	if r.include(SectionTenants, true) {
+		report.ByTenant = r.aggregateByTenant(filtered)
+	}
+	if r.include(SectionWorkspaces, true) {
+		report.ByWorkspace = r.aggregateByWorkspace(filtered)
+	}
+	if r.include(SectionAgents, true) {
+		report.ByAgent = r.aggregateByAgent(filtered)
+	}
+	if r.include(SectionTypes, true) {
+		report.BySessionType = r.aggregateBySessionType(filtered)
+	}
+	if r.include(SectionTopics, len(r.config.Topics) > 0) {
+		report.ByTopic = r.aggregateByTopic(filtered, report.TotalCost)
+	}
+	if r.include(SectionModels, true) {
+		report.ByModel = r.aggregateByModel(filtered)
+	}
+	if r.include(SectionDays, true) {
+		report.ByDay = r.aggregateByDay(filtered)
+	}
+	if r.include(SectionMonths, CalendarPeriod(r.config.Period)) {
+		report.ByMonth = monthDimension.Aggregate(filtered)
+	}
 
-	if r.config.Crons || r.config.Full {
+	if r.include(SectionCrons, r.config.Crons || r.config.Full) {
 		report.ByCron = r.aggregateByCron(filtered)
+		report.CronSlots = r.aggregateByCronSlot(filtered, report.ByCron)
+		report.CronCache = r.aggregateCronCache(filtered)
+		report.CronGrowth = r.aggregateCronGrowth(filtered)
+	}
+
+	if r.config.AgentDays {
+		report.ByAgentDay = agentDayDimension.Aggregate(filtered)
+	}
+
+	if r.include(SectionRoles, r.config.Roles || r.config.Full) {
+		report.ByRole = r.aggregateByRole(filtered)
 	}
 
-	if r.config.Full {
+	if r.include(SectionTurns, r.config.Turns || r.config.Full) {
+		report.ByTurn = r.aggregateByTurn(filtered)
+	}
+
+	if r.include(SectionEfficiency, r.config.Full) {
+		report.Efficiency = r.aggregateEfficiency(filtered)
+	}
+
+	if r.include(SectionErrors, r.config.Errors || r.config.Full) {
+		report.ByError = r.aggregateByError(filtered)
+	}
+
+	if r.include(SectionCascades, r.config.Cascades || r.config.Full) {
+		report.ByCascade = r.aggregateByCascade(filtered)
+	}
+
+	if r.include(SectionMix, r.config.TypeMix || r.config.Full) {
+		bucket := typeMixBucket(r.config.Period)
+		if report.TypeMix = r.aggregateTypeMix(filtered, bucket); len(report.TypeMix) > 0 {
+			report.TypeMixBucket = bucket
+		}
+	}
+
+	if r.include(SectionSessions, r.config.Full) {
 		report.Sessions = r.getSessionDetails(filtered)
 	}
 
+	if r.config.Compare {
+		r.compare(&report)
+	}
+
 	// Detect anomalies
-	report.Anomalies = r.detectAnomalies(filtered)
+	if r.include(SectionAnomalies, true) {
+		report.Anomalies = r.detectAnomalies(filtered)
+	}
+	if r.include(SectionDeprecations, true) {
+		report.Deprecations = r.detectDeprecations(filtered, r.now())
+	}
+	if r.include(SectionCompliance, len(r.config.ModelPolicies) > 0) {
+		report.Compliance = r.checkCompliance(filtered)
+	}
 
 	return report
 }
 
-// filterByPeriod filters sessions based on the configured period.
-func (r *Reporter) filterByPeriod(sessions []parser.Session) []parser.Session {
+// Filtered returns the sessions that fall within the configured period.
+func (r *Reporter) Filtered() []parser.Session {
+	return r.selectSessions(r.inPeriod())
+}
+
+// now returns the instant the report is as of: Config.AsOf if set,
+// otherwise the current time. Either is in local time, so calendar periods
+// such as today run from local midnight whatever zone AsOf was given in.
+func (r *Reporter) now() time.Time {
+	if !r.config.AsOf.IsZero() {
+		return r.config.AsOf.In(time.Local)
+	}
+	return time.Now()
+}
+
+// inPeriod returns whether a session starting at a given time falls within
+// the configured period and range, or nil when every session does.
+func (r *Reporter) inPeriod() func(startedAt time.Time) bool {
+	keep := r.inWindow()
+	since, until := r.config.Since, r.config.Until
+	if since.IsZero() && until.IsZero() {
+		return keep
+	}
+	return func(t time.Time) bool {
+		return (keep == nil || keep(t)) && !t.IsZero() && !t.Before(since) && (until.IsZero() || t.Before(until))
+	}
+}
+
+// inWindow returns whether a session starting at a given time falls within
+// the configured period, or nil when every session does. With AsOf set,
+// sessions starting after it are dropped as well.
+func (r *Reporter) inWindow() func(startedAt time.Time) bool {
+	asOf := r.config.AsOf
+	beforeAsOf := func(t time.Time) bool { return asOf.IsZero() || !t.After(asOf) }
+
 	if r.config.Period == "" || r.config.Period == "all" {
-		return sessions
+		if asOf.IsZero() {
+			return nil
+		}
+		return beforeAsOf
 	}
 
-	now := time.Now()
-	var cutoff time.Time
+	now := r.now()
+	if CalendarPeriod(r.config.Period) {
+		from, until, _ := PeriodWindow(r.config.Period, now)
+		return func(t time.Time) bool {
+			return beforeAsOf(t) && !t.IsZero() && !t.Before(from) && t.Before(until)
+		}
+	}
 
+	var cutoff time.Time
 	switch r.config.Period {
 	case "today":
 		cutoff = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
@@ -173,203 +568,285 @@ func (r *Reporter) filterByPeriod(sessions []parser.Session) []parser.Session {
 		nextDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 		cutoff = time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 0, 0, 0, 0, yesterday.Location())
 		// Filter to yesterday only
-		var result []parser.Session
-		for _, s := range sessions {
-			if !s.StartedAt.IsZero() && s.StartedAt.After(cutoff) && s.StartedAt.Before(nextDay) {
-				result = append(result, s)
-			}
+		return func(t time.Time) bool {
+			return beforeAsOf(t) && !t.IsZero() && t.After(cutoff) && t.Before(nextDay)
 		}
-		return result
 	case "week":
 		cutoff = now.AddDate(0, 0, -7)
 	case "month":
 		cutoff = now.AddDate(0, -1, 0)
 	}
 
+	return func(t time.Time) bool {
+		return beforeAsOf(t) && !t.IsZero() && t.After(cutoff)
+	}
+}
+
+// selectSessions returns the sessions whose start time keep accepts, or
+// all of them if keep is nil, in the order of sortSessions.
+func (r *Reporter) selectSessions(keep func(startedAt time.Time) bool) []parser.Session {
 	var result []parser.Session
-	for _, s := range sessions {
-		if !s.StartedAt.IsZero() && s.StartedAt.After(cutoff) {
+	for _, s := range r.sessions {
+		if keep == nil || keep(s.StartedAt) {
 			result = append(result, s)
 		}
 	}
+	sortSessions(result)
 	return result
 }
 
-func (r *Reporter) aggregateByAgent(sessions []parser.Session) []AgentSummary {
-	agg := make(map[string]*AgentSummary)
+// sortSessions orders sessions by start time, then tenant, agent, ID and
+// model. Summing costs in a fixed order, and listing sessions and
+// anomalies in one, keeps reports identical whatever order the sessions
+// were read in.
+func sortSessions(sessions []parser.Session) {
+	sort.SliceStable(sessions, func(i, j int) bool {
+		a, b := &sessions[i], &sessions[j]
+		switch {
+		case !a.StartedAt.Equal(b.StartedAt):
+			return a.StartedAt.Before(b.StartedAt)
+		case a.Tenant != b.Tenant:
+			return a.Tenant < b.Tenant
+		case a.Agent != b.Agent:
+			return a.Agent < b.Agent
+		case a.ID != b.ID:
+			return a.ID < b.ID
+		default:
+			return a.Usage.Model < b.Usage.Model
+		}
+	})
+}
 
+// window returns the sessions that started in [from, until).
+func (r *Reporter) window(from, until time.Time) []parser.Session {
+	return r.selectSessions(func(t time.Time) bool {
+		return !t.IsZero() && !t.Before(from) && t.Before(until)
+	})
+}
+
+func (r *Reporter) aggregateByTenant(sessions []parser.Session) []TenantSummary {
+	tenants := tenantDimension.Aggregate(sessions)
+	if len(tenants) == 0 {
+		return nil
+	}
+
+	agents := make(map[string]map[string]bool)
 	for _, s := range sessions {
-		if _, ok := agg[s.Agent]; !ok {
-			agg[s.Agent] = &AgentSummary{Agent: s.Agent}
+		if agents[s.Tenant] == nil {
+			agents[s.Tenant] = make(map[string]bool)
 		}
-		a := agg[s.Agent]
-		a.Sessions++
-		a.TotalCost += s.Usage.CostTotal
-		a.InputTokens += s.Usage.Input
-		a.OutputTokens += s.Usage.Output
-		a.TotalTokens += s.Usage.Total
+		agents[s.Tenant][s.Agent] = true
 	}
-
-	result := make([]AgentSummary, 0, len(agg))
-	for _, a := range agg {
-		result = append(result, *a)
+	for i := range tenants {
+		tenants[i].Agents = len(agents[tenants[i].Tenant])
 	}
-
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].TotalCost > result[j].TotalCost
-	})
-
-	return result
+	return tenants
 }
 
-func (r *Reporter) aggregateBySessionType(sessions []parser.Session) []SessionTypeSummary {
-	agg := make(map[parser.SessionType]*SessionTypeSummary)
+func (r *Reporter) aggregateByWorkspace(sessions []parser.Session) []WorkspaceSummary {
+	workspaces := workspaceDimension.Aggregate(sessions)
+	if len(workspaces) == 0 {
+		return nil
+	}
 
+	agents := make(map[workspaceKey]map[string]bool)
 	for _, s := range sessions {
-		if _, ok := agg[s.Type]; !ok {
-			agg[s.Type] = &SessionTypeSummary{Type: s.Type}
+		key := workspaceKey{tenant: s.Tenant, workspace: s.Workspace}
+		if agents[key] == nil {
+			agents[key] = make(map[string]bool)
 		}
-		t := agg[s.Type]
-		t.Sessions++
-		t.TotalCost += s.Usage.CostTotal
-		t.TotalTokens += s.Usage.Total
+		agents[key][s.Agent] = true
 	}
-
-	result := make([]SessionTypeSummary, 0, len(agg))
-	for _, t := range agg {
-		result = append(result, *t)
+	for i := range workspaces {
+		workspaces[i].Agents = len(agents[workspaceKey{tenant: workspaces[i].Tenant, workspace: workspaces[i].Workspace}])
 	}
+	return workspaces
+}
 
-	// Order: interactive, cron, subagent
-	order := map[parser.SessionType]int{
-		parser.SessionTypeInteractive: 0,
-		parser.SessionTypeCron:        1,
-		parser.SessionTypeSubagent:    2,
+func (r *Reporter) aggregateByAgent(sessions []parser.Session) []AgentSummary {
+	agents := agentDimension.Aggregate(sessions)
+	seen := make(map[KnownAgent]bool, len(agents))
+	for _, a := range agents {
+		seen[KnownAgent{Tenant: a.Tenant, Agent: a.Agent}] = true
 	}
-	sort.Slice(result, func(i, j int) bool {
-		return order[result[i].Type] < order[result[j].Type]
+	var idle []AgentSummary
+	for _, known := range r.config.Agents {
+		if !seen[known] {
+			seen[known] = true
+			idle = append(idle, AgentSummary{Agent: known.Agent, Tenant: known.Tenant, Idle: true})
+		}
+	}
+	sort.Slice(idle, func(i, j int) bool {
+		if idle[i].Tenant != idle[j].Tenant {
+			return idle[i].Tenant < idle[j].Tenant
+		}
+		return idle[i].Agent < idle[j].Agent
 	})
+	return append(agents, idle...)
+}
 
-	return result
+func (r *Reporter) aggregateBySessionType(sessions []parser.Session) []SessionTypeSummary {
+	return sessionTypeDimension.Aggregate(sessions)
 }
 
 func (r *Reporter) aggregateByCron(sessions []parser.Session) []CronSummary {
-	// Only include cron sessions
-	type cronKey struct {
-		name string
-		id   string
+	crons := cronDimension.Aggregate(sessions)
+	for i := range crons {
+		crons[i].Owner = r.cronOwner(crons[i].CronName)
+		crons[i].Notes = notes.ForCron(r.config.Notes, crons[i].CronName)
 	}
-	agg := make(map[cronKey]*CronSummary)
+	r.expectCronCosts(crons)
+	return crons
+}
 
-	for _, s := range sessions {
-		if s.Type != parser.SessionTypeCron {
+// cronOwner returns the configured owner of a cron, or nil.
+func (r *Reporter) cronOwner(name string) *CronOwner {
+	owner, ok := r.config.CronOwners[name]
+	if !ok {
+		return nil
+	}
+	return &owner
+}
+
+// cronSlotTolerance is how long after a slot's earliest run start a run
+// still belongs to that slot. Runs are jittered and queue behind each
+// other, so a cron scheduled for 04:00 also starts at 04:01 or 04:03.
+const cronSlotTolerance = 10 // minutes
+
+// aggregateByCronSlot breaks each cron down by scheduled run time and
+// compares each slot's average against the cron's overall average.
+func (r *Reporter) aggregateByCronSlot(sessions []parser.Session, crons []CronSummary) []CronSlotSummary {
+	avgByCron := make(map[cronKey]float64)
+	for _, c := range crons {
+		avgByCron[cronKey{name: c.CronName, id: c.CronID}] = c.AvgCost
+	}
+
+	// Run start times are lost when sessions are rebuilt from daily aggregates.
+	runs := individualSessions(sessions)
+	slotOf := cronRunSlots(runs)
+	slots := cronSlotDimension(slotOf).Aggregate(runs)
+	for i := range slots {
+		if avg := avgByCron[cronKey{name: slots[i].CronName, id: slots[i].CronID}]; avg > 0 {
+			slots[i].Relative = slots[i].AvgCost / avg
+		}
+	}
+	return slots
+}
+
+// cronRunSlots buckets each cron's runs by local start minute: a run
+// starting less than cronSlotTolerance after the earliest start of a slot
+// joins it, and later runs open the next slot. It returns the HH:MM of the
+// slot a run belongs to.
+func cronRunSlots(runs []parser.Session) func(s parser.Session) string {
+	minuteOf := func(s parser.Session) int {
+		t := s.StartedAt.Local()
+		return t.Hour()*60 + t.Minute()
+	}
+	minutes := make(map[cronKey]map[int]bool)
+	for _, s := range runs {
+		if s.Type != parser.SessionTypeCron || s.StartedAt.IsZero() {
 			continue
 		}
 		key := cronKey{name: s.CronName, id: s.CronID}
-		if _, ok := agg[key]; !ok {
-			agg[key] = &CronSummary{CronName: s.CronName, CronID: s.CronID}
-		}
-		c := agg[key]
-		c.Runs++
-		c.TotalCost += s.Usage.CostTotal
-		c.TotalTokens += s.Usage.Total
-		if s.Usage.CostTotal > c.MaxCost {
-			c.MaxCost = s.Usage.CostTotal
+		if minutes[key] == nil {
+			minutes[key] = make(map[int]bool)
 		}
+		minutes[key][minuteOf(s)] = true
 	}
 
-	result := make([]CronSummary, 0, len(agg))
-	for _, c := range agg {
-		if c.Runs > 0 {
-			c.AvgCost = c.TotalCost / float64(c.Runs)
+	slotStart := make(map[cronKey]map[int]int)
+	for key, set := range minutes {
+		sorted := make([]int, 0, len(set))
+		for m := range set {
+			sorted = append(sorted, m)
+		}
+		sort.Ints(sorted)
+		slotStart[key] = make(map[int]int, len(sorted))
+		start := sorted[0]
+		for _, m := range sorted {
+			if m-start >= cronSlotTolerance {
+				start = m
+			}
+			slotStart[key][m] = start
 		}
-		result = append(result, *c)
 	}
 
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].TotalCost > result[j].TotalCost
-	})
-
-	return result
+	return func(s parser.Session) string {
+		start := slotStart[cronKey{name: s.CronName, id: s.CronID}][minuteOf(s)]
+		return fmt.Sprintf("%02d:%02d", start/60, start%60)
+	}
 }
 
 func (r *Reporter) aggregateByModel(sessions []parser.Session) []ModelSummary {
-	agg := make(map[string]*ModelSummary)
-
+	models := modelDimension.Aggregate(sessions)
+	estimated := make(map[string]bool)
 	for _, s := range sessions {
-		model := s.Usage.Model
-		if model == "" {
-			model = "unknown"
+		if s.Estimated {
+			estimated[s.Usage.Model] = true
 		}
-		if _, ok := agg[model]; !ok {
-			agg[model] = &ModelSummary{Model: model}
-		}
-		m := agg[model]
-		m.Sessions++
-		m.TotalCost += s.Usage.CostTotal
-		m.InputTokens += s.Usage.Input
-		m.OutputTokens += s.Usage.Output
-		m.TotalTokens += s.Usage.Total
 	}
-
-	result := make([]ModelSummary, 0, len(agg))
-	for _, m := range agg {
-		result = append(result, *m)
+	for i := range models {
+		models[i].Estimated = estimated[models[i].Model]
 	}
-
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].TotalCost > result[j].TotalCost
-	})
-
-	return result
+	return models
 }
 
 func (r *Reporter) aggregateByDay(sessions []parser.Session) []DaySummary {
-	agg := make(map[string]*DaySummary)
-
-	for _, s := range sessions {
-		if s.StartedAt.IsZero() {
-			continue
-		}
-		date := s.StartedAt.Format("2006-01-02")
-		if _, ok := agg[date]; !ok {
-			agg[date] = &DaySummary{Date: date}
-		}
-		d := agg[date]
-		d.Sessions++
-		d.TotalCost += s.Usage.CostTotal
-		d.TotalTokens += s.Usage.Total
+	days := dayDimension.Aggregate(sessions)
+	for i := range days {
+		days[i].Notes = notes.ForDate(r.config.Notes, days[i].Date)
 	}
-
-	result := make([]DaySummary, 0, len(agg))
-	for _, d := range agg {
-		result = append(result, *d)
-	}
-
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].Date < result[j].Date
-	})
-
-	return result
+	return days
 }
 
 func (r *Reporter) detectAnomalies(sessions []parser.Session) []Anomaly {
 	var anomalies []Anomaly
 
+	// Per-session rules don't apply to sessions rebuilt from daily aggregates.
+	sessions = individualSessions(sessions)
+
 	// Expensive crons
 	for _, s := range sessions {
-		if s.Type == parser.SessionTypeCron && s.Usage.CostTotal > r.config.Threshold {
+		if s.Type != parser.SessionTypeCron {
+			continue
+		}
+		threshold := r.cronThreshold(s.CronName)
+		if s.Usage.CostTotal > threshold {
 			anomalies = append(anomalies, Anomaly{
 				Type:        "expensive_cron",
-				Description: fmt.Sprintf("Cron %s exceeded $%.2f threshold", s.CronName, r.config.Threshold),
+				Description: fmt.Sprintf("Cron %s exceeded $%.2f threshold", s.CronName, threshold),
 				Severity:    "warning",
 				Cost:        s.Usage.CostTotal,
+				Threshold:   threshold,
 				SessionID:   s.ID,
 				Agent:       s.Agent,
 			})
 		}
 	}
 
+	// Crons over their token thresholds
+	for _, s := range sessions {
+		if s.Type != parser.SessionTypeCron {
+			continue
+		}
+		limit, ok := r.cronTokenThreshold(s.CronName)
+		if !ok {
+			continue
+		}
+		if kind, used, threshold, over := limit.exceeded(s.Usage); over {
+			anomalies = append(anomalies, Anomaly{
+				Type:           "expensive_cron_tokens",
+				Description:    fmt.Sprintf("Cron %s used %s %s tokens, over its %s threshold", s.CronName, parser.FormatTokens(used), kind, parser.FormatTokens(threshold)),
+				Severity:       "warning",
+				Cost:           s.Usage.CostTotal,
+				Tokens:         used,
+				TokenThreshold: threshold,
+				SessionID:      s.ID,
+				Agent:          s.Agent,
+			})
+		}
+	}
+
 	// High token counts (sessions with >100k tokens)
 	for _, s := range sessions {
 		if s.Usage.Total > 100000 {
@@ -384,6 +861,71 @@ func (r *Reporter) detectAnomalies(sessions []parser.Session) []Anomaly {
 		}
 	}
 
+	// Zero cost despite substantial usage (sessions with >10k tokens): the
+	// upstream pricing config for the model is likely missing or broken.
+	for _, s := range sessions {
+		if s.Usage.Total > 10000 && s.Usage.CostTotal == 0 {
+			model := s.Usage.Model
+			if model == "" {
+				model = "unknown"
+			}
+			anomalies = append(anomalies, Anomaly{
+				Type:        "zero_cost",
+				Description: fmt.Sprintf("Session used %d tokens on %s but reported $0 cost; check OpenClaw's pricing config for this model", s.Usage.Total, model),
+				Severity:    "warning",
+				SessionID:   s.ID,
+				Agent:       s.Agent,
+			})
+		}
+	}
+
+	// Sessions that hit or came close to their spend cap; capped sessions
+	// likely stopped before finishing their work.
+	for _, s := range sessions {
+		limit, ok := r.sessionCap(s)
+		if !ok || limit <= 0 {
+			continue
+		}
+		if s.Usage.CostTotal >= limit {
+			anomalies = append(anomalies, Anomaly{
+				Type:        "session_cap_hit",
+				Description: fmt.Sprintf("Session reached its $%.2f spend cap; its work was likely truncated", limit),
+				Severity:    "error",
+				Cost:        s.Usage.CostTotal,
+				Threshold:   limit,
+				SessionID:   s.ID,
+				Agent:       s.Agent,
+			})
+		} else if s.Usage.CostTotal >= limit*(1-r.config.CapMargin) {
+			anomalies = append(anomalies, Anomaly{
+				Type:        "session_cap_near",
+				Description: fmt.Sprintf("Session used %.0f%% of its $%.2f spend cap", s.Usage.CostTotal/limit*100, limit),
+				Severity:    "warning",
+				Cost:        s.Usage.CostTotal,
+				Threshold:   limit,
+				SessionID:   s.ID,
+				Agent:       s.Agent,
+			})
+		}
+	}
+
+	// Sessions on a pricier model than their agent is configured to use,
+	// e.g. a manual override that was never switched back.
+	for _, s := range sessions {
+		configured, extra, ok := r.modelOverride(s)
+		if !ok {
+			continue
+		}
+		anomalies = append(anomalies, Anomaly{
+			Type:        "model_override",
+			Description: fmt.Sprintf("Session ran on %s instead of the configured %s, costing an estimated $%.2f more", s.Usage.Model, configured, extra),
+			Severity:    "warning",
+			Cost:        s.Usage.CostTotal,
+			SessionID:   s.ID,
+			Agent:       s.Agent,
+		})
+	}
+
 	// Opus usage where cheaper model might suffice
 	for _, s := range sessions {
 		if containsOpus(s.Usage.Model) && s.Usage.Total < 5000 {
@@ -398,34 +940,273 @@ func (r *Reporter) detectAnomalies(sessions []parser.Session) []Anomaly {
 		}
 	}
 
+	anomalies = append(anomalies, r.detectChattyAgents(sessions)...)
+	anomalies = append(anomalies, r.detectContextGrowth(sessions)...)
+	anomalies = append(anomalies, r.runDetectors(sessions)...)
+
+	// Tag anomalies about cron runs with the cron and its owner, so
+	// notifications can be routed to the owning team, and anomalies about a
+	// session with its transcript.
+	crons := make(map[[2]string]string)
+	started := make(map[[2]string]time.Time)
+	files := make(map[[2]string]string)
+	for _, s := range sessions {
+		if s.Type == parser.SessionTypeCron {
+			crons[[2]string{s.Agent, s.ID}] = s.CronName
+		}
+		started[[2]string{s.Agent, s.ID}] = s.StartedAt
+		files[[2]string{s.Agent, s.ID}] = s.FilePath
+	}
+	for i, a := range anomalies {
+		if name, ok := crons[[2]string{a.Agent, a.SessionID}]; ok {
+			anomalies[i].Cron = name
+			anomalies[i].Owner = r.cronOwner(name)
+		}
+		if a.SessionID != "" && a.File == "" {
+			anomalies[i].File = files[[2]string{a.Agent, a.SessionID}]
+		}
+		anomalies[i].ID = AnomalyID(anomalies[i])
+	}
+
+	// Mute anomalies raised during maintenance windows: those about a
+	// session by when it started, the rest by the report's time.
+	for i, a := range anomalies {
+		at := started[[2]string{a.Agent, a.SessionID}]
+		if at.IsZero() {
+			at = r.now()
+		}
+		if w, ok := maintenance.Muting(r.config.Maintenance, a.Type, anomalies[i].Cron, at); ok {
+			anomalies[i].Muted = w.Name
+		}
+	}
+
 	return anomalies
 }
 
+// detectDeprecations reports models in use that the catalog marks as
+// deprecated, with the estimated cost delta of moving to the replacement.
+// Both sides of the delta are priced at the rates in effect at now, so a
+// price change since the sessions ran doesn't count as migration savings.
+func (r *Reporter) detectDeprecations(sessions []parser.Session, now time.Time) []DeprecationNotice {
+	agg := make(map[string]*DeprecationNotice)
+	agents := make(map[string]map[string]bool)
+
+	for _, s := range sessions {
+		m, ok := catalog.Lookup(s.Usage.Model)
+		if !ok {
+			continue
+		}
+		status := m.Status(now)
+		if status == catalog.StatusActive {
+			continue
+		}
+		if _, ok := agg[s.Usage.Model]; !ok {
+			n := &DeprecationNotice{
+				Model:       s.Usage.Model,
+				Status:      status,
+				Replacement: m.Replacement,
+			}
+			if !m.RetiresAt.IsZero() {
+				n.RetiresAt = m.RetiresAt.Format("2006-01-02")
+			}
+			agg[s.Usage.Model] = n
+			agents[s.Usage.Model] = make(map[string]bool)
+		}
+		n := agg[s.Usage.Model]
+		n.Sessions++
+		n.TotalCost += s.Usage.CostTotal
+		agents[s.Usage.Model][s.Agent] = true
+
+		if replacement, ok := catalog.Lookup(m.Replacement); ok {
+			current := m.EstimateCostAt(now, s.Usage.Input, s.Usage.Output, s.Usage.CacheRead, s.Usage.CacheWrite)
+			migrated := replacement.EstimateCostAt(now, s.Usage.Input, s.Usage.Output, s.Usage.CacheRead, s.Usage.CacheWrite)
+			n.MigratedCost += migrated
+			n.CostDelta += migrated - current
+		}
+	}
+
+	result := make([]DeprecationNotice, 0, len(agg))
+	for model, n := range agg {
+		for agent := range agents[model] {
+			n.Agents = append(n.Agents, agent)
+		}
+		sort.Strings(n.Agents)
+		result = append(result, *n)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalCost != result[j].TotalCost {
+			return result[i].TotalCost > result[j].TotalCost
+		}
+		return result[i].Model < result[j].Model
+	})
+
+	return result
+}
+
+// cronThreshold returns the expensive_cron threshold for a cron.
+func (r *Reporter) cronThreshold(cronName string) float64 {
+	if t, ok := r.config.CronThresholds[cronName]; ok {
+		return t
+	}
+	return r.config.Threshold
+}
+
+// cronTokenThreshold returns the token threshold for a cron: its own, else
+// the "*" one.
+func (r *Reporter) cronTokenThreshold(cronName string) (TokenThreshold, bool) {
+	if t, ok := r.config.CronTokens[cronName]; ok {
+		return t, true
+	}
+	t, ok := r.config.CronTokens["*"]
+	return t, ok
+}
+
+// aggregateByRole sums each agent's role token estimates, largest agent
+// first, followed by a combined row for all agents.
+func (r *Reporter) aggregateByRole(sessions []parser.Session) []RoleSummary {
+	byAgent := make(map[string]*RoleSummary)
+	all := RoleSummary{Tokens: make(map[string]int)}
+	for _, s := range sessions {
+		if len(s.TokensByRole) == 0 {
+			continue
+		}
+		name := s.Agent
+		if s.Tenant != "" {
+			name = s.Tenant + "/" + s.Agent
+		}
+		summary, ok := byAgent[name]
+		if !ok {
+			summary = &RoleSummary{Agent: name, Tokens: make(map[string]int)}
+			byAgent[name] = summary
+		}
+		for role, tokens := range s.TokensByRole {
+			summary.Tokens[role] += tokens
+			summary.Total += tokens
+			all.Tokens[role] += tokens
+			all.Total += tokens
+		}
+	}
+	if len(byAgent) == 0 {
+		return nil
+	}
+
+	result := make([]RoleSummary, 0, len(byAgent)+1)
+	for _, summary := range byAgent {
+		result = append(result, *summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Total != result[j].Total {
+			return result[i].Total > result[j].Total
+		}
+		return result[i].Agent < result[j].Agent
+	})
+	return append(result, all)
+}
+
+// sessionCap returns the spend cap that applies to a session: its cron's
+// per-run cap, else its agent's per-session cap, else the cap configured
+// in its agent directory, else the "*" cap.
+func (r *Reporter) sessionCap(s parser.Session) (float64, bool) {
+	if s.Type == parser.SessionTypeCron {
+		if c, ok := r.config.CronCaps[s.CronName]; ok {
+			return c, true
+		}
+	}
+	if c, ok := r.config.SessionCaps[s.Agent]; ok {
+		return c, true
+	}
+	if s.SpendCap > 0 {
+		return s.SpendCap, true
+	}
+	c, ok := r.config.SessionCaps["*"]
+	return c, ok
+}
+
 func (r *Reporter) getSessionDetails(sessions []parser.Session) []SessionDetail {
+	sessions = individualSessions(sessions)
 	result := make([]SessionDetail, 0, len(sessions))
 
 	for _, s := range sessions {
 		result = append(result, SessionDetail{
-			ID:        s.ID,
-			Agent:     s.Agent,
-			Type:      s.Type,
-			CronName:  s.CronName,
-			Model:     s.Usage.Model,
-			Cost:      s.Usage.CostTotal,
-			Tokens:    s.Usage.Total,
-			StartedAt: s.StartedAt,
-			Duration:  s.Duration,
+			ID:         s.ID,
+			Agent:      s.Agent,
+			Tenant:     s.Tenant,
+			Type:       s.Type,
+			CronName:   s.CronName,
+			Model:      s.Usage.Model,
+			Cost:       s.Usage.CostTotal,
+			CostInput:  s.Usage.CostInput,
+			CostOutput: s.Usage.CostOutput,
+			Tokens:     s.Usage.Total,
+			Turns:      s.Turns(),
+			Errors:     len(s.Errors),
+			CacheRead:  s.Usage.CacheRead,
+			CacheWrite: s.Usage.CacheWrite,
+			Roles:      s.TokensByRole,
+			StartedAt:  s.StartedAt,
+			EndedAt:    s.EndedAt,
+			Duration:   s.Duration,
+			Estimated:  s.Estimated,
 		})
 	}
 
 	// Sort by cost descending
 	sort.Slice(result, func(i, j int) bool {
-		return result[i].Cost > result[j].Cost
+		if result[i].Cost != result[j].Cost {
+			return result[i].Cost > result[j].Cost
+		}
+		if result[i].Agent != result[j].Agent {
+			return result[i].Agent < result[j].Agent
+		}
+		return result[i].ID < result[j].ID
 	})
 
 	return result
 }
 
+// individualSessions drops sessions rebuilt from stored aggregates, which
+// have no per-session identity.
+func individualSessions(sessions []parser.Session) []parser.Session {
+	result := make([]parser.Session, 0, len(sessions))
+	for _, s := range sessions {
+		if s.Count == 0 {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// modelOverride reports whether s ran on a model other than its agent's
+// configured default that is more expensive for the same tokens. It returns
+// the configured model and the estimated extra cost. Models missing from
+// the catalog are not compared.
+func (r *Reporter) modelOverride(s parser.Session) (string, float64, bool) {
+	configured := s.ConfiguredModel
+	if configured == "" {
+		configured = r.config.AgentModels[s.Agent]
+	}
+	if configured == "" || s.Usage.Model == "" {
+		return "", 0, false
+	}
+	want, ok := catalog.Lookup(configured)
+	if !ok {
+		return "", 0, false
+	}
+	used, ok := catalog.Lookup(s.Usage.Model)
+	if !ok || used.ID == want.ID {
+		return "", 0, false
+	}
+
+	u := s.Usage
+	extra := used.EstimateCostAt(s.StartedAt, u.Input, u.Output, u.CacheRead, u.CacheWrite) -
+		want.EstimateCostAt(s.StartedAt, u.Input, u.Output, u.CacheRead, u.CacheWrite)
+	if extra <= 0 {
+		return "", 0, false
+	}
+	return configured, extra, true
+}
+
 func containsOpus(model string) bool {
 	opusModels := []string{"opus", "claude-opus", "claude-3-opus"}
 	lower := fmt.Sprintf("%s", model)
@@ -1,9 +1,17 @@
 package reporter
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/misty-step/costctl/maintenance"
+	"github.com/misty-step/costctl/notes"
 	"github.com/misty-step/costctl/parser"
 )
 
@@ -33,6 +41,23 @@ func TestAggregateByAgent(t *testing.T) {
 	}
 }
 
+func TestAggregateByAgentIdle(t *testing.T) {
+	sessions := []parser.Session{
+		{Agent: "urza", Usage: parser.Usage{CostTotal: 1.5, Total: 1000}},
+	}
+	r := New(sessions, Config{Agents: []KnownAgent{{Agent: "urza"}, {Agent: "pepper"}, {Agent: "amos"}, {Tenant: "acme", Agent: "urza"}}})
+	result := r.aggregateByAgent(sessions)
+
+	var got []string
+	for _, a := range result {
+		got = append(got, fmt.Sprintf("%s/%s idle=%v", a.Tenant, a.Agent, a.Idle))
+	}
+	want := []string{"/urza idle=false", "/amos idle=true", "/pepper idle=true", "acme/urza idle=true"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected idle agents after active ones, got %v", got)
+	}
+}
+
 func TestAggregateBySessionType(t *testing.T) {
 	sessions := []parser.Session{
 		{Type: parser.SessionTypeInteractive, Usage: parser.Usage{CostTotal: 1.0}},
@@ -171,7 +196,7 @@ func TestFilterByPeriod(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.period, func(t *testing.T) {
 			r := New(sessions, Config{Period: tt.period})
-			result := r.filterByPeriod(sessions)
+			result := r.Filtered()
 			if len(result) != tt.expected {
 				t.Errorf("period %q: expected %d sessions, got %d", tt.period, tt.expected, len(result))
 			}
@@ -179,6 +204,87 @@ func TestFilterByPeriod(t *testing.T) {
 	}
 }
 
+func TestFilterByPeriodAsOf(t *testing.T) {
+	asOf := time.Date(2026, 6, 30, 23, 59, 0, 0, time.UTC)
+	sessions := []parser.Session{
+		{StartedAt: asOf.AddDate(0, 0, -10)},
+		{StartedAt: asOf.AddDate(0, 0, -3)},
+		{StartedAt: asOf.Add(time.Hour)},
+	}
+
+	tests := []struct {
+		period   string
+		expected int
+	}{
+		{"week", 1},
+		{"month", 2},
+		{"all", 2},
+	}
+	for _, tt := range tests {
+		r := New(sessions, Config{Period: tt.period, AsOf: asOf})
+		if got := len(r.Filtered()); got != tt.expected {
+			t.Errorf("period %q as of %s: expected %d sessions, got %d", tt.period, asOf, tt.expected, got)
+		}
+	}
+
+	report := New(sessions, Config{AsOf: asOf}).Generate()
+	if report.AsOf == nil || !report.AsOf.Equal(asOf) {
+		t.Errorf("expected report as of %s, got %v", asOf, report.AsOf)
+	}
+}
+
+func TestFilterByPeriodAsOfLocal(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("time zone data unavailable:", err)
+	}
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+	time.Local = la
+
+	// 17:00 UTC on June 30 is 10:00 in Los Angeles, whose June 30 started
+	// at 07:00 UTC.
+	asOf := time.Date(2026, 6, 30, 17, 0, 0, 0, time.UTC)
+	sessions := []parser.Session{
+		{ID: "la-yesterday", StartedAt: time.Date(2026, 6, 30, 5, 0, 0, 0, time.UTC)},
+		{ID: "la-today", StartedAt: time.Date(2026, 6, 30, 8, 0, 0, 0, time.UTC)},
+	}
+	if got := New(sessions, Config{Period: "today", AsOf: asOf}).Filtered(); len(got) != 1 || got[0].ID != "la-today" {
+		t.Errorf("expected today from local midnight, got %+v", got)
+	}
+	if got := New(sessions, Config{Period: "yesterday", AsOf: asOf}).Filtered(); len(got) != 1 || got[0].ID != "la-yesterday" {
+		t.Errorf("expected yesterday in local time, got %+v", got)
+	}
+}
+
+func TestFilterBySinceUntil(t *testing.T) {
+	since := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)
+	sessions := []parser.Session{
+		{ID: "before", StartedAt: since.Add(-time.Minute)},
+		{ID: "first", StartedAt: since},
+		{ID: "last", StartedAt: until.Add(-time.Minute)},
+		{ID: "after", StartedAt: until},
+		{ID: "undated"},
+	}
+
+	got := New(sessions, Config{Since: since, Until: until}).Filtered()
+	if len(got) != 2 || got[0].ID != "first" || got[1].ID != "last" {
+		t.Errorf("expected the sessions in [since, until), got %+v", got)
+	}
+	if got := New(sessions, Config{Until: until}).Filtered(); len(got) != 3 {
+		t.Errorf("expected 3 sessions before until, got %+v", got)
+	}
+	// A range narrows a period rather than replacing it.
+	if got := New(sessions, Config{Period: "2026-02", Since: since, Until: until}).Filtered(); len(got) != 1 || got[0].ID != "last" {
+		t.Errorf("expected the range within February, got %+v", got)
+	}
+
+	report := New(sessions, Config{Since: since}).Generate()
+	if report.Since == nil || !report.Since.Equal(since) || report.Until != nil {
+		t.Errorf("expected the report to record since only, got %v, %v", report.Since, report.Until)
+	}
+}
+
 func TestDetectAnomalies(t *testing.T) {
 	sessions := []parser.Session{
 		{
@@ -200,14 +306,20 @@ func TestDetectAnomalies(t *testing.T) {
 			ID:    "session3",
 			Usage: parser.Usage{CostTotal: 0.5, Total: 1000, Model: "claude-opus-4"}, // Opus overkill
 		},
+		{
+			Type:  parser.SessionTypeInteractive,
+			Agent: "kaylee",
+			ID:    "session4",
+			Usage: parser.Usage{CostTotal: 0, Total: 40000, Model: "kimi-k2.5"}, // Zero cost
+		},
 	}
 
 	r := New(sessions, Config{Threshold: 0.50})
 	anomalies := r.detectAnomalies(sessions)
 
-	// Should detect: expensive cron, high token count, opus overkill
-	if len(anomalies) != 3 {
-		t.Errorf("expected 3 anomalies, got %d", len(anomalies))
+	// Should detect: expensive cron, high token count, zero cost, opus overkill
+	if len(anomalies) != 4 {
+		t.Errorf("expected 4 anomalies, got %d", len(anomalies))
 	}
 
 	// Check types
@@ -225,6 +337,112 @@ func TestDetectAnomalies(t *testing.T) {
 	if !types["opus_overkill"] {
 		t.Error("expected opus_overkill anomaly")
 	}
+	if !types["zero_cost"] {
+		t.Error("expected zero_cost anomaly")
+	}
+
+	// IDs are unique and stable across runs and periods.
+	ids := make(map[string]bool)
+	for _, a := range anomalies {
+		if a.ID == "" || ids[a.ID] {
+			t.Errorf("expected a unique ID, got %q", a.ID)
+		}
+		ids[a.ID] = true
+	}
+	again := New(sessions, Config{Threshold: 0.50, Period: "today"}).detectAnomalies(sessions)
+	for _, a := range again {
+		if !ids[a.ID] {
+			t.Errorf("expected %s to keep its ID, got %q", a.Type, a.ID)
+		}
+	}
+}
+
+func TestCronTokenThresholds(t *testing.T) {
+	sessions := []parser.Session{
+		{Type: parser.SessionTypeCron, CronName: "sync", Agent: "urza", ID: "run1", Usage: parser.Usage{CostTotal: 0.1, Input: 300000, Total: 310000}},
+		{Type: parser.SessionTypeCron, CronName: "digest", Agent: "urza", ID: "run2", Usage: parser.Usage{CostTotal: 0.1, Input: 300000, Total: 310000}},
+		{Type: parser.SessionTypeCron, CronName: "digest", Agent: "urza", ID: "run3", Usage: parser.Usage{CostTotal: 0.1, Output: 9000, Total: 20000}},
+		{Type: parser.SessionTypeInteractive, Agent: "amos", ID: "chat", Usage: parser.Usage{Input: 900000, Total: 900000}},
+	}
+	r := New(sessions, Config{Threshold: 0.5, CronTokens: map[string]TokenThreshold{
+		"*":      {Input: 250000},
+		"digest": {Input: 500000, Output: 8000},
+	}})
+
+	var flagged []Anomaly
+	for _, a := range r.detectAnomalies(sessions) {
+		if a.Type == "expensive_cron_tokens" {
+			flagged = append(flagged, a)
+		}
+	}
+	if len(flagged) != 2 {
+		t.Fatalf("expected sync's input and digest's output flagged, got %+v", flagged)
+	}
+	if flagged[0].SessionID != "run1" || flagged[0].Tokens != 300000 || flagged[0].TokenThreshold != 250000 {
+		t.Errorf("expected run1 over the default input threshold, got %+v", flagged[0])
+	}
+	if flagged[1].SessionID != "run3" || flagged[1].Tokens != 9000 || flagged[1].TokenThreshold != 8000 {
+		t.Errorf("expected run3 over digest's output threshold, got %+v", flagged[1])
+	}
+}
+
+func TestMaintenanceWindowsMuteAnomalies(t *testing.T) {
+	saturday := time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)
+	sessions := []parser.Session{
+		{Type: parser.SessionTypeCron, CronName: "reindex", Agent: "urza", ID: "run1", StartedAt: saturday, Usage: parser.Usage{CostTotal: 2.0}},
+		{Type: parser.SessionTypeCron, CronName: "reindex", Agent: "urza", ID: "run2", StartedAt: saturday.AddDate(0, 0, 1), Usage: parser.Usage{CostTotal: 2.0}},
+		{Type: parser.SessionTypeCron, CronName: "digest", Agent: "urza", ID: "run3", StartedAt: saturday, Usage: parser.Usage{CostTotal: 2.0}},
+	}
+	schedule, err := maintenance.ParseSchedule("0 2 * * 6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New(sessions, Config{Threshold: 0.5, Maintenance: []maintenance.Window{{
+		Name:     "weekly-reindex",
+		Rules:    []string{"expensive_cron"},
+		Crons:    []string{"reindex"},
+		Schedule: schedule,
+		Duration: 4 * time.Hour,
+		Location: time.UTC,
+	}}})
+
+	muted := make(map[string]string)
+	for _, a := range r.detectAnomalies(sessions) {
+		muted[a.SessionID] = a.Muted
+	}
+	if muted["run1"] != "weekly-reindex" {
+		t.Errorf("expected the Saturday reindex run muted, got %q", muted["run1"])
+	}
+	if muted["run2"] != "" || muted["run3"] != "" {
+		t.Errorf("expected runs outside the window or of other crons unmuted, got %v", muted)
+	}
+}
+
+func TestCronOwners(t *testing.T) {
+	sessions := []parser.Session{
+		{Type: parser.SessionTypeCron, CronName: "sync", Agent: "urza", ID: "run1", Usage: parser.Usage{CostTotal: 1.0}},
+		{Type: parser.SessionTypeCron, CronName: "digest", Agent: "urza", ID: "run2", Usage: parser.Usage{CostTotal: 1.0}},
+	}
+	owner := CronOwner{Team: "platform", Email: "platform@example.com", Channel: "#platform-alerts"}
+	r := New(sessions, Config{Threshold: 0.5, Crons: true, CronOwners: map[string]CronOwner{"sync": owner}})
+	report := r.Generate()
+
+	for _, c := range report.ByCron {
+		if (c.Owner != nil) != (c.CronName == "sync") {
+			t.Errorf("cron %s: unexpected owner %v", c.CronName, c.Owner)
+		}
+	}
+	for _, a := range report.Anomalies {
+		if a.Cron == "" {
+			t.Errorf("expected %s anomaly to name its cron", a.Type)
+		}
+		if (a.Owner != nil) != (a.Cron == "sync") {
+			t.Errorf("anomaly for %s: unexpected owner %v", a.Cron, a.Owner)
+		}
+	}
+	if got := owner.String(); got != "platform (#platform-alerts, platform@example.com)" {
+		t.Errorf("unexpected owner description %q", got)
+	}
 }
 
 func TestContainsOpus(t *testing.T) {
@@ -284,3 +502,339 @@ func TestGenerate(t *testing.T) {
 		t.Errorf("expected 1 session detail, got %d", len(report.Sessions))
 	}
 }
+
+func TestGenerateDeterministic(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	sessions := []parser.Session{
+		{Agent: "urza", ID: "a", Type: parser.SessionTypeCron, CronName: "sync", CronID: "s1", StartedAt: start,
+			Usage: parser.Usage{CostTotal: 0.1, Total: 200000, Model: "kimi"}},
+		{Agent: "amos", ID: "b", Type: parser.SessionTypeCron, CronName: "backup", CronID: "b1", StartedAt: start,
+			Usage: parser.Usage{CostTotal: 0.2, Total: 200000, Model: "glm"}},
+		{Agent: "bolas", ID: "c", Type: parser.SessionTypeInteractive, StartedAt: start,
+			Usage: parser.Usage{CostTotal: 0.3, Total: 200000, Model: "kimi"}},
+		{Agent: "urza", ID: "d", Type: parser.SessionTypeCron, CronName: "backup", CronID: "b1", StartedAt: start.Add(time.Minute),
+			Usage: parser.Usage{CostTotal: 0.1, Total: 200000, Model: "glm"}},
+	}
+	// Equal costs everywhere, so only the tie-breakers order the rows.
+	render := func(sessions []parser.Session) string {
+		report := New(sessions, Config{Period: "today", Full: true}).Generate()
+		report.GeneratedAt = time.Time{}
+		data, err := json.Marshal(report)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	want := render(sessions)
+	for i := 0; i < 10; i++ {
+		shuffled := append([]parser.Session(nil), sessions...)
+		rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		if got := render(shuffled); got != want {
+			t.Fatalf("report depends on session order:\n got %s\nwant %s", got, want)
+		}
+	}
+}
+
+func TestGenerateSections(t *testing.T) {
+	sessions := []parser.Session{
+		{Agent: "urza", Type: parser.SessionTypeCron, CronName: "backup", StartedAt: time.Now(),
+			Usage: parser.Usage{CostTotal: 2, Total: 200000, Model: "kimi"}},
+	}
+
+	report := New(sessions, Config{Full: true, Sections: []string{SectionAgents, SectionCrons}}).Generate()
+	if len(report.ByAgent) != 1 || len(report.ByCron) != 1 {
+		t.Errorf("expected the listed sections, got agents %+v, crons %+v", report.ByAgent, report.ByCron)
+	}
+	if report.ByModel != nil || report.ByDay != nil || report.Sessions != nil || report.Anomalies != nil {
+		t.Errorf("expected unlisted sections skipped despite Full, got %+v", report)
+	}
+	if report.TotalCost != 2 || report.Includes(SectionSummary) || !report.Includes(SectionCrons) {
+		t.Errorf("expected totals kept and the sections recorded, got %+v", report)
+	}
+
+	all := New(sessions, Config{}).Generate()
+	if !all.Includes(SectionSummary) || all.Sections != nil || len(all.Anomalies) == 0 {
+		t.Errorf("expected default sections without Sections, got %+v", all)
+	}
+}
+
+func TestDetectDeprecations(t *testing.T) {
+	sessions := []parser.Session{
+		{Agent: "urza", Usage: parser.Usage{CostTotal: 2.0, Input: 100000, Output: 10000, Model: "claude-3-opus-20240229"}},
+		{Agent: "amos", Usage: parser.Usage{CostTotal: 1.0, Input: 50000, Output: 5000, Model: "claude-3-opus-20240229"}},
+		{Agent: "urza", Usage: parser.Usage{CostTotal: 0.5, Input: 10000, Output: 1000, Model: "claude-sonnet-4-5"}},
+	}
+
+	r := New(sessions, Config{})
+	notices := r.detectDeprecations(sessions, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))
+
+	if len(notices) != 1 {
+		t.Fatalf("expected 1 deprecation notice, got %d", len(notices))
+	}
+
+	n := notices[0]
+	if n.Status != "retired" {
+		t.Errorf("expected status retired, got %s", n.Status)
+	}
+	if n.Replacement != "claude-opus-4-5" {
+		t.Errorf("expected replacement claude-opus-4-5, got %s", n.Replacement)
+	}
+	if n.Sessions != 2 {
+		t.Errorf("expected 2 sessions, got %d", n.Sessions)
+	}
+	if len(n.Agents) != 2 || n.Agents[0] != "amos" {
+		t.Errorf("expected agents [amos urza], got %v", n.Agents)
+	}
+	// Both sides at today's rates ($15/$75 against $5/$25), whatever the
+	// sessions were billed.
+	if math.Abs(n.MigratedCost-1.125) > 1e-9 || math.Abs(n.CostDelta-(-2.25)) > 1e-9 {
+		t.Errorf("expected $1.125 migrated and a $2.25 saving, got %f and %f", n.MigratedCost, n.CostDelta)
+	}
+}
+
+func TestAggregateByCronSlot(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, 2, day, hour, 0, 5, 0, time.Local)
+	}
+	sessions := []parser.Session{
+		{Type: parser.SessionTypeCron, CronName: "sync", StartedAt: at(10, 2), Usage: parser.Usage{CostTotal: 1.0}},
+		{Type: parser.SessionTypeCron, CronName: "sync", StartedAt: at(11, 2), Usage: parser.Usage{CostTotal: 1.0}},
+		{Type: parser.SessionTypeCron, CronName: "sync", StartedAt: at(10, 14), Usage: parser.Usage{CostTotal: 0.2}},
+		{Type: parser.SessionTypeCron, CronName: "sync", StartedAt: at(11, 14), Usage: parser.Usage{CostTotal: 0.2}},
+		{Type: parser.SessionTypeInteractive, StartedAt: at(10, 2), Usage: parser.Usage{CostTotal: 5.0}},
+	}
+
+	r := New(sessions, Config{})
+	result := r.aggregateByCronSlot(sessions, r.aggregateByCron(sessions))
+
+	if len(result) != 2 {
+		t.Fatalf("expected 2 slots, got %d", len(result))
+	}
+	if result[0].Slot != "02:00" || result[1].Slot != "14:00" {
+		t.Errorf("expected slots 02:00 and 14:00, got %s and %s", result[0].Slot, result[1].Slot)
+	}
+	if result[0].Runs != 2 || result[0].AvgCost != 1.0 {
+		t.Errorf("expected 02:00 slot with 2 runs at avg 1.0, got %+v", result[0])
+	}
+	if ratio := result[0].Relative / result[1].Relative; math.Abs(ratio-5.0) > 1e-9 {
+		t.Errorf("expected 02:00 slot to be 5x the 14:00 slot, got %.2f vs %.2f", result[0].Relative, result[1].Relative)
+	}
+}
+
+func TestAggregateByCronSlotJitter(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 2, day, hour, minute, 30, 0, time.Local)
+	}
+	run := func(agent, id string, start time.Time, cost float64) parser.Session {
+		return parser.Session{Type: parser.SessionTypeCron, Agent: agent, CronID: id, CronName: "sync", StartedAt: start, Usage: parser.Usage{CostTotal: cost}}
+	}
+	sessions := []parser.Session{
+		// One agent's sync at 04:00, jittered to 04:01 and 04:03.
+		run("amos", "sync-aaaaaa1", at(10, 4, 0), 1.0),
+		run("amos", "sync-aaaaaa1", at(11, 4, 1), 1.0),
+		run("amos", "sync-aaaaaa1", at(12, 4, 3), 1.0),
+		run("amos", "sync-aaaaaa1", at(10, 16, 2), 3.0),
+		// Another agent's sync of the same name, far pricier.
+		run("urza", "sync-bbbbbb2", at(10, 4, 0), 10.0),
+	}
+
+	r := New(sessions, Config{})
+	result := r.aggregateByCronSlot(sessions, r.aggregateByCron(sessions))
+	if len(result) != 3 {
+		t.Fatalf("expected 3 slots, got %+v", result)
+	}
+	if s := result[0]; s.CronID != "sync-aaaaaa1" || s.Agent != "amos" || s.Slot != "04:00" || s.Runs != 3 || math.Abs(s.Relative-2.0/3) > 1e-9 {
+		t.Errorf("expected jittered runs in one 04:00 slot at 0.67x, got %+v", s)
+	}
+	if s := result[1]; s.CronID != "sync-bbbbbb2" || s.Agent != "urza" || s.Slot != "04:00" || s.Relative != 1 {
+		t.Errorf("expected the other sync compared with its own average, got %+v", s)
+	}
+	if s := result[2]; s.CronID != "sync-aaaaaa1" || s.Slot != "16:02" || math.Abs(s.Relative-2) > 1e-9 {
+		t.Errorf("expected a 16:02 slot at 2x, got %+v", s)
+	}
+}
+
+func TestNotesAttached(t *testing.T) {
+	sessions := []parser.Session{
+		{Type: parser.SessionTypeCron, CronName: "sync", StartedAt: time.Date(2026, 2, 10, 10, 0, 0, 0, time.UTC), Usage: parser.Usage{CostTotal: 1.0}},
+		{Type: parser.SessionTypeCron, CronName: "sync", StartedAt: time.Date(2026, 2, 11, 10, 0, 0, 0, time.UTC), Usage: parser.Usage{CostTotal: 2.0}},
+	}
+	annotations := []notes.Note{
+		{Date: "2026-02-11", Text: "enabled caching"},
+		{Cron: "sync", Text: "expected ~$1/run"},
+	}
+
+	r := New(sessions, Config{Notes: annotations})
+	days := r.aggregateByDay(sessions)
+	if len(days[0].Notes) != 0 || len(days[1].Notes) != 1 || days[1].Notes[0] != "enabled caching" {
+		t.Errorf("unexpected day notes: %v / %v", days[0].Notes, days[1].Notes)
+	}
+
+	crons := r.aggregateByCron(sessions)
+	if len(crons[0].Notes) != 1 || crons[0].Notes[0] != "expected ~$1/run" {
+		t.Errorf("unexpected cron notes: %v", crons[0].Notes)
+	}
+}
+
+func TestAggregateByTenant(t *testing.T) {
+	sessions := []parser.Session{
+		{Tenant: "acme", Agent: "main", Usage: parser.Usage{CostTotal: 1.0}},
+		{Tenant: "acme", Agent: "ops", Usage: parser.Usage{CostTotal: 2.0}},
+		{Tenant: "globex", Agent: "main", Usage: parser.Usage{CostTotal: 0.5}},
+	}
+
+	r := New(sessions, Config{})
+	tenants := r.aggregateByTenant(sessions)
+	if len(tenants) != 2 {
+		t.Fatalf("expected 2 tenants, got %d", len(tenants))
+	}
+	if tenants[0].Tenant != "acme" || tenants[0].Agents != 2 || tenants[0].TotalCost != 3.0 {
+		t.Errorf("unexpected first tenant: %+v", tenants[0])
+	}
+
+	// The same agent name under two tenants stays two rows.
+	agents := r.aggregateByAgent(sessions)
+	if len(agents) != 3 {
+		t.Errorf("expected 3 agent rows, got %+v", agents)
+	}
+
+	// Without tenants the dimension is omitted.
+	if got := r.aggregateByTenant([]parser.Session{{Agent: "main"}}); got != nil {
+		t.Errorf("expected no tenant rows, got %+v", got)
+	}
+}
+
+func TestAggregateByWorkspace(t *testing.T) {
+	sessions := []parser.Session{
+		{Workspace: "hq", Agent: "hq/urza", Usage: parser.Usage{CostTotal: 1.0}},
+		{Workspace: "hq", Agent: "hq/pepper", Usage: parser.Usage{CostTotal: 2.0}},
+		{Workspace: "lab", Agent: "lab/urza", Usage: parser.Usage{CostTotal: 0.5}},
+		{Tenant: "acme", Workspace: "hq", Agent: "hq/urza", Usage: parser.Usage{CostTotal: 0.25}},
+		{Agent: "main", Usage: parser.Usage{CostTotal: 4.0}},
+	}
+
+	r := New(sessions, Config{})
+	workspaces := r.aggregateByWorkspace(sessions)
+	if len(workspaces) != 3 {
+		t.Fatalf("expected 3 workspaces, got %+v", workspaces)
+	}
+	if w := workspaces[0]; w.Workspace != "hq" || w.Tenant != "" || w.Agents != 2 || w.TotalCost != 3.0 {
+		t.Errorf("unexpected first workspace: %+v", w)
+	}
+	// A tenant's workspace is its own row.
+	if w := workspaces[2]; w.Workspace != "hq" || w.Tenant != "acme" || w.Agents != 1 {
+		t.Errorf("unexpected tenant workspace: %+v", w)
+	}
+
+	// Without nested agents the dimension is omitted.
+	if got := r.aggregateByWorkspace([]parser.Session{{Agent: "main"}}); got != nil {
+		t.Errorf("expected no workspace rows, got %+v", got)
+	}
+}
+
+func TestSessionCaps(t *testing.T) {
+	sessions := []parser.Session{
+		{ID: "hit", Agent: "urza", Type: parser.SessionTypeInteractive, Usage: parser.Usage{CostTotal: 2.0}},
+		{ID: "near", Agent: "amos", Type: parser.SessionTypeInteractive, Usage: parser.Usage{CostTotal: 0.95}},
+		{ID: "under", Agent: "amos", Type: parser.SessionTypeInteractive, Usage: parser.Usage{CostTotal: 0.5}},
+		// The cron cap takes precedence over urza's session cap.
+		{ID: "cron", Agent: "urza", Type: parser.SessionTypeCron, CronName: "sync", Usage: parser.Usage{CostTotal: 0.3}},
+		// The cap from pepper's config takes precedence over "*", urza's
+		// rule over the one from its config.
+		{ID: "configured", Agent: "pepper", SpendCap: 0.5, Usage: parser.Usage{CostTotal: 0.5}},
+		{ID: "overridden", Agent: "urza", SpendCap: 0.5, Usage: parser.Usage{CostTotal: 1.0}},
+	}
+
+	r := New(sessions, Config{
+		Threshold:   100,
+		SessionCaps: map[string]float64{"urza": 2.0, "*": 1.0},
+		CronCaps:    map[string]float64{"sync": 0.25},
+		CapMargin:   0.1,
+	})
+
+	got := make(map[string]string)
+	for _, a := range r.detectAnomalies(sessions) {
+		got[a.SessionID] = a.Type
+		if a.SessionID == "cron" && a.Threshold != 0.25 {
+			t.Errorf("expected the cron cap as threshold, got %v", a.Threshold)
+		}
+	}
+	expected := map[string]string{"hit": "session_cap_hit", "near": "session_cap_near", "cron": "session_cap_hit", "configured": "session_cap_hit"}
+	if len(got) != len(expected) {
+		t.Errorf("expected %d cap anomalies, got %v", len(expected), got)
+	}
+	for id, typ := range expected {
+		if got[id] != typ {
+			t.Errorf("session %s: expected %s, got %q", id, typ, got[id])
+		}
+	}
+}
+
+func TestAggregateByRole(t *testing.T) {
+	sessions := []parser.Session{
+		{Agent: "urza", TokensByRole: map[string]int{parser.RoleSystem: 60, parser.RoleText: 40}},
+		{Agent: "urza", TokensByRole: map[string]int{parser.RoleToolResult: 100}},
+		{Agent: "amos", TokensByRole: map[string]int{parser.RoleUser: 50}},
+		{Agent: "pepper", Count: 3}, // rebuilt from aggregates, no role data
+	}
+
+	roles := New(sessions, Config{}).aggregateByRole(sessions)
+	if len(roles) != 3 {
+		t.Fatalf("expected urza, amos, and combined rows, got %+v", roles)
+	}
+	if roles[0].Agent != "urza" || roles[0].Total != 200 || roles[0].Share(parser.RoleToolResult) != 0.5 {
+		t.Errorf("unexpected urza row: %+v", roles[0])
+	}
+	if all := roles[2]; all.Agent != "" || all.Total != 250 || all.Tokens[parser.RoleUser] != 50 {
+		t.Errorf("unexpected combined row: %+v", all)
+	}
+}
+
+func TestSessionDetailsCostComposition(t *testing.T) {
+	sessions := []parser.Session{{
+		ID:    "s1",
+		Agent: "urza",
+		Usage: parser.Usage{CostTotal: 0.5, CostInput: 0.2, CostOutput: 0.3, Total: 1000, CacheRead: 600, CacheWrite: 100},
+	}}
+
+	details := New(sessions, Config{}).getSessionDetails(sessions)
+	if len(details) != 1 {
+		t.Fatalf("expected 1 session detail, got %d", len(details))
+	}
+	d := details[0]
+	if d.CostInput != 0.2 || d.CostOutput != 0.3 || d.CacheRead != 600 || d.CacheWrite != 100 {
+		t.Errorf("expected split costs and cache tokens, got %+v", d)
+	}
+}
+
+func TestModelOverride(t *testing.T) {
+	usage := func(model string) parser.Usage {
+		return parser.Usage{Model: model, Input: 100000, Output: 10000, CostTotal: 1.0}
+	}
+	sessions := []parser.Session{
+		{ID: "override", Agent: "urza", Usage: usage("anthropic/claude-opus-4-6")},
+		{ID: "default", Agent: "urza", Usage: usage("moonshotai/kimi-k2.5")},
+		{ID: "cheaper", Agent: "amos", Usage: usage("claude-haiku-4-5")},
+		{ID: "unconfigured", Agent: "pepper", Usage: usage("claude-opus-4-6")},
+		{ID: "uncatalogued", Agent: "urza", Usage: usage("acme/mystery-1")},
+	}
+
+	r := New(sessions, Config{
+		Threshold:   100,
+		AgentModels: map[string]string{"urza": "kimi-k2.5", "amos": "anthropic/claude-sonnet-4-5"},
+	})
+
+	var flagged []string
+	for _, a := range r.detectAnomalies(sessions) {
+		if a.Type == "model_override" {
+			flagged = append(flagged, a.SessionID)
+			if !strings.Contains(a.Description, "instead of the configured kimi-k2.5") {
+				t.Errorf("unexpected description: %s", a.Description)
+			}
+		}
+	}
+	if len(flagged) != 1 || flagged[0] != "override" {
+		t.Errorf("expected only the override session flagged, got %v", flagged)
+	}
+}
@@ -0,0 +1,53 @@
+package reporter
+
+import "slices"
+
+// Report sections that Config.Sections can select.
+const (
+	SectionSummary      = "summary"      // totals and KPIs
+	SectionBudgets      = "budgets"      // consumption of the configured budgets
+	SectionForecast     = "forecast"     // month-to-date spend projected to the end of the month
+	SectionTenants      = "tenants"      // by tenant
+	SectionWorkspaces   = "workspaces"   // by workspace, for nested agent layouts
+	SectionAgents       = "agents"       // by agent
+	SectionTypes        = "types"        // by session type
+	SectionTopics       = "topics"       // by conversation topic, from Config.Topics
+	SectionCrons        = "crons"        // cron ranking, run slots, cache warm-up and context growth
+	SectionModels       = "models"       // by model
+	SectionDays         = "days"         // daily trend
+	SectionMonths       = "months"       // monthly subtotals, by default for ytd and month ranges
+	SectionRoles        = "roles"        // tokens by message role
+	SectionTurns        = "turns"        // turn efficiency
+	SectionEfficiency   = "efficiency"   // cost and time per message by model
+	SectionErrors       = "errors"       // failed requests
+	SectionCascades     = "cascades"     // mid-session escalations to pricier models
+	SectionMix          = "mix"          // each agent's spend by session type over time
+	SectionAnomalies    = "anomalies"    // anomalies
+	SectionDeprecations = "deprecations" // deprecated models
+	SectionCompliance   = "compliance"   // sessions on models disallowed by Config.ModelPolicies
+	SectionSessions     = "sessions"     // most expensive sessions
+)
+
+// Sections lists the report sections, in report order.
+var Sections = []string{
+	SectionSummary, SectionBudgets, SectionForecast, SectionTenants, SectionWorkspaces,
+	SectionAgents, SectionTypes, SectionTopics, SectionCrons, SectionModels, SectionDays, SectionMonths,
+	SectionRoles, SectionTurns, SectionEfficiency, SectionErrors, SectionCascades,
+	SectionMix, SectionAnomalies, SectionDeprecations, SectionCompliance, SectionSessions,
+}
+
+// Includes reports whether the report was generated with section. Reports
+// generated without Config.Sections include every section they have data
+// for.
+func (r Report) Includes(section string) bool {
+	return r.Sections == nil || slices.Contains(r.Sections, section)
+}
+
+// include reports whether section is generated: when Config.Sections is
+// set, whether it is listed, and otherwise byDefault.
+func (r *Reporter) include(section string, byDefault bool) bool {
+	if r.config.Sections == nil {
+		return byDefault
+	}
+	return slices.Contains(r.config.Sections, section)
+}
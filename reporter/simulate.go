@@ -0,0 +1,211 @@
+package reporter
+
+import (
+	"sort"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// Limits are stricter generation settings to replay history under. Zero
+// fields are not applied.
+type Limits struct {
+	MaxOutputTokens int `json:"max_output_tokens,omitempty"` // output tokens per model request
+	MaxInputTokens  int `json:"max_input_tokens,omitempty"`  // uncached input tokens per model request
+	MaxTurns        int `json:"max_turns,omitempty"`         // model requests per session
+}
+
+// Simulation is what sessions would have cost under Limits.
+type Simulation struct {
+	Limits    Limits           `json:"limits"`
+	Sessions  int              `json:"sessions"`
+	Requests  int              `json:"requests"`
+	Capped    int              `json:"capped"`  // requests truncated by a token limit
+	Dropped   int              `json:"dropped"` // requests past MaxTurns
+	Affected  int              `json:"affected_sessions"`
+	Cost      float64          `json:"cost"`
+	Simulated float64          `json:"simulated_cost"`
+	Savings   float64          `json:"savings"`
+	ByAgent   []SimulatedGroup `json:"by_agent"`
+	ByCron    []SimulatedGroup `json:"by_cron,omitempty"`
+}
+
+// SimulatedGroup is the simulated spend of one agent or cron.
+type SimulatedGroup struct {
+	Key       string  `json:"key"`
+	Sessions  int     `json:"sessions"`
+	Affected  int     `json:"affected_sessions"`
+	Cost      float64 `json:"cost"`
+	Simulated float64 `json:"simulated_cost"`
+	Savings   float64 `json:"savings"`
+}
+
+// Simulate recomputes what sessions would have cost under limits. Each
+// request's output (or input) cost is scaled down by the share of tokens
+// the limit cuts off, and requests past MaxTurns are dropped. It is a
+// first-order estimate: a truncated reply would also shrink the context of
+// later requests, which is not modeled. Sessions rebuilt from stored
+// aggregates carry no requests and are left out.
+func Simulate(sessions []parser.Session, limits Limits) Simulation {
+	sim := Simulation{Limits: limits}
+	agents := make(map[string]*SimulatedGroup)
+	crons := make(map[string]*SimulatedGroup)
+
+	for _, s := range individualSessions(sessions) {
+		var cost, simulated float64
+		affected := false
+		for i, m := range s.Messages {
+			u := m.Message.Usage
+			sim.Requests++
+			cost += u.Cost.Total
+
+			if limits.MaxTurns > 0 && i >= limits.MaxTurns {
+				sim.Dropped++
+				affected = true
+				continue
+			}
+
+			c := u.Cost.Total
+			capped := false
+			if limits.MaxOutputTokens > 0 && u.Output > limits.MaxOutputTokens {
+				c -= u.Cost.Output * float64(u.Output-limits.MaxOutputTokens) / float64(u.Output)
+				capped = true
+			}
+			if limits.MaxInputTokens > 0 && u.Input > limits.MaxInputTokens {
+				c -= u.Cost.Input * float64(u.Input-limits.MaxInputTokens) / float64(u.Input)
+				capped = true
+			}
+			if capped {
+				sim.Capped++
+				affected = true
+			}
+			simulated += c
+		}
+
+		sim.Sessions++
+		sim.Cost += cost
+		sim.Simulated += simulated
+		if affected {
+			sim.Affected++
+		}
+
+		name := s.Agent
+		if s.Tenant != "" {
+			name = s.Tenant + "/" + s.Agent
+		}
+		addSimulated(agents, name, cost, simulated, affected)
+		if s.Type == parser.SessionTypeCron {
+			addSimulated(crons, s.CronName, cost, simulated, affected)
+		}
+	}
+
+	sim.Savings = sim.Cost - sim.Simulated
+	sim.ByAgent = sortSimulated(agents)
+	sim.ByCron = sortSimulated(crons)
+	return sim
+}
+
+func addSimulated(groups map[string]*SimulatedGroup, key string, cost, simulated float64, affected bool) {
+	g, ok := groups[key]
+	if !ok {
+		g = &SimulatedGroup{Key: key}
+		groups[key] = g
+	}
+	g.Sessions++
+	g.Cost += cost
+	g.Simulated += simulated
+	g.Savings = g.Cost - g.Simulated
+	if affected {
+		g.Affected++
+	}
+}
+
+// sortSimulated orders groups by savings, largest first, then by key.
+func sortSimulated(groups map[string]*SimulatedGroup) []SimulatedGroup {
+	result := make([]SimulatedGroup, 0, len(groups))
+	for _, g := range groups {
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Savings != result[j].Savings {
+			return result[i].Savings > result[j].Savings
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// CapSimulation is when a daily spend cap would have been hit on each of
+// a range of past days, to choose a cap before enforcing one.
+type CapSimulation struct {
+	Cap      float64  `json:"daily_cap"`
+	Days     []CapDay `json:"days"`
+	DaysHit  int      `json:"days_hit"`
+	Earliest string   `json:"earliest_hit,omitempty"` // earliest time of day a cap was hit, HH:MM
+	MaxDaily float64  `json:"max_daily"`              // the lowest cap that would never have been hit is just above it
+	Spent    float64  `json:"spent"`
+	Blocked  float64  `json:"blocked"` // spend after the cap was hit, which enforcement would have stopped
+}
+
+// CapDay is one day of a CapSimulation.
+type CapDay struct {
+	Date    string     `json:"date"` // YYYY-MM-DD
+	Spent   float64    `json:"spent"`
+	HitAt   *time.Time `json:"hit_at,omitempty"`
+	Blocked float64    `json:"blocked,omitempty"`
+}
+
+// SimulateDailyCap replays the requests of sessions made in [from, until)
+// against a daily spend cap, finding when each day's running spend would
+// have reached it. Days run midnight to midnight in until's location, and
+// the request that reaches the cap counts as allowed. Sessions rebuilt from
+// stored aggregates carry no requests and are left out.
+func SimulateDailyCap(sessions []parser.Session, dailyCap float64, from, until time.Time) CapSimulation {
+	sim := CapSimulation{Cap: dailyCap}
+	loc := until.Location()
+
+	type spend struct {
+		at   time.Time
+		cost float64
+	}
+	byDay := make(map[string][]spend)
+	for _, s := range individualSessions(sessions) {
+		for _, m := range s.Messages {
+			cost := m.Message.Usage.Cost.Total
+			if cost == 0 || m.Timestamp.Before(from) || !m.Timestamp.Before(until) {
+				continue
+			}
+			day := m.Timestamp.In(loc).Format(time.DateOnly)
+			byDay[day] = append(byDay[day], spend{m.Timestamp.In(loc), cost})
+		}
+	}
+
+	start := from.In(loc)
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc); day.Before(until); day = day.AddDate(0, 0, 1) {
+		d := CapDay{Date: day.Format(time.DateOnly)}
+		requests := byDay[d.Date]
+		sort.SliceStable(requests, func(i, j int) bool { return requests[i].at.Before(requests[j].at) })
+		for _, r := range requests {
+			if d.HitAt != nil {
+				d.Blocked += r.cost
+			}
+			d.Spent += r.cost
+			if d.HitAt == nil && d.Spent >= dailyCap {
+				at := r.at
+				d.HitAt = &at
+			}
+		}
+
+		sim.Spent += d.Spent
+		sim.Blocked += d.Blocked
+		sim.MaxDaily = max(sim.MaxDaily, d.Spent)
+		if d.HitAt != nil {
+			sim.DaysHit++
+			if clock := d.HitAt.Format("15:04"); sim.Earliest == "" || clock < sim.Earliest {
+				sim.Earliest = clock
+			}
+		}
+		sim.Days = append(sim.Days, d)
+	}
+	return sim
+}
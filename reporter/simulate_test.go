@@ -0,0 +1,97 @@
+package reporter
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// request builds an assistant message with the given tokens, priced at
+// $1 per 1k input and $2 per 1k output tokens.
+func request(input, output int) parser.Message {
+	var m parser.Message
+	m.Type = "message"
+	m.Message.Role = "assistant"
+	m.Message.Usage.Input = input
+	m.Message.Usage.Output = output
+	m.Message.Usage.Cost.Input = float64(input) / 1000
+	m.Message.Usage.Cost.Output = float64(output) * 2 / 1000
+	m.Message.Usage.Cost.Total = m.Message.Usage.Cost.Input + m.Message.Usage.Cost.Output
+	return m
+}
+
+func TestSimulate(t *testing.T) {
+	sessions := []parser.Session{
+		{Agent: "amos", Type: parser.SessionTypeCron, CronName: "sync",
+			Messages: []parser.Message{request(1000, 4000), request(1000, 1000)}},
+		{Agent: "kaylee", Tenant: "acme",
+			Messages: []parser.Message{request(5000, 500), request(1000, 500), request(1000, 500)}},
+		// Aggregates have no requests to replay.
+		{Agent: "amos", Count: 3, Usage: parser.Usage{CostTotal: 9}},
+	}
+
+	sim := Simulate(sessions, Limits{MaxOutputTokens: 2000})
+	if sim.Sessions != 2 || sim.Requests != 5 || sim.Capped != 1 || sim.Dropped != 0 || sim.Affected != 1 {
+		t.Errorf("unexpected counts: %+v", sim)
+	}
+	// amos: 1 + 8 + 1 + 2 = 12, capped to 1 + 4 + 1 + 2 = 8
+	assertCost(t, "savings", sim.Savings, 4)
+	assertCost(t, "cost", sim.Cost, 12+10)
+	if len(sim.ByAgent) != 2 || sim.ByAgent[0].Key != "amos" || sim.ByAgent[1].Key != "acme/kaylee" {
+		t.Fatalf("unexpected agents: %+v", sim.ByAgent)
+	}
+	if sim.ByAgent[1].Savings != 0 || sim.ByAgent[1].Affected != 0 {
+		t.Errorf("expected kaylee unaffected, got %+v", sim.ByAgent[1])
+	}
+	if len(sim.ByCron) != 1 || sim.ByCron[0].Key != "sync" {
+		t.Fatalf("unexpected crons: %+v", sim.ByCron)
+	}
+	assertCost(t, "cron savings", sim.ByCron[0].Savings, 4)
+
+	sim = Simulate(sessions, Limits{MaxInputTokens: 2000, MaxTurns: 2})
+	if sim.Capped != 1 || sim.Dropped != 1 || sim.Affected != 1 {
+		t.Errorf("unexpected counts: %+v", sim)
+	}
+	// kaylee: input 5 capped to 2, third request (2) dropped
+	assertCost(t, "savings", sim.Savings, 3+2)
+}
+
+func TestSimulateDailyCap(t *testing.T) {
+	at := func(day, hour int, input int) parser.Message {
+		m := request(input, 0)
+		m.Timestamp = time.Date(2026, 6, day, hour, 0, 0, 0, time.UTC)
+		return m
+	}
+	sessions := []parser.Session{
+		{Agent: "amos", Messages: []parser.Message{at(10, 9, 4000), at(10, 14, 3000)}},
+		{Agent: "kaylee", Messages: []parser.Message{at(10, 11, 3000), at(11, 8, 2000), at(12, 7, 6000)}},
+		// Before the range.
+		{Agent: "amos", Messages: []parser.Message{at(8, 9, 50000)}},
+	}
+
+	from := time.Date(2026, 6, 10, 0, 0, 0, 0, time.UTC)
+	sim := SimulateDailyCap(sessions, 5, from, from.AddDate(0, 0, 4))
+	if len(sim.Days) != 4 || sim.DaysHit != 2 || sim.Earliest != "07:00" {
+		t.Fatalf("unexpected simulation: %+v", sim)
+	}
+	// June 10: $4 at 9:00, $7 at 11:00 hits the cap, $3 at 14:00 is blocked.
+	day := sim.Days[0]
+	if day.HitAt == nil || day.HitAt.Hour() != 11 {
+		t.Errorf("expected the cap hit at 11:00 on June 10, got %+v", day)
+	}
+	assertCost(t, "blocked", day.Blocked, 3)
+	if sim.Days[1].HitAt != nil || sim.Days[3].Spent != 0 {
+		t.Errorf("expected June 11 under the cap and June 13 idle, got %+v", sim.Days)
+	}
+	assertCost(t, "max daily", sim.MaxDaily, 10)
+	assertCost(t, "total blocked", sim.Blocked, 3)
+}
+
+func assertCost(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
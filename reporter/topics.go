@@ -0,0 +1,73 @@
+package reporter
+
+import (
+	"strings"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// TopicOther is the topic of sessions no topic rule matches.
+const TopicOther = "other"
+
+// TopicRule assigns a topic to the sessions whose title (first prompt) or
+// cron name contains any of its keywords, ignoring case.
+type TopicRule struct {
+	Topic    string
+	Keywords []string
+}
+
+// TopicSummary aggregates costs by conversation topic.
+type TopicSummary struct {
+	Topic       string  `json:"topic"`
+	Sessions    int     `json:"sessions"`
+	TotalCost   float64 `json:"total_cost"`
+	TotalTokens int     `json:"total_tokens"`
+	Share       float64 `json:"share"` // percent of the period's cost
+}
+
+// Topic returns the topic of the first rule matching s, or TopicOther.
+// Sessions rebuilt from stored aggregates have no title and only match on
+// their cron name.
+func Topic(rules []TopicRule, s parser.Session) string {
+	title := strings.ToLower(s.Title)
+	cron := strings.ToLower(s.CronName)
+	for _, rule := range rules {
+		for _, keyword := range rule.Keywords {
+			k := strings.ToLower(keyword)
+			if k != "" && (strings.Contains(title, k) || strings.Contains(cron, k)) {
+				return rule.Topic
+			}
+		}
+	}
+	return TopicOther
+}
+
+// aggregateByTopic groups sessions by Config.Topics, costliest first with
+// unmatched sessions last.
+func (r *Reporter) aggregateByTopic(sessions []parser.Session, totalCost float64) []TopicSummary {
+	topics := Dimension[string, TopicSummary]{
+		Key: func(s parser.Session) (string, bool) { return Topic(r.config.Topics, s), true },
+		Build: func(topic string, acc *Accumulator) TopicSummary {
+			t := TopicSummary{
+				Topic:       topic,
+				Sessions:    acc.Sessions,
+				TotalCost:   acc.TotalCost,
+				TotalTokens: acc.TotalTokens,
+			}
+			if totalCost > 0 {
+				t.Share = acc.TotalCost / totalCost * 100
+			}
+			return t
+		},
+		Less: func(a, b TopicSummary) bool {
+			if (a.Topic == TopicOther) != (b.Topic == TopicOther) {
+				return b.Topic == TopicOther
+			}
+			if a.TotalCost != b.TotalCost {
+				return a.TotalCost > b.TotalCost
+			}
+			return a.Topic < b.Topic
+		},
+	}
+	return topics.Aggregate(sessions)
+}
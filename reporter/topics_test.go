@@ -0,0 +1,36 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestGenerateByTopic(t *testing.T) {
+	sessions := []parser.Session{
+		{Agent: "urza", Title: "Please REVIEW this pull request", Usage: parser.Usage{CostTotal: 2, Total: 100}},
+		{Agent: "urza", Title: "Research vector databases", Usage: parser.Usage{CostTotal: 1, Total: 50}},
+		{Agent: "amos", Type: parser.SessionTypeCron, CronName: "nightly-review", Usage: parser.Usage{CostTotal: 3, Total: 200}},
+		{Agent: "amos", Title: "hello", Usage: parser.Usage{CostTotal: 4, Total: 10}},
+	}
+	rules := []TopicRule{
+		{Topic: "code review", Keywords: []string{"review", "pull request"}},
+		{Topic: "research", Keywords: []string{"research"}},
+	}
+
+	report := New(sessions, Config{Topics: rules}).Generate()
+	if len(report.ByTopic) != 3 {
+		t.Fatalf("expected 3 topics, got %+v", report.ByTopic)
+	}
+	review := report.ByTopic[0]
+	if review.Topic != "code review" || review.Sessions != 2 || review.TotalCost != 5 || review.Share != 50 {
+		t.Errorf("expected code review first with half the cost, got %+v", review)
+	}
+	if report.ByTopic[1].Topic != "research" || report.ByTopic[2].Topic != TopicOther {
+		t.Errorf("expected unmatched sessions last, got %+v", report.ByTopic)
+	}
+
+	if report := New(sessions, Config{}).Generate(); report.ByTopic != nil {
+		t.Errorf("expected no topics without rules, got %+v", report.ByTopic)
+	}
+}
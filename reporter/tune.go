@@ -0,0 +1,81 @@
+package reporter
+
+import (
+	"math"
+	"sort"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// ThresholdSuggestion proposes an expensive_cron threshold for one cron
+// derived from its own run history.
+type ThresholdSuggestion struct {
+	CronName   string  `json:"cron_name"`
+	Runs       int     `json:"runs"`
+	Percentile float64 `json:"percentile"` // cost at the requested percentile
+	Suggested  float64 `json:"suggested"`  // Percentile × factor
+}
+
+// TuneOptions controls how thresholds are derived.
+type TuneOptions struct {
+	Percentile float64 // e.g. 95
+	Factor     float64 // headroom multiplier, e.g. 1.5
+	MinRuns    int     // crons with fewer runs get no suggestion
+}
+
+// SuggestThresholds computes per-cron thresholds as the given percentile of
+// run cost times a headroom factor, so each cron is judged against its own
+// history instead of a single global guess.
+func SuggestThresholds(sessions []parser.Session, opts TuneOptions) []ThresholdSuggestion {
+	costs := make(map[string][]float64)
+	for _, s := range individualSessions(sessions) {
+		if s.Type != parser.SessionTypeCron {
+			continue
+		}
+		costs[s.CronName] = append(costs[s.CronName], s.Usage.CostTotal)
+	}
+
+	var result []ThresholdSuggestion
+	for name, runs := range costs {
+		if len(runs) < opts.MinRuns {
+			continue
+		}
+		p := percentile(runs, opts.Percentile)
+		result = append(result, ThresholdSuggestion{
+			CronName:   name,
+			Runs:       len(runs),
+			Percentile: p,
+			Suggested:  roundCents(p * opts.Factor),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CronName < result[j].CronName
+	})
+	return result
+}
+
+// percentile returns the nearest-rank percentile (0-100) of values.
+func percentile(values []float64, pct float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(pct / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// roundCents rounds up to the next cent so tiny crons still get a usable
+// threshold. Floating-point noise (5 × 1.2 = 6.000000000000001) is
+// rounded away first.
+func roundCents(v float64) float64 {
+	return math.Ceil(math.Round(v*1e6)/1e4) / 100
+}
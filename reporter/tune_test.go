@@ -0,0 +1,68 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestPercentile(t *testing.T) {
+	values := []float64{5, 1, 4, 2, 3, 6, 7, 8, 9, 10}
+	tests := []struct {
+		pct      float64
+		expected float64
+	}{
+		{50, 5},
+		{90, 9},
+		{95, 10},
+		{100, 10},
+		{0, 1},
+	}
+	for _, tt := range tests {
+		if got := percentile(values, tt.pct); got != tt.expected {
+			t.Errorf("percentile(%v) = %v, want %v", tt.pct, got, tt.expected)
+		}
+	}
+}
+
+func TestSuggestThresholds(t *testing.T) {
+	var sessions []parser.Session
+	for i := 1; i <= 20; i++ {
+		sessions = append(sessions, parser.Session{
+			Type: parser.SessionTypeCron, CronName: "sync", Usage: parser.Usage{CostTotal: float64(i) * 0.01},
+		})
+	}
+	sessions = append(sessions,
+		parser.Session{Type: parser.SessionTypeCron, CronName: "rare", Usage: parser.Usage{CostTotal: 1.0}},
+		parser.Session{Type: parser.SessionTypeInteractive, Usage: parser.Usage{CostTotal: 9.0}},
+	)
+
+	result := SuggestThresholds(sessions, TuneOptions{Percentile: 95, Factor: 1.5, MinRuns: 5})
+	if len(result) != 1 {
+		t.Fatalf("expected 1 suggestion, got %+v", result)
+	}
+
+	s := result[0]
+	if s.CronName != "sync" || s.Runs != 20 {
+		t.Errorf("unexpected suggestion: %+v", s)
+	}
+	if s.Percentile != 0.19 {
+		t.Errorf("expected p95 0.19, got %v", s.Percentile)
+	}
+	if s.Suggested != 0.29 {
+		t.Errorf("expected suggested 0.29 (0.285 rounded up), got %v", s.Suggested)
+	}
+}
+
+func TestCronThresholdOverride(t *testing.T) {
+	sessions := []parser.Session{
+		{Type: parser.SessionTypeCron, CronName: "big", ID: "a", Usage: parser.Usage{CostTotal: 1.0}},
+		{Type: parser.SessionTypeCron, CronName: "small", ID: "b", Usage: parser.Usage{CostTotal: 0.3}},
+	}
+
+	r := New(sessions, Config{Threshold: 0.5, CronThresholds: map[string]float64{"big": 2.0, "small": 0.2}})
+	anomalies := r.detectAnomalies(sessions)
+	if len(anomalies) != 1 || anomalies[0].SessionID != "b" {
+		t.Errorf("expected only the small cron to be flagged, got %+v", anomalies)
+	}
+}
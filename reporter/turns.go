@@ -0,0 +1,117 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// TurnSummary describes how an agent spends its tokens across assistant
+// turns. Many small turns each repeat the context, so a low token count per
+// turn (and a low output/input ratio) means a larger share goes to overhead.
+type TurnSummary struct {
+	Agent            string  `json:"agent"` // "" for all agents combined
+	Sessions         int     `json:"sessions"`
+	Turns            int     `json:"turns"`
+	TurnsPerSession  float64 `json:"turns_per_session"`
+	AvgTokensPerTurn float64 `json:"avg_tokens_per_turn"`
+	OutputInputRatio float64 `json:"output_input_ratio"`
+	Chatty           bool    `json:"chatty,omitempty"`
+}
+
+// An agent is chatty when it has at least chattyMinTurns turns averaging
+// fewer than chattyShare of the fleet's tokens per turn.
+const (
+	chattyShare    = 0.5
+	chattyMinTurns = 20
+)
+
+// aggregateByTurn summarizes turns per agent, most turns first, followed by
+// a combined row for all agents. Sessions rebuilt from stored aggregates
+// have no turns and are left out.
+func (r *Reporter) aggregateByTurn(sessions []parser.Session) []TurnSummary {
+	type totals struct {
+		sessions, turns, tokens, input, output int
+	}
+	byAgent := make(map[string]*totals)
+	var all totals
+	for _, s := range individualSessions(sessions) {
+		if s.Turns() == 0 {
+			continue
+		}
+		name := s.Agent
+		if s.Tenant != "" {
+			name = s.Tenant + "/" + s.Agent
+		}
+		t, ok := byAgent[name]
+		if !ok {
+			t = &totals{}
+			byAgent[name] = t
+		}
+		for _, acc := range []*totals{t, &all} {
+			acc.sessions++
+			acc.turns += s.Turns()
+			acc.tokens += s.Usage.Total
+			acc.input += s.Usage.Input
+			acc.output += s.Usage.Output
+		}
+	}
+	if len(byAgent) == 0 {
+		return nil
+	}
+
+	summarize := func(agent string, t totals) TurnSummary {
+		summary := TurnSummary{
+			Agent:            agent,
+			Sessions:         t.sessions,
+			Turns:            t.turns,
+			TurnsPerSession:  float64(t.turns) / float64(t.sessions),
+			AvgTokensPerTurn: float64(t.tokens) / float64(t.turns),
+		}
+		if t.input > 0 {
+			summary.OutputInputRatio = float64(t.output) / float64(t.input)
+		}
+		return summary
+	}
+
+	fleet := summarize("", all)
+	result := make([]TurnSummary, 0, len(byAgent)+1)
+	for agent, t := range byAgent {
+		summary := summarize(agent, *t)
+		summary.Chatty = len(byAgent) > 1 && summary.Turns >= chattyMinTurns &&
+			summary.AvgTokensPerTurn < chattyShare*fleet.AvgTokensPerTurn
+		result = append(result, summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Turns != result[j].Turns {
+			return result[i].Turns > result[j].Turns
+		}
+		return result[i].Agent < result[j].Agent
+	})
+	return append(result, fleet)
+}
+
+// detectChattyAgents flags agents whose turns are much smaller than the
+// fleet's.
+func (r *Reporter) detectChattyAgents(sessions []parser.Session) []Anomaly {
+	turns := r.aggregateByTurn(sessions)
+	if len(turns) == 0 {
+		return nil
+	}
+	fleet := turns[len(turns)-1]
+
+	var anomalies []Anomaly
+	for _, t := range turns {
+		if !t.Chatty {
+			continue
+		}
+		anomalies = append(anomalies, Anomaly{
+			Type:        "chatty_agent",
+			Description: fmt.Sprintf("Agent %s averages %.0f tokens per turn over %d turns, under half the fleet's %.0f; many small turns repeat context overhead", t.Agent, t.AvgTokensPerTurn, t.Turns, fleet.AvgTokensPerTurn),
+			Severity:    "info",
+			Agent:       t.Agent,
+		})
+	}
+	return anomalies
+}
@@ -0,0 +1,61 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// turnSession builds a session with n assistant turns of tokens each.
+func turnSession(agent string, n, tokens int) parser.Session {
+	return parser.Session{
+		Agent:    agent,
+		Messages: make([]parser.Message, n),
+		Usage:    parser.Usage{Input: n * tokens * 3 / 4, Output: n * tokens / 4, Total: n * tokens},
+	}
+}
+
+func TestAggregateByTurn(t *testing.T) {
+	sessions := []parser.Session{
+		turnSession("kaylee", 40, 100), // many tiny turns
+		turnSession("kaylee", 40, 100),
+		turnSession("urza", 10, 4000),
+		turnSession("amos", 5, 4000),
+		{Agent: "amos", Count: 3, Usage: parser.Usage{Total: 1000}}, // rebuilt aggregate, no turns
+	}
+
+	r := New(sessions, Config{Turns: true})
+	turns := r.Generate().ByTurn
+	if len(turns) != 4 {
+		t.Fatalf("expected 3 agents and a combined row, got %+v", turns)
+	}
+
+	kaylee := turns[0]
+	if kaylee.Agent != "kaylee" || kaylee.Turns != 80 || kaylee.TurnsPerSession != 40 || kaylee.AvgTokensPerTurn != 100 {
+		t.Errorf("unexpected kaylee summary: %+v", kaylee)
+	}
+	if kaylee.OutputInputRatio < 0.33 || kaylee.OutputInputRatio > 0.34 {
+		t.Errorf("expected output/input ratio 1/3, got %v", kaylee.OutputInputRatio)
+	}
+	if !kaylee.Chatty {
+		t.Error("expected kaylee to be flagged chatty")
+	}
+	for _, turn := range turns[1:] {
+		if turn.Chatty {
+			t.Errorf("expected %q not to be chatty", turn.Agent)
+		}
+	}
+	if all := turns[3]; all.Agent != "" || all.Sessions != 4 || all.Turns != 95 {
+		t.Errorf("unexpected combined row: %+v", all)
+	}
+
+	var chatty []Anomaly
+	for _, a := range r.detectAnomalies(sessions) {
+		if a.Type == "chatty_agent" {
+			chatty = append(chatty, a)
+		}
+	}
+	if len(chatty) != 1 || chatty[0].Agent != "kaylee" || chatty[0].Severity != "info" {
+		t.Errorf("expected one chatty_agent anomaly for kaylee, got %+v", chatty)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/misty-step/costctl/catalog"
+	"github.com/misty-step/costctl/formats"
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/reporter"
+	"github.com/misty-step/costctl/store"
+	"github.com/spf13/cobra"
+)
+
+// selftest command flags
+var selftestRequireStatic bool
+
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Check that embedded resources and store drivers work on this host",
+	Long: `Run a series of checks against this binary on the current platform: that it
+was built without cgo, that the embedded HTML template and model catalog
+load, and that the SQLite and file stores can write and read back data in a
+temporary directory. Postgres needs a server, so only its driver is checked.
+
+Run it after copying costctl to a new or locked-down host. It exits non-zero
+if any check fails.
+
+Examples:
+  costctl selftest
+  costctl selftest --require-static   # also fail on a cgo build`,
+	RunE: runSelftest,
+}
+
+func init() {
+	selftestCmd.Flags().BoolVar(&selftestRequireStatic, "require-static", false, "Fail if the binary was built with cgo")
+}
+
+// selftestCheck is one check run by selftest. It returns a short detail
+// on success.
+type selftestCheck struct {
+	name string
+	run  func(ctx context.Context, dir string) (string, error)
+}
+
+var selftestChecks = []selftestCheck{
+	{"build", checkBuild},
+	{"html template", checkHTMLTemplate},
+	{"model catalog", checkCatalog},
+	{"store drivers", checkDrivers},
+	{"file store", func(ctx context.Context, dir string) (string, error) {
+		return checkStore(ctx, "file:"+filepath.Join(dir, "history"))
+	}},
+	{"sqlite store", func(ctx context.Context, dir string) (string, error) {
+		return checkStore(ctx, "sqlite:"+filepath.Join(dir, "history.db"))
+	}},
+}
+
+func runSelftest(cmd *cobra.Command, args []string) error {
+	dir, err := os.MkdirTemp("", "costctl-selftest-")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	failed := 0
+	for _, check := range selftestChecks {
+		detail, err := check.run(ctx, dir)
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL  %-14s %v\n", check.name, err)
+			continue
+		}
+		fmt.Printf("ok    %-14s %s\n", check.name, detail)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(selftestChecks))
+	}
+	return nil
+}
+
+// checkBuild reports the platform and whether the binary is static.
+func checkBuild(ctx context.Context, dir string) (string, error) {
+	platform := fmt.Sprintf("costctl %s, %s %s/%s", version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	cgo := "unknown"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "CGO_ENABLED" {
+				cgo = s.Value
+			}
+		}
+	}
+	switch {
+	case cgo == "0":
+		return platform + ", static (cgo disabled)", nil
+	case selftestRequireStatic:
+		return "", fmt.Errorf("%s, built with CGO_ENABLED=%s; rebuild with CGO_ENABLED=0", platform, cgo)
+	default:
+		return fmt.Sprintf("%s, CGO_ENABLED=%s (build with CGO_ENABLED=0 for a static binary)", platform, cgo), nil
+	}
+}
+
+// checkHTMLTemplate renders a small report with the embedded template.
+func checkHTMLTemplate(ctx context.Context, dir string) (string, error) {
+	now := time.Now()
+	sessions := []parser.Session{
+		{ID: "a", Agent: "selftest", Type: parser.SessionTypeCron, CronName: "sync", StartedAt: now.Add(-time.Hour),
+			Usage: parser.Usage{Total: 1500, CostTotal: 0.75, Model: "anthropic/claude-sonnet-4"}},
+		{ID: "b", Agent: "selftest", Type: parser.SessionTypeInteractive, StartedAt: now.Add(-2 * time.Hour),
+			Usage: parser.Usage{Total: 500, CostTotal: 0.25, Model: "anthropic/claude-sonnet-4"}},
+	}
+	r := reporter.New(sessions, reporter.Config{Full: true, Threshold: 0.5}).Generate()
+	out, err := formats.NewHTMLFormatter().Format(r)
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains(out, "<h1>OpenClaw Cost Report</h1>") || !strings.Contains(out, "selftest") {
+		return "", fmt.Errorf("rendered report is missing expected content")
+	}
+	return fmt.Sprintf("rendered %d bytes", len(out)), nil
+}
+
+// checkCatalog looks up a known model in the embedded catalog.
+func checkCatalog(ctx context.Context, dir string) (string, error) {
+	models := catalog.All()
+	if len(models) == 0 {
+		return "", fmt.Errorf("catalog is empty")
+	}
+	if _, ok := catalog.Lookup(models[0].ID); !ok {
+		return "", fmt.Errorf("lookup of %s failed", models[0].ID)
+	}
+	return fmt.Sprintf("%d models", len(models)), nil
+}
+
+// checkDrivers confirms the SQL store drivers are compiled in.
+func checkDrivers(ctx context.Context, dir string) (string, error) {
+	drivers := sql.Drivers()
+	for _, name := range []string{"sqlite", "postgres"} {
+		if !slices.Contains(drivers, name) {
+			return "", fmt.Errorf("%s driver missing (have %s)", name, strings.Join(drivers, ", "))
+		}
+	}
+	return "sqlite, postgres", nil
+}
+
+// checkStore writes aggregates to the store at dsn and reads them back.
+func checkStore(ctx context.Context, dsn string) (string, error) {
+	s, err := store.Open(dsn)
+	if err != nil {
+		return "", err
+	}
+	defer s.Close()
+
+	rows := []store.DailyAggregate{
+		{Date: "2026-01-01", Host: "selftest", Agent: "selftest", SessionType: parser.SessionTypeCron, CronName: "sync", Model: "m", Sessions: 2, TotalCost: 1.5, TotalTokens: 300},
+		{Date: "2026-01-02", Host: "selftest", Agent: "selftest", SessionType: parser.SessionTypeInteractive, Model: "m", Sessions: 1, TotalCost: 0.25, TotalTokens: 100},
+	}
+	if err := s.Put(ctx, rows); err != nil {
+		return "", fmt.Errorf("write failed: %w", err)
+	}
+	got, err := s.Query(ctx, store.Query{Since: "2026-01-02"})
+	if err != nil {
+		return "", fmt.Errorf("read failed: %w", err)
+	}
+	if len(got) != 1 || got[0] != rows[1] {
+		return "", fmt.Errorf("read back %+v, want %+v", got, rows[1:])
+	}
+	return "write and read back ok", nil
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/misty-step/costctl/formats"
+	"github.com/misty-step/costctl/report"
+	"github.com/misty-step/costctl/reporter"
+	"github.com/misty-step/costctl/server"
+	"github.com/spf13/cobra"
+)
+
+// serve command flags
+var (
+	serveAddr   string
+	serveTTL    time.Duration
+	servePeriod string
+)
+
+// serveParams are the query parameters report requests accept.
+var serveParams = []string{"period", "agent", "cron", "workspace", "sections"}
+
+// serveShutdownTimeout bounds how long in-flight requests may finish once
+// the server is interrupted.
+const serveShutdownTimeout = 10 * time.Second
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve reports as an HTML dashboard and JSON API",
+	Long: `Run an HTTP server that renders reports on request, so a team can share a
+browser view instead of each running the CLI:
+
+  /              the HTML report, with links to each period
+  /api/report    the JSON report
+  /metrics       the server's own metrics, in Prometheus format
+  /healthz       200 while reports refresh, 503 when one keeps failing
+
+Both report endpoints take period, agent, cron, workspace and sections
+(comma-separated) query parameters, as the report flags of the same names.
+Reports are kept for --ttl; older ones are still served while a fresh copy
+is generated in the background, and the 100 most recently requested are
+kept. The config file is read once, at startup.
+
+The server listens on localhost only by default. There is no
+authentication, so only pass an --addr such as :8080, which listens on
+every interface, on a trusted network.
+
+Examples:
+  costctl serve
+  costctl serve --addr 127.0.0.1:9000 --ttl 5m --period month
+  curl 'localhost:8080/api/report?period=week&agent=urza'`,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().DurationVar(&serveTTL, "ttl", time.Minute, "How long a generated report is served before it is refreshed")
+	serveCmd.Flags().StringVar(&servePeriod, "period", "week", "Period of requests that do not set one")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if serveTTL <= 0 {
+		return fmt.Errorf("--ttl must be positive")
+	}
+	if err := reporter.ValidatePeriod(servePeriod); err != nil {
+		return err
+	}
+	settings, err := loadSettings()
+	if err != nil {
+		return err
+	}
+	roots, err := resolveAgentsRoots()
+	if err != nil {
+		return err
+	}
+	dates, err := formats.NewDateStyle(settings.Display.DateFormat, settings.Display.ISOWeeks)
+	if err != nil {
+		return err
+	}
+	costs, err := costStyle(settings.Display.Costs)
+	if err != nil {
+		return err
+	}
+	cache := openCache()
+	defer saveCache(cache)
+
+	metrics := server.NewMetrics()
+	generate := func(query url.Values) (reporter.Report, error) {
+		opts, err := serveOptions(query)
+		if err != nil {
+			return reporter.Report{}, err
+		}
+		opts.Roots = roots
+		opts.Settings = settings
+		opts.Cache = cache
+		opts.Version = rootCmd.Version
+		start := time.Now()
+		result, err := report.Generate(context.Background(), opts)
+		if err != nil {
+			return reporter.Report{}, err
+		}
+		metrics.ObserveParse(time.Since(start), result.Scanned)
+		return result.Report, nil
+	}
+
+	dashboard := server.NewCache(func(query url.Values) ([]byte, string, error) {
+		r, err := generate(query)
+		if err != nil {
+			return nil, "", err
+		}
+		formatter := &formats.HTMLFormatter{Dates: dates, Costs: costs, Nav: periodNav(query, r.Period)}
+		output, err := formatter.Format(r)
+		if err != nil {
+			return nil, "", err
+		}
+		return []byte(output), "text/html; charset=utf-8", nil
+	}, serveTTL)
+	dashboard.Metrics = metrics
+
+	api := server.NewCache(func(query url.Values) ([]byte, string, error) {
+		r, err := generate(query)
+		if err != nil {
+			return nil, "", err
+		}
+		output, err := formats.NewJSONFormatter().Format(r)
+		if err != nil {
+			return nil, "", err
+		}
+		return []byte(output), "application/json", nil
+	}, serveTTL)
+	api.Metrics = metrics
+
+	mux := http.NewServeMux()
+	mux.Handle("/{$}", dashboard)
+	mux.Handle("/api/report", api)
+	mux.Handle("/metrics", metrics)
+	mux.Handle("/healthz", metrics.HealthHandler())
+
+	srv := &http.Server{Addr: serveAddr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	fmt.Fprintf(os.Stderr, "Serving reports on %s\n", serveAddr)
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdown); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Stopped")
+	return nil
+}
+
+// serveOptions returns the report options of a request's query. Unknown
+// and invalid parameters are the client's errors (server.ErrBadQuery).
+func serveOptions(query url.Values) (report.Options, error) {
+	for name, values := range query {
+		if !slices.Contains(serveParams, name) {
+			return report.Options{}, fmt.Errorf("%w: unknown parameter %q (valid: %s)", server.ErrBadQuery, name, strings.Join(serveParams, ", "))
+		}
+		if len(values) > 1 {
+			return report.Options{}, fmt.Errorf("%w: parameter %q given more than once", server.ErrBadQuery, name)
+		}
+	}
+	opts := report.Options{
+		Period:    query.Get("period"),
+		Agent:     query.Get("agent"),
+		Cron:      query.Get("cron"),
+		Workspace: query.Get("workspace"),
+	}
+	if opts.Period == "" {
+		opts.Period = servePeriod
+	}
+	if err := reporter.ValidatePeriod(opts.Period); err != nil {
+		return report.Options{}, fmt.Errorf("%w: %v", server.ErrBadQuery, err)
+	}
+	if sections := query.Get("sections"); sections != "" {
+		opts.Sections = strings.Split(sections, ",")
+		for _, section := range opts.Sections {
+			if !slices.Contains(reporter.Sections, section) {
+				return report.Options{}, fmt.Errorf("%w: unknown section: %s (valid: %s)", server.ErrBadQuery, section, strings.Join(reporter.Sections, ", "))
+			}
+		}
+	}
+	return opts, nil
+}
+
+// periodNav links the dashboard to each period, keeping the query's other
+// parameters.
+func periodNav(query url.Values, current string) []formats.NavLink {
+	var nav []formats.NavLink
+	for _, period := range report.Periods {
+		q := url.Values{}
+		for name, values := range query {
+			q[name] = values
+		}
+		q.Set("period", period)
+		nav = append(nav, formats.NavLink{Label: period, URL: "?" + q.Encode(), Current: period == current})
+	}
+	return nav
+}
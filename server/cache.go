@@ -0,0 +1,197 @@
+// Package server serves generated reports over HTTP.
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Generator produces a report for the given query parameters, returning
+// its body and content type. Errors wrapping ErrBadQuery are the client's.
+type Generator func(query url.Values) (body []byte, contentType string, err error)
+
+// ErrBadQuery marks generation errors caused by invalid query parameters,
+// which are answered with 400 Bad Request rather than 500.
+var ErrBadQuery = errors.New("bad query")
+
+// DefaultMaxEntries is the number of reports a Cache keeps by default.
+// Query values are free-form, so without a bound every distinct agent,
+// cron or workspace ever requested would stay in memory.
+const DefaultMaxEntries = 100
+
+// Cache is an http.Handler that serves generated reports from memory,
+// keyed by query parameters. Entries older than the TTL are still served
+// while a fresh copy is generated in the background, so clients polling
+// faster than reports can be built never wait on a re-parse. Responses
+// carry an ETag and If-None-Match requests for unchanged reports get 304.
+// At most MaxEntries reports are kept; the least recently requested one is
+// dropped to make room for another.
+type Cache struct {
+	// Metrics, when set, records cache hits and misses and the outcome of
+	// each generation.
+	Metrics *Metrics
+
+	// MaxEntries bounds the number of reports kept. NewCache sets it to
+	// DefaultMaxEntries; zero or less keeps every report.
+	MaxEntries int
+
+	generate Generator
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry is a generated report. ready is closed once the first
+// generation finishes; later refreshes replace the fields under the
+// Cache's lock.
+type cacheEntry struct {
+	ready       chan struct{}
+	body        []byte
+	contentType string
+	etag        string
+	err         error
+	generatedAt time.Time
+	usedAt      time.Time // last requested, for eviction
+	refreshing  bool
+}
+
+// NewCache creates a Cache that regenerates reports older than ttl.
+func NewCache(generate Generator, ttl time.Duration) *Cache {
+	return &Cache{
+		MaxEntries: DefaultMaxEntries,
+		generate:   generate,
+		ttl:        ttl,
+		now:        time.Now,
+		entries:    make(map[string]*cacheEntry),
+	}
+}
+
+// ServeHTTP serves the cached report for the request's query parameters.
+func (c *Cache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, contentType, etag, err := c.Get(r.URL.Query())
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrBadQuery) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(body)
+}
+
+// Get returns the report for query, generating it on first use and
+// refreshing it in the background once it is older than the TTL.
+// Concurrent first requests for the same query share one generation.
+func (c *Cache) Get(query url.Values) (body []byte, contentType, etag string, err error) {
+	key := query.Encode() // sorted by key, so parameter order does not matter
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.Metrics.cacheLookup(ok)
+	if !ok {
+		c.evict()
+		e = &cacheEntry{ready: make(chan struct{}), usedAt: c.now()}
+		c.entries[key] = e
+		c.mu.Unlock()
+
+		c.refresh(key, e, query)
+		close(e.ready)
+	} else {
+		e.usedAt = c.now()
+		c.mu.Unlock()
+		<-e.ready
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e.err != nil {
+		// Failed generations are not cached.
+		if c.entries[key] == e {
+			delete(c.entries, key)
+		}
+		return nil, "", "", e.err
+	}
+	if c.now().Sub(e.generatedAt) >= c.ttl && !e.refreshing {
+		e.refreshing = true
+		go c.refresh(key, e, query)
+	}
+	return e.body, e.contentType, e.etag, nil
+}
+
+// refresh regenerates an entry. A failed background refresh keeps the
+// previous report.
+func (c *Cache) refresh(key string, e *cacheEntry, query url.Values) {
+	body, contentType, err := c.generate(query)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// An entry evicted while it refreshed no longer counts against health.
+	c.Metrics.refreshed(key, err, e.body != nil && c.entries[key] == e)
+	e.refreshing = false
+	if err != nil {
+		if e.body == nil {
+			e.err = err
+		}
+		return
+	}
+	sum := sha256.Sum256(body)
+	e.body = body
+	e.contentType = contentType
+	e.etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+	e.err = nil
+	e.generatedAt = c.now()
+}
+
+// evict drops least recently requested entries until there is room for
+// one more. The caller holds c.mu.
+func (c *Cache) evict() {
+	if c.MaxEntries <= 0 {
+		return
+	}
+	for len(c.entries) >= c.MaxEntries {
+		var oldest string
+		var oldestEntry *cacheEntry
+		for key, e := range c.entries {
+			if oldestEntry == nil || e.usedAt.Before(oldestEntry.usedAt) {
+				oldest, oldestEntry = key, e
+			}
+		}
+		delete(c.entries, oldest)
+		c.Metrics.forget(oldest)
+	}
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, using
+// the weak comparison RFC 9110 prescribes for GET.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
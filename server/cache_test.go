@@ -0,0 +1,177 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingGenerator returns a generator whose output changes on every call.
+func countingGenerator(calls *int32) Generator {
+	return func(query url.Values) ([]byte, string, error) {
+		n := atomic.AddInt32(calls, 1)
+		return []byte(fmt.Sprintf("%s #%d", query.Get("period"), n)), "text/plain", nil
+	}
+}
+
+func TestCacheServesETags(t *testing.T) {
+	var calls int32
+	c := NewCache(countingGenerator(&calls), time.Minute)
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest("GET", "/api/report?period=week&agent=urza", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "week #1" {
+		t.Fatalf("unexpected response %d %q", rec.Code, rec.Body.String())
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+
+	// Same parameters in another order hit the cache and match the ETag.
+	req := httptest.NewRequest("GET", "/api/report?agent=urza&period=week", nil)
+	req.Header.Set("If-None-Match", `"other", W/`+etag)
+	rec = httptest.NewRecorder()
+	c.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("expected 304 with no body, got %d %q", rec.Code, rec.Body.String())
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 generation, got %d", calls)
+	}
+
+	rec = httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest("POST", "/api/report", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for POST, got %d", rec.Code)
+	}
+}
+
+func TestCacheRefreshesInBackground(t *testing.T) {
+	var calls int32
+	c := NewCache(countingGenerator(&calls), time.Minute)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	query := url.Values{"period": {"today"}}
+	body, _, etag, _ := c.Get(query)
+
+	// A stale report is still served while it is regenerated.
+	now = now.Add(2 * time.Minute)
+	if stale, _, _, _ := c.Get(query); string(stale) != string(body) {
+		t.Errorf("expected the stale report, got %q", stale)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		fresh, _, freshTag, _ := c.Get(query)
+		if string(fresh) == "today #2" {
+			if freshTag == etag {
+				t.Error("expected a new ETag after refresh")
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("report was not refreshed, still %q", fresh)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCacheSharesFirstGeneration(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	generate := func(query url.Values) ([]byte, string, error) {
+		<-release
+		return countingGenerator(&calls)(query)
+	}
+	c := NewCache(generate, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Get(url.Values{"period": {"week"}})
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("expected concurrent requests to share 1 generation, got %d", calls)
+	}
+}
+
+func TestCacheDoesNotCacheErrors(t *testing.T) {
+	fail := true
+	generate := func(query url.Values) ([]byte, string, error) {
+		if fail {
+			return nil, "", errors.New("agents directory unreadable")
+		}
+		return []byte("ok"), "text/plain", nil
+	}
+	c := NewCache(generate, time.Minute)
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+
+	fail = false
+	if body, _, _, err := c.Get(url.Values{}); err != nil || string(body) != "ok" {
+		t.Errorf("expected a retry after the failure, got %q, %v", body, err)
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	var calls int32
+	c := NewCache(countingGenerator(&calls), time.Minute)
+	c.MaxEntries = 2
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	get := func(period string) string {
+		now = now.Add(time.Second)
+		body, _, _, err := c.Get(url.Values{"period": {period}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	get("week")
+	get("month")
+	get("week") // month is now the least recently used
+	if got := get("today"); got != "today #3" {
+		t.Fatalf("expected a third generation, got %q", got)
+	}
+	if len(c.entries) != 2 {
+		t.Errorf("expected 2 entries kept, got %d", len(c.entries))
+	}
+	if got := get("week"); got != "week #1" {
+		t.Errorf("expected week still cached, got %q", got)
+	}
+	if got := get("month"); got != "month #4" {
+		t.Errorf("expected month evicted and regenerated, got %q", got)
+	}
+}
+
+func TestCacheBadQuery(t *testing.T) {
+	c := NewCache(func(query url.Values) ([]byte, string, error) {
+		return nil, "", fmt.Errorf("%w: invalid period %q", ErrBadQuery, query.Get("period"))
+	}, time.Minute)
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest("GET", "/?period=fortnight", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d %q", rec.Code, rec.Body.String())
+	}
+}
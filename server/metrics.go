@@ -0,0 +1,188 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics records how the server itself is doing, for monitoring costctl
+// in production: how long transcript parses take, how many files they
+// scan, how often reports are served from the cache, and when a report was
+// last refreshed. Its methods are safe for concurrent use, and a nil
+// *Metrics records nothing.
+type Metrics struct {
+	now     func() time.Time
+	started time.Time
+
+	mu            sync.Mutex
+	parses        int
+	parseSeconds  float64 // total across parses
+	lastParse     time.Duration
+	filesScanned  int // total across parses
+	lastFiles     int
+	hits, misses  int
+	refreshes     int
+	refreshErrors int
+	lastRefresh   time.Time
+	failing       map[string]error // by report key, for reports whose refresh failed
+}
+
+// NewMetrics creates Metrics, with uptime counted from now.
+func NewMetrics() *Metrics {
+	return &Metrics{now: time.Now, started: time.Now(), failing: make(map[string]error)}
+}
+
+// ObserveParse records a transcript parse that took d and read files
+// transcripts. Generators call it; the Cache cannot see inside them.
+func (m *Metrics) ObserveParse(d time.Duration, files int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.parses++
+	m.parseSeconds += d.Seconds()
+	m.lastParse = d
+	m.filesScanned += files
+	m.lastFiles = files
+}
+
+// cacheLookup records a Cache request served from an existing entry (hit)
+// or one that had to be generated first (miss).
+func (m *Metrics) cacheLookup(hit bool) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if hit {
+		m.hits++
+	} else {
+		m.misses++
+	}
+}
+
+// refreshed records the outcome of generating the report for key. Only
+// failures to refresh a report that was generated before count against
+// health: a first generation failing is more likely a bad request than a
+// broken server.
+func (m *Metrics) refreshed(key string, err error, stale bool) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refreshes++
+	if err != nil {
+		m.refreshErrors++
+		if stale {
+			m.failing[key] = err
+		}
+		return
+	}
+	m.lastRefresh = m.now()
+	delete(m.failing, key)
+}
+
+// forget drops key's refresh failure once the Cache no longer keeps its
+// report, so an evicted report does not keep health failing.
+func (m *Metrics) forget(key string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.failing, key)
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	metric := func(name, kind, help string, value float64) {
+		fmt.Fprintf(&b, "# HELP costctl_%s %s\n# TYPE costctl_%s %s\ncostctl_%s %s\n",
+			name, help, name, kind, name, strconv.FormatFloat(value, 'f', -1, 64))
+	}
+	metric("uptime_seconds", "gauge", "Seconds since the server started.", m.now().Sub(m.started).Seconds())
+	metric("parses_total", "counter", "Transcript parses run.", float64(m.parses))
+	metric("parse_seconds_total", "counter", "Time spent parsing transcripts.", m.parseSeconds)
+	metric("last_parse_seconds", "gauge", "Duration of the last transcript parse.", m.lastParse.Seconds())
+	metric("files_scanned_total", "counter", "Transcripts read across parses.", float64(m.filesScanned))
+	metric("last_files_scanned", "gauge", "Transcripts read by the last parse.", float64(m.lastFiles))
+	metric("cache_hits_total", "counter", "Report requests served from the cache.", float64(m.hits))
+	metric("cache_misses_total", "counter", "Report requests that waited for a generation.", float64(m.misses))
+	ratio := 0.0
+	if m.hits+m.misses > 0 {
+		ratio = float64(m.hits) / float64(m.hits+m.misses)
+	}
+	metric("cache_hit_ratio", "gauge", "Share of report requests served from the cache.", ratio)
+	metric("refreshes_total", "counter", "Report generations, including failed ones.", float64(m.refreshes))
+	metric("refresh_errors_total", "counter", "Report generations that failed.", float64(m.refreshErrors))
+	last := 0.0
+	if !m.lastRefresh.IsZero() {
+		last = float64(m.lastRefresh.UnixNano()) / 1e9
+	}
+	metric("last_refresh_timestamp_seconds", "gauge", "Unix time of the last successful report generation (0 if none).", last)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(b.String()))
+}
+
+// Health is the body of a /healthz response.
+type Health struct {
+	Status        string     `json:"status"` // ok or failing
+	UptimeSeconds float64    `json:"uptime_seconds"`
+	LastRefresh   *time.Time `json:"last_refresh,omitempty"`
+	Error         string     `json:"error,omitempty"` // why a report fails to refresh
+}
+
+// HealthHandler serves /healthz: 200 with status ok while the server is
+// up, and 503 with status failing and an error while any cached report
+// fails to refresh. It only reads state and never triggers a parse.
+func (m *Metrics) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		m.mu.Lock()
+		h := Health{Status: "ok", UptimeSeconds: m.now().Sub(m.started).Seconds()}
+		if !m.lastRefresh.IsZero() {
+			last := m.lastRefresh.UTC()
+			h.LastRefresh = &last
+		}
+		if len(m.failing) > 0 {
+			keys := make([]string, 0, len(m.failing))
+			for key := range m.failing {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			h.Status = "failing"
+			h.Error = m.failing[keys[0]].Error()
+		}
+		m.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if h.Status != "ok" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(h)
+	})
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	m.now = clock
+	m.started = now.Add(-time.Hour)
+
+	var fail atomic.Bool
+	c := NewCache(func(query url.Values) ([]byte, string, error) {
+		if fail.Load() {
+			return nil, "", errors.New("agents directory not found")
+		}
+		m.ObserveParse(1500*time.Millisecond, 40)
+		return []byte(query.Get("period")), "text/plain", nil
+	}, time.Minute)
+	c.now = clock
+	c.Metrics = m
+
+	health := func() (int, Health) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		m.HealthHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+		var h Health
+		if err := json.Unmarshal(rec.Body.Bytes(), &h); err != nil {
+			t.Fatalf("invalid health body %q: %v", rec.Body.String(), err)
+		}
+		return rec.Code, h
+	}
+	if code, h := health(); code != http.StatusOK || h.Status != "ok" || h.LastRefresh != nil || h.UptimeSeconds != 3600 {
+		t.Errorf("expected a healthy server before any report, got %d %+v", code, h)
+	}
+
+	for _, period := range []string{"week", "week", "week", "month"} {
+		if _, _, _, err := c.Get(url.Values{"period": {period}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	for _, want := range []string{
+		"# TYPE costctl_parses_total counter\ncostctl_parses_total 2\n",
+		"costctl_last_parse_seconds 1.5\n",
+		"costctl_files_scanned_total 80\n",
+		"costctl_cache_hits_total 2\n",
+		"costctl_cache_misses_total 2\n",
+		"costctl_cache_hit_ratio 0.5\n",
+		"costctl_last_refresh_timestamp_seconds 1772366400\n",
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("expected %q in metrics:\n%s", want, rec.Body.String())
+		}
+	}
+
+	// A report that never worked is a bad request, not an unhealthy server.
+	fail.Store(true)
+	if _, _, _, err := c.Get(url.Values{"period": {"fortnight"}}); err == nil {
+		t.Fatal("expected the generation to fail")
+	}
+	if code, h := health(); code != http.StatusOK || h.LastRefresh == nil {
+		t.Errorf("expected a healthy server after a bad request, got %d %+v", code, h)
+	}
+
+	// A cached report that stops refreshing is.
+	now = now.Add(2 * time.Minute)
+	waitHealth := func(want int) Health {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for {
+			c.Get(url.Values{"period": {"week"}})
+			code, h := health()
+			if code == want {
+				return h
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected health %d, still %d %+v", want, code, h)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if h := waitHealth(http.StatusServiceUnavailable); h.Status != "failing" || h.Error != "agents directory not found" {
+		t.Errorf("unexpected failing health: %+v", h)
+	}
+
+	fail.Store(false)
+	if h := waitHealth(http.StatusOK); h.Status != "ok" || h.Error != "" {
+		t.Errorf("unexpected recovered health: %+v", h)
+	}
+
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("POST", "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for POST, got %d", rec.Code)
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.ObserveParse(time.Second, 1)
+	m.cacheLookup(true)
+	m.refreshed("", nil, false)
+}
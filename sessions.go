@@ -0,0 +1,297 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/misty-step/costctl/config"
+	"github.com/misty-step/costctl/formats"
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/report"
+	"github.com/spf13/cobra"
+)
+
+// sessions bundle command flags
+var (
+	bundleAgent  string
+	bundleRedact bool
+	bundleOut    string
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Inspect individual sessions",
+}
+
+var sessionsBundleCmd = &cobra.Command{
+	Use:   "bundle <id>",
+	Short: "Package a session for a bug report",
+	Long: `Package a session's transcript, its session index entry and costctl's
+computed view of it (a full JSON report over the session alone) into a
+.tar.gz to attach to an issue about mis-computed costs.
+
+The id is the transcript's file name without .jsonl, or the session ID at the
+end of a cron or sub-agent session key. With --redact, message text,
+thinking and tool arguments are replaced by x's of the same size and other
+strings by "[redacted]"; timestamps, models, usage and error messages are
+kept, so the bundle reproduces the same costs.
+
+Examples:
+  costctl sessions bundle 2026-06-10-chat --redact --out bundle.tar.gz
+  costctl sessions bundle r1 --agent urza --redact`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionsBundle,
+}
+
+func init() {
+	sessionsBundleCmd.Flags().StringVar(&bundleAgent, "agent", "", "Only look for the session under this agent")
+	sessionsBundleCmd.Flags().BoolVar(&bundleRedact, "redact", false, "Redact message content, keeping what costs are computed from")
+	sessionsBundleCmd.Flags().StringVar(&bundleOut, "out", "costctl-bundle.tar.gz", "File to write the bundle to")
+
+	sessionsBundleCmd.RegisterFlagCompletionFunc("agent", completeAgents)
+
+	sessionsCmd.AddCommand(sessionsBundleCmd)
+}
+
+// bundleManifest describes a bundle's contents.
+type bundleManifest struct {
+	CostctlVersion string    `json:"costctl_version"`
+	CreatedAt      time.Time `json:"created_at"`
+	SessionID      string    `json:"session_id"`
+	Agent          string    `json:"agent"`
+	Tenant         string    `json:"tenant,omitempty"`
+	Transcript     string    `json:"transcript"` // file name, or full path when not redacted
+	Redacted       bool      `json:"redacted"`
+	Files          []string  `json:"files"`
+}
+
+// sessionFile is a transcript found for a session ID.
+type sessionFile struct {
+	root  report.Root
+	agent string
+	path  string
+}
+
+func runSessionsBundle(cmd *cobra.Command, args []string) error {
+	id := args[0]
+	settings, err := loadSettings()
+	if err != nil {
+		return err
+	}
+	roots, err := resolveAgentsRoots()
+	if err != nil {
+		return err
+	}
+	file, err := findSessionFile(roots, settings, bundleAgent, id)
+	if err != nil {
+		return err
+	}
+
+	transcript, err := os.ReadFile(file.path)
+	if err != nil {
+		return fmt.Errorf("failed to read transcript: %w", err)
+	}
+	result, err := report.Generate(context.Background(), report.Options{
+		Files:    []string{file.path},
+		Full:     true,
+		Settings: settings,
+	})
+	if err != nil {
+		return err
+	}
+	if len(result.Skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", result.Skipped[0])
+	}
+	view, err := formats.NewJSONFormatter().Format(result.Report)
+	if err != nil {
+		return fmt.Errorf("failed to format report: %w", err)
+	}
+
+	manifest := bundleManifest{
+		CostctlVersion: version,
+		CreatedAt:      time.Now().UTC(),
+		SessionID:      strings.TrimSuffix(filepath.Base(file.path), ".jsonl"),
+		Agent:          file.agent,
+		Tenant:         file.root.Tenant,
+		Transcript:     file.path,
+		Redacted:       bundleRedact,
+	}
+	files := []bundleFile{{"transcript.jsonl", transcript}, {"report.json", []byte(view)}}
+	if entry := sessionIndexEntry(file.path); entry != nil {
+		files = append(files, bundleFile{"index.json", entry})
+	}
+	if bundleRedact {
+		manifest.Transcript = filepath.Base(file.path)
+		if files[0].data, err = parser.RedactTranscript(transcript); err != nil {
+			return fmt.Errorf("failed to redact transcript: %w", err)
+		}
+		if len(files) == 3 {
+			files[2].data = parser.RedactIndexEntry(files[2].data)
+		}
+	}
+	for _, f := range files {
+		manifest.Files = append(manifest.Files, f.name)
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	files = append([]bundleFile{{"manifest.json", data}}, files...)
+
+	if err := writeBundle(bundleOut, files, manifest.CreatedAt); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s (%s, $%.4f)\n", bundleOut, manifest.SessionID, result.TotalCost)
+	if !bundleRedact {
+		fmt.Fprintln(os.Stderr, "Note: the bundle includes the full transcript; use --redact before sharing it publicly")
+	}
+	return nil
+}
+
+// findSessionFile returns the one transcript of session id in the agent
+// directories selected by the agent filter.
+func findSessionFile(roots []report.Root, settings *config.Config, agent, id string) (sessionFile, error) {
+	found, err := findSessionFiles(roots, settings, agent, id)
+	if err != nil {
+		return sessionFile{}, err
+	}
+	switch len(found) {
+	case 0:
+		return sessionFile{}, fmt.Errorf("no session %s found", id)
+	case 1:
+		return found[0], nil
+	default:
+		var paths []string
+		for _, f := range found {
+			paths = append(paths, f.path)
+		}
+		return sessionFile{}, fmt.Errorf("session %s is ambiguous (use --agent or --tenant): %s", id, strings.Join(paths, ", "))
+	}
+}
+
+// findSessionFiles looks for the transcript of session id in every agent
+// directory selected by the agent filter.
+func findSessionFiles(roots []report.Root, settings *config.Config, agent, id string) ([]sessionFile, error) {
+	return listSessionFiles(roots, settings, agent, func(path string) bool {
+		stem := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+		return stem == id || strings.HasSuffix(stem, ":run:"+id) || strings.HasSuffix(stem, ":subagent:"+id)
+	})
+}
+
+// listSessionFiles returns the transcripts kept by keep in every agent
+// directory selected by the agent filter.
+func listSessionFiles(roots []report.Root, settings *config.Config, agent string, keep func(path string) bool) ([]sessionFile, error) {
+	names := settings.AgentNames
+	var found []sessionFile
+	for _, root := range roots {
+		p := newParser(root.Dir, settings)
+		agents, err := p.ListAgents()
+		if err != nil {
+			if len(roots) == 1 {
+				return nil, err
+			}
+			continue
+		}
+		for _, dir := range agents {
+			if agent != "" && dir != agent && report.DisplayName(names, dir) != agent {
+				continue
+			}
+			sessionsDir := filepath.Join(p.AgentDir(dir), "sessions")
+			matches, err := filepath.Glob(filepath.Join(sessionsDir, "*.jsonl"))
+			if err != nil {
+				return nil, err
+			}
+			for _, path := range matches {
+				if keep(path) {
+					found = append(found, sessionFile{root: root, agent: report.DisplayName(names, dir), path: path})
+				}
+			}
+		}
+	}
+	return found, nil
+}
+
+// sessionIndexEntry returns the sessions.json entry for the transcript at
+// path, as a one-entry object, or nil. The entry is the one the parser
+// would use, or else the one keyed by or naming the transcript.
+func sessionIndexEntry(path string) json.RawMessage {
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(path), "sessions.json"))
+	if err != nil {
+		return nil
+	}
+	var index map[string]json.RawMessage
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil
+	}
+
+	stem := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+	keys := []string{stem}
+	if sessions := parser.New("").ParseFiles([]string{path}, ""); len(sessions) > 0 {
+		keys = append([]string{sessions[0].Key()}, keys...)
+	}
+	for key, entry := range index {
+		var fields struct {
+			SessionID string `json:"sessionId"`
+		}
+		if json.Unmarshal(entry, &fields) == nil && fields.SessionID == stem {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		if entry, ok := index[key]; ok {
+			data, err := json.Marshal(map[string]json.RawMessage{key: entry})
+			if err != nil {
+				return nil
+			}
+			return data
+		}
+	}
+	return nil
+}
+
+// bundleFile is a file to add to a bundle.
+type bundleFile struct {
+	name string
+	data []byte
+}
+
+// writeBundle writes files into a gzipped tar under a costctl-bundle
+// directory.
+func writeBundle(path string, files []bundleFile, modTime time.Time) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		header := &tar.Header{
+			Name:    "costctl-bundle/" + f.name,
+			Mode:    0644,
+			Size:    int64(len(f.data)),
+			ModTime: modTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/misty-step/costctl/formats"
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/reporter"
+	"github.com/spf13/cobra"
+)
+
+// simulate command flags
+var (
+	simulateDays   int
+	simulateAgent  string
+	simulateFormat string
+	simulateTop    int
+	simulateLimits reporter.Limits
+	simulateCap    float64
+)
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Estimate what past sessions would have cost under stricter limits",
+	Long: `Replay recent sessions under stricter generation limits and report what
+they would have cost, to quantify the savings of a proposed OpenClaw config
+change before making it. Each request's output (or input) cost is scaled by
+the share of tokens a limit cuts off; requests past --max-turns are dropped.
+
+With --daily-cap, find instead the time of day each of the last --days days
+(today included) would have hit a daily spend cap, and the spend it would
+have blocked, to choose a safe cap before enabling enforcement.
+
+Examples:
+  costctl simulate --max-output-tokens 2000
+  costctl simulate --max-input-tokens 50000 --days 7
+  costctl simulate --max-turns 20 --agent amos --format json
+  costctl simulate --daily-cap 25 --days 14`,
+	RunE: runSimulate,
+}
+
+func init() {
+	simulateCmd.Flags().IntVar(&simulateLimits.MaxOutputTokens, "max-output-tokens", 0, "Cap output tokens per model request")
+	simulateCmd.Flags().IntVar(&simulateLimits.MaxInputTokens, "max-input-tokens", 0, "Cap uncached input tokens per model request")
+	simulateCmd.Flags().IntVar(&simulateLimits.MaxTurns, "max-turns", 0, "Cap model requests per session")
+	simulateCmd.Flags().Float64Var(&simulateCap, "daily-cap", 0, "Find when each day would have hit this daily spend cap, in dollars")
+	simulateCmd.Flags().IntVar(&simulateDays, "days", 30, "Days of history to replay")
+	simulateCmd.Flags().StringVar(&simulateAgent, "agent", "", "Filter by agent")
+	simulateCmd.Flags().StringVar(&simulateFormat, "format", "text", "Output format: json|text")
+	simulateCmd.Flags().IntVar(&simulateTop, "top", 10, "Rows per table; the rest are folded together (0 for all)")
+
+	simulateCmd.RegisterFlagCompletionFunc("agent", completeAgents)
+}
+
+func runSimulate(cmd *cobra.Command, args []string) error {
+	if simulateFormat != "json" && simulateFormat != "text" {
+		return fmt.Errorf("invalid format: %s (valid: json, text)", simulateFormat)
+	}
+	l := simulateLimits
+	if l.MaxOutputTokens < 0 || l.MaxInputTokens < 0 || l.MaxTurns < 0 || simulateCap < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	if simulateCap > 0 {
+		if l != (reporter.Limits{}) {
+			return fmt.Errorf("--daily-cap cannot be combined with other limits")
+		}
+		return runSimulateCap()
+	}
+	if l == (reporter.Limits{}) {
+		return fmt.Errorf("no limits given (use --max-output-tokens, --max-input-tokens, --max-turns or --daily-cap)")
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -simulateDays)
+	sessions, _, err := parseSessions(simulateAgent, cutoff, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	var recent []parser.Session
+	for _, s := range sessions {
+		if !s.StartedAt.IsZero() && s.StartedAt.After(cutoff) {
+			recent = append(recent, s)
+		}
+	}
+
+	sim := reporter.Simulate(recent, l)
+
+	if simulateFormat == "json" {
+		data, err := json.MarshalIndent(sim, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format simulation: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Print(formats.FormatSimulation(sim, simulateTop))
+	return nil
+}
+
+// runSimulateCap replays the last --days days, today included, against
+// --daily-cap.
+func runSimulateCap() error {
+	if simulateDays <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+	now := time.Now()
+	from := reporter.BudgetStart(reporter.BudgetDaily, now).AddDate(0, 0, 1-simulateDays)
+	// Sessions started before the window may still make requests in it,
+	// so none are cut by their start: those that ended before it are
+	// dropped, and SimulateDailyCap counts each request by its own time.
+	sessions, _, err := parseSessions(simulateAgent, time.Time{}, time.Time{})
+	if err != nil {
+		return err
+	}
+	active := sessions[:0]
+	for _, s := range sessions {
+		if !s.EndedAt.Before(from) {
+			active = append(active, s)
+		}
+	}
+	sessions = active
+
+	sim := reporter.SimulateDailyCap(sessions, simulateCap, from, now)
+
+	if simulateFormat == "json" {
+		data, err := json.MarshalIndent(sim, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format simulation: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Print(formats.FormatCapSimulation(sim))
+	return nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/misty-step/costctl/reporter"
+	"github.com/misty-step/costctl/store"
+	"github.com/spf13/cobra"
+)
+
+// defaultStoreDSN is where snapshots go when --store is not given.
+const defaultStoreDSN = "sqlite:~/.costctl/history.db"
+
+// snapshot command flags
+var (
+	snapshotPeriod string
+	snapshotAgent  string
+	snapshotStore  string
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Persist daily cost aggregates to the history store",
+	Long: `Parse session transcripts and upsert daily aggregates (per agent, session
+type, cron, and model) into a history store, so cost history survives
+transcript rotation.
+
+Re-running a snapshot replaces the stored aggregates for the days it covers,
+which are always whole days: --period week starts at midnight seven days ago.
+Run it at least as often as OpenClaw rotates transcripts, e.g. daily from cron.
+
+Stores:
+  sqlite:/path/to/db  local SQLite database (default: ` + defaultStoreDSN + `)
+  file:/path/to/dir   one JSON file per day
+
+Examples:
+  costctl snapshot
+  costctl snapshot --period month --store file:/srv/costctl/history`,
+	RunE: runSnapshot,
+}
+
+func init() {
+	snapshotCmd.Flags().StringVar(&snapshotPeriod, "period", "week", "Time period to snapshot: today|yesterday|week|month|all")
+	snapshotCmd.Flags().StringVar(&snapshotAgent, "agent", "", "Only snapshot this agent")
+	snapshotCmd.Flags().StringVar(&snapshotStore, "store", defaultStoreDSN, "History store DSN")
+
+	snapshotCmd.RegisterFlagCompletionFunc("agent", completeAgents)
+}
+
+func runSnapshot(cmd *cobra.Command, args []string) error {
+	return writeSnapshot(snapshotStore, snapshotPeriod, snapshotAgent)
+}
+
+// push command flags
+var (
+	pushTo     string
+	pushPeriod string
+	pushAgent  string
+)
+
+var pushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Upload daily cost aggregates to a shared fleet store",
+	Long: `Upload this machine's daily aggregates to a shared store (typically Postgres)
+so every machine in a fleet reports into one warehouse. Rows are keyed by
+hostname, so machines never overwrite each other.
+
+Report across the fleet with:
+  costctl report --source postgres://costctl@warehouse/costs --period month
+  costctl report --source postgres --period month   # PG* environment variables
+
+Examples:
+  costctl push --to postgres://costctl@warehouse/costs
+  costctl push --to "host=warehouse dbname=costs sslmode=require" --period yesterday
+  PGHOST=warehouse PGDATABASE=costs costctl push --to postgres`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if pushTo == "" {
+			return fmt.Errorf("--to is required")
+		}
+		return writeSnapshot(pushTo, pushPeriod, pushAgent)
+	},
+}
+
+func init() {
+	pushCmd.Flags().StringVar(&pushTo, "to", "", "Destination store DSN, e.g. postgres://user@host/db")
+	pushCmd.Flags().StringVar(&pushPeriod, "period", "week", "Time period to push: today|yesterday|week|month|all")
+	pushCmd.Flags().StringVar(&pushAgent, "agent", "", "Only push this agent")
+
+	pushCmd.RegisterFlagCompletionFunc("agent", completeAgents)
+}
+
+// writeSnapshot parses transcripts for the period and upserts their daily
+// aggregates into the store at dsn.
+func writeSnapshot(dsn, period, agent string) error {
+	if err := validatePeriod(period); err != nil {
+		return err
+	}
+
+	from, until := snapshotWindow(period, time.Now())
+	sessions, _, err := parseSessions(agent, from, time.Time{})
+	if err != nil {
+		return err
+	}
+	sessions = reporter.New(sessions, reporter.Config{Since: from, Until: until}).Filtered()
+
+	host, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("failed to get hostname: %w", err)
+	}
+	rows := store.Aggregate(sessions, host)
+
+	s, err := store.Open(dsn)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	if err := s.Put(context.Background(), rows); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	days := make(map[string]bool)
+	for _, row := range rows {
+		days[row.Date] = true
+	}
+	fmt.Printf("Stored %d aggregates across %d days from %d sessions\n", len(rows), len(days), len(sessions))
+	return nil
+}
+
+// snapshotWindow returns the sessions a snapshot of period as of now
+// covers: whole local days, as Put replaces a stored day with whatever the
+// snapshot has for it. Rolling periods such as week start at midnight of
+// their first day instead of mid-day. Both are zero for all.
+func snapshotWindow(period string, now time.Time) (time.Time, time.Time) {
+	from, until, err := reporter.PeriodWindow(period, now)
+	if err != nil {
+		return time.Time{}, time.Time{}
+	}
+	return reporter.BudgetStart(reporter.BudgetDaily, from.Local()), until
+}
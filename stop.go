@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/misty-step/costctl/config"
+	"github.com/misty-step/costctl/parser"
+	"github.com/spf13/cobra"
+)
+
+// sessions stop command flags
+var (
+	stopAgent string
+	stopYes   bool
+	stopForce bool
+)
+
+// stopIdleLimit is how long after its last turn a session is taken to
+// have ended, so stopping it is refused without --force.
+const stopIdleLimit = time.Hour
+
+var sessionsStopCmd = &cobra.Command{
+	Use:   "stop <id>",
+	Short: "Stop a runaway session through OpenClaw's controls",
+	Long: `Stop a running session, e.g. one sessions tail or an anomaly shows running
+away, by running the stop command configured in the config file's
+session_control.stop. costctl only reads transcripts, so terminating a
+session is left to whatever controls the OpenClaw deployment has, such as a
+client of its control socket or API.
+
+The id is found as for sessions bundle. Sessions without a turn in the
+last hour have most likely ended and are not stopped unless --force is
+given. The session's cost so far is shown and confirmation asked for
+first, unless --yes is given.
+
+Examples:
+  costctl sessions stop 2026-06-10-chat
+  costctl sessions stop r1 --agent urza --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionsStop,
+}
+
+func init() {
+	sessionsStopCmd.Flags().StringVar(&stopAgent, "agent", "", "Only look for the session under this agent")
+	sessionsStopCmd.Flags().BoolVar(&stopYes, "yes", false, "Stop the session without asking for confirmation")
+	sessionsStopCmd.Flags().BoolVar(&stopForce, "force", false, "Stop the session even if it looks ended")
+
+	sessionsStopCmd.RegisterFlagCompletionFunc("agent", completeAgents)
+
+	sessionsCmd.AddCommand(sessionsStopCmd)
+}
+
+func runSessionsStop(cmd *cobra.Command, args []string) error {
+	settings, err := loadSettings()
+	if err != nil {
+		return err
+	}
+	control := settings.SessionControl
+	if len(control.Stop) == 0 {
+		return fmt.Errorf("no stop command configured: set session_control.stop in the config file to a command that stops an OpenClaw session")
+	}
+	roots, err := resolveAgentsRoots()
+	if err != nil {
+		return err
+	}
+	file, err := findSessionFile(roots, settings, stopAgent, args[0])
+	if err != nil {
+		return err
+	}
+
+	session := strings.TrimSuffix(filepath.Base(file.path), ".jsonl")
+	follower := parser.NewFollower(file.path)
+	turns, err := follower.Poll()
+	if err != nil {
+		return err
+	}
+	if last := lastActive(file.path, turns); !stopForce && time.Since(last) > stopIdleLimit {
+		return fmt.Errorf("session %s/%s has had no turn since %s and has likely ended (use --force to stop it anyway)",
+			file.agent, session, last.Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Printf("%s/%s: %d turns, %s so far\n", file.agent, session, follower.Turns, parser.FormatCost(follower.Usage.CostTotal))
+	if !stopYes {
+		ok, err := confirm(bufio.NewReader(os.Stdin), "Stop this session?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Not stopped")
+			return nil
+		}
+	}
+
+	if err := stopSession(control, file.agent, session, file.path); err != nil {
+		return err
+	}
+	fmt.Printf("Stop requested for %s/%s\n", file.agent, session)
+	return nil
+}
+
+// lastActive returns when a session last made progress: its last turn,
+// or the transcript's modification time before the first one.
+func lastActive(path string, turns []parser.Message) time.Time {
+	if len(turns) > 0 {
+		return turns[len(turns)-1].Timestamp
+	}
+	if info, err := os.Stat(path); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
+
+// stopSession runs control's stop command for a session, with its output
+// passed through.
+func stopSession(control config.SessionControl, agent, session, transcript string) error {
+	placeholders := strings.NewReplacer("{agent}", agent, "{session}", session, "{transcript}", transcript)
+	argv := make([]string, len(control.Stop))
+	for i, arg := range control.Stop {
+		argv[i] = placeholders.Replace(arg)
+	}
+	timeout := control.Timeout
+	if timeout <= 0 {
+		timeout = config.DefaultStopTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+	c := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	c.Stdout, c.Stderr = os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("stop command timed out after %ds", timeout)
+		}
+		return fmt.Errorf("stop command failed: %w", err)
+	}
+	return nil
+}
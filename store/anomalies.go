@@ -0,0 +1,268 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/misty-step/costctl/reporter"
+)
+
+// AnomalyRecord tracks one anomaly across runs by its stable ID (see
+// reporter.AnomalyID). An anomaly is open from the run that first finds it
+// until a run no longer does; found again later, it opens anew.
+type AnomalyRecord struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"` // as last found
+	Agent       string `json:"agent,omitempty"`
+	Cron        string `json:"cron,omitempty"`
+	SessionID   string `json:"session_id,omitempty"`
+	// FirstSeen is when the anomaly last opened, LastSeen the last run
+	// that found it. ResolvedAt is zero while it is open.
+	FirstSeen  time.Time `json:"first_seen"`
+	LastSeen   time.Time `json:"last_seen"`
+	ResolvedAt time.Time `json:"resolved_at"`
+	// NotifiedAt is when the anomaly was last sent to the webhook; it is
+	// not sent again until it reopens.
+	NotifiedAt time.Time `json:"notified_at"`
+}
+
+// Open reports whether the anomaly is unresolved.
+func (r AnomalyRecord) Open() bool {
+	return r.ResolvedAt.IsZero()
+}
+
+// Notified reports whether the anomaly was sent since it last opened.
+func (r AnomalyRecord) Notified() bool {
+	return !r.NotifiedAt.IsZero() && !r.NotifiedAt.Before(r.FirstSeen)
+}
+
+// AnomalyStore is implemented by stores that track anomalies across runs.
+// FileStore and SQLStore both do.
+type AnomalyStore interface {
+	// PutAnomalies upserts records, replacing any stored with the same ID.
+	PutAnomalies(ctx context.Context, records []AnomalyRecord) error
+	// Anomalies returns every tracked anomaly, oldest first.
+	Anomalies(ctx context.Context) ([]AnomalyRecord, error)
+}
+
+// TrackAnomalies updates tracked records with the anomalies a run found at
+// now and returns the records that changed. Found anomalies are opened or
+// marked seen; open records the run did not find are resolved, unless the
+// run was partial (e.g. limited to one agent) and could not have found them.
+func TrackAnomalies(tracked []AnomalyRecord, found []reporter.Anomaly, now time.Time, partial bool) []AnomalyRecord {
+	byID := make(map[string]AnomalyRecord, len(tracked))
+	for _, r := range tracked {
+		byID[r.ID] = r
+	}
+
+	var changed []AnomalyRecord
+	seen := make(map[string]bool, len(found))
+	for _, a := range found {
+		if a.ID == "" || seen[a.ID] {
+			continue
+		}
+		seen[a.ID] = true
+		r, ok := byID[a.ID]
+		if !ok || !r.Open() {
+			r = AnomalyRecord{ID: a.ID, FirstSeen: now, NotifiedAt: r.NotifiedAt}
+		}
+		r.Type, r.Severity, r.Description = a.Type, a.Severity, a.Description
+		r.Agent, r.Cron, r.SessionID = a.Agent, a.Cron, a.SessionID
+		r.LastSeen = now
+		changed = append(changed, r)
+	}
+	if !partial {
+		for _, r := range tracked {
+			if r.Open() && !seen[r.ID] {
+				r.ResolvedAt = now
+				changed = append(changed, r)
+			}
+		}
+	}
+	return changed
+}
+
+// sortAnomalies orders records by when they opened, then by ID.
+func sortAnomalies(records []AnomalyRecord) {
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].FirstSeen.Equal(records[j].FirstSeen) {
+			return records[i].FirstSeen.Before(records[j].FirstSeen)
+		}
+		return records[i].ID < records[j].ID
+	})
+}
+
+// anomaliesPath is the file FileStore tracks anomalies in. It is kept in a
+// subdirectory, which Query skips, so it is never mistaken for a day file.
+func (f *FileStore) anomaliesPath() string {
+	return filepath.Join(f.dir, "state", "anomalies.json")
+}
+
+// PutAnomalies upserts records into the anomalies file.
+func (f *FileStore) PutAnomalies(ctx context.Context, records []AnomalyRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	existing, err := f.readAnomalies()
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]AnomalyRecord, len(existing)+len(records))
+	for _, r := range existing {
+		byID[r.ID] = r
+	}
+	for _, r := range records {
+		byID[r.ID] = r
+	}
+	merged := make([]AnomalyRecord, 0, len(byID))
+	for _, r := range byID {
+		merged = append(merged, r)
+	}
+	sortAnomalies(merged)
+
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.anomaliesPath()), 0755); err != nil {
+		return fmt.Errorf("failed to create store directory: %w", err)
+	}
+	return writeFileAtomic(f.anomaliesPath(), data)
+}
+
+// Anomalies reads the anomalies file.
+func (f *FileStore) Anomalies(ctx context.Context) ([]AnomalyRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.readAnomalies()
+}
+
+func (f *FileStore) readAnomalies() ([]AnomalyRecord, error) {
+	data, err := os.ReadFile(f.anomaliesPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", f.anomaliesPath(), err)
+	}
+	var records []AnomalyRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", f.anomaliesPath(), err)
+	}
+	return records, nil
+}
+
+// sqlAnomalySchema is portable across SQLite and Postgres. Times are
+// RFC 3339 text, empty when unset.
+const sqlAnomalySchema = `CREATE TABLE IF NOT EXISTS anomalies (
+	id          TEXT PRIMARY KEY,
+	type        TEXT NOT NULL,
+	severity    TEXT NOT NULL,
+	description TEXT NOT NULL,
+	agent       TEXT NOT NULL,
+	cron_name   TEXT NOT NULL,
+	session_id  TEXT NOT NULL,
+	first_seen  TEXT NOT NULL,
+	last_seen   TEXT NOT NULL,
+	resolved_at TEXT NOT NULL,
+	notified_at TEXT NOT NULL
+)`
+
+const sqlAnomalyUpsert = `INSERT INTO anomalies (
+	id, type, severity, description, agent, cron_name, session_id,
+	first_seen, last_seen, resolved_at, notified_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (id) DO UPDATE SET
+	type = excluded.type,
+	severity = excluded.severity,
+	description = excluded.description,
+	agent = excluded.agent,
+	cron_name = excluded.cron_name,
+	session_id = excluded.session_id,
+	first_seen = excluded.first_seen,
+	last_seen = excluded.last_seen,
+	resolved_at = excluded.resolved_at,
+	notified_at = excluded.notified_at`
+
+const sqlAnomalySelect = `SELECT
+	id, type, severity, description, agent, cron_name, session_id,
+	first_seen, last_seen, resolved_at, notified_at
+FROM anomalies`
+
+// PutAnomalies upserts records in a single transaction.
+func (s *SQLStore) PutAnomalies(ctx context.Context, records []AnomalyRecord) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, sqlAnomalyUpsert)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, r := range records {
+		if _, err := stmt.ExecContext(ctx,
+			r.ID, r.Type, r.Severity, r.Description, r.Agent, r.Cron, r.SessionID,
+			formatTime(r.FirstSeen), formatTime(r.LastSeen), formatTime(r.ResolvedAt), formatTime(r.NotifiedAt),
+		); err != nil {
+			return fmt.Errorf("failed to upsert anomaly %s: %w", r.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Anomalies selects every tracked anomaly.
+func (s *SQLStore) Anomalies(ctx context.Context) ([]AnomalyRecord, error) {
+	rows, err := s.db.QueryContext(ctx, sqlAnomalySelect)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []AnomalyRecord
+	for rows.Next() {
+		var r AnomalyRecord
+		var times [4]string
+		if err := rows.Scan(
+			&r.ID, &r.Type, &r.Severity, &r.Description, &r.Agent, &r.Cron, &r.SessionID,
+			&times[0], &times[1], &times[2], &times[3],
+		); err != nil {
+			return nil, err
+		}
+		for i, t := range []*time.Time{&r.FirstSeen, &r.LastSeen, &r.ResolvedAt, &r.NotifiedAt} {
+			if *t, err = parseTime(times[i]); err != nil {
+				return nil, fmt.Errorf("anomaly %s: %w", r.ID, err)
+			}
+		}
+		records = append(records, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sortAnomalies(records)
+	return records, nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/reporter"
+)
+
+func TestTrackAnomalies(t *testing.T) {
+	day1 := time.Date(2026, 2, 10, 9, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	day3 := day2.Add(24 * time.Hour)
+	cron := reporter.Anomaly{ID: "a1", Type: "expensive_cron", Agent: "urza", SessionID: "s1"}
+	chatty := reporter.Anomaly{ID: "a2", Type: "chatty_agent", Agent: "amos"}
+
+	tracked := TrackAnomalies(nil, []reporter.Anomaly{cron, chatty, cron}, day1, false)
+	if len(tracked) != 2 || !tracked[0].FirstSeen.Equal(day1) || !tracked[0].Open() {
+		t.Fatalf("expected 2 anomalies opened on day 1, got %+v", tracked)
+	}
+	tracked[0].NotifiedAt = day1
+
+	// Day 2 finds only the cron anomaly, which stays open; the other resolves.
+	changed := TrackAnomalies(tracked, []reporter.Anomaly{cron}, day2, false)
+	if len(changed) != 2 {
+		t.Fatalf("expected 2 changes on day 2, got %+v", changed)
+	}
+	if r := changed[0]; r.ID != "a1" || !r.FirstSeen.Equal(day1) || !r.LastSeen.Equal(day2) || !r.Notified() {
+		t.Errorf("expected a1 seen again, still notified, got %+v", r)
+	}
+	if r := changed[1]; r.ID != "a2" || r.Open() || !r.ResolvedAt.Equal(day2) {
+		t.Errorf("expected a2 resolved on day 2, got %+v", r)
+	}
+
+	// A partial run resolves nothing.
+	if changed := TrackAnomalies(changed, nil, day3, true); len(changed) != 0 {
+		t.Errorf("expected a partial run to change nothing, got %+v", changed)
+	}
+
+	// Found again, a resolved anomaly reopens and is due a notification.
+	resolved := AnomalyRecord{ID: "a1", FirstSeen: day1, ResolvedAt: day2, NotifiedAt: day1}
+	reopened := TrackAnomalies([]AnomalyRecord{resolved}, []reporter.Anomaly{cron}, day3, false)
+	if len(reopened) != 1 || !reopened[0].Open() || !reopened[0].FirstSeen.Equal(day3) || reopened[0].Notified() {
+		t.Errorf("expected a1 to reopen on day 3 unnotified, got %+v", reopened)
+	}
+}
+
+func TestFileStoreAnomalies(t *testing.T) {
+	testAnomalyStore(t, "file:"+t.TempDir())
+}
+
+func TestSQLiteStoreAnomalies(t *testing.T) {
+	testAnomalyStore(t, "sqlite:"+filepath.Join(t.TempDir(), "history.db"))
+}
+
+// testAnomalyStore exercises the AnomalyStore contract against any backend.
+func testAnomalyStore(t *testing.T, dsn string) {
+	ctx := context.Background()
+	s, err := Open(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	as, ok := s.(AnomalyStore)
+	if !ok {
+		t.Fatalf("%T does not track anomalies", s)
+	}
+
+	day1 := time.Date(2026, 2, 10, 9, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	records := []AnomalyRecord{
+		{ID: "b", Type: "chatty_agent", Agent: "amos", FirstSeen: day2, LastSeen: day2},
+		{ID: "a", Type: "expensive_cron", Agent: "urza", Cron: "sync", SessionID: "s1", FirstSeen: day1, LastSeen: day1, NotifiedAt: day1},
+	}
+	if err := as.PutAnomalies(ctx, records); err != nil {
+		t.Fatal(err)
+	}
+	records[1].ResolvedAt = day2
+	if err := as.PutAnomalies(ctx, records[1:]); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := as.Anomalies(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "a" || got[1].ID != "b" {
+		t.Fatalf("expected anomalies a and b, oldest first, got %+v", got)
+	}
+	if got[0] != records[1] || got[1] != records[0] {
+		t.Errorf("round trip changed records:\n got %+v\nwant %+v", got, []AnomalyRecord{records[1], records[0]})
+	}
+
+	// Tracked anomalies are not day files.
+	if rows, err := s.Query(ctx, Query{}); err != nil || len(rows) != 0 {
+		t.Errorf("expected no aggregates, got %v, %v", rows, err)
+	}
+}
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FileStore keeps one JSON file of aggregates per day under a directory.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates a FileStore rooted at dir, creating it if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Put upserts aggregates into their day files.
+func (f *FileStore) Put(ctx context.Context, rows []DailyAggregate) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	byDate := make(map[string][]DailyAggregate)
+	for _, row := range rows {
+		byDate[row.Date] = append(byDate[row.Date], row)
+	}
+
+	for date, incoming := range byDate {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		existing, err := f.readDay(date)
+		if err != nil {
+			return err
+		}
+
+		merged := make(map[string]DailyAggregate, len(existing)+len(incoming))
+		for _, row := range existing {
+			merged[row.Key()] = row
+		}
+		for _, row := range incoming {
+			merged[row.Key()] = row
+		}
+
+		day := make([]DailyAggregate, 0, len(merged))
+		for _, row := range merged {
+			day = append(day, row)
+		}
+		sort.Slice(day, func(i, j int) bool {
+			return day[i].Key() < day[j].Key()
+		})
+
+		if err := f.writeDay(date, day); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Query scans the day files within the query's date bounds.
+func (f *FileStore) Query(ctx context.Context, q Query) ([]DailyAggregate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store directory: %w", err)
+	}
+
+	var result []DailyAggregate
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		date := strings.TrimSuffix(entry.Name(), ".json")
+		if (q.Since != "" && date < q.Since) || (q.Until != "" && date > q.Until) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		rows, err := f.readDay(date)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			if q.Match(row) {
+				result = append(result, row)
+			}
+		}
+	}
+
+	// ReadDir returns entries sorted by name, so result is already in date order.
+	return result, nil
+}
+
+// Close is a no-op for FileStore.
+func (f *FileStore) Close() error {
+	return nil
+}
+
+func (f *FileStore) dayPath(date string) string {
+	return filepath.Join(f.dir, date+".json")
+}
+
+func (f *FileStore) readDay(date string) ([]DailyAggregate, error) {
+	data, err := os.ReadFile(f.dayPath(date))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", f.dayPath(date), err)
+	}
+
+	var rows []DailyAggregate
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", f.dayPath(date), err)
+	}
+	return rows, nil
+}
+
+// writeDay replaces a day file atomically so readers never see a partial write.
+func (f *FileStore) writeDay(date string, rows []DailyAggregate) error {
+	data, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(f.dayPath(date), data)
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the
+// same directory.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
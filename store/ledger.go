@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+// sqlSessionSchema is the session ledger: one row per ingested session,
+// holding its parser.Record. It is portable across SQLite and Postgres.
+const sqlSessionSchema = `CREATE TABLE IF NOT EXISTS sessions (
+	host       TEXT NOT NULL,
+	tenant     TEXT NOT NULL DEFAULT '',
+	agent      TEXT NOT NULL,
+	id         TEXT NOT NULL,
+	started_at BIGINT NOT NULL,
+	cost       DOUBLE PRECISION NOT NULL,
+	record     TEXT NOT NULL,
+	PRIMARY KEY (host, tenant, agent, id)
+)`
+
+const sqlSessionUpsert = `INSERT INTO sessions (host, tenant, agent, id, started_at, cost, record)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (host, tenant, agent, id) DO UPDATE SET
+	started_at = excluded.started_at,
+	cost = excluded.cost,
+	record = excluded.record`
+
+// SessionQuery selects ledger sessions. Zero fields are unbounded.
+type SessionQuery struct {
+	Since  time.Time // inclusive start time
+	Until  time.Time // exclusive start time
+	Agent  string
+	Host   string
+	Tenant string
+}
+
+// SessionStore is implemented by stores that keep a ledger of individual
+// sessions, which unlike daily aggregates keeps each session's
+// parser.Record. SQLStore does.
+type SessionStore interface {
+	// PutSessions upserts sessions parsed on host, replacing any stored
+	// with the same tenant, agent and ID. It returns how many were new.
+	PutSessions(ctx context.Context, host string, sessions []parser.Session) (int, error)
+	// Sessions returns the ledger sessions matching q, oldest first.
+	Sessions(ctx context.Context, q SessionQuery) ([]parser.Session, error)
+}
+
+// PutSessions upserts sessions into the ledger in a single transaction.
+// A session still being written is replaced on each ingest until its
+// transcript stops growing.
+func (s *SQLStore) PutSessions(ctx context.Context, host string, sessions []parser.Session) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	exists, err := tx.PrepareContext(ctx, "SELECT 1 FROM sessions WHERE host = $1 AND tenant = $2 AND agent = $3 AND id = $4")
+	if err != nil {
+		return 0, err
+	}
+	defer exists.Close()
+	upsert, err := tx.PrepareContext(ctx, sqlSessionUpsert)
+	if err != nil {
+		return 0, err
+	}
+	defer upsert.Close()
+
+	added := 0
+	for _, session := range sessions {
+		record, err := json.Marshal(parser.NewRecord(session))
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode session %s: %w", session.ID, err)
+		}
+		rows, err := exists.QueryContext(ctx, host, session.Tenant, session.Agent, session.ID)
+		if err != nil {
+			return 0, err
+		}
+		found := rows.Next()
+		rows.Close()
+		if !found {
+			added++
+		}
+		if _, err := upsert.ExecContext(ctx,
+			host, session.Tenant, session.Agent, session.ID,
+			startedAt(session), session.Usage.CostTotal, string(record),
+		); err != nil {
+			return 0, fmt.Errorf("failed to upsert session %s: %w", session.ID, err)
+		}
+	}
+	return added, tx.Commit()
+}
+
+// Sessions selects the ledger sessions matching q, oldest first.
+func (s *SQLStore) Sessions(ctx context.Context, q SessionQuery) ([]parser.Session, error) {
+	var where []string
+	var args []interface{}
+	add := func(clause string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(clause, len(args)))
+	}
+	if !q.Since.IsZero() {
+		add("started_at >= $%d", q.Since.UnixNano())
+	}
+	if !q.Until.IsZero() {
+		add("started_at < $%d", q.Until.UnixNano())
+	}
+	if q.Agent != "" {
+		add("agent = $%d", q.Agent)
+	}
+	if q.Host != "" {
+		add("host = $%d", q.Host)
+	}
+	if q.Tenant != "" {
+		add("tenant = $%d", q.Tenant)
+	}
+
+	query := "SELECT record FROM sessions"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY started_at, host, tenant, agent, id"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []parser.Session
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var record parser.Record
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			return nil, fmt.Errorf("invalid ledger session: %w", err)
+		}
+		if !parser.SupportedRecordVersion(record.Version) {
+			return nil, fmt.Errorf("unsupported ledger session version %d (want %d)", record.Version, parser.RecordVersion)
+		}
+		sessions = append(sessions, record.Session())
+	}
+	return sessions, rows.Err()
+}
+
+// startedAt is the ledger's sort key of s: its start time in Unix
+// nanoseconds, or 0 for sessions without one.
+func startedAt(s parser.Session) int64 {
+	if s.StartedAt.IsZero() {
+		return 0
+	}
+	return s.StartedAt.UnixNano()
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestSQLiteStoreSessions(t *testing.T) {
+	testSessionStore(t, "sqlite:"+filepath.Join(t.TempDir(), "history.db"))
+}
+
+// testSessionStore exercises the SessionStore contract against any backend.
+func testSessionStore(t *testing.T, dsn string) {
+	ctx := context.Background()
+	s, err := Open(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ledger, ok := s.(SessionStore)
+	if !ok {
+		t.Fatalf("%T does not keep sessions", s)
+	}
+
+	day1 := time.Date(2026, 2, 10, 9, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	sessions := []parser.Session{
+		{ID: "s2", Agent: "amos", Type: parser.SessionTypeInteractive, StartedAt: day2, EndedAt: day2.Add(time.Hour),
+			Usage: parser.Usage{Total: 300, CostTotal: 0.3, Model: "opus"}},
+		{ID: "s1", Agent: "urza", Tenant: "acme", Type: parser.SessionTypeCron, CronName: "sync", StartedAt: day1,
+			Usage: parser.Usage{Total: 100, CostTotal: 0.1, Model: "kimi"}, TokensByRole: map[string]int{"assistant": 100}},
+	}
+	if added, err := ledger.PutSessions(ctx, "host-a", sessions); err != nil || added != 2 {
+		t.Fatalf("expected 2 new sessions, got %d, %v", added, err)
+	}
+	// Re-ingesting a session that grew replaces it.
+	sessions[0].Usage.CostTotal = 0.5
+	if added, err := ledger.PutSessions(ctx, "host-a", sessions[:1]); err != nil || added != 0 {
+		t.Fatalf("expected no new sessions, got %d, %v", added, err)
+	}
+	// The same session on another host is another row.
+	if added, err := ledger.PutSessions(ctx, "host-b", sessions[:1]); err != nil || added != 1 {
+		t.Fatalf("expected 1 new session, got %d, %v", added, err)
+	}
+
+	got, err := ledger.Sessions(ctx, SessionQuery{Host: "host-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "s1" || got[1].ID != "s2" {
+		t.Fatalf("expected s1 and s2, oldest first, got %+v", got)
+	}
+	if got[0].Tenant != "acme" || got[0].CronName != "sync" || got[0].TokensByRole["assistant"] != 100 || !got[0].StartedAt.Equal(day1) {
+		t.Errorf("round trip lost detail: %+v", got[0])
+	}
+	if got[1].Usage.CostTotal != 0.5 {
+		t.Errorf("expected the re-ingested cost, got %v", got[1].Usage.CostTotal)
+	}
+
+	for _, tc := range []struct {
+		query SessionQuery
+		want  int
+	}{
+		{SessionQuery{}, 3},
+		{SessionQuery{Since: day2}, 2},
+		{SessionQuery{Until: day2}, 1},
+		{SessionQuery{Agent: "amos"}, 2},
+		{SessionQuery{Tenant: "acme"}, 1},
+	} {
+		got, err := ledger.Sessions(ctx, tc.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != tc.want {
+			t.Errorf("%+v: expected %d sessions, got %d", tc.query, tc.want, len(got))
+		}
+	}
+
+	// Ledger sessions are not aggregates.
+	if rows, err := s.Query(ctx, Query{}); err != nil || len(rows) != 0 {
+		t.Errorf("expected no aggregates, got %v, %v", rows, err)
+	}
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPostgres runs the store contracts against the real server at
+// $COSTCTL_POSTGRES_DSN, which SQLite cannot stand in for: it checks the
+// placeholders, upserts and column types against what Postgres accepts.
+// Each contract runs in a schema of its own, dropped afterwards.
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv("COSTCTL_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("COSTCTL_POSTGRES_DSN not set")
+	}
+	for _, tc := range []struct {
+		name string
+		test func(*testing.T, string)
+	}{
+		{"PutQuery", testStorePutQuery},
+		{"Anomalies", testAnomalyStore},
+		{"Sessions", testSessionStore},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.test(t, postgresSchema(t, dsn))
+		})
+	}
+}
+
+// postgresSchema creates an empty schema on the server at dsn and returns
+// a DSN whose tables live in it. The schema is dropped when t ends.
+func postgresSchema(t *testing.T, dsn string) string {
+	connect := dsn
+	if dsn == "postgres" {
+		// Configured from PG* environment variables.
+		connect = ""
+	}
+	db, err := sql.Open("postgres", connect)
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	schema := fmt.Sprintf("costctl_test_%d", time.Now().UnixNano())
+	if _, err := db.Exec("CREATE SCHEMA " + schema); err != nil {
+		db.Close()
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := db.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("failed to drop schema %s: %v", schema, err)
+		}
+		db.Close()
+	})
+
+	// search_path is passed to the server as a run-time parameter.
+	if !strings.Contains(dsn, "://") {
+		return strings.TrimSpace(connect + " search_path=" + schema)
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("invalid COSTCTL_POSTGRES_DSN: %v", err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
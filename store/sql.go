@@ -0,0 +1,187 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/misty-step/costctl/parser"
+
+	_ "github.com/lib/pq"  // Postgres driver
+	_ "modernc.org/sqlite" // pure-Go SQLite driver, keeps the binary cgo-free
+)
+
+// sqlSchema is portable across SQLite and Postgres.
+const sqlSchema = `CREATE TABLE IF NOT EXISTS daily_aggregates (
+	date          TEXT NOT NULL,
+	host          TEXT NOT NULL,
+	tenant        TEXT NOT NULL DEFAULT '',
+	agent         TEXT NOT NULL,
+	session_type  TEXT NOT NULL,
+	cron_name     TEXT NOT NULL,
+	model         TEXT NOT NULL,
+	sessions      BIGINT NOT NULL,
+	total_cost    DOUBLE PRECISION NOT NULL,
+	input_tokens  BIGINT NOT NULL,
+	output_tokens BIGINT NOT NULL,
+	total_tokens  BIGINT NOT NULL,
+	cache_read    BIGINT NOT NULL,
+	cache_write   BIGINT NOT NULL,
+	PRIMARY KEY (date, host, tenant, agent, session_type, cron_name, model)
+)`
+
+const sqlUpsert = `INSERT INTO daily_aggregates (
+	date, host, tenant, agent, session_type, cron_name, model,
+	sessions, total_cost, input_tokens, output_tokens, total_tokens, cache_read, cache_write
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (date, host, tenant, agent, session_type, cron_name, model) DO UPDATE SET
+	sessions = excluded.sessions,
+	total_cost = excluded.total_cost,
+	input_tokens = excluded.input_tokens,
+	output_tokens = excluded.output_tokens,
+	total_tokens = excluded.total_tokens,
+	cache_read = excluded.cache_read,
+	cache_write = excluded.cache_write`
+
+const sqlSelect = `SELECT
+	date, host, tenant, agent, session_type, cron_name, model,
+	sessions, total_cost, input_tokens, output_tokens, total_tokens, cache_read, cache_write
+FROM daily_aggregates`
+
+// SQLStore keeps aggregates in a SQL database. The statements it uses are
+// valid for both SQLite and Postgres.
+type SQLStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens (creating if needed) a SQLite database at path.
+func OpenSQLite(path string) (*SQLStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite store: %w", err)
+	}
+	// SQLite allows a single writer; serialize access through one connection.
+	db.SetMaxOpenConns(1)
+	return newSQLStore(db)
+}
+
+// OpenPostgres connects to a Postgres database, e.g. a warehouse shared by
+// every machine in a fleet. dsn is a postgres:// URL or key=value string.
+func OpenPostgres(dsn string) (*SQLStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres store: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to postgres store: %w", err)
+	}
+	return newSQLStore(db)
+}
+
+func newSQLStore(db *sql.DB) (*SQLStore, error) {
+	if _, err := db.Exec(sqlSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize store schema: %w", err)
+	}
+	if _, err := db.Exec(sqlAnomalySchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize store schema: %w", err)
+	}
+	if _, err := db.Exec(sqlSessionSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize store schema: %w", err)
+	}
+	return &SQLStore{db: db}, nil
+}
+
+// Put upserts aggregates in a single transaction.
+func (s *SQLStore) Put(ctx context.Context, rows []DailyAggregate) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, sqlUpsert)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx,
+			row.Date, row.Host, row.Tenant, row.Agent, string(row.SessionType), row.CronName, row.Model,
+			row.Sessions, row.TotalCost, row.InputTokens, row.OutputTokens, row.TotalTokens,
+			row.CacheRead, row.CacheWrite,
+		); err != nil {
+			return fmt.Errorf("failed to upsert aggregate for %s: %w", row.Date, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Query selects aggregates matching q, ordered by date.
+func (s *SQLStore) Query(ctx context.Context, q Query) ([]DailyAggregate, error) {
+	var where []string
+	var args []interface{}
+	add := func(clause string, arg string) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(clause, len(args)))
+	}
+	if q.Since != "" {
+		add("date >= $%d", q.Since)
+	}
+	if q.Until != "" {
+		add("date <= $%d", q.Until)
+	}
+	if q.Agent != "" {
+		add("agent = $%d", q.Agent)
+	}
+	if q.Host != "" {
+		add("host = $%d", q.Host)
+	}
+	if q.Tenant != "" {
+		add("tenant = $%d", q.Tenant)
+	}
+
+	query := sqlSelect
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY date, host, tenant, agent, session_type, cron_name, model"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []DailyAggregate
+	for rows.Next() {
+		var a DailyAggregate
+		var sessionType string
+		if err := rows.Scan(
+			&a.Date, &a.Host, &a.Tenant, &a.Agent, &sessionType, &a.CronName, &a.Model,
+			&a.Sessions, &a.TotalCost, &a.InputTokens, &a.OutputTokens, &a.TotalTokens,
+			&a.CacheRead, &a.CacheWrite,
+		); err != nil {
+			return nil, err
+		}
+		a.SessionType = parser.SessionType(sessionType)
+		result = append(result, a)
+	}
+	return result, rows.Err()
+}
+
+// Close closes the underlying database.
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,206 @@
+// Package store persists daily cost aggregates so cost history outlives
+// the transcripts it was computed from.
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/reporter"
+)
+
+// DailyAggregate is one day's usage for a unique combination of host,
+// tenant, agent, session type, cron, and model. It is the unit of storage:
+// reports over any of those dimensions can be rebuilt from it.
+type DailyAggregate struct {
+	Date         string             `json:"date"` // YYYY-MM-DD, local time
+	Host         string             `json:"host,omitempty"`
+	Tenant       string             `json:"tenant,omitempty"`
+	Agent        string             `json:"agent"`
+	SessionType  parser.SessionType `json:"session_type"`
+	CronName     string             `json:"cron_name,omitempty"`
+	Model        string             `json:"model"`
+	Sessions     int                `json:"sessions"`
+	TotalCost    float64            `json:"total_cost"`
+	InputTokens  int                `json:"input_tokens"`
+	OutputTokens int                `json:"output_tokens"`
+	TotalTokens  int                `json:"total_tokens"`
+	CacheRead    int                `json:"cache_read"`
+	CacheWrite   int                `json:"cache_write"`
+}
+
+// Key identifies the aggregate for upserts.
+func (a DailyAggregate) Key() string {
+	return strings.Join([]string{a.Date, a.Host, a.Tenant, a.Agent, string(a.SessionType), a.CronName, a.Model}, "\x00")
+}
+
+// Query selects stored aggregates. Empty fields are unbounded.
+type Query struct {
+	Since  string // inclusive YYYY-MM-DD
+	Until  string // inclusive YYYY-MM-DD
+	Agent  string
+	Host   string
+	Tenant string
+}
+
+// Match reports whether the aggregate satisfies the query.
+func (q Query) Match(a DailyAggregate) bool {
+	if q.Since != "" && a.Date < q.Since {
+		return false
+	}
+	if q.Until != "" && a.Date > q.Until {
+		return false
+	}
+	if q.Agent != "" && a.Agent != q.Agent {
+		return false
+	}
+	if q.Host != "" && a.Host != q.Host {
+		return false
+	}
+	if q.Tenant != "" && a.Tenant != q.Tenant {
+		return false
+	}
+	return true
+}
+
+// Store is a backend for daily aggregates.
+type Store interface {
+	// Put upserts aggregates, replacing any stored row with the same Key.
+	Put(ctx context.Context, rows []DailyAggregate) error
+	// Query returns the aggregates matching q, ordered by date.
+	Query(ctx context.Context, q Query) ([]DailyAggregate, error)
+	// Close releases any resources held by the store.
+	Close() error
+}
+
+// Open opens a store from a DSN. Supported forms:
+//   - file:/path/to/dir (or a bare path) → FileStore
+//   - sqlite:/path/to/costctl.db → SQLStore
+//   - postgres://user@host/db or "host=... dbname=..." → SQLStore
+//   - postgres → SQLStore configured from PG* environment variables
+func Open(dsn string) (Store, error) {
+	if dsn == "postgres" {
+		return OpenPostgres("")
+	}
+
+	scheme, location, ok := strings.Cut(dsn, ":")
+	switch {
+	case strings.Contains(scheme, "="):
+		// key=value Postgres connection string.
+		scheme = "postgres"
+	case !ok || len(scheme) == 1:
+		// Bare paths, including Windows drive letters (C:\...), are directories.
+		scheme, location = "file", dsn
+	}
+
+	switch scheme {
+	case "file":
+		path, err := parser.ExpandPath(location)
+		if err != nil {
+			return nil, err
+		}
+		return NewFileStore(path)
+	case "sqlite":
+		path, err := parser.ExpandPath(location)
+		if err != nil {
+			return nil, err
+		}
+		return OpenSQLite(path)
+	case "postgres", "postgresql":
+		return OpenPostgres(dsn)
+	default:
+		return nil, fmt.Errorf("unsupported store: %s (valid: file, sqlite, postgres)", scheme)
+	}
+}
+
+// Sessions rebuilds sessions from stored aggregates so they can be fed to
+// the reporter. Each aggregate becomes one session weighted by its count
+// and dated at the start of its day; per-session detail is not recoverable.
+func Sessions(rows []DailyAggregate) ([]parser.Session, error) {
+	sessions := make([]parser.Session, 0, len(rows))
+	for _, row := range rows {
+		day, err := time.ParseInLocation("2006-01-02", row.Date, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid aggregate date %q: %w", row.Date, err)
+		}
+		model := row.Model
+		if model == "unknown" {
+			model = ""
+		}
+		sessions = append(sessions, parser.Session{
+			ID:        "aggregate:" + row.Key(),
+			Agent:     row.Agent,
+			Tenant:    row.Tenant,
+			Type:      row.SessionType,
+			CronName:  row.CronName,
+			StartedAt: day,
+			Count:     row.Sessions,
+			Usage: parser.Usage{
+				Input:      row.InputTokens,
+				Output:     row.OutputTokens,
+				Total:      row.TotalTokens,
+				CacheRead:  row.CacheRead,
+				CacheWrite: row.CacheWrite,
+				CostTotal:  row.TotalCost,
+				Model:      model,
+			},
+		})
+	}
+	return sessions, nil
+}
+
+type aggregateKey struct {
+	date        string
+	tenant      string
+	agent       string
+	sessionType parser.SessionType
+	cronName    string
+	model       string
+}
+
+// Aggregate folds sessions into daily aggregates attributed to host.
+// Sessions without a start time cannot be placed on a day and are skipped.
+func Aggregate(sessions []parser.Session, host string) []DailyAggregate {
+	dim := reporter.Dimension[aggregateKey, DailyAggregate]{
+		Key: func(s parser.Session) (aggregateKey, bool) {
+			if s.StartedAt.IsZero() {
+				return aggregateKey{}, false
+			}
+			model := s.Usage.Model
+			if model == "" {
+				model = "unknown"
+			}
+			return aggregateKey{
+				date:        s.StartedAt.Local().Format("2006-01-02"),
+				tenant:      s.Tenant,
+				agent:       s.Agent,
+				sessionType: s.Type,
+				cronName:    s.CronName,
+				model:       model,
+			}, true
+		},
+		Build: func(k aggregateKey, acc *reporter.Accumulator) DailyAggregate {
+			return DailyAggregate{
+				Date:         k.date,
+				Host:         host,
+				Tenant:       k.tenant,
+				Agent:        k.agent,
+				SessionType:  k.sessionType,
+				CronName:     k.cronName,
+				Model:        k.model,
+				Sessions:     acc.Sessions,
+				TotalCost:    acc.TotalCost,
+				InputTokens:  acc.InputTokens,
+				OutputTokens: acc.OutputTokens,
+				TotalTokens:  acc.TotalTokens,
+				CacheRead:    acc.CacheRead,
+				CacheWrite:   acc.CacheWrite,
+			}
+		},
+		Less: func(a, b DailyAggregate) bool { return a.Key() < b.Key() },
+	}
+	return dim.Aggregate(sessions)
+}
@@ -0,0 +1,158 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func TestAggregate(t *testing.T) {
+	day1 := time.Date(2026, 2, 10, 10, 0, 0, 0, time.Local)
+	day2 := time.Date(2026, 2, 11, 10, 0, 0, 0, time.Local)
+	sessions := []parser.Session{
+		{Agent: "urza", Type: parser.SessionTypeCron, CronName: "sync", StartedAt: day1, Usage: parser.Usage{CostTotal: 1.0, Total: 100, Model: "kimi"}},
+		{Agent: "urza", Type: parser.SessionTypeCron, CronName: "sync", StartedAt: day1, Usage: parser.Usage{CostTotal: 0.5, Total: 50, Model: "kimi"}},
+		{Agent: "urza", Type: parser.SessionTypeInteractive, StartedAt: day1, Usage: parser.Usage{CostTotal: 2.0, Model: "opus"}},
+		{Agent: "amos", Type: parser.SessionTypeInteractive, StartedAt: day2, Usage: parser.Usage{CostTotal: 3.0}},
+		{Agent: "amos", Usage: parser.Usage{CostTotal: 9.0}}, // no timestamp, skipped
+	}
+
+	rows := Aggregate(sessions, "host-a")
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 aggregates, got %d: %+v", len(rows), rows)
+	}
+
+	var total float64
+	for _, row := range rows {
+		if row.Host != "host-a" {
+			t.Errorf("expected host-a, got %q", row.Host)
+		}
+		total += row.TotalCost
+		if row.CronName == "sync" && (row.Sessions != 2 || row.TotalTokens != 150) {
+			t.Errorf("expected sync aggregate with 2 sessions and 150 tokens, got %+v", row)
+		}
+		if row.Agent == "amos" && row.Model != "unknown" {
+			t.Errorf("expected empty model to be recorded as unknown, got %q", row.Model)
+		}
+	}
+	if total != 6.5 {
+		t.Errorf("expected total cost 6.5, got %f", total)
+	}
+}
+
+func TestFileStorePutQuery(t *testing.T) {
+	testStorePutQuery(t, "file:"+t.TempDir())
+}
+
+func TestSQLiteStorePutQuery(t *testing.T) {
+	testStorePutQuery(t, "sqlite:"+filepath.Join(t.TempDir(), "history.db"))
+}
+
+// testStorePutQuery exercises the Store contract against any backend.
+func testStorePutQuery(t *testing.T, dsn string) {
+	ctx := context.Background()
+	s, err := Open(dsn)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer s.Close()
+
+	rows := []DailyAggregate{
+		{Date: "2026-02-10", Agent: "urza", Model: "kimi", Sessions: 1, TotalCost: 1.0},
+		{Date: "2026-02-10", Agent: "amos", Model: "kimi", Sessions: 1, TotalCost: 2.0},
+		{Date: "2026-02-11", Agent: "urza", Model: "kimi", Sessions: 1, TotalCost: 3.0},
+		// Same agent name under another tenant is a distinct row.
+		{Date: "2026-02-10", Tenant: "acme", Agent: "urza", Model: "kimi", Sessions: 1, TotalCost: 4.0},
+	}
+	if err := s.Put(ctx, rows); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	// Re-putting the same key replaces rather than duplicates.
+	if err := s.Put(ctx, []DailyAggregate{{Date: "2026-02-10", Agent: "urza", Model: "kimi", Sessions: 2, TotalCost: 1.5}}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	all, err := s.Query(ctx, Query{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(all) != 4 {
+		t.Fatalf("expected 4 rows, got %d", len(all))
+	}
+	if all[0].Date != "2026-02-10" || all[3].Date != "2026-02-11" {
+		t.Errorf("expected rows ordered by date, got %+v", all)
+	}
+
+	tests := []struct {
+		name     string
+		query    Query
+		expected float64
+	}{
+		{"since", Query{Since: "2026-02-11"}, 3.0},
+		{"until", Query{Until: "2026-02-10"}, 7.5},
+		{"agent", Query{Agent: "urza"}, 8.5},
+		{"host", Query{Host: "elsewhere"}, 0},
+		{"tenant", Query{Tenant: "acme"}, 4.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.Query(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("Query failed: %v", err)
+			}
+			var total float64
+			for _, row := range result {
+				total += row.TotalCost
+			}
+			if total != tt.expected {
+				t.Errorf("expected total %f, got %f", tt.expected, total)
+			}
+		})
+	}
+}
+
+func TestOpenUnsupported(t *testing.T) {
+	if _, err := Open("mysql://localhost/costs"); err == nil {
+		t.Error("expected error for unsupported store scheme")
+	}
+}
+
+func TestSessionsFromAggregates(t *testing.T) {
+	rows := []DailyAggregate{
+		{Date: "2026-02-10", Agent: "urza", SessionType: parser.SessionTypeCron, CronName: "sync", Model: "kimi", Sessions: 4, TotalCost: 2.0},
+		{Date: "2026-02-11", Agent: "amos", SessionType: parser.SessionTypeInteractive, Model: "unknown", Sessions: 1, TotalCost: 1.0},
+	}
+
+	sessions, err := Sessions(rows)
+	if err != nil {
+		t.Fatalf("Sessions failed: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	if sessions[0].Weight() != 4 || sessions[0].CronName != "sync" {
+		t.Errorf("expected cron session weighted 4, got %+v", sessions[0])
+	}
+	if sessions[1].Usage.Model != "" {
+		t.Errorf("expected unknown model to round-trip as empty, got %q", sessions[1].Usage.Model)
+	}
+
+	// Round-tripping through Aggregate restores the stored rows.
+	back := Aggregate(sessions, "")
+	if len(back) != 2 || back[0].Sessions+back[1].Sessions != 5 {
+		t.Errorf("expected aggregates to round-trip, got %+v", back)
+	}
+}
+
+func TestOpenPostgresKeywordDSN(t *testing.T) {
+	// A key=value DSN must be routed to Postgres rather than treated as a path.
+	_, err := Open("host=127.0.0.1 port=1 dbname=costs connect_timeout=1 sslmode=disable")
+	if err == nil || !strings.Contains(err.Error(), "postgres") {
+		t.Errorf("expected postgres connection error, got %v", err)
+	}
+}
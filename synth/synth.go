@@ -0,0 +1,377 @@
+// Package synth fabricates realistic OpenClaw agents directories, for
+// demos, screenshots, benchmarks and integration tests.
+package synth
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/misty-step/costctl/catalog"
+)
+
+// Options controls what is generated. Counts are means: actual numbers
+// vary from day to day.
+type Options struct {
+	Agents         int       // number of agents
+	Days           int       // days of history, ending at End
+	End            time.Time // nothing is generated after this instant
+	SessionsPerDay float64   // mean interactive sessions per agent per day
+	CronsPerAgent  int       // daily crons per agent
+	SubagentShare  float64   // chance an interactive session spawns a sub-agent
+	OverrideShare  float64   // chance a session runs on OverrideModel instead of the agent's model
+	Models         []string  // default models, assigned to agents round-robin
+	OverrideModel  string    // model used by manual overrides
+	Seed           int64     // same seed and options, same output
+}
+
+// DefaultOptions returns a month of history for five agents.
+func DefaultOptions() Options {
+	return Options{
+		Agents:         5,
+		Days:           30,
+		End:            time.Now(),
+		SessionsPerDay: 6,
+		CronsPerAgent:  3,
+		SubagentShare:  0.2,
+		OverrideShare:  0.05,
+		Models:         []string{"moonshotai/kimi-k2.5", "anthropic/claude-sonnet-4-5", "anthropic/claude-haiku-4-5", "openai/gpt-4o"},
+		OverrideModel:  "anthropic/claude-opus-4-6",
+		Seed:           1,
+	}
+}
+
+// Stats summarizes a generated directory.
+type Stats struct {
+	Agents    int
+	Sessions  int
+	CronRuns  int
+	Subagents int
+	TotalCost float64
+}
+
+var (
+	agentNames = []string{"amos", "kaylee", "pepper", "abra", "urza", "pluto", "mishra", "cato", "venser"}
+	cronNames  = []string{"daily-kickoff", "code-reviewer", "inbox-triage", "standup-notes", "dependency-audit", "metrics-digest", "backup-check", "news-brief"}
+	words      = strings.Fields("the agent reviewed pull request tests failing deploy config cache model prompt budget cron schedule latency error retry summary report issue branch merge release notes customer ticket metrics dashboard query index")
+)
+
+// generator holds the state of one Generate call.
+type generator struct {
+	opts  Options
+	rng   *rand.Rand
+	stats Stats
+}
+
+// Generate writes an agents directory to dir: per agent, a config.json
+// with its default model, and a sessions directory of transcripts plus a
+// sessions.json index.
+func Generate(dir string, opts Options) (Stats, error) {
+	if opts.Agents <= 0 || opts.Days <= 0 {
+		return Stats{}, fmt.Errorf("agents and days must be positive")
+	}
+	if len(opts.Models) == 0 {
+		return Stats{}, fmt.Errorf("at least one model is required")
+	}
+	for _, model := range append([]string{opts.OverrideModel}, opts.Models...) {
+		if _, ok := catalog.Lookup(model); !ok {
+			return Stats{}, fmt.Errorf("model not in catalog: %s", model)
+		}
+	}
+
+	g := &generator{opts: opts, rng: rand.New(rand.NewSource(opts.Seed))}
+	for i := 0; i < opts.Agents; i++ {
+		name := fmt.Sprintf("agent-%d", i+1)
+		if i < len(agentNames) {
+			name = agentNames[i]
+		}
+		if err := g.agent(filepath.Join(dir, name), name, opts.Models[i%len(opts.Models)]); err != nil {
+			return g.stats, err
+		}
+		g.stats.Agents++
+	}
+	return g.stats, nil
+}
+
+// indexEntry is a sessions.json entry.
+type indexEntry struct {
+	SessionID string `json:"sessionId"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+// cronJob is a generated daily cron.
+type cronJob struct {
+	id     string
+	hour   int
+	turns  int
+	cached bool
+}
+
+// agent writes one agent's directory.
+func (g *generator) agent(dir, name, model string) error {
+	sessionsDir := filepath.Join(dir, "sessions")
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		return err
+	}
+	config, err := json.MarshalIndent(map[string]string{"model": model}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), append(config, '\n'), 0644); err != nil {
+		return err
+	}
+
+	var crons []cronJob
+	for i := 0; i < g.opts.CronsPerAgent; i++ {
+		crons = append(crons, cronJob{
+			id:     fmt.Sprintf("%s-%s", cronNames[(i+len(name))%len(cronNames)], g.token(6)),
+			hour:   g.rng.Intn(24),
+			turns:  2 + g.rng.Intn(6),
+			cached: g.rng.Float64() < 0.5,
+		})
+	}
+	systemTokens := 2000 + g.rng.Intn(6000)
+
+	// Index entries for cron and sub-agent runs. The agent's own
+	// "agent:{name}" entry is left out: the parser would apply its single
+	// timestamp to every interactive session.
+	index := make(map[string]indexEntry)
+	write := func(key string, start time.Time, turns int, model string, cached bool) error {
+		end, err := g.transcript(filepath.Join(sessionsDir, key+".jsonl"), key, start, turns, systemTokens, model, cached)
+		if err != nil {
+			return err
+		}
+		if strings.Contains(key, ":") {
+			index[key] = indexEntry{SessionID: key, UpdatedAt: end.UnixMilli()}
+		}
+		g.stats.Sessions++
+		return nil
+	}
+
+	end := g.opts.End
+	for d := g.opts.Days - 1; d >= 0; d-- {
+		day := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location()).AddDate(0, 0, -d)
+
+		for _, c := range crons {
+			start := day.Add(time.Duration(c.hour)*time.Hour + time.Duration(g.rng.Intn(120))*time.Second)
+			if start.After(end) {
+				continue
+			}
+			key := fmt.Sprintf("agent:%s:cron:%s:run:%s", name, c.id, g.token(8))
+			if err := write(key, start, c.turns, g.model(model), c.cached); err != nil {
+				return err
+			}
+			g.stats.CronRuns++
+		}
+
+		for i := g.poisson(g.opts.SessionsPerDay); i > 0; i-- {
+			start := day.Add(time.Duration(8*3600+g.rng.Intn(14*3600)) * time.Second)
+			if start.After(end) {
+				continue
+			}
+			if err := write(g.token(12), start, 1+g.rng.Intn(12), g.model(model), true); err != nil {
+				return err
+			}
+			if g.rng.Float64() < g.opts.SubagentShare {
+				key := fmt.Sprintf("agent:%s:subagent:%s", name, g.token(8))
+				if err := write(key, start.Add(time.Minute), 2+g.rng.Intn(8), g.model(model), false); err != nil {
+					return err
+				}
+				g.stats.Subagents++
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(sessionsDir, "sessions.json"), append(data, '\n'), 0644)
+}
+
+// Transcript line types, mirroring what OpenClaw writes.
+type (
+	event struct {
+		Type      string    `json:"type"`
+		Version   int       `json:"version,omitempty"`
+		ID        string    `json:"id,omitempty"`
+		Timestamp time.Time `json:"timestamp"`
+		Message   *message  `json:"message,omitempty"`
+	}
+	message struct {
+		Role    string    `json:"role"`
+		Content []content `json:"content"`
+		Usage   *usage    `json:"usage,omitempty"`
+		Model   string    `json:"model,omitempty"`
+	}
+	content struct {
+		Type      string          `json:"type"`
+		Text      string          `json:"text,omitempty"`
+		Thinking  string          `json:"thinking,omitempty"`
+		Name      string          `json:"name,omitempty"`
+		Arguments json.RawMessage `json:"arguments,omitempty"`
+	}
+	usage struct {
+		Input       int  `json:"input"`
+		Output      int  `json:"output"`
+		CacheRead   int  `json:"cacheRead"`
+		CacheWrite  int  `json:"cacheWrite"`
+		TotalTokens int  `json:"totalTokens"`
+		Cost        cost `json:"cost"`
+	}
+	cost struct {
+		Input      float64 `json:"input"`
+		Output     float64 `json:"output"`
+		CacheRead  float64 `json:"cacheRead"`
+		CacheWrite float64 `json:"cacheWrite"`
+		Total      float64 `json:"total"`
+	}
+)
+
+// transcript writes one session of the given number of turns and returns
+// the time of its last message. Messages that would fall after Options.End
+// are dated at End instead, so a session still running at End is not
+// dated in the future. The prompt of each turn is the system
+// prompt plus the conversation so far; with caching, the first turn writes
+// the prompt cache and later turns read the previous prompt from it.
+func (g *generator) transcript(path, id string, start time.Time, turns, systemTokens int, model string, cached bool) (time.Time, error) {
+	m, _ := catalog.Lookup(model)
+	price := m.PriceAt(start)
+	cached = cached && price.CacheRead > 0
+
+	file, err := os.Create(path)
+	if err != nil {
+		return start, err
+	}
+	defer file.Close()
+	enc := json.NewEncoder(file)
+
+	end := g.opts.End
+	advance := func(now time.Time, d time.Duration) time.Time {
+		if now = now.Add(d); now.After(end) {
+			return end
+		}
+		return now
+	}
+
+	now := advance(start, 0)
+	if err := enc.Encode(event{Type: "session", Version: 3, ID: id, Timestamp: now}); err != nil {
+		return now, err
+	}
+
+	history, prevPrompt := 0, 0
+	for turn := 0; turn < turns; turn++ {
+		now = advance(now, time.Duration(5+g.rng.Intn(120))*time.Second)
+		prompt := g.text(20 + g.rng.Intn(400))
+		if err := enc.Encode(event{Type: "message", Timestamp: now, Message: &message{
+			Role: "user", Content: []content{{Type: "text", Text: prompt}},
+		}}); err != nil {
+			return now, err
+		}
+		history += len(prompt) / 4
+
+		reply := []content{{Type: "text", Text: g.text(40 + g.rng.Intn(800))}}
+		if g.rng.Float64() < 0.4 {
+			reply = append([]content{{Type: "thinking", Thinking: g.text(100 + g.rng.Intn(1500))}}, reply...)
+		}
+		toolCall := g.rng.Float64() < 0.5
+		if toolCall {
+			args, _ := json.Marshal(map[string]string{"query": g.text(30)})
+			reply = append(reply, content{Type: "toolCall", Name: "search", Arguments: args})
+		}
+
+		var u usage
+		for _, c := range reply {
+			u.Output += (len(c.Text) + len(c.Thinking) + len(c.Arguments)) / 4
+		}
+		total := systemTokens + history
+		switch {
+		case cached && prevPrompt == 0:
+			u.CacheWrite = total
+		case cached:
+			u.CacheRead = prevPrompt
+			u.Input = total - prevPrompt
+		default:
+			u.Input = total
+		}
+		prevPrompt = total
+		u.TotalTokens = u.Input + u.Output + u.CacheRead + u.CacheWrite
+		u.Cost = cost{
+			Input:      float64(u.Input) * price.Input / 1_000_000,
+			Output:     float64(u.Output) * price.Output / 1_000_000,
+			CacheRead:  float64(u.CacheRead) * price.CacheRead / 1_000_000,
+			CacheWrite: float64(u.CacheWrite) * price.CacheWrite / 1_000_000,
+		}
+		u.Cost.Total = u.Cost.Input + u.Cost.Output + u.Cost.CacheRead + u.Cost.CacheWrite
+		g.stats.TotalCost += u.Cost.Total
+
+		now = advance(now, time.Duration(2+g.rng.Intn(30))*time.Second)
+		if err := enc.Encode(event{Type: "message", Timestamp: now, Message: &message{
+			Role: "assistant", Content: reply, Usage: &u, Model: model,
+		}}); err != nil {
+			return now, err
+		}
+		history += u.Output
+
+		if toolCall {
+			result := g.text(200 + g.rng.Intn(4000))
+			if err := enc.Encode(event{Type: "message", Timestamp: now, Message: &message{
+				Role: "toolResult", Content: []content{{Type: "text", Text: result}},
+			}}); err != nil {
+				return now, err
+			}
+			history += len(result) / 4
+		}
+	}
+	return now, nil
+}
+
+// model returns the agent's model, or occasionally the override model.
+func (g *generator) model(agentModel string) string {
+	if g.rng.Float64() < g.opts.OverrideShare {
+		return g.opts.OverrideModel
+	}
+	return agentModel
+}
+
+// text returns roughly n characters of filler words.
+func (g *generator) text(n int) string {
+	var b strings.Builder
+	for b.Len() < n {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(words[g.rng.Intn(len(words))])
+	}
+	return b.String()
+}
+
+// token returns n random lowercase alphanumerics.
+func (g *generator) token(n int) string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[g.rng.Intn(len(alphabet))]
+	}
+	return string(b)
+}
+
+// poisson samples a Poisson-distributed count with the given mean.
+func (g *generator) poisson(mean float64) int {
+	if mean <= 0 {
+		return 0
+	}
+	limit, k, p := math.Exp(-mean), 0, 1.0
+	for {
+		p *= g.rng.Float64()
+		if p <= limit {
+			return k
+		}
+		k++
+	}
+}
@@ -0,0 +1,114 @@
+package synth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/misty-step/costctl/parser"
+)
+
+func testOptions() Options {
+	opts := DefaultOptions()
+	opts.Agents = 2
+	opts.Days = 3
+	opts.End = time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+	opts.SubagentShare = 1
+	return opts
+}
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	stats, err := Generate(dir, testOptions())
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if stats.Agents != 2 || stats.CronRuns != 2*3*3 || stats.Subagents == 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	p := parser.New(dir)
+	sessions, err := p.ParseAll("")
+	if err != nil || len(p.Errors()) > 0 {
+		t.Fatalf("generated data did not parse: %v %v", err, p.Errors())
+	}
+	if len(sessions) != stats.Sessions {
+		t.Errorf("expected %d sessions, parsed %d", stats.Sessions, len(sessions))
+	}
+
+	types := make(map[parser.SessionType]int)
+	var total float64
+	for _, s := range sessions {
+		types[s.Type]++
+		total += s.Usage.CostTotal
+		if s.StartedAt.IsZero() || s.Usage.Model == "" || len(s.TokensByRole) == 0 {
+			t.Errorf("session %s lacks timestamps, model, or roles", s.ID)
+		}
+		if s.Type == parser.SessionTypeCron && (s.CronName == s.CronID || s.CronName == "") {
+			t.Errorf("expected a readable cron name for %s", s.CronID)
+		}
+	}
+	if types[parser.SessionTypeCron] != stats.CronRuns || types[parser.SessionTypeSubagent] != stats.Subagents {
+		t.Errorf("unexpected session types: %v", types)
+	}
+	if total <= 0 || total-stats.TotalCost > 1e-9 || stats.TotalCost-total > 1e-9 {
+		t.Errorf("expected parsed cost %v to match generated %v", total, stats.TotalCost)
+	}
+
+	if model, err := p.DefaultModel("amos"); err != nil || model != testOptions().Models[0] {
+		t.Errorf("expected amos to be configured with %s, got %q (%v)", testOptions().Models[0], model, err)
+	}
+}
+
+func TestGenerateStopsAtEnd(t *testing.T) {
+	// Sessions still running at End are cut off there. Busy agents make
+	// sure some are.
+	opts := testOptions()
+	opts.Agents = 5
+	opts.SessionsPerDay = 50
+	opts.End = time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	if _, err := Generate(dir, opts); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	sessions, err := parser.New(dir).ParseAll("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range sessions {
+		if s.EndedAt.After(opts.End) {
+			t.Errorf("expected session %s to end by %v, got %v", s.ID, opts.End, s.EndedAt)
+		}
+	}
+
+	// Data generated up to now reports right away, without lines dated in
+	// the future.
+	opts.End = time.Now()
+	dir = t.TempDir()
+	if _, err := Generate(dir, opts); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	p := parser.New(dir)
+	if _, err := p.ParseAll(""); err != nil || len(p.Errors()) > 0 {
+		t.Errorf("expected data generated up to now to parse, got %v %v", err, p.Errors())
+	}
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	a, err := Generate(t.TempDir(), testOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Generate(t.TempDir(), testOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Errorf("expected the same seed to generate the same data, got %+v and %+v", a, b)
+	}
+
+	opts := testOptions()
+	opts.Models = []string{"acme/unknown"}
+	if _, err := Generate(t.TempDir(), opts); err == nil {
+		t.Error("expected an error for a model missing from the catalog")
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/misty-step/costctl/config"
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/report"
+	"github.com/spf13/cobra"
+)
+
+// sessions tail command flags
+var (
+	tailAgent     string
+	tailInterval  time.Duration
+	tailFromStart bool
+	tailAll       bool
+)
+
+// tailActiveWindow is how recently a transcript must have been written
+// for tail --all to follow it.
+const tailActiveWindow = time.Hour
+
+// tailMaxBytes caps what tail --all reads per interval, so a backlog of
+// busy transcripts, e.g. on startup, is spread over several intervals.
+const tailMaxBytes = 8 << 20
+
+var sessionsTailCmd = &cobra.Command{
+	Use:   "tail [<id>]",
+	Short: "Follow a session's cost as it runs",
+	Long: `Follow a session's transcript and print each new assistant turn's tokens and
+cost with the session's running total, until interrupted.
+
+The id is found as for sessions bundle. Turns already in the transcript are
+summed into the starting total; --from-start prints them as well.
+
+With --all instead of an id, every session of the agents (or of --agent)
+whose transcript was written in the last hour is followed, each turn
+labelled with its session. Sessions are listed again every interval, so
+new ones are picked up, and only what was appended to each transcript
+since the last interval is read.
+
+Examples:
+  costctl sessions tail 2026-06-10-chat
+  costctl sessions tail r1 --agent urza --interval 5s
+  costctl sessions tail --all --interval 10s`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSessionsTail,
+}
+
+func init() {
+	sessionsTailCmd.Flags().StringVar(&tailAgent, "agent", "", "Only look for the session under this agent")
+	sessionsTailCmd.Flags().DurationVar(&tailInterval, "interval", time.Second, "How often to check the transcript for new turns")
+	sessionsTailCmd.Flags().BoolVar(&tailFromStart, "from-start", false, "Print the turns already in the transcript too")
+	sessionsTailCmd.Flags().BoolVar(&tailAll, "all", false, "Follow every session written in the last hour instead of one")
+
+	sessionsTailCmd.RegisterFlagCompletionFunc("agent", completeAgents)
+
+	sessionsCmd.AddCommand(sessionsTailCmd)
+}
+
+func runSessionsTail(cmd *cobra.Command, args []string) error {
+	if tailAll == (len(args) > 0) {
+		return fmt.Errorf("give either a session id or --all")
+	}
+	if tailInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	settings, err := loadSettings()
+	if err != nil {
+		return err
+	}
+	roots, err := resolveAgentsRoots()
+	if err != nil {
+		return err
+	}
+	if tailAll {
+		return tailActiveSessions(roots, settings)
+	}
+	file, err := findSessionFile(roots, settings, tailAgent, args[0])
+	if err != nil {
+		return err
+	}
+
+	follower := parser.NewFollower(file.path)
+	turns, err := follower.Poll()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Following %s/%s: %d turns, %s so far\n", file.agent, filepath.Base(file.path), follower.Turns, parser.FormatCost(follower.Usage.CostTotal))
+	if tailFromStart {
+		printTurns("", turns, follower, time.Time{})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ticker := time.NewTicker(tailInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Printf("Stopped: %d turns, %s\n", follower.Turns, parser.FormatCost(follower.Usage.CostTotal))
+			return nil
+		case <-ticker.C:
+		}
+		turns, err := follower.Poll()
+		if err != nil {
+			return err
+		}
+		printTurns("", turns, follower, time.Time{})
+	}
+}
+
+// tailActiveSessions follows every session of the agents selected by
+// --agent whose transcript was written within tailActiveWindow, until
+// interrupted. Turns from before it started are only printed with
+// --from-start; transcripts that cannot be read are reported and retried.
+func tailActiveSessions(roots []report.Root, settings *config.Config) error {
+	tracker := parser.NewTracker()
+	tracker.MaxBytes = tailMaxBytes
+	started := time.Now()
+	if tailFromStart {
+		started = time.Time{}
+	}
+
+	poll := func() error {
+		cutoff := time.Now().Add(-tailActiveWindow)
+		files, err := listSessionFiles(roots, settings, tailAgent, func(path string) bool {
+			info, err := os.Stat(path)
+			return err == nil && info.ModTime().After(cutoff)
+		})
+		if err != nil {
+			return err
+		}
+		paths := make([]string, len(files))
+		for i, f := range files {
+			paths[i] = f.path
+		}
+		polled, err := tracker.Poll(paths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		for _, f := range files {
+			if turns := polled[f.path]; len(turns) > 0 {
+				label := f.agent + "/" + strings.TrimSuffix(filepath.Base(f.path), ".jsonl")
+				printTurns(label, turns, tracker.Follower(f.path), started)
+			}
+		}
+		return nil
+	}
+
+	fmt.Println("Following sessions written in the last hour")
+	if err := poll(); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ticker := time.NewTicker(tailInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Println("Stopped")
+			return nil
+		case <-ticker.C:
+		}
+		if err := poll(); err != nil {
+			return err
+		}
+	}
+}
+
+// printTurns prints one line per turn ending with the running total after
+// it, prefixed with label if set. The follower has already added the turns
+// to its totals. Turns dated before since are counted but not printed.
+func printTurns(label string, turns []parser.Message, follower *parser.Follower, since time.Time) {
+	total := follower.Usage.CostTotal
+	for _, t := range turns {
+		total -= t.Message.Usage.Cost.Total
+	}
+	for _, t := range turns {
+		u := t.Message.Usage
+		total += u.Cost.Total
+		if !t.Timestamp.IsZero() && t.Timestamp.Before(since) {
+			continue
+		}
+		stamp := "--:--:--"
+		if !t.Timestamp.IsZero() {
+			stamp = t.Timestamp.Local().Format("15:04:05")
+		}
+		line := fmt.Sprintf("%s  %-28s in %7s  out %7s  cache %7s/%-7s  %9s  total %s",
+			stamp, t.Message.Model, parser.FormatTokens(u.Input), parser.FormatTokens(u.Output),
+			parser.FormatTokens(u.CacheRead), parser.FormatTokens(u.CacheWrite),
+			parser.FormatCost(u.Cost.Total), parser.FormatCost(total))
+		if t.Failed() {
+			line += "  (failed)"
+		}
+		if label != "" {
+			line = label + "  " + line
+		}
+		fmt.Println(line)
+	}
+}
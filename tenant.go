@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/misty-step/costctl/config"
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/report"
+)
+
+// resolveAgentsRoots determines which agents directories to read. An
+// explicit --agents-dir wins and is labelled with --tenant, if given. With
+// tenants configured, --tenant selects one of them and the default is all
+// of them; otherwise the default agents directory is the only root.
+func resolveAgentsRoots() ([]report.Root, error) {
+	if agentsDir != "" {
+		dir, err := parser.ResolveAgentsDir(agentsDir)
+		if err != nil {
+			return nil, err
+		}
+		return []report.Root{{Tenant: tenantName, Dir: dir}}, nil
+	}
+
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Tenants) == 0 {
+		if tenantName != "" {
+			return nil, fmt.Errorf("unknown tenant: %s (no tenants configured in %s)", tenantName, path)
+		}
+		dir, err := parser.ResolveAgentsDir("")
+		if err != nil {
+			return nil, err
+		}
+		return []report.Root{{Dir: dir}}, nil
+	}
+
+	var names []string
+	if tenantName != "" {
+		if _, ok := cfg.Tenants[tenantName]; !ok {
+			return nil, fmt.Errorf("unknown tenant: %s (configured in %s)", tenantName, path)
+		}
+		names = []string{tenantName}
+	} else {
+		for name := range cfg.Tenants {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	roots := make([]report.Root, 0, len(names))
+	for _, name := range names {
+		dir, err := parser.ResolveAgentsDir(cfg.Tenants[name])
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", name, err)
+		}
+		roots = append(roots, report.Root{Tenant: name, Dir: dir})
+	}
+	return roots, nil
+}
+
+// parseSessions parses every agents root as of asOf (zero for now), tagging
+// sessions with their tenant and giving agents their configured display
+// names. Sessions that started before since (zero for all time) are left
+// out. It also returns the agents and session files that were skipped.
+func parseSessions(agent string, since, asOf time.Time) ([]parser.Session, []error, error) {
+	roots, err := resolveAgentsRoots()
+	if err != nil {
+		return nil, nil, err
+	}
+	settings, err := loadSettings()
+	if err != nil {
+		return nil, nil, err
+	}
+	progress, err := newProgress()
+	if err != nil {
+		return nil, nil, err
+	}
+	cache := openCache()
+	defer saveCache(cache)
+	sessions, skipped, err := report.Load(context.Background(), report.Options{
+		Roots:    roots,
+		Agent:    agent,
+		Since:    since,
+		AsOf:     asOf,
+		Settings: settings,
+		Progress: parserProgress(progress),
+		Cache:    cache,
+	})
+	if err == nil && progress != nil {
+		progress.Done()
+	}
+	return sessions, skipped, err
+}
+
+// loadSettings reads the config file named by --config, adding the
+// --exclude-agent and --exclude-session globs to its filter.
+func loadSettings() (*config.Config, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	settings, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	settings.Filter.ExcludeAgents = append(settings.Filter.ExcludeAgents, excludeAgents...)
+	settings.Filter.ExcludeSessions = append(settings.Filter.ExcludeSessions, excludeSessions...)
+	if err := parser.Filter(settings.Filter).Validate(); err != nil {
+		return nil, err
+	}
+	if err := parser.ValidateDiscovery(settings.Discovery); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// newParser returns a parser for the agents directory dir that finds and
+// filters agents as settings configure.
+func newParser(dir string, settings *config.Config) *parser.Parser {
+	p := parser.New(dir)
+	p.Discovery = settings.Discovery
+	p.Filter = parser.Filter(settings.Filter)
+	return p
+}
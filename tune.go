@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/misty-step/costctl/config"
+	"github.com/misty-step/costctl/formats"
+	"github.com/misty-step/costctl/parser"
+	"github.com/misty-step/costctl/reporter"
+	"github.com/spf13/cobra"
+)
+
+// tune command flags
+var (
+	tuneDays       int
+	tunePercentile float64
+	tuneFactor     float64
+	tuneMinRuns    int
+	tuneAgent      string
+	tuneWrite      bool
+)
+
+var tuneCmd = &cobra.Command{
+	Use:   "tune",
+	Short: "Suggest per-cron anomaly thresholds from run history",
+	Long: `Analyze recent cron runs and suggest an expensive_cron threshold for each
+cron: the given percentile of its run cost times a headroom factor. With
+--write, suggestions are saved to rules.cron_thresholds in the config file
+and override --threshold for those crons in future reports.
+
+Examples:
+  costctl tune
+  costctl tune --days 14 --percentile 90 --factor 2
+  costctl tune --write`,
+	RunE: runTune,
+}
+
+func init() {
+	tuneCmd.Flags().IntVar(&tuneDays, "days", 30, "Days of history to analyze")
+	tuneCmd.Flags().Float64Var(&tunePercentile, "percentile", 95, "Run cost percentile to base thresholds on")
+	tuneCmd.Flags().Float64Var(&tuneFactor, "factor", 1.5, "Headroom multiplier applied to the percentile")
+	tuneCmd.Flags().IntVar(&tuneMinRuns, "min-runs", 5, "Minimum runs before a cron gets a suggestion")
+	tuneCmd.Flags().StringVar(&tuneAgent, "agent", "", "Only analyze this agent's crons")
+	tuneCmd.Flags().BoolVar(&tuneWrite, "write", false, "Save suggestions to the config file")
+
+	tuneCmd.RegisterFlagCompletionFunc("agent", completeAgents)
+}
+
+func runTune(cmd *cobra.Command, args []string) error {
+	if tunePercentile <= 0 || tunePercentile > 100 {
+		return fmt.Errorf("invalid percentile: %v (must be in (0, 100])", tunePercentile)
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -tuneDays)
+	sessions, _, err := parseSessions(tuneAgent, cutoff, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	var recent []parser.Session
+	for _, s := range sessions {
+		if !s.StartedAt.IsZero() && s.StartedAt.After(cutoff) {
+			recent = append(recent, s)
+		}
+	}
+
+	suggestions := reporter.SuggestThresholds(recent, reporter.TuneOptions{
+		Percentile: tunePercentile,
+		Factor:     tuneFactor,
+		MinRuns:    tuneMinRuns,
+	})
+
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	if len(suggestions) == 0 {
+		fmt.Printf("No crons with at least %d runs in the last %d days\n", tuneMinRuns, tuneDays)
+		return nil
+	}
+
+	names := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		names[i] = s.CronName
+	}
+	// Capped like the cron names of report's text tables.
+	width := formats.ColumnWidth("CRON NAME", names, 40, false)
+	fmt.Printf("  %-*s %6s %10s %10s %10s\n", width, "CRON NAME", "RUNS", fmt.Sprintf("P%g", tunePercentile), "SUGGESTED", "CURRENT")
+	for _, s := range suggestions {
+		current := "-"
+		if t, ok := cfg.Rules.CronThresholds[s.CronName]; ok {
+			current = parser.FormatCost(t)
+		}
+		fmt.Printf("  %-*s %6d %10s %10s %10s\n",
+			width, formats.Truncate(s.CronName, width), s.Runs, parser.FormatCost(s.Percentile), parser.FormatCost(s.Suggested), current)
+	}
+
+	if !tuneWrite {
+		fmt.Println("\nRe-run with --write to save these thresholds to", path)
+		return nil
+	}
+
+	if cfg.Rules.CronThresholds == nil {
+		cfg.Rules.CronThresholds = make(map[string]float64)
+	}
+	for _, s := range suggestions {
+		cfg.Rules.CronThresholds[s.CronName] = s.Suggested
+	}
+	if err := cfg.Save(path); err != nil {
+		return err
+	}
+	fmt.Printf("\nSaved %d thresholds to %s\n", len(suggestions), path)
+	return nil
+}